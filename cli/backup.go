@@ -0,0 +1,112 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	kitlog "github.com/go-kit/kit/log"
+	"github.com/kolide/kolide-ose/server/backup"
+	"github.com/kolide/kolide-ose/server/config"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+)
+
+func createBackupCmd(configManager config.Manager) *cobra.Command {
+	var backupCmd = &cobra.Command{
+		Use:   "backup <file>",
+		Short: "Back up the kolide database to an archive",
+		Long: `
+Back up the kolide database to an archive
+
+The archive is a tar file of JSON documents holding the users, app config,
+options, labels, queries, packs with their scheduled queries and targets,
+decorators, file integrity monitoring and YARA config, detail queries, and
+hosts. It can be restored with kolide restore into a database of any of the datastore drivers.
+`,
+		Run: func(cmd *cobra.Command, args []string) {
+			if len(args) != 1 {
+				cmd.Help()
+				os.Exit(1)
+			}
+
+			config := configManager.LoadConfig()
+			ds, err := newDatastore(config, kitlog.NewNopLogger())
+			if err != nil {
+				initFatal(err, "creating db connection")
+			}
+
+			f, err := os.Create(args[0])
+			if err != nil {
+				initFatal(err, "creating archive")
+			}
+			if err := backup.Backup(ds, f); err != nil {
+				f.Close()
+				os.Remove(args[0])
+				initFatal(err, "backing up database")
+			}
+			if err := f.Close(); err != nil {
+				initFatal(err, "closing archive")
+			}
+			fmt.Printf("Backed up the %s database to %s\n", ds.Name(), args[0])
+		},
+	}
+
+	return backupCmd
+}
+
+func createRestoreCmd(configManager config.Manager) *cobra.Command {
+	var restoreCmd = &cobra.Command{
+		Use:   "restore <file>",
+		Short: "Restore the kolide database from an archive",
+		Long: `
+Restore the kolide database from an archive
+
+Loads an archive written by kolide backup into the configured database, which
+may use a different datastore driver than the one backed up. Run kolide
+prepare db first, and restore into a database without users, queries, packs
+or hosts. If a restore fails, recreate the database before trying again.
+
+Hosts fetch their config again once the restore is done, as the cached osquery
+configs are invalidated when the config cache is Redis.
+`,
+		Run: func(cmd *cobra.Command, args []string) {
+			if len(args) != 1 {
+				cmd.Help()
+				os.Exit(1)
+			}
+
+			config := configManager.LoadConfig()
+			ds, err := newDatastore(config, kitlog.NewNopLogger())
+			if err != nil {
+				initFatal(err, "creating db connection")
+			}
+
+			status, err := ds.MigrationStatus()
+			if err != nil {
+				initFatal(err, "checking database migrations")
+			}
+			if pending := status.Pending(); len(pending) > 0 {
+				initFatal(errors.Errorf("%d pending migrations, run kolide prepare db first", len(pending)), "checking database migrations")
+			}
+
+			f, err := os.Open(args[0])
+			if err != nil {
+				initFatal(err, "opening archive")
+			}
+			defer f.Close()
+
+			manifest, err := backup.Restore(ds, f)
+			if err != nil {
+				initFatal(err, "restoring database")
+			}
+			if err := invalidateConfigCache(config); err != nil {
+				initFatal(err, "invalidating osquery config cache")
+			}
+			fmt.Printf("Restored the %s database backed up by kolide %s at %s\n",
+				manifest.Datastore, manifest.KolideVersion, manifest.CreatedAt.Format(time.RFC3339))
+		},
+	}
+
+	return restoreCmd
+}
@@ -1,13 +1,21 @@
 package cli
 
 import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+
 	"github.com/WatchBeam/clock"
 	kitlog "github.com/go-kit/kit/log"
 	"github.com/kolide/kolide-ose/server/config"
-	"github.com/kolide/kolide-ose/server/datastore/mysql"
 	"github.com/kolide/kolide-ose/server/kolide"
 	"github.com/kolide/kolide-ose/server/pubsub"
 	"github.com/kolide/kolide-ose/server/service"
+	"github.com/pkg/errors"
 	"github.com/spf13/cobra"
 	"golang.org/x/net/context"
 )
@@ -27,17 +35,36 @@ To setup kolide infrastructure, use one of the available commands.
 		},
 	}
 
+	var (
+		dryRun bool
+		yes    bool
+	)
+
 	var dbCmd = &cobra.Command{
 		Use:   "db",
 		Short: "Given correct database configurations, prepare the databases for use",
-		Long:  ``,
+		Long: `
+Given correct database configurations, prepare the databases for use
+
+Applies the pending table migrations and then the pending data migrations.
+With --dry-run, the SQL of the pending migrations is printed instead.
+`,
 		Run: func(cmd *cobra.Command, args []string) {
 			config := configManager.LoadConfig()
-			ds, err := mysql.New(config.Mysql, clock.C)
+			ds, err := newDatastore(config, kitlog.NewNopLogger())
 			if err != nil {
 				initFatal(err, "creating db connection")
 			}
 
+			if dryRun {
+				steps, err := ds.PlanMigrations()
+				if err != nil {
+					initFatal(err, "planning migrations")
+				}
+				printMigrationSteps(os.Stdout, steps)
+				return
+			}
+
 			if err := ds.MigrateTables(); err != nil {
 				initFatal(err, "migrating db schema")
 			}
@@ -45,9 +72,127 @@ To setup kolide infrastructure, use one of the available commands.
 			if err := ds.MigrateData(); err != nil {
 				initFatal(err, "migrating builtin data")
 			}
+
+			if err := invalidateConfigCache(config); err != nil {
+				initFatal(err, "invalidating osquery config cache")
+			}
+		},
+	}
+
+	dbCmd.PersistentFlags().BoolVar(&dryRun, "dry-run", false, "Print the SQL of the migrations instead of running it")
+
+	var dbStatusCmd = &cobra.Command{
+		Use:   "status",
+		Short: "Show the applied and pending migrations",
+		Long:  ``,
+		Run: func(cmd *cobra.Command, args []string) {
+			config := configManager.LoadConfig()
+			ds, err := newDatastore(config, kitlog.NewNopLogger())
+			if err != nil {
+				initFatal(err, "creating db connection")
+			}
+
+			status, err := ds.MigrationStatus()
+			if err != nil {
+				initFatal(err, "reading migration status")
+			}
+
+			w := tabwriter.NewWriter(os.Stdout, 0, 8, 2, ' ', 0)
+			fmt.Fprintln(w, "KIND\tVERSION\tNAME\tSTATUS")
+			for _, m := range status.Migrations() {
+				state := "pending"
+				if m.Applied {
+					state = "applied"
+				}
+				fmt.Fprintf(w, "%s\t%d\t%s\t%s\n", m.Kind, m.Version, m.Name, state)
+			}
+			w.Flush()
+			fmt.Printf("\n%d pending migrations\n", len(status.Pending()))
+		},
+	}
+
+	dbCmd.AddCommand(dbStatusCmd)
+
+	var dbDownCmd = &cobra.Command{
+		Use:   "down",
+		Short: "Roll back the most recently applied migration",
+		Long:  ``,
+		Run: func(cmd *cobra.Command, args []string) {
+			config := configManager.LoadConfig()
+			ds, err := newDatastore(config, kitlog.NewNopLogger())
+			if err != nil {
+				initFatal(err, "creating db connection")
+			}
+
+			status, err := ds.MigrationStatus()
+			if err != nil {
+				initFatal(err, "reading migration status")
+			}
+			applied := appliedVersions(status)
+			if len(applied) == 0 {
+				fmt.Println("No migrations have been applied")
+				return
+			}
+			var version int64
+			if len(applied) > 1 {
+				version = applied[len(applied)-2]
+			}
+
+			rollbackMigrations(config, ds, version, dryRun, yes)
 		},
 	}
 
+	dbDownCmd.Flags().BoolVar(&yes, "yes", false, "Roll back without asking for confirmation")
+	dbCmd.AddCommand(dbDownCmd)
+
+	var dbToCmd = &cobra.Command{
+		Use:   "to <version>",
+		Short: "Roll back the migrations applied after a version",
+		Long: `
+Roll back the migrations applied after a version
+
+The migrations applied after the given one are rolled back, newest first.
+Table migrations are applied before data migrations, so data migrations are
+rolled back first, and rolling back to a table migration rolls back every data
+migration. Rolling back to version 0 rolls back every migration.
+`,
+		Run: func(cmd *cobra.Command, args []string) {
+			if len(args) != 1 {
+				cmd.Help()
+				os.Exit(1)
+			}
+			version, err := strconv.ParseInt(args[0], 10, 64)
+			if err != nil {
+				initFatal(err, "parsing migration version")
+			}
+
+			config := configManager.LoadConfig()
+			ds, err := newDatastore(config, kitlog.NewNopLogger())
+			if err != nil {
+				initFatal(err, "creating db connection")
+			}
+
+			if version != 0 {
+				status, err := ds.MigrationStatus()
+				if err != nil {
+					initFatal(err, "reading migration status")
+				}
+				known := false
+				for _, m := range status.Migrations() {
+					known = known || m.Version == version
+				}
+				if !known {
+					initFatal(errors.Errorf("unknown version %d", version), "finding migration")
+				}
+			}
+
+			rollbackMigrations(config, ds, version, dryRun, yes)
+		},
+	}
+
+	dbToCmd.Flags().BoolVar(&yes, "yes", false, "Roll back without asking for confirmation")
+	dbCmd.AddCommand(dbToCmd)
+
 	prepareCmd.AddCommand(dbCmd)
 
 	var testDataCmd = &cobra.Command{
@@ -56,7 +201,7 @@ To setup kolide infrastructure, use one of the available commands.
 		Long:  ``,
 		Run: func(cmd *cobra.Command, arg []string) {
 			config := configManager.LoadConfig()
-			ds, err := mysql.New(config.Mysql, clock.C)
+			ds, err := newDatastore(config, kitlog.NewNopLogger())
 			if err != nil {
 				initFatal(err, "creating db connection")
 			}
@@ -77,7 +222,7 @@ To setup kolide infrastructure, use one of the available commands.
 				Enabled:  &enabled,
 				Admin:    &isAdmin,
 			}
-			svc, err := service.NewService(ds, pubsub.NewInmemQueryResults(), kitlog.NewNopLogger(), config, nil, clock.C)
+			svc, err := service.NewService(ds, pubsub.NewInmemQueryResults(), kitlog.NewNopLogger(), config, nil, clock.C, nil)
 			if err != nil {
 				initFatal(err, "creating service")
 			}
@@ -92,5 +237,76 @@ To setup kolide infrastructure, use one of the available commands.
 	prepareCmd.AddCommand(testDataCmd)
 
 	return prepareCmd
+}
+
+// appliedVersions returns the versions of the applied table and data
+// migrations in the order they are applied, table migrations first.
+func appliedVersions(status *kolide.MigrationStatus) []int64 {
+	versions := []int64{}
+	for _, m := range status.Migrations() {
+		if m.Applied {
+			versions = append(versions, m.Version)
+		}
+	}
+	return versions
+}
+
+// rollbackMigrations rolls back the migrations above version once the user
+// confirms, or prints their SQL when dryRun is true. Configs cached in Redis
+// are invalidated after a rollback since they may name dropped packs.
+func rollbackMigrations(conf config.KolideConfig, ds kolide.Datastore, version int64, dryRun, yes bool) {
+	steps, err := ds.PlanRollback(version)
+	if err != nil {
+		initFatal(err, "planning rollback")
+	}
+	if len(steps) == 0 {
+		fmt.Println("No migrations to roll back")
+		return
+	}
+	if dryRun {
+		printMigrationSteps(os.Stdout, steps)
+		return
+	}
 
+	fmt.Println("The following migrations will be rolled back:")
+	for _, step := range steps {
+		fmt.Printf("  %s %d %s\n", step.Kind, step.Version, step.Name)
+	}
+	if !yes {
+		fmt.Print("Roll back these migrations? Data in dropped tables is lost. [y/N] ")
+		answer, _ := bufio.NewReader(os.Stdin).ReadString('\n')
+		answer = strings.ToLower(strings.TrimSpace(answer))
+		if answer != "y" && answer != "yes" {
+			fmt.Println("Rollback cancelled")
+			return
+		}
+	}
+
+	if err := ds.RollbackMigrations(version); err != nil {
+		initFatal(err, "rolling back migrations")
+	}
+	if err := invalidateConfigCache(conf); err != nil {
+		initFatal(err, "invalidating osquery config cache")
+	}
+	fmt.Printf("Rolled back %d migrations\n", len(steps))
+}
+
+// printMigrationSteps writes the SQL of each migration step, preceded by a
+// comment naming the migration.
+func printMigrationSteps(w io.Writer, steps []*kolide.MigrationStep) {
+	if len(steps) == 0 {
+		fmt.Fprintln(w, "-- no migrations to run")
+		return
+	}
+	for _, step := range steps {
+		direction := "down"
+		if step.Up {
+			direction = "up"
+		}
+		fmt.Fprintf(w, "-- %s migration %d %s (%s)\n", step.Kind, step.Version, step.Name, direction)
+		for _, statement := range step.Statements {
+			fmt.Fprintln(w, statement)
+		}
+		fmt.Fprintln(w)
+	}
 }
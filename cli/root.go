@@ -18,6 +18,9 @@ func Launch() {
 	rootCmd.AddCommand(createServeCmd(configManager))
 	rootCmd.AddCommand(createConfigDumpCmd(configManager))
 	rootCmd.AddCommand(createVersionCmd(configManager))
+	rootCmd.AddCommand(createImportVulnerabilitiesCmd(configManager))
+	rootCmd.AddCommand(createBackupCmd(configManager))
+	rootCmd.AddCommand(createRestoreCmd(configManager))
 
 	if err := rootCmd.Execute(); err != nil {
 		initFatal(err, "running root command")
@@ -1,8 +1,11 @@
 package cli
 
 import (
+	"crypto/tls"
+	"crypto/x509"
 	"encoding/json"
 	"fmt"
+	"io/ioutil"
 	"net/http"
 	"os"
 	"os/signal"
@@ -13,25 +16,27 @@ import (
 	kitlog "github.com/go-kit/kit/log"
 	kitprometheus "github.com/go-kit/kit/metrics/prometheus"
 	"github.com/kolide/kolide-ose/server/config"
+	"github.com/kolide/kolide-ose/server/configcache"
+	"github.com/kolide/kolide-ose/server/datastore/inmem"
 	"github.com/kolide/kolide-ose/server/datastore/mysql"
+	"github.com/kolide/kolide-ose/server/datastore/postgres"
+	"github.com/kolide/kolide-ose/server/datastore/sqlite"
+	"github.com/kolide/kolide-ose/server/jobs"
 	"github.com/kolide/kolide-ose/server/kolide"
 	"github.com/kolide/kolide-ose/server/mail"
 	"github.com/kolide/kolide-ose/server/pubsub"
 	"github.com/kolide/kolide-ose/server/service"
 	"github.com/kolide/kolide-ose/server/version"
+	"github.com/pkg/errors"
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/prometheus/client_golang/prometheus/promhttp"
 	"github.com/spf13/cobra"
 	"golang.org/x/net/context"
 )
 
-type initializer interface {
-	// Initialize is used to populate a datastore with
-	// preloaded data
-	Initialize() error
-}
-
 func createServeCmd(configManager config.Manager) *cobra.Command {
+	var dev bool
+
 	serveCmd := &cobra.Command{
 		Use:   "serve",
 		Short: "Launch the kolide server",
@@ -42,6 +47,14 @@ Use kolide serve to run the main HTTPS server. The Kolide server bundles
 together all static assets and dependent libraries into a statically linked go
 binary (which you're executing right now). Use the options below to customize
 the way that the kolide server works.
+
+State is stored in the database selected by datastore.driver, one of mysql,
+postgres or sqlite, and in Redis. A SQLite database can't be shared by several
+servers.
+
+With --dev, or server.mode set to standalone, all state is kept in memory
+instead, and is saved to server.snapshot_file on shutdown if it is set. --dev
+also loads example data the first time that it starts.
 `,
 		Run: func(cmd *cobra.Command, args []string) {
 			ctx := context.Background()
@@ -58,38 +71,34 @@ the way that the kolide server works.
 				logger = kitlog.NewContext(logger).With("ts", kitlog.DefaultTimestampUTC)
 			}
 
-			var ds kolide.Datastore
-			var err error
 			mailService := mail.NewService()
 
-			ds, err = mysql.New(config.Mysql, clock.C, mysql.Logger(logger))
+			ds, resultStore, err := newStores(config, dev, logger)
 			if err != nil {
 				initFatal(err, "initializing datastore")
 			}
 
-			if initializingDS, ok := ds.(initializer); ok {
-				if err := initializingDS.Initialize(); err != nil {
-					initFatal(err, "loading built in data")
-				}
+			status, err := ds.MigrationStatus()
+			if err != nil {
+				initFatal(err, "checking database migrations")
+			}
+			if pending := status.Pending(); len(pending) > 0 {
+				fmt.Printf("The database has %d pending migrations, starting with %s migration %d %s.\n"+
+					"Run kolide prepare db to apply them before starting the server.\n",
+					len(pending), pending[0].Kind, pending[0].Version, pending[0].Name)
+				os.Exit(1)
 			}
 
-			var resultStore kolide.QueryResultStore
-			redisPool := pubsub.NewRedisPool(config.Redis.Address, config.Redis.Password)
-			resultStore = pubsub.NewRedisQueryResults(redisPool)
+			configCache, err := newConfigCache(config)
+			if err != nil {
+				initFatal(err, "initializing osquery config cache")
+			}
 
-			svc, err := service.NewService(ds, resultStore, logger, config, mailService, clock.C)
+			svc, err := service.NewService(ds, resultStore, logger, config, mailService, clock.C, configCache)
 			if err != nil {
 				initFatal(err, "initializing service")
 			}
 
-			go func() {
-				ticker := time.NewTicker(1 * time.Hour)
-				for {
-					ds.CleanupDistributedQueryCampaigns(time.Now())
-					<-ticker.C
-				}
-			}()
-
 			fieldKeys := []string{"method", "error"}
 			requestCount := kitprometheus.NewCounterFrom(prometheus.CounterOpts{
 				Namespace: "api",
@@ -108,12 +117,61 @@ the way that the kolide server works.
 			svc = service.NewLoggingService(svc, svcLogger)
 			svc = service.NewMetricsService(svc, requestCount, requestLatency)
 
+			if config.Osquery.HostSeenFlushInterval <= 0 {
+				initFatal(errors.New("osquery.host_seen_flush_interval must be greater than zero"), "loading config")
+			}
+			go func() {
+				ticker := time.NewTicker(config.Osquery.HostSeenFlushInterval)
+				for range ticker.C {
+					if err := svc.FlushSeenHosts(ctx); err != nil {
+						logger.Log("method", "FlushSeenHosts", "err", err)
+					}
+				}
+			}()
+
+			instance, err := jobs.InstanceName()
+			if err != nil {
+				initFatal(err, "naming server instance")
+			}
+			scheduler := jobs.NewScheduler(ds, instance, kitlog.NewContext(logger).With("component", "jobs"), clock.C)
+			err = scheduler.Register("cleanup_distributed_query_campaigns", 1*time.Hour, func(ctx context.Context) error {
+				_, _, err := ds.CleanupDistributedQueryCampaigns(time.Now())
+				return err
+			})
+			if err != nil {
+				initFatal(err, "registering jobs")
+			}
+			// Purging hosts goes through the logging service so that
+			// every run is logged
+			if config.Osquery.HostRetention > 0 {
+				err = scheduler.Register("purge_hosts", 1*time.Hour, func(ctx context.Context) error {
+					_, err := svc.PurgeHosts(ctx)
+					return err
+				})
+				if err != nil {
+					initFatal(err, "registering jobs")
+				}
+			}
+			if err := scheduler.Register(kolide.MatchVulnerabilitiesJob, 24*time.Hour, svc.MatchVulnerabilities); err != nil {
+				initFatal(err, "registering jobs")
+			}
+			jobsCtx, stopJobs := context.WithCancel(ctx)
+			jobsDone := make(chan struct{})
+			go func() {
+				scheduler.Run(jobsCtx)
+				close(jobsDone)
+			}()
+
 			httpLogger := kitlog.NewContext(logger).With("component", "http")
 
 			var apiHandler, frontendHandler http.Handler
 			{
 				frontendHandler = prometheus.InstrumentHandler("get_frontend", service.ServeFrontend())
-				apiHandler = service.MakeHandler(ctx, svc, config.Auth.JwtKey, httpLogger)
+				if config.Server.OsqueryRoutes {
+					apiHandler = service.MakeHandler(ctx, svc, config.Auth.JwtKey, httpLogger)
+				} else {
+					apiHandler = service.MakeAdminHandler(ctx, svc, config.Auth.JwtKey, httpLogger)
+				}
 				// WithSetup will check if first time setup is required
 				// By performing the same check inside main, we can make server startups
 				// more efficient after the first startup.
@@ -134,38 +192,249 @@ the way that the kolide server works.
 			http.Handle("/healthz", prometheus.InstrumentHandler("healthz", healthz(healthCheckers)))
 			http.Handle("/version", prometheus.InstrumentHandler("version", version.Handler()))
 			http.Handle("/assets/", prometheus.InstrumentHandler("static_assets", service.ServeStaticAssets("/assets/")))
+			prometheus.MustRegister(service.NewHostBreakdownCollector(svc))
 			http.Handle("/metrics", prometheus.InstrumentHandler("metrics", promhttp.Handler()))
 			http.Handle("/api/", apiHandler)
 			http.Handle("/", frontendHandler)
 
-			errs := make(chan error, 2)
+			srv := &http.Server{
+				Addr:         config.Server.Address,
+				ReadTimeout:  config.Server.ReadTimeout,
+				WriteTimeout: config.Server.WriteTimeout,
+				IdleTimeout:  config.Server.IdleTimeout,
+			}
+			errs := make(chan error, 3)
 			go func() {
 				if !config.Server.TLS {
 					logger.Log("transport", "http", "address", config.Server.Address, "msg", "listening")
-					errs <- http.ListenAndServe(config.Server.Address, nil)
+					errs <- srv.ListenAndServe()
 				} else {
 					logger.Log("transport", "https", "address", config.Server.Address, "msg", "listening")
-					errs <- http.ListenAndServeTLS(
-						config.Server.Address,
-						config.Server.Cert,
-						config.Server.Key,
-						nil,
-					)
+					errs <- srv.ListenAndServeTLS(config.Server.Cert, config.Server.Key)
 				}
 			}()
+
+			// The agent server serves only the osquery agent API, on its
+			// own address and certificate
+			var agentSrv *http.Server
+			if config.AgentServer.Address != "" {
+				tlsConfig, err := agentTLSConfig(config.AgentServer)
+				if err != nil {
+					initFatal(err, "configuring agent server")
+				}
+				agentSrv = &http.Server{
+					Addr:         config.AgentServer.Address,
+					Handler:      service.MakeOsqueryHandler(ctx, svc, config.Auth.JwtKey, httpLogger),
+					TLSConfig:    tlsConfig,
+					ReadTimeout:  config.Server.ReadTimeout,
+					WriteTimeout: config.Server.WriteTimeout,
+					IdleTimeout:  config.Server.IdleTimeout,
+				}
+				go func() {
+					if !config.AgentServer.TLS {
+						logger.Log("transport", "http", "address", config.AgentServer.Address, "msg", "listening for agents")
+						errs <- agentSrv.ListenAndServe()
+					} else {
+						logger.Log("transport", "https", "address", config.AgentServer.Address, "msg", "listening for agents")
+						errs <- agentSrv.ListenAndServeTLS(config.AgentServer.Cert, config.AgentServer.Key)
+					}
+				}()
+			} else if !config.Server.OsqueryRoutes {
+				initFatal(errors.New("server.osquery_routes may only be disabled when agent_server.address is set"), "loading config")
+			}
 			go func() {
-				c := make(chan os.Signal)
-				signal.Notify(c, syscall.SIGINT)
+				c := make(chan os.Signal, 1)
+				signal.Notify(c, syscall.SIGINT, syscall.SIGTERM)
 				errs <- fmt.Errorf("%s", <-c)
 			}()
 
 			logger.Log("terminated", <-errs)
+
+			// Stop accepting connections and let the requests in flight
+			// finish, then close the campaign websockets, which the HTTP
+			// server no longer tracks, and flush the logs
+			shutdownCtx, cancelShutdown := context.WithTimeout(context.Background(), config.Server.ShutdownTimeout)
+			defer cancelShutdown()
+			if err := srv.Shutdown(shutdownCtx); err != nil {
+				logger.Log("msg", "draining requests", "err", err)
+			}
+			if agentSrv != nil {
+				if err := agentSrv.Shutdown(shutdownCtx); err != nil {
+					logger.Log("msg", "draining agent requests", "err", err)
+				}
+			}
+
+			stopJobs()
+			select {
+			case <-jobsDone:
+			case <-shutdownCtx.Done():
+				logger.Log("msg", "waiting for jobs", "err", shutdownCtx.Err())
+			}
+
+			if err := svc.Shutdown(shutdownCtx); err != nil {
+				logger.Log("method", "Shutdown", "err", err)
+			}
+
+			if snapshotter, ok := ds.(*inmem.Datastore); ok && config.Server.SnapshotFile != "" {
+				if err := snapshotter.SaveSnapshotFile(config.Server.SnapshotFile); err != nil {
+					initFatal(err, "saving snapshot")
+				}
+				logger.Log("msg", "saved snapshot", "file", config.Server.SnapshotFile)
+			}
 		},
 	}
 
+	serveCmd.Flags().BoolVar(&dev, "dev", false, "Run standalone with example data, without MySQL or Redis")
+
 	return serveCmd
 }
 
+// newConfigCache returns the osquery config cache selected by
+// osquery.config_cache, counting its lookups, or nil when configs are not
+// cached.
+func newConfigCache(conf config.KolideConfig) (kolide.OsqueryConfigCache, error) {
+	cache, err := openConfigCache(conf)
+	if cache == nil || err != nil {
+		return nil, err
+	}
+
+	lookups := kitprometheus.NewCounterFrom(prometheus.CounterOpts{
+		Namespace: "osquery",
+		Subsystem: "config_cache",
+		Name:      "lookup_count",
+		Help:      "Number of osquery config cache lookups, by result (hit, miss or error).",
+	}, []string{"result"})
+	return configcache.NewMetricsConfigCache(cache, lookups), nil
+}
+
+// openConfigCache returns the osquery config cache selected by the config,
+// or nil when caching is disabled.
+func openConfigCache(conf config.KolideConfig) (kolide.OsqueryConfigCache, error) {
+	switch conf.Osquery.ConfigCache {
+	case config.OsqueryConfigCacheNone, "":
+		return nil, nil
+	case config.OsqueryConfigCacheMemory:
+		return configcache.NewInmemConfigCache(conf.Osquery.ConfigCacheTTL, clock.C), nil
+	case config.OsqueryConfigCacheRedis:
+		redisPool := pubsub.NewRedisPool(conf.Redis.Address, conf.Redis.Password)
+		return configcache.NewRedisConfigCache(redisPool, conf.Osquery.ConfigCacheTTL), nil
+	default:
+		return nil, errors.Errorf("unknown osquery.config_cache %q, must be one of %s, %s or %s",
+			conf.Osquery.ConfigCache, config.OsqueryConfigCacheNone, config.OsqueryConfigCacheMemory, config.OsqueryConfigCacheRedis)
+	}
+}
+
+// invalidateConfigCache discards the configs cached in Redis, which outlive
+// the server and would otherwise keep serving packs from before the database
+// was restored or migrated. The memory cache lives in the server process and
+// needs no invalidation from the command line.
+func invalidateConfigCache(conf config.KolideConfig) error {
+	if conf.Osquery.ConfigCache != config.OsqueryConfigCacheRedis {
+		return nil
+	}
+	cache, err := openConfigCache(conf)
+	if err != nil {
+		return err
+	}
+	return cache.Invalidate()
+}
+
+// agentTLSConfig returns the TLS config of the agent server, which verifies
+// client certificates against the client CA when client authentication is
+// enabled.
+func agentTLSConfig(conf config.AgentServerConfig) (*tls.Config, error) {
+	var clientAuth tls.ClientAuthType
+	switch conf.ClientAuth {
+	case config.AgentClientAuthNone:
+		return nil, nil
+	case config.AgentClientAuthVerifyIfGiven:
+		clientAuth = tls.VerifyClientCertIfGiven
+	case config.AgentClientAuthRequire:
+		clientAuth = tls.RequireAndVerifyClientCert
+	default:
+		return nil, errors.Errorf("unknown agent_server.client_auth %q", conf.ClientAuth)
+	}
+
+	if !conf.TLS {
+		return nil, errors.New("agent_server.client_auth requires agent_server.tls")
+	}
+	if conf.ClientCA == "" {
+		return nil, errors.New("agent_server.client_auth requires agent_server.client_ca")
+	}
+	pem, err := ioutil.ReadFile(conf.ClientCA)
+	if err != nil {
+		return nil, errors.Wrap(err, "reading client CA")
+	}
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(pem) {
+		return nil, errors.Errorf("no certificates found in %s", conf.ClientCA)
+	}
+	return &tls.Config{ClientAuth: clientAuth, ClientCAs: pool}, nil
+}
+
+// newStores returns the datastore and query result store of the server mode,
+// which is always standalone when dev is true. In standalone mode, the
+// datastore is restored from the snapshot file if there is one, and otherwise
+// starts with the built in data, along with the example data when dev is true.
+func newStores(conf config.KolideConfig, dev bool, logger kitlog.Logger) (kolide.Datastore, kolide.QueryResultStore, error) {
+	mode := conf.Server.Mode
+	if dev {
+		mode = config.ServerModeStandalone
+	}
+
+	switch mode {
+	case config.ServerModeCluster:
+		ds, err := newDatastore(conf, logger)
+		if err != nil {
+			return nil, nil, err
+		}
+		redisPool := pubsub.NewRedisPool(conf.Redis.Address, conf.Redis.Password)
+		return ds, pubsub.NewRedisQueryResults(redisPool), nil
+
+	case config.ServerModeStandalone:
+		ds, err := inmem.New(conf)
+		if err != nil {
+			return nil, nil, err
+		}
+		if conf.Server.SnapshotFile != "" {
+			loaded, err := ds.LoadSnapshotFile(conf.Server.SnapshotFile)
+			if err != nil {
+				return nil, nil, err
+			}
+			if loaded {
+				logger.Log("msg", "loaded snapshot", "file", conf.Server.SnapshotFile)
+				return ds, pubsub.NewInmemQueryResults(), nil
+			}
+		}
+		if err := ds.MigrateData(); err != nil {
+			return nil, nil, errors.Wrap(err, "loading built in data")
+		}
+		if dev {
+			if err := ds.Initialize(); err != nil {
+				return nil, nil, errors.Wrap(err, "loading example data")
+			}
+		}
+		return ds, pubsub.NewInmemQueryResults(), nil
+
+	default:
+		return nil, nil, errors.Errorf("unknown server mode %q", mode)
+	}
+}
+
+// newDatastore returns the datastore of the configured datastore driver.
+func newDatastore(conf config.KolideConfig, logger kitlog.Logger) (kolide.Datastore, error) {
+	switch conf.Datastore.Driver {
+	case config.DatastoreDriverMySQL:
+		return mysql.New(conf.Mysql, clock.C, mysql.Logger(logger))
+	case config.DatastoreDriverSQLite:
+		return sqlite.New(conf.Sqlite, clock.C, sqlite.Logger(logger))
+	case config.DatastoreDriverPostgres:
+		return postgres.New(conf.Postgres, clock.C, postgres.Logger(logger))
+	default:
+		return nil, errors.Errorf("unknown datastore driver %q", conf.Datastore.Driver)
+	}
+}
+
 // healthz is an http handler which responds with either
 // 200 OK if the server can successfuly communicate with it's backends or
 // 500 if any of the backends are reporting an issue.
@@ -0,0 +1,75 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/WatchBeam/clock"
+	kitlog "github.com/go-kit/kit/log"
+	"github.com/kolide/kolide-ose/server/config"
+	"github.com/kolide/kolide-ose/server/kolide"
+	"github.com/kolide/kolide-ose/server/pubsub"
+	"github.com/kolide/kolide-ose/server/service"
+	"github.com/spf13/cobra"
+	"golang.org/x/net/context"
+)
+
+func createImportVulnerabilitiesCmd(configManager config.Manager) *cobra.Command {
+	var format string
+
+	var importVulnerabilitiesCmd = &cobra.Command{
+		Use:   "import-vulnerabilities <file>",
+		Short: "Import a vulnerability feed from a local file",
+		Long: `
+Import a vulnerability feed from a local file
+
+The feed is matched against the software installed on hosts, and does not
+need network access, so feeds can be copied into air-gapped environments.
+Either a JSON feed published by the NVD, optionally compressed with gzip,
+or a CSV file of CPE names and CVE IDs can be imported. The format is
+detected from the extension of the file unless --format is given.
+`,
+		Run: func(cmd *cobra.Command, args []string) {
+			if len(args) != 1 {
+				cmd.Help()
+				os.Exit(1)
+			}
+			path := args[0]
+			if format == "" {
+				format = kolide.VulnerabilityFeedNVD
+				ext := filepath.Ext(strings.TrimSuffix(path, ".gz"))
+				if strings.ToLower(ext) == ".csv" {
+					format = kolide.VulnerabilityFeedCSV
+				}
+			}
+
+			feed, err := os.Open(path)
+			if err != nil {
+				initFatal(err, "opening vulnerability feed")
+			}
+			defer feed.Close()
+
+			config := configManager.LoadConfig()
+			ds, err := newDatastore(config, kitlog.NewNopLogger())
+			if err != nil {
+				initFatal(err, "creating db connection")
+			}
+			svc, err := service.NewService(ds, pubsub.NewInmemQueryResults(), kitlog.NewNopLogger(), config, nil, clock.C, nil)
+			if err != nil {
+				initFatal(err, "creating service")
+			}
+
+			imported, err := svc.ImportVulnerabilities(context.Background(), format, feed)
+			if err != nil {
+				initFatal(err, "importing vulnerability feed")
+			}
+			fmt.Printf("Imported %d vulnerabilities\n", imported)
+		},
+	}
+
+	importVulnerabilitiesCmd.Flags().StringVar(&format, "format", "", "Format of the feed, either nvd or csv")
+
+	return importVulnerabilitiesCmd
+}
@@ -0,0 +1,249 @@
+// Package backup writes the state of a Kolide datastore to a portable
+// archive, and restores an archive into a datastore of any backend.
+//
+// An archive is a tar file holding a manifest and a JSON file for each kind
+// of record. Records keep the IDs they had in the datastore they were backed
+// up from, and references between records use those IDs. Restoring assigns
+// new IDs and rewrites the references.
+package backup
+
+import (
+	"archive/tar"
+	"encoding/json"
+	"io"
+	"math"
+	"time"
+
+	"github.com/kolide/kolide-ose/server/kolide"
+	"github.com/kolide/kolide-ose/server/version"
+	"github.com/pkg/errors"
+)
+
+// FormatVersion is the version of the archive format written by Backup.
+// Restore reads archives of this version or older. Version 2 added the
+// detail queries.
+const FormatVersion = 2
+
+// The names of the files in an archive.
+const (
+	manifestFile         = "manifest.json"
+	usersFile            = "users.json"
+	appConfigFile        = "app_config.json"
+	optionsFile          = "options.json"
+	labelsFile           = "labels.json"
+	queriesFile          = "queries.json"
+	packsFile            = "packs.json"
+	scheduledQueriesFile = "scheduled_queries.json"
+	packTargetsFile      = "pack_targets.json"
+	decoratorsFile       = "decorators.json"
+	fimFile              = "file_integrity_monitoring.json"
+	yaraFile             = "yara.json"
+	detailQueriesFile    = "detail_queries.json"
+	hostsFile            = "hosts.json"
+)
+
+// Manifest describes an archive.
+type Manifest struct {
+	FormatVersion int       `json:"format_version"`
+	KolideVersion string    `json:"kolide_version"`
+	Datastore     string    `json:"datastore"`
+	CreatedAt     time.Time `json:"created_at"`
+}
+
+// user is a user along with the password fields that are not included in
+// the JSON of a kolide.User.
+type user struct {
+	kolide.User
+	Password []byte `json:"password"`
+	Salt     string `json:"salt"`
+}
+
+// host is a host along with the enrollment fields that are not included in
+// the JSON of a kolide.Host.
+type host struct {
+	kolide.Host
+	OsqueryHostID string `json:"osquery_host_id"`
+	NodeKey       string `json:"node_key"`
+}
+
+// packTarget is a label or host that a pack targets.
+type packTarget struct {
+	PackID   uint              `json:"pack_id"`
+	Type     kolide.TargetType `json:"type"`
+	TargetID uint              `json:"target_id"`
+}
+
+// all lists every record in a single page, as the datastores otherwise
+// limit the records returned.
+var all = kolide.ListOptions{PerPage: math.MaxInt32}
+
+// Backup writes the users, app config, options, labels, queries, packs and
+// their scheduled queries and targets, decorators, file integrity monitoring
+// and YARA config, detail queries, and hosts of the datastore to w as an archive.
+func Backup(ds kolide.Datastore, w io.Writer) error {
+	files := map[string]interface{}{}
+
+	users, err := ds.ListUsers(all)
+	if err != nil {
+		return errors.Wrap(err, "listing users")
+	}
+	archivedUsers := []user{}
+	for _, u := range users {
+		archivedUsers = append(archivedUsers, user{User: *u, Password: u.Password, Salt: u.Salt})
+	}
+	files[usersFile] = archivedUsers
+
+	appConfig, err := ds.AppConfig()
+	if err != nil {
+		return errors.Wrap(err, "getting app config")
+	}
+	files[appConfigFile] = appConfig
+
+	options, err := ds.ListOptions()
+	if err != nil {
+		return errors.Wrap(err, "listing options")
+	}
+	files[optionsFile] = options
+
+	labels, err := ds.ListLabels(all)
+	if err != nil {
+		return errors.Wrap(err, "listing labels")
+	}
+	files[labelsFile] = labels
+
+	queries, err := ds.ListQueries(all, kolide.QueryFilter{})
+	if err != nil {
+		return errors.Wrap(err, "listing queries")
+	}
+
+	packs, err := ds.ListPacks(all)
+	if err != nil {
+		return errors.Wrap(err, "listing packs")
+	}
+	files[packsFile] = packs
+
+	scheduledQueries := []*kolide.ScheduledQuery{}
+	packTargets := []packTarget{}
+	for _, pack := range packs {
+		sqs, err := ds.ListScheduledQueriesInPack(pack.ID, all)
+		if err != nil {
+			return errors.Wrapf(err, "listing scheduled queries in pack %d", pack.ID)
+		}
+		scheduledQueries = append(scheduledQueries, sqs...)
+
+		labels, err := ds.ListLabelsForPack(pack.ID)
+		if err != nil {
+			return errors.Wrapf(err, "listing labels for pack %d", pack.ID)
+		}
+		for _, label := range labels {
+			packTargets = append(packTargets, packTarget{PackID: pack.ID, Type: kolide.TargetLabel, TargetID: label.ID})
+		}
+
+		hosts, err := ds.ListExplicitHostsInPack(pack.ID, all)
+		if err != nil {
+			return errors.Wrapf(err, "listing hosts in pack %d", pack.ID)
+		}
+		for _, h := range hosts {
+			packTargets = append(packTargets, packTarget{PackID: pack.ID, Type: kolide.TargetHost, TargetID: h.ID})
+		}
+	}
+	files[scheduledQueriesFile] = scheduledQueries
+	files[packTargetsFile] = packTargets
+
+	// Queries scheduled in packs are usually saved, but an unsaved query can
+	// be scheduled too, and the archive must include every query that a
+	// scheduled query refers to
+	archived := map[uint]bool{}
+	for _, q := range queries {
+		archived[q.ID] = true
+	}
+	for _, sq := range scheduledQueries {
+		if archived[sq.QueryID] {
+			continue
+		}
+		q, err := ds.Query(sq.QueryID)
+		if err != nil {
+			return errors.Wrapf(err, "getting scheduled query %d", sq.QueryID)
+		}
+		queries = append(queries, q)
+		archived[q.ID] = true
+	}
+	files[queriesFile] = queries
+
+	decorators, err := ds.ListDecorators()
+	if err != nil {
+		return errors.Wrap(err, "listing decorators")
+	}
+	files[decoratorsFile] = decorators
+
+	fim, err := ds.FIMSections()
+	if err != nil {
+		return errors.Wrap(err, "getting file integrity monitoring sections")
+	}
+	files[fimFile] = fim
+
+	yara, err := ds.YARASection()
+	if err != nil {
+		return errors.Wrap(err, "getting YARA section")
+	}
+	files[yaraFile] = yara
+
+	detailQueries, err := ds.ListDetailQueries(all)
+	if err != nil {
+		return errors.Wrap(err, "listing detail queries")
+	}
+	files[detailQueriesFile] = detailQueries
+
+	hosts, err := ds.ListHosts(all, kolide.HostFilter{})
+	if err != nil {
+		return errors.Wrap(err, "listing hosts")
+	}
+	archivedHosts := []host{}
+	for _, h := range hosts {
+		archivedHosts = append(archivedHosts, host{Host: *h, OsqueryHostID: h.OsqueryHostID, NodeKey: h.NodeKey})
+	}
+	files[hostsFile] = archivedHosts
+
+	manifest := Manifest{
+		FormatVersion: FormatVersion,
+		KolideVersion: version.Version().Version,
+		Datastore:     ds.Name(),
+		CreatedAt:     time.Now().UTC(),
+	}
+
+	tw := tar.NewWriter(w)
+	if err := writeFile(tw, manifestFile, manifest, manifest.CreatedAt); err != nil {
+		return err
+	}
+	for _, name := range []string{
+		usersFile, appConfigFile, optionsFile, labelsFile, queriesFile,
+		packsFile, scheduledQueriesFile, packTargetsFile, decoratorsFile,
+		fimFile, yaraFile, detailQueriesFile, hostsFile,
+	} {
+		if err := writeFile(tw, name, files[name], manifest.CreatedAt); err != nil {
+			return err
+		}
+	}
+	return errors.Wrap(tw.Close(), "closing archive")
+}
+
+// writeFile writes v to the archive as a JSON file.
+func writeFile(tw *tar.Writer, name string, v interface{}, modTime time.Time) error {
+	b, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return errors.Wrapf(err, "encoding %s", name)
+	}
+	header := &tar.Header{
+		Name:    name,
+		Mode:    0600,
+		Size:    int64(len(b)),
+		ModTime: modTime,
+	}
+	if err := tw.WriteHeader(header); err != nil {
+		return errors.Wrapf(err, "writing %s header", name)
+	}
+	if _, err := tw.Write(b); err != nil {
+		return errors.Wrapf(err, "writing %s", name)
+	}
+	return nil
+}
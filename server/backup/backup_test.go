@@ -0,0 +1,172 @@
+package backup
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/WatchBeam/clock"
+	"github.com/kolide/kolide-ose/server/config"
+	"github.com/kolide/kolide-ose/server/datastore/inmem"
+	"github.com/kolide/kolide-ose/server/datastore/sqlite"
+	"github.com/kolide/kolide-ose/server/kolide"
+	"github.com/kolide/kolide-ose/server/test"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBackupAndRestore(t *testing.T) {
+	src, err := inmem.New(config.TestConfig())
+	require.Nil(t, err)
+	require.Nil(t, src.MigrateData())
+
+	now := time.Date(2017, time.February, 20, 12, 0, 0, 0, time.UTC)
+
+	// Give the IDs of the source an offset, so that restoring with the
+	// archived IDs would be noticed
+	discarded := test.NewLabel(t, src, "discarded", "select 1")
+	require.Nil(t, src.DeleteLabel(discarded.ID))
+
+	user := test.NewUser(t, src, "Zach", "zwass", "zwass@kolide.co", true)
+	label := test.NewLabel(t, src, "label", "select 1 from osquery_info")
+	query := test.NewQuery(t, src, "query", "select * from time", user.ID, true)
+	unsaved := test.NewQuery(t, src, "unsaved", "select * from uptime", user.ID, false)
+	pack := test.NewPack(t, src, "pack")
+	test.NewScheduledQuery(t, src, pack.ID, query.ID, 60, true, false)
+	test.NewScheduledQuery(t, src, pack.ID, unsaved.ID, 120, false, false)
+	host := test.NewHost(t, src, "foo.local", "192.168.1.10", "key", "uuid", now)
+	host.NetworkInterfaces = []*kolide.NetworkInterface{
+		{Interface: "en0", IPAddress: "192.168.1.10"},
+		{Interface: "en1", IPAddress: "10.0.0.10"},
+	}
+	require.Nil(t, src.SaveHost(host))
+	primary := host.NetworkInterfaces[1].ID
+	host.PrimaryNetworkInterfaceID = &primary
+	require.Nil(t, src.SaveHost(host))
+	require.Nil(t, src.AddLabelToPack(label.ID, pack.ID))
+	require.Nil(t, src.AddHostToPack(host.ID, pack.ID))
+
+	opt, err := src.OptionByName("logger_plugin")
+	require.Nil(t, err)
+	opt.SetValue("filesystem")
+	require.Nil(t, src.SaveOptions([]kolide.Option{*opt}))
+
+	appConfig, err := src.AppConfig()
+	require.Nil(t, err)
+	appConfig.OrgName = "Kolide"
+	require.Nil(t, src.SaveAppConfig(appConfig))
+
+	_, err = src.NewDecorator(&kolide.Decorator{Type: kolide.DecoratorLoad, Query: "select 1"})
+	require.Nil(t, err)
+	_, err = src.NewFIMSection(&kolide.FIMSection{SectionName: "etc", Paths: []string{"/etc/%%"}})
+	require.Nil(t, err)
+	_, err = src.NewYARASignatureGroup(&kolide.YARASignatureGroup{SignatureName: "sigs", Paths: []string{"/sigs/a.sig"}})
+	require.Nil(t, err)
+	require.Nil(t, src.NewYARAFilePath("etc", "sigs"))
+	_, err = src.NewDetailQuery(&kolide.DetailQuery{
+		Name:     "users",
+		Query:    "select count(*) as count from users",
+		Interval: 3600,
+		Columns:  kolide.DetailQueryColumns{{Name: "count", Type: kolide.HostAttributeInteger}},
+	})
+	require.Nil(t, err)
+
+	var archive bytes.Buffer
+	require.Nil(t, Backup(src, &archive))
+
+	dir, err := ioutil.TempDir("", "kolide-backup")
+	require.Nil(t, err)
+	defer os.RemoveAll(dir)
+	dst, err := sqlite.New(config.SqliteConfig{Path: filepath.Join(dir, "kolide.db")}, clock.NewMockClock())
+	require.Nil(t, err)
+	defer dst.Close()
+	require.Nil(t, dst.MigrateTables())
+	require.Nil(t, dst.MigrateData())
+
+	manifest, err := Restore(dst, bytes.NewReader(archive.Bytes()))
+	require.Nil(t, err)
+	assert.Equal(t, FormatVersion, manifest.FormatVersion)
+	assert.Equal(t, "inmem", manifest.Datastore)
+
+	users, err := dst.ListUsers(kolide.ListOptions{})
+	require.Nil(t, err)
+	require.Len(t, users, 1)
+	assert.Equal(t, "zwass", users[0].Username)
+	assert.Equal(t, []byte("garbage"), users[0].Password)
+	assert.Equal(t, "garbage", users[0].Salt)
+
+	restoredConfig, err := dst.AppConfig()
+	require.Nil(t, err)
+	assert.Equal(t, "Kolide", restoredConfig.OrgName)
+
+	restoredOpt, err := dst.OptionByName("logger_plugin")
+	require.Nil(t, err)
+	assert.Equal(t, "filesystem", restoredOpt.GetValue())
+
+	labels, err := dst.ListLabels(kolide.ListOptions{})
+	require.Nil(t, err)
+	assert.Len(t, labels, 6)
+
+	queries, err := dst.ListQueries(kolide.ListOptions{}, kolide.QueryFilter{})
+	require.Nil(t, err)
+	require.Len(t, queries, 1)
+	assert.Equal(t, "query", queries[0].Name)
+	assert.Equal(t, users[0].ID, queries[0].AuthorID)
+
+	packs, err := dst.ListPacks(kolide.ListOptions{})
+	require.Nil(t, err)
+	require.Len(t, packs, 1)
+	scheduled, err := dst.ListScheduledQueriesInPack(packs[0].ID, kolide.ListOptions{})
+	require.Nil(t, err)
+	require.Len(t, scheduled, 2)
+	names := []string{scheduled[0].Name, scheduled[1].Name}
+	sort.Strings(names)
+	assert.Equal(t, []string{"query", "unsaved"}, names)
+
+	packLabels, err := dst.ListLabelsForPack(packs[0].ID)
+	require.Nil(t, err)
+	require.Len(t, packLabels, 1)
+	assert.Equal(t, "label", packLabels[0].Name)
+
+	packHosts, err := dst.ListExplicitHostsInPack(packs[0].ID, kolide.ListOptions{})
+	require.Nil(t, err)
+	require.Len(t, packHosts, 1)
+	assert.Equal(t, "foo.local", packHosts[0].HostName)
+
+	restoredHost, err := dst.AuthenticateHost("key")
+	require.Nil(t, err)
+	assert.Equal(t, host.OsqueryHostID, restoredHost.OsqueryHostID)
+	restoredHost, err = dst.Host(restoredHost.ID)
+	require.Nil(t, err)
+	require.Len(t, restoredHost.NetworkInterfaces, 2)
+	require.NotNil(t, restoredHost.PrimaryNetworkInterfaceID)
+	for _, nic := range restoredHost.NetworkInterfaces {
+		if nic.ID == *restoredHost.PrimaryNetworkInterfaceID {
+			assert.Equal(t, "10.0.0.10", nic.IPAddress)
+		}
+	}
+
+	decorators, err := dst.ListDecorators()
+	require.Nil(t, err)
+	require.Len(t, decorators, 1)
+	fim, err := dst.FIMSections()
+	require.Nil(t, err)
+	assert.Equal(t, kolide.FIMSections{"etc": {"/etc/%%"}}, fim)
+	yara, err := dst.YARASection()
+	require.Nil(t, err)
+	assert.Equal(t, map[string][]string{"sigs": {"/sigs/a.sig"}}, yara.Signatures)
+	assert.Equal(t, map[string][]string{"etc": {"sigs"}}, yara.FilePaths)
+	detailQueries, err := dst.ListDetailQueries(kolide.ListOptions{})
+	require.Nil(t, err)
+	require.Len(t, detailQueries, 1)
+	assert.Equal(t, "users", detailQueries[0].Name)
+	assert.Equal(t, kolide.DetailQueryColumns{{Name: "count", Type: kolide.HostAttributeInteger}}, detailQueries[0].Columns)
+
+	// A datastore with records is not restored into
+	_, err = Restore(dst, bytes.NewReader(archive.Bytes()))
+	assert.NotNil(t, err)
+}
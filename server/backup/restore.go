@@ -0,0 +1,410 @@
+package backup
+
+import (
+	"archive/tar"
+	"bytes"
+	"encoding/json"
+	"io"
+	"io/ioutil"
+
+	"github.com/kolide/kolide-ose/server/kolide"
+	"github.com/pkg/errors"
+)
+
+// archive is the decoded content of an archive.
+type archive struct {
+	manifest         Manifest
+	users            []user
+	appConfig        *kolide.AppConfig
+	options          []kolide.Option
+	labels           []*kolide.Label
+	queries          []*kolide.Query
+	packs            []*kolide.Pack
+	scheduledQueries []*kolide.ScheduledQuery
+	packTargets      []packTarget
+	decorators       []*kolide.Decorator
+	fim              kolide.FIMSections
+	yara             *kolide.YARASection
+	detailQueries    []*kolide.DetailQuery
+	hosts            []host
+}
+
+// readArchive reads and decodes every file of an archive.
+func readArchive(r io.Reader) (*archive, error) {
+	files := map[string][]byte{}
+	tr := tar.NewReader(r)
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, errors.Wrap(err, "reading archive")
+		}
+		b, err := ioutil.ReadAll(tr)
+		if err != nil {
+			return nil, errors.Wrapf(err, "reading %s", header.Name)
+		}
+		files[header.Name] = b
+	}
+
+	a := &archive{}
+	if err := decodeFile(files, manifestFile, &a.manifest); err != nil {
+		return nil, err
+	}
+	if a.manifest.FormatVersion < 1 || a.manifest.FormatVersion > FormatVersion {
+		return nil, errors.Errorf("unsupported archive format version %d", a.manifest.FormatVersion)
+	}
+
+	for name, v := range map[string]interface{}{
+		usersFile:            &a.users,
+		appConfigFile:        &a.appConfig,
+		optionsFile:          &a.options,
+		labelsFile:           &a.labels,
+		queriesFile:          &a.queries,
+		packsFile:            &a.packs,
+		scheduledQueriesFile: &a.scheduledQueries,
+		packTargetsFile:      &a.packTargets,
+		decoratorsFile:       &a.decorators,
+		fimFile:              &a.fim,
+		yaraFile:             &a.yara,
+		hostsFile:            &a.hosts,
+	} {
+		if err := decodeFile(files, name, v); err != nil {
+			return nil, err
+		}
+	}
+	if a.manifest.FormatVersion >= 2 {
+		if err := decodeFile(files, detailQueriesFile, &a.detailQueries); err != nil {
+			return nil, err
+		}
+	}
+	return a, nil
+}
+
+func decodeFile(files map[string][]byte, name string, v interface{}) error {
+	b, ok := files[name]
+	if !ok {
+		return errors.Errorf("archive is missing %s", name)
+	}
+	if err := json.NewDecoder(bytes.NewReader(b)).Decode(v); err != nil {
+		return errors.Wrapf(err, "decoding %s", name)
+	}
+	return nil
+}
+
+// Restore loads an archive written by Backup into the datastore, returning
+// the manifest of the archive. The datastore must be migrated and must not
+// have any users, queries, packs or hosts yet. The built in options and
+// labels are kept, with the option values of the archive.
+//
+// Records are created one at a time, so a restore that fails part way leaves
+// the records created so far in place. Restore again into a freshly prepared
+// database.
+//
+// The osquery configs cached for hosts are not touched, so callers must
+// invalidate the config cache once the restore is done.
+func Restore(ds kolide.Datastore, r io.Reader) (*Manifest, error) {
+	a, err := readArchive(r)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkEmpty(ds); err != nil {
+		return nil, err
+	}
+
+	userIDs := map[uint]uint{}
+	for _, u := range a.users {
+		archivedID := u.ID
+		restored := u.User
+		restored.ID = 0
+		restored.Password = u.Password
+		restored.Salt = u.Salt
+		if _, err := ds.NewUser(&restored); err != nil {
+			return nil, errors.Wrapf(err, "restoring user %s", u.Username)
+		}
+		userIDs[archivedID] = restored.ID
+	}
+
+	if a.appConfig != nil {
+		existing, err := ds.AppConfig()
+		if err != nil {
+			return nil, errors.Wrap(err, "getting app config")
+		}
+		appConfig := *a.appConfig
+		appConfig.ID = existing.ID
+		if err := ds.SaveAppConfig(&appConfig); err != nil {
+			return nil, errors.Wrap(err, "restoring app config")
+		}
+	}
+
+	if err := restoreOptions(ds, a.options); err != nil {
+		return nil, err
+	}
+
+	labelIDs, err := restoreLabels(ds, a.labels)
+	if err != nil {
+		return nil, err
+	}
+
+	queryIDs := map[uint]uint{}
+	for _, q := range a.queries {
+		archivedID := q.ID
+		restored := *q
+		restored.ID = 0
+		restored.AuthorID = userIDs[q.AuthorID]
+		restored.Packs = nil
+		if _, err := ds.NewQuery(&restored); err != nil {
+			return nil, errors.Wrapf(err, "restoring query %s", q.Name)
+		}
+		queryIDs[archivedID] = restored.ID
+	}
+
+	packIDs := map[uint]uint{}
+	for _, p := range a.packs {
+		archivedID := p.ID
+		restored := *p
+		restored.ID = 0
+		restored.CreatedBy = userIDs[p.CreatedBy]
+		if _, err := ds.NewPack(&restored); err != nil {
+			return nil, errors.Wrapf(err, "restoring pack %s", p.Name)
+		}
+		packIDs[archivedID] = restored.ID
+	}
+
+	for _, sq := range a.scheduledQueries {
+		packID, ok := packIDs[sq.PackID]
+		if !ok {
+			return nil, errors.Errorf("scheduled query %d is in unknown pack %d", sq.ID, sq.PackID)
+		}
+		queryID, ok := queryIDs[sq.QueryID]
+		if !ok {
+			return nil, errors.Errorf("scheduled query %d is of unknown query %d", sq.ID, sq.QueryID)
+		}
+		restored := *sq
+		restored.ID = 0
+		restored.PackID = packID
+		restored.QueryID = queryID
+		if _, err := ds.NewScheduledQuery(&restored); err != nil {
+			return nil, errors.Wrapf(err, "restoring scheduled query %d", sq.ID)
+		}
+	}
+
+	for _, d := range a.decorators {
+		restored := *d
+		restored.ID = 0
+		if _, err := ds.NewDecorator(&restored); err != nil {
+			return nil, errors.Wrapf(err, "restoring decorator %d", d.ID)
+		}
+	}
+
+	for name, paths := range a.fim {
+		if _, err := ds.NewFIMSection(&kolide.FIMSection{SectionName: name, Paths: paths}); err != nil {
+			return nil, errors.Wrapf(err, "restoring file integrity monitoring section %s", name)
+		}
+	}
+
+	if a.yara != nil {
+		for name, paths := range a.yara.Signatures {
+			if _, err := ds.NewYARASignatureGroup(&kolide.YARASignatureGroup{SignatureName: name, Paths: paths}); err != nil {
+				return nil, errors.Wrapf(err, "restoring YARA signature group %s", name)
+			}
+		}
+		for section, groups := range a.yara.FilePaths {
+			for _, group := range groups {
+				if err := ds.NewYARAFilePath(section, group); err != nil {
+					return nil, errors.Wrapf(err, "restoring YARA file path %s", section)
+				}
+			}
+		}
+	}
+
+	// Host attributes produced by detail queries are not archived, and are
+	// collected again when the hosts next check in
+	for _, q := range a.detailQueries {
+		restored := *q
+		restored.ID = 0
+		if _, err := ds.NewDetailQuery(&restored); err != nil {
+			return nil, errors.Wrapf(err, "restoring detail query %s", q.Name)
+		}
+	}
+
+	hostIDs, err := restoreHosts(ds, a.hosts)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, target := range a.packTargets {
+		packID, ok := packIDs[target.PackID]
+		if !ok {
+			return nil, errors.Errorf("pack target of unknown pack %d", target.PackID)
+		}
+		switch target.Type {
+		case kolide.TargetLabel:
+			labelID, ok := labelIDs[target.TargetID]
+			if !ok {
+				return nil, errors.Errorf("pack %d targets unknown label %d", target.PackID, target.TargetID)
+			}
+			err = ds.AddLabelToPack(labelID, packID)
+		case kolide.TargetHost:
+			hostID, ok := hostIDs[target.TargetID]
+			if !ok {
+				return nil, errors.Errorf("pack %d targets unknown host %d", target.PackID, target.TargetID)
+			}
+			err = ds.AddHostToPack(hostID, packID)
+		default:
+			return nil, errors.Errorf("pack %d has target of unknown type %d", target.PackID, target.Type)
+		}
+		if err != nil {
+			return nil, errors.Wrapf(err, "restoring target of pack %d", target.PackID)
+		}
+	}
+
+	return &a.manifest, nil
+}
+
+// checkEmpty returns an error if the datastore already has users, queries,
+// packs or hosts, as the records of the archive would be mixed with them.
+func checkEmpty(ds kolide.Datastore) error {
+	one := kolide.ListOptions{PerPage: 1}
+	users, err := ds.ListUsers(one)
+	if err != nil {
+		return errors.Wrap(err, "listing users")
+	}
+	queries, err := ds.ListQueries(one, kolide.QueryFilter{})
+	if err != nil {
+		return errors.Wrap(err, "listing queries")
+	}
+	packs, err := ds.ListPacks(one)
+	if err != nil {
+		return errors.Wrap(err, "listing packs")
+	}
+	hosts, err := ds.CountHosts(kolide.HostFilter{})
+	if err != nil {
+		return errors.Wrap(err, "counting hosts")
+	}
+	if len(users) > 0 || len(queries) > 0 || len(packs) > 0 || hosts > 0 {
+		return errors.New("the datastore is not empty, restore into a freshly prepared database")
+	}
+	return nil
+}
+
+// restoreOptions sets the values of the archived options that differ from
+// the values in the datastore. Read only options are left alone.
+func restoreOptions(ds kolide.Datastore, options []kolide.Option) error {
+	existing, err := ds.ListOptions()
+	if err != nil {
+		return errors.Wrap(err, "listing options")
+	}
+	byName := map[string]kolide.Option{}
+	for _, opt := range existing {
+		byName[opt.Name] = opt
+	}
+
+	changed := []kolide.Option{}
+	for _, opt := range options {
+		if opt.ReadOnly {
+			continue
+		}
+		current, ok := byName[opt.Name]
+		if !ok {
+			return errors.Errorf("unknown option %s", opt.Name)
+		}
+		if current.ReadOnly || current.Type != opt.Type {
+			return errors.Errorf("option %s can't be restored", opt.Name)
+		}
+		archivedValue, err := json.Marshal(opt.Value)
+		if err != nil {
+			return errors.Wrapf(err, "encoding option %s", opt.Name)
+		}
+		currentValue, err := json.Marshal(current.Value)
+		if err != nil {
+			return errors.Wrapf(err, "encoding option %s", opt.Name)
+		}
+		if bytes.Equal(archivedValue, currentValue) {
+			continue
+		}
+		current.Value = opt.Value
+		changed = append(changed, current)
+	}
+
+	if len(changed) == 0 {
+		return nil
+	}
+	return errors.Wrap(ds.SaveOptions(changed), "restoring options")
+}
+
+// restoreLabels creates the archived labels, returning the IDs of the
+// restored labels by their archived IDs. Labels that the datastore already
+// has, such as the built in labels, are matched by name rather than created.
+func restoreLabels(ds kolide.Datastore, labels []*kolide.Label) (map[uint]uint, error) {
+	existing, err := ds.ListLabels(all)
+	if err != nil {
+		return nil, errors.Wrap(err, "listing labels")
+	}
+	byName := map[string]uint{}
+	for _, l := range existing {
+		byName[l.Name] = l.ID
+	}
+
+	labelIDs := map[uint]uint{}
+	for _, l := range labels {
+		if id, ok := byName[l.Name]; ok {
+			labelIDs[l.ID] = id
+			continue
+		}
+		restored := *l
+		restored.ID = 0
+		if _, err := ds.NewLabel(&restored); err != nil {
+			return nil, errors.Wrapf(err, "restoring label %s", l.Name)
+		}
+		labelIDs[l.ID] = restored.ID
+	}
+	return labelIDs, nil
+}
+
+// restoreHosts creates the archived hosts, returning the IDs of the restored
+// hosts by their archived IDs. Label membership and other results of queries
+// are not archived, and are collected again when the hosts next check in.
+func restoreHosts(ds kolide.Datastore, hosts []host) (map[uint]uint, error) {
+	hostIDs := map[uint]uint{}
+	for _, h := range hosts {
+		restored := h.Host
+		restored.ID = 0
+		restored.OsqueryHostID = h.OsqueryHostID
+		restored.NodeKey = h.NodeKey
+
+		// Network interfaces get new IDs when the host is saved, and the
+		// datastore makes the first interface the primary one
+		nics := []*kolide.NetworkInterface{}
+		for _, nic := range h.NetworkInterfaces {
+			restoredNIC := *nic
+			restoredNIC.ID = 0
+			restoredNIC.HostID = 0
+			if h.PrimaryNetworkInterfaceID != nil && nic.ID == *h.PrimaryNetworkInterfaceID {
+				nics = append([]*kolide.NetworkInterface{&restoredNIC}, nics...)
+			} else {
+				nics = append(nics, &restoredNIC)
+			}
+		}
+		restored.NetworkInterfaces = nil
+		restored.PrimaryNetworkInterfaceID = nil
+
+		if _, err := ds.NewHost(&restored); err != nil {
+			return nil, errors.Wrapf(err, "restoring host %s", h.HostName)
+		}
+		// Only some fields are set when a host is created, the rest are set
+		// as details are collected
+		restored.NetworkInterfaces = nics
+		if err := ds.SaveHost(&restored); err != nil {
+			return nil, errors.Wrapf(err, "restoring details of host %s", h.HostName)
+		}
+		if h.ConfigHash != "" {
+			if err := ds.MarkHostsConfigFetched([]uint{restored.ID}, h.ConfigHash, h.ConfigFetchTime); err != nil {
+				return nil, errors.Wrapf(err, "restoring config fetch of host %s", h.HostName)
+			}
+		}
+		hostIDs[h.ID] = restored.ID
+	}
+	return hostIDs, nil
+}
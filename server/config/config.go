@@ -22,18 +22,123 @@ type MysqlConfig struct {
 	Database string
 }
 
+// Datastore drivers
+const (
+	DatastoreDriverMySQL    = "mysql"
+	DatastoreDriverSQLite   = "sqlite"
+	DatastoreDriverPostgres = "postgres"
+)
+
+// DatastoreConfig defines configs related to the datastore
+type DatastoreConfig struct {
+	// Driver is the database that Kolide stores state in, one of the
+	// datastore drivers.
+	Driver string
+}
+
+// SqliteConfig defines configs related to SQLite
+type SqliteConfig struct {
+	// Path is the database file, which is created if it does not exist.
+	Path string
+}
+
+// PostgresConfig defines configs related to PostgreSQL
+type PostgresConfig struct {
+	Address  string
+	Username string
+	Password string
+	Database string
+	// SSLMode is the sslmode of the connection, as accepted by libpq.
+	SSLMode string
+}
+
 // RedisConfig defines configs related to Redis
 type RedisConfig struct {
 	Address  string
 	Password string
 }
 
+// Server modes
+const (
+	// ServerModeCluster stores state in the configured datastore and Redis,
+	// which may be shared by many Kolide servers when the datastore is
+	// MySQL or PostgreSQL.
+	ServerModeCluster = "cluster"
+	// ServerModeStandalone keeps all state in the memory of a single Kolide
+	// server, optionally snapshotted to SnapshotFile.
+	ServerModeStandalone = "standalone"
+)
+
+// Osquery config caches
+const (
+	// OsqueryConfigCacheNone generates the config of a host each time it
+	// is fetched.
+	OsqueryConfigCacheNone = "none"
+	// OsqueryConfigCacheMemory caches configs in the memory of each Kolide
+	// server. A change made through one server is only seen by the others
+	// once their cached configs expire, so it suits a single server.
+	OsqueryConfigCacheMemory = "memory"
+	// OsqueryConfigCacheRedis caches configs in Redis, shared by every
+	// Kolide server.
+	OsqueryConfigCacheRedis = "redis"
+)
+
 // ServerConfig defines configs related to the Kolide server
 type ServerConfig struct {
 	Address string
 	Cert    string
 	Key     string
 	TLS     bool
+	// Mode is one of the server modes, ServerModeCluster or
+	// ServerModeStandalone.
+	Mode string
+	// SnapshotFile, in standalone mode, is the file that state is loaded
+	// from on start and saved to on shutdown. State is not persisted when
+	// it is empty.
+	SnapshotFile string
+	// ReadTimeout, WriteTimeout and IdleTimeout bound the time spent
+	// reading a request, writing a response, and waiting for the next
+	// request on a kept alive connection.
+	ReadTimeout  time.Duration
+	WriteTimeout time.Duration
+	IdleTimeout  time.Duration
+	// ShutdownTimeout is how long the server waits for requests to drain,
+	// campaigns to close and logs to flush when it is terminated.
+	ShutdownTimeout time.Duration
+	// OsqueryRoutes is whether the server serves the osquery agent API
+	// along with the admin API and UI. It may be disabled when the agent
+	// API is served by the agent server instead.
+	OsqueryRoutes bool
+}
+
+// Agent server client authentication
+const (
+	// AgentClientAuthNone does not request client certificates.
+	AgentClientAuthNone = "none"
+	// AgentClientAuthVerifyIfGiven verifies a client certificate against
+	// ClientCA when one is sent, but does not require one.
+	AgentClientAuthVerifyIfGiven = "verify_if_given"
+	// AgentClientAuthRequire requires a client certificate, verified
+	// against ClientCA.
+	AgentClientAuthRequire = "require"
+)
+
+// AgentServerConfig defines configs related to the optional listener that
+// serves only the osquery agent API, so that the agent endpoints can be
+// exposed without exposing the admin API and UI. It shares the timeouts of
+// the server.
+type AgentServerConfig struct {
+	// Address is the address that the agent API is served on. The agent
+	// server is disabled when it is empty.
+	Address string
+	Cert    string
+	Key     string
+	TLS     bool
+	// ClientAuth is one of the agent server client authentications.
+	ClientAuth string
+	// ClientCA is the PEM file of the certificate authorities that client
+	// certificates are verified against.
+	ClientCA string
 }
 
 // AuthConfig defines configs related to user authorization
@@ -63,6 +168,29 @@ type OsqueryConfig struct {
 	StatusLogFile       string
 	ResultLogFile       string
 	LabelUpdateInterval time.Duration
+	AgentLogRetention   int
+	// OfflineDuration and MIADuration are the periods that hosts can go
+	// without communication before they are considered offline or MIA.
+	OfflineDuration time.Duration
+	MIADuration     time.Duration
+	// OfflineIntervalGrace, when positive, replaces OfflineDuration for
+	// hosts that reported their check in intervals with their shortest
+	// interval multiplied by it.
+	OfflineIntervalGrace float64
+	// HostRetention, when positive, is how long hosts are kept after they
+	// were last seen before they are purged.
+	HostRetention time.Duration
+	// HostRetentionExemptLabels is a comma separated list of the names of
+	// labels whose members are never purged.
+	HostRetentionExemptLabels string
+	// ConfigCache is where the configs generated for hosts are cached, one
+	// of the OsqueryConfigCache constants, and ConfigCacheTTL is how long
+	// each config is kept.
+	ConfigCache    string
+	ConfigCacheTTL time.Duration
+	// HostSeenFlushInterval is how often the times that hosts were seen
+	// are written to the datastore.
+	HostSeenFlushInterval time.Duration
 }
 
 // LoggingConfig defines configs related to logging
@@ -77,25 +205,42 @@ type LoggingConfig struct {
 // structs, Manager.addConfigs and Manager.LoadConfig should be
 // updated to set and retrieve the configurations as appropriate.
 type KolideConfig struct {
-	Mysql   MysqlConfig
-	Redis   RedisConfig
-	Server  ServerConfig
-	Auth    AuthConfig
-	App     AppConfig
-	Session SessionConfig
-	Osquery OsqueryConfig
-	Logging LoggingConfig
+	Datastore   DatastoreConfig
+	Mysql       MysqlConfig
+	Sqlite      SqliteConfig
+	Postgres    PostgresConfig
+	Redis       RedisConfig
+	Server      ServerConfig
+	AgentServer AgentServerConfig
+	Auth        AuthConfig
+	App         AppConfig
+	Session     SessionConfig
+	Osquery     OsqueryConfig
+	Logging     LoggingConfig
 }
 
 // addConfigs adds the configuration keys and default values that will be
 // filled into the KolideConfig struct
 func (man Manager) addConfigs() {
+	// Datastore
+	man.addConfigString("datastore.driver", DatastoreDriverMySQL)
+
 	// MySQL
 	man.addConfigString("mysql.address", "localhost:3306")
 	man.addConfigString("mysql.username", "kolide")
 	man.addConfigString("mysql.password", "kolide")
 	man.addConfigString("mysql.database", "kolide")
 
+	// SQLite
+	man.addConfigString("sqlite.path", "./kolide.db")
+
+	// PostgreSQL
+	man.addConfigString("postgres.address", "localhost:5432")
+	man.addConfigString("postgres.username", "kolide")
+	man.addConfigString("postgres.password", "kolide")
+	man.addConfigString("postgres.database", "kolide")
+	man.addConfigString("postgres.sslmode", "disable")
+
 	// Redis
 	man.addConfigString("redis.address", "localhost:6379")
 	man.addConfigString("redis.password", "")
@@ -105,6 +250,21 @@ func (man Manager) addConfigs() {
 	man.addConfigString("server.cert", "./tools/osquery/kolide.crt")
 	man.addConfigString("server.key", "./tools/osquery/kolide.key")
 	man.addConfigBool("server.tls", true)
+	man.addConfigString("server.mode", ServerModeCluster)
+	man.addConfigString("server.snapshot_file", "")
+	man.addConfigDuration("server.read_timeout", 30*time.Second)
+	man.addConfigDuration("server.write_timeout", 60*time.Second)
+	man.addConfigDuration("server.idle_timeout", 120*time.Second)
+	man.addConfigDuration("server.shutdown_timeout", 30*time.Second)
+	man.addConfigBool("server.osquery_routes", true)
+
+	// Agent server
+	man.addConfigString("agent_server.address", "")
+	man.addConfigString("agent_server.cert", "./tools/osquery/kolide.crt")
+	man.addConfigString("agent_server.key", "./tools/osquery/kolide.key")
+	man.addConfigBool("agent_server.tls", true)
+	man.addConfigString("agent_server.client_auth", AgentClientAuthNone)
+	man.addConfigString("agent_server.client_ca", "")
 
 	// Auth
 	man.addConfigString("auth.jwt_key", "CHANGEME")
@@ -127,6 +287,15 @@ func (man Manager) addConfigs() {
 	man.addConfigString("osquery.status_log_file", "/tmp/osquery_status")
 	man.addConfigString("osquery.result_log_file", "/tmp/osquery_result")
 	man.addConfigDuration("osquery.label_update_interval", 1*time.Hour)
+	man.addConfigInt("osquery.agent_log_retention", 100)
+	man.addConfigDuration("osquery.offline_duration", 30*time.Minute)
+	man.addConfigDuration("osquery.mia_duration", 30*24*time.Hour)
+	man.addConfigFloat64("osquery.offline_interval_grace", 0)
+	man.addConfigDuration("osquery.host_retention", 0)
+	man.addConfigString("osquery.host_retention_exempt_labels", "")
+	man.addConfigString("osquery.config_cache", OsqueryConfigCacheNone)
+	man.addConfigDuration("osquery.config_cache_ttl", 1*time.Hour)
+	man.addConfigDuration("osquery.host_seen_flush_interval", 5*time.Second)
 
 	// Logging
 	man.addConfigBool("logging.debug", false)
@@ -140,21 +309,49 @@ func (man Manager) LoadConfig() KolideConfig {
 	man.loadConfigFile()
 
 	return KolideConfig{
+		Datastore: DatastoreConfig{
+			Driver: man.getConfigString("datastore.driver"),
+		},
 		Mysql: MysqlConfig{
 			Address:  man.getConfigString("mysql.address"),
 			Username: man.getConfigString("mysql.username"),
 			Password: man.getConfigString("mysql.password"),
 			Database: man.getConfigString("mysql.database"),
 		},
+		Sqlite: SqliteConfig{
+			Path: man.getConfigString("sqlite.path"),
+		},
+		Postgres: PostgresConfig{
+			Address:  man.getConfigString("postgres.address"),
+			Username: man.getConfigString("postgres.username"),
+			Password: man.getConfigString("postgres.password"),
+			Database: man.getConfigString("postgres.database"),
+			SSLMode:  man.getConfigString("postgres.sslmode"),
+		},
 		Redis: RedisConfig{
 			Address:  man.getConfigString("redis.address"),
 			Password: man.getConfigString("redis.password"),
 		},
 		Server: ServerConfig{
-			Address: man.getConfigString("server.address"),
-			Cert:    man.getConfigString("server.cert"),
-			Key:     man.getConfigString("server.key"),
-			TLS:     man.getConfigBool("server.tls"),
+			Address:         man.getConfigString("server.address"),
+			Cert:            man.getConfigString("server.cert"),
+			Key:             man.getConfigString("server.key"),
+			TLS:             man.getConfigBool("server.tls"),
+			Mode:            man.getConfigString("server.mode"),
+			SnapshotFile:    man.getConfigString("server.snapshot_file"),
+			ReadTimeout:     man.getConfigDuration("server.read_timeout"),
+			WriteTimeout:    man.getConfigDuration("server.write_timeout"),
+			IdleTimeout:     man.getConfigDuration("server.idle_timeout"),
+			ShutdownTimeout: man.getConfigDuration("server.shutdown_timeout"),
+			OsqueryRoutes:   man.getConfigBool("server.osquery_routes"),
+		},
+		AgentServer: AgentServerConfig{
+			Address:    man.getConfigString("agent_server.address"),
+			Cert:       man.getConfigString("agent_server.cert"),
+			Key:        man.getConfigString("agent_server.key"),
+			TLS:        man.getConfigBool("agent_server.tls"),
+			ClientAuth: man.getConfigString("agent_server.client_auth"),
+			ClientCA:   man.getConfigString("agent_server.client_ca"),
 		},
 		Auth: AuthConfig{
 			JwtKey:      man.getConfigString("auth.jwt_key"),
@@ -171,11 +368,20 @@ func (man Manager) LoadConfig() KolideConfig {
 			Duration: man.getConfigDuration("session.duration"),
 		},
 		Osquery: OsqueryConfig{
-			EnrollSecret:        man.getConfigString("osquery.enroll_secret"),
-			NodeKeySize:         man.getConfigInt("osquery.node_key_size"),
-			StatusLogFile:       man.getConfigString("osquery.status_log_file"),
-			ResultLogFile:       man.getConfigString("osquery.result_log_file"),
-			LabelUpdateInterval: man.getConfigDuration("osquery.label_update_interval"),
+			EnrollSecret:              man.getConfigString("osquery.enroll_secret"),
+			NodeKeySize:               man.getConfigInt("osquery.node_key_size"),
+			StatusLogFile:             man.getConfigString("osquery.status_log_file"),
+			ResultLogFile:             man.getConfigString("osquery.result_log_file"),
+			LabelUpdateInterval:       man.getConfigDuration("osquery.label_update_interval"),
+			AgentLogRetention:         man.getConfigInt("osquery.agent_log_retention"),
+			OfflineDuration:           man.getConfigDuration("osquery.offline_duration"),
+			MIADuration:               man.getConfigDuration("osquery.mia_duration"),
+			OfflineIntervalGrace:      man.getConfigFloat64("osquery.offline_interval_grace"),
+			HostRetention:             man.getConfigDuration("osquery.host_retention"),
+			HostRetentionExemptLabels: man.getConfigString("osquery.host_retention_exempt_labels"),
+			ConfigCache:               man.getConfigString("osquery.config_cache"),
+			ConfigCacheTTL:            man.getConfigDuration("osquery.config_cache_ttl"),
+			HostSeenFlushInterval:     man.getConfigDuration("osquery.host_seen_flush_interval"),
 		},
 		Logging: LoggingConfig{
 			Debug:         man.getConfigBool("logging.debug"),
@@ -334,6 +540,27 @@ func (man Manager) getConfigDuration(key string) time.Duration {
 	return durationVal
 }
 
+// addConfigFloat64 adds a float config to the config options
+func (man Manager) addConfigFloat64(key string, defVal float64) {
+	man.command.PersistentFlags().Float64(flagNameFromConfigKey(key), defVal, "Env: "+envNameFromConfigKey(key))
+	man.viper.BindPFlag(key, man.command.PersistentFlags().Lookup(flagNameFromConfigKey(key)))
+	man.viper.BindEnv(key, envNameFromConfigKey(key))
+
+	// Add default
+	man.addDefault(key, defVal)
+}
+
+// getConfigFloat64 retrieves a float from the loaded config
+func (man Manager) getConfigFloat64(key string) float64 {
+	interfaceVal := man.getInterfaceVal(key)
+	floatVal, err := cast.ToFloat64E(interfaceVal)
+	if err != nil {
+		panic("Unable to cast to float for key " + key + ": " + err.Error())
+	}
+
+	return floatVal
+}
+
 // loadConfigFile handles the loading of the config file.
 func (man Manager) loadConfigFile() {
 	man.viper.SetConfigType("yaml")
@@ -374,11 +601,17 @@ func TestConfig() KolideConfig {
 			Duration: 24 * 90 * time.Hour,
 		},
 		Osquery: OsqueryConfig{
-			EnrollSecret:        "",
-			NodeKeySize:         24,
-			StatusLogFile:       "",
-			ResultLogFile:       "",
-			LabelUpdateInterval: 1 * time.Hour,
+			EnrollSecret:          "",
+			NodeKeySize:           24,
+			StatusLogFile:         "",
+			ResultLogFile:         "",
+			LabelUpdateInterval:   1 * time.Hour,
+			AgentLogRetention:     100,
+			OfflineDuration:       30 * time.Minute,
+			MIADuration:           30 * 24 * time.Hour,
+			ConfigCache:           OsqueryConfigCacheNone,
+			ConfigCacheTTL:        1 * time.Hour,
+			HostSeenFlushInterval: 5 * time.Second,
 		},
 		Logging: LoggingConfig{
 			Debug:         true,
@@ -0,0 +1,25 @@
+// Package configcache implements the kolide.OsqueryConfigCache interface in
+// memory and in Redis.
+package configcache
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// key returns the cache key of a set of packs, which is the same for any
+// order of the IDs.
+func key(packIDs []uint) string {
+	ids := make([]int, 0, len(packIDs))
+	for _, id := range packIDs {
+		ids = append(ids, int(id))
+	}
+	sort.Ints(ids)
+
+	parts := make([]string, 0, len(ids))
+	for _, id := range ids {
+		parts = append(parts, strconv.Itoa(id))
+	}
+	return strings.Join(parts, ",")
+}
@@ -0,0 +1,139 @@
+package configcache
+
+import (
+	"fmt"
+	"os"
+	"reflect"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/WatchBeam/clock"
+	"github.com/garyburd/redigo/redis"
+	"github.com/kolide/kolide-ose/server/kolide"
+	"github.com/kolide/kolide-ose/server/pubsub"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func functionName(f func(*testing.T, kolide.OsqueryConfigCache)) string {
+	fullName := runtime.FuncForPC(reflect.ValueOf(f).Pointer()).Name()
+	elements := strings.Split(fullName, ".")
+	return elements[len(elements)-1]
+}
+
+var testFunctions = [...]func(*testing.T, kolide.OsqueryConfigCache){
+	testConfigCache,
+	testConfigCacheInvalidate,
+}
+
+func TestRedis(t *testing.T) {
+	if _, ok := os.LookupEnv("REDIS_TEST"); !ok {
+		t.SkipNow()
+	}
+
+	pool, teardown := setupRedis(t)
+	defer teardown()
+
+	for _, f := range testFunctions {
+		t.Run(functionName(f), func(t *testing.T) {
+			_, err := pool.Get().Do("FLUSHDB")
+			require.Nil(t, err)
+			f(t, NewRedisConfigCache(pool, time.Minute))
+		})
+	}
+}
+
+func TestInmem(t *testing.T) {
+	for _, f := range testFunctions {
+		t.Run(functionName(f), func(t *testing.T) {
+			t.Parallel()
+			f(t, NewInmemConfigCache(time.Minute, clock.C))
+		})
+	}
+}
+
+func setupRedis(t *testing.T) (pool *redis.Pool, teardown func()) {
+	var (
+		addr     = "127.0.0.1:6379"
+		password = ""
+	)
+
+	if a, ok := os.LookupEnv("REDIS_PORT_6379_TCP_ADDR"); ok {
+		addr = fmt.Sprintf("%s:6379", a)
+	}
+
+	pool = pubsub.NewRedisPool(addr, password)
+	_, err := pool.Get().Do("PING")
+	require.Nil(t, err)
+
+	return pool, func() { pool.Close() }
+}
+
+func testConfig(packName string) *kolide.OsqueryConfig {
+	return &kolide.OsqueryConfig{
+		Options: map[string]interface{}{"logger_plugin": "tls"},
+		Packs: kolide.Packs{
+			packName: kolide.PackContent{
+				Queries: kolide.Queries{
+					"time": kolide.QueryContent{Query: "select * from time", Interval: 60},
+				},
+			},
+		},
+	}
+}
+
+func testConfigCache(t *testing.T, cache kolide.OsqueryConfigCache) {
+	config, generation, err := cache.Get([]uint{1, 2})
+	require.Nil(t, err)
+	assert.Nil(t, config)
+
+	require.Nil(t, cache.Set([]uint{1, 2}, generation, testConfig("pack")))
+
+	// The order of the pack IDs does not matter
+	config, _, err = cache.Get([]uint{2, 1})
+	require.Nil(t, err)
+	require.NotNil(t, config)
+	assert.Contains(t, config.Packs, "pack")
+
+	config, _, err = cache.Get([]uint{1})
+	require.Nil(t, err)
+	assert.Nil(t, config)
+}
+
+func testConfigCacheInvalidate(t *testing.T, cache kolide.OsqueryConfigCache) {
+	_, generation, err := cache.Get([]uint{1})
+	require.Nil(t, err)
+	require.Nil(t, cache.Set([]uint{1}, generation, testConfig("pack")))
+
+	require.Nil(t, cache.Invalidate())
+	config, _, err := cache.Get([]uint{1})
+	require.Nil(t, err)
+	assert.Nil(t, config)
+
+	// A config generated before the cache was invalidated is not cached
+	require.Nil(t, cache.Set([]uint{1}, generation, testConfig("stale")))
+	config, _, err = cache.Get([]uint{1})
+	require.Nil(t, err)
+	assert.Nil(t, config)
+}
+
+func TestInmemExpiry(t *testing.T) {
+	c := clock.NewMockClock()
+	cache := NewInmemConfigCache(time.Minute, c)
+
+	_, generation, err := cache.Get([]uint{1})
+	require.Nil(t, err)
+	require.Nil(t, cache.Set([]uint{1}, generation, testConfig("pack")))
+
+	c.AddTime(59 * time.Second)
+	config, _, err := cache.Get([]uint{1})
+	require.Nil(t, err)
+	assert.NotNil(t, config)
+
+	c.AddTime(time.Second)
+	config, _, err = cache.Get([]uint{1})
+	require.Nil(t, err)
+	assert.Nil(t, config)
+}
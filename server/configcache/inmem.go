@@ -0,0 +1,73 @@
+package configcache
+
+import (
+	"sync"
+	"time"
+
+	"github.com/WatchBeam/clock"
+	"github.com/kolide/kolide-ose/server/kolide"
+)
+
+type inmemEntry struct {
+	config  *kolide.OsqueryConfig
+	expires time.Time
+}
+
+type inmemConfigCache struct {
+	mtx        sync.Mutex
+	clock      clock.Clock
+	ttl        time.Duration
+	generation int64
+	entries    map[string]inmemEntry
+}
+
+var _ kolide.OsqueryConfigCache = &inmemConfigCache{}
+
+// NewInmemConfigCache creates a config cache held in memory, keeping each
+// config for ttl.
+func NewInmemConfigCache(ttl time.Duration, c clock.Clock) *inmemConfigCache {
+	return &inmemConfigCache{
+		clock:   c,
+		ttl:     ttl,
+		entries: make(map[string]inmemEntry),
+	}
+}
+
+func (c *inmemConfigCache) Get(packIDs []uint) (*kolide.OsqueryConfig, int64, error) {
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+
+	k := key(packIDs)
+	entry, ok := c.entries[k]
+	if !ok {
+		return nil, c.generation, nil
+	}
+	if !c.clock.Now().Before(entry.expires) {
+		delete(c.entries, k)
+		return nil, c.generation, nil
+	}
+	return entry.config, c.generation, nil
+}
+
+func (c *inmemConfigCache) Set(packIDs []uint, generation int64, config *kolide.OsqueryConfig) error {
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+
+	if generation != c.generation {
+		return nil
+	}
+	c.entries[key(packIDs)] = inmemEntry{
+		config:  config,
+		expires: c.clock.Now().Add(c.ttl),
+	}
+	return nil
+}
+
+func (c *inmemConfigCache) Invalidate() error {
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+
+	c.generation++
+	c.entries = make(map[string]inmemEntry)
+	return nil
+}
@@ -0,0 +1,29 @@
+package configcache
+
+import (
+	"github.com/go-kit/kit/metrics"
+	"github.com/kolide/kolide-ose/server/kolide"
+)
+
+type metricsConfigCache struct {
+	kolide.OsqueryConfigCache
+	lookups metrics.Counter
+}
+
+// NewMetricsConfigCache wraps a config cache, counting its lookups by their
+// result, which is one of hit, miss or error.
+func NewMetricsConfigCache(cache kolide.OsqueryConfigCache, lookups metrics.Counter) kolide.OsqueryConfigCache {
+	return metricsConfigCache{OsqueryConfigCache: cache, lookups: lookups}
+}
+
+func (c metricsConfigCache) Get(packIDs []uint) (*kolide.OsqueryConfig, int64, error) {
+	config, generation, err := c.OsqueryConfigCache.Get(packIDs)
+	result := "hit"
+	if err != nil {
+		result = "error"
+	} else if config == nil {
+		result = "miss"
+	}
+	c.lookups.With("result", result).Add(1)
+	return config, generation, err
+}
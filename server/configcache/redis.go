@@ -0,0 +1,90 @@
+package configcache
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/garyburd/redigo/redis"
+	"github.com/kolide/kolide-ose/server/kolide"
+	"github.com/pkg/errors"
+)
+
+const (
+	redisGenerationKey = "osquery_config_generation"
+	redisConfigPrefix  = "osquery_config_"
+)
+
+// redisEntry is a cached config, along with the generation of the cache it
+// was cached in. Invalidating the cache increments the generation rather
+// than deleting every config, and configs of an older generation are
+// ignored until they expire.
+type redisEntry struct {
+	Generation int64                 `json:"generation"`
+	Config     *kolide.OsqueryConfig `json:"config"`
+}
+
+type redisConfigCache struct {
+	pool *redis.Pool
+	ttl  time.Duration
+}
+
+var _ kolide.OsqueryConfigCache = &redisConfigCache{}
+
+// NewRedisConfigCache creates a config cache in Redis, shared by every
+// Kolide server using the Redis server, keeping each config for ttl.
+func NewRedisConfigCache(pool *redis.Pool, ttl time.Duration) *redisConfigCache {
+	return &redisConfigCache{pool: pool, ttl: ttl}
+}
+
+func (c *redisConfigCache) Get(packIDs []uint) (*kolide.OsqueryConfig, int64, error) {
+	conn := c.pool.Get()
+	defer conn.Close()
+
+	values, err := redis.Values(conn.Do("MGET", redisGenerationKey, redisConfigPrefix+key(packIDs)))
+	if err != nil {
+		return nil, 0, errors.Wrap(err, "getting cached config")
+	}
+	var generation int64
+	var encoded []byte
+	if _, err := redis.Scan(values, &generation, &encoded); err != nil {
+		return nil, 0, errors.Wrap(err, "reading cached config")
+	}
+	if encoded == nil {
+		return nil, generation, nil
+	}
+
+	var entry redisEntry
+	if err := json.Unmarshal(encoded, &entry); err != nil {
+		return nil, 0, errors.Wrap(err, "decoding cached config")
+	}
+	if entry.Generation != generation {
+		return nil, generation, nil
+	}
+	return entry.Config, generation, nil
+}
+
+func (c *redisConfigCache) Set(packIDs []uint, generation int64, config *kolide.OsqueryConfig) error {
+	encoded, err := json.Marshal(redisEntry{Generation: generation, Config: config})
+	if err != nil {
+		return errors.Wrap(err, "encoding config")
+	}
+
+	conn := c.pool.Get()
+	defer conn.Close()
+
+	ttl := int64(c.ttl / time.Millisecond)
+	if _, err := conn.Do("SET", redisConfigPrefix+key(packIDs), encoded, "PX", ttl); err != nil {
+		return errors.Wrap(err, "caching config")
+	}
+	return nil
+}
+
+func (c *redisConfigCache) Invalidate() error {
+	conn := c.pool.Get()
+	defer conn.Close()
+
+	if _, err := conn.Do("INCR", redisGenerationKey); err != nil {
+		return errors.Wrap(err, "invalidating cached configs")
+	}
+	return nil
+}
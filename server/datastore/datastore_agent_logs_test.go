@@ -0,0 +1,121 @@
+package datastore
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/kolide/kolide-ose/server/kolide"
+	"github.com/kolide/kolide-ose/server/test"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testAgentLogs(t *testing.T, ds kolide.Datastore) {
+	h1 := test.NewHost(t, ds, "h1", "10.0.0.1", "1", "1", time.Now())
+	h2 := test.NewHost(t, ds, "h2", "10.0.0.2", "2", "2", time.Now())
+	now := time.Now().UTC().Truncate(time.Second)
+
+	newLog := func(severity, message string, line uint) *kolide.AgentLog {
+		return &kolide.AgentLog{
+			CreatedAt: now,
+			Severity:  severity,
+			Filename:  "scheduler.cpp",
+			Line:      line,
+			Message:   message,
+			Version:   "2.2.1",
+		}
+	}
+
+	err := ds.NewAgentLogs(h1.ID, []*kolide.AgentLog{
+		newLog(kolide.AgentLogSeverityInfo, "started", 10),
+		newLog(kolide.AgentLogSeverityWarning, "slow query", 20),
+		newLog(kolide.AgentLogSeverityError, "query failed", 30),
+	}, 3)
+	require.Nil(t, err)
+
+	// Info logs are counted but not stored
+	logs, err := ds.ListAgentLogs(h1.ID, kolide.ListOptions{})
+	require.Nil(t, err)
+	require.Len(t, logs, 2)
+	assert.Equal(t, "query failed", logs[0].Message)
+	assert.Equal(t, kolide.AgentLogSeverityError, logs[0].Severity)
+	assert.Equal(t, uint(30), logs[0].Line)
+	assert.Equal(t, "2.2.1", logs[0].Version)
+	assert.Equal(t, h1.ID, logs[0].HostID)
+	assert.Equal(t, "slow query", logs[1].Message)
+
+	// Only the most recent logs are retained, but all are counted
+	var more []*kolide.AgentLog
+	for i := 0; i < 3; i++ {
+		more = append(more, newLog(kolide.AgentLogSeverityError, fmt.Sprintf("failure %d", i), 40))
+	}
+	require.Nil(t, ds.NewAgentLogs(h1.ID, more, 3))
+
+	logs, err = ds.ListAgentLogs(h1.ID, kolide.ListOptions{})
+	require.Nil(t, err)
+	require.Len(t, logs, 3)
+	assert.Equal(t, "failure 2", logs[0].Message)
+	assert.Equal(t, "failure 0", logs[2].Message)
+
+	logs, err = ds.ListAgentLogs(h1.ID, kolide.ListOptions{PerPage: 1, Page: 1})
+	require.Nil(t, err)
+	require.Len(t, logs, 1)
+	assert.Equal(t, "failure 1", logs[0].Message)
+
+	// Logs that are only counted leave the stored logs alone
+	require.Nil(t, ds.NewAgentLogs(h1.ID, nil, 1))
+	require.Nil(t, ds.NewAgentLogs(h1.ID, []*kolide.AgentLog{
+		newLog(kolide.AgentLogSeverityInfo, "started", 10),
+	}, 1))
+
+	logs, err = ds.ListAgentLogs(h1.ID, kolide.ListOptions{})
+	require.Nil(t, err)
+	require.Len(t, logs, 3)
+
+	counts, err := ds.AgentLogCounts(h1.ID)
+	require.Nil(t, err)
+	assert.Equal(t, kolide.AgentLogCounts{
+		kolide.AgentLogSeverityInfo:    2,
+		kolide.AgentLogSeverityWarning: 1,
+		kolide.AgentLogSeverityError:   4,
+	}, counts)
+
+	counts, err = ds.AgentLogCounts(h2.ID)
+	require.Nil(t, err)
+	assert.Len(t, counts, 0)
+
+	err = ds.NewAgentLogs(h2.ID, []*kolide.AgentLog{
+		newLog(kolide.AgentLogSeverityError, "failure 0", 40),
+		newLog(kolide.AgentLogSeverityError, "failure 0", 40),
+		newLog(kolide.AgentLogSeverityWarning, "slow query", 20),
+	}, 100)
+	require.Nil(t, err)
+
+	summaries, err := ds.ListAgentLogSummaries("", 10)
+	require.Nil(t, err)
+	require.Len(t, summaries, 4)
+	assert.Equal(t, "failure 0", summaries[0].Message)
+	assert.Equal(t, uint(3), summaries[0].Count)
+	assert.Equal(t, uint(2), summaries[0].HostCount)
+	assert.Equal(t, uint(40), summaries[0].Line)
+	assert.Equal(t, "scheduler.cpp", summaries[0].Filename)
+	assert.Equal(t, now, summaries[0].LastSeen.UTC())
+
+	summaries, err = ds.ListAgentLogSummaries(kolide.AgentLogSeverityWarning, 10)
+	require.Nil(t, err)
+	require.Len(t, summaries, 1)
+	assert.Equal(t, "slow query", summaries[0].Message)
+	assert.Equal(t, uint(1), summaries[0].Count)
+
+	summaries, err = ds.ListAgentLogSummaries("", 1)
+	require.Nil(t, err)
+	assert.Len(t, summaries, 1)
+
+	// Logs of deleted hosts are not summarized
+	require.Nil(t, ds.DeleteHost(h2.ID))
+	summaries, err = ds.ListAgentLogSummaries("", 10)
+	require.Nil(t, err)
+	require.Len(t, summaries, 3)
+	assert.Equal(t, uint(1), summaries[0].Count)
+}
@@ -66,6 +66,35 @@ func testDistributedQueryCampaign(t *testing.T, ds kolide.Datastore) {
 
 }
 
+func testDistributedQueryCampaignHosts(t *testing.T, ds kolide.Datastore) {
+	user := test.NewUser(t, ds, "Zach", "zwass", "zwass@kolide.co", true)
+
+	mockClock := clock.NewMockClock()
+
+	query := test.NewQuery(t, ds, "test", "select * from time", user.ID, false)
+
+	c1 := test.NewCampaign(t, ds, query.ID, kolide.QueryWaiting, mockClock.Now())
+	c2 := test.NewCampaign(t, ds, query.ID, kolide.QueryWaiting, mockClock.Now())
+
+	hostIDs, err := ds.DistributedQueryCampaignHostIDs(c1.ID)
+	require.Nil(t, err)
+	assert.Empty(t, hostIDs)
+
+	require.Nil(t, ds.NewDistributedQueryCampaignHosts(c1.ID, []uint{3, 1, 2}))
+	require.Nil(t, ds.NewDistributedQueryCampaignHosts(c2.ID, []uint{4}))
+
+	// Recording a host twice should not duplicate it
+	require.Nil(t, ds.NewDistributedQueryCampaignHosts(c1.ID, []uint{2}))
+
+	hostIDs, err = ds.DistributedQueryCampaignHostIDs(c1.ID)
+	require.Nil(t, err)
+	assert.Equal(t, []uint{1, 2, 3}, hostIDs)
+
+	hostIDs, err = ds.DistributedQueryCampaignHostIDs(c2.ID)
+	require.Nil(t, err)
+	assert.Equal(t, []uint{4}, hostIDs)
+}
+
 func testCleanupDistributedQueryCampaigns(t *testing.T, ds kolide.Datastore) {
 	user := test.NewUser(t, ds, "Zach", "zwass", "zwass@kolide.co", true)
 
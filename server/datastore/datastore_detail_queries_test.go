@@ -0,0 +1,160 @@
+package datastore
+
+import (
+	"testing"
+	"time"
+
+	"github.com/kolide/kolide-ose/server/kolide"
+	"github.com/kolide/kolide-ose/server/test"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testDetailQueries(t *testing.T, ds kolide.Datastore) {
+	h1 := test.NewHost(t, ds, "h1", "10.0.0.1", "1", "1", time.Now())
+	h2 := test.NewHost(t, ds, "h2", "10.0.0.2", "2", "2", time.Now())
+	h1.Platform = "ubuntu"
+	require.Nil(t, ds.SaveHost(h1))
+	h2.Platform = "darwin"
+	require.Nil(t, ds.SaveHost(h2))
+
+	disk, err := ds.NewDetailQuery(&kolide.DetailQuery{
+		Name:     "disk",
+		Query:    "select encrypted, free_gb from disk",
+		Interval: 3600,
+		Columns: kolide.DetailQueryColumns{
+			{Name: "encrypted", Type: kolide.HostAttributeBoolean},
+			{Name: "free_gb", Type: kolide.HostAttributeInteger},
+		},
+	})
+	require.Nil(t, err)
+	require.NotZero(t, disk.ID)
+
+	kernel, err := ds.NewDetailQuery(&kolide.DetailQuery{
+		Name:     "kernel",
+		Query:    "select version from kernel_info",
+		Platform: "ubuntu,centos",
+		Interval: 600,
+		Columns: kolide.DetailQueryColumns{
+			{Name: "version", Type: kolide.HostAttributeString},
+		},
+	})
+	require.Nil(t, err)
+
+	_, err = ds.NewDetailQuery(&kolide.DetailQuery{Name: "disk", Query: "select 1", Interval: 60})
+	assert.NotNil(t, err, "names must be unique")
+
+	queries, err := ds.ListDetailQueries(kolide.ListOptions{})
+	require.Nil(t, err)
+	require.Len(t, queries, 2)
+
+	loaded, err := ds.DetailQuery(kernel.ID)
+	require.Nil(t, err)
+	assert.Equal(t, "ubuntu,centos", loaded.Platform)
+	assert.Equal(t, uint(600), loaded.Interval)
+	assert.Equal(t, kernel.Columns, loaded.Columns)
+
+	loaded.Interval = 1200
+	require.Nil(t, ds.SaveDetailQuery(loaded))
+	loaded, err = ds.DetailQuery(kernel.ID)
+	require.Nil(t, err)
+	assert.Equal(t, uint(1200), loaded.Interval)
+
+	// Queries run on the hosts of their platforms until they have run
+	// within their interval
+	now := time.Now().UTC().Truncate(time.Second)
+	forHost, err := ds.DetailQueriesForHost(h1, now)
+	require.Nil(t, err)
+	assert.Equal(t, map[uint]string{disk.ID: disk.Query, kernel.ID: kernel.Query}, forHost)
+	forHost, err = ds.DetailQueriesForHost(h2, now)
+	require.Nil(t, err)
+	assert.Equal(t, map[uint]string{disk.ID: disk.Query}, forHost)
+
+	require.Nil(t, ds.RecordHostAttributes(h1.ID, disk.ID, []kolide.HostAttribute{
+		{Key: "disk.encrypted", Type: kolide.HostAttributeBoolean, Value: "1"},
+		{Key: "disk.free_gb", Type: kolide.HostAttributeInteger, Value: "120"},
+	}, now))
+	require.Nil(t, ds.RecordHostAttributes(h1.ID, kernel.ID, []kolide.HostAttribute{
+		{Key: "kernel.version", Type: kolide.HostAttributeString, Value: "4.4.0"},
+	}, now))
+	require.Nil(t, ds.RecordHostAttributes(h2.ID, disk.ID, []kolide.HostAttribute{
+		{Key: "disk.encrypted", Type: kolide.HostAttributeBoolean, Value: "0"},
+		{Key: "disk.free_gb", Type: kolide.HostAttributeInteger, Value: "9"},
+	}, now))
+
+	forHost, err = ds.DetailQueriesForHost(h1, now.Add(30*time.Minute))
+	require.Nil(t, err)
+	assert.Equal(t, map[uint]string{kernel.ID: kernel.Query}, forHost)
+	forHost, err = ds.DetailQueriesForHost(h1, now.Add(2*time.Hour))
+	require.Nil(t, err)
+	assert.Len(t, forHost, 2)
+
+	attributes, err := ds.HostAttributes(h1.ID)
+	require.Nil(t, err)
+	require.Len(t, attributes, 3)
+	assert.Equal(t, "disk.encrypted", attributes[0].Key)
+	assert.Equal(t, "1", attributes[0].Value)
+	assert.Equal(t, disk.ID, attributes[0].DetailQueryID)
+	assert.Equal(t, "disk.free_gb", attributes[1].Key)
+	assert.Equal(t, "kernel.version", attributes[2].Key)
+	assert.Equal(t, kolide.HostAttributeString, attributes[2].Type)
+
+	// Filters compare numeric attributes as numbers
+	filterHosts := func(filters ...string) []string {
+		filter := kolide.HostFilter{}
+		for _, f := range filters {
+			parsed, err := kolide.ParseHostAttributeFilter(f)
+			require.Nil(t, err)
+			filter.Attributes = append(filter.Attributes, parsed)
+		}
+		hosts, err := ds.ListHosts(kolide.ListOptions{}, filter)
+		require.Nil(t, err)
+		names := []string{}
+		for _, h := range hosts {
+			names = append(names, h.HostName)
+		}
+		return names
+	}
+	assert.Equal(t, []string{"h1", "h2"}, filterHosts())
+	assert.Equal(t, []string{"h1"}, filterHosts("disk.encrypted=true"))
+	assert.Equal(t, []string{"h2"}, filterHosts("disk.encrypted!=1"))
+	assert.Equal(t, []string{"h1"}, filterHosts("disk.free_gb>10"))
+	assert.Equal(t, []string{"h2"}, filterHosts("disk.free_gb<=9"))
+	assert.Equal(t, []string{}, filterHosts("disk.free_gb>abc"))
+	assert.Equal(t, []string{"h1"}, filterHosts("kernel.version=4.4.0"))
+	assert.Equal(t, []string{"h1"}, filterHosts("kernel.version>=4", "disk.free_gb>100"))
+	assert.Equal(t, []string{}, filterHosts("kernel.version>=4", "disk.free_gb<100"))
+
+	// Operators are never written into a query unless they are known
+	injected := kolide.HostFilter{Attributes: []kolide.HostAttributeFilter{
+		{Key: "disk.free_gb", Operator: "= 1 OR 1 = 1 OR 1 =", Value: "0"},
+	}}
+	_, err = ds.ListHosts(kolide.ListOptions{}, injected)
+	assert.NotNil(t, err)
+	_, err = ds.CountHosts(injected)
+	assert.NotNil(t, err)
+
+	// Recording replaces the attributes produced by the query
+	require.Nil(t, ds.RecordHostAttributes(h1.ID, disk.ID, []kolide.HostAttribute{
+		{Key: "disk.free_gb", Type: kolide.HostAttributeInteger, Value: "80"},
+	}, now))
+	attributes, err = ds.HostAttributes(h1.ID)
+	require.Nil(t, err)
+	require.Len(t, attributes, 2)
+	assert.Equal(t, "disk.free_gb", attributes[0].Key)
+	assert.Equal(t, "80", attributes[0].Value)
+
+	// Deleting a query removes its attributes
+	require.Nil(t, ds.DeleteDetailQuery(disk.ID))
+	_, err = ds.DetailQuery(disk.ID)
+	assert.NotNil(t, err)
+	attributes, err = ds.HostAttributes(h1.ID)
+	require.Nil(t, err)
+	require.Len(t, attributes, 1)
+	assert.Equal(t, "kernel.version", attributes[0].Key)
+	attributes, err = ds.HostAttributes(h2.ID)
+	require.Nil(t, err)
+	assert.Len(t, attributes, 0)
+
+	assert.NotNil(t, ds.DeleteDetailQuery(disk.ID))
+}
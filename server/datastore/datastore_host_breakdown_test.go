@@ -0,0 +1,70 @@
+package datastore
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/kolide/kolide-ose/server/kolide"
+	"github.com/kolide/kolide-ose/server/test"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testHostBreakdown(t *testing.T, ds kolide.Datastore) {
+	breakdown, err := ds.HostBreakdown(0)
+	require.Nil(t, err)
+	assert.Equal(t, uint(0), breakdown.TotalCount)
+	assert.Empty(t, breakdown.Platforms)
+	assert.Empty(t, breakdown.HardwareModels)
+
+	inventory := []struct {
+		platform, osquery, vendor, model string
+		memory                           int
+	}{
+		{"ubuntu", "2.2.1", "Dell Inc.", "XPS 13", 8 << 30},
+		{"ubuntu", "2.1.2", "Dell Inc.", "XPS 13", 16 << 30},
+		{"darwin", "2.2.1", "Apple Inc.", "MacBookPro13,1", 6 << 30},
+	}
+	hosts := []*kolide.Host{}
+	for i, inv := range inventory {
+		h := test.NewHost(t, ds, inv.platform, "", fmt.Sprint(i), fmt.Sprint(i), time.Now())
+		h.Platform = inv.platform
+		h.OsqueryVersion = inv.osquery
+		h.HardwareVendor = inv.vendor
+		h.HardwareModel = inv.model
+		h.PhysicalMemory = inv.memory
+		require.Nil(t, ds.SaveHost(h))
+		hosts = append(hosts, h)
+	}
+
+	breakdown, err = ds.HostBreakdown(0)
+	require.Nil(t, err)
+	assert.Equal(t, uint(3), breakdown.TotalCount)
+	assert.Equal(t, []*kolide.HostCount{
+		{Value: "ubuntu", Count: 2},
+		{Value: "darwin", Count: 1},
+	}, breakdown.Platforms)
+	assert.Equal(t, []*kolide.HostCount{
+		{Value: "2.2.1", Count: 2},
+		{Value: "2.1.2", Count: 1},
+	}, breakdown.OsqueryVersions)
+	assert.Equal(t, []*kolide.ModelCount{
+		{Vendor: "Dell Inc.", Model: "XPS 13", Count: 2},
+		{Vendor: "Apple Inc.", Model: "MacBookPro13,1", Count: 1},
+	}, breakdown.HardwareModels)
+	assert.Equal(t, []*kolide.HostCount{
+		{Value: "8GB", Count: 2},
+		{Value: "16GB", Count: 1},
+	}, breakdown.Memory)
+
+	label := test.NewLabel(t, ds, "ubuntu", "select 1")
+	require.Nil(t, ds.RecordLabelQueryExecutions(hosts[0], map[uint]bool{label.ID: true}, time.Now()))
+	require.Nil(t, ds.RecordLabelQueryExecutions(hosts[2], map[uint]bool{label.ID: false}, time.Now()))
+
+	breakdown, err = ds.HostBreakdown(label.ID)
+	require.Nil(t, err)
+	assert.Equal(t, uint(1), breakdown.TotalCount)
+	assert.Equal(t, []*kolide.HostCount{{Value: "ubuntu", Count: 1}}, breakdown.Platforms)
+	assert.Equal(t, []*kolide.HostCount{{Value: "8GB", Count: 1}}, breakdown.Memory)
+}
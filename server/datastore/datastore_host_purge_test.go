@@ -0,0 +1,102 @@
+package datastore
+
+import (
+	"testing"
+	"time"
+
+	"github.com/kolide/kolide-ose/server/kolide"
+	"github.com/kolide/kolide-ose/server/test"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testPurgeHosts(t *testing.T, ds kolide.Datastore) {
+	now := time.Now().Truncate(time.Second)
+	active := test.NewHost(t, ds, "active", "", "1", "1", now)
+	gone := test.NewHost(t, ds, "gone", "", "2", "2", now.Add(-100*24*time.Hour))
+	exempt := test.NewHost(t, ds, "exempt", "", "3", "3", now.Add(-100*24*time.Hour))
+
+	label := test.NewLabel(t, ds, "all", "select 1")
+	servers := test.NewLabel(t, ds, "servers", "select 1")
+	require.Nil(t, ds.RecordLabelQueryExecutions(active, map[uint]bool{label.ID: true}, now))
+	require.Nil(t, ds.RecordLabelQueryExecutions(gone, map[uint]bool{label.ID: true}, now))
+	require.Nil(t, ds.RecordLabelQueryExecutions(exempt, map[uint]bool{label.ID: true, servers.ID: true}, now))
+
+	pack := test.NewPack(t, ds, "monitoring")
+	require.Nil(t, ds.AddHostToPack(gone.ID, pack.ID))
+	require.Nil(t, ds.AddHostToPack(active.ID, pack.ID))
+
+	require.Nil(t, ds.NewAgentLogs(gone.ID, []*kolide.AgentLog{
+		{Severity: kolide.AgentLogSeverityError, Message: "failed"},
+	}, 10))
+	require.Nil(t, ds.SaveHostSoftware(gone.ID, "deb_packages", []kolide.Software{
+		{Name: "bash", Version: "4.3"},
+	}, now))
+
+	user := test.NewUser(t, ds, "Zach", "zwass", "zwass@kolide.co", true)
+	query := test.NewQuery(t, ds, "time", "select * from time", user.ID, true)
+	campaign := test.NewCampaign(t, ds, query.ID, kolide.QueryComplete, now)
+	test.AddHostToCampaign(t, ds, campaign.ID, gone.ID)
+	test.AddHostToCampaign(t, ds, campaign.ID, active.ID)
+	require.Nil(t, ds.NewDistributedQueryCampaignHosts(campaign.ID, []uint{gone.ID, active.ID}))
+	test.NewExecution(t, ds, campaign.ID, gone.ID)
+	test.NewExecution(t, ds, campaign.ID, active.ID)
+
+	seenBefore := now.Add(-90 * 24 * time.Hour)
+	hosts, err := ds.ListPurgeableHosts(seenBefore, nil)
+	require.Nil(t, err)
+	require.Len(t, hosts, 2)
+	assert.Equal(t, gone.ID, hosts[0].ID)
+	assert.Equal(t, exempt.ID, hosts[1].ID)
+
+	hosts, err = ds.ListPurgeableHosts(seenBefore, []uint{servers.ID})
+	require.Nil(t, err)
+	require.Len(t, hosts, 1)
+	assert.Equal(t, gone.ID, hosts[0].ID)
+
+	// Hosts seen after seenBefore are not purged even when listed
+	purged, err := ds.PurgeHosts([]uint{gone.ID, active.ID}, seenBefore)
+	require.Nil(t, err)
+	assert.Equal(t, uint(1), purged)
+
+	_, err = ds.Host(active.ID)
+	assert.Nil(t, err)
+
+	_, err = ds.Host(gone.ID)
+	assert.NotNil(t, err)
+
+	members, err := ds.ListHostsInLabel(label.ID)
+	require.Nil(t, err)
+	assert.Len(t, members, 2)
+
+	packHosts, err := ds.ListHostsInPack(pack.ID, kolide.ListOptions{})
+	require.Nil(t, err)
+	require.Len(t, packHosts, 1)
+	assert.Equal(t, active.ID, packHosts[0].ID)
+
+	counts, err := ds.AgentLogCounts(gone.ID)
+	require.Nil(t, err)
+	assert.Empty(t, counts)
+
+	software, err := ds.ListHostSoftware(gone.ID, kolide.ListOptions{})
+	require.Nil(t, err)
+	assert.Empty(t, software)
+
+	targetHostIDs, _, err := ds.DistributedQueryCampaignTargetIDs(campaign.ID)
+	require.Nil(t, err)
+	assert.Equal(t, []uint{active.ID}, targetHostIDs)
+
+	campaignHostIDs, err := ds.DistributedQueryCampaignHostIDs(campaign.ID)
+	require.Nil(t, err)
+	assert.Equal(t, []uint{active.ID}, campaignHostIDs)
+
+	// Only the execution of the remaining host is left to clean up
+	_, deleted, err := ds.CleanupDistributedQueryCampaigns(now)
+	require.Nil(t, err)
+	assert.Equal(t, uint(1), deleted)
+
+	// Purging hosts that do not exist deletes nothing
+	purged, err = ds.PurgeHosts([]uint{gone.ID}, seenBefore)
+	require.Nil(t, err)
+	assert.Equal(t, uint(0), purged)
+}
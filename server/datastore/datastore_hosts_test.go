@@ -177,7 +177,7 @@ func testListHost(t *testing.T, ds kolide.Datastore) {
 	err = ds.SaveHost(hosts[3])
 	require.Nil(t, err)
 
-	hosts2, err := ds.ListHosts(kolide.ListOptions{})
+	hosts2, err := ds.ListHosts(kolide.ListOptions{}, kolide.HostFilter{})
 	require.Nil(t, err)
 	assert.Equal(t, len(hosts), len(hosts2))
 
@@ -189,11 +189,11 @@ func testListHost(t *testing.T, ds kolide.Datastore) {
 
 	err = ds.DeleteHost(hosts[0].ID)
 	require.Nil(t, err)
-	hosts2, err = ds.ListHosts(kolide.ListOptions{})
+	hosts2, err = ds.ListHosts(kolide.ListOptions{}, kolide.HostFilter{})
 	require.Nil(t, err)
 	assert.Equal(t, len(hosts)-1, len(hosts2))
 
-	hosts, err = ds.ListHosts(kolide.ListOptions{})
+	hosts, err = ds.ListHosts(kolide.ListOptions{}, kolide.HostFilter{})
 	require.Nil(t, err)
 	require.Equal(t, len(hosts2), len(hosts))
 	hosts[0].NetworkInterfaces = []*kolide.NetworkInterface{
@@ -209,7 +209,7 @@ func testListHost(t *testing.T, ds kolide.Datastore) {
 
 	err = ds.SaveHost(hosts[0])
 	require.Nil(t, err)
-	hosts2, err = ds.ListHosts(kolide.ListOptions{})
+	hosts2, err = ds.ListHosts(kolide.ListOptions{}, kolide.HostFilter{})
 	require.Nil(t, err)
 	require.Equal(t, hosts[0].ID, hosts2[0].ID)
 	assert.Equal(t, len(hosts[0].NetworkInterfaces), len(hosts2[0].NetworkInterfaces))
@@ -217,6 +217,84 @@ func testListHost(t *testing.T, ds kolide.Datastore) {
 	assert.Equal(t, hosts[0].ID, hosts2[0].NetworkInterfaces[0].HostID)
 }
 
+func testListHostsFilter(t *testing.T, ds kolide.Datastore) {
+	now := time.Now().UTC().Truncate(time.Second)
+	h1 := test.NewHost(t, ds, "web-1.example.com", "", "1", "uuid-aaaa", now)
+	h2 := test.NewHost(t, ds, "web-2.example.com", "", "2", "uuid-bbbb", now.Add(-2*time.Hour))
+	h3 := test.NewHost(t, ds, "db-1.example.com", "", "3", "uuid-cccc", now.Add(-48*time.Hour))
+
+	h1.Platform = "ubuntu"
+	h1.OSVersion = "Ubuntu 16.04"
+	h1.OsqueryVersion = "2.2.1"
+	h1.HardwareSerial = "C02SERIAL"
+	h1.NetworkInterfaces = []*kolide.NetworkInterface{
+		{Interface: "en0", IPAddress: "10.0.0.1", MAC: "aa:bb:cc:dd:ee:ff"},
+	}
+	require.Nil(t, ds.SaveHost(h1))
+	h2.Platform = "ubuntu"
+	h2.OSVersion = "Ubuntu 14.04"
+	h2.OsqueryVersion = "2.2.1"
+	require.Nil(t, ds.SaveHost(h2))
+	h3.Platform = "centos"
+	h3.OsqueryVersion = "2.1.2"
+	require.Nil(t, ds.SaveHost(h3))
+
+	label := test.NewLabel(t, ds, "web", "select 1")
+	require.Nil(t, ds.RecordLabelQueryExecutions(h2, map[uint]bool{label.ID: true}, now))
+	require.Nil(t, ds.RecordLabelQueryExecutions(h3, map[uint]bool{label.ID: false}, now))
+
+	var filterTests = []struct {
+		filter   kolide.HostFilter
+		expected []uint
+	}{
+		{kolide.HostFilter{}, []uint{h1.ID, h2.ID, h3.ID}},
+		{kolide.HostFilter{Platform: "ubuntu"}, []uint{h1.ID, h2.ID}},
+		{kolide.HostFilter{OSVersion: "Ubuntu 14.04"}, []uint{h2.ID}},
+		{kolide.HostFilter{OsqueryVersion: "2.2.1", Platform: "centos"}, []uint{}},
+		{kolide.HostFilter{LabelID: label.ID}, []uint{h2.ID}},
+		{kolide.HostFilter{SeenAfter: now.Add(-3 * time.Hour)}, []uint{h1.ID, h2.ID}},
+		{kolide.HostFilter{SeenBefore: now.Add(-2 * time.Hour)}, []uint{h2.ID, h3.ID}},
+		{kolide.HostFilter{SeenAfter: now.Add(-3 * time.Hour), SeenBefore: now.Add(-time.Hour)}, []uint{h2.ID}},
+		{kolide.HostFilter{Query: "WEB"}, []uint{h1.ID, h2.ID}},
+		{kolide.HostFilter{Query: "cccc"}, []uint{h3.ID}},
+		{kolide.HostFilter{Query: "c02serial"}, []uint{h1.ID}},
+		{kolide.HostFilter{Query: "10.0.0"}, []uint{h1.ID}},
+		{kolide.HostFilter{Query: "dd:ee"}, []uint{h1.ID}},
+		{kolide.HostFilter{Query: "%"}, []uint{}},
+	}
+	for _, tt := range filterTests {
+		hosts, err := ds.ListHosts(kolide.ListOptions{}, tt.filter)
+		require.Nil(t, err)
+		ids := []uint{}
+		for _, h := range hosts {
+			ids = append(ids, h.ID)
+		}
+		assert.Equal(t, tt.expected, ids, "%+v", tt.filter)
+
+		count, err := ds.CountHosts(tt.filter)
+		require.Nil(t, err)
+		assert.Equal(t, uint(len(tt.expected)), count, "%+v", tt.filter)
+	}
+
+	hosts, err := ds.ListHosts(kolide.ListOptions{
+		OrderKey:       "seen_time",
+		OrderDirection: kolide.OrderDescending,
+		PerPage:        2,
+		Page:           1,
+	}, kolide.HostFilter{})
+	require.Nil(t, err)
+	require.Len(t, hosts, 1)
+	assert.Equal(t, h3.ID, hosts[0].ID)
+
+	hosts, err = ds.ListHosts(kolide.ListOptions{OrderKey: "hostname"}, kolide.HostFilter{})
+	require.Nil(t, err)
+	require.Len(t, hosts, 3)
+	assert.Equal(t, h3.ID, hosts[0].ID)
+
+	_, err = ds.ListHosts(kolide.ListOptions{OrderKey: "nope"}, kolide.HostFilter{})
+	assert.NotNil(t, err)
+}
+
 func testEnrollHost(t *testing.T, ds kolide.Datastore) {
 	var hosts []*kolide.Host
 	for _, tt := range enrollTests {
@@ -428,6 +506,7 @@ func testDistributedQueriesForHost(t *testing.T, ds kolide.Datastore) {
 	}
 	target, err = ds.NewDistributedQueryCampaignTarget(target)
 	require.Nil(t, err)
+	require.Nil(t, ds.NewDistributedQueryCampaignHosts(c1.ID, []uint{h1.ID, h2.ID}))
 
 	// All should have the query now
 	queries, err = ds.DistributedQueriesForHost(h1)
@@ -472,6 +551,7 @@ func testDistributedQueriesForHost(t *testing.T, ds kolide.Datastore) {
 	}
 	_, err = ds.NewDistributedQueryCampaignTarget(target)
 	require.Nil(t, err)
+	require.Nil(t, ds.NewDistributedQueryCampaignHosts(c2.ID, []uint{h1.ID}))
 
 	// Check for correct queries
 	queries, err = ds.DistributedQueriesForHost(h1)
@@ -498,10 +578,52 @@ func testDistributedQueriesForHost(t *testing.T, ds kolide.Datastore) {
 	assert.Empty(t, queries)
 }
 
+func testDistributedQueriesForCampaignHosts(t *testing.T, ds kolide.Datastore) {
+	user := test.NewUser(t, ds, "Zach", "zwass", "zwass@kolide.co", true)
+
+	h1 := test.NewHost(t, ds, "foo.local", "192.168.1.10", "1", "1", time.Now())
+	h2 := test.NewHost(t, ds, "bar.local", "192.168.1.11", "2", "2", time.Now())
+
+	l1, err := ds.NewLabel(&kolide.Label{
+		Name:  "label foo",
+		Query: "query1",
+	})
+	require.Nil(t, err)
+	require.Nil(t, ds.RecordLabelQueryExecutions(h1, map[uint]bool{l1.ID: true}, time.Now()))
+
+	q1 := test.NewQuery(t, ds, "bar", "select * from bar", user.ID, false)
+	c1 := test.NewCampaign(t, ds, q1.ID, kolide.QueryRunning, time.Now())
+	test.AddLabelToCampaign(t, ds, c1.ID, l1.ID)
+
+	// Targets that were never resolved to hosts don't send the query
+	queries, err := ds.DistributedQueriesForHost(h1)
+	require.Nil(t, err)
+	assert.Empty(t, queries)
+
+	require.Nil(t, ds.NewDistributedQueryCampaignHosts(c1.ID, []uint{h1.ID}))
+
+	// A host that joins the label after the campaign was created does not
+	// get the query
+	require.Nil(t, ds.RecordLabelQueryExecutions(h2, map[uint]bool{l1.ID: true}, time.Now()))
+
+	queries, err = ds.DistributedQueriesForHost(h1)
+	require.Nil(t, err)
+	assert.Equal(t, map[uint]string{c1.ID: "select * from bar"}, queries)
+	queries, err = ds.DistributedQueriesForHost(h2)
+	require.Nil(t, err)
+	assert.Empty(t, queries)
+
+	// A host that left the label still gets the query
+	require.Nil(t, ds.RecordLabelQueryExecutions(h1, map[uint]bool{l1.ID: false}, time.Now()))
+	queries, err = ds.DistributedQueriesForHost(h1)
+	require.Nil(t, err)
+	assert.Equal(t, map[uint]string{c1.ID: "select * from bar"}, queries)
+}
+
 func testGenerateHostStatusStatistics(t *testing.T, ds kolide.Datastore) {
 	mockClock := clock.NewMockClock()
 
-	online, offline, mia, err := ds.GenerateHostStatusStatistics(mockClock.Now())
+	online, offline, mia, err := ds.GenerateHostStatusStatistics(mockClock.Now(), kolide.DefaultHostStatusThresholds)
 	assert.Nil(t, err)
 	assert.Equal(t, uint(0), online)
 	assert.Equal(t, uint(0), offline)
@@ -551,11 +673,42 @@ func testGenerateHostStatusStatistics(t *testing.T, ds kolide.Datastore) {
 	})
 	assert.Nil(t, err)
 
-	online, offline, mia, err = ds.GenerateHostStatusStatistics(mockClock.Now())
+	online, offline, mia, err = ds.GenerateHostStatusStatistics(mockClock.Now(), kolide.DefaultHostStatusThresholds)
 	assert.Nil(t, err)
 	assert.Equal(t, uint(2), online)
 	assert.Equal(t, uint(1), offline)
 	assert.Equal(t, uint(1), mia)
+
+	// With thresholds derived from the check in intervals, the host on a
+	// short interval is offline and the one on a long interval is online
+	h2, err := ds.Host(2)
+	require.Nil(t, err)
+	h2.DistributedInterval = 10
+	require.Nil(t, ds.SaveHost(h2))
+	h3, err := ds.Host(3)
+	require.Nil(t, err)
+	h3.DistributedInterval = 3600
+	h3.ConfigRefresh = 7200
+	require.Nil(t, ds.SaveHost(h3))
+
+	thresholds := kolide.DefaultHostStatusThresholds
+	thresholds.IntervalGrace = 3
+	online, offline, mia, err = ds.GenerateHostStatusStatistics(mockClock.Now(), thresholds)
+	assert.Nil(t, err)
+	assert.Equal(t, uint(2), online)
+	assert.Equal(t, uint(1), offline)
+	assert.Equal(t, uint(1), mia)
+
+	filter := kolide.HostFilter{
+		Status:           kolide.StatusOffline,
+		StatusTime:       mockClock.Now(),
+		StatusThresholds: thresholds,
+	}
+	hosts, err := ds.ListHosts(kolide.ListOptions{}, filter)
+	require.Nil(t, err)
+	require.Len(t, hosts, 1)
+	assert.Equal(t, uint(2), hosts[0].ID)
+	assert.Equal(t, uint(10), hosts[0].DistributedInterval)
 }
 
 func testMarkHostSeen(t *testing.T, ds kolide.Datastore) {
@@ -591,3 +744,74 @@ func testMarkHostSeen(t *testing.T, ds kolide.Datastore) {
 		assert.WithinDuration(t, anHourAgo, h1Verify.SeenTime, time.Second)
 	}
 }
+
+func testMarkHostsSeen(t *testing.T, ds kolide.Datastore) {
+	mockClock := clock.NewMockClock()
+
+	anHourAgo := mockClock.Now().Add(-1 * time.Hour).UTC()
+	aDayAgo := mockClock.Now().Add(-24 * time.Hour).UTC()
+
+	var hostIDs []uint
+	for i := 0; i < 3; i++ {
+		h, err := ds.NewHost(&kolide.Host{
+			OsqueryHostID:    strconv.Itoa(i),
+			UUID:             strconv.Itoa(i),
+			NodeKey:          strconv.Itoa(i),
+			DetailUpdateTime: aDayAgo,
+			SeenTime:         aDayAgo,
+		})
+		require.Nil(t, err)
+		hostIDs = append(hostIDs, h.ID)
+	}
+	loaded, err := ds.Host(hostIDs[0])
+	require.Nil(t, err)
+	stale := *loaded
+
+	require.Nil(t, ds.MarkHostsSeen(hostIDs[:2], anHourAgo))
+	require.Nil(t, ds.MarkHostsSeen(nil, anHourAgo))
+
+	// Saving a host loaded before it was marked seen keeps the seen time
+	stale.SeenTime = aDayAgo
+	require.Nil(t, ds.SaveHost(&stale))
+
+	for i, id := range hostIDs {
+		h, err := ds.Host(id)
+		require.Nil(t, err)
+		if i < 2 {
+			assert.WithinDuration(t, anHourAgo, h.SeenTime, time.Second)
+		} else {
+			assert.WithinDuration(t, aDayAgo, h.SeenTime, time.Second)
+		}
+	}
+}
+
+func testMarkHostsConfigFetched(t *testing.T, ds kolide.Datastore) {
+	mockClock := clock.NewMockClock()
+	anHourAgo := mockClock.Now().Add(-1 * time.Hour).UTC()
+
+	var hostIDs []uint
+	for i := 0; i < 3; i++ {
+		h, err := ds.NewHost(&kolide.Host{
+			OsqueryHostID:    strconv.Itoa(i),
+			UUID:             strconv.Itoa(i),
+			NodeKey:          strconv.Itoa(i),
+			DetailUpdateTime: anHourAgo,
+			SeenTime:         anHourAgo,
+		})
+		require.Nil(t, err)
+		hostIDs = append(hostIDs, h.ID)
+	}
+
+	require.Nil(t, ds.MarkHostsConfigFetched(hostIDs[:2], "hash", anHourAgo))
+
+	for i, id := range hostIDs {
+		h, err := ds.Host(id)
+		require.Nil(t, err)
+		if i < 2 {
+			assert.Equal(t, "hash", h.ConfigHash)
+			assert.WithinDuration(t, anHourAgo, h.ConfigFetchTime, time.Second)
+		} else {
+			assert.Empty(t, h.ConfigHash)
+		}
+	}
+}
@@ -0,0 +1,79 @@
+package datastore
+
+import (
+	"testing"
+	"time"
+
+	"github.com/kolide/kolide-ose/server/kolide"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testJobs(t *testing.T, ds kolide.Datastore) {
+	now := time.Now().UTC().Truncate(time.Second)
+
+	jobs, err := ds.ListJobs()
+	require.Nil(t, err)
+	assert.Len(t, jobs, 0)
+
+	// Jobs are only locked once registered, and are listed before they
+	// first run
+	locked, err := ds.LockJob("cleanup", "a", time.Hour, now)
+	require.Nil(t, err)
+	assert.False(t, locked)
+	require.Nil(t, ds.RegisterJob("cleanup", time.Minute))
+	require.Nil(t, ds.RegisterJob("cleanup", time.Hour))
+	jobs, err = ds.ListJobs()
+	require.Nil(t, err)
+	require.Len(t, jobs, 1)
+	assert.Equal(t, time.Hour, jobs[0].Interval)
+	assert.Equal(t, kolide.JobStatusNeverRun, jobs[0].Status())
+
+	locked, err = ds.LockJob("cleanup", "a", time.Hour, now)
+	require.Nil(t, err)
+	assert.True(t, locked)
+
+	// Another server can't take the lock until it expires
+	locked, err = ds.LockJob("cleanup", "b", time.Hour, now.Add(time.Minute))
+	require.Nil(t, err)
+	assert.False(t, locked)
+
+	jobs, err = ds.ListJobs()
+	require.Nil(t, err)
+	require.Len(t, jobs, 1)
+	assert.Equal(t, "cleanup", jobs[0].Name)
+	assert.Equal(t, time.Hour, jobs[0].Interval)
+	assert.Equal(t, "a", jobs[0].LastInstance)
+	assert.Equal(t, kolide.JobStatusRunning, jobs[0].Status())
+
+	require.Nil(t, ds.FinishJob("cleanup", "a", now.Add(time.Second), "failed to clean up"))
+	jobs, err = ds.ListJobs()
+	require.Nil(t, err)
+	require.Len(t, jobs, 1)
+	assert.Equal(t, kolide.JobStatusFailed, jobs[0].Status())
+	assert.Equal(t, "failed to clean up", jobs[0].LastError)
+
+	locked, err = ds.LockJob("cleanup", "b", time.Hour, now.Add(time.Hour))
+	require.Nil(t, err)
+	assert.True(t, locked)
+
+	// The run of a server that no longer holds the job is not recorded
+	require.Nil(t, ds.FinishJob("cleanup", "a", now.Add(time.Hour+time.Second), ""))
+	require.Nil(t, ds.FinishJob("cleanup", "b", now.Add(time.Hour+time.Second), ""))
+	jobs, err = ds.ListJobs()
+	require.Nil(t, err)
+	require.Len(t, jobs, 1)
+	assert.Equal(t, "b", jobs[0].LastInstance)
+	assert.Equal(t, kolide.JobStatusSucceeded, jobs[0].Status())
+	assert.WithinDuration(t, now.Add(time.Hour+time.Second), jobs[0].LastFinishedAt, time.Second)
+
+	// A triggered job is locked again before its interval passes
+	require.Nil(t, ds.TriggerJob("unknown", now))
+	locked, err = ds.LockJob("cleanup", "a", time.Hour, now.Add(time.Hour+time.Minute))
+	require.Nil(t, err)
+	assert.False(t, locked)
+	require.Nil(t, ds.TriggerJob("cleanup", now.Add(time.Hour+time.Minute)))
+	locked, err = ds.LockJob("cleanup", "a", time.Hour, now.Add(time.Hour+2*time.Minute))
+	require.Nil(t, err)
+	assert.True(t, locked)
+}
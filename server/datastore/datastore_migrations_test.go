@@ -0,0 +1,42 @@
+package datastore
+
+import (
+	"testing"
+
+	"github.com/kolide/kolide-ose/server/kolide"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testMigrationsRoundTrip(t *testing.T, ds kolide.Datastore) {
+	if ds.Name() == "inmem" {
+		t.Skip("inmem has no migrations")
+	}
+	require.Nil(t, ds.MigrateData())
+
+	labels, err := ds.ListLabels(kolide.ListOptions{})
+	require.Nil(t, err)
+	require.NotEmpty(t, labels)
+	options, err := ds.ListOptions()
+	require.Nil(t, err)
+	require.NotEmpty(t, options)
+
+	require.Nil(t, ds.RollbackMigrations(0))
+	status, err := ds.MigrationStatus()
+	require.Nil(t, err)
+	assert.Len(t, status.Pending(), len(status.Tables)+len(status.Data))
+
+	require.Nil(t, ds.MigrateTables())
+	require.Nil(t, ds.MigrateData())
+	status, err = ds.MigrationStatus()
+	require.Nil(t, err)
+	assert.Empty(t, status.Pending())
+
+	// The built in data is restored along with the tables
+	restoredLabels, err := ds.ListLabels(kolide.ListOptions{})
+	require.Nil(t, err)
+	assert.Len(t, restoredLabels, len(labels))
+	restoredOptions, err := ds.ListOptions()
+	require.Nil(t, err)
+	assert.Len(t, restoredOptions, len(options))
+}
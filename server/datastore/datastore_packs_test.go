@@ -147,3 +147,56 @@ func testAddLabelToPackTwice(t *testing.T, ds kolide.Datastore) {
 	assert.Nil(t, err)
 	assert.Len(t, labels, 1)
 }
+
+func testListPackTargetsForHost(t *testing.T, ds kolide.Datastore) {
+	mockClock := clock.NewMockClock()
+
+	l1 := test.NewLabel(t, ds, "l1", "select 1;")
+	l2 := test.NewLabel(t, ds, "l2", "select 2;")
+	p1 := test.NewPack(t, ds, "p1")
+	p2 := test.NewPack(t, ds, "p2")
+	p3 := test.NewPack(t, ds, "p3")
+	p4 := test.NewPack(t, ds, "p4")
+
+	h1 := test.NewHost(t, ds, "h1.local", "10.10.10.1", "1", "1", mockClock.Now())
+	h2 := test.NewHost(t, ds, "h2.local", "10.10.10.2", "2", "2", mockClock.Now())
+
+	require.Nil(t, ds.RecordLabelQueryExecutions(
+		h1,
+		map[uint]bool{l1.ID: true, l2.ID: false},
+		mockClock.Now(),
+	))
+
+	// p1 applies through the host and a label, p2 through a label the host
+	// doesn't match, p3 through another host and p4 is disabled
+	require.Nil(t, ds.AddHostToPack(h1.ID, p1.ID))
+	require.Nil(t, ds.AddLabelToPack(l1.ID, p1.ID))
+	require.Nil(t, ds.AddLabelToPack(l2.ID, p2.ID))
+	require.Nil(t, ds.AddHostToPack(h2.ID, p3.ID))
+	require.Nil(t, ds.AddLabelToPack(l1.ID, p4.ID))
+	p4.Disabled = true
+	require.Nil(t, ds.SavePack(p4))
+
+	targets, err := ds.ListPackTargetsForHost(h1.ID)
+	require.Nil(t, err)
+	require.Len(t, targets, 2)
+	assert.Equal(t, p1.ID, targets[0].ID)
+	assert.Equal(t, "p1", targets[0].Name)
+	assert.Equal(t, kolide.TargetLabel, targets[0].TargetType)
+	assert.Equal(t, l1.ID, targets[0].TargetID)
+	assert.Equal(t, "l1", targets[0].LabelName)
+	assert.Equal(t, p1.ID, targets[1].ID)
+	assert.Equal(t, kolide.TargetHost, targets[1].TargetType)
+	assert.Equal(t, h1.ID, targets[1].TargetID)
+
+	targets, err = ds.ListPackTargetsForHost(h2.ID)
+	require.Nil(t, err)
+	require.Len(t, targets, 1)
+	assert.Equal(t, p3.ID, targets[0].ID)
+	assert.Equal(t, kolide.TargetHost, targets[0].TargetType)
+
+	require.Nil(t, ds.DeletePack(p1.ID))
+	targets, err = ds.ListPackTargetsForHost(h1.ID)
+	require.Nil(t, err)
+	assert.Len(t, targets, 0)
+}
@@ -2,6 +2,7 @@ package datastore
 
 import (
 	"fmt"
+	"sort"
 	"testing"
 
 	"github.com/kolide/kolide-ose/server/kolide"
@@ -54,7 +55,7 @@ func testDeleteQueries(t *testing.T, ds kolide.Datastore) {
 	q3 := test.NewQuery(t, ds, "q3", "select 1", user.ID, true)
 	q4 := test.NewQuery(t, ds, "q4", "select * from osquery_info", user.ID, true)
 
-	queries, err := ds.ListQueries(kolide.ListOptions{})
+	queries, err := ds.ListQueries(kolide.ListOptions{}, kolide.QueryFilter{})
 	require.Nil(t, err)
 	assert.Len(t, queries, 4)
 
@@ -62,7 +63,7 @@ func testDeleteQueries(t *testing.T, ds kolide.Datastore) {
 	require.Nil(t, err)
 	assert.Equal(t, uint(2), deleted)
 
-	queries, err = ds.ListQueries(kolide.ListOptions{})
+	queries, err = ds.ListQueries(kolide.ListOptions{}, kolide.QueryFilter{})
 	require.Nil(t, err)
 	assert.Len(t, queries, 2)
 
@@ -70,7 +71,7 @@ func testDeleteQueries(t *testing.T, ds kolide.Datastore) {
 	require.Nil(t, err)
 	assert.Equal(t, uint(1), deleted)
 
-	queries, err = ds.ListQueries(kolide.ListOptions{})
+	queries, err = ds.ListQueries(kolide.ListOptions{}, kolide.QueryFilter{})
 	require.Nil(t, err)
 	assert.Len(t, queries, 1)
 
@@ -78,7 +79,7 @@ func testDeleteQueries(t *testing.T, ds kolide.Datastore) {
 	require.Nil(t, err)
 	assert.Equal(t, uint(1), deleted)
 
-	queries, err = ds.ListQueries(kolide.ListOptions{})
+	queries, err = ds.ListQueries(kolide.ListOptions{}, kolide.QueryFilter{})
 	require.Nil(t, err)
 	assert.Len(t, queries, 0)
 
@@ -132,11 +133,82 @@ func testListQuery(t *testing.T, ds kolide.Datastore) {
 	require.Nil(t, err)
 
 	opts := kolide.ListOptions{}
-	results, err := ds.ListQueries(opts)
+	results, err := ds.ListQueries(opts, kolide.QueryFilter{})
 	assert.Nil(t, err)
 	assert.Equal(t, 10, len(results))
 }
 
+func testListQueryFilters(t *testing.T, ds kolide.Datastore) {
+	zach := test.NewUser(t, ds, "Zach", "zwass", "zwass@kolide.co", true)
+	mike := test.NewUser(t, ds, "Mike", "mike", "mike@kolide.co", true)
+
+	newQuery := func(name, description, sql string, author uint, saved bool, category string, tags ...string) *kolide.Query {
+		q, err := ds.NewQuery(&kolide.Query{
+			Name:        name,
+			Description: description,
+			Query:       sql,
+			Saved:       saved,
+			AuthorID:    author,
+			Category:    category,
+			Tags:        tags,
+		})
+		require.Nil(t, err)
+		return q
+	}
+	ports := newQuery("listening ports", "processes with open sockets", "select * from listening_ports", zach.ID, true, "network", "linux", "security")
+	users := newQuery("logged in users", "interactive sessions", "select * from logged_in_users", zach.ID, true, "security", "security")
+	usb := newQuery("usb devices", "attached hardware", "select * from usb_devices", mike.ID, true, "", "darwin", "linux")
+	campaign := newQuery("distributed", "", "select * from listening_ports where port = 22", mike.ID, false, "")
+
+	unsaved := false
+	var filterTests = []struct {
+		name     string
+		filter   kolide.QueryFilter
+		expected []uint
+	}{
+		{"none", kolide.QueryFilter{}, []uint{ports.ID, users.ID, usb.ID}},
+		{"unsaved", kolide.QueryFilter{Saved: &unsaved}, []uint{campaign.ID}},
+		{"author", kolide.QueryFilter{AuthorID: &mike.ID}, []uint{usb.ID}},
+		{"category", kolide.QueryFilter{Category: "security"}, []uint{users.ID}},
+		{"tag", kolide.QueryFilter{Tags: []string{"linux"}}, []uint{ports.ID, usb.ID}},
+		{"all tags", kolide.QueryFilter{Tags: []string{"linux", "security"}}, []uint{ports.ID}},
+		{"unknown tag", kolide.QueryFilter{Tags: []string{"windows"}}, []uint{}},
+		{"search name", kolide.QueryFilter{Search: "devices"}, []uint{usb.ID}},
+		{"search description", kolide.QueryFilter{Search: "sockets"}, []uint{ports.ID}},
+		{"search sql", kolide.QueryFilter{Search: "logged_in_users"}, []uint{users.ID}},
+		{"search every word", kolide.QueryFilter{Search: "listening sockets"}, []uint{ports.ID}},
+		{"search unsaved", kolide.QueryFilter{Saved: &unsaved, Search: "listening"}, []uint{campaign.ID}},
+		{"combined", kolide.QueryFilter{AuthorID: &zach.ID, Tags: []string{"security"}, Search: "users"}, []uint{users.ID}},
+	}
+
+	for _, tt := range filterTests {
+		t.Run(tt.name, func(t *testing.T) {
+			results, err := ds.ListQueries(kolide.ListOptions{}, tt.filter)
+			require.Nil(t, err)
+			ids := []uint{}
+			for _, q := range results {
+				ids = append(ids, q.ID)
+			}
+			sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+			assert.Equal(t, tt.expected, ids)
+		})
+	}
+
+	query, err := ds.Query(ports.ID)
+	require.Nil(t, err)
+	assert.Equal(t, []string{"linux", "security"}, query.Tags)
+	assert.Equal(t, "network", query.Category)
+
+	query.Tags = []string{"network"}
+	query.Category = "inventory"
+	require.Nil(t, ds.SaveQuery(query))
+
+	query, err = ds.Query(ports.ID)
+	require.Nil(t, err)
+	assert.Equal(t, []string{"network"}, query.Tags)
+	assert.Equal(t, "inventory", query.Category)
+}
+
 func checkPacks(t *testing.T, expected []kolide.Pack, actual []kolide.Pack) {
 	sortutil.AscByField(expected, "ID")
 	sortutil.AscByField(actual, "ID")
@@ -199,7 +271,7 @@ func testDuplicateNewQuery(t *testing.T, ds kolide.Datastore) {
 		Query: "select * from osquery_info;",
 	})
 
-	// Note that we can't do the actual type assertion here because existsError
-	// is private to the individual datastore implementations
+	// Note that we can't do the actual type assertion here because the SQL
+	// datastores and inmem each have their own exists error type
 	assert.Contains(t, err.Error(), "already exists in the datastore")
 }
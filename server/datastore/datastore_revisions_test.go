@@ -0,0 +1,91 @@
+package datastore
+
+import (
+	"testing"
+	"time"
+
+	"github.com/kolide/kolide-ose/server/kolide"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testRevisions(t *testing.T, ds kolide.Datastore) {
+	user, err := ds.NewUser(&kolide.User{
+		Username: "author",
+		Name:     "Author",
+		Email:    "author@kolide.co",
+	})
+	require.Nil(t, err)
+
+	createdAt := time.Date(2017, time.January, 26, 12, 0, 0, 0, time.UTC)
+	first, err := ds.NewRevision(&kolide.Revision{
+		CreatedAt:  createdAt,
+		ObjectType: kolide.RevisionObjectQuery,
+		ObjectID:   1,
+		Action:     kolide.RevisionActionCreated,
+		AuthorID:   &user.ID,
+		Snapshot:   kolide.RevisionSnapshot{"name": "foo", "query": "select 1"},
+		Changes: kolide.RevisionChanges{
+			{Field: "name", New: "foo"},
+			{Field: "query", New: "select 1"},
+		},
+	})
+	require.Nil(t, err)
+	assert.NotZero(t, first.ID)
+
+	second, err := ds.NewRevision(&kolide.Revision{
+		CreatedAt:    createdAt.Add(time.Minute),
+		ObjectType:   kolide.RevisionObjectQuery,
+		ObjectID:     1,
+		Action:       kolide.RevisionActionRestored,
+		RestoredFrom: &first.ID,
+		Snapshot:     kolide.RevisionSnapshot{"name": "bar", "query": "select 1"},
+		Changes:      kolide.RevisionChanges{{Field: "name", Old: "foo", New: "bar"}},
+	})
+	require.Nil(t, err)
+
+	// Revisions of other objects must not be listed
+	_, err = ds.NewRevision(&kolide.Revision{
+		CreatedAt:  createdAt,
+		ObjectType: kolide.RevisionObjectPack,
+		ObjectID:   1,
+		Action:     kolide.RevisionActionCreated,
+		Snapshot:   kolide.RevisionSnapshot{"name": "pack"},
+		Changes:    kolide.RevisionChanges{},
+	})
+	require.Nil(t, err)
+
+	rev, err := ds.Revision(first.ID)
+	require.Nil(t, err)
+	assert.Equal(t, createdAt, rev.CreatedAt.UTC())
+	assert.Equal(t, kolide.RevisionActionCreated, rev.Action)
+	require.NotNil(t, rev.AuthorID)
+	assert.Equal(t, user.ID, *rev.AuthorID)
+	assert.Equal(t, "Author", rev.AuthorName)
+	assert.Nil(t, rev.RestoredFrom)
+	assert.Equal(t, kolide.RevisionSnapshot{"name": "foo", "query": "select 1"}, rev.Snapshot)
+	assert.Len(t, rev.Changes, 2)
+
+	_, err = ds.Revision(12345)
+	assert.NotNil(t, err)
+
+	revisions, err := ds.ListRevisions(kolide.RevisionObjectQuery, 1, kolide.ListOptions{})
+	require.Nil(t, err)
+	require.Len(t, revisions, 2)
+	assert.Equal(t, second.ID, revisions[0].ID)
+	assert.Equal(t, first.ID, revisions[1].ID)
+	assert.Nil(t, revisions[0].AuthorID)
+	assert.Equal(t, "", revisions[0].AuthorName)
+	require.NotNil(t, revisions[0].RestoredFrom)
+	assert.Equal(t, first.ID, *revisions[0].RestoredFrom)
+	assert.Equal(t, kolide.RevisionChanges{{Field: "name", Old: "foo", New: "bar"}}, revisions[0].Changes)
+
+	revisions, err = ds.ListRevisions(kolide.RevisionObjectQuery, 1, kolide.ListOptions{Page: 1, PerPage: 1})
+	require.Nil(t, err)
+	require.Len(t, revisions, 1)
+	assert.Equal(t, first.ID, revisions[0].ID)
+
+	revisions, err = ds.ListRevisions(kolide.RevisionObjectScheduledQuery, 1, kolide.ListOptions{})
+	require.Nil(t, err)
+	assert.Len(t, revisions, 0)
+}
@@ -2,6 +2,7 @@ package datastore
 
 import (
 	"testing"
+	"time"
 
 	"github.com/kolide/kolide-ose/server/kolide"
 	"github.com/kolide/kolide-ose/server/test"
@@ -72,6 +73,37 @@ func testListScheduledQueriesInPack(t *testing.T, ds kolide.Datastore) {
 	require.Len(t, queries, 3)
 }
 
+func testListScheduledQueryNames(t *testing.T, ds kolide.Datastore) {
+	u1 := test.NewUser(t, ds, "Admin", "admin", "admin@kolide.co", true)
+	q1 := test.NewQuery(t, ds, "foo", "select * from time;", u1.ID, true)
+	q2 := test.NewQuery(t, ds, "bar", "select * from time;", u1.ID, true)
+	p1 := test.NewPack(t, ds, "baz")
+	p2 := test.NewPack(t, ds, "qux")
+
+	names, err := ds.ListScheduledQueryNames()
+	require.Nil(t, err)
+	assert.Empty(t, names)
+
+	sq1 := test.NewScheduledQuery(t, ds, p1.ID, q1.ID, 60, false, false)
+	sq2 := test.NewScheduledQuery(t, ds, p1.ID, q2.ID, 60, false, false)
+	sq3 := test.NewScheduledQuery(t, ds, p2.ID, q1.ID, 60, false, false)
+
+	names, err = ds.ListScheduledQueryNames()
+	require.Nil(t, err)
+	assert.Equal(t, []*kolide.ScheduledQueryName{
+		{ID: sq1.ID, PackName: "baz", QueryName: "foo"},
+		{ID: sq2.ID, PackName: "baz", QueryName: "bar"},
+		{ID: sq3.ID, PackName: "qux", QueryName: "foo"},
+	}, names)
+
+	require.Nil(t, ds.DeletePack(p1.ID))
+	names, err = ds.ListScheduledQueryNames()
+	require.Nil(t, err)
+	assert.Equal(t, []*kolide.ScheduledQueryName{
+		{ID: sq3.ID, PackName: "qux", QueryName: "foo"},
+	}, names)
+}
+
 func testSaveScheduledQuery(t *testing.T, ds kolide.Datastore) {
 	u1 := test.NewUser(t, ds, "Admin", "admin", "admin@kolide.co", true)
 	q1 := test.NewQuery(t, ds, "foo", "select * from time;", u1.ID, true)
@@ -91,3 +123,89 @@ func testSaveScheduledQuery(t *testing.T, ds kolide.Datastore) {
 	require.Nil(t, err)
 	assert.Equal(t, uint(120), queryVerify.Interval)
 }
+
+func testScheduledQueryStats(t *testing.T, ds kolide.Datastore) {
+	u1 := test.NewUser(t, ds, "Admin", "admin", "admin@kolide.co", true)
+	q1 := test.NewQuery(t, ds, "foo", "select * from time;", u1.ID, true)
+	q2 := test.NewQuery(t, ds, "bar", "select * from processes;", u1.ID, true)
+	p1 := test.NewPack(t, ds, "baz")
+	sq1 := test.NewScheduledQuery(t, ds, p1.ID, q1.ID, 60, false, false)
+	sq2 := test.NewScheduledQuery(t, ds, p1.ID, q2.ID, 3600, false, false)
+	h1 := test.NewHost(t, ds, "h1", "10.0.0.1", "1", "1", time.Now())
+	h2 := test.NewHost(t, ds, "h2", "10.0.0.2", "2", "2", time.Now())
+
+	lastExecuted := time.Unix(1485000000, 0).UTC()
+	err := ds.SaveScheduledQueryStats(h1.ID, []kolide.ScheduledQueryStats{
+		{
+			ScheduledQueryID: sq1.ID,
+			Executions:       10,
+			LastExecuted:     lastExecuted,
+			WallTime:         2,
+			UserTime:         300,
+			SystemTime:       100,
+			AverageMemory:    1000,
+			OutputSize:       50,
+		},
+	})
+	require.Nil(t, err)
+	err = ds.SaveScheduledQueryStats(h2.ID, []kolide.ScheduledQueryStats{
+		{
+			ScheduledQueryID: sq1.ID,
+			Executions:       30,
+			LastExecuted:     lastExecuted,
+			WallTime:         6,
+			UserTime:         500,
+			SystemTime:       300,
+			AverageMemory:    3000,
+			OutputSize:       150,
+			Denylisted:       true,
+		},
+	})
+	require.Nil(t, err)
+
+	stats, err := ds.ScheduledQueryStatsInPack(p1.ID)
+	require.Nil(t, err)
+	require.Len(t, stats, 2)
+
+	assert.Equal(t, sq1.ID, stats[0].ScheduledQueryID)
+	assert.Equal(t, "foo", stats[0].Name)
+	assert.Equal(t, uint(60), stats[0].Interval)
+	assert.Equal(t, uint(2), stats[0].HostCount)
+	assert.Equal(t, uint(1), stats[0].DenylistedCount)
+	assert.Equal(t, uint64(40), stats[0].Executions)
+	assert.Equal(t, uint64(8), stats[0].WallTime)
+	assert.Equal(t, uint64(800), stats[0].UserTime)
+	assert.Equal(t, uint64(400), stats[0].SystemTime)
+	assert.Equal(t, uint64(200), stats[0].OutputSize)
+	assert.Equal(t, uint64(2000), stats[0].AverageMemory)
+	assert.Equal(t, uint64(3000), stats[0].MaxMemory)
+	assert.Equal(t, float64(200), stats[0].WallTimePerExecution)
+	assert.Equal(t, float64(20), stats[0].UserTimePerExecution)
+	assert.Equal(t, float64(10), stats[0].SystemTimePerExecution)
+
+	// Scheduled queries without stats are included
+	assert.Equal(t, sq2.ID, stats[1].ScheduledQueryID)
+	assert.Equal(t, uint(0), stats[1].HostCount)
+	assert.Equal(t, uint64(0), stats[1].Executions)
+
+	// Saving replaces the stats of the host
+	err = ds.SaveScheduledQueryStats(h2.ID, []kolide.ScheduledQueryStats{
+		{ScheduledQueryID: sq2.ID, Executions: 1, UserTime: 5},
+	})
+	require.Nil(t, err)
+
+	stats, err = ds.ScheduledQueryStatsInPack(p1.ID)
+	require.Nil(t, err)
+	require.Len(t, stats, 2)
+	assert.Equal(t, uint(1), stats[0].HostCount)
+	assert.Equal(t, uint64(10), stats[0].Executions)
+	assert.Equal(t, uint(1), stats[1].HostCount)
+	assert.Equal(t, uint64(5), stats[1].UserTime)
+
+	// Stats of deleted hosts are not counted
+	require.Nil(t, ds.DeleteHost(h1.ID))
+	stats, err = ds.ScheduledQueryStatsInPack(p1.ID)
+	require.Nil(t, err)
+	require.Len(t, stats, 2)
+	assert.Equal(t, uint(0), stats[0].HostCount)
+}
@@ -0,0 +1,112 @@
+package datastore
+
+import (
+	"testing"
+	"time"
+
+	"github.com/kolide/kolide-ose/server/kolide"
+	"github.com/kolide/kolide-ose/server/test"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testSoftware(t *testing.T, ds kolide.Datastore) {
+	h1 := test.NewHost(t, ds, "h1", "10.0.0.1", "1", "1", time.Now())
+	h2 := test.NewHost(t, ds, "h2", "10.0.0.2", "2", "2", time.Now())
+
+	first := time.Now().Add(-time.Hour).Truncate(time.Second)
+	err := ds.SaveHostSoftware(h1.ID, "deb_packages", []kolide.Software{
+		{Name: "openssl", Version: "1.0.2g"},
+		{Name: "bash", Version: "4.3"},
+		{Name: "bash", Version: "4.3"},
+	}, first)
+	require.Nil(t, err)
+	err = ds.SaveHostSoftware(h2.ID, "deb_packages", []kolide.Software{
+		{Name: "openssl", Version: "1.0.2g"},
+		{Name: "curl", Version: "7.47.0"},
+	}, first)
+	require.Nil(t, err)
+	err = ds.SaveHostSoftware(h2.ID, "homebrew_packages", []kolide.Software{
+		{Name: "openssl", Version: "1.0.2j"},
+	}, first)
+	require.Nil(t, err)
+
+	software, err := ds.ListHostSoftware(h1.ID, kolide.ListOptions{})
+	require.Nil(t, err)
+	require.Len(t, software, 2)
+	assert.Equal(t, "bash", software[0].Name)
+	assert.Equal(t, "openssl", software[1].Name)
+	assert.Equal(t, "deb_packages", software[1].Source)
+	assert.Equal(t, first.Unix(), software[1].FirstSeen.Unix())
+
+	// Upgrading a package replaces it, and packages still installed keep
+	// their first seen time
+	second := first.Add(30 * time.Minute)
+	err = ds.SaveHostSoftware(h1.ID, "deb_packages", []kolide.Software{
+		{Name: "openssl", Version: "1.0.2k"},
+		{Name: "bash", Version: "4.3"},
+	}, second)
+	require.Nil(t, err)
+	software, err = ds.ListHostSoftware(h1.ID, kolide.ListOptions{})
+	require.Nil(t, err)
+	require.Len(t, software, 2)
+	assert.Equal(t, "bash", software[0].Name)
+	assert.Equal(t, first.Unix(), software[0].FirstSeen.Unix())
+	assert.Equal(t, second.Unix(), software[0].LastSeen.Unix())
+	assert.Equal(t, "1.0.2k", software[1].Version)
+	assert.Equal(t, second.Unix(), software[1].FirstSeen.Unix())
+
+	// Saving one source leaves the others alone
+	software, err = ds.ListHostSoftware(h2.ID, kolide.ListOptions{})
+	require.Nil(t, err)
+	assert.Len(t, software, 3)
+
+	counts, err := ds.SearchSoftware(kolide.SoftwareFilter{Name: "OpenSSL"}, kolide.ListOptions{})
+	require.Nil(t, err)
+	require.Len(t, counts, 3, "software no host has installed is not returned")
+	assert.Equal(t, "1.0.2g", counts[0].Version)
+	assert.Equal(t, uint(1), counts[0].HostCount)
+	assert.Equal(t, "1.0.2j", counts[1].Version)
+	assert.Equal(t, "1.0.2k", counts[2].Version)
+
+	counts, err = ds.SearchSoftware(kolide.SoftwareFilter{Name: "ssl", Version: "1.0.2j", Source: "homebrew_packages"}, kolide.ListOptions{})
+	require.Nil(t, err)
+	require.Len(t, counts, 1)
+
+	counts, err = ds.SearchSoftware(kolide.SoftwareFilter{Name: "bash"}, kolide.ListOptions{})
+	require.Nil(t, err)
+	require.Len(t, counts, 1)
+	bash := counts[0]
+
+	err = ds.SaveHostSoftware(h2.ID, "deb_packages", []kolide.Software{
+		{Name: "bash", Version: "4.3"},
+	}, second)
+	require.Nil(t, err)
+	counts, err = ds.SearchSoftware(kolide.SoftwareFilter{Name: "bash"}, kolide.ListOptions{})
+	require.Nil(t, err)
+	require.Len(t, counts, 1)
+	assert.Equal(t, uint(2), counts[0].HostCount)
+
+	loaded, err := ds.Software(bash.ID)
+	require.Nil(t, err)
+	assert.Equal(t, bash.Software, *loaded)
+	_, err = ds.Software(bash.ID + 1000)
+	assert.NotNil(t, err)
+
+	installs, err := ds.ListSoftwareInstalls(bash.ID, kolide.ListOptions{})
+	require.Nil(t, err)
+	require.Len(t, installs, 2)
+	assert.Equal(t, h1.ID, installs[0].HostID)
+	assert.Equal(t, "h1", installs[0].HostName)
+	assert.Equal(t, h2.ID, installs[1].HostID)
+
+	// Deleted hosts are not counted
+	require.Nil(t, ds.DeleteHost(h2.ID))
+	counts, err = ds.SearchSoftware(kolide.SoftwareFilter{Name: "bash"}, kolide.ListOptions{})
+	require.Nil(t, err)
+	require.Len(t, counts, 1)
+	assert.Equal(t, uint(1), counts[0].HostCount)
+	installs, err = ds.ListSoftwareInstalls(bash.ID, kolide.ListOptions{})
+	require.Nil(t, err)
+	assert.Len(t, installs, 1)
+}
@@ -0,0 +1,71 @@
+package datastore
+
+import (
+	"testing"
+	"time"
+
+	"github.com/kolide/kolide-ose/server/kolide"
+	"github.com/kolide/kolide-ose/server/test"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testListTargetHosts(t *testing.T, ds kolide.Datastore) {
+	h1 := test.NewHost(t, ds, "foo.local", "192.168.1.10", "1", "1", time.Now())
+	h2 := test.NewHost(t, ds, "bar.local", "192.168.1.11", "2", "2", time.Now())
+	h3 := test.NewHost(t, ds, "baz.local", "192.168.1.12", "3", "3", time.Now())
+	h4 := test.NewHost(t, ds, "qux.local", "192.168.1.13", "4", "4", time.Now())
+
+	l1, err := ds.NewLabel(&kolide.Label{Name: "label foo", Query: "query foo"})
+	require.Nil(t, err)
+	l2, err := ds.NewLabel(&kolide.Label{Name: "label bar", Query: "query bar"})
+	require.Nil(t, err)
+
+	require.Nil(t, ds.RecordLabelQueryExecutions(h2, map[uint]bool{l1.ID: true, l2.ID: true}, time.Now()))
+	require.Nil(t, ds.RecordLabelQueryExecutions(h3, map[uint]bool{l1.ID: true}, time.Now()))
+	require.Nil(t, ds.RecordLabelQueryExecutions(h4, map[uint]bool{l1.ID: false, l2.ID: true}, time.Now()))
+
+	hostIDs := func(hosts []*kolide.Host) []uint {
+		ids := []uint{}
+		for _, h := range hosts {
+			ids = append(ids, h.ID)
+		}
+		return ids
+	}
+
+	hosts, err := ds.ListTargetHosts(nil, nil, kolide.ListOptions{})
+	require.Nil(t, err)
+	assert.Empty(t, hosts)
+
+	hosts, err = ds.ListTargetHosts([]uint{h1.ID, h2.ID}, []uint{l1.ID}, kolide.ListOptions{})
+	require.Nil(t, err)
+	assert.Equal(t, []uint{h2.ID, h3.ID, h1.ID}, hostIDs(hosts))
+
+	hosts, err = ds.ListTargetHosts([]uint{h1.ID, h2.ID}, []uint{l1.ID}, kolide.ListOptions{Page: 1, PerPage: 2})
+	require.Nil(t, err)
+	assert.Equal(t, []uint{h1.ID}, hostIDs(hosts))
+
+	hosts, err = ds.ListTargetHosts(nil, []uint{l2.ID}, kolide.ListOptions{})
+	require.Nil(t, err)
+	assert.Equal(t, []uint{h2.ID, h4.ID}, hostIDs(hosts))
+
+	count, err := ds.CountTargetHosts([]uint{h1.ID, h2.ID}, []uint{l1.ID, l2.ID})
+	require.Nil(t, err)
+	assert.Equal(t, uint(4), count)
+
+	count, err = ds.CountTargetHosts([]uint{h1.ID, 999}, nil)
+	require.Nil(t, err)
+	assert.Equal(t, uint(1), count)
+
+	labels, err := ds.TargetLabelIDsForHosts([]uint{h1.ID, h2.ID, h3.ID, h4.ID}, []uint{l1.ID, l2.ID})
+	require.Nil(t, err)
+	assert.Equal(t, map[uint][]uint{
+		h2.ID: {l1.ID, l2.ID},
+		h3.ID: {l1.ID},
+		h4.ID: {l2.ID},
+	}, labels)
+
+	labels, err = ds.TargetLabelIDsForHosts([]uint{h2.ID}, nil)
+	require.Nil(t, err)
+	assert.Empty(t, labels)
+}
@@ -27,6 +27,7 @@ var testFunctions = [...]func(*testing.T, kolide.Datastore){
 	testDeleteQueries,
 	testSaveQuery,
 	testListQuery,
+	testListQueryFilters,
 	testDeletePack,
 	testEnrollHost,
 	testAuthenticateHost,
@@ -44,17 +45,30 @@ var testFunctions = [...]func(*testing.T, kolide.Datastore){
 	testListHostsInLabel,
 	testListUniqueHostsInLabels,
 	testDistributedQueriesForHost,
+	testDistributedQueriesForCampaignHosts,
+	testListTargetHosts,
 	testSaveHosts,
 	testDeleteHost,
 	testListHost,
+	testListHostsFilter,
 	testGetHostsInPack,
 	testDistributedQueryCampaign,
 	testCleanupDistributedQueryCampaigns,
+	testDistributedQueryCampaignHosts,
+	testRevisions,
 	testBuiltInLabels,
 	testLoadPacksForQueries,
 	testScheduledQuery,
+	testScheduledQueryStats,
+	testAgentLogs,
+	testDetailQueries,
+	testSoftware,
+	testVulnerabilities,
+	testPurgeHosts,
+	testHostBreakdown,
 	testDeleteScheduledQuery,
 	testListScheduledQueriesInPack,
+	testListScheduledQueryNames,
 	testSaveScheduledQuery,
 	testOptions,
 	testNewScheduledQuery,
@@ -65,7 +79,12 @@ var testFunctions = [...]func(*testing.T, kolide.Datastore){
 	testFileIntegrityMonitoring,
 	testYARAStore,
 	testAddLabelToPackTwice,
+	testListPackTargetsForHost,
 	testGenerateHostStatusStatistics,
 	testMarkHostSeen,
+	testMarkHostsSeen,
+	testMarkHostsConfigFetched,
+	testJobs,
+	testMigrationsRoundTrip,
 	testDuplicateNewQuery,
 }
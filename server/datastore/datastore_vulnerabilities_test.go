@@ -0,0 +1,129 @@
+package datastore
+
+import (
+	"testing"
+	"time"
+
+	"github.com/kolide/kolide-ose/server/kolide"
+	"github.com/kolide/kolide-ose/server/test"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testVulnerabilities(t *testing.T, ds kolide.Datastore) {
+	h1 := test.NewHost(t, ds, "h1", "10.0.0.1", "1", "1", time.Now())
+	h2 := test.NewHost(t, ds, "h2", "10.0.0.2", "2", "2", time.Now())
+
+	h1Software := []kolide.Software{
+		{Name: "openssl", Version: "1.0.2g"},
+		{Name: "curl", Version: "7.47.0"},
+	}
+	require.Nil(t, ds.SaveHostSoftware(h1.ID, "deb_packages", h1Software, time.Now()))
+	h2Software := []kolide.Software{
+		{Name: "openssl", Version: "1.0.2g"},
+	}
+	require.Nil(t, ds.SaveHostSoftware(h2.ID, "deb_packages", h2Software, time.Now()))
+	require.NotZero(t, h1Software[0].ID)
+	assert.Equal(t, h1Software[0].ID, h2Software[0].ID)
+
+	err := ds.ImportVulnerabilities([]*kolide.Vulnerability{
+		{
+			CVE:       "CVE-2016-2107",
+			Summary:   "The AES-NI implementation leaks",
+			CVSSScore: 5.9,
+			Criteria: []kolide.VulnerableSoftware{
+				{Vendor: "openssl", Product: "openssl", Version: "1.0.2g"},
+			},
+		},
+		{
+			CVE:       "CVE-2016-8615",
+			CVSSScore: 7.5,
+			Criteria: []kolide.VulnerableSoftware{
+				{Vendor: "haxx", Product: "curl", VersionEndIncluding: "7.50.3"},
+			},
+		},
+	})
+	require.Nil(t, err)
+
+	vuln, err := ds.Vulnerability("CVE-2016-2107")
+	require.Nil(t, err)
+	assert.Equal(t, "The AES-NI implementation leaks", vuln.Summary)
+	_, err = ds.Vulnerability("CVE-1999-0001")
+	assert.NotNil(t, err)
+
+	criteria, err := ds.ListVulnerableSoftware(nil)
+	require.Nil(t, err)
+	assert.Len(t, criteria, 2)
+	criteria, err = ds.ListVulnerableSoftware([]string{"curl", "bash"})
+	require.Nil(t, err)
+	require.Len(t, criteria, 1)
+	assert.Equal(t, "CVE-2016-8615", criteria[0].CVE)
+	assert.Equal(t, "7.50.3", criteria[0].VersionEndIncluding)
+
+	// Importing a vulnerability again replaces its criteria
+	err = ds.ImportVulnerabilities([]*kolide.Vulnerability{
+		{
+			CVE:       "CVE-2016-8615",
+			CVSSScore: 7.5,
+			Criteria: []kolide.VulnerableSoftware{
+				{Vendor: "haxx", Product: "curl", VersionEndExcluding: "7.51.0"},
+			},
+		},
+	})
+	require.Nil(t, err)
+	criteria, err = ds.ListVulnerableSoftware([]string{"curl"})
+	require.Nil(t, err)
+	require.Len(t, criteria, 1)
+	assert.Equal(t, "7.51.0", criteria[0].VersionEndExcluding)
+
+	software, err := ds.ListAllSoftware()
+	require.Nil(t, err)
+	assert.Len(t, software, 2)
+
+	require.Nil(t, ds.ReplaceSoftwareVulnerabilities([]kolide.SoftwareVulnerability{
+		{SoftwareID: h1Software[0].ID, CVE: "CVE-2016-2107"},
+	}))
+	require.Nil(t, ds.AddSoftwareVulnerabilities([]kolide.SoftwareVulnerability{
+		{SoftwareID: h1Software[0].ID, CVE: "CVE-2016-2107"},
+		{SoftwareID: h1Software[1].ID, CVE: "CVE-2016-8615"},
+	}))
+
+	vulns, err := ds.ListVulnerabilities(kolide.ListOptions{})
+	require.Nil(t, err)
+	require.Len(t, vulns, 2)
+	assert.Equal(t, "CVE-2016-8615", vulns[0].CVE, "ordered by CVSS score")
+	assert.Equal(t, uint(1), vulns[0].HostCount)
+	assert.Equal(t, "CVE-2016-2107", vulns[1].CVE)
+	assert.Equal(t, uint(2), vulns[1].HostCount)
+
+	hosts, err := ds.ListVulnerableHosts("CVE-2016-2107", kolide.ListOptions{})
+	require.Nil(t, err)
+	require.Len(t, hosts, 2)
+	assert.Equal(t, h1.ID, hosts[0].HostID)
+	assert.Equal(t, "h1", hosts[0].HostName)
+	assert.Equal(t, "openssl", hosts[0].Software.Name)
+	assert.Equal(t, "1.0.2g", hosts[0].Software.Version)
+	assert.Equal(t, h2.ID, hosts[1].HostID)
+
+	hostVulns, err := ds.ListHostVulnerabilities(h1.ID, kolide.ListOptions{})
+	require.Nil(t, err)
+	require.Len(t, hostVulns, 2)
+	assert.Equal(t, "CVE-2016-8615", hostVulns[0].CVE)
+	assert.Equal(t, "curl", hostVulns[0].Software.Name)
+	assert.Equal(t, "CVE-2016-2107", hostVulns[1].CVE)
+	assert.Equal(t, 5.9, hostVulns[1].CVSSScore)
+
+	// Replacing removes the matches that are left out
+	require.Nil(t, ds.ReplaceSoftwareVulnerabilities([]kolide.SoftwareVulnerability{
+		{SoftwareID: h1Software[1].ID, CVE: "CVE-2016-8615"},
+	}))
+	hostVulns, err = ds.ListHostVulnerabilities(h2.ID, kolide.ListOptions{})
+	require.Nil(t, err)
+	assert.Len(t, hostVulns, 0)
+
+	// Deleted hosts are not counted
+	require.Nil(t, ds.DeleteHost(h1.ID))
+	vulns, err = ds.ListVulnerabilities(kolide.ListOptions{})
+	require.Nil(t, err)
+	assert.Len(t, vulns, 0)
+}
@@ -0,0 +1,119 @@
+package inmem
+
+import (
+	"sort"
+
+	"github.com/kolide/kolide-ose/server/kolide"
+)
+
+func (d *Datastore) NewAgentLogs(hostID uint, logs []*kolide.AgentLog, retain int) error {
+	d.mtx.Lock()
+	defer d.mtx.Unlock()
+
+	if d.agentLogCounts[hostID] == nil {
+		d.agentLogCounts[hostID] = kolide.AgentLogCounts{}
+	}
+
+	var stored int
+	for _, log := range logs {
+		d.agentLogCounts[hostID][log.Severity]++
+		if !kolide.AgentLogSeverityStored(log.Severity) {
+			continue
+		}
+		newLog := *log
+		newLog.HostID = hostID
+		newLog.ID = d.nextID(newLog)
+		d.agentLogs[hostID] = append(d.agentLogs[hostID], &newLog)
+		stored++
+	}
+
+	// Old logs are only removed when new ones were stored
+	if hostLogs := d.agentLogs[hostID]; retain > 0 && stored > 0 && len(hostLogs) > retain {
+		d.agentLogs[hostID] = hostLogs[len(hostLogs)-retain:]
+	}
+
+	return nil
+}
+
+func (d *Datastore) ListAgentLogs(hostID uint, opt kolide.ListOptions) ([]*kolide.AgentLog, error) {
+	d.mtx.Lock()
+	defer d.mtx.Unlock()
+
+	// Logs are stored oldest first, and listed newest first
+	hostLogs := d.agentLogs[hostID]
+	logs := make([]*kolide.AgentLog, 0, len(hostLogs))
+	for i := len(hostLogs) - 1; i >= 0; i-- {
+		logs = append(logs, hostLogs[i])
+	}
+
+	low, high := d.getLimitOffsetSliceBounds(opt, len(logs))
+	return logs[low:high], nil
+}
+
+func (d *Datastore) AgentLogCounts(hostID uint) (kolide.AgentLogCounts, error) {
+	d.mtx.Lock()
+	defer d.mtx.Unlock()
+
+	counts := kolide.AgentLogCounts{}
+	for severity, count := range d.agentLogCounts[hostID] {
+		counts[severity] = count
+	}
+	return counts, nil
+}
+
+func (d *Datastore) ListAgentLogSummaries(severity string, limit uint) ([]*kolide.AgentLogSummary, error) {
+	d.mtx.Lock()
+	defer d.mtx.Unlock()
+
+	type summaryKey struct {
+		severity, filename, message string
+		line                        uint
+	}
+	summaries := map[summaryKey]*kolide.AgentLogSummary{}
+	hosts := map[summaryKey]map[uint]bool{}
+
+	for hostID, hostLogs := range d.agentLogs {
+		if _, ok := d.hosts[hostID]; !ok {
+			continue
+		}
+		for _, log := range hostLogs {
+			if severity != "" && log.Severity != severity {
+				continue
+			}
+			key := summaryKey{log.Severity, log.Filename, log.Message, log.Line}
+			summary, ok := summaries[key]
+			if !ok {
+				summary = &kolide.AgentLogSummary{
+					Severity: log.Severity,
+					Filename: log.Filename,
+					Line:     log.Line,
+					Message:  log.Message,
+				}
+				summaries[key] = summary
+				hosts[key] = map[uint]bool{}
+			}
+			summary.Count++
+			hosts[key][hostID] = true
+			if log.CreatedAt.After(summary.LastSeen) {
+				summary.LastSeen = log.CreatedAt
+			}
+		}
+	}
+
+	results := []*kolide.AgentLogSummary{}
+	for key, summary := range summaries {
+		summary.HostCount = uint(len(hosts[key]))
+		results = append(results, summary)
+	}
+	sort.Slice(results, func(i, j int) bool {
+		if results[i].Count != results[j].Count {
+			return results[i].Count > results[j].Count
+		}
+		return results[i].LastSeen.After(results[j].LastSeen)
+	})
+
+	if limit > 0 && uint(len(results)) > limit {
+		results = results[:limit]
+	}
+	return results, nil
+}
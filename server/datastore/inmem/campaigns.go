@@ -5,6 +5,7 @@ import (
 	"time"
 
 	"github.com/kolide/kolide-ose/server/kolide"
+	"github.com/patrickmn/sortutil"
 )
 
 func (d *Datastore) NewDistributedQueryCampaign(camp *kolide.DistributedQueryCampaign) (*kolide.DistributedQueryCampaign, error) {
@@ -72,6 +73,35 @@ func (d *Datastore) NewDistributedQueryCampaignTarget(target *kolide.Distributed
 	return target, nil
 }
 
+func (d *Datastore) NewDistributedQueryCampaignHosts(campaignID uint, hostIDs []uint) error {
+	d.mtx.Lock()
+	defer d.mtx.Unlock()
+
+	hosts, ok := d.distributedQueryCampaignHosts[campaignID]
+	if !ok {
+		hosts = map[uint]bool{}
+		d.distributedQueryCampaignHosts[campaignID] = hosts
+	}
+	for _, hostID := range hostIDs {
+		hosts[hostID] = true
+	}
+
+	return nil
+}
+
+func (d *Datastore) DistributedQueryCampaignHostIDs(id uint) ([]uint, error) {
+	d.mtx.Lock()
+	defer d.mtx.Unlock()
+
+	hostIDs := []uint{}
+	for hostID := range d.distributedQueryCampaignHosts[id] {
+		hostIDs = append(hostIDs, hostID)
+	}
+	sortutil.Asc(hostIDs)
+
+	return hostIDs, nil
+}
+
 func (d *Datastore) NewDistributedQueryExecution(exec *kolide.DistributedQueryExecution) (*kolide.DistributedQueryExecution, error) {
 	d.mtx.Lock()
 	defer d.mtx.Unlock()
@@ -0,0 +1,196 @@
+package inmem
+
+import (
+	"sort"
+	"time"
+
+	"github.com/kolide/kolide-ose/server/kolide"
+)
+
+func (d *Datastore) NewDetailQuery(query *kolide.DetailQuery) (*kolide.DetailQuery, error) {
+	d.mtx.Lock()
+	defer d.mtx.Unlock()
+
+	for _, q := range d.detailQueries {
+		if q.Name == query.Name {
+			return nil, alreadyExists("DetailQuery", q.ID)
+		}
+	}
+
+	newQuery := *query
+	newQuery.ID = d.nextID(newQuery)
+	d.detailQueries[newQuery.ID] = &newQuery
+	query.ID = newQuery.ID
+
+	return query, nil
+}
+
+func (d *Datastore) SaveDetailQuery(query *kolide.DetailQuery) error {
+	d.mtx.Lock()
+	defer d.mtx.Unlock()
+
+	if _, ok := d.detailQueries[query.ID]; !ok {
+		return notFound("DetailQuery").WithID(query.ID)
+	}
+	for _, q := range d.detailQueries {
+		if q.Name == query.Name && q.ID != query.ID {
+			return alreadyExists("DetailQuery", q.ID)
+		}
+	}
+
+	savedQuery := *query
+	d.detailQueries[query.ID] = &savedQuery
+	return nil
+}
+
+func (d *Datastore) DeleteDetailQuery(id uint) error {
+	d.mtx.Lock()
+	defer d.mtx.Unlock()
+
+	if _, ok := d.detailQueries[id]; !ok {
+		return notFound("DetailQuery").WithID(id)
+	}
+	delete(d.detailQueries, id)
+
+	for hostID, attributes := range d.hostAttributes {
+		for key, a := range attributes {
+			if a.DetailQueryID == id {
+				delete(d.hostAttributes[hostID], key)
+			}
+		}
+	}
+	for _, executions := range d.detailQueryExecutions {
+		delete(executions, id)
+	}
+
+	return nil
+}
+
+func (d *Datastore) DetailQuery(id uint) (*kolide.DetailQuery, error) {
+	d.mtx.Lock()
+	defer d.mtx.Unlock()
+
+	query, ok := d.detailQueries[id]
+	if !ok {
+		return nil, notFound("DetailQuery").WithID(id)
+	}
+	// Copy so that changes are only stored by SaveDetailQuery
+	result := *query
+	return &result, nil
+}
+
+func (d *Datastore) ListDetailQueries(opt kolide.ListOptions) ([]*kolide.DetailQuery, error) {
+	d.mtx.Lock()
+	defer d.mtx.Unlock()
+
+	// We need to sort by keys to provide reliable ordering
+	keys := []int{}
+	for k := range d.detailQueries {
+		keys = append(keys, int(k))
+	}
+	sort.Ints(keys)
+
+	queries := []*kolide.DetailQuery{}
+	for _, k := range keys {
+		queries = append(queries, d.detailQueries[uint(k)])
+	}
+
+	// Apply ordering
+	if opt.OrderKey != "" {
+		var fields = map[string]string{
+			"id":         "ID",
+			"created_at": "CreatedAt",
+			"updated_at": "UpdatedAt",
+			"name":       "Name",
+			"platform":   "Platform",
+			"interval":   "Interval",
+		}
+		if err := sortResults(queries, opt, fields); err != nil {
+			return nil, err
+		}
+	}
+
+	// Apply limit/offset
+	low, high := d.getLimitOffsetSliceBounds(opt, len(queries))
+	return queries[low:high], nil
+}
+
+func (d *Datastore) DetailQueriesForHost(host *kolide.Host, now time.Time) (map[uint]string, error) {
+	d.mtx.Lock()
+	defer d.mtx.Unlock()
+
+	queries := map[uint]string{}
+	for _, query := range d.detailQueries {
+		if !query.RunsOnPlatform(host.Platform) {
+			continue
+		}
+		updated, ok := d.detailQueryExecutions[host.ID][query.ID]
+		if ok && updated.After(now.Add(-time.Duration(query.Interval)*time.Second)) {
+			continue
+		}
+		queries[query.ID] = query.Query
+	}
+	return queries, nil
+}
+
+func (d *Datastore) RecordHostAttributes(hostID, detailQueryID uint, attributes []kolide.HostAttribute, updated time.Time) error {
+	d.mtx.Lock()
+	defer d.mtx.Unlock()
+
+	if d.hostAttributes[hostID] == nil {
+		d.hostAttributes[hostID] = map[string]kolide.HostAttribute{}
+	}
+	for key, a := range d.hostAttributes[hostID] {
+		if a.DetailQueryID == detailQueryID {
+			delete(d.hostAttributes[hostID], key)
+		}
+	}
+	for _, a := range attributes {
+		a.HostID = hostID
+		a.DetailQueryID = detailQueryID
+		a.UpdatedAt = updated
+		d.hostAttributes[hostID][a.Key] = a
+	}
+
+	if d.detailQueryExecutions[hostID] == nil {
+		d.detailQueryExecutions[hostID] = map[uint]time.Time{}
+	}
+	d.detailQueryExecutions[hostID][detailQueryID] = updated
+
+	return nil
+}
+
+func (d *Datastore) HostAttributes(hostID uint) ([]kolide.HostAttribute, error) {
+	d.mtx.Lock()
+	defer d.mtx.Unlock()
+
+	return d.sortedHostAttributes(hostID), nil
+}
+
+// sortedHostAttributes returns the attributes of a host ordered by key. The
+// caller must hold the lock.
+func (d *Datastore) sortedHostAttributes(hostID uint) []kolide.HostAttribute {
+	attributes := []kolide.HostAttribute{}
+	for _, a := range d.hostAttributes[hostID] {
+		attributes = append(attributes, a)
+	}
+	sort.Slice(attributes, func(i, j int) bool {
+		return attributes[i].Key < attributes[j].Key
+	})
+	return attributes
+}
+
+// hostMatchesAttributeFilters returns whether the host has attributes
+// matching every one of the filters. The caller must hold the lock.
+func (d *Datastore) hostMatchesAttributeFilters(hostID uint, filters []kolide.HostAttributeFilter) bool {
+	if len(filters) == 0 {
+		return true
+	}
+	attributes := d.sortedHostAttributes(hostID)
+	for _, f := range filters {
+		if !f.MatchesAny(attributes) {
+			return false
+		}
+	}
+	return true
+}
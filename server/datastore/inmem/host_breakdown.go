@@ -0,0 +1,62 @@
+package inmem
+
+import "github.com/kolide/kolide-ose/server/kolide"
+
+func (d *Datastore) HostBreakdown(labelID uint) (*kolide.HostBreakdown, error) {
+	d.mtx.Lock()
+	defer d.mtx.Unlock()
+
+	type model struct{ vendor, model string }
+	platforms := map[string]uint{}
+	osVersions := map[string]uint{}
+	osqueryVersions := map[string]uint{}
+	vendors := map[string]uint{}
+	models := map[model]uint{}
+	cpuBrands := map[string]uint{}
+	memory := map[string]uint{}
+
+	breakdown := &kolide.HostBreakdown{}
+	labelHosts := d.labelHosts(labelID)
+	for _, host := range d.hosts {
+		if labelHosts != nil && !labelHosts[host.ID] {
+			continue
+		}
+		breakdown.TotalCount++
+		platforms[host.Platform]++
+		osVersions[host.OSVersion]++
+		osqueryVersions[host.OsqueryVersion]++
+		vendors[host.HardwareVendor]++
+		models[model{host.HardwareVendor, host.HardwareModel}]++
+		cpuBrands[host.CPUBrand]++
+		memory[kolide.MemoryBucket(host.PhysicalMemory)]++
+	}
+
+	breakdown.Platforms = hostCounts(platforms)
+	breakdown.OSVersions = hostCounts(osVersions)
+	breakdown.OsqueryVersions = hostCounts(osqueryVersions)
+	breakdown.HardwareVendors = hostCounts(vendors)
+	breakdown.CPUBrands = hostCounts(cpuBrands)
+	breakdown.Memory = hostCounts(memory)
+
+	breakdown.HardwareModels = []*kolide.ModelCount{}
+	for m, count := range models {
+		breakdown.HardwareModels = append(breakdown.HardwareModels, &kolide.ModelCount{
+			Vendor: m.vendor,
+			Model:  m.model,
+			Count:  count,
+		})
+	}
+	kolide.SortModelCounts(breakdown.HardwareModels)
+
+	return breakdown, nil
+}
+
+// hostCounts returns the sorted counts of the values.
+func hostCounts(values map[string]uint) []*kolide.HostCount {
+	counts := []*kolide.HostCount{}
+	for value, count := range values {
+		counts = append(counts, &kolide.HostCount{Value: value, Count: count})
+	}
+	kolide.SortHostCounts(counts)
+	return counts
+}
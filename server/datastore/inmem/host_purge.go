@@ -0,0 +1,88 @@
+package inmem
+
+import (
+	"sort"
+	"time"
+
+	"github.com/kolide/kolide-ose/server/kolide"
+)
+
+func (d *Datastore) ListPurgeableHosts(seenBefore time.Time, exemptLabelIDs []uint) ([]*kolide.Host, error) {
+	d.mtx.Lock()
+	defer d.mtx.Unlock()
+
+	exemptLabels := map[uint]bool{}
+	for _, id := range exemptLabelIDs {
+		exemptLabels[id] = true
+	}
+	exemptHosts := map[uint]bool{}
+	for _, lqe := range d.labelQueryExecutions {
+		if lqe.Matches && exemptLabels[lqe.LabelID] {
+			exemptHosts[lqe.HostID] = true
+		}
+	}
+
+	hosts := []*kolide.Host{}
+	for _, host := range d.hosts {
+		if !host.SeenTime.Before(seenBefore) || exemptHosts[host.ID] {
+			continue
+		}
+		result := *host
+		hosts = append(hosts, &result)
+	}
+	sort.Slice(hosts, func(i, j int) bool {
+		return hosts[i].ID < hosts[j].ID
+	})
+	return hosts, nil
+}
+
+func (d *Datastore) PurgeHosts(hostIDs []uint, seenBefore time.Time) (uint, error) {
+	d.mtx.Lock()
+	defer d.mtx.Unlock()
+
+	// Hosts seen since they were listed are no longer purged
+	purge := map[uint]bool{}
+	for _, id := range hostIDs {
+		if host, ok := d.hosts[id]; ok && host.SeenTime.Before(seenBefore) {
+			purge[id] = true
+		}
+	}
+
+	for id, lqe := range d.labelQueryExecutions {
+		if purge[lqe.HostID] {
+			delete(d.labelQueryExecutions, id)
+		}
+	}
+	for id, target := range d.packTargets {
+		if target.Type == kolide.TargetHost && purge[target.TargetID] {
+			delete(d.packTargets, id)
+		}
+	}
+	for id, target := range d.distributedQueryCampaignTargets {
+		if target.Type == kolide.TargetHost && purge[target.TargetID] {
+			delete(d.distributedQueryCampaignTargets, id)
+		}
+	}
+	for id, exec := range d.distributedQueryExecutions {
+		if purge[exec.HostID] {
+			delete(d.distributedQueryExecutions, id)
+		}
+	}
+	for _, hosts := range d.distributedQueryCampaignHosts {
+		for hostID := range purge {
+			delete(hosts, hostID)
+		}
+	}
+	for hostID := range purge {
+		// Network interfaces are stored with the host
+		delete(d.hosts, hostID)
+		delete(d.scheduledQueryStats, hostID)
+		delete(d.agentLogs, hostID)
+		delete(d.agentLogCounts, hostID)
+		delete(d.detailQueryExecutions, hostID)
+		delete(d.hostAttributes, hostID)
+		delete(d.hostSoftware, hostID)
+	}
+
+	return uint(len(purge)), nil
+}
@@ -2,6 +2,7 @@ package inmem
 
 import (
 	"errors"
+	"fmt"
 	"sort"
 	"strings"
 	"time"
@@ -30,9 +31,13 @@ func (d *Datastore) SaveHost(host *kolide.Host) error {
 	d.mtx.Lock()
 	defer d.mtx.Unlock()
 
-	if _, ok := d.hosts[host.ID]; !ok {
+	stored, ok := d.hosts[host.ID]
+	if !ok {
 		return notFound("Host").WithID(host.ID)
 	}
+	// The seen time is only written by MarkHostSeen and MarkHostsSeen, so
+	// that saving a host loaded earlier does not move it backwards
+	host.SeenTime = stored.SeenTime
 
 	for _, nic := range host.NetworkInterfaces {
 		if nic.ID == 0 {
@@ -69,7 +74,11 @@ func (d *Datastore) Host(id uint) (*kolide.Host, error) {
 	return host, nil
 }
 
-func (d *Datastore) ListHosts(opt kolide.ListOptions) ([]*kolide.Host, error) {
+func (d *Datastore) ListHosts(opt kolide.ListOptions, filter kolide.HostFilter) ([]*kolide.Host, error) {
+	if err := validateHostFilter(filter); err != nil {
+		return nil, err
+	}
+
 	d.mtx.Lock()
 	defer d.mtx.Unlock()
 
@@ -80,9 +89,14 @@ func (d *Datastore) ListHosts(opt kolide.ListOptions) ([]*kolide.Host, error) {
 	}
 	sort.Ints(keys)
 
+	labelHosts := d.labelHosts(filter.LabelID)
 	hosts := []*kolide.Host{}
 	for _, k := range keys {
-		hosts = append(hosts, d.hosts[uint(k)])
+		host := d.hosts[uint(k)]
+		if !d.hostMatchesFilter(host, filter, labelHosts) {
+			continue
+		}
+		hosts = append(hosts, host)
 	}
 
 	// Apply ordering
@@ -92,6 +106,7 @@ func (d *Datastore) ListHosts(opt kolide.ListOptions) ([]*kolide.Host, error) {
 			"created_at":         "CreatedAt",
 			"updated_at":         "UpdatedAt",
 			"detail_update_time": "DetailUpdateTime",
+			"seen_time":          "SeenTime",
 			"hostname":           "HostName",
 			"uuid":               "UUID",
 			"platform":           "Platform",
@@ -114,12 +129,102 @@ func (d *Datastore) ListHosts(opt kolide.ListOptions) ([]*kolide.Host, error) {
 	return hosts, nil
 }
 
-func (d *Datastore) GenerateHostStatusStatistics(now time.Time) (online, offline, mia uint, err error) {
+func (d *Datastore) CountHosts(filter kolide.HostFilter) (uint, error) {
+	if err := validateHostFilter(filter); err != nil {
+		return 0, err
+	}
+
 	d.mtx.Lock()
 	defer d.mtx.Unlock()
 
+	labelHosts := d.labelHosts(filter.LabelID)
+	var count uint
 	for _, host := range d.hosts {
-		status := host.Status(now)
+		if d.hostMatchesFilter(host, filter, labelHosts) {
+			count++
+		}
+	}
+	return count, nil
+}
+
+// validateHostFilter returns an error when the filter has an attribute filter
+// with an unknown operator, as the SQL datastores do.
+func validateHostFilter(filter kolide.HostFilter) error {
+	for _, f := range filter.Attributes {
+		if !f.ValidOperator() {
+			return fmt.Errorf("unknown host attribute operator %q", f.Operator)
+		}
+	}
+	return nil
+}
+
+// labelHosts returns the IDs of the hosts that are members of the label, or
+// nil when no label is given. The caller must hold the lock.
+func (d *Datastore) labelHosts(labelID uint) map[uint]bool {
+	if labelID == 0 {
+		return nil
+	}
+	hosts := map[uint]bool{}
+	for _, lqe := range d.labelQueryExecutions {
+		if lqe.LabelID == labelID && lqe.Matches {
+			hosts[lqe.HostID] = true
+		}
+	}
+	return hosts
+}
+
+// hostMatchesFilter returns whether the host matches the filter. labelHosts
+// are the members of the label of the filter. The caller must hold the lock.
+func (d *Datastore) hostMatchesFilter(host *kolide.Host, filter kolide.HostFilter, labelHosts map[uint]bool) bool {
+	if filter.Status != "" && host.Status(filter.StatusTime, filter.StatusThresholds) != filter.Status {
+		return false
+	}
+	if filter.Platform != "" && host.Platform != filter.Platform {
+		return false
+	}
+	if filter.OSVersion != "" && host.OSVersion != filter.OSVersion {
+		return false
+	}
+	if filter.OsqueryVersion != "" && host.OsqueryVersion != filter.OsqueryVersion {
+		return false
+	}
+	if labelHosts != nil && !labelHosts[host.ID] {
+		return false
+	}
+	if !filter.SeenAfter.IsZero() && host.SeenTime.Before(filter.SeenAfter) {
+		return false
+	}
+	if !filter.SeenBefore.IsZero() && host.SeenTime.After(filter.SeenBefore) {
+		return false
+	}
+	if filter.Query != "" && !hostMatchesQuery(host, filter.Query) {
+		return false
+	}
+	return d.hostMatchesAttributeFilters(host.ID, filter.Attributes)
+}
+
+// hostMatchesQuery returns whether the hostname, UUID, hardware serial, or
+// an IP or MAC address of the host contains the query, ignoring case.
+func hostMatchesQuery(host *kolide.Host, query string) bool {
+	query = strings.ToLower(query)
+	fields := []string{host.HostName, host.UUID, host.HardwareSerial}
+	for _, nic := range host.NetworkInterfaces {
+		fields = append(fields, nic.IPAddress, nic.MAC)
+	}
+	for _, field := range fields {
+		if strings.Contains(strings.ToLower(field), query) {
+			return true
+		}
+	}
+	return false
+}
+
+func (d *Datastore) GenerateHostStatusStatistics(now time.Time, thresholds kolide.HostStatusThresholds) (online, offline, mia uint, err error) {
+	d.mtx.Lock()
+	defer d.mtx.Unlock()
+
+	for _, host := range d.hosts {
+		status := host.Status(now, thresholds)
 		switch status {
 		case kolide.StatusMIA:
 			mia++
@@ -197,6 +302,32 @@ func (d *Datastore) MarkHostSeen(host *kolide.Host, t time.Time) error {
 	return nil
 }
 
+func (d *Datastore) MarkHostsSeen(hostIDs []uint, t time.Time) error {
+	d.mtx.Lock()
+	defer d.mtx.Unlock()
+
+	for _, id := range hostIDs {
+		if h, ok := d.hosts[id]; ok {
+			h.UpdatedAt = t
+			h.SeenTime = t
+		}
+	}
+	return nil
+}
+
+func (d *Datastore) MarkHostsConfigFetched(hostIDs []uint, hash string, t time.Time) error {
+	d.mtx.Lock()
+	defer d.mtx.Unlock()
+
+	for _, id := range hostIDs {
+		if h, ok := d.hosts[id]; ok {
+			h.ConfigFetchTime = t
+			h.ConfigHash = hash
+		}
+	}
+	return nil
+}
+
 func (d *Datastore) SearchHosts(query string, omit ...uint) ([]*kolide.Host, error) {
 	omitLookup := map[uint]bool{}
 	for _, o := range omit {
@@ -232,6 +363,9 @@ func (d *Datastore) SearchHosts(query string, omit ...uint) ([]*kolide.Host, err
 }
 
 func (d *Datastore) DistributedQueriesForHost(host *kolide.Host) (map[uint]string, error) {
+	d.mtx.Lock()
+	defer d.mtx.Unlock()
+
 	// lookup of executions for this host
 	hostExecutions := map[uint]kolide.DistributedQueryExecutionStatus{}
 	for _, e := range d.distributedQueryExecutions {
@@ -240,28 +374,16 @@ func (d *Datastore) DistributedQueriesForHost(host *kolide.Host) (map[uint]strin
 		}
 	}
 
-	// lookup of labels for this host (only including matching labels)
-	hostLabels := map[uint]bool{}
-	labels, err := d.ListLabelsForHost(host.ID)
-	if err != nil {
-		return nil, err
-	}
-	for _, l := range labels {
-		hostLabels[l.ID] = true
-	}
-
+	// Queries are sent to the hosts that the campaign targets resolved to
+	// when it was created, not to the current members of its labels
 	queries := map[uint]string{} // map campaign ID -> query string
 	for _, campaign := range d.distributedQueryCampaigns {
 		if campaign.Status != kolide.QueryRunning {
 			continue
 		}
-		for _, target := range d.distributedQueryCampaignTargets {
-			if campaign.ID == target.DistributedQueryCampaignID &&
-				((target.Type == kolide.TargetHost && target.TargetID == host.ID) ||
-					(target.Type == kolide.TargetLabel && hostLabels[target.TargetID])) &&
-				(hostExecutions[campaign.ID] == kolide.ExecutionWaiting) {
-				queries[campaign.ID] = d.queries[campaign.QueryID].Query
-			}
+		if d.distributedQueryCampaignHosts[campaign.ID][host.ID] &&
+			hostExecutions[campaign.ID] == kolide.ExecutionWaiting {
+			queries[campaign.ID] = d.queries[campaign.QueryID].Query
 		}
 	}
 
@@ -16,7 +16,7 @@ import (
 type Datastore struct {
 	Driver  string
 	mtx     sync.RWMutex
-	nextIDs map[interface{}]uint
+	nextIDs map[string]uint
 
 	users                           map[uint]*kolide.User
 	sessions                        map[uint]*kolide.Session
@@ -32,11 +32,24 @@ type Datastore struct {
 	distributedQueryExecutions      map[uint]kolide.DistributedQueryExecution
 	distributedQueryCampaigns       map[uint]kolide.DistributedQueryCampaign
 	distributedQueryCampaignTargets map[uint]kolide.DistributedQueryCampaignTarget
+	distributedQueryCampaignHosts   map[uint]map[uint]bool
 	options                         map[uint]*kolide.Option
 	decorators                      map[uint]*kolide.Decorator
 	filePaths                       map[uint]*kolide.FIMSection
 	yaraFilePaths                   kolide.YARAFilePaths
 	yaraSignatureGroups             map[uint]*kolide.YARASignatureGroup
+	revisions                       map[uint]*kolide.Revision
+	scheduledQueryStats             map[uint]map[uint]kolide.ScheduledQueryStats
+	agentLogs                       map[uint][]*kolide.AgentLog
+	agentLogCounts                  map[uint]kolide.AgentLogCounts
+	detailQueries                   map[uint]*kolide.DetailQuery
+	detailQueryExecutions           map[uint]map[uint]time.Time
+	hostAttributes                  map[uint]map[string]kolide.HostAttribute
+	software                        map[uint]*kolide.Software
+	hostSoftware                    map[uint]map[uint]*kolide.HostSoftware
+	vulnerabilities                 map[string]*kolide.Vulnerability
+	softwareVulnerabilities         map[uint]map[string]bool
+	jobs                            map[string]*kolide.Job
 	appConfig                       *kolide.AppConfig
 	config                          *config.KolideConfig
 }
@@ -77,7 +90,7 @@ func (d *Datastore) MigrateTables() error {
 	d.mtx.Lock()
 	defer d.mtx.Unlock()
 
-	d.nextIDs = make(map[interface{}]uint)
+	d.nextIDs = make(map[string]uint)
 	d.users = make(map[uint]*kolide.User)
 	d.sessions = make(map[uint]*kolide.Session)
 	d.passwordResets = make(map[uint]*kolide.PasswordResetRequest)
@@ -92,11 +105,24 @@ func (d *Datastore) MigrateTables() error {
 	d.distributedQueryExecutions = make(map[uint]kolide.DistributedQueryExecution)
 	d.distributedQueryCampaigns = make(map[uint]kolide.DistributedQueryCampaign)
 	d.distributedQueryCampaignTargets = make(map[uint]kolide.DistributedQueryCampaignTarget)
+	d.distributedQueryCampaignHosts = make(map[uint]map[uint]bool)
 	d.options = make(map[uint]*kolide.Option)
 	d.decorators = make(map[uint]*kolide.Decorator)
 	d.filePaths = make(map[uint]*kolide.FIMSection)
 	d.yaraFilePaths = make(kolide.YARAFilePaths)
 	d.yaraSignatureGroups = make(map[uint]*kolide.YARASignatureGroup)
+	d.revisions = make(map[uint]*kolide.Revision)
+	d.scheduledQueryStats = make(map[uint]map[uint]kolide.ScheduledQueryStats)
+	d.agentLogs = make(map[uint][]*kolide.AgentLog)
+	d.agentLogCounts = make(map[uint]kolide.AgentLogCounts)
+	d.detailQueries = make(map[uint]*kolide.DetailQuery)
+	d.detailQueryExecutions = make(map[uint]map[uint]time.Time)
+	d.hostAttributes = make(map[uint]map[string]kolide.HostAttribute)
+	d.software = make(map[uint]*kolide.Software)
+	d.hostSoftware = make(map[uint]map[uint]*kolide.HostSoftware)
+	d.vulnerabilities = make(map[string]*kolide.Vulnerability)
+	d.softwareVulnerabilities = make(map[uint]map[string]bool)
+	d.jobs = make(map[string]*kolide.Job)
 
 	return nil
 }
@@ -181,9 +207,9 @@ func (d *Datastore) getLimitOffsetSliceBounds(opt kolide.ListOptions, length int
 }
 
 // nextID returns the next ID value that should be used for a struct of the
-// given type
+// given type. IDs are tracked by type name so that they can be snapshotted.
 func (d *Datastore) nextID(val interface{}) uint {
-	valType := reflect.TypeOf(reflect.Indirect(reflect.ValueOf(val)).Interface())
+	valType := reflect.TypeOf(reflect.Indirect(reflect.ValueOf(val)).Interface()).String()
 	d.nextIDs[valType]++
 	return d.nextIDs[valType]
 }
@@ -0,0 +1,73 @@
+package inmem
+
+import (
+	"sort"
+	"time"
+
+	"github.com/kolide/kolide-ose/server/kolide"
+)
+
+func (d *Datastore) RegisterJob(name string, interval time.Duration) error {
+	d.mtx.Lock()
+	defer d.mtx.Unlock()
+
+	job, ok := d.jobs[name]
+	if !ok {
+		job = &kolide.Job{Name: name}
+		d.jobs[name] = job
+	}
+	job.Interval = interval
+	return nil
+}
+
+func (d *Datastore) LockJob(name, owner string, interval time.Duration, now time.Time) (bool, error) {
+	d.mtx.Lock()
+	defer d.mtx.Unlock()
+
+	job, ok := d.jobs[name]
+	if !ok || job.LockedUntil.After(now) {
+		return false, nil
+	}
+	job.Interval = interval
+	job.LockOwner = owner
+	job.LockedUntil = now.Add(interval)
+	job.LastInstance = owner
+	job.LastStartedAt = now
+	return true, nil
+}
+
+func (d *Datastore) FinishJob(name, owner string, finished time.Time, runErr string) error {
+	d.mtx.Lock()
+	defer d.mtx.Unlock()
+
+	if job, ok := d.jobs[name]; ok && job.LastInstance == owner {
+		job.LastFinishedAt = finished
+		job.LastError = runErr
+	}
+	return nil
+}
+
+func (d *Datastore) TriggerJob(name string, now time.Time) error {
+	d.mtx.Lock()
+	defer d.mtx.Unlock()
+
+	if job, ok := d.jobs[name]; ok && job.LockedUntil.After(now) {
+		job.LockedUntil = now
+	}
+	return nil
+}
+
+func (d *Datastore) ListJobs() ([]*kolide.Job, error) {
+	d.mtx.Lock()
+	defer d.mtx.Unlock()
+
+	jobs := []*kolide.Job{}
+	for _, job := range d.jobs {
+		j := *job
+		jobs = append(jobs, &j)
+	}
+	sort.Slice(jobs, func(i, j int) bool {
+		return jobs[i].Name < jobs[j].Name
+	})
+	return jobs, nil
+}
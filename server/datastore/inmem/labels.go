@@ -1,7 +1,6 @@
 package inmem
 
 import (
-	"errors"
 	"sort"
 	"strconv"
 	"strings"
@@ -59,6 +58,9 @@ func (d *Datastore) LabelQueriesForHost(host *kolide.Host, cutoff time.Time) (ma
 
 	queries := map[string]string{}
 	for _, label := range d.labels {
+		if label.LabelType == kolide.LabelTypeAttribute {
+			continue
+		}
 		if (label.Platform == "" || strings.Contains(label.Platform, host.Platform)) && !execedIDs[label.ID] {
 			queries[strconv.Itoa(int(label.ID))] = label.Query
 		}
@@ -118,7 +120,7 @@ func (d *Datastore) Label(lid uint) (*kolide.Label, error) {
 	d.mtx.Unlock()
 
 	if !ok {
-		return nil, errors.New("Label not found")
+		return nil, notFound("Label").WithID(lid)
 	}
 	return label, nil
 }
@@ -0,0 +1,26 @@
+package inmem
+
+import (
+	"errors"
+
+	"github.com/kolide/kolide-ose/server/kolide"
+)
+
+// The inmem datastore has no versioned migrations. Its tables and built in
+// data are created with the datastore, so there is never a pending migration.
+
+func (d *Datastore) MigrationStatus() (*kolide.MigrationStatus, error) {
+	return &kolide.MigrationStatus{Tables: []*kolide.Migration{}, Data: []*kolide.Migration{}}, nil
+}
+
+func (d *Datastore) RollbackMigrations(version int64) error {
+	return errors.New("the inmem datastore does not support rolling back migrations")
+}
+
+func (d *Datastore) PlanMigrations() ([]*kolide.MigrationStep, error) {
+	return []*kolide.MigrationStep{}, nil
+}
+
+func (d *Datastore) PlanRollback(version int64) ([]*kolide.MigrationStep, error) {
+	return nil, errors.New("the inmem datastore does not support rolling back migrations")
+}
@@ -312,3 +312,60 @@ func (d *Datastore) ListExplicitHostsInPack(pid uint, opt kolide.ListOptions) ([
 
 	return hosts, nil
 }
+
+func (d *Datastore) ListPackTargetsForHost(hid uint) ([]*kolide.HostPackTarget, error) {
+	d.mtx.Lock()
+	defer d.mtx.Unlock()
+
+	targets := []*kolide.HostPackTarget{}
+	for _, pt := range d.packTargets {
+		pack, ok := d.packs[pt.PackID]
+		if !ok || pack.Disabled {
+			continue
+		}
+
+		target := &kolide.HostPackTarget{
+			Pack:       *pack,
+			TargetType: pt.Type,
+			TargetID:   pt.TargetID,
+		}
+		switch pt.Type {
+		case kolide.TargetHost:
+			if pt.TargetID != hid {
+				continue
+			}
+		case kolide.TargetLabel:
+			label, ok := d.labels[pt.TargetID]
+			if !ok || !d.hostMatchesLabel(hid, pt.TargetID) {
+				continue
+			}
+			target.LabelName = label.Name
+		default:
+			continue
+		}
+		targets = append(targets, target)
+	}
+
+	sort.Slice(targets, func(i, j int) bool {
+		if targets[i].ID != targets[j].ID {
+			return targets[i].ID < targets[j].ID
+		}
+		if targets[i].TargetType != targets[j].TargetType {
+			return targets[i].TargetType < targets[j].TargetType
+		}
+		return targets[i].TargetID < targets[j].TargetID
+	})
+
+	return targets, nil
+}
+
+// hostMatchesLabel reports whether the host is a member of the label. The
+// caller must hold d.mtx.
+func (d *Datastore) hostMatchesLabel(hid, lid uint) bool {
+	for _, lqe := range d.labelQueryExecutions {
+		if lqe.HostID == hid && lqe.LabelID == lid && lqe.Matches {
+			return true
+		}
+	}
+	return false
+}
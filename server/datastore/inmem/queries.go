@@ -2,6 +2,7 @@ package inmem
 
 import (
 	"sort"
+	"strings"
 
 	"github.com/kolide/kolide-ose/server/kolide"
 	"github.com/pkg/errors"
@@ -103,7 +104,7 @@ func (d *Datastore) Query(id uint) (*kolide.Query, error) {
 	return query, nil
 }
 
-func (d *Datastore) ListQueries(opt kolide.ListOptions) ([]*kolide.Query, error) {
+func (d *Datastore) ListQueries(opt kolide.ListOptions, filter kolide.QueryFilter) ([]*kolide.Query, error) {
 	d.mtx.Lock()
 	defer d.mtx.Unlock()
 
@@ -117,7 +118,7 @@ func (d *Datastore) ListQueries(opt kolide.ListOptions) ([]*kolide.Query, error)
 	queries := []*kolide.Query{}
 	for _, k := range keys {
 		q := d.queries[uint(k)]
-		if q.Saved {
+		if queryMatchesFilter(q, filter) {
 			q.AuthorName = d.getUserNameByID(q.AuthorID)
 			queries = append(queries, q)
 		}
@@ -131,6 +132,7 @@ func (d *Datastore) ListQueries(opt kolide.ListOptions) ([]*kolide.Query, error)
 			"updated_at":   "UpdatedAt",
 			"name":         "Name",
 			"query":        "Query",
+			"category":     "Category",
 			"interval":     "Interval",
 			"snapshot":     "Snapshot",
 			"differential": "Differential",
@@ -153,6 +155,43 @@ func (d *Datastore) ListQueries(opt kolide.ListOptions) ([]*kolide.Query, error)
 	return queries, nil
 }
 
+// queryMatchesFilter returns whether the query is one of those selected by
+// the filter.
+func queryMatchesFilter(q *kolide.Query, filter kolide.QueryFilter) bool {
+	saved := true
+	if filter.Saved != nil {
+		saved = *filter.Saved
+	}
+	if q.Saved != saved {
+		return false
+	}
+	if filter.AuthorID != nil && q.AuthorID != *filter.AuthorID {
+		return false
+	}
+	if filter.Category != "" && q.Category != filter.Category {
+		return false
+	}
+
+	tags := map[string]bool{}
+	for _, tag := range q.Tags {
+		tags[tag] = true
+	}
+	for _, tag := range filter.Tags {
+		if !tags[tag] {
+			return false
+		}
+	}
+
+	text := strings.ToLower(q.Name + "\n" + q.Description + "\n" + q.Query)
+	for _, word := range strings.Fields(strings.ToLower(filter.Search)) {
+		if !strings.Contains(text, word) {
+			return false
+		}
+	}
+
+	return true
+}
+
 // loadPacksForQueries loads the packs associated with the provided queries
 func (d *Datastore) loadPacksForQueries(queries []*kolide.Query) error {
 	for _, q := range queries {
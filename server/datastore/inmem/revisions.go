@@ -0,0 +1,59 @@
+package inmem
+
+import (
+	"sort"
+
+	"github.com/kolide/kolide-ose/server/kolide"
+)
+
+func (d *Datastore) NewRevision(rev *kolide.Revision) (*kolide.Revision, error) {
+	d.mtx.Lock()
+	defer d.mtx.Unlock()
+
+	newRevision := *rev
+	newRevision.ID = d.nextID(newRevision)
+	d.revisions[newRevision.ID] = &newRevision
+
+	return &newRevision, nil
+}
+
+func (d *Datastore) Revision(id uint) (*kolide.Revision, error) {
+	d.mtx.Lock()
+	defer d.mtx.Unlock()
+
+	rev, ok := d.revisions[id]
+	if !ok {
+		return nil, notFound("Revision").WithID(id)
+	}
+
+	result := *rev
+	if rev.AuthorID != nil {
+		result.AuthorName = d.getUserNameByID(*rev.AuthorID)
+	}
+	return &result, nil
+}
+
+func (d *Datastore) ListRevisions(objectType string, objectID uint, opt kolide.ListOptions) ([]*kolide.Revision, error) {
+	d.mtx.Lock()
+	defer d.mtx.Unlock()
+
+	revisions := []*kolide.Revision{}
+	for _, rev := range d.revisions {
+		if rev.ObjectType != objectType || rev.ObjectID != objectID {
+			continue
+		}
+		result := *rev
+		if rev.AuthorID != nil {
+			result.AuthorName = d.getUserNameByID(*rev.AuthorID)
+		}
+		revisions = append(revisions, &result)
+	}
+
+	// Newest first
+	sort.Slice(revisions, func(i, j int) bool {
+		return revisions[i].ID > revisions[j].ID
+	})
+
+	low, high := d.getLimitOffsetSliceBounds(opt, len(revisions))
+	return revisions[low:high], nil
+}
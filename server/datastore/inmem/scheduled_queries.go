@@ -111,3 +111,27 @@ func (d *Datastore) ListScheduledQueriesInPack(id uint, opt kolide.ListOptions)
 
 	return scheduledQueries, nil
 }
+
+func (d *Datastore) ListScheduledQueryNames() ([]*kolide.ScheduledQueryName, error) {
+	d.mtx.Lock()
+	defer d.mtx.Unlock()
+
+	names := []*kolide.ScheduledQueryName{}
+	for _, sq := range d.scheduledQueries {
+		pack, ok := d.packs[sq.PackID]
+		if !ok {
+			continue
+		}
+		query, ok := d.queries[sq.QueryID]
+		if !ok {
+			continue
+		}
+		names = append(names, &kolide.ScheduledQueryName{
+			ID:        sq.ID,
+			PackName:  pack.Name,
+			QueryName: query.Name,
+		})
+	}
+	sort.Slice(names, func(i, j int) bool { return names[i].ID < names[j].ID })
+	return names, nil
+}
@@ -0,0 +1,75 @@
+package inmem
+
+import (
+	"sort"
+
+	"github.com/kolide/kolide-ose/server/kolide"
+)
+
+func (d *Datastore) SaveScheduledQueryStats(hostID uint, stats []kolide.ScheduledQueryStats) error {
+	d.mtx.Lock()
+	defer d.mtx.Unlock()
+
+	hostStats := map[uint]kolide.ScheduledQueryStats{}
+	for _, s := range stats {
+		s.HostID = hostID
+		hostStats[s.ScheduledQueryID] = s
+	}
+	d.scheduledQueryStats[hostID] = hostStats
+
+	return nil
+}
+
+func (d *Datastore) ScheduledQueryStatsInPack(packID uint) ([]*kolide.ScheduledQueryStatsRollup, error) {
+	d.mtx.Lock()
+	defer d.mtx.Unlock()
+
+	results := []*kolide.ScheduledQueryStatsRollup{}
+	for _, sq := range d.scheduledQueries {
+		if sq.PackID != packID {
+			continue
+		}
+
+		rollup := &kolide.ScheduledQueryStatsRollup{
+			ScheduledQueryID: sq.ID,
+			PackID:           sq.PackID,
+			QueryID:          sq.QueryID,
+			Name:             d.queries[sq.QueryID].Name,
+			Interval:         sq.Interval,
+		}
+		var totalMemory uint64
+		for hostID, hostStats := range d.scheduledQueryStats {
+			if _, ok := d.hosts[hostID]; !ok {
+				continue
+			}
+			s, ok := hostStats[sq.ID]
+			if !ok {
+				continue
+			}
+			rollup.HostCount++
+			if s.Denylisted {
+				rollup.DenylistedCount++
+			}
+			rollup.Executions += s.Executions
+			rollup.WallTime += s.WallTime
+			rollup.UserTime += s.UserTime
+			rollup.SystemTime += s.SystemTime
+			rollup.OutputSize += s.OutputSize
+			totalMemory += s.AverageMemory
+			if s.AverageMemory > rollup.MaxMemory {
+				rollup.MaxMemory = s.AverageMemory
+			}
+		}
+		if rollup.HostCount > 0 {
+			rollup.AverageMemory = totalMemory / uint64(rollup.HostCount)
+		}
+		rollup.ComputeAverages()
+		results = append(results, rollup)
+	}
+
+	sort.Slice(results, func(i, j int) bool {
+		return results[i].ScheduledQueryID < results[j].ScheduledQueryID
+	})
+
+	return results, nil
+}
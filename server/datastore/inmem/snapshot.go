@@ -0,0 +1,217 @@
+package inmem
+
+import (
+	"encoding/gob"
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"time"
+
+	"github.com/kolide/kolide-ose/server/kolide"
+	"github.com/pkg/errors"
+)
+
+// snapshot is the state of the datastore, with exported fields so that it
+// can be gob encoded. Revisions are JSON encoded, as their snapshots and
+// changes hold interface values of types that gob cannot encode.
+type snapshot struct {
+	NextIDs                         map[string]uint
+	Users                           map[uint]*kolide.User
+	Sessions                        map[uint]*kolide.Session
+	PasswordResets                  map[uint]*kolide.PasswordResetRequest
+	Invites                         map[uint]*kolide.Invite
+	Labels                          map[uint]*kolide.Label
+	LabelQueryExecutions            map[uint]*kolide.LabelQueryExecution
+	Queries                         map[uint]*kolide.Query
+	Packs                           map[uint]*kolide.Pack
+	Hosts                           map[uint]*kolide.Host
+	ScheduledQueries                map[uint]*kolide.ScheduledQuery
+	PackTargets                     map[uint]*kolide.PackTarget
+	DistributedQueryExecutions      map[uint]kolide.DistributedQueryExecution
+	DistributedQueryCampaigns       map[uint]kolide.DistributedQueryCampaign
+	DistributedQueryCampaignTargets map[uint]kolide.DistributedQueryCampaignTarget
+	DistributedQueryCampaignHosts   map[uint]map[uint]bool
+	Options                         map[uint]*kolide.Option
+	Decorators                      map[uint]*kolide.Decorator
+	FilePaths                       map[uint]*kolide.FIMSection
+	YARAFilePaths                   kolide.YARAFilePaths
+	YARASignatureGroups             map[uint]*kolide.YARASignatureGroup
+	Revisions                       map[uint][]byte
+	ScheduledQueryStats             map[uint]map[uint]kolide.ScheduledQueryStats
+	AgentLogs                       map[uint][]*kolide.AgentLog
+	AgentLogCounts                  map[uint]kolide.AgentLogCounts
+	DetailQueries                   map[uint]*kolide.DetailQuery
+	DetailQueryExecutions           map[uint]map[uint]time.Time
+	HostAttributes                  map[uint]map[string]kolide.HostAttribute
+	Software                        map[uint]*kolide.Software
+	HostSoftware                    map[uint]map[uint]*kolide.HostSoftware
+	Vulnerabilities                 map[string]*kolide.Vulnerability
+	SoftwareVulnerabilities         map[uint]map[string]bool
+	AppConfig                       *kolide.AppConfig
+}
+
+// Snapshot writes the state of the datastore to w, in the format read by
+// Restore.
+func (d *Datastore) Snapshot(w io.Writer) error {
+	d.mtx.RLock()
+	defer d.mtx.RUnlock()
+
+	s := snapshot{
+		NextIDs:                         d.nextIDs,
+		Users:                           d.users,
+		Sessions:                        d.sessions,
+		PasswordResets:                  d.passwordResets,
+		Invites:                         d.invites,
+		Labels:                          d.labels,
+		LabelQueryExecutions:            d.labelQueryExecutions,
+		Queries:                         d.queries,
+		Packs:                           d.packs,
+		Hosts:                           d.hosts,
+		ScheduledQueries:                d.scheduledQueries,
+		PackTargets:                     d.packTargets,
+		DistributedQueryExecutions:      d.distributedQueryExecutions,
+		DistributedQueryCampaigns:       d.distributedQueryCampaigns,
+		DistributedQueryCampaignTargets: d.distributedQueryCampaignTargets,
+		DistributedQueryCampaignHosts:   d.distributedQueryCampaignHosts,
+		Options:                         d.options,
+		Decorators:                      d.decorators,
+		FilePaths:                       d.filePaths,
+		YARAFilePaths:                   d.yaraFilePaths,
+		YARASignatureGroups:             d.yaraSignatureGroups,
+		Revisions:                       map[uint][]byte{},
+		ScheduledQueryStats:             d.scheduledQueryStats,
+		AgentLogs:                       d.agentLogs,
+		AgentLogCounts:                  d.agentLogCounts,
+		DetailQueries:                   d.detailQueries,
+		DetailQueryExecutions:           d.detailQueryExecutions,
+		HostAttributes:                  d.hostAttributes,
+		Software:                        d.software,
+		HostSoftware:                    d.hostSoftware,
+		Vulnerabilities:                 d.vulnerabilities,
+		SoftwareVulnerabilities:         d.softwareVulnerabilities,
+		AppConfig:                       d.appConfig,
+	}
+	for id, revision := range d.revisions {
+		encoded, err := json.Marshal(revision)
+		if err != nil {
+			return errors.Wrap(err, "encoding snapshot revision")
+		}
+		s.Revisions[id] = encoded
+	}
+	return errors.Wrap(gob.NewEncoder(w).Encode(&s), "encoding snapshot")
+}
+
+// Restore replaces the state of the datastore with a snapshot read from r.
+func (d *Datastore) Restore(r io.Reader) error {
+	var s snapshot
+	if err := gob.NewDecoder(r).Decode(&s); err != nil {
+		return errors.Wrap(err, "decoding snapshot")
+	}
+
+	revisions := map[uint]*kolide.Revision{}
+	for id, encoded := range s.Revisions {
+		var revision kolide.Revision
+		if err := json.Unmarshal(encoded, &revision); err != nil {
+			return errors.Wrap(err, "decoding snapshot revision")
+		}
+		revisions[id] = &revision
+	}
+
+	// Start from empty tables, as gob leaves empty maps in the snapshot nil
+	if err := d.MigrateTables(); err != nil {
+		return err
+	}
+
+	d.mtx.Lock()
+	defer d.mtx.Unlock()
+
+	copyMap(d.nextIDs, s.NextIDs)
+	copyMap(d.users, s.Users)
+	copyMap(d.sessions, s.Sessions)
+	copyMap(d.passwordResets, s.PasswordResets)
+	copyMap(d.invites, s.Invites)
+	copyMap(d.labels, s.Labels)
+	copyMap(d.labelQueryExecutions, s.LabelQueryExecutions)
+	copyMap(d.queries, s.Queries)
+	copyMap(d.packs, s.Packs)
+	copyMap(d.hosts, s.Hosts)
+	copyMap(d.scheduledQueries, s.ScheduledQueries)
+	copyMap(d.packTargets, s.PackTargets)
+	copyMap(d.distributedQueryExecutions, s.DistributedQueryExecutions)
+	copyMap(d.distributedQueryCampaigns, s.DistributedQueryCampaigns)
+	copyMap(d.distributedQueryCampaignTargets, s.DistributedQueryCampaignTargets)
+	copyMap(d.distributedQueryCampaignHosts, s.DistributedQueryCampaignHosts)
+	copyMap(d.options, s.Options)
+	copyMap(d.decorators, s.Decorators)
+	copyMap(d.filePaths, s.FilePaths)
+	copyMap(d.yaraFilePaths, s.YARAFilePaths)
+	copyMap(d.yaraSignatureGroups, s.YARASignatureGroups)
+	copyMap(d.revisions, revisions)
+	copyMap(d.scheduledQueryStats, s.ScheduledQueryStats)
+	copyMap(d.agentLogs, s.AgentLogs)
+	copyMap(d.agentLogCounts, s.AgentLogCounts)
+	copyMap(d.detailQueries, s.DetailQueries)
+	copyMap(d.detailQueryExecutions, s.DetailQueryExecutions)
+	copyMap(d.hostAttributes, s.HostAttributes)
+	copyMap(d.software, s.Software)
+	copyMap(d.hostSoftware, s.HostSoftware)
+	copyMap(d.vulnerabilities, s.Vulnerabilities)
+	copyMap(d.softwareVulnerabilities, s.SoftwareVulnerabilities)
+	d.appConfig = s.AppConfig
+
+	return nil
+}
+
+// copyMap copies the entries of the map src into the map dst.
+func copyMap(dst, src interface{}) {
+	dstVal, srcVal := reflect.ValueOf(dst), reflect.ValueOf(src)
+	for _, key := range srcVal.MapKeys() {
+		dstVal.SetMapIndex(key, srcVal.MapIndex(key))
+	}
+}
+
+// SaveSnapshotFile writes a snapshot of the datastore to the file at path.
+// The snapshot is written to a temporary file that replaces the file once it
+// is complete, so that a failed snapshot leaves the previous one intact.
+func (d *Datastore) SaveSnapshotFile(path string) (err error) {
+	tmp, err := ioutil.TempFile(filepath.Dir(path), filepath.Base(path)+".tmp")
+	if err != nil {
+		return errors.Wrap(err, "creating snapshot file")
+	}
+	defer func() {
+		if err != nil {
+			tmp.Close()
+			os.Remove(tmp.Name())
+		}
+	}()
+
+	if err = d.Snapshot(tmp); err != nil {
+		return err
+	}
+	if err = tmp.Close(); err != nil {
+		return errors.Wrap(err, "closing snapshot file")
+	}
+	return errors.Wrap(os.Rename(tmp.Name(), path), "replacing snapshot file")
+}
+
+// LoadSnapshotFile restores the datastore from the snapshot in the file at
+// path. It returns false, leaving the datastore as is, when the file does not
+// exist.
+func (d *Datastore) LoadSnapshotFile(path string) (bool, error) {
+	f, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return false, nil
+	}
+	if err != nil {
+		return false, errors.Wrap(err, "opening snapshot file")
+	}
+	defer f.Close()
+
+	if err := d.Restore(f); err != nil {
+		return false, err
+	}
+	return true, nil
+}
@@ -0,0 +1,127 @@
+package inmem
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/kolide/kolide-ose/server/config"
+	"github.com/kolide/kolide-ose/server/kolide"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSnapshotRestore(t *testing.T) {
+	ds, err := New(config.TestConfig())
+	require.Nil(t, err)
+	require.Nil(t, ds.MigrateData())
+	require.Nil(t, ds.Initialize())
+
+	var buf bytes.Buffer
+	require.Nil(t, ds.Snapshot(&buf))
+
+	restored, err := New(config.TestConfig())
+	require.Nil(t, err)
+	require.Nil(t, restored.Restore(&buf))
+
+	users, err := ds.ListUsers(kolide.ListOptions{})
+	require.Nil(t, err)
+	restoredUsers, err := restored.ListUsers(kolide.ListOptions{})
+	require.Nil(t, err)
+	require.Len(t, restoredUsers, len(users))
+	assert.Nil(t, restoredUsers[0].ValidatePassword(users[0].Username))
+
+	hosts, err := ds.ListHosts(kolide.ListOptions{}, kolide.HostFilter{})
+	require.Nil(t, err)
+	restoredHosts, err := restored.ListHosts(kolide.ListOptions{}, kolide.HostFilter{})
+	require.Nil(t, err)
+	assert.Len(t, restoredHosts, len(hosts))
+
+	options, err := ds.ListOptions()
+	require.Nil(t, err)
+	restoredOptions, err := restored.ListOptions()
+	require.Nil(t, err)
+	assert.Equal(t, options, restoredOptions)
+
+	// Revision snapshots hold slices and maps, which are not gob encodable
+	// as interface values
+	pack := &kolide.Pack{Name: "monitoring"}
+	pack, err = ds.NewPack(pack)
+	require.Nil(t, err)
+	authorID := uint(1)
+	revision, err := ds.NewRevision(&kolide.Revision{
+		ObjectType: kolide.RevisionObjectPack,
+		ObjectID:   pack.ID,
+		Action:     kolide.RevisionActionCreated,
+		AuthorID:   &authorID,
+		Snapshot: kolide.RevisionSnapshot{
+			"name":      "monitoring",
+			"host_ids":  []interface{}{float64(1), float64(2)},
+			"label_ids": []interface{}{},
+			"platform":  map[string]interface{}{"os": "darwin"},
+		},
+		Changes: kolide.RevisionChanges{
+			{Field: "host_ids", Old: []interface{}{}, New: []interface{}{float64(1), float64(2)}},
+		},
+	})
+	require.Nil(t, err)
+
+	buf.Reset()
+	require.Nil(t, ds.Snapshot(&buf))
+	restored, err = New(config.TestConfig())
+	require.Nil(t, err)
+	require.Nil(t, restored.Restore(&buf))
+
+	restoredRevision, err := restored.Revision(revision.ID)
+	require.Nil(t, err)
+	assert.Equal(t, revision.Snapshot, restoredRevision.Snapshot)
+	assert.Equal(t, revision.Changes, restoredRevision.Changes)
+	assert.Equal(t, revision.AuthorID, restoredRevision.AuthorID)
+	assert.True(t, revision.CreatedAt.Equal(restoredRevision.CreatedAt))
+
+	// IDs continue from where the snapshotted datastore left off
+	query, err := ds.NewQuery(&kolide.Query{Name: "new", Query: "select 1"})
+	require.Nil(t, err)
+	restoredQuery, err := restored.NewQuery(&kolide.Query{Name: "new", Query: "select 1"})
+	require.Nil(t, err)
+	assert.Equal(t, query.ID, restoredQuery.ID)
+}
+
+func TestSnapshotFile(t *testing.T) {
+	dir, err := ioutil.TempDir("", "kolide-snapshot")
+	require.Nil(t, err)
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "kolide.snapshot")
+
+	ds, err := New(config.TestConfig())
+	require.Nil(t, err)
+
+	loaded, err := ds.LoadSnapshotFile(path)
+	require.Nil(t, err)
+	assert.False(t, loaded)
+
+	require.Nil(t, ds.MigrateData())
+	_, err = ds.NewLabel(&kolide.Label{Name: "saved", Query: "select 1"})
+	require.Nil(t, err)
+	require.Nil(t, ds.SaveSnapshotFile(path))
+
+	restored, err := New(config.TestConfig())
+	require.Nil(t, err)
+	loaded, err = restored.LoadSnapshotFile(path)
+	require.Nil(t, err)
+	assert.True(t, loaded)
+
+	labels, err := restored.ListLabels(kolide.ListOptions{})
+	require.Nil(t, err)
+	names := []string{}
+	for _, label := range labels {
+		names = append(names, label.Name)
+	}
+	assert.Contains(t, names, "saved")
+
+	files, err := ioutil.ReadDir(dir)
+	require.Nil(t, err)
+	assert.Len(t, files, 1)
+}
@@ -0,0 +1,205 @@
+package inmem
+
+import (
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/kolide/kolide-ose/server/kolide"
+)
+
+func (d *Datastore) SaveHostSoftware(hostID uint, source string, software []kolide.Software, seen time.Time) error {
+	d.mtx.Lock()
+	defer d.mtx.Unlock()
+
+	if d.hostSoftware[hostID] == nil {
+		d.hostSoftware[hostID] = map[uint]*kolide.HostSoftware{}
+	}
+	installed := d.hostSoftware[hostID]
+
+	reported := map[uint]bool{}
+	for i, s := range software {
+		id := d.softwareID(s.Name, s.Version, source)
+		software[i].ID = id
+		reported[id] = true
+		if hs, ok := installed[id]; ok {
+			hs.LastSeen = seen
+			continue
+		}
+		installed[id] = &kolide.HostSoftware{
+			Software:  *d.software[id],
+			FirstSeen: seen,
+			LastSeen:  seen,
+		}
+	}
+
+	for id, hs := range installed {
+		if hs.Source == source && !reported[id] {
+			delete(installed, id)
+		}
+	}
+
+	return nil
+}
+
+// softwareID returns the ID of the software, creating it if no host has
+// reported it before. The caller must hold the lock.
+func (d *Datastore) softwareID(name, version, source string) uint {
+	for _, s := range d.software {
+		if s.Name == name && s.Version == version && s.Source == source {
+			return s.ID
+		}
+	}
+	s := &kolide.Software{Name: name, Version: version, Source: source}
+	s.ID = d.nextID(s)
+	d.software[s.ID] = s
+	return s.ID
+}
+
+var softwareSortFields = map[string]string{
+	"id":      "ID",
+	"name":    "Name",
+	"version": "Version",
+	"source":  "Source",
+}
+
+func (d *Datastore) ListHostSoftware(hostID uint, opt kolide.ListOptions) ([]*kolide.HostSoftware, error) {
+	d.mtx.Lock()
+	defer d.mtx.Unlock()
+
+	software := []*kolide.HostSoftware{}
+	for _, hs := range d.hostSoftware[hostID] {
+		result := *hs
+		software = append(software, &result)
+	}
+	sort.Slice(software, func(i, j int) bool {
+		return softwareLess(&software[i].Software, &software[j].Software)
+	})
+
+	if opt.OrderKey != "" {
+		fields := map[string]string{
+			"first_seen": "FirstSeen",
+			"last_seen":  "LastSeen",
+		}
+		for k, v := range softwareSortFields {
+			fields[k] = v
+		}
+		if err := sortResults(software, opt, fields); err != nil {
+			return nil, err
+		}
+	}
+
+	low, high := d.getLimitOffsetSliceBounds(opt, len(software))
+	return software[low:high], nil
+}
+
+func (d *Datastore) SearchSoftware(filter kolide.SoftwareFilter, opt kolide.ListOptions) ([]*kolide.SoftwareCount, error) {
+	d.mtx.Lock()
+	defer d.mtx.Unlock()
+
+	counts := map[uint]uint{}
+	for hostID, installed := range d.hostSoftware {
+		if _, ok := d.hosts[hostID]; !ok {
+			continue
+		}
+		for id := range installed {
+			counts[id]++
+		}
+	}
+
+	name := strings.ToLower(filter.Name)
+	software := []*kolide.SoftwareCount{}
+	for id, count := range counts {
+		s := d.software[id]
+		if !strings.Contains(strings.ToLower(s.Name), name) {
+			continue
+		}
+		if filter.Version != "" && s.Version != filter.Version {
+			continue
+		}
+		if filter.Source != "" && s.Source != filter.Source {
+			continue
+		}
+		software = append(software, &kolide.SoftwareCount{Software: *s, HostCount: count})
+	}
+	sort.Slice(software, func(i, j int) bool {
+		return softwareLess(&software[i].Software, &software[j].Software)
+	})
+
+	if opt.OrderKey != "" {
+		fields := map[string]string{
+			"host_count": "HostCount",
+		}
+		for k, v := range softwareSortFields {
+			fields[k] = v
+		}
+		if err := sortResults(software, opt, fields); err != nil {
+			return nil, err
+		}
+	}
+
+	low, high := d.getLimitOffsetSliceBounds(opt, len(software))
+	return software[low:high], nil
+}
+
+func (d *Datastore) Software(id uint) (*kolide.Software, error) {
+	d.mtx.Lock()
+	defer d.mtx.Unlock()
+
+	software, ok := d.software[id]
+	if !ok {
+		return nil, notFound("Software").WithID(id)
+	}
+	result := *software
+	return &result, nil
+}
+
+func (d *Datastore) ListSoftwareInstalls(softwareID uint, opt kolide.ListOptions) ([]*kolide.SoftwareInstall, error) {
+	d.mtx.Lock()
+	defer d.mtx.Unlock()
+
+	installs := []*kolide.SoftwareInstall{}
+	for hostID, installed := range d.hostSoftware {
+		host, ok := d.hosts[hostID]
+		if !ok {
+			continue
+		}
+		if hs, ok := installed[softwareID]; ok {
+			installs = append(installs, &kolide.SoftwareInstall{
+				HostID:    hostID,
+				HostName:  host.HostName,
+				FirstSeen: hs.FirstSeen,
+				LastSeen:  hs.LastSeen,
+			})
+		}
+	}
+	sort.Slice(installs, func(i, j int) bool {
+		if installs[i].HostName != installs[j].HostName {
+			return installs[i].HostName < installs[j].HostName
+		}
+		return installs[i].HostID < installs[j].HostID
+	})
+
+	if opt.OrderKey != "" {
+		fields := map[string]string{
+			"host_id":    "HostID",
+			"hostname":   "HostName",
+			"first_seen": "FirstSeen",
+			"last_seen":  "LastSeen",
+		}
+		if err := sortResults(installs, opt, fields); err != nil {
+			return nil, err
+		}
+	}
+
+	low, high := d.getLimitOffsetSliceBounds(opt, len(installs))
+	return installs[low:high], nil
+}
+
+// softwareLess orders software by name and then version.
+func softwareLess(a, b *kolide.Software) bool {
+	if a.Name != b.Name {
+		return a.Name < b.Name
+	}
+	return a.Version < b.Version
+}
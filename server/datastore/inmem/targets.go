@@ -0,0 +1,80 @@
+package inmem
+
+import (
+	"sort"
+
+	"github.com/kolide/kolide-ose/server/kolide"
+)
+
+// targetHosts returns the hosts in the selected targets, ordered by hostname
+// and then ID. The caller must hold the lock.
+func (d *Datastore) targetHosts(hostIDs []uint, labelIDs []uint) []*kolide.Host {
+	selected := map[uint]bool{}
+	for _, id := range hostIDs {
+		selected[id] = true
+	}
+	labelSet := map[uint]bool{}
+	for _, id := range labelIDs {
+		labelSet[id] = true
+	}
+	for _, lqe := range d.labelQueryExecutions {
+		if labelSet[lqe.LabelID] && lqe.Matches {
+			selected[lqe.HostID] = true
+		}
+	}
+
+	hosts := []*kolide.Host{}
+	for id := range selected {
+		if host, ok := d.hosts[id]; ok {
+			hosts = append(hosts, host)
+		}
+	}
+	sort.Slice(hosts, func(i, j int) bool {
+		if hosts[i].HostName != hosts[j].HostName {
+			return hosts[i].HostName < hosts[j].HostName
+		}
+		return hosts[i].ID < hosts[j].ID
+	})
+	return hosts
+}
+
+func (d *Datastore) ListTargetHosts(hostIDs []uint, labelIDs []uint, opt kolide.ListOptions) ([]*kolide.Host, error) {
+	d.mtx.Lock()
+	defer d.mtx.Unlock()
+
+	hosts := d.targetHosts(hostIDs, labelIDs)
+	low, high := d.getLimitOffsetSliceBounds(opt, len(hosts))
+	return hosts[low:high], nil
+}
+
+func (d *Datastore) CountTargetHosts(hostIDs []uint, labelIDs []uint) (uint, error) {
+	d.mtx.Lock()
+	defer d.mtx.Unlock()
+
+	return uint(len(d.targetHosts(hostIDs, labelIDs))), nil
+}
+
+func (d *Datastore) TargetLabelIDsForHosts(hostIDs []uint, labelIDs []uint) (map[uint][]uint, error) {
+	d.mtx.Lock()
+	defer d.mtx.Unlock()
+
+	hostSet := map[uint]bool{}
+	for _, id := range hostIDs {
+		hostSet[id] = true
+	}
+	labelSet := map[uint]bool{}
+	for _, id := range labelIDs {
+		labelSet[id] = true
+	}
+
+	labels := map[uint][]uint{}
+	for _, lqe := range d.labelQueryExecutions {
+		if hostSet[lqe.HostID] && labelSet[lqe.LabelID] && lqe.Matches {
+			labels[lqe.HostID] = append(labels[lqe.HostID], lqe.LabelID)
+		}
+	}
+	for _, ids := range labels {
+		sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+	}
+	return labels, nil
+}
@@ -0,0 +1,237 @@
+package inmem
+
+import (
+	"sort"
+
+	"github.com/kolide/kolide-ose/server/kolide"
+)
+
+func (d *Datastore) ImportVulnerabilities(vulns []*kolide.Vulnerability) error {
+	d.mtx.Lock()
+	defer d.mtx.Unlock()
+
+	for _, vuln := range vulns {
+		imported := *vuln
+		imported.Criteria = append([]kolide.VulnerableSoftware{}, vuln.Criteria...)
+		for i := range imported.Criteria {
+			imported.Criteria[i].CVE = vuln.CVE
+		}
+		d.vulnerabilities[vuln.CVE] = &imported
+	}
+	return nil
+}
+
+func (d *Datastore) Vulnerability(cve string) (*kolide.Vulnerability, error) {
+	d.mtx.Lock()
+	defer d.mtx.Unlock()
+
+	vuln, ok := d.vulnerabilities[cve]
+	if !ok {
+		return nil, notFound("Vulnerability").WithMessage(cve)
+	}
+	result := *vuln
+	result.Criteria = nil
+	return &result, nil
+}
+
+func (d *Datastore) ListVulnerableSoftware(products []string) ([]*kolide.VulnerableSoftware, error) {
+	d.mtx.Lock()
+	defer d.mtx.Unlock()
+
+	wanted := map[string]bool{}
+	for _, product := range products {
+		wanted[product] = true
+	}
+
+	criteria := []*kolide.VulnerableSoftware{}
+	for _, vuln := range d.vulnerabilities {
+		for _, c := range vuln.Criteria {
+			if products == nil || wanted[c.Product] {
+				criterion := c
+				criteria = append(criteria, &criterion)
+			}
+		}
+	}
+	return criteria, nil
+}
+
+func (d *Datastore) ListAllSoftware() ([]*kolide.Software, error) {
+	d.mtx.Lock()
+	defer d.mtx.Unlock()
+
+	software := []*kolide.Software{}
+	for _, s := range d.software {
+		result := *s
+		software = append(software, &result)
+	}
+	sort.Slice(software, func(i, j int) bool {
+		return software[i].ID < software[j].ID
+	})
+	return software, nil
+}
+
+func (d *Datastore) ReplaceSoftwareVulnerabilities(matches []kolide.SoftwareVulnerability) error {
+	d.mtx.Lock()
+	defer d.mtx.Unlock()
+
+	d.softwareVulnerabilities = make(map[uint]map[string]bool)
+	d.addSoftwareVulnerabilities(matches)
+	return nil
+}
+
+func (d *Datastore) AddSoftwareVulnerabilities(matches []kolide.SoftwareVulnerability) error {
+	d.mtx.Lock()
+	defer d.mtx.Unlock()
+
+	d.addSoftwareVulnerabilities(matches)
+	return nil
+}
+
+// addSoftwareVulnerabilities stores the matches. The caller must hold the
+// lock.
+func (d *Datastore) addSoftwareVulnerabilities(matches []kolide.SoftwareVulnerability) {
+	for _, m := range matches {
+		if d.softwareVulnerabilities[m.SoftwareID] == nil {
+			d.softwareVulnerabilities[m.SoftwareID] = map[string]bool{}
+		}
+		d.softwareVulnerabilities[m.SoftwareID][m.CVE] = true
+	}
+}
+
+func (d *Datastore) ListVulnerabilities(opt kolide.ListOptions) ([]*kolide.VulnerabilityCount, error) {
+	d.mtx.Lock()
+	defer d.mtx.Unlock()
+
+	hosts := map[string]map[uint]bool{}
+	for hostID, installed := range d.hostSoftware {
+		if _, ok := d.hosts[hostID]; !ok {
+			continue
+		}
+		for softwareID := range installed {
+			for cve := range d.softwareVulnerabilities[softwareID] {
+				if hosts[cve] == nil {
+					hosts[cve] = map[uint]bool{}
+				}
+				hosts[cve][hostID] = true
+			}
+		}
+	}
+
+	vulns := []*kolide.VulnerabilityCount{}
+	for cve, affected := range hosts {
+		vuln, ok := d.vulnerabilities[cve]
+		if !ok {
+			continue
+		}
+		count := &kolide.VulnerabilityCount{Vulnerability: *vuln, HostCount: uint(len(affected))}
+		count.Criteria = nil
+		vulns = append(vulns, count)
+	}
+	sort.Slice(vulns, func(i, j int) bool {
+		return vulnerabilityLess(&vulns[i].Vulnerability, &vulns[j].Vulnerability)
+	})
+
+	if opt.OrderKey != "" {
+		fields := map[string]string{
+			"cve":        "CVE",
+			"cvss_score": "CVSSScore",
+			"host_count": "HostCount",
+		}
+		if err := sortResults(vulns, opt, fields); err != nil {
+			return nil, err
+		}
+	}
+
+	low, high := d.getLimitOffsetSliceBounds(opt, len(vulns))
+	return vulns[low:high], nil
+}
+
+func (d *Datastore) ListVulnerableHosts(cve string, opt kolide.ListOptions) ([]*kolide.VulnerableHost, error) {
+	d.mtx.Lock()
+	defer d.mtx.Unlock()
+
+	hosts := []*kolide.VulnerableHost{}
+	for hostID, installed := range d.hostSoftware {
+		host, ok := d.hosts[hostID]
+		if !ok {
+			continue
+		}
+		for softwareID, hs := range installed {
+			if d.softwareVulnerabilities[softwareID][cve] {
+				hosts = append(hosts, &kolide.VulnerableHost{
+					HostID:   hostID,
+					HostName: host.HostName,
+					Software: hs.Software,
+				})
+			}
+		}
+	}
+	sort.Slice(hosts, func(i, j int) bool {
+		if hosts[i].HostName != hosts[j].HostName {
+			return hosts[i].HostName < hosts[j].HostName
+		}
+		if hosts[i].HostID != hosts[j].HostID {
+			return hosts[i].HostID < hosts[j].HostID
+		}
+		return softwareLess(&hosts[i].Software, &hosts[j].Software)
+	})
+
+	if opt.OrderKey != "" {
+		fields := map[string]string{
+			"host_id":  "HostID",
+			"hostname": "HostName",
+		}
+		if err := sortResults(hosts, opt, fields); err != nil {
+			return nil, err
+		}
+	}
+
+	low, high := d.getLimitOffsetSliceBounds(opt, len(hosts))
+	return hosts[low:high], nil
+}
+
+func (d *Datastore) ListHostVulnerabilities(hostID uint, opt kolide.ListOptions) ([]*kolide.HostVulnerability, error) {
+	d.mtx.Lock()
+	defer d.mtx.Unlock()
+
+	vulns := []*kolide.HostVulnerability{}
+	for softwareID, hs := range d.hostSoftware[hostID] {
+		for cve := range d.softwareVulnerabilities[softwareID] {
+			vuln, ok := d.vulnerabilities[cve]
+			if !ok {
+				continue
+			}
+			result := &kolide.HostVulnerability{Vulnerability: *vuln, Software: hs.Software}
+			result.Criteria = nil
+			vulns = append(vulns, result)
+		}
+	}
+	sort.Slice(vulns, func(i, j int) bool {
+		if vulns[i].CVE != vulns[j].CVE {
+			return vulnerabilityLess(&vulns[i].Vulnerability, &vulns[j].Vulnerability)
+		}
+		return softwareLess(&vulns[i].Software, &vulns[j].Software)
+	})
+
+	if opt.OrderKey != "" {
+		fields := map[string]string{
+			"cve":        "CVE",
+			"cvss_score": "CVSSScore",
+		}
+		if err := sortResults(vulns, opt, fields); err != nil {
+			return nil, err
+		}
+	}
+
+	low, high := d.getLimitOffsetSliceBounds(opt, len(vulns))
+	return vulns[low:high], nil
+}
+
+// vulnerabilityLess orders vulnerabilities by descending CVSS score and then
+// CVE ID.
+func vulnerabilityLess(a, b *kolide.Vulnerability) bool {
+	if a.CVSSScore != b.CVSSScore {
+		return a.CVSSScore > b.CVSSScore
+	}
+	return a.CVE < b.CVE
+}
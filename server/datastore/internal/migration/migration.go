@@ -0,0 +1,246 @@
+// Package migration reports, rolls back and plans the goose table and data
+// migrations of the SQL datastores.
+package migration
+
+import (
+	"database/sql"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/kolide/goose"
+	"github.com/kolide/kolide-ose/server/kolide"
+	"github.com/pkg/errors"
+)
+
+// Clients are the goose clients of the table and data migrations of a
+// datastore.
+type Clients struct {
+	Tables *goose.Client
+	Data   *goose.Client
+}
+
+func (c Clients) byKind() []struct {
+	kind   string
+	client *goose.Client
+} {
+	return []struct {
+		kind   string
+		client *goose.Client
+	}{
+		{kolide.MigrationKindTables, c.Tables},
+		{kolide.MigrationKindData, c.Data},
+	}
+}
+
+// migrations returns the migrations of the client ordered by version,
+// marking those at or below the current version of the database as applied.
+func migrations(db *sql.DB, kind string, client *goose.Client) ([]*kolide.Migration, map[int64]*goose.Migration, error) {
+	current, err := dbVersion(db, client)
+	if err != nil {
+		return nil, nil, errors.Wrapf(err, "getting %s migration version", kind)
+	}
+
+	result := []*kolide.Migration{}
+	byVersion := map[int64]*goose.Migration{}
+	for _, m := range client.Migrations {
+		result = append(result, &kolide.Migration{
+			Kind:    kind,
+			Version: m.Version,
+			Name:    name(m.Source),
+			Applied: m.Version <= current,
+		})
+		byVersion[m.Version] = m
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].Version < result[j].Version })
+	return result, byVersion, nil
+}
+
+// dbVersion returns the current version of the migrations of the client, as
+// goose's GetDBVersion does, but without creating the version table when it
+// does not exist yet, so that inspecting and planning migrations leaves the
+// database untouched. It is 0 when no migrations have been applied.
+func dbVersion(db *sql.DB, client *goose.Client) (int64, error) {
+	var exists bool
+	var err error
+	switch client.Dialect.(type) {
+	case goose.Sqlite3Dialect:
+		err = db.QueryRow(
+			"SELECT COUNT(*) > 0 FROM sqlite_master WHERE type = 'table' AND name = ?",
+			client.TableName,
+		).Scan(&exists)
+	case goose.PostgresDialect:
+		err = db.QueryRow(
+			"SELECT COUNT(*) > 0 FROM information_schema.tables WHERE table_schema = current_schema() AND table_name = $1",
+			client.TableName,
+		).Scan(&exists)
+	default:
+		err = db.QueryRow(
+			"SELECT COUNT(*) > 0 FROM information_schema.tables WHERE table_schema = DATABASE() AND table_name = ?",
+			client.TableName,
+		).Scan(&exists)
+	}
+	if err != nil {
+		return 0, errors.Wrap(err, "finding migration version table")
+	}
+	if !exists {
+		return 0, nil
+	}
+
+	// Rolling back a migration records it as not applied rather than
+	// removing its row, so the newest row of each version decides whether
+	// it is applied
+	rows, err := db.Query("SELECT version_id, is_applied FROM " + client.TableName + " ORDER BY id DESC")
+	if err != nil {
+		return 0, errors.Wrap(err, "reading migration version table")
+	}
+	defer rows.Close()
+	seen := map[int64]bool{}
+	for rows.Next() {
+		var version int64
+		var applied bool
+		if err := rows.Scan(&version, &applied); err != nil {
+			return 0, errors.Wrap(err, "reading migration version table")
+		}
+		if seen[version] {
+			continue
+		}
+		if applied {
+			return version, nil
+		}
+		seen[version] = true
+	}
+	return 0, errors.Wrap(rows.Err(), "reading migration version table")
+}
+
+// name returns the name of a migration from its source file, such as
+// CreateTableHosts for 20161118212528_CreateTableHosts.go.
+func name(source string) string {
+	base := strings.TrimSuffix(filepath.Base(source), filepath.Ext(source))
+	parts := strings.SplitN(base, "_", 2)
+	return parts[len(parts)-1]
+}
+
+// Status returns the table and data migrations and whether each has been
+// applied to the database.
+func Status(db *sql.DB, c Clients) (*kolide.MigrationStatus, error) {
+	tables, _, err := migrations(db, kolide.MigrationKindTables, c.Tables)
+	if err != nil {
+		return nil, err
+	}
+	data, _, err := migrations(db, kolide.MigrationKindData, c.Data)
+	if err != nil {
+		return nil, err
+	}
+	return &kolide.MigrationStatus{Tables: tables, Data: data}, nil
+}
+
+// rollbackSteps returns the applied migrations that come after version in
+// the order migrations are applied, table migrations before data migrations,
+// newest first. Data migrations populate tables created by table
+// migrations of any version, so rolling back to a table migration rolls back
+// every data migration too. Rolling back to version 0 rolls back every
+// migration.
+func rollbackSteps(db *sql.DB, c Clients, version int64) ([]*kolide.Migration, map[int64]*goose.Migration, error) {
+	applied := []*kolide.Migration{}
+	byVersion := map[int64]*goose.Migration{}
+	found := version == 0
+	after := version == 0
+	for _, k := range c.byKind() {
+		ms, kindByVersion, err := migrations(db, k.kind, k.client)
+		if err != nil {
+			return nil, nil, err
+		}
+		for _, m := range ms {
+			if after && m.Applied {
+				applied = append(applied, m)
+				byVersion[m.Version] = kindByVersion[m.Version]
+			}
+			if m.Version == version {
+				found, after = true, true
+			}
+		}
+	}
+	if !found {
+		return nil, nil, errors.Errorf("unknown migration version %d", version)
+	}
+
+	for i, j := 0, len(applied)-1; i < j; i, j = i+1, j-1 {
+		applied[i], applied[j] = applied[j], applied[i]
+	}
+	return applied, byVersion, nil
+}
+
+// Rollback rolls back the applied migrations that come after version, newest
+// first, as ordered by rollbackSteps.
+func Rollback(db *sql.DB, c Clients, version int64) error {
+	steps, _, err := rollbackSteps(db, c, version)
+	if err != nil {
+		return err
+	}
+	for _, m := range steps {
+		client := c.Tables
+		if m.Kind == kolide.MigrationKindData {
+			client = c.Data
+		}
+		current, err := client.GetDBVersion(db)
+		if err != nil {
+			return errors.Wrap(err, "getting migration version")
+		}
+		if current != m.Version {
+			return errors.Errorf("expected %s migration %d to be current, found %d", m.Kind, m.Version, current)
+		}
+		if err := client.Down(db, ""); err != nil {
+			return errors.Wrapf(err, "rolling back migration %d", m.Version)
+		}
+		current, err = client.GetDBVersion(db)
+		if err != nil {
+			return errors.Wrap(err, "getting migration version")
+		}
+		if current >= m.Version {
+			return errors.Errorf("migration %d was not rolled back", m.Version)
+		}
+	}
+	return nil
+}
+
+// PlanUp returns the pending table migrations and then the pending data
+// migrations, in the order they are applied, with the SQL of each.
+func PlanUp(db *sql.DB, c Clients) ([]*kolide.MigrationStep, error) {
+	steps := []*kolide.MigrationStep{}
+	for _, k := range c.byKind() {
+		ms, byVersion, err := migrations(db, k.kind, k.client)
+		if err != nil {
+			return nil, err
+		}
+		for _, m := range ms {
+			if m.Applied {
+				continue
+			}
+			statements, err := record(byVersion[m.Version].UpFn)
+			if err != nil {
+				return nil, errors.Wrapf(err, "planning migration %d", m.Version)
+			}
+			steps = append(steps, &kolide.MigrationStep{Migration: *m, Up: true, Statements: statements})
+		}
+	}
+	return steps, nil
+}
+
+// PlanRollback returns the applied migrations that Rollback would roll back,
+// in the order it rolls them back, with the SQL of each.
+func PlanRollback(db *sql.DB, c Clients, version int64) ([]*kolide.MigrationStep, error) {
+	ms, byVersion, err := rollbackSteps(db, c, version)
+	if err != nil {
+		return nil, err
+	}
+	steps := []*kolide.MigrationStep{}
+	for _, m := range ms {
+		statements, err := record(byVersion[m.Version].DownFn)
+		if err != nil {
+			return nil, errors.Wrapf(err, "planning rollback of migration %d", m.Version)
+		}
+		steps = append(steps, &kolide.MigrationStep{Migration: *m, Statements: statements})
+	}
+	return steps, nil
+}
@@ -0,0 +1,141 @@
+package migration
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// recordDriver is a database/sql driver that records the statements run
+// against it instead of running them, so that the SQL of a migration can be
+// printed without a database. Each connection string names its own list of
+// statements.
+type recordDriver struct {
+	mtx        sync.Mutex
+	nextID     int
+	statements map[string]*[]string
+}
+
+var recorder = &recordDriver{statements: map[string]*[]string{}}
+
+func init() {
+	sql.Register("kolide-migration-record", recorder)
+}
+
+// record runs the migration function in a transaction of the recording
+// driver, returning the statements it runs.
+func record(fn func(*sql.Tx) error) ([]string, error) {
+	recorder.mtx.Lock()
+	recorder.nextID++
+	dsn := strconv.Itoa(recorder.nextID)
+	statements := []string{}
+	recorder.statements[dsn] = &statements
+	recorder.mtx.Unlock()
+
+	defer func() {
+		recorder.mtx.Lock()
+		delete(recorder.statements, dsn)
+		recorder.mtx.Unlock()
+	}()
+
+	db, err := sql.Open("kolide-migration-record", dsn)
+	if err != nil {
+		return nil, err
+	}
+	defer db.Close()
+
+	tx, err := db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	if err := fn(tx); err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+	if err := tx.Rollback(); err != nil {
+		return nil, err
+	}
+
+	recorder.mtx.Lock()
+	defer recorder.mtx.Unlock()
+	return append([]string{}, statements...), nil
+}
+
+func (d *recordDriver) Open(dsn string) (driver.Conn, error) {
+	d.mtx.Lock()
+	defer d.mtx.Unlock()
+	statements, ok := d.statements[dsn]
+	if !ok {
+		return nil, fmt.Errorf("no migration recording %q", dsn)
+	}
+	return &recordConn{driver: d, statements: statements}, nil
+}
+
+type recordConn struct {
+	driver     *recordDriver
+	statements *[]string
+}
+
+func (c *recordConn) add(query string, args []driver.Value) {
+	statement := strings.TrimSuffix(strings.TrimSpace(query), ";") + ";"
+	if len(args) > 0 {
+		values := []string{}
+		for _, arg := range args {
+			values = append(values, fmt.Sprintf("%#v", arg))
+		}
+		statement += "\n-- args: " + strings.Join(values, ", ")
+	}
+
+	c.driver.mtx.Lock()
+	defer c.driver.mtx.Unlock()
+	*c.statements = append(*c.statements, statement)
+}
+
+func (c *recordConn) Exec(query string, args []driver.Value) (driver.Result, error) {
+	c.add(query, args)
+	return driver.RowsAffected(0), nil
+}
+
+func (c *recordConn) Query(query string, args []driver.Value) (driver.Rows, error) {
+	c.add(query, args)
+	return recordRows{}, nil
+}
+
+func (c *recordConn) Prepare(query string) (driver.Stmt, error) {
+	return &recordStmt{conn: c, query: query}, nil
+}
+
+func (c *recordConn) Begin() (driver.Tx, error) { return recordTx{}, nil }
+func (c *recordConn) Close() error              { return nil }
+
+type recordStmt struct {
+	conn  *recordConn
+	query string
+}
+
+func (s *recordStmt) Close() error  { return nil }
+func (s *recordStmt) NumInput() int { return -1 }
+
+func (s *recordStmt) Exec(args []driver.Value) (driver.Result, error) {
+	return s.conn.Exec(s.query, args)
+}
+
+func (s *recordStmt) Query(args []driver.Value) (driver.Rows, error) {
+	return s.conn.Query(s.query, args)
+}
+
+type recordTx struct{}
+
+func (recordTx) Commit() error   { return nil }
+func (recordTx) Rollback() error { return nil }
+
+// recordRows is the empty result of every recorded query.
+type recordRows struct{}
+
+func (recordRows) Columns() []string              { return []string{} }
+func (recordRows) Close() error                   { return nil }
+func (recordRows) Next(dest []driver.Value) error { return io.EOF }
@@ -0,0 +1,56 @@
+package sqlcommon
+
+import (
+	"github.com/jmoiron/sqlx"
+	"github.com/kolide/kolide-ose/server/kolide"
+	"github.com/pkg/errors"
+)
+
+// agentLogsBatchSize limits the number of rows inserted by a single
+// statement when storing agent logs
+const agentLogsBatchSize = 500
+
+// InsertAgentLogs stores the logs of warning severity and above for a host,
+// returning the number of logs stored.
+func InsertAgentLogs(tx *sqlx.Tx, hostID uint, logs []*kolide.AgentLog) (uint, error) {
+	stored := []*kolide.AgentLog{}
+	for _, log := range logs {
+		if kolide.AgentLogSeverityStored(log.Severity) {
+			stored = append(stored, log)
+		}
+	}
+
+	for batch := stored; len(batch) > 0; {
+		rows := batch
+		if len(rows) > agentLogsBatchSize {
+			rows = rows[:agentLogsBatchSize]
+		}
+		batch = batch[len(rows):]
+
+		vals := []interface{}{}
+		bindvars := ""
+		for _, log := range rows {
+			if bindvars != "" {
+				bindvars += ","
+			}
+			bindvars += "(?,?,?,?,?,?,?)"
+			vals = append(vals, hostID, log.CreatedAt, log.Severity, log.Filename, log.Line, log.Message, log.Version)
+		}
+		sqlStatement := `
+			INSERT INTO agent_logs (
+				host_id,
+				created_at,
+				severity,
+				filename,
+				line,
+				message,
+				version
+			) VALUES ` + bindvars
+
+		if _, err := tx.Exec(tx.Rebind(sqlStatement), vals...); err != nil {
+			return 0, errors.Wrap(err, "inserting agent logs")
+		}
+	}
+
+	return uint(len(stored)), nil
+}
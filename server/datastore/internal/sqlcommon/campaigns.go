@@ -0,0 +1,221 @@
+package sqlcommon
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/kolide/kolide-ose/server/kolide"
+	"github.com/pkg/errors"
+)
+
+func NewDistributedQueryCampaign(db *sqlx.DB, dialect Dialect, camp *kolide.DistributedQueryCampaign) (*kolide.DistributedQueryCampaign, error) {
+
+	sqlStatement := `
+		INSERT INTO distributed_query_campaigns (
+			query_id,
+			status,
+			user_id
+		)
+		VALUES(?,?,?)
+	`
+	id, err := insert(db, dialect, sqlStatement, camp.QueryID, camp.Status, camp.UserID)
+	if err != nil {
+		return nil, errors.Wrap(err, "inserting distributed query campaign")
+	}
+
+	camp.ID = id
+	return camp, nil
+}
+
+func DistributedQueryCampaign(db *sqlx.DB, id uint) (*kolide.DistributedQueryCampaign, error) {
+	sql := `
+		SELECT * FROM distributed_query_campaigns WHERE id = ? AND NOT deleted
+	`
+	campaign := &kolide.DistributedQueryCampaign{}
+	if err := db.Get(campaign, db.Rebind(sql), id); err != nil {
+		return nil, errors.Wrap(err, "selecting distributed query campaign")
+	}
+
+	return campaign, nil
+}
+
+func SaveDistributedQueryCampaign(db *sqlx.DB, camp *kolide.DistributedQueryCampaign) error {
+	sqlStatement := `
+		UPDATE distributed_query_campaigns SET
+			query_id = ?,
+			status = ?,
+			user_id = ?
+		WHERE id = ?
+		AND NOT deleted
+	`
+	_, err := db.Exec(db.Rebind(sqlStatement), camp.QueryID, camp.Status, camp.UserID, camp.ID)
+	if err != nil {
+		return errors.Wrap(err, "updating distributed query campaign")
+	}
+
+	return nil
+}
+
+func DistributedQueryCampaignTargetIDs(db *sqlx.DB, id uint) (hostIDs []uint, labelIDs []uint, err error) {
+	sqlStatement := `
+		SELECT * FROM distributed_query_campaign_targets WHERE distributed_query_campaign_id = ?
+	`
+	targets := []kolide.DistributedQueryCampaignTarget{}
+
+	if err = db.Select(&targets, db.Rebind(sqlStatement), id); err != nil {
+		return nil, nil, errors.Wrap(err, "selecting distributed campaign target")
+	}
+
+	hostIDs = []uint{}
+	labelIDs = []uint{}
+	for _, target := range targets {
+		if target.Type == kolide.TargetHost {
+			hostIDs = append(hostIDs, target.TargetID)
+		} else if target.Type == kolide.TargetLabel {
+			labelIDs = append(labelIDs, target.TargetID)
+		} else {
+			return []uint{}, []uint{}, fmt.Errorf("invalid target type: %d", target.Type)
+		}
+	}
+
+	return hostIDs, labelIDs, nil
+}
+
+func NewDistributedQueryCampaignTarget(db *sqlx.DB, dialect Dialect, target *kolide.DistributedQueryCampaignTarget) (*kolide.DistributedQueryCampaignTarget, error) {
+	sqlStatement := `
+		INSERT into distributed_query_campaign_targets (
+			type,
+			distributed_query_campaign_id,
+			target_id
+		)
+		VALUES (?,?,?)
+	`
+	id, err := insert(db, dialect, sqlStatement, target.Type, target.DistributedQueryCampaignID, target.TargetID)
+	if err != nil {
+		return nil, errors.Wrap(err, "insert distributed campaign target")
+	}
+
+	target.ID = id
+	return target, nil
+}
+
+// campaignHostsBatchSize limits the number of rows inserted by a single
+// statement when recording the hosts for a campaign
+const campaignHostsBatchSize = 1000
+
+func NewDistributedQueryCampaignHosts(db *sqlx.DB, dialect Dialect, campaignID uint, hostIDs []uint) error {
+	for len(hostIDs) > 0 {
+		batch := hostIDs
+		if len(batch) > campaignHostsBatchSize {
+			batch = batch[:campaignHostsBatchSize]
+		}
+		hostIDs = hostIDs[len(batch):]
+
+		vals := []interface{}{}
+		bindvars := ""
+		for _, hostID := range batch {
+			if bindvars != "" {
+				bindvars += ","
+			}
+			bindvars += "(?,?)"
+			vals = append(vals, campaignID, hostID)
+		}
+		sqlStatement := fmt.Sprintf(dialect.InsertIgnore, `distributed_query_campaign_hosts (
+				distributed_query_campaign_id,
+				host_id
+			) VALUES `+bindvars)
+
+		if _, err := db.Exec(db.Rebind(sqlStatement), vals...); err != nil {
+			return errors.Wrap(err, "inserting distributed campaign hosts")
+		}
+	}
+
+	return nil
+}
+
+func DistributedQueryCampaignHostIDs(db *sqlx.DB, id uint) ([]uint, error) {
+	sqlStatement := `
+		SELECT host_id FROM distributed_query_campaign_hosts
+		WHERE distributed_query_campaign_id = ?
+		ORDER BY host_id
+	`
+	hostIDs := []uint{}
+	if err := db.Select(&hostIDs, db.Rebind(sqlStatement), id); err != nil {
+		return nil, errors.Wrap(err, "selecting distributed campaign hosts")
+	}
+
+	return hostIDs, nil
+}
+
+func NewDistributedQueryExecution(db *sqlx.DB, dialect Dialect, exec *kolide.DistributedQueryExecution) (*kolide.DistributedQueryExecution, error) {
+	sqlStatement := `
+		INSERT INTO distributed_query_executions (
+			host_id,
+			distributed_query_campaign_id,
+			status,
+			error,
+			execution_duration
+		) VALUES (?,?,?,?,?)
+	`
+	id, err := insert(db, dialect, sqlStatement, exec.HostID, exec.DistributedQueryCampaignID,
+		exec.Status, exec.Error, exec.ExecutionDuration)
+	if err != nil {
+		return nil, errors.Wrap(err, "insert distributed campaign target")
+	}
+
+	exec.ID = id
+
+	return exec, nil
+}
+
+// ExpireDistributedQueryCampaigns completes the campaigns that have waited
+// or run for too long at now, returning the number of expired campaigns.
+func ExpireDistributedQueryCampaigns(db *sqlx.DB, now time.Time) (uint, error) {
+	sqlStatement := `
+		UPDATE distributed_query_campaigns
+		SET status = ?
+		WHERE (status = ? AND created_at < ?)
+		OR (status = ? AND created_at < ?)
+	`
+	result, err := db.Exec(db.Rebind(sqlStatement), kolide.QueryComplete,
+		kolide.QueryWaiting, now.Add(-1*time.Minute),
+		kolide.QueryRunning, now.Add(-24*time.Hour))
+	if err != nil {
+		return 0, errors.Wrap(err, "updating distributed query campaign")
+	}
+
+	exp, err := result.RowsAffected()
+	if err != nil {
+		return 0, errors.Wrap(err, "rows effected updating distributed query campaign")
+	}
+	return uint(exp), nil
+}
+
+func CleanupDistributedQueryCampaigns(db *sqlx.DB, now time.Time) (expired uint, deleted uint, err error) {
+	// First expire old waiting and running campaigns
+	expired, err = ExpireDistributedQueryCampaigns(db, now)
+	if err != nil {
+		return expired, deleted, err
+	}
+
+	// Now delete executions for expired campaigns
+	sqlStatement := `
+		DELETE FROM distributed_query_executions
+		WHERE distributed_query_campaign_id IN (
+			SELECT id FROM distributed_query_campaigns WHERE status = ?
+		)
+	`
+	result, err := db.Exec(db.Rebind(sqlStatement), kolide.QueryComplete)
+	if err != nil {
+		return expired, deleted, errors.Wrap(err, "deleting distributed campaign executions")
+	}
+
+	del, err := result.RowsAffected()
+	if err != nil {
+		return expired, deleted, errors.Wrap(err, "rows effected deleting distributed campaign")
+	}
+	deleted = uint(del)
+
+	return expired, deleted, nil
+}
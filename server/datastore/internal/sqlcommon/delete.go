@@ -0,0 +1,27 @@
+package sqlcommon
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/pkg/errors"
+)
+
+// DeleteEntity soft deletes the row of the table with the ID.
+func DeleteEntity(db *sqlx.DB, dbTable string, id uint, now time.Time) error {
+	deleteStmt := fmt.Sprintf(
+		`
+		UPDATE %s SET deleted_at = ?, deleted = TRUE
+			WHERE id = ?
+	`, dbTable)
+	result, err := db.Exec(db.Rebind(deleteStmt), now, id)
+	if err != nil {
+		return errors.Wrap(err, fmt.Sprintf("delete %s", dbTable))
+	}
+	rows, _ := result.RowsAffected()
+	if rows != 1 {
+		return NotFound(dbTable).WithID(id)
+	}
+	return nil
+}
@@ -0,0 +1,72 @@
+package sqlcommon
+
+import (
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/kolide/kolide-ose/server/kolide"
+)
+
+// Dialect holds what the shared queries need from the database that they
+// run on. Each SQL datastore declares the dialect of its database.
+type Dialect struct {
+	// ReturningID is true when the ID of an inserted row is read from a
+	// RETURNING clause, rather than from the LastInsertId of the result.
+	ReturningID bool
+
+	// Like follows a column to match it against a pattern argument escaped
+	// by EscapeLike, ignoring case.
+	Like string
+
+	// InsertIgnore is the format of an INSERT statement that skips rows
+	// duplicating a unique key, with the table, columns and values
+	// substituted for %s.
+	InsertIgnore string
+
+	// IsDuplicate reports whether an error is the violation of a unique
+	// key.
+	IsDuplicate func(err error) bool
+
+	// HostOfflineSQL returns the condition on the hosts table, aliased h,
+	// that selects hosts that have gone without communication for longer
+	// than their offline threshold at now, along with its arguments. It
+	// mirrors kolide.HostStatusThresholds.OfflineDuration.
+	HostOfflineSQL func(now time.Time, thresholds kolide.HostStatusThresholds) (string, []interface{})
+
+	// HostAttributeFilterSQL returns the condition on the hosts table,
+	// aliased h, that selects hosts with an attribute matching the filter,
+	// along with its arguments.
+	HostAttributeFilterSQL func(filter kolide.HostAttributeFilter) (string, []interface{}, error)
+
+	// QuerySearchSQL returns the condition on the queries table, aliased q,
+	// that selects queries containing every word of the search, along with
+	// its arguments. The condition is empty when the search has no words.
+	QuerySearchSQL func(search string) (string, []interface{})
+
+	// UpsertNetworkInterface inserts the network interface of a host, or
+	// updates the interface of the host with the same name and IP address,
+	// and sets the ID of an inserted interface.
+	UpsertNetworkInterface func(tx *sqlx.Tx, nic *kolide.NetworkInterface) error
+}
+
+// execer is a database or a transaction that statements run in.
+type execer interface {
+	sqlx.Execer
+	sqlx.Queryer
+	Rebind(query string) string
+}
+
+// insert runs the INSERT statement and returns the ID of the inserted row.
+func insert(e execer, dialect Dialect, sqlStatement string, args ...interface{}) (uint, error) {
+	if dialect.ReturningID {
+		var id uint
+		err := sqlx.Get(e, &id, e.Rebind(sqlStatement+" RETURNING id"), args...)
+		return id, err
+	}
+	result, err := e.Exec(e.Rebind(sqlStatement), args...)
+	if err != nil {
+		return 0, err
+	}
+	id, _ := result.LastInsertId()
+	return uint(id), nil
+}
@@ -0,0 +1,81 @@
+package sqlcommon
+
+import "fmt"
+
+// NotFoundError is returned when a requested resource is not in the
+// datastore.
+type NotFoundError struct {
+	ID           uint
+	Message      string
+	ResourceType string
+}
+
+// NotFound returns the error for a missing resource of the kind.
+func NotFound(kind string) *NotFoundError {
+	return &NotFoundError{
+		ResourceType: kind,
+	}
+}
+
+func (e *NotFoundError) Error() string {
+	if e.ID != 0 {
+		return fmt.Sprintf("%s %d was not found in the datastore", e.ResourceType, e.ID)
+	}
+	if e.Message != "" {
+		return fmt.Sprintf("%s %s was not found in the datastore", e.ResourceType, e.Message)
+	}
+	return fmt.Sprintf("%s was not found in the datastore", e.ResourceType)
+}
+
+func (e *NotFoundError) WithID(id uint) error {
+	e.ID = id
+	return e
+}
+
+func (e *NotFoundError) WithMessage(msg string) error {
+	e.Message = msg
+	return e
+}
+
+func (e *NotFoundError) IsNotFound() bool {
+	return true
+}
+
+// ExistsError is returned when a resource conflicts with one already in the
+// datastore.
+type ExistsError struct {
+	ID           uint
+	Message      string
+	ResourceType string
+}
+
+// AlreadyExists returns the error for a conflicting resource of the kind.
+func AlreadyExists(kind string) *ExistsError {
+	return &ExistsError{
+		ResourceType: kind,
+	}
+}
+
+func (e *ExistsError) Error() string {
+	if e.ID != 0 {
+		return fmt.Sprintf("%s %d already exists in the datastore", e.ResourceType, e.ID)
+	}
+	if e.Message != "" {
+		return fmt.Sprintf("%s %s already exists in the datastore", e.ResourceType, e.Message)
+	}
+	return fmt.Sprintf("%s already exists in the datastore", e.ResourceType)
+}
+
+func (e *ExistsError) WithID(id uint) error {
+	e.ID = id
+	return e
+}
+
+func (e *ExistsError) WithMessage(msg string) error {
+	e.Message = msg
+	return e
+}
+
+func (e *ExistsError) IsExists() bool {
+	return true
+}
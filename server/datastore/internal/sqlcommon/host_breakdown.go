@@ -0,0 +1,93 @@
+package sqlcommon
+
+import (
+	"github.com/jmoiron/sqlx"
+	"github.com/kolide/kolide-ose/server/kolide"
+	"github.com/pkg/errors"
+)
+
+// HostBreakdown counts the hosts, or the members of the label when labelID
+// is not 0, by each of the attributes of the breakdown.
+func HostBreakdown(db *sqlx.DB, labelID uint) (*kolide.HostBreakdown, error) {
+	from := `
+		FROM hosts h
+		WHERE NOT h.deleted
+	`
+	args := []interface{}{}
+	if labelID != 0 {
+		from += `
+			AND EXISTS (
+				SELECT 1 FROM label_query_executions lqe
+				WHERE lqe.host_id = h.id
+				AND lqe.label_id = ?
+				AND lqe.matches
+			)
+		`
+		args = append(args, labelID)
+	}
+
+	countBy := func(column string) ([]*kolide.HostCount, error) {
+		sqlStatement := "SELECT " + column + " AS value, COUNT(*) AS count " + from + " GROUP BY " + column
+		counts := []*kolide.HostCount{}
+		if err := db.Select(&counts, db.Rebind(sqlStatement), args...); err != nil {
+			return nil, errors.Wrapf(err, "counting hosts by %s", column)
+		}
+		kolide.SortHostCounts(counts)
+		return counts, nil
+	}
+
+	breakdown := &kolide.HostBreakdown{}
+	var err error
+	if breakdown.Platforms, err = countBy("h.platform"); err != nil {
+		return nil, err
+	}
+	if breakdown.OSVersions, err = countBy("h.os_version"); err != nil {
+		return nil, err
+	}
+	if breakdown.OsqueryVersions, err = countBy("h.osquery_version"); err != nil {
+		return nil, err
+	}
+	if breakdown.HardwareVendors, err = countBy("h.hardware_vendor"); err != nil {
+		return nil, err
+	}
+	if breakdown.CPUBrands, err = countBy("h.cpu_brand"); err != nil {
+		return nil, err
+	}
+	for _, count := range breakdown.Platforms {
+		breakdown.TotalCount += count.Count
+	}
+
+	breakdown.HardwareModels = []*kolide.ModelCount{}
+	sqlStatement := `
+		SELECT h.hardware_vendor AS vendor, h.hardware_model AS model, COUNT(*) AS count
+	` + from + `
+		GROUP BY h.hardware_vendor, h.hardware_model
+	`
+	if err := db.Select(&breakdown.HardwareModels, db.Rebind(sqlStatement), args...); err != nil {
+		return nil, errors.Wrap(err, "counting hosts by hardware model")
+	}
+	kolide.SortModelCounts(breakdown.HardwareModels)
+
+	// Hosts are bucketed by memory after counting each distinct amount
+	memory := []struct {
+		PhysicalMemory int  `db:"physical_memory"`
+		Count          uint `db:"count"`
+	}{}
+	sqlStatement = "SELECT h.physical_memory, COUNT(*) AS count " + from + " GROUP BY h.physical_memory"
+	if err := db.Select(&memory, db.Rebind(sqlStatement), args...); err != nil {
+		return nil, errors.Wrap(err, "counting hosts by memory")
+	}
+	buckets := map[string]*kolide.HostCount{}
+	breakdown.Memory = []*kolide.HostCount{}
+	for _, m := range memory {
+		bucket := kolide.MemoryBucket(m.PhysicalMemory)
+		if buckets[bucket] == nil {
+			buckets[bucket] = &kolide.HostCount{Value: bucket}
+			breakdown.Memory = append(breakdown.Memory, buckets[bucket])
+		}
+		buckets[bucket].Count += m.Count
+	}
+	kolide.SortHostCounts(breakdown.Memory)
+
+	return breakdown, nil
+}
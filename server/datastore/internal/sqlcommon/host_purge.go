@@ -0,0 +1,143 @@
+package sqlcommon
+
+import (
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/kolide/kolide-ose/server/kolide"
+	"github.com/pkg/errors"
+)
+
+// hostPurgeBatchSize limits the number of hosts deleted by each statement
+// when purging hosts.
+const hostPurgeBatchSize = 500
+
+// ListPurgeableHosts returns the hosts last seen before seenBefore that are
+// not members of any of the exempt labels, ordered by ID.
+func ListPurgeableHosts(db *sqlx.DB, seenBefore time.Time, exemptLabelIDs []uint) ([]*kolide.Host, error) {
+	sqlStatement := `
+		SELECT h.* FROM hosts h
+		WHERE NOT h.deleted
+		AND h.seen_time < ?
+	`
+	args := []interface{}{seenBefore}
+	if len(exemptLabelIDs) > 0 {
+		sqlStatement += `
+			AND NOT EXISTS (
+				SELECT 1 FROM label_query_executions lqe
+				WHERE lqe.host_id = h.id
+				AND lqe.label_id IN (?)
+				AND lqe.matches
+			)
+		`
+		args = append(args, exemptLabelIDs)
+	}
+	sqlStatement += " ORDER BY h.id"
+
+	query, args, err := sqlx.In(sqlStatement, args...)
+	if err != nil {
+		return nil, errors.Wrap(err, "building purgeable hosts query")
+	}
+	hosts := []*kolide.Host{}
+	if err := db.Select(&hosts, db.Rebind(query), args...); err != nil {
+		return nil, errors.Wrap(err, "listing purgeable hosts")
+	}
+	return hosts, nil
+}
+
+// PurgeHosts deletes the hosts that are still last seen before seenBefore,
+// along with everything stored about them, in a single transaction.
+func PurgeHosts(db *sqlx.DB, hostIDs []uint, seenBefore time.Time) (purged uint, err error) {
+	txn, err := db.Beginx()
+	if err != nil {
+		return 0, errors.Wrap(err, "purge hosts begin transaction")
+	}
+	var success bool
+	defer func() {
+		if success {
+			if err = txn.Commit(); err == nil {
+				return
+			}
+		}
+		txn.Rollback()
+	}()
+
+	// Everything stored about the hosts is deleted before the hosts
+	// themselves
+	statements := []string{
+		"DELETE FROM label_query_executions WHERE host_id IN (?)",
+		"DELETE FROM network_interfaces WHERE host_id IN (?)",
+		"DELETE FROM scheduled_query_stats WHERE host_id IN (?)",
+		"DELETE FROM agent_logs WHERE host_id IN (?)",
+		"DELETE FROM agent_log_counts WHERE host_id IN (?)",
+		"DELETE FROM detail_query_executions WHERE host_id IN (?)",
+		"DELETE FROM host_attributes WHERE host_id IN (?)",
+		"DELETE FROM host_software WHERE host_id IN (?)",
+		"DELETE FROM distributed_query_executions WHERE host_id IN (?)",
+		"DELETE FROM distributed_query_campaign_hosts WHERE host_id IN (?)",
+	}
+
+	for start := 0; start < len(hostIDs); start += hostPurgeBatchSize {
+		end := start + hostPurgeBatchSize
+		if end > len(hostIDs) {
+			end = len(hostIDs)
+		}
+
+		// Hosts seen since they were listed are no longer purged
+		query, args, err := sqlx.In(
+			"SELECT id FROM hosts WHERE id IN (?) AND seen_time < ?",
+			hostIDs[start:end], seenBefore,
+		)
+		if err != nil {
+			return 0, errors.Wrap(err, "building purged hosts query")
+		}
+		batch := []uint{}
+		if err := txn.Select(&batch, txn.Rebind(query), args...); err != nil {
+			return 0, errors.Wrap(err, "selecting purged hosts")
+		}
+		if len(batch) == 0 {
+			continue
+		}
+
+		for _, statement := range statements {
+			query, args, err := sqlx.In(statement, batch)
+			if err != nil {
+				return 0, errors.Wrap(err, "building host purge statement")
+			}
+			if _, err := txn.Exec(txn.Rebind(query), args...); err != nil {
+				return 0, errors.Wrap(err, "purging host data")
+			}
+		}
+
+		targetStatements := []string{
+			"DELETE FROM pack_targets WHERE type = ? AND target_id IN (?)",
+			"DELETE FROM distributed_query_campaign_targets WHERE type = ? AND target_id IN (?)",
+		}
+		for _, statement := range targetStatements {
+			query, args, err := sqlx.In(statement, kolide.TargetHost, batch)
+			if err != nil {
+				return 0, errors.Wrap(err, "building host target purge statement")
+			}
+			if _, err := txn.Exec(txn.Rebind(query), args...); err != nil {
+				return 0, errors.Wrap(err, "purging host targets")
+			}
+		}
+
+		query, args, err = sqlx.In("DELETE FROM hosts WHERE id IN (?) AND seen_time < ?", batch, seenBefore)
+		if err != nil {
+			return 0, errors.Wrap(err, "building host purge statement")
+		}
+		result, err := txn.Exec(txn.Rebind(query), args...)
+		if err != nil {
+			return 0, errors.Wrap(err, "purging hosts")
+		}
+		deleted, err := result.RowsAffected()
+		if err != nil {
+			return 0, errors.Wrap(err, "rows affected purging hosts")
+		}
+		purged += uint(deleted)
+	}
+
+	success = true
+	return purged, err
+}
@@ -0,0 +1,602 @@
+package sqlcommon
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/kolide/kolide-ose/server/kolide"
+	"github.com/pkg/errors"
+)
+
+func NewHost(db *sqlx.DB, dialect Dialect, host *kolide.Host) (*kolide.Host, error) {
+	sqlStatement := `
+	INSERT INTO hosts (
+		osquery_host_id,
+		detail_update_time,
+		node_key,
+		host_name,
+		uuid,
+		platform,
+		osquery_version,
+		os_version,
+		uptime,
+		physical_memory,
+		seen_time
+	)
+	VALUES( ?,?,?,?,?,?,?,?,?,?,? )
+	`
+	id, err := insert(db, dialect, sqlStatement, host.OsqueryHostID, host.DetailUpdateTime,
+		host.NodeKey, host.HostName, host.UUID, host.Platform, host.OsqueryVersion,
+		host.OSVersion, host.Uptime, host.PhysicalMemory, host.SeenTime)
+	if err != nil {
+		return nil, errors.Wrap(err, "new host")
+	}
+	host.ID = id
+	return host, nil
+}
+
+func removedUnusedNics(tx *sqlx.Tx, host *kolide.Host) error {
+	if len(host.NetworkInterfaces) == 0 {
+		_, err := tx.Exec(tx.Rebind(`DELETE FROM network_interfaces WHERE host_id = ?`), host.ID)
+		return err
+	}
+	// Remove nics not associated with host
+	sqlStatement := fmt.Sprintf(`
+			DELETE FROM network_interfaces
+			WHERE host_id = %d AND id NOT IN (?)
+		`, host.ID)
+
+	list := []uint{}
+	for _, nic := range host.NetworkInterfaces {
+		list = append(list, nic.ID)
+	}
+
+	sql, args, err := sqlx.In(sqlStatement, list)
+	if err != nil {
+		return err
+	}
+
+	sql = tx.Rebind(sql)
+	_, err = tx.Exec(sql, args...)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func updateNicsForHost(tx *sqlx.Tx, dialect Dialect, host *kolide.Host) ([]*kolide.NetworkInterface, error) {
+	updatedNics := []*kolide.NetworkInterface{}
+	for _, nic := range host.NetworkInterfaces {
+		nic.HostID = host.ID
+		if err := dialect.UpsertNetworkInterface(tx, nic); err != nil {
+			return nil, err
+		}
+		updatedNics = append(updatedNics, nic)
+	}
+
+	return updatedNics, nil
+}
+
+func SaveHost(db *sqlx.DB, dialect Dialect, host *kolide.Host) error {
+	sqlStatement := `
+		UPDATE hosts SET
+			detail_update_time = ?,
+			node_key = ?,
+			host_name = ?,
+			uuid = ?,
+			platform = ?,
+			osquery_version = ?,
+			os_version = ?,
+			uptime = ?,
+			physical_memory = ?,
+			cpu_type = ?,
+			cpu_subtype = ?,
+			cpu_brand = ?,
+			cpu_physical_cores = ?,
+			hardware_vendor = ?,
+			hardware_model = ?,
+			hardware_version = ?,
+			hardware_serial = ?,
+			computer_name = ?,
+			primary_ip_id = ?,
+			build = ?,
+			platform_like = ?,
+			code_name = ?,
+			cpu_logical_cores = ?,
+			distributed_interval = ?,
+			config_refresh = ?
+		WHERE id = ?
+	`
+
+	tx, err := db.Beginx()
+	if err != nil {
+		return errors.Wrap(err, "creating transaction")
+	}
+
+	_, err = tx.Exec(tx.Rebind(sqlStatement),
+		host.DetailUpdateTime,
+		host.NodeKey,
+		host.HostName,
+		host.UUID,
+		host.Platform,
+		host.OsqueryVersion,
+		host.OSVersion,
+		host.Uptime,
+		host.PhysicalMemory,
+		host.CPUType,
+		host.CPUSubtype,
+		host.CPUBrand,
+		host.CPUPhysicalCores,
+		host.HardwareVendor,
+		host.HardwareModel,
+		host.HardwareVersion,
+		host.HardwareSerial,
+		host.ComputerName,
+		host.PrimaryNetworkInterfaceID,
+		host.Build,
+		host.PlatformLike,
+		host.CodeName,
+		host.CPULogicalCores,
+		host.DistributedInterval,
+		host.ConfigRefresh,
+		host.ID)
+	if err != nil {
+		tx.Rollback()
+		return errors.Wrap(err, "executing main SQL statement")
+	}
+
+	host.NetworkInterfaces, err = updateNicsForHost(tx, dialect, host)
+	if err != nil {
+		tx.Rollback()
+		return errors.Wrap(err, "updating nics")
+	}
+
+	if err = removedUnusedNics(tx, host); err != nil {
+		tx.Rollback()
+		return errors.Wrap(err, "removing unused nics")
+	}
+
+	if needsUpdate := host.ResetPrimaryNetwork(); needsUpdate {
+		_, err = tx.Exec(
+			tx.Rebind("UPDATE hosts SET primary_ip_id = ? WHERE id = ?"),
+			host.PrimaryNetworkInterfaceID,
+			host.ID,
+		)
+
+		if err != nil {
+			tx.Rollback()
+			return errors.Wrap(err, "resetting primary network")
+		}
+	}
+
+	if err = tx.Commit(); err != nil {
+		tx.Rollback()
+		return errors.Wrap(err, "committing transaction")
+	}
+	return nil
+}
+
+func Host(db *sqlx.DB, id uint) (*kolide.Host, error) {
+	sqlStatement := `
+		SELECT * FROM hosts
+		WHERE id = ? AND NOT deleted LIMIT 1
+	`
+	host := &kolide.Host{}
+	err := db.Get(host, db.Rebind(sqlStatement), id)
+	if err == sql.ErrNoRows {
+		return nil, NotFound("Host").WithID(id)
+	} else if err != nil {
+		return nil, errors.Wrap(err, "getting host by id")
+	}
+
+	if err := loadNetInterfacesForHost(db, host); err != nil {
+		return nil, err
+	}
+
+	return host, nil
+
+}
+
+// hostOrderKeys maps the keys that hosts can be ordered by to their columns.
+var hostOrderKeys = map[string]string{
+	"id":                 "h.id",
+	"created_at":         "h.created_at",
+	"updated_at":         "h.updated_at",
+	"detail_update_time": "h.detail_update_time",
+	"seen_time":          "h.seen_time",
+	"hostname":           "h.host_name",
+	"uuid":               "h.uuid",
+	"platform":           "h.platform",
+	"osquery_version":    "h.osquery_version",
+	"os_version":         "h.os_version",
+	"uptime":             "h.uptime",
+	"memory":             "h.physical_memory",
+}
+
+func ListHosts(db *sqlx.DB, dialect Dialect, opt kolide.ListOptions, filter kolide.HostFilter) ([]*kolide.Host, error) {
+	if opt.OrderKey != "" {
+		column, ok := hostOrderKeys[opt.OrderKey]
+		if !ok {
+			return nil, errors.New("cannot sort on unknown key: " + opt.OrderKey)
+		}
+		opt.OrderKey = column
+	}
+
+	condition, args, err := hostFilterSQL(dialect, filter)
+	if err != nil {
+		return nil, err
+	}
+	sqlStatement := `
+		SELECT h.* FROM hosts h
+		WHERE NOT h.deleted
+	` + condition
+	sqlStatement = AppendListOptionsToSQL(sqlStatement, opt)
+	hosts := []*kolide.Host{}
+	if err := db.Select(&hosts, db.Rebind(sqlStatement), args...); err != nil {
+		return nil, errors.Wrap(err, "list hosts")
+	}
+
+	if err := LoadNetInterfacesForHosts(db, hosts); err != nil {
+		return nil, err
+	}
+
+	return hosts, nil
+}
+
+func CountHosts(db *sqlx.DB, dialect Dialect, filter kolide.HostFilter) (uint, error) {
+	condition, args, err := hostFilterSQL(dialect, filter)
+	if err != nil {
+		return 0, err
+	}
+	sqlStatement := `
+		SELECT COUNT(*) FROM hosts h
+		WHERE NOT h.deleted
+	` + condition
+	var count uint
+	if err := db.Get(&count, db.Rebind(sqlStatement), args...); err != nil {
+		return 0, errors.Wrap(err, "count hosts")
+	}
+	return count, nil
+}
+
+// hostFilterSQL returns the conditions on the hosts table, aliased h, that
+// select hosts matching the filter, each preceded by AND, along with their
+// arguments.
+func hostFilterSQL(dialect Dialect, filter kolide.HostFilter) (string, []interface{}, error) {
+	conditions := ""
+	args := []interface{}{}
+	if filter.Status != "" {
+		condition, conditionArgs := hostStatusSQL(dialect, filter.Status, filter.StatusTime, filter.StatusThresholds)
+		conditions += " AND " + condition
+		args = append(args, conditionArgs...)
+	}
+	if filter.Platform != "" {
+		conditions += " AND h.platform = ?"
+		args = append(args, filter.Platform)
+	}
+	if filter.OSVersion != "" {
+		conditions += " AND h.os_version = ?"
+		args = append(args, filter.OSVersion)
+	}
+	if filter.OsqueryVersion != "" {
+		conditions += " AND h.osquery_version = ?"
+		args = append(args, filter.OsqueryVersion)
+	}
+	if filter.LabelID != 0 {
+		conditions += `
+			AND EXISTS (
+				SELECT 1 FROM label_query_executions lqe
+				WHERE lqe.host_id = h.id
+				AND lqe.label_id = ?
+				AND lqe.matches
+			)
+		`
+		args = append(args, filter.LabelID)
+	}
+	if !filter.SeenAfter.IsZero() {
+		conditions += " AND h.seen_time >= ?"
+		args = append(args, filter.SeenAfter)
+	}
+	if !filter.SeenBefore.IsZero() {
+		conditions += " AND h.seen_time <= ?"
+		args = append(args, filter.SeenBefore)
+	}
+	if filter.Query != "" {
+		query := "%" + EscapeLike(filter.Query) + "%"
+		like := dialect.Like
+		conditions += `
+			AND (
+				h.host_name ` + like + `
+				OR h.uuid ` + like + `
+				OR h.hardware_serial ` + like + `
+				OR EXISTS (
+					SELECT 1 FROM network_interfaces ni
+					WHERE ni.host_id = h.id
+					AND (ni.ip_address ` + like + ` OR ni.mac ` + like + `)
+				)
+			)
+		`
+		args = append(args, query, query, query, query, query)
+	}
+	for _, f := range filter.Attributes {
+		condition, conditionArgs, err := dialect.HostAttributeFilterSQL(f)
+		if err != nil {
+			return "", nil, err
+		}
+		conditions += " AND " + condition
+		args = append(args, conditionArgs...)
+	}
+	return conditions, args, nil
+}
+
+// hostStatusSQL returns the condition on the hosts table, aliased h, that
+// selects hosts with the status at now, along with its arguments.
+func hostStatusSQL(dialect Dialect, status string, now time.Time, thresholds kolide.HostStatusThresholds) (string, []interface{}) {
+	miaTime := now.Add(-thresholds.MIA)
+	offline, offlineArgs := dialect.HostOfflineSQL(now, thresholds)
+	switch status {
+	case kolide.StatusOnline:
+		return "h.seen_time >= ? AND NOT " + offline, append([]interface{}{miaTime}, offlineArgs...)
+	case kolide.StatusOffline:
+		return "h.seen_time >= ? AND " + offline, append([]interface{}{miaTime}, offlineArgs...)
+	case kolide.StatusMIA:
+		return "h.seen_time < ?", []interface{}{miaTime}
+	}
+	return "FALSE", nil
+}
+
+func GenerateHostStatusStatistics(db *sqlx.DB, dialect Dialect, now time.Time, thresholds kolide.HostStatusThresholds) (online, offline, mia uint, e error) {
+	var args []interface{}
+	subquery := func(status string) string {
+		condition, conditionArgs := hostStatusSQL(dialect, status, now, thresholds)
+		args = append(args, conditionArgs...)
+		return "SELECT count(id) FROM hosts h WHERE " + condition
+	}
+	sqlStatement := fmt.Sprintf(`
+		SELECT
+			(%s) AS mia,
+			(%s) AS offline,
+			(%s) AS online
+		FROM hosts
+		LIMIT 1;
+	`, subquery(kolide.StatusMIA), subquery(kolide.StatusOffline), subquery(kolide.StatusOnline))
+
+	counts := struct {
+		MIA     uint `db:"mia"`
+		Offline uint `db:"offline"`
+		Online  uint `db:"online"`
+	}{}
+	err := db.Get(&counts, db.Rebind(sqlStatement), args...)
+	if err != nil && err != sql.ErrNoRows {
+		e = errors.Wrap(err, "generating host statistics")
+		return
+	}
+
+	mia = counts.MIA
+	offline = counts.Offline
+	online = counts.Online
+	return online, offline, mia, nil
+}
+
+// LoadNetInterfacesForHosts loads the network interfaces of a set of hosts.
+// Instead of looping through hosts and doing a select for each host to get
+// nics, we get all nics at once, so 2 db calls, and then assign nics to
+// hosts here.
+func LoadNetInterfacesForHosts(db *sqlx.DB, hosts []*kolide.Host) error {
+	if len(hosts) == 0 {
+		return nil
+	}
+
+	sqlStatement := `
+		SELECT *
+		FROM network_interfaces
+		WHERE host_id IN (?)
+		ORDER BY host_id ASC
+	`
+	hostIDs := make([]uint, 0, len(hosts))
+	for _, host := range hosts {
+		hostIDs = append(hostIDs, host.ID)
+	}
+
+	query, args, err := sqlx.In(sqlStatement, hostIDs)
+	if err != nil {
+		return errors.Wrap(err, "select nics for hosts, in query")
+	}
+
+	nics := []*kolide.NetworkInterface{}
+	err = db.Select(&nics, db.Rebind(query), args...)
+	if err != nil {
+		return errors.Wrap(err, "select nics for hosts, rebound query")
+	}
+
+	// The hosts are left in the order they were listed in
+	byID := map[uint]*kolide.Host{}
+	for _, host := range hosts {
+		byID[host.ID] = host
+	}
+	for _, nic := range nics {
+		if host, ok := byID[nic.HostID]; ok {
+			host.NetworkInterfaces = append(host.NetworkInterfaces, nic)
+		}
+	}
+
+	return nil
+}
+
+func loadNetInterfacesForHost(db *sqlx.DB, host *kolide.Host) error {
+	sqlStatement := `
+		SELECT * FROM network_interfaces
+		WHERE host_id = ?
+	`
+	if err := db.Select(&host.NetworkInterfaces, db.Rebind(sqlStatement), host.ID); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func AuthenticateHost(db *sqlx.DB, nodeKey string) (*kolide.Host, error) {
+	sqlStatement := `
+		SELECT *
+		FROM hosts
+		WHERE node_key = ? AND NOT deleted
+		LIMIT 1
+	`
+
+	host := &kolide.Host{}
+	if err := db.Get(host, db.Rebind(sqlStatement), nodeKey); err != nil {
+		switch err {
+		case sql.ErrNoRows:
+			return nil, errors.Wrap(err, "host not found")
+		default:
+			return nil, errors.New("finding host")
+		}
+	}
+
+	if err := loadNetInterfacesForHost(db, host); err != nil {
+		return nil, errors.Wrap(err, "getting interfaces")
+	}
+
+	return host, nil
+}
+
+func MarkHostSeen(db *sqlx.DB, host *kolide.Host, t time.Time) error {
+	sqlStatement := `
+		UPDATE hosts SET
+			seen_time = ?
+		WHERE node_key=?
+	`
+
+	_, err := db.Exec(db.Rebind(sqlStatement), t, host.NodeKey)
+	if err != nil {
+		return errors.Wrap(err, "marking host seen")
+	}
+
+	host.UpdatedAt = t
+	return nil
+}
+
+// hostsUpdateBatchSize limits the number of hosts updated by each statement
+// when updating many hosts at once.
+const hostsUpdateBatchSize = 500
+
+// MarkHostsSeen records that each of the hosts was seen at t.
+func MarkHostsSeen(db *sqlx.DB, hostIDs []uint, t time.Time) error {
+	for start := 0; start < len(hostIDs); start += hostsUpdateBatchSize {
+		end := start + hostsUpdateBatchSize
+		if end > len(hostIDs) {
+			end = len(hostIDs)
+		}
+		query, args, err := sqlx.In("UPDATE hosts SET seen_time = ? WHERE id IN (?)", t, hostIDs[start:end])
+		if err != nil {
+			return errors.Wrap(err, "building hosts seen update")
+		}
+		if _, err := db.Exec(db.Rebind(query), args...); err != nil {
+			return errors.Wrap(err, "marking hosts seen")
+		}
+	}
+	return nil
+}
+
+// MarkHostsConfigFetched records that each of the hosts fetched the config
+// with the hash at t.
+func MarkHostsConfigFetched(db *sqlx.DB, hostIDs []uint, hash string, t time.Time) error {
+	for start := 0; start < len(hostIDs); start += hostsUpdateBatchSize {
+		end := start + hostsUpdateBatchSize
+		if end > len(hostIDs) {
+			end = len(hostIDs)
+		}
+		query, args, err := sqlx.In(
+			"UPDATE hosts SET config_fetch_time = ?, config_hash = ? WHERE id IN (?)",
+			t, hash, hostIDs[start:end],
+		)
+		if err != nil {
+			return errors.Wrap(err, "building host config fetch update")
+		}
+		if _, err := db.Exec(db.Rebind(query), args...); err != nil {
+			return errors.Wrap(err, "marking hosts config fetched")
+		}
+	}
+	return nil
+}
+
+// SearchHostsDefault returns the most recently seen hosts, other than those
+// to omit, for a search without a query.
+func SearchHostsDefault(db *sqlx.DB, omit ...uint) ([]*kolide.Host, error) {
+	sqlStatement := `
+	SELECT * FROM hosts
+	WHERE NOT deleted
+	AND id NOT IN (?)
+	ORDER BY seen_time DESC
+	LIMIT 5
+	`
+
+	var in interface{}
+	{
+		// use -1 if there are no values to omit.
+		//Avoids empty args error for `sqlx.In`
+		in = omit
+		if len(omit) == 0 {
+			in = -1
+		}
+	}
+
+	var hosts []*kolide.Host
+	sql, args, err := sqlx.In(sqlStatement, in)
+	if err != nil {
+		return nil, errors.Wrap(err, "searching default hosts")
+	}
+	sql = db.Rebind(sql)
+	err = db.Select(&hosts, sql, args...)
+	if err != nil {
+		return nil, errors.Wrap(err, "searching default hosts rebound")
+	}
+	if err := LoadNetInterfacesForHosts(db, hosts); err != nil {
+		return nil, errors.Wrap(err, "getting network interfaces for default search hosts")
+	}
+	return hosts, nil
+}
+
+func DistributedQueriesForHost(db *sqlx.DB, host *kolide.Host) (map[uint]string, error) {
+	// Queries are sent to the hosts that the campaign targets resolved to
+	// when it was created, not to the current members of its labels
+	sqlStatement := `
+		SELECT dqc.id, q.query
+		FROM distributed_query_campaigns dqc
+		JOIN distributed_query_campaign_hosts dqch
+		    ON (dqc.id = dqch.distributed_query_campaign_id)
+		LEFT JOIN distributed_query_executions dqe
+		    ON (dqch.host_id = dqe.host_id AND dqc.id = dqe.distributed_query_campaign_id)
+		JOIN queries q
+		    ON (dqc.query_id = q.id)
+		WHERE dqe.status IS NULL AND dqc.status = ? AND dqch.host_id = ?
+			AND NOT q.deleted
+			AND NOT dqc.deleted
+ `
+	rows, err := db.Query(db.Rebind(sqlStatement), kolide.QueryRunning, host.ID)
+	if err != nil {
+		return nil, errors.Wrap(err, "finding distributed queries for host")
+	}
+	defer rows.Close()
+
+	results := map[uint]string{}
+
+	for rows.Next() {
+		var (
+			id    uint
+			query string
+		)
+		err = rows.Scan(&id, &query)
+		if err != nil {
+			return nil, errors.Wrap(err, "scanning query results")
+		}
+
+		results[id] = query
+
+	}
+
+	return results, nil
+}
@@ -0,0 +1,66 @@
+package sqlcommon
+
+import (
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/kolide/kolide-ose/server/kolide"
+	"github.com/pkg/errors"
+)
+
+// LockJob takes the lock of the named job, which must have been registered,
+// when it is not held at now. The lock is taken by a single
+// update, so that only one of the servers taking it at once succeeds.
+func LockJob(db *sqlx.DB, name, owner string, interval time.Duration, now time.Time) (bool, error) {
+	sqlStatement := `
+		UPDATE jobs SET
+			run_interval = ?,
+			lock_owner = ?,
+			locked_until = ?,
+			last_instance = ?,
+			last_started_at = ?
+		WHERE name = ? AND locked_until <= ?
+	`
+	result, err := db.Exec(db.Rebind(sqlStatement), interval, owner, now.Add(interval), owner, now, name, now)
+	if err != nil {
+		return false, errors.Wrap(err, "locking job")
+	}
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return false, errors.Wrap(err, "locking job")
+	}
+	return rows == 1, nil
+}
+
+func FinishJob(db *sqlx.DB, name, owner string, finished time.Time, runErr string) error {
+	sqlStatement := `
+		UPDATE jobs SET
+			last_finished_at = ?,
+			last_error = ?
+		WHERE name = ? AND last_instance = ?
+	`
+	if _, err := db.Exec(db.Rebind(sqlStatement), finished, runErr, name, owner); err != nil {
+		return errors.Wrap(err, "finishing job")
+	}
+	return nil
+}
+
+func TriggerJob(db *sqlx.DB, name string, now time.Time) error {
+	sqlStatement := `
+		UPDATE jobs SET
+			locked_until = ?
+		WHERE name = ? AND locked_until > ?
+	`
+	if _, err := db.Exec(db.Rebind(sqlStatement), now, name, now); err != nil {
+		return errors.Wrap(err, "triggering job")
+	}
+	return nil
+}
+
+func ListJobs(db *sqlx.DB) ([]*kolide.Job, error) {
+	jobs := []*kolide.Job{}
+	if err := db.Select(&jobs, "SELECT * FROM jobs ORDER BY name"); err != nil {
+		return nil, errors.Wrap(err, "listing jobs")
+	}
+	return jobs, nil
+}
@@ -0,0 +1,213 @@
+package sqlcommon
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/kolide/kolide-ose/server/kolide"
+	"github.com/pkg/errors"
+)
+
+// NewLabel creates a new kolide.Label
+func NewLabel(db *sqlx.DB, dialect Dialect, label *kolide.Label) (*kolide.Label, error) {
+
+	sql := `
+		INSERT INTO labels (
+			name,
+			description,
+			query,
+			platform,
+			label_type,
+			attribute
+		) VALUES ( ?, ?, ?, ?, ?, ?)
+	`
+	id, err := insert(db, dialect, sql, label.Name, label.Description, label.Query, label.Platform, label.LabelType, label.Attribute)
+	if err != nil {
+		return nil, errors.Wrap(err, "inserting label")
+	}
+
+	label.ID = id
+	return label, nil
+
+}
+
+// Label returns a kolide.Label identified by  lid if one exists
+func Label(db *sqlx.DB, lid uint) (*kolide.Label, error) {
+	sqlStatement := `
+		SELECT * FROM labels
+			WHERE id = ? AND NOT deleted
+	`
+	label := &kolide.Label{}
+
+	err := db.Get(label, db.Rebind(sqlStatement), lid)
+	if err == sql.ErrNoRows {
+		return nil, NotFound("Label").WithID(lid)
+	} else if err != nil {
+		return nil, errors.Wrap(err, "selecting label")
+	}
+
+	return label, nil
+}
+
+// ListLabels returns all labels limited or sorted  by kolide.ListOptions
+func ListLabels(db *sqlx.DB, opt kolide.ListOptions) ([]*kolide.Label, error) {
+	query := `
+		SELECT * FROM labels WHERE NOT deleted
+	`
+	query = AppendListOptionsToSQL(query, opt)
+	labels := []*kolide.Label{}
+
+	if err := db.Select(&labels, db.Rebind(query)); err != nil {
+		// it's ok if no labels exist
+		if err == sql.ErrNoRows {
+			return labels, nil
+		}
+		return nil, errors.Wrap(err, "selecting labels")
+	}
+
+	return labels, nil
+}
+
+func LabelQueriesForHost(db *sqlx.DB, host *kolide.Host, cutoff time.Time) (map[string]string, error) {
+	sqlStatment := `
+			SELECT l.id, l.query
+			FROM labels l
+			WHERE (l.platform = ? OR l.platform = '')
+			AND NOT l.deleted
+			AND l.label_type != ? /* attribute labels are not queries */
+			AND l.id NOT IN /* subtract the set of executions that are recent enough */
+			(
+			  SELECT l.id
+			  FROM labels l
+			  JOIN label_query_executions lqe
+			  ON lqe.label_id = l.id
+			  WHERE lqe.host_id = ? AND lqe.updated_at > ?
+			)
+	`
+	rows, err := db.Query(db.Rebind(sqlStatment), host.Platform, kolide.LabelTypeAttribute, host.ID, cutoff)
+	if err != nil && err != sql.ErrNoRows {
+		return nil, errors.Wrap(err, "selecting label queries for host")
+	}
+
+	defer rows.Close()
+	results := map[string]string{}
+
+	for rows.Next() {
+		var id, query string
+
+		if err = rows.Scan(&id, &query); err != nil {
+			return nil, errors.Wrap(err, "scanning label queries for host")
+		}
+
+		results[id] = query
+	}
+
+	return results, nil
+
+}
+
+// ListLabelsForHost returns a list of kolide.Label for a given host id.
+func ListLabelsForHost(db *sqlx.DB, hid uint) ([]kolide.Label, error) {
+	sqlStatement := `
+		SELECT labels.* from labels, label_query_executions lqe
+		WHERE lqe.host_id = ?
+		AND lqe.label_id = labels.id
+		AND lqe.matches
+		AND NOT labels.deleted
+	`
+
+	labels := []kolide.Label{}
+	err := db.Select(&labels, db.Rebind(sqlStatement), hid)
+	if err != nil {
+		return nil, errors.Wrap(err, "selecting host labels")
+	}
+
+	return labels, nil
+
+}
+
+// ListHostsInLabel returns a list of kolide.Host that are associated
+// with kolide.Label referened by Label ID
+func ListHostsInLabel(db *sqlx.DB, lid uint) ([]kolide.Host, error) {
+	sqlStatement := `
+		SELECT h.*
+		FROM label_query_executions lqe
+		JOIN hosts h
+		ON lqe.host_id = h.id
+		WHERE lqe.label_id = ?
+		AND lqe.matches
+		AND NOT h.deleted
+	`
+	hosts := []kolide.Host{}
+	err := db.Select(&hosts, db.Rebind(sqlStatement), lid)
+	if err != nil {
+		return nil, errors.Wrap(err, "selecting label query executions")
+	}
+	return hosts, nil
+}
+
+func ListUniqueHostsInLabels(db *sqlx.DB, labels []uint) ([]kolide.Host, error) {
+	if len(labels) == 0 {
+		return []kolide.Host{}, nil
+	}
+
+	sqlStatement := `
+		SELECT h.*
+		FROM label_query_executions lqe
+		JOIN hosts h
+		ON lqe.host_id = h.id
+		WHERE lqe.label_id IN (?)
+		AND lqe.matches
+		AND NOT h.deleted
+		GROUP BY h.id;
+	`
+	query, args, err := sqlx.In(sqlStatement, labels)
+	if err != nil {
+		return nil, errors.Wrap(err, "building query listing unique hosts in labels")
+	}
+
+	query = db.Rebind(query)
+	hosts := []kolide.Host{}
+	err = db.Select(&hosts, query, args...)
+	if err != nil {
+		return nil, errors.Wrap(err, "listing unique hosts in labels")
+	}
+
+	return hosts, nil
+
+}
+
+// SearchLabelsDefault returns labels, other than those to omit, for a search
+// without a query.
+func SearchLabelsDefault(db *sqlx.DB, omit ...uint) ([]kolide.Label, error) {
+	sqlStatement := `
+	SELECT *
+	FROM labels
+	WHERE NOT deleted
+	AND id NOT IN (?)
+	LIMIT 5
+	`
+
+	var in interface{}
+	{
+		// use -1 if there are no values to omit.
+		//Avoids empty args error for `sqlx.In`
+		in = omit
+		if len(omit) == 0 {
+			in = -1
+		}
+	}
+
+	var labels []kolide.Label
+	sql, args, err := sqlx.In(sqlStatement, in)
+	if err != nil {
+		return nil, errors.Wrap(err, "searching default labels")
+	}
+	sql = db.Rebind(sql)
+	err = db.Select(&labels, sql, args...)
+	if err != nil {
+		return nil, errors.Wrap(err, "searching default labels rebound")
+	}
+	return labels, nil
+}
@@ -0,0 +1,236 @@
+package sqlcommon
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/kolide/kolide-ose/server/kolide"
+	"github.com/pkg/errors"
+)
+
+func PackByName(db *sqlx.DB, name string) (*kolide.Pack, bool, error) {
+	sqlStatement := `
+		SELECT *
+			FROM packs
+			WHERE name = ? AND NOT deleted
+	`
+	var pack kolide.Pack
+	err := db.Get(&pack, db.Rebind(sqlStatement), name)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, false, nil
+		}
+		return nil, false, errors.Wrap(err, "fetching packs by name")
+	}
+
+	return &pack, true, nil
+}
+
+// SavePack stores changes to pack
+func SavePack(db *sqlx.DB, pack *kolide.Pack) error {
+	query := `
+			UPDATE packs
+			SET name = ?, platform = ?, disabled = ?, description = ?
+			WHERE id = ? AND NOT deleted
+	`
+
+	_, err := db.Exec(db.Rebind(query), pack.Name, pack.Platform, pack.Disabled, pack.Description, pack.ID)
+	if err == sql.ErrNoRows {
+		return NotFound("Pack").WithID(pack.ID)
+	} else if err != nil {
+		return errors.Wrap(err, "update pack")
+	}
+
+	return nil
+}
+
+// DeletePack soft deletes a kolide.Pack so that it won't show up in results
+func DeletePack(db *sqlx.DB, pid uint, now time.Time) error {
+	err := DeleteEntity(db, "packs", pid, now)
+	if err == sql.ErrNoRows {
+		return NotFound("Pack").WithID(pid)
+	} else if err != nil {
+		return errors.Wrap(err, "delete pack")
+	}
+	return nil
+}
+
+// Pack fetch kolide.Pack with matching ID
+func Pack(db *sqlx.DB, pid uint) (*kolide.Pack, error) {
+	query := `SELECT * FROM packs WHERE id = ? AND NOT deleted`
+	pack := &kolide.Pack{}
+	err := db.Get(pack, db.Rebind(query), pid)
+	if err == sql.ErrNoRows {
+		return nil, NotFound("Pack").WithID(pid)
+	} else if err != nil {
+		return nil, errors.Wrap(err, "getting pack")
+	}
+
+	return pack, nil
+}
+
+// ListPacks returns all kolide.Pack records limited and sorted by kolide.ListOptions
+func ListPacks(db *sqlx.DB, opt kolide.ListOptions) ([]*kolide.Pack, error) {
+	query := `SELECT * FROM packs WHERE NOT deleted`
+	packs := []*kolide.Pack{}
+	err := db.Select(&packs, db.Rebind(AppendListOptionsToSQL(query, opt)))
+	if err != nil && err != sql.ErrNoRows {
+		return nil, errors.Wrap(err, "listing packs")
+	}
+	return packs, nil
+}
+
+// ListLabelsForPack will return a list of kolide.Label records associated with kolide.Pack
+func ListLabelsForPack(db *sqlx.DB, pid uint) ([]*kolide.Label, error) {
+	query := `
+	SELECT
+		l.id,
+		l.created_at,
+		l.updated_at,
+		l.name
+	FROM
+		labels l
+	JOIN
+		pack_targets pt
+	ON
+		pt.target_id = l.id
+	WHERE
+		pt.type = ?
+			AND
+		pt.pack_id = ?
+	AND NOT l.deleted
+	`
+
+	labels := []*kolide.Label{}
+
+	if err := db.Select(&labels, db.Rebind(query), kolide.TargetLabel, pid); err != nil && err != sql.ErrNoRows {
+		return nil, errors.Wrap(err, "listing labels for pack")
+	}
+
+	return labels, nil
+}
+
+// RemoreLabelFromPack will remove the association between a kolide.Label and
+// a kolide.Pack
+func RemoveLabelFromPack(db *sqlx.DB, lid, pid uint) error {
+	query := `
+		DELETE FROM pack_targets
+			WHERE target_id = ? AND pack_id = ? AND type = ?
+	`
+	_, err := db.Exec(db.Rebind(query), lid, pid, kolide.TargetLabel)
+	if err == sql.ErrNoRows {
+		return NotFound("PackTarget").WithMessage(fmt.Sprintf("label ID: %d, pack ID: %d", lid, pid))
+	} else if err != nil {
+		return errors.Wrap(err, "removing label from pack")
+	}
+
+	return nil
+}
+
+// RemoveHostFromPack will remove the association between a kolide.Host and a
+// kolide.Pack
+func RemoveHostFromPack(db *sqlx.DB, hid, pid uint) error {
+	query := `
+		DELETE FROM pack_targets
+			WHERE target_id = ? AND pack_id = ? AND type = ?
+	`
+	_, err := db.Exec(db.Rebind(query), hid, pid, kolide.TargetHost)
+	if err == sql.ErrNoRows {
+		return NotFound("PackTarget").WithMessage(fmt.Sprintf("host ID: %d, pack ID: %d", hid, pid))
+	} else if err != nil {
+		return errors.Wrap(err, "removing host from pack")
+	}
+
+	return nil
+
+}
+
+func ListHostsInPack(db *sqlx.DB, pid uint, opt kolide.ListOptions) ([]*kolide.Host, error) {
+	query := `
+		SELECT DISTINCT h.*
+		FROM hosts h
+		JOIN pack_targets pt
+		ON (
+		  pt.type = ?
+		  AND pt.target_id IN (
+		    SELECT lqe.label_id
+		    FROM label_query_executions lqe
+		    WHERE lqe.host_id = h.id
+		    AND lqe.matches
+		  )
+		) OR (
+		  pt.target_id = h.id
+		  AND pt.type = ?
+		)
+		WHERE pt.pack_id = ?
+	`
+
+	hosts := []*kolide.Host{}
+	if err := db.Select(&hosts, db.Rebind(AppendListOptionsToSQL(query, opt)), kolide.TargetLabel, kolide.TargetHost, pid); err != nil && err != sql.ErrNoRows {
+		return nil, errors.Wrap(err, "listing hosts in pack")
+	}
+	return hosts, nil
+}
+
+func ListExplicitHostsInPack(db *sqlx.DB, pid uint, opt kolide.ListOptions) ([]*kolide.Host, error) {
+	query := `
+		SELECT DISTINCT h.*
+		FROM hosts h
+		JOIN pack_targets pt
+		ON (
+		  pt.target_id = h.id
+		  AND pt.type = ?
+		)
+		WHERE pt.pack_id = ?
+	`
+	hosts := []*kolide.Host{}
+	if err := db.Select(&hosts, db.Rebind(AppendListOptionsToSQL(query, opt)), kolide.TargetHost, pid); err != nil && err != sql.ErrNoRows {
+		return nil, errors.Wrap(err, "listing explicit hosts in pack")
+	}
+	return hosts, nil
+
+}
+
+// ListPackTargetsForHost lists the targets that make enabled packs apply to
+// the host with a single query, so that the packs of a host checking in are
+// resolved without a query for each pack.
+func ListPackTargetsForHost(db *sqlx.DB, hid uint) ([]*kolide.HostPackTarget, error) {
+	sqlStatement := `
+		SELECT
+			p.*,
+			pt.type AS target_type,
+			pt.target_id,
+			COALESCE(l.name, '') AS label_name
+		FROM packs p
+		JOIN pack_targets pt ON pt.pack_id = p.id
+		LEFT JOIN labels l ON pt.type = ? AND l.id = pt.target_id
+		WHERE NOT p.deleted
+		AND NOT p.disabled
+		AND (
+			(pt.type = ? AND pt.target_id = ?)
+			OR (
+				pt.type = ?
+				AND NOT l.deleted
+				AND EXISTS (
+					SELECT 1 FROM label_query_executions lqe
+					WHERE lqe.host_id = ?
+					AND lqe.label_id = pt.target_id
+					AND lqe.matches
+				)
+			)
+		)
+		ORDER BY p.id, pt.type, pt.target_id
+	`
+	targets := []*kolide.HostPackTarget{}
+	err := db.Select(&targets, db.Rebind(sqlStatement),
+		kolide.TargetLabel,
+		kolide.TargetHost, hid,
+		kolide.TargetLabel, hid,
+	)
+	if err != nil {
+		return nil, errors.Wrap(err, "listing pack targets for host")
+	}
+	return targets, nil
+}
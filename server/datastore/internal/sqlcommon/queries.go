@@ -0,0 +1,332 @@
+package sqlcommon
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/kolide/kolide-ose/server/kolide"
+	"github.com/pkg/errors"
+)
+
+func QueryByName(db *sqlx.DB, name string) (*kolide.Query, bool, error) {
+	sqlStatement := `
+		SELECT *
+			FROM queries
+			WHERE name = ? AND NOT deleted
+	`
+	var query kolide.Query
+	err := db.Get(&query, db.Rebind(sqlStatement), name)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, false, nil
+		}
+		return nil, false, errors.Wrap(err, "selecting query by name")
+	}
+	return &query, true, nil
+}
+
+// NewQuery creates a Query
+func NewQuery(db *sqlx.DB, dialect Dialect, query *kolide.Query) (result *kolide.Query, err error) {
+	txn, err := db.Beginx()
+	if err != nil {
+		return nil, errors.Wrap(err, "new query begin transaction")
+	}
+	var success bool
+	defer func() {
+		if success {
+			if err = txn.Commit(); err == nil {
+				return
+			}
+		}
+		txn.Rollback()
+		result = nil
+	}()
+
+	sqlStatement := `
+		INSERT INTO queries (
+			name,
+			description,
+			query,
+			saved,
+			author_id,
+			category
+		) VALUES ( ?, ?, ?, ?, ?, ? )
+	`
+	id, err := insert(txn, dialect, sqlStatement, query.Name, query.Description, query.Query, query.Saved, query.AuthorID, query.Category)
+	if err != nil && dialect.IsDuplicate(err) {
+		return nil, AlreadyExists("Query").WithMessage(query.Name)
+	} else if err != nil {
+		return nil, errors.Wrap(err, "inserting new query")
+	}
+
+	query.ID = id
+	query.Packs = []kolide.Pack{}
+
+	if err := saveTagsForQuery(txn, dialect, query); err != nil {
+		return nil, err
+	}
+
+	success = true
+	return query, nil
+}
+
+// SaveQuery saves changes to a Query.
+func SaveQuery(db *sqlx.DB, dialect Dialect, q *kolide.Query) (err error) {
+	txn, err := db.Beginx()
+	if err != nil {
+		return errors.Wrap(err, "save query begin transaction")
+	}
+	var success bool
+	defer func() {
+		if success {
+			if err = txn.Commit(); err == nil {
+				return
+			}
+		}
+		txn.Rollback()
+	}()
+
+	sqlStatement := `
+		UPDATE queries
+			SET name = ?, description = ?, query = ?, author_id = ?, saved = ?, category = ?
+			WHERE id = ? AND NOT deleted
+	`
+	_, err = txn.Exec(txn.Rebind(sqlStatement), q.Name, q.Description, q.Query, q.AuthorID, q.Saved, q.Category, q.ID)
+	if err != nil {
+		return errors.Wrap(err, "updating query")
+	}
+
+	if err := saveTagsForQuery(txn, dialect, q); err != nil {
+		return err
+	}
+
+	success = true
+	return nil
+}
+
+// saveTagsForQuery replaces the tags stored for a query with those of the
+// provided query, as part of the transaction that saves the query.
+func saveTagsForQuery(txn *sqlx.Tx, dialect Dialect, q *kolide.Query) error {
+	if _, err := txn.Exec(txn.Rebind("DELETE FROM query_tags WHERE query_id = ?"), q.ID); err != nil {
+		return errors.Wrap(err, "deleting query tags")
+	}
+	sqlStatement := fmt.Sprintf(dialect.InsertIgnore, "query_tags (query_id, tag) VALUES (?, ?)")
+	for _, tag := range q.Tags {
+		if _, err := txn.Exec(txn.Rebind(sqlStatement), q.ID, tag); err != nil {
+			return errors.Wrap(err, "inserting query tag")
+		}
+	}
+	return nil
+}
+
+// DeleteQueries (soft) deletes the existing query objects with the provided
+// IDs. The number of deleted queries is returned along with any error.
+func DeleteQueries(db *sqlx.DB, ids []uint, now time.Time) (uint, error) {
+	sql := `
+		UPDATE queries
+			SET deleted_at = ?, deleted = true
+			WHERE id IN (?) AND NOT deleted
+	`
+	query, args, err := sqlx.In(sql, now, ids)
+	if err != nil {
+		return 0, errors.Wrap(err, "building delete query query")
+	}
+
+	result, err := db.Exec(db.Rebind(query), args...)
+	if err != nil {
+		return 0, errors.Wrap(err, "updating delete query")
+	}
+
+	deleted, err := result.RowsAffected()
+	if err != nil {
+		return 0, errors.Wrap(err, "fetching delete query rows effected")
+	}
+
+	return uint(deleted), nil
+}
+
+// Query returns a single Query identified by id, if such
+// exists
+func Query(db *sqlx.DB, id uint) (*kolide.Query, error) {
+	sqlStatement := `
+		SELECT q.*, COALESCE(NULLIF(u.name, ''), u.username) AS author_name
+		FROM queries q
+		LEFT JOIN users u
+			ON q.author_id = u.id
+		WHERE q.id = ?
+		AND NOT q.deleted
+	`
+	query := &kolide.Query{}
+	err := db.Get(query, db.Rebind(sqlStatement), id)
+	if err == sql.ErrNoRows {
+		return nil, NotFound("Query").WithID(id)
+	} else if err != nil {
+		return nil, errors.Wrap(err, "selecting query")
+	}
+
+	if err := loadPacksForQueries(db, []*kolide.Query{query}); err != nil {
+		return nil, errors.Wrap(err, "loading packs for queries")
+	}
+
+	if err := loadTagsForQueries(db, []*kolide.Query{query}); err != nil {
+		return nil, errors.Wrap(err, "loading tags for queries")
+	}
+
+	return query, nil
+}
+
+// ListQueries returns a list of the queries matching the filter, with sort
+// order and results limit determined by passed in kolide.ListOptions
+func ListQueries(db *sqlx.DB, dialect Dialect, opt kolide.ListOptions, filter kolide.QueryFilter) ([]*kolide.Query, error) {
+	saved := true
+	if filter.Saved != nil {
+		saved = *filter.Saved
+	}
+
+	sqlStatement := `
+		SELECT q.*, COALESCE(NULLIF(u.name, ''), u.username) AS author_name
+		FROM queries q
+		LEFT JOIN users u
+			ON q.author_id = u.id
+		WHERE q.saved = ?
+		AND NOT q.deleted
+	`
+	args := []interface{}{saved}
+
+	if filter.AuthorID != nil {
+		sqlStatement += " AND q.author_id = ?"
+		args = append(args, *filter.AuthorID)
+	}
+	if filter.Category != "" {
+		sqlStatement += " AND q.category = ?"
+		args = append(args, filter.Category)
+	}
+	if len(filter.Tags) > 0 {
+		sqlStatement += `
+		AND q.id IN (
+			SELECT query_id
+			FROM query_tags
+			WHERE tag IN (?)
+			GROUP BY query_id
+			HAVING COUNT(*) = ?
+		)
+		`
+		args = append(args, filter.Tags, len(filter.Tags))
+	}
+	if condition, searchArgs := dialect.QuerySearchSQL(filter.Search); condition != "" {
+		sqlStatement += " AND " + condition
+		args = append(args, searchArgs...)
+	}
+	sqlStatement = AppendListOptionsToSQL(sqlStatement, opt)
+
+	sqlStatement, args, err := sqlx.In(sqlStatement, args...)
+	if err != nil {
+		return nil, errors.Wrap(err, "building list queries query")
+	}
+
+	results := []*kolide.Query{}
+	if err := db.Select(&results, db.Rebind(sqlStatement), args...); err != nil {
+		return nil, errors.Wrap(err, "listing queries")
+	}
+
+	if err := loadPacksForQueries(db, results); err != nil {
+		return nil, errors.Wrap(err, "loading packs for queries")
+	}
+
+	if err := loadTagsForQueries(db, results); err != nil {
+		return nil, errors.Wrap(err, "loading tags for queries")
+	}
+
+	return results, nil
+}
+
+// loadPacksForQueries loads the packs associated with the provided queries
+func loadPacksForQueries(db *sqlx.DB, queries []*kolide.Query) error {
+	if len(queries) == 0 {
+		return nil
+	}
+
+	sql := `
+		SELECT p.*, sq.query_id AS query_id
+		FROM packs p
+		JOIN scheduled_queries sq
+			ON p.id = sq.pack_id
+		WHERE query_id IN (?)
+	`
+
+	// Used to map the results
+	idQueries := map[uint]*kolide.Query{}
+	// Used for the IN clause
+	ids := []uint{}
+	for _, q := range queries {
+		q.Packs = make([]kolide.Pack, 0)
+		ids = append(ids, q.ID)
+		idQueries[q.ID] = q
+	}
+
+	query, args, err := sqlx.In(sql, ids)
+	if err != nil {
+		return errors.Wrap(err, "building query in load packs for queries")
+	}
+
+	rows := []struct {
+		QueryID uint `db:"query_id"`
+		kolide.Pack
+	}{}
+
+	err = db.Select(&rows, db.Rebind(query), args...)
+	if err != nil {
+		return errors.Wrap(err, "selecting load packs for queries")
+	}
+
+	for _, row := range rows {
+		q := idQueries[row.QueryID]
+		q.Packs = append(q.Packs, row.Pack)
+	}
+
+	return nil
+}
+
+// loadTagsForQueries loads the tags of the provided queries
+func loadTagsForQueries(db *sqlx.DB, queries []*kolide.Query) error {
+	if len(queries) == 0 {
+		return nil
+	}
+
+	sql := `
+		SELECT query_id, tag
+		FROM query_tags
+		WHERE query_id IN (?)
+		ORDER BY tag
+	`
+
+	idQueries := map[uint]*kolide.Query{}
+	ids := []uint{}
+	for _, q := range queries {
+		q.Tags = []string{}
+		ids = append(ids, q.ID)
+		idQueries[q.ID] = q
+	}
+
+	query, args, err := sqlx.In(sql, ids)
+	if err != nil {
+		return errors.Wrap(err, "building query in load tags for queries")
+	}
+
+	rows := []struct {
+		QueryID uint   `db:"query_id"`
+		Tag     string `db:"tag"`
+	}{}
+	if err := db.Select(&rows, db.Rebind(query), args...); err != nil {
+		return errors.Wrap(err, "selecting load tags for queries")
+	}
+
+	for _, row := range rows {
+		q := idQueries[row.QueryID]
+		q.Tags = append(q.Tags, row.Tag)
+	}
+
+	return nil
+}
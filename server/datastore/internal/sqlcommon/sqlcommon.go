@@ -0,0 +1,47 @@
+// Package sqlcommon holds the queries that the SQL datastores run
+// unchanged. Placeholders are written as ? and rebound for the driver of
+// the database.
+package sqlcommon
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/kolide/kolide-ose/server/kolide"
+)
+
+// defaultSelectLimit is the limit of a list with no page size, as in the
+// datastores.
+const defaultSelectLimit = 1000
+
+// appendLimitToSQL appends the limit and offset of the page selected by the
+// list options to the statement.
+func appendLimitToSQL(sql string, opt kolide.ListOptions) string {
+	if opt.PerPage == 0 {
+		opt.PerPage = defaultSelectLimit
+	}
+	sql = fmt.Sprintf("%s LIMIT %d", sql, opt.PerPage)
+	if offset := opt.PerPage * opt.Page; offset > 0 {
+		sql = fmt.Sprintf("%s OFFSET %d", sql, offset)
+	}
+	return sql
+}
+
+// AppendListOptionsToSQL appends the ordering, limit and offset selected by
+// the list options to the statement.
+func AppendListOptionsToSQL(sql string, opts kolide.ListOptions) string {
+	if opts.OrderKey != "" {
+		direction := "ASC"
+		if opts.OrderDirection == kolide.OrderDescending {
+			direction = "DESC"
+		}
+
+		sql = fmt.Sprintf("%s ORDER BY %s %s", sql, opts.OrderKey, direction)
+	}
+	return appendLimitToSQL(sql, opts)
+}
+
+// EscapeLike escapes the wildcards of a LIKE pattern.
+func EscapeLike(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(s)
+}
@@ -0,0 +1,98 @@
+package sqlcommon
+
+import (
+	"strings"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/kolide/kolide-ose/server/kolide"
+	"github.com/pkg/errors"
+)
+
+// targetHostsWhere returns the WHERE clause and arguments that match the
+// hosts in the selected targets. ok is false when no targets are selected.
+func targetHostsWhere(hostIDs []uint, labelIDs []uint) (where string, args []interface{}, ok bool) {
+	conditions := []string{}
+	if len(hostIDs) > 0 {
+		conditions = append(conditions, "h.id IN (?)")
+		args = append(args, hostIDs)
+	}
+	if len(labelIDs) > 0 {
+		conditions = append(conditions, `h.id IN (
+			SELECT lqe.host_id FROM label_query_executions lqe
+			WHERE lqe.label_id IN (?) AND lqe.matches
+		)`)
+		args = append(args, labelIDs)
+	}
+	if len(conditions) == 0 {
+		return "", nil, false
+	}
+	return " WHERE NOT h.deleted AND (" + strings.Join(conditions, " OR ") + ")", args, true
+}
+
+// ListTargetHosts returns a page of the hosts in the selected targets,
+// ordered by hostname and then ID.
+func ListTargetHosts(db *sqlx.DB, hostIDs []uint, labelIDs []uint, opt kolide.ListOptions) ([]*kolide.Host, error) {
+	hosts := []*kolide.Host{}
+	where, args, ok := targetHostsWhere(hostIDs, labelIDs)
+	if !ok {
+		return hosts, nil
+	}
+
+	sqlStatement := appendLimitToSQL("SELECT h.* FROM hosts h"+where+" ORDER BY h.host_name, h.id", opt)
+	query, args, err := sqlx.In(sqlStatement, args...)
+	if err != nil {
+		return nil, errors.Wrap(err, "building target hosts query")
+	}
+	if err := db.Select(&hosts, db.Rebind(query), args...); err != nil {
+		return nil, errors.Wrap(err, "listing target hosts")
+	}
+	return hosts, nil
+}
+
+// CountTargetHosts returns the number of hosts in the selected targets.
+func CountTargetHosts(db *sqlx.DB, hostIDs []uint, labelIDs []uint) (uint, error) {
+	where, args, ok := targetHostsWhere(hostIDs, labelIDs)
+	if !ok {
+		return 0, nil
+	}
+
+	query, args, err := sqlx.In("SELECT COUNT(*) FROM hosts h"+where, args...)
+	if err != nil {
+		return 0, errors.Wrap(err, "building target hosts count query")
+	}
+	var count uint
+	if err := db.Get(&count, db.Rebind(query), args...); err != nil {
+		return 0, errors.Wrap(err, "counting target hosts")
+	}
+	return count, nil
+}
+
+// TargetLabelIDsForHosts returns the IDs of the selected labels that each
+// of the hosts is a member of.
+func TargetLabelIDsForHosts(db *sqlx.DB, hostIDs []uint, labelIDs []uint) (map[uint][]uint, error) {
+	labels := map[uint][]uint{}
+	if len(hostIDs) == 0 || len(labelIDs) == 0 {
+		return labels, nil
+	}
+
+	sqlStatement := `
+		SELECT host_id, label_id FROM label_query_executions
+		WHERE host_id IN (?) AND label_id IN (?) AND matches
+		ORDER BY host_id, label_id
+	`
+	query, args, err := sqlx.In(sqlStatement, hostIDs, labelIDs)
+	if err != nil {
+		return nil, errors.Wrap(err, "building target labels query")
+	}
+	rows := []struct {
+		HostID  uint `db:"host_id"`
+		LabelID uint `db:"label_id"`
+	}{}
+	if err := db.Select(&rows, db.Rebind(query), args...); err != nil {
+		return nil, errors.Wrap(err, "listing target labels for hosts")
+	}
+	for _, row := range rows {
+		labels[row.HostID] = append(labels[row.HostID], row.LabelID)
+	}
+	return labels, nil
+}
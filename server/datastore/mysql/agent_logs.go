@@ -0,0 +1,145 @@
+package mysql
+
+import (
+	"github.com/kolide/kolide-ose/server/datastore/internal/sqlcommon"
+	"github.com/kolide/kolide-ose/server/kolide"
+	"github.com/pkg/errors"
+)
+
+// NewAgentLogs counts the logs of a host by severity and stores those of
+// warning severity and above, keeping only the most recent retain logs of
+// the host.
+func (d *Datastore) NewAgentLogs(hostID uint, logs []*kolide.AgentLog, retain int) (err error) {
+	if len(logs) == 0 {
+		return nil
+	}
+
+	txn, err := d.db.Beginx()
+	if err != nil {
+		return errors.Wrap(err, "new agent logs begin transaction")
+	}
+	var success bool
+	defer func() {
+		if success {
+			if err = txn.Commit(); err == nil {
+				return
+			}
+		}
+		txn.Rollback()
+	}()
+
+	stored, err := sqlcommon.InsertAgentLogs(txn, hostID, logs)
+	if err != nil {
+		return err
+	}
+
+	counts := kolide.AgentLogCounts{}
+	for _, log := range logs {
+		counts[log.Severity]++
+	}
+
+	for severity, count := range counts {
+		_, err := txn.Exec(`
+			INSERT INTO agent_log_counts (host_id, severity, count)
+			VALUES (?, ?, ?)
+			ON DUPLICATE KEY UPDATE count = count + VALUES(count)
+			`,
+			hostID, severity, count,
+		)
+		if err != nil {
+			return errors.Wrap(err, "updating agent log counts")
+		}
+	}
+
+	// Old logs are only removed when new ones were stored
+	if retain > 0 && stored > 0 {
+		// The derived table works around MySQL not supporting LIMIT in
+		// subqueries of the table being deleted from
+		_, err := txn.Exec(`
+			DELETE FROM agent_logs
+			WHERE host_id = ?
+			AND id < (
+				SELECT id FROM (
+					SELECT id
+					FROM agent_logs
+					WHERE host_id = ?
+					ORDER BY id DESC
+					LIMIT 1 OFFSET ?
+				) oldest
+			)
+			`,
+			hostID, hostID, retain-1,
+		)
+		if err != nil {
+			return errors.Wrap(err, "removing old agent logs")
+		}
+	}
+
+	success = true
+	return err
+}
+
+func (d *Datastore) ListAgentLogs(hostID uint, opt kolide.ListOptions) ([]*kolide.AgentLog, error) {
+	sqlStatement := `
+		SELECT *
+		FROM agent_logs
+		WHERE host_id = ?
+	`
+	// Logs are always listed newest first
+	opt.OrderKey = "id"
+	opt.OrderDirection = kolide.OrderDescending
+	sqlStatement = appendListOptionsToSQL(sqlStatement, opt)
+
+	logs := []*kolide.AgentLog{}
+	if err := d.db.Select(&logs, sqlStatement, hostID); err != nil {
+		return nil, errors.Wrap(err, "listing agent logs")
+	}
+	return logs, nil
+}
+
+func (d *Datastore) AgentLogCounts(hostID uint) (kolide.AgentLogCounts, error) {
+	rows := []struct {
+		Severity string
+		Count    uint
+	}{}
+	err := d.db.Select(&rows, "SELECT severity, count FROM agent_log_counts WHERE host_id = ?", hostID)
+	if err != nil {
+		return nil, errors.Wrap(err, "selecting agent log counts")
+	}
+
+	counts := kolide.AgentLogCounts{}
+	for _, row := range rows {
+		counts[row.Severity] = row.Count
+	}
+	return counts, nil
+}
+
+func (d *Datastore) ListAgentLogSummaries(severity string, limit uint) ([]*kolide.AgentLogSummary, error) {
+	sqlStatement := `
+		SELECT
+			l.severity,
+			l.filename,
+			l.line,
+			l.message,
+			COUNT(*) AS count,
+			COUNT(DISTINCT l.host_id) AS host_count,
+			MAX(l.created_at) AS last_seen
+		FROM agent_logs l
+		JOIN hosts h
+			ON l.host_id = h.id
+		WHERE NOT h.deleted
+		AND (? = '' OR l.severity = ?)
+		GROUP BY l.severity, l.filename, l.line, l.message
+		ORDER BY count DESC, last_seen DESC
+		LIMIT ?
+	`
+	if limit == 0 {
+		limit = defaultSelectLimit
+	}
+
+	summaries := []*kolide.AgentLogSummary{}
+	if err := d.db.Select(&summaries, sqlStatement, severity, severity, limit); err != nil {
+		return nil, errors.Wrap(err, "summarizing agent logs")
+	}
+	return summaries, nil
+}
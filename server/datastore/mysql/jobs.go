@@ -0,0 +1,36 @@
+package mysql
+
+import (
+	"time"
+
+	"github.com/kolide/kolide-ose/server/datastore/internal/sqlcommon"
+	"github.com/kolide/kolide-ose/server/kolide"
+	"github.com/pkg/errors"
+)
+
+func (d *Datastore) RegisterJob(name string, interval time.Duration) error {
+	sqlStatement := `
+		INSERT INTO jobs (name, run_interval, last_error) VALUES (?, ?, '')
+		ON DUPLICATE KEY UPDATE run_interval = VALUES(run_interval)
+	`
+	if _, err := d.db.Exec(sqlStatement, name, interval); err != nil {
+		return errors.Wrap(err, "registering job")
+	}
+	return nil
+}
+
+func (d *Datastore) LockJob(name, owner string, interval time.Duration, now time.Time) (bool, error) {
+	return sqlcommon.LockJob(d.db, name, owner, interval, now)
+}
+
+func (d *Datastore) FinishJob(name, owner string, finished time.Time, runErr string) error {
+	return sqlcommon.FinishJob(d.db, name, owner, finished, runErr)
+}
+
+func (d *Datastore) TriggerJob(name string, now time.Time) error {
+	return sqlcommon.TriggerJob(d.db, name, now)
+}
+
+func (d *Datastore) ListJobs() ([]*kolide.Job, error) {
+	return sqlcommon.ListJobs(d.db)
+}
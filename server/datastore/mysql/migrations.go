@@ -0,0 +1,29 @@
+package mysql
+
+import (
+	"github.com/kolide/kolide-ose/server/datastore/internal/migration"
+	"github.com/kolide/kolide-ose/server/datastore/mysql/migrations/data"
+	"github.com/kolide/kolide-ose/server/datastore/mysql/migrations/tables"
+	"github.com/kolide/kolide-ose/server/kolide"
+)
+
+var migrationClients = migration.Clients{
+	Tables: tables.MigrationClient,
+	Data:   data.MigrationClient,
+}
+
+func (d *Datastore) MigrationStatus() (*kolide.MigrationStatus, error) {
+	return migration.Status(d.db.DB, migrationClients)
+}
+
+func (d *Datastore) RollbackMigrations(version int64) error {
+	return migration.Rollback(d.db.DB, migrationClients, version)
+}
+
+func (d *Datastore) PlanMigrations() ([]*kolide.MigrationStep, error) {
+	return migration.PlanUp(d.db.DB, migrationClients)
+}
+
+func (d *Datastore) PlanRollback(version int64) ([]*kolide.MigrationStep, error) {
+	return migration.PlanRollback(d.db.DB, migrationClients, version)
+}
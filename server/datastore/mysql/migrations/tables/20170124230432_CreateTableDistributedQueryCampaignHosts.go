@@ -0,0 +1,24 @@
+package tables
+
+import (
+	"database/sql"
+)
+
+func init() {
+	MigrationClient.AddMigration(Up_20170124230432, Down_20170124230432)
+}
+
+func Up_20170124230432(tx *sql.Tx) error {
+	sqlStatement := "CREATE TABLE `distributed_query_campaign_hosts` (" +
+		"`distributed_query_campaign_id` int(10) unsigned NOT NULL," +
+		"`host_id` int(10) unsigned NOT NULL," +
+		"PRIMARY KEY (`distributed_query_campaign_id`, `host_id`)" +
+		") ENGINE=InnoDB DEFAULT CHARSET=utf8;"
+	_, err := tx.Exec(sqlStatement)
+	return err
+}
+
+func Down_20170124230432(tx *sql.Tx) error {
+	_, err := tx.Exec("DROP TABLE IF EXISTS `distributed_query_campaign_hosts`;")
+	return err
+}
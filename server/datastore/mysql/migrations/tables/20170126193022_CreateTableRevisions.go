@@ -0,0 +1,32 @@
+package tables
+
+import (
+	"database/sql"
+)
+
+func init() {
+	MigrationClient.AddMigration(Up_20170126193022, Down_20170126193022)
+}
+
+func Up_20170126193022(tx *sql.Tx) error {
+	sqlStatement := "CREATE TABLE `revisions` (" +
+		"`id` int(10) unsigned NOT NULL AUTO_INCREMENT," +
+		"`created_at` timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP," +
+		"`object_type` varchar(32) NOT NULL," +
+		"`object_id` int(10) unsigned NOT NULL," +
+		"`action` varchar(32) NOT NULL," +
+		"`author_id` int(10) unsigned DEFAULT NULL," +
+		"`restored_from` int(10) unsigned DEFAULT NULL," +
+		"`snapshot` mediumtext NOT NULL," +
+		"`changes` mediumtext NOT NULL," +
+		"PRIMARY KEY (`id`)," +
+		"KEY `idx_revisions_object` (`object_type`, `object_id`)" +
+		") ENGINE=InnoDB DEFAULT CHARSET=utf8;"
+	_, err := tx.Exec(sqlStatement)
+	return err
+}
+
+func Down_20170126193022(tx *sql.Tx) error {
+	_, err := tx.Exec("DROP TABLE IF EXISTS `revisions`;")
+	return err
+}
@@ -0,0 +1,39 @@
+package tables
+
+import (
+	"database/sql"
+)
+
+func init() {
+	MigrationClient.AddMigration(Up_20170127014618, Down_20170127014618)
+}
+
+func Up_20170127014618(tx *sql.Tx) error {
+	_, err := tx.Exec(
+		"ALTER TABLE `queries` " +
+			"ADD COLUMN `category` varchar(255) NOT NULL DEFAULT ''," +
+			"ADD KEY `idx_queries_category` (`category`)," +
+			"ADD KEY `idx_queries_saved_author` (`saved`, `author_id`);",
+	)
+	if err != nil {
+		return err
+	}
+	// The full-text index is added on its own, as InnoDB rebuilds the table
+	// to add its first full-text index
+	_, err = tx.Exec(
+		"ALTER TABLE `queries` " +
+			"ADD FULLTEXT KEY `queries_search` (`name`, `description`, `query`);",
+	)
+	return err
+}
+
+func Down_20170127014618(tx *sql.Tx) error {
+	_, err := tx.Exec(
+		"ALTER TABLE `queries` " +
+			"DROP INDEX `queries_search`," +
+			"DROP INDEX `idx_queries_saved_author`," +
+			"DROP INDEX `idx_queries_category`," +
+			"DROP COLUMN `category`;",
+	)
+	return err
+}
@@ -0,0 +1,25 @@
+package tables
+
+import (
+	"database/sql"
+)
+
+func init() {
+	MigrationClient.AddMigration(Up_20170127014725, Down_20170127014725)
+}
+
+func Up_20170127014725(tx *sql.Tx) error {
+	sqlStatement := "CREATE TABLE `query_tags` (" +
+		"`query_id` int(10) unsigned NOT NULL," +
+		"`tag` varchar(64) NOT NULL," +
+		"PRIMARY KEY (`query_id`, `tag`)," +
+		"KEY `idx_query_tags_tag` (`tag`, `query_id`)" +
+		") ENGINE=InnoDB DEFAULT CHARSET=utf8;"
+	_, err := tx.Exec(sqlStatement)
+	return err
+}
+
+func Down_20170127014725(tx *sql.Tx) error {
+	_, err := tx.Exec("DROP TABLE IF EXISTS `query_tags`;")
+	return err
+}
@@ -0,0 +1,33 @@
+package tables
+
+import (
+	"database/sql"
+)
+
+func init() {
+	MigrationClient.AddMigration(Up_20170131183644, Down_20170131183644)
+}
+
+func Up_20170131183644(tx *sql.Tx) error {
+	sqlStatement := "CREATE TABLE `scheduled_query_stats` (" +
+		"`host_id` int(10) unsigned NOT NULL," +
+		"`scheduled_query_id` int(10) unsigned NOT NULL," +
+		"`executions` bigint(20) unsigned NOT NULL DEFAULT 0," +
+		"`last_executed` timestamp NULL DEFAULT NULL," +
+		"`wall_time` bigint(20) unsigned NOT NULL DEFAULT 0," +
+		"`user_time` bigint(20) unsigned NOT NULL DEFAULT 0," +
+		"`system_time` bigint(20) unsigned NOT NULL DEFAULT 0," +
+		"`average_memory` bigint(20) unsigned NOT NULL DEFAULT 0," +
+		"`output_size` bigint(20) unsigned NOT NULL DEFAULT 0," +
+		"`denylisted` tinyint(1) NOT NULL DEFAULT FALSE," +
+		"PRIMARY KEY (`host_id`, `scheduled_query_id`)," +
+		"KEY `idx_scheduled_query_stats_scheduled_query` (`scheduled_query_id`)" +
+		") ENGINE=InnoDB DEFAULT CHARSET=utf8;"
+	_, err := tx.Exec(sqlStatement)
+	return err
+}
+
+func Down_20170131183644(tx *sql.Tx) error {
+	_, err := tx.Exec("DROP TABLE IF EXISTS `scheduled_query_stats`;")
+	return err
+}
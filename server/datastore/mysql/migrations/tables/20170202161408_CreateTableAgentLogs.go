@@ -0,0 +1,47 @@
+package tables
+
+import (
+	"database/sql"
+)
+
+func init() {
+	MigrationClient.AddMigration(Up_20170202161408, Down_20170202161408)
+}
+
+func Up_20170202161408(tx *sql.Tx) error {
+	_, err := tx.Exec("CREATE TABLE `agent_logs` (" +
+		"`id` int(10) unsigned NOT NULL AUTO_INCREMENT," +
+		"`host_id` int(10) unsigned NOT NULL," +
+		"`created_at` timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP," +
+		"`severity` varchar(16) NOT NULL," +
+		"`filename` varchar(255) NOT NULL DEFAULT ''," +
+		"`line` int(10) unsigned NOT NULL DEFAULT 0," +
+		"`message` text NOT NULL," +
+		"`version` varchar(32) NOT NULL DEFAULT ''," +
+		"PRIMARY KEY (`id`)," +
+		"KEY `idx_agent_logs_host` (`host_id`, `id`)," +
+		"KEY `idx_agent_logs_location` (`severity`, `filename`, `line`)" +
+		") ENGINE=InnoDB DEFAULT CHARSET=utf8;",
+	)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.Exec("CREATE TABLE `agent_log_counts` (" +
+		"`host_id` int(10) unsigned NOT NULL," +
+		"`severity` varchar(16) NOT NULL," +
+		"`count` int(10) unsigned NOT NULL DEFAULT 0," +
+		"PRIMARY KEY (`host_id`, `severity`)" +
+		") ENGINE=InnoDB DEFAULT CHARSET=utf8;",
+	)
+	return err
+}
+
+func Down_20170202161408(tx *sql.Tx) error {
+	_, err := tx.Exec("DROP TABLE IF EXISTS `agent_log_counts`;")
+	if err != nil {
+		return err
+	}
+	_, err = tx.Exec("DROP TABLE IF EXISTS `agent_logs`;")
+	return err
+}
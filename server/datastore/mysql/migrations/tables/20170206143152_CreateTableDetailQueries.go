@@ -0,0 +1,67 @@
+package tables
+
+import (
+	"database/sql"
+)
+
+func init() {
+	MigrationClient.AddMigration(Up_20170206143152, Down_20170206143152)
+}
+
+func Up_20170206143152(tx *sql.Tx) error {
+	_, err := tx.Exec("CREATE TABLE `detail_queries` (" +
+		"`id` int(10) unsigned NOT NULL AUTO_INCREMENT," +
+		"`created_at` timestamp DEFAULT CURRENT_TIMESTAMP," +
+		"`updated_at` timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP," +
+		"`name` varchar(64) NOT NULL," +
+		"`query` text NOT NULL," +
+		"`platform` varchar(255) NOT NULL DEFAULT ''," +
+		"`interval` int(10) unsigned NOT NULL," +
+		"`columns` text NOT NULL," +
+		"PRIMARY KEY (`id`)," +
+		"UNIQUE KEY `idx_detail_queries_unique_name` (`name`)" +
+		") ENGINE=InnoDB DEFAULT CHARSET=utf8;",
+	)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.Exec("CREATE TABLE `detail_query_executions` (" +
+		"`host_id` int(10) unsigned NOT NULL," +
+		"`detail_query_id` int(10) unsigned NOT NULL," +
+		"`updated_at` timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP," +
+		"PRIMARY KEY (`host_id`, `detail_query_id`)," +
+		"KEY `idx_detail_query_executions_query` (`detail_query_id`)" +
+		") ENGINE=InnoDB DEFAULT CHARSET=utf8;",
+	)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.Exec("CREATE TABLE `host_attributes` (" +
+		"`host_id` int(10) unsigned NOT NULL," +
+		"`detail_query_id` int(10) unsigned NOT NULL," +
+		"`key` varchar(255) NOT NULL," +
+		"`type` varchar(16) NOT NULL," +
+		"`value` text NOT NULL," +
+		"`updated_at` timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP," +
+		"PRIMARY KEY (`host_id`, `key`)," +
+		"KEY `idx_host_attributes_key` (`key`)," +
+		"KEY `idx_host_attributes_query` (`detail_query_id`)" +
+		") ENGINE=InnoDB DEFAULT CHARSET=utf8;",
+	)
+	return err
+}
+
+func Down_20170206143152(tx *sql.Tx) error {
+	_, err := tx.Exec("DROP TABLE IF EXISTS `host_attributes`;")
+	if err != nil {
+		return err
+	}
+	_, err = tx.Exec("DROP TABLE IF EXISTS `detail_query_executions`;")
+	if err != nil {
+		return err
+	}
+	_, err = tx.Exec("DROP TABLE IF EXISTS `detail_queries`;")
+	return err
+}
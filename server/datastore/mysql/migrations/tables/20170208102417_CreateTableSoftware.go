@@ -0,0 +1,45 @@
+package tables
+
+import (
+	"database/sql"
+)
+
+func init() {
+	MigrationClient.AddMigration(Up_20170208102417, Down_20170208102417)
+}
+
+func Up_20170208102417(tx *sql.Tx) error {
+	_, err := tx.Exec("CREATE TABLE `software` (" +
+		"`id` int(10) unsigned NOT NULL AUTO_INCREMENT," +
+		"`name` varchar(255) NOT NULL," +
+		"`version` varchar(255) NOT NULL DEFAULT ''," +
+		"`source` varchar(64) NOT NULL," +
+		"PRIMARY KEY (`id`)," +
+		"UNIQUE KEY `idx_software_unique_name_version_source` (`name`, `version`, `source`)," +
+		"KEY `idx_software_source` (`source`)" +
+		") ENGINE=InnoDB DEFAULT CHARSET=utf8;",
+	)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.Exec("CREATE TABLE `host_software` (" +
+		"`host_id` int(10) unsigned NOT NULL," +
+		"`software_id` int(10) unsigned NOT NULL," +
+		"`first_seen` timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP," +
+		"`last_seen` timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP," +
+		"PRIMARY KEY (`host_id`, `software_id`)," +
+		"KEY `idx_host_software_software` (`software_id`)" +
+		") ENGINE=InnoDB DEFAULT CHARSET=utf8;",
+	)
+	return err
+}
+
+func Down_20170208102417(tx *sql.Tx) error {
+	_, err := tx.Exec("DROP TABLE IF EXISTS `host_software`;")
+	if err != nil {
+		return err
+	}
+	_, err = tx.Exec("DROP TABLE IF EXISTS `software`;")
+	return err
+}
@@ -0,0 +1,63 @@
+package tables
+
+import (
+	"database/sql"
+)
+
+func init() {
+	MigrationClient.AddMigration(Up_20170209111530, Down_20170209111530)
+}
+
+func Up_20170209111530(tx *sql.Tx) error {
+	_, err := tx.Exec("CREATE TABLE `vulnerabilities` (" +
+		"`cve` varchar(32) NOT NULL," +
+		"`summary` text NOT NULL," +
+		"`cvss_score` double NOT NULL DEFAULT 0," +
+		"PRIMARY KEY (`cve`)" +
+		") ENGINE=InnoDB DEFAULT CHARSET=utf8;",
+	)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.Exec("CREATE TABLE `vulnerable_software` (" +
+		"`id` int(10) unsigned NOT NULL AUTO_INCREMENT," +
+		"`cve` varchar(32) NOT NULL," +
+		"`vendor` varchar(255) NOT NULL DEFAULT ''," +
+		"`product` varchar(255) NOT NULL," +
+		"`version` varchar(255) NOT NULL DEFAULT ''," +
+		"`version_start_including` varchar(255) NOT NULL DEFAULT ''," +
+		"`version_start_excluding` varchar(255) NOT NULL DEFAULT ''," +
+		"`version_end_including` varchar(255) NOT NULL DEFAULT ''," +
+		"`version_end_excluding` varchar(255) NOT NULL DEFAULT ''," +
+		"PRIMARY KEY (`id`)," +
+		"KEY `idx_vulnerable_software_cve` (`cve`)," +
+		"KEY `idx_vulnerable_software_product` (`product`)" +
+		") ENGINE=InnoDB DEFAULT CHARSET=utf8;",
+	)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.Exec("CREATE TABLE `software_vulnerabilities` (" +
+		"`software_id` int(10) unsigned NOT NULL," +
+		"`cve` varchar(32) NOT NULL," +
+		"PRIMARY KEY (`software_id`, `cve`)," +
+		"KEY `idx_software_vulnerabilities_cve` (`cve`)" +
+		") ENGINE=InnoDB DEFAULT CHARSET=utf8;",
+	)
+	return err
+}
+
+func Down_20170209111530(tx *sql.Tx) error {
+	_, err := tx.Exec("DROP TABLE IF EXISTS `software_vulnerabilities`;")
+	if err != nil {
+		return err
+	}
+	_, err = tx.Exec("DROP TABLE IF EXISTS `vulnerable_software`;")
+	if err != nil {
+		return err
+	}
+	_, err = tx.Exec("DROP TABLE IF EXISTS `vulnerabilities`;")
+	return err
+}
@@ -0,0 +1,25 @@
+package tables
+
+import "database/sql"
+
+func init() {
+	MigrationClient.AddMigration(Up_20170210094212, Down_20170210094212)
+}
+
+func Up_20170210094212(tx *sql.Tx) error {
+	_, err := tx.Exec(
+		"ALTER TABLE `hosts` " +
+			"ADD COLUMN `distributed_interval` int(10) unsigned NOT NULL DEFAULT 0, " +
+			"ADD COLUMN `config_refresh` int(10) unsigned NOT NULL DEFAULT 0;",
+	)
+	return err
+}
+
+func Down_20170210094212(tx *sql.Tx) error {
+	_, err := tx.Exec(
+		"ALTER TABLE `hosts` " +
+			"DROP COLUMN `distributed_interval`, " +
+			"DROP COLUMN `config_refresh`;",
+	)
+	return err
+}
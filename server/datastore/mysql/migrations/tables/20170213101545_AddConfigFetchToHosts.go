@@ -0,0 +1,25 @@
+package tables
+
+import "database/sql"
+
+func init() {
+	MigrationClient.AddMigration(Up_20170213101545, Down_20170213101545)
+}
+
+func Up_20170213101545(tx *sql.Tx) error {
+	_, err := tx.Exec(
+		"ALTER TABLE `hosts` " +
+			"ADD COLUMN `config_fetch_time` timestamp NOT NULL DEFAULT '1970-01-02 00:00:00', " +
+			"ADD COLUMN `config_hash` varchar(64) NOT NULL DEFAULT '';",
+	)
+	return err
+}
+
+func Down_20170213101545(tx *sql.Tx) error {
+	_, err := tx.Exec(
+		"ALTER TABLE `hosts` " +
+			"DROP COLUMN `config_fetch_time`, " +
+			"DROP COLUMN `config_hash`;",
+	)
+	return err
+}
@@ -0,0 +1,45 @@
+package tables
+
+import (
+	"database/sql"
+	"fmt"
+
+	"github.com/kolide/kolide-ose/server/kolide"
+)
+
+func init() {
+	MigrationClient.AddMigration(Up_20170214153012, Down_20170214153012)
+}
+
+func Up_20170214153012(tx *sql.Tx) error {
+	_, err := tx.Exec(
+		"ALTER TABLE `labels` " +
+			"ADD COLUMN `attribute` varchar(1024) NOT NULL DEFAULT '';",
+	)
+	if err != nil {
+		return err
+	}
+
+	// Attribute labels kept their filter in the query column
+	_, err = tx.Exec(fmt.Sprintf(
+		"UPDATE `labels` SET `attribute` = `query`, `query` = '' WHERE `label_type` = %d;",
+		kolide.LabelTypeAttribute,
+	))
+	return err
+}
+
+func Down_20170214153012(tx *sql.Tx) error {
+	_, err := tx.Exec(fmt.Sprintf(
+		"UPDATE `labels` SET `query` = `attribute` WHERE `label_type` = %d;",
+		kolide.LabelTypeAttribute,
+	))
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.Exec(
+		"ALTER TABLE `labels` " +
+			"DROP COLUMN `attribute`;",
+	)
+	return err
+}
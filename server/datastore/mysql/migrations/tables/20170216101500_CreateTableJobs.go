@@ -0,0 +1,30 @@
+package tables
+
+import (
+	"database/sql"
+)
+
+func init() {
+	MigrationClient.AddMigration(Up_20170216101500, Down_20170216101500)
+}
+
+func Up_20170216101500(tx *sql.Tx) error {
+	_, err := tx.Exec("CREATE TABLE `jobs` (" +
+		"`name` varchar(255) NOT NULL," +
+		"`run_interval` bigint NOT NULL DEFAULT 0," +
+		"`lock_owner` varchar(255) NOT NULL DEFAULT ''," +
+		"`locked_until` timestamp NOT NULL DEFAULT '1970-01-02 00:00:00'," +
+		"`last_instance` varchar(255) NOT NULL DEFAULT ''," +
+		"`last_started_at` timestamp NOT NULL DEFAULT '1970-01-02 00:00:00'," +
+		"`last_finished_at` timestamp NOT NULL DEFAULT '1970-01-02 00:00:00'," +
+		"`last_error` text NOT NULL," +
+		"PRIMARY KEY (`name`)" +
+		") ENGINE=InnoDB DEFAULT CHARSET=utf8;",
+	)
+	return err
+}
+
+func Down_20170216101500(tx *sql.Tx) error {
+	_, err := tx.Exec("DROP TABLE IF EXISTS `jobs`;")
+	return err
+}
@@ -8,6 +8,7 @@ import (
 	"github.com/go-kit/kit/log"
 	"github.com/jmoiron/sqlx"
 	"github.com/kolide/kolide-ose/server/config"
+	"github.com/kolide/kolide-ose/server/datastore/internal/sqlcommon"
 	"github.com/kolide/kolide-ose/server/datastore/mysql/migrations/data"
 	"github.com/kolide/kolide-ose/server/datastore/mysql/migrations/tables"
 	"github.com/kolide/kolide-ose/server/kolide"
@@ -17,6 +18,18 @@ const (
 	defaultSelectLimit = 1000
 )
 
+// dialect is what the shared queries need from MySQL. The default collation
+// compares ignoring case.
+var dialect = sqlcommon.Dialect{
+	Like:                   "LIKE ?",
+	InsertIgnore:           "INSERT IGNORE INTO %s",
+	IsDuplicate:            isDuplicate,
+	HostOfflineSQL:         hostOfflineSQL,
+	HostAttributeFilterSQL: hostAttributeFilterSQL,
+	QuerySearchSQL:         querySearchSQL,
+	UpsertNetworkInterface: upsertNetworkInterface,
+}
+
 // Datastore is an implementation of kolide.Datastore interface backed by
 // MySQL
 type Datastore struct {
@@ -145,30 +158,7 @@ func (d *Datastore) log(msg string) {
 }
 
 func appendListOptionsToSQL(sql string, opts kolide.ListOptions) string {
-	if opts.OrderKey != "" {
-		direction := "ASC"
-		if opts.OrderDirection == kolide.OrderDescending {
-			direction = "DESC"
-		}
-
-		sql = fmt.Sprintf("%s ORDER BY %s %s", sql, opts.OrderKey, direction)
-	}
-	// REVIEW: If caller doesn't supply a limit apply a default limit of 1000
-	// to insure that an unbounded query with many results doesn't consume too
-	// much memory or hang
-	if opts.PerPage == 0 {
-		opts.PerPage = defaultSelectLimit
-	}
-
-	sql = fmt.Sprintf("%s LIMIT %d", sql, opts.PerPage)
-
-	offset := opts.PerPage * opts.Page
-
-	if offset > 0 {
-		sql = fmt.Sprintf("%s OFFSET %d", sql, offset)
-	}
-
-	return sql
+	return sqlcommon.AppendListOptionsToSQL(sql, opts)
 }
 
 // generateMysqlConnectionString returns a MySQL connection string using the
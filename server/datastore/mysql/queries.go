@@ -1,73 +1,23 @@
 package mysql
 
 import (
-	"database/sql"
+	"strings"
+	"unicode"
 
-	"github.com/VividCortex/mysqlerr"
-	"github.com/go-sql-driver/mysql"
-	"github.com/jmoiron/sqlx"
+	"github.com/kolide/kolide-ose/server/datastore/internal/sqlcommon"
 	"github.com/kolide/kolide-ose/server/kolide"
-	"github.com/pkg/errors"
 )
 
 func (d *Datastore) QueryByName(name string) (*kolide.Query, bool, error) {
-	sqlStatement := `
-		SELECT *
-			FROM queries
-			WHERE name = ? AND NOT deleted
-	`
-	var query kolide.Query
-	err := d.db.Get(&query, sqlStatement, name)
-	if err != nil {
-		if err == sql.ErrNoRows {
-			return nil, false, nil
-		}
-		return nil, false, errors.Wrap(err, "selecting query by name")
-	}
-	return &query, true, nil
+	return sqlcommon.QueryByName(d.db, name)
 }
 
-// NewQuery creates a Query
 func (d *Datastore) NewQuery(query *kolide.Query) (*kolide.Query, error) {
-	sql := `
-		INSERT INTO queries (
-			name,
-			description,
-			query,
-			saved,
-			author_id
-		) VALUES ( ?, ?, ?, ?, ? )
-	`
-	result, err := d.db.Exec(sql, query.Name, query.Description, query.Query, query.Saved, query.AuthorID)
-	if driverErr, ok := err.(*mysql.MySQLError); ok {
-		if driverErr.Number == mysqlerr.ER_DUP_ENTRY {
-			// TODO: this shouldn't require an ID parameter
-			return nil, alreadyExists("Query", 0)
-		}
-	}
-	if err != nil {
-		return nil, errors.Wrap(err, "inserting new query")
-	}
-
-	id, _ := result.LastInsertId()
-	query.ID = uint(id)
-	query.Packs = []kolide.Pack{}
-	return query, nil
+	return sqlcommon.NewQuery(d.db, dialect, query)
 }
 
-// SaveQuery saves changes to a Query.
 func (d *Datastore) SaveQuery(q *kolide.Query) error {
-	sql := `
-		UPDATE queries
-			SET name = ?, description = ?, query = ?, author_id = ?, saved = ?
-			WHERE id = ? AND NOT deleted
-	`
-	_, err := d.db.Exec(sql, q.Name, q.Description, q.Query, q.AuthorID, q.Saved, q.ID)
-	if err != nil {
-		return errors.Wrap(err, "updating query")
-	}
-
-	return nil
+	return sqlcommon.SaveQuery(d.db, dialect, q)
 }
 
 // DeleteQuery soft deletes Query identified by Query.ID
@@ -75,124 +25,38 @@ func (d *Datastore) DeleteQuery(qid uint) error {
 	return d.deleteEntity("queries", qid)
 }
 
-// DeleteQueries (soft) deletes the existing query objects with the provided
-// IDs. The number of deleted queries is returned along with any error.
 func (d *Datastore) DeleteQueries(ids []uint) (uint, error) {
-	sql := `
-		UPDATE queries
-			SET deleted_at = NOW(), deleted = true
-			WHERE id IN (?)
-	`
-	query, args, err := sqlx.In(sql, ids)
-	if err != nil {
-		return 0, errors.Wrap(err, "building delete query query")
-	}
-
-	result, err := d.db.Exec(query, args...)
-	if err != nil {
-		return 0, errors.Wrap(err, "updating delete query")
-	}
-
-	deleted, err := result.RowsAffected()
-	if err != nil {
-		return 0, errors.Wrap(err, "fetching delete query rows effected")
-	}
-
-	return uint(deleted), nil
+	return sqlcommon.DeleteQueries(d.db, ids, d.clock.Now())
 }
 
-// Query returns a single Query identified by id, if such
-// exists
 func (d *Datastore) Query(id uint) (*kolide.Query, error) {
-	sql := `
-		SELECT q.*, COALESCE(NULLIF(u.name, ''), u.username) AS author_name
-		FROM queries q
-		LEFT JOIN users u
-			ON q.author_id = u.id
-		WHERE q.id = ?
-		AND NOT q.deleted
-	`
-	query := &kolide.Query{}
-	if err := d.db.Get(query, sql, id); err != nil {
-		return nil, errors.Wrap(err, "selecting query")
-	}
-
-	if err := d.loadPacksForQueries([]*kolide.Query{query}); err != nil {
-		return nil, errors.Wrap(err, "loading packs for queries")
-	}
-
-	return query, nil
+	return sqlcommon.Query(d.db, id)
 }
 
-// ListQueries returns a list of queries with sort order and results limit
-// determined by passed in kolide.ListOptions
-func (d *Datastore) ListQueries(opt kolide.ListOptions) ([]*kolide.Query, error) {
-	sql := `
-		SELECT q.*, COALESCE(NULLIF(u.name, ''), u.username) AS author_name
-		FROM queries q
-		LEFT JOIN users u
-			ON q.author_id = u.id
-		WHERE saved = true
-		AND NOT q.deleted
-	`
-	sql = appendListOptionsToSQL(sql, opt)
-	results := []*kolide.Query{}
-
-	if err := d.db.Select(&results, sql); err != nil {
-		return nil, errors.Wrap(err, "listing queries")
-	}
-
-	if err := d.loadPacksForQueries(results); err != nil {
-		return nil, errors.Wrap(err, "loading packs for queries")
-	}
-
-	return results, nil
-
+func (d *Datastore) ListQueries(opt kolide.ListOptions, filter kolide.QueryFilter) ([]*kolide.Query, error) {
+	return sqlcommon.ListQueries(d.db, dialect, opt, filter)
 }
 
-// loadPacksForQueries loads the packs associated with the provided queries
-func (d *Datastore) loadPacksForQueries(queries []*kolide.Query) error {
-	if len(queries) == 0 {
-		return nil
-	}
-
-	sql := `
-		SELECT p.*, sq.query_id AS query_id
-		FROM packs p
-		JOIN scheduled_queries sq
-			ON p.id = sq.pack_id
-		WHERE query_id IN (?)
-	`
-
-	// Used to map the results
-	id_queries := map[uint]*kolide.Query{}
-	// Used for the IN clause
-	ids := []uint{}
-	for _, q := range queries {
-		q.Packs = make([]kolide.Pack, 0)
-		ids = append(ids, q.ID)
-		id_queries[q.ID] = q
-	}
-
-	query, args, err := sqlx.In(sql, ids)
-	if err != nil {
-		return errors.Wrap(err, "building query in load packs for queries")
-	}
-
-	rows := []struct {
-		QueryID uint `db:"query_id"`
-		kolide.Pack
-	}{}
-
-	err = d.db.Select(&rows, query, args...)
-	if err != nil {
-		return errors.Wrap(err, "selecting load packs for queries")
+// querySearchSQL returns the boolean mode full-text search condition on the
+// queries table, aliased q, that requires every word of the search.
+func querySearchSQL(search string) (string, []interface{}) {
+	terms := querySearchTerms(search)
+	if terms == "" {
+		return "", nil
 	}
+	return "MATCH(q.name, q.description, q.query) AGAINST(? IN BOOLEAN MODE)", []interface{}{terms}
+}
 
-	for _, row := range rows {
-		q := id_queries[row.QueryID]
-		q.Packs = append(q.Packs, row.Pack)
+// querySearchTerms converts a search into a boolean mode full-text search
+// requiring every word, each matched as a prefix. Characters that are
+// operators in boolean mode are dropped.
+func querySearchTerms(search string) string {
+	words := strings.FieldsFunc(search, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '_'
+	})
+	terms := make([]string, len(words))
+	for i, word := range words {
+		terms[i] = "+" + word + "*"
 	}
-
-	return nil
+	return strings.Join(terms, " ")
 }
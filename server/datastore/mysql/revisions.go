@@ -0,0 +1,73 @@
+package mysql
+
+import (
+	"database/sql"
+
+	"github.com/kolide/kolide-ose/server/kolide"
+	"github.com/pkg/errors"
+)
+
+func (d *Datastore) NewRevision(rev *kolide.Revision) (*kolide.Revision, error) {
+	sqlStatement := `
+		INSERT INTO revisions (
+			created_at,
+			object_type,
+			object_id,
+			action,
+			author_id,
+			restored_from,
+			snapshot,
+			changes
+		)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+	`
+	result, err := d.db.Exec(sqlStatement, rev.CreatedAt, rev.ObjectType, rev.ObjectID,
+		rev.Action, rev.AuthorID, rev.RestoredFrom, rev.Snapshot, rev.Changes)
+	if err != nil {
+		return nil, errors.Wrap(err, "inserting revision")
+	}
+
+	id, _ := result.LastInsertId()
+	rev.ID = uint(id)
+	return rev, nil
+}
+
+func (d *Datastore) Revision(id uint) (*kolide.Revision, error) {
+	sqlStatement := `
+		SELECT r.*, COALESCE(NULLIF(u.name, ''), u.username, '') AS author_name
+		FROM revisions r
+		LEFT JOIN users u
+			ON r.author_id = u.id
+		WHERE r.id = ?
+	`
+	rev := &kolide.Revision{}
+	err := d.db.Get(rev, sqlStatement, id)
+	if err == sql.ErrNoRows {
+		return nil, notFound("Revision").WithID(id)
+	} else if err != nil {
+		return nil, errors.Wrap(err, "selecting revision")
+	}
+
+	return rev, nil
+}
+
+func (d *Datastore) ListRevisions(objectType string, objectID uint, opt kolide.ListOptions) ([]*kolide.Revision, error) {
+	sqlStatement := `
+		SELECT r.*, COALESCE(NULLIF(u.name, ''), u.username, '') AS author_name
+		FROM revisions r
+		LEFT JOIN users u
+			ON r.author_id = u.id
+		WHERE r.object_type = ? AND r.object_id = ?
+	`
+	// Revisions are always listed newest first
+	opt.OrderKey = "r.id"
+	opt.OrderDirection = kolide.OrderDescending
+	sqlStatement = appendListOptionsToSQL(sqlStatement, opt)
+
+	revisions := []*kolide.Revision{}
+	if err := d.db.Select(&revisions, sqlStatement, objectType, objectID); err != nil {
+		return nil, errors.Wrap(err, "listing revisions")
+	}
+
+	return revisions, nil
+}
@@ -104,3 +104,22 @@ func (d *Datastore) ListScheduledQueriesInPack(id uint, opts kolide.ListOptions)
 
 	return results, nil
 }
+
+func (d *Datastore) ListScheduledQueryNames() ([]*kolide.ScheduledQueryName, error) {
+	query := `
+		SELECT sq.id, p.name AS pack_name, q.name AS query_name
+		FROM scheduled_queries sq
+		JOIN packs p
+		ON sq.pack_id = p.id
+		JOIN queries q
+		ON sq.query_id = q.id
+		WHERE NOT sq.deleted
+		AND NOT p.deleted
+		ORDER BY sq.id
+	`
+	names := []*kolide.ScheduledQueryName{}
+	if err := d.db.Select(&names, query); err != nil {
+		return nil, errors.Wrap(err, "listing scheduled query names")
+	}
+	return names, nil
+}
@@ -0,0 +1,119 @@
+package mysql
+
+import (
+	"github.com/kolide/kolide-ose/server/kolide"
+	"github.com/pkg/errors"
+)
+
+// SaveScheduledQueryStats replaces the performance stats stored for a host
+// with the provided stats.
+func (d *Datastore) SaveScheduledQueryStats(hostID uint, stats []kolide.ScheduledQueryStats) (err error) {
+	sqlStatement := `
+		INSERT INTO scheduled_query_stats (
+			host_id,
+			scheduled_query_id,
+			executions,
+			last_executed,
+			wall_time,
+			user_time,
+			system_time,
+			average_memory,
+			output_size,
+			denylisted
+		) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+		ON DUPLICATE KEY UPDATE
+			executions = VALUES(executions),
+			last_executed = VALUES(last_executed),
+			wall_time = VALUES(wall_time),
+			user_time = VALUES(user_time),
+			system_time = VALUES(system_time),
+			average_memory = VALUES(average_memory),
+			output_size = VALUES(output_size),
+			denylisted = VALUES(denylisted)
+	`
+	txn, err := d.db.Begin()
+	if err != nil {
+		return errors.Wrap(err, "save scheduled query stats begin transaction")
+	}
+	var success bool
+	defer func() {
+		if success {
+			if err = txn.Commit(); err == nil {
+				return
+			}
+		}
+		txn.Rollback()
+	}()
+
+	_, err = txn.Exec("DELETE FROM scheduled_query_stats WHERE host_id = ?", hostID)
+	if err != nil {
+		return errors.Wrap(err, "deleting scheduled query stats")
+	}
+	for _, s := range stats {
+		_, err := txn.Exec(sqlStatement,
+			hostID,
+			s.ScheduledQueryID,
+			s.Executions,
+			s.LastExecuted,
+			s.WallTime,
+			s.UserTime,
+			s.SystemTime,
+			s.AverageMemory,
+			s.OutputSize,
+			s.Denylisted,
+		)
+		if err != nil {
+			return errors.Wrap(err, "inserting scheduled query stats")
+		}
+	}
+
+	success = true
+	return err
+}
+
+// ScheduledQueryStatsInPack returns the performance stats of each scheduled
+// query in a pack, summed over the (not deleted) hosts that reported them.
+func (d *Datastore) ScheduledQueryStatsInPack(packID uint) ([]*kolide.ScheduledQueryStatsRollup, error) {
+	sqlStatement := `
+		SELECT
+			sq.id AS scheduled_query_id,
+			sq.pack_id,
+			sq.query_id,
+			q.name,
+			sq.interval,
+			COUNT(s.host_id) AS host_count,
+			COALESCE(SUM(s.denylisted), 0) AS denylisted_count,
+			COALESCE(SUM(s.executions), 0) AS executions,
+			COALESCE(SUM(s.wall_time), 0) AS wall_time,
+			COALESCE(SUM(s.user_time), 0) AS user_time,
+			COALESCE(SUM(s.system_time), 0) AS system_time,
+			COALESCE(SUM(s.output_size), 0) AS output_size,
+			COALESCE(ROUND(AVG(s.average_memory)), 0) AS average_memory,
+			COALESCE(MAX(s.average_memory), 0) AS max_memory
+		FROM scheduled_queries sq
+		JOIN queries q
+			ON sq.query_id = q.id
+		LEFT JOIN (
+			SELECT st.*
+			FROM scheduled_query_stats st
+			JOIN hosts h
+				ON st.host_id = h.id
+			WHERE NOT h.deleted
+		) s
+			ON s.scheduled_query_id = sq.id
+		WHERE sq.pack_id = ?
+		AND NOT sq.deleted
+		GROUP BY sq.id, sq.pack_id, sq.query_id, q.name, sq.interval
+		ORDER BY sq.id
+	`
+	results := []*kolide.ScheduledQueryStatsRollup{}
+	if err := d.db.Select(&results, sqlStatement, packID); err != nil {
+		return nil, errors.Wrap(err, "selecting scheduled query stats in pack")
+	}
+
+	for _, r := range results {
+		r.ComputeAverages()
+	}
+
+	return results, nil
+}
@@ -0,0 +1,18 @@
+package mysql
+
+import (
+	"github.com/kolide/kolide-ose/server/datastore/internal/sqlcommon"
+	"github.com/kolide/kolide-ose/server/kolide"
+)
+
+func (d *Datastore) ListTargetHosts(hostIDs []uint, labelIDs []uint, opt kolide.ListOptions) ([]*kolide.Host, error) {
+	return sqlcommon.ListTargetHosts(d.db, hostIDs, labelIDs, opt)
+}
+
+func (d *Datastore) CountTargetHosts(hostIDs []uint, labelIDs []uint) (uint, error) {
+	return sqlcommon.CountTargetHosts(d.db, hostIDs, labelIDs)
+}
+
+func (d *Datastore) TargetLabelIDsForHosts(hostIDs []uint, labelIDs []uint) (map[uint][]uint, error) {
+	return sqlcommon.TargetLabelIDsForHosts(d.db, hostIDs, labelIDs)
+}
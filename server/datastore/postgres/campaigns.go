@@ -0,0 +1,44 @@
+package postgres
+
+import (
+	"time"
+
+	"github.com/kolide/kolide-ose/server/datastore/internal/sqlcommon"
+	"github.com/kolide/kolide-ose/server/kolide"
+)
+
+func (d *Datastore) NewDistributedQueryCampaign(camp *kolide.DistributedQueryCampaign) (*kolide.DistributedQueryCampaign, error) {
+	return sqlcommon.NewDistributedQueryCampaign(d.db, dialect, camp)
+}
+
+func (d *Datastore) DistributedQueryCampaign(id uint) (*kolide.DistributedQueryCampaign, error) {
+	return sqlcommon.DistributedQueryCampaign(d.db, id)
+}
+
+func (d *Datastore) SaveDistributedQueryCampaign(camp *kolide.DistributedQueryCampaign) error {
+	return sqlcommon.SaveDistributedQueryCampaign(d.db, camp)
+}
+
+func (d *Datastore) DistributedQueryCampaignTargetIDs(id uint) (hostIDs []uint, labelIDs []uint, err error) {
+	return sqlcommon.DistributedQueryCampaignTargetIDs(d.db, id)
+}
+
+func (d *Datastore) NewDistributedQueryCampaignTarget(target *kolide.DistributedQueryCampaignTarget) (*kolide.DistributedQueryCampaignTarget, error) {
+	return sqlcommon.NewDistributedQueryCampaignTarget(d.db, dialect, target)
+}
+
+func (d *Datastore) NewDistributedQueryCampaignHosts(campaignID uint, hostIDs []uint) error {
+	return sqlcommon.NewDistributedQueryCampaignHosts(d.db, dialect, campaignID, hostIDs)
+}
+
+func (d *Datastore) DistributedQueryCampaignHostIDs(id uint) ([]uint, error) {
+	return sqlcommon.DistributedQueryCampaignHostIDs(d.db, id)
+}
+
+func (d *Datastore) NewDistributedQueryExecution(exec *kolide.DistributedQueryExecution) (*kolide.DistributedQueryExecution, error) {
+	return sqlcommon.NewDistributedQueryExecution(d.db, dialect, exec)
+}
+
+func (d *Datastore) CleanupDistributedQueryCampaigns(now time.Time) (expired uint, deleted uint, err error) {
+	return sqlcommon.CleanupDistributedQueryCampaigns(d.db, now)
+}
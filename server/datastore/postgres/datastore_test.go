@@ -0,0 +1,76 @@
+package postgres
+
+import (
+	"testing"
+
+	"github.com/kolide/kolide-ose/server/kolide"
+)
+
+func TestAppendListOptionsToSQL(t *testing.T) {
+	sql := "SELECT * FROM app_configs"
+	opts := kolide.ListOptions{
+		OrderKey: "name",
+	}
+
+	actual := appendListOptionsToSQL(sql, opts)
+	expected := "SELECT * FROM app_configs ORDER BY name ASC LIMIT 1000"
+	if actual != expected {
+		t.Error("Expected", expected, "Actual", actual)
+	}
+
+	sql = "SELECT * FROM app_configs"
+	opts.OrderDirection = kolide.OrderDescending
+	actual = appendListOptionsToSQL(sql, opts)
+	expected = "SELECT * FROM app_configs ORDER BY name DESC LIMIT 1000"
+	if actual != expected {
+		t.Error("Expected", expected, "Actual", actual)
+	}
+
+	opts = kolide.ListOptions{
+		PerPage: 10,
+	}
+
+	sql = "SELECT * FROM app_configs"
+	actual = appendListOptionsToSQL(sql, opts)
+	expected = "SELECT * FROM app_configs LIMIT 10"
+	if actual != expected {
+		t.Error("Expected", expected, "Actual", actual)
+	}
+
+	sql = "SELECT * FROM app_configs"
+	opts.Page = 2
+	actual = appendListOptionsToSQL(sql, opts)
+	expected = "SELECT * FROM app_configs LIMIT 10 OFFSET 20"
+	if actual != expected {
+		t.Error("Expected", expected, "Actual", actual)
+	}
+
+	opts = kolide.ListOptions{}
+	sql = "SELECT * FROM app_configs"
+	actual = appendListOptionsToSQL(sql, opts)
+	expected = "SELECT * FROM app_configs LIMIT 1000"
+
+	if actual != expected {
+		t.Error("Expected", expected, "Actual", actual)
+	}
+
+}
+
+func TestSearchQuery(t *testing.T) {
+	var searchTests = []struct {
+		search   string
+		expected string
+	}{
+		{"", ""},
+		{"foo", "foo:*"},
+		{"99.100.101", "99 <-> 100 <-> 101:*"},
+		{"listening sockets", "listening:* & sockets:*"},
+		{"logged_in_users", "logged <-> in <-> users:*"},
+		{"foo's !bar | ...", "foo <-> s:* & bar:*"},
+	}
+	for _, tt := range searchTests {
+		if actual := searchQuery(tt.search); actual != tt.expected {
+			t.Errorf("searchQuery(%q): expected %q, actual %q", tt.search, tt.expected, actual)
+		}
+	}
+}
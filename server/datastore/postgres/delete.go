@@ -0,0 +1,9 @@
+package postgres
+
+import (
+	"github.com/kolide/kolide-ose/server/datastore/internal/sqlcommon"
+)
+
+func (d *Datastore) deleteEntity(dbTable string, id uint) error {
+	return sqlcommon.DeleteEntity(d.db, dbTable, id, d.clock.Now())
+}
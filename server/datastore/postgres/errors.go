@@ -0,0 +1,25 @@
+package postgres
+
+import (
+	"github.com/kolide/kolide-ose/server/datastore/internal/sqlcommon"
+	"github.com/lib/pq"
+)
+
+func notFound(kind string) *sqlcommon.NotFoundError {
+	return sqlcommon.NotFound(kind)
+}
+
+func alreadyExists(kind string) *sqlcommon.ExistsError {
+	return sqlcommon.AlreadyExists(kind)
+}
+
+// uniqueViolation is the PostgreSQL error code of a unique or primary key
+// constraint violation.
+const uniqueViolation = "23505"
+
+func isDuplicate(err error) bool {
+	if driverErr, ok := err.(*pq.Error); ok {
+		return driverErr.Code == uniqueViolation
+	}
+	return false
+}
@@ -0,0 +1,16 @@
+package postgres
+
+import (
+	"time"
+
+	"github.com/kolide/kolide-ose/server/datastore/internal/sqlcommon"
+	"github.com/kolide/kolide-ose/server/kolide"
+)
+
+func (d *Datastore) ListPurgeableHosts(seenBefore time.Time, exemptLabelIDs []uint) ([]*kolide.Host, error) {
+	return sqlcommon.ListPurgeableHosts(d.db, seenBefore, exemptLabelIDs)
+}
+
+func (d *Datastore) PurgeHosts(hostIDs []uint, seenBefore time.Time) (uint, error) {
+	return sqlcommon.PurgeHosts(d.db, hostIDs, seenBefore)
+}
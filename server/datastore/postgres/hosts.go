@@ -0,0 +1,275 @@
+package postgres
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/kolide/kolide-ose/server/datastore/internal/sqlcommon"
+	"github.com/kolide/kolide-ose/server/kolide"
+	"github.com/pkg/errors"
+)
+
+func (d *Datastore) NewHost(host *kolide.Host) (*kolide.Host, error) {
+	return sqlcommon.NewHost(d.db, dialect, host)
+}
+
+// upsertNetworkInterface inserts the network interface of a host, or
+// updates the interface of the host with the same name and IP address.
+func upsertNetworkInterface(tx *sqlx.Tx, nic *kolide.NetworkInterface) error {
+	sqlStatement := `
+	 	INSERT INTO network_interfaces (
+			host_id,
+			mac,
+			ip_address,
+			broadcast,
+			ibytes,
+			interface,
+			ipackets,
+			last_change,
+			mask,
+			metric,
+			mtu,
+			obytes,
+			ierrors,
+			oerrors,
+			opackets,
+			point_to_point,
+			type
+		) VALUES (?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?)
+		ON CONFLICT (ip_address, host_id, interface) DO UPDATE SET
+			mac = excluded.mac,
+			broadcast = excluded.broadcast,
+			ibytes = excluded.ibytes,
+			ipackets = excluded.ipackets,
+			last_change = excluded.last_change,
+			mask = excluded.mask,
+			metric = excluded.metric,
+			mtu = excluded.mtu,
+			obytes = excluded.obytes,
+			ierrors = excluded.ierrors,
+			oerrors = excluded.oerrors,
+			opackets = excluded.opackets,
+			point_to_point = excluded.point_to_point,
+			type = excluded.type
+		RETURNING id
+	 `
+	return tx.Get(&nic.ID, tx.Rebind(sqlStatement),
+		nic.HostID,
+		nic.MAC,
+		nic.IPAddress,
+		nic.Broadcast,
+		nic.IBytes,
+		nic.Interface,
+		nic.IPackets,
+		nic.LastChange,
+		nic.Mask,
+		nic.Metric,
+		nic.MTU,
+		nic.OBytes,
+		nic.IErrors,
+		nic.OErrors,
+		nic.OPackets,
+		nic.PointToPoint,
+		nic.Type,
+	)
+}
+
+func (d *Datastore) SaveHost(host *kolide.Host) error {
+	return sqlcommon.SaveHost(d.db, dialect, host)
+}
+
+func (d *Datastore) DeleteHost(hid uint) error {
+	return d.deleteEntity("hosts", hid)
+}
+
+func (d *Datastore) Host(id uint) (*kolide.Host, error) {
+	return sqlcommon.Host(d.db, id)
+}
+
+func (d *Datastore) ListHosts(opt kolide.ListOptions, filter kolide.HostFilter) ([]*kolide.Host, error) {
+	return sqlcommon.ListHosts(d.db, dialect, opt, filter)
+}
+
+func (d *Datastore) CountHosts(filter kolide.HostFilter) (uint, error) {
+	return sqlcommon.CountHosts(d.db, dialect, filter)
+}
+
+// hostOfflineSQL returns the condition on the hosts table, aliased h, that
+// selects hosts that have gone without communication for longer than their
+// offline threshold at now, along with its arguments. It mirrors
+// kolide.HostStatusThresholds.OfflineDuration.
+func hostOfflineSQL(now time.Time, thresholds kolide.HostStatusThresholds) (string, []interface{}) {
+	offline := thresholds.Offline.Seconds()
+	elapsed := "EXTRACT(EPOCH FROM CAST(? AS TIMESTAMPTZ) - h.seen_time)"
+	if thresholds.IntervalGrace <= 0 {
+		return "(" + elapsed + " > CAST(? AS DOUBLE PRECISION))", []interface{}{now, offline}
+	}
+	interval := `
+		CASE WHEN h.distributed_interval > 0
+			AND (h.config_refresh = 0 OR h.distributed_interval < h.config_refresh)
+			THEN h.distributed_interval
+			ELSE h.config_refresh
+		END
+	`
+	condition := fmt.Sprintf(
+		"(%s > CASE WHEN %s > 0 THEN %s * CAST(? AS DOUBLE PRECISION) ELSE CAST(? AS DOUBLE PRECISION) END)",
+		elapsed, interval, interval,
+	)
+	return condition, []interface{}{now, thresholds.IntervalGrace, offline}
+}
+
+func (d *Datastore) GenerateHostStatusStatistics(now time.Time, thresholds kolide.HostStatusThresholds) (online, offline, mia uint, e error) {
+	return sqlcommon.GenerateHostStatusStatistics(d.db, dialect, now, thresholds)
+}
+
+// EnrollHost enrolls a host
+func (d *Datastore) EnrollHost(osqueryHostID string, nodeKeySize int) (*kolide.Host, error) {
+	if osqueryHostID == "" {
+		return nil, fmt.Errorf("missing osquery host identifier")
+	}
+
+	detailUpdateTime := time.Unix(0, 0).Add(24 * time.Hour)
+	nodeKey, err := kolide.RandomText(nodeKeySize)
+	if err != nil {
+		return nil, errors.Wrap(err, "generating random text")
+	}
+
+	sqlInsert := `
+		INSERT INTO hosts (
+			detail_update_time,
+			osquery_host_id,
+			seen_time,
+			node_key
+		) VALUES (?, ?, ?, ?)
+		ON CONFLICT (osquery_host_id) DO UPDATE SET
+			node_key = excluded.node_key,
+			deleted = FALSE
+		RETURNING id
+	`
+
+	var id uint
+	err = d.db.Get(&id, d.db.Rebind(sqlInsert), detailUpdateTime, osqueryHostID, time.Now().UTC(), nodeKey)
+	if err != nil {
+		return nil, errors.Wrap(err, "inserting")
+	}
+
+	sqlSelect := `
+		SELECT * FROM hosts WHERE id = ? LIMIT 1
+	`
+	host := &kolide.Host{}
+	err = d.db.Get(host, d.db.Rebind(sqlSelect), id)
+	if err != nil {
+		return nil, errors.Wrap(err, "getting the host to return")
+	}
+
+	return host, nil
+
+}
+
+func (d *Datastore) AuthenticateHost(nodeKey string) (*kolide.Host, error) {
+	return sqlcommon.AuthenticateHost(d.db, nodeKey)
+}
+
+func (d *Datastore) MarkHostSeen(host *kolide.Host, t time.Time) error {
+	return sqlcommon.MarkHostSeen(d.db, host, t)
+}
+
+func (d *Datastore) MarkHostsSeen(hostIDs []uint, t time.Time) error {
+	return sqlcommon.MarkHostsSeen(d.db, hostIDs, t)
+}
+
+func (d *Datastore) MarkHostsConfigFetched(hostIDs []uint, hash string, t time.Time) error {
+	return sqlcommon.MarkHostsConfigFetched(d.db, hostIDs, hash, t)
+}
+
+func (d *Datastore) searchHostsWithOmits(query string, omit ...uint) ([]*kolide.Host, error) {
+	search := searchQuery(query)
+
+	sqlStatement :=
+		`
+		SELECT DISTINCT *
+		FROM hosts
+		WHERE
+		(
+			` + searchDocument("host_name") + ` @@ to_tsquery('simple', ?)
+		OR
+			id IN (
+				SELECT host_id
+				FROM network_interfaces
+				WHERE
+				` + searchDocument("ip_address") + ` @@ to_tsquery('simple', ?)
+			)
+		)
+		AND NOT deleted
+		AND id NOT IN (?)
+		LIMIT 10
+	`
+
+	sql, args, err := sqlx.In(sqlStatement, search, search, omit)
+	if err != nil {
+		return nil, errors.Wrap(err, "searching hosts")
+	}
+	sql = d.db.Rebind(sql)
+
+	hosts := []*kolide.Host{}
+
+	err = d.db.Select(&hosts, sql, args...)
+	if err != nil {
+		return nil, errors.Wrap(err, "searching hosts rebound")
+	}
+
+	if err := sqlcommon.LoadNetInterfacesForHosts(d.db, hosts); err != nil {
+		return nil, errors.Wrap(err, "getting network interfaces for hosts")
+	}
+
+	return hosts, nil
+}
+
+// SearchHosts find hosts by query containing an IP address or a host name. Optionally
+// pass a list of IDs to omit from the search
+func (d *Datastore) SearchHosts(query string, omit ...uint) ([]*kolide.Host, error) {
+	if query == "" {
+		return sqlcommon.SearchHostsDefault(d.db, omit...)
+	}
+	if len(omit) > 0 {
+		return d.searchHostsWithOmits(query, omit...)
+	}
+
+	search := searchQuery(query)
+
+	sqlStatement :=
+		`
+		SELECT DISTINCT *
+		FROM hosts
+		WHERE
+		(
+			` + searchDocument("host_name") + ` @@ to_tsquery('simple', ?)
+		OR
+			id IN (
+				SELECT host_id
+				FROM network_interfaces
+				WHERE
+				` + searchDocument("ip_address") + ` @@ to_tsquery('simple', ?)
+			)
+		)
+		AND NOT deleted
+		LIMIT 10
+	`
+	hosts := []*kolide.Host{}
+
+	if err := d.db.Select(&hosts, d.db.Rebind(sqlStatement), search, search); err != nil {
+		return nil, errors.Wrap(err, "searching hosts")
+	}
+
+	if err := sqlcommon.LoadNetInterfacesForHosts(d.db, hosts); err != nil {
+		return nil, errors.Wrap(err, "getting interfaces")
+	}
+
+	return hosts, nil
+
+}
+
+func (d *Datastore) DistributedQueriesForHost(host *kolide.Host) (map[uint]string, error) {
+	return sqlcommon.DistributedQueriesForHost(d.db, host)
+}
@@ -0,0 +1,145 @@
+package postgres
+
+import (
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/kolide/kolide-ose/server/datastore/internal/sqlcommon"
+	"github.com/kolide/kolide-ose/server/kolide"
+	"github.com/pkg/errors"
+)
+
+func (d *Datastore) NewLabel(label *kolide.Label) (*kolide.Label, error) {
+	return sqlcommon.NewLabel(d.db, dialect, label)
+}
+
+// DeleteLabel soft deletes a kolide.Label
+func (d *Datastore) DeleteLabel(lid uint) error {
+	return d.deleteEntity("labels", lid)
+}
+
+func (d *Datastore) Label(lid uint) (*kolide.Label, error) {
+	return sqlcommon.Label(d.db, lid)
+}
+
+func (d *Datastore) ListLabels(opt kolide.ListOptions) ([]*kolide.Label, error) {
+	return sqlcommon.ListLabels(d.db, opt)
+}
+
+func (d *Datastore) LabelQueriesForHost(host *kolide.Host, cutoff time.Time) (map[string]string, error) {
+	return sqlcommon.LabelQueriesForHost(d.db, host, cutoff)
+}
+
+func (d *Datastore) RecordLabelQueryExecutions(host *kolide.Host, results map[uint]bool, updated time.Time) error {
+	sqlStatement := `
+	INSERT INTO label_query_executions (updated_at, matches, label_id, host_id) VALUES
+
+	`
+	vals := []interface{}{}
+	bindvars := ""
+
+	for labelID, result := range results {
+		if bindvars != "" {
+			bindvars += ","
+		}
+		bindvars += "(?,?,?,?)"
+		vals = append(vals, updated, result, labelID, host.ID)
+	}
+
+	sqlStatement += bindvars
+	sqlStatement += `
+		ON CONFLICT (label_id, host_id) DO UPDATE SET
+		updated_at = excluded.updated_at,
+		matches = excluded.matches
+	`
+
+	_, err := d.db.Exec(d.db.Rebind(sqlStatement), vals...)
+	if err != nil {
+		return errors.Wrap(err, "inserting label query execution")
+	}
+
+	return nil
+}
+
+func (d *Datastore) ListLabelsForHost(hid uint) ([]kolide.Label, error) {
+	return sqlcommon.ListLabelsForHost(d.db, hid)
+}
+
+func (d *Datastore) ListHostsInLabel(lid uint) ([]kolide.Host, error) {
+	return sqlcommon.ListHostsInLabel(d.db, lid)
+}
+
+func (d *Datastore) ListUniqueHostsInLabels(labels []uint) ([]kolide.Host, error) {
+	return sqlcommon.ListUniqueHostsInLabels(d.db, labels)
+}
+
+func (d *Datastore) searchLabelsWithOmits(query string, omit ...uint) ([]kolide.Label, error) {
+	query = searchQuery(query)
+	sqlStatement := `
+		SELECT *
+		FROM labels
+		WHERE (
+			(
+				` + searchDocument("name") + ` @@ to_tsquery('simple', ?)
+				AND NOT deleted
+			)
+			OR (
+				label_type=?
+				AND name = 'All Hosts'
+			)
+		)
+		AND id NOT IN (?)
+		ORDER BY id ASC
+		LIMIT 10
+	`
+
+	sql, args, err := sqlx.In(sqlStatement, query, kolide.LabelTypeBuiltIn, omit)
+	if err != nil {
+		return nil, errors.Wrap(err, "building query for labels with omits")
+	}
+
+	sql = d.db.Rebind(sql)
+
+	matches := []kolide.Label{}
+	err = d.db.Select(&matches, sql, args...)
+	if err != nil {
+		return nil, errors.Wrap(err, "selecting labels with omits")
+	}
+
+	return matches, nil
+}
+
+// SearchLabels performs full text searches on kolide.Label name
+func (d *Datastore) SearchLabels(query string, omit ...uint) ([]kolide.Label, error) {
+	if query == "" {
+		return sqlcommon.SearchLabelsDefault(d.db, omit...)
+	}
+	if len(omit) > 0 {
+		return d.searchLabelsWithOmits(query, omit...)
+	}
+
+	query = searchQuery(query)
+
+	sqlStatement := `
+		SELECT *
+		FROM labels
+		WHERE (
+			(
+				` + searchDocument("name") + ` @@ to_tsquery('simple', ?)
+				AND NOT deleted
+			)
+			OR (
+				label_type=?
+				AND name = 'All Hosts'
+			)
+		)
+		ORDER BY id ASC
+		LIMIT 10
+	`
+	matches := []kolide.Label{}
+	err := d.db.Select(&matches, d.db.Rebind(sqlStatement), query, kolide.LabelTypeBuiltIn)
+	if err != nil {
+		return nil, errors.Wrap(err, "selecting labels for search")
+	}
+	return matches, nil
+}
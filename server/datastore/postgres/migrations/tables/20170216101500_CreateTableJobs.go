@@ -0,0 +1,29 @@
+package tables
+
+import (
+	"database/sql"
+)
+
+func init() {
+	MigrationClient.AddMigration(Up_20170216101500, Down_20170216101500)
+}
+
+func Up_20170216101500(tx *sql.Tx) error {
+	_, err := tx.Exec("CREATE TABLE jobs (" +
+		"name VARCHAR(255) NOT NULL PRIMARY KEY," +
+		"run_interval BIGINT NOT NULL DEFAULT 0," +
+		"lock_owner VARCHAR(255) NOT NULL DEFAULT ''," +
+		"locked_until TIMESTAMPTZ NOT NULL DEFAULT '1970-01-02 00:00:00+00'," +
+		"last_instance VARCHAR(255) NOT NULL DEFAULT ''," +
+		"last_started_at TIMESTAMPTZ NOT NULL DEFAULT '1970-01-02 00:00:00+00'," +
+		"last_finished_at TIMESTAMPTZ NOT NULL DEFAULT '1970-01-02 00:00:00+00'," +
+		"last_error TEXT NOT NULL DEFAULT ''" +
+		")",
+	)
+	return err
+}
+
+func Down_20170216101500(tx *sql.Tx) error {
+	_, err := tx.Exec("DROP TABLE IF EXISTS jobs")
+	return err
+}
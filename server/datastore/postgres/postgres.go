@@ -0,0 +1,173 @@
+package postgres
+
+import (
+	"fmt"
+	"net"
+	"net/url"
+	"time"
+
+	"github.com/WatchBeam/clock"
+	"github.com/go-kit/kit/log"
+	"github.com/jmoiron/sqlx"
+	"github.com/kolide/kolide-ose/server/config"
+	"github.com/kolide/kolide-ose/server/datastore/internal/sqlcommon"
+	"github.com/kolide/kolide-ose/server/datastore/postgres/migrations/data"
+	"github.com/kolide/kolide-ose/server/datastore/postgres/migrations/tables"
+	"github.com/kolide/kolide-ose/server/kolide"
+	_ "github.com/lib/pq"
+)
+
+const (
+	defaultSelectLimit = 1000
+)
+
+// dialect is what the shared queries need from PostgreSQL, which returns
+// the IDs of inserted rows with RETURNING.
+var dialect = sqlcommon.Dialect{
+	ReturningID:            true,
+	Like:                   `ILIKE ? ESCAPE '\'`,
+	InsertIgnore:           "INSERT INTO %s ON CONFLICT DO NOTHING",
+	IsDuplicate:            isDuplicate,
+	HostOfflineSQL:         hostOfflineSQL,
+	HostAttributeFilterSQL: hostAttributeFilterSQL,
+	QuerySearchSQL:         querySearchSQL,
+	UpsertNetworkInterface: upsertNetworkInterface,
+}
+
+// Datastore is an implementation of kolide.Datastore interface backed by
+// PostgreSQL
+type Datastore struct {
+	db     *sqlx.DB
+	logger log.Logger
+	clock  clock.Clock
+	config config.PostgresConfig
+}
+
+// New creates a PostgreSQL datastore.
+func New(config config.PostgresConfig, c clock.Clock, opts ...DBOption) (*Datastore, error) {
+	options := &dbOptions{
+		maxAttempts: defaultMaxAttempts,
+		logger:      log.NewNopLogger(),
+	}
+
+	for _, setOpt := range opts {
+		setOpt(options)
+	}
+
+	db, err := sqlx.Open("postgres", generatePostgresConnectionString(config))
+	if err != nil {
+		return nil, err
+	}
+
+	var dbError error
+	for attempt := 0; attempt < options.maxAttempts; attempt++ {
+		dbError = db.Ping()
+		if dbError == nil {
+			// we're connected!
+			break
+		}
+		interval := time.Duration(attempt) * time.Second
+		options.logger.Log("postgres", fmt.Sprintf(
+			"could not connect to db: %v, sleeping %v", dbError, interval))
+		time.Sleep(interval)
+	}
+
+	if dbError != nil {
+		return nil, dbError
+	}
+
+	ds := &Datastore{
+		db:     db,
+		logger: options.logger,
+		clock:  c,
+		config: config,
+	}
+
+	return ds, nil
+}
+
+func (d *Datastore) Name() string {
+	return "postgres"
+}
+
+func (d *Datastore) MigrateTables() error {
+	if err := tables.MigrationClient.Up(d.db.DB, ""); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (d *Datastore) MigrateData() error {
+	if err := data.MigrationClient.Up(d.db.DB, ""); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// Drop removes all tables
+func (d *Datastore) Drop() error {
+	tables := []string{}
+	sql := `
+		SELECT tablename FROM pg_tables
+		WHERE schemaname = current_schema()
+	`
+	if err := d.db.Select(&tables, d.db.Rebind(sql)); err != nil {
+		return err
+	}
+
+	tx, err := d.db.Begin()
+	if err != nil {
+		return err
+	}
+	for _, table := range tables {
+		_, err = tx.Exec(fmt.Sprintf(`DROP TABLE IF EXISTS "%s" CASCADE`, table))
+		if err != nil {
+			tx.Rollback()
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+// HealthCheck returns an error if the PostgreSQL backend is not healthy.
+func (d *Datastore) HealthCheck() error {
+	_, err := d.db.Exec("select 1")
+	return err
+}
+
+// Close frees resources associated with the underlying database
+func (d *Datastore) Close() error {
+	return d.db.Close()
+}
+
+func (d *Datastore) log(msg string) {
+	d.logger.Log("comp", d.Name(), "msg", msg)
+}
+
+func appendListOptionsToSQL(sql string, opts kolide.ListOptions) string {
+	return sqlcommon.AppendListOptionsToSQL(sql, opts)
+}
+
+// generatePostgresConnectionString returns a PostgreSQL connection URL
+// using the provided configuration. The session time zone is UTC, so that
+// times are read back as they are stored.
+func generatePostgresConnectionString(conf config.PostgresConfig) string {
+	address := conf.Address
+	if _, _, err := net.SplitHostPort(address); err != nil {
+		address = net.JoinHostPort(address, "5432")
+	}
+	sslMode := conf.SSLMode
+	if sslMode == "" {
+		sslMode = "disable"
+	}
+	u := url.URL{
+		Scheme:   "postgres",
+		User:     url.UserPassword(conf.Username, conf.Password),
+		Host:     address,
+		Path:     "/" + conf.Database,
+		RawQuery: url.Values{"sslmode": {sslMode}, "timezone": {"UTC"}}.Encode(),
+	}
+	return u.String()
+}
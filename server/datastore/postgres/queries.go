@@ -0,0 +1,49 @@
+package postgres
+
+import (
+	"github.com/kolide/kolide-ose/server/datastore/internal/sqlcommon"
+	"github.com/kolide/kolide-ose/server/kolide"
+)
+
+func (d *Datastore) QueryByName(name string) (*kolide.Query, bool, error) {
+	return sqlcommon.QueryByName(d.db, name)
+}
+
+func (d *Datastore) NewQuery(query *kolide.Query) (*kolide.Query, error) {
+	return sqlcommon.NewQuery(d.db, dialect, query)
+}
+
+func (d *Datastore) SaveQuery(q *kolide.Query) error {
+	return sqlcommon.SaveQuery(d.db, dialect, q)
+}
+
+// DeleteQuery soft deletes Query identified by Query.ID
+func (d *Datastore) DeleteQuery(qid uint) error {
+	return d.deleteEntity("queries", qid)
+}
+
+func (d *Datastore) DeleteQueries(ids []uint) (uint, error) {
+	return sqlcommon.DeleteQueries(d.db, ids, d.clock.Now())
+}
+
+func (d *Datastore) Query(id uint) (*kolide.Query, error) {
+	return sqlcommon.Query(d.db, id)
+}
+
+func (d *Datastore) ListQueries(opt kolide.ListOptions, filter kolide.QueryFilter) ([]*kolide.Query, error) {
+	return sqlcommon.ListQueries(d.db, dialect, opt, filter)
+}
+
+// querySearchSQL returns the full text search condition on the queries
+// table, aliased q, that requires every word of the search.
+func querySearchSQL(search string) (string, []interface{}) {
+	query := searchQuery(search)
+	if query == "" {
+		return "", nil
+	}
+	return searchDocument(querySearchColumns) + " @@ to_tsquery('simple', ?)", []interface{}{query}
+}
+
+// querySearchColumns are the columns of the queries table, aliased q, that
+// searches match.
+const querySearchColumns = "q.name || ' ' || COALESCE(q.description, '') || ' ' || q.query"
@@ -0,0 +1,119 @@
+package postgres
+
+import (
+	"github.com/kolide/kolide-ose/server/kolide"
+	"github.com/pkg/errors"
+)
+
+// SaveScheduledQueryStats replaces the performance stats stored for a host
+// with the provided stats.
+func (d *Datastore) SaveScheduledQueryStats(hostID uint, stats []kolide.ScheduledQueryStats) (err error) {
+	sqlStatement := `
+		INSERT INTO scheduled_query_stats (
+			host_id,
+			scheduled_query_id,
+			executions,
+			last_executed,
+			wall_time,
+			user_time,
+			system_time,
+			average_memory,
+			output_size,
+			denylisted
+		) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+		ON CONFLICT (host_id, scheduled_query_id) DO UPDATE SET
+			executions = excluded.executions,
+			last_executed = excluded.last_executed,
+			wall_time = excluded.wall_time,
+			user_time = excluded.user_time,
+			system_time = excluded.system_time,
+			average_memory = excluded.average_memory,
+			output_size = excluded.output_size,
+			denylisted = excluded.denylisted
+	`
+	txn, err := d.db.Begin()
+	if err != nil {
+		return errors.Wrap(err, "save scheduled query stats begin transaction")
+	}
+	var success bool
+	defer func() {
+		if success {
+			if err = txn.Commit(); err == nil {
+				return
+			}
+		}
+		txn.Rollback()
+	}()
+
+	_, err = txn.Exec(d.db.Rebind("DELETE FROM scheduled_query_stats WHERE host_id = ?"), hostID)
+	if err != nil {
+		return errors.Wrap(err, "deleting scheduled query stats")
+	}
+	for _, s := range stats {
+		_, err := txn.Exec(d.db.Rebind(sqlStatement),
+			hostID,
+			s.ScheduledQueryID,
+			s.Executions,
+			s.LastExecuted,
+			s.WallTime,
+			s.UserTime,
+			s.SystemTime,
+			s.AverageMemory,
+			s.OutputSize,
+			s.Denylisted,
+		)
+		if err != nil {
+			return errors.Wrap(err, "inserting scheduled query stats")
+		}
+	}
+
+	success = true
+	return err
+}
+
+// ScheduledQueryStatsInPack returns the performance stats of each scheduled
+// query in a pack, summed over the (not deleted) hosts that reported them.
+func (d *Datastore) ScheduledQueryStatsInPack(packID uint) ([]*kolide.ScheduledQueryStatsRollup, error) {
+	sqlStatement := `
+		SELECT
+			sq.id AS scheduled_query_id,
+			sq.pack_id,
+			sq.query_id,
+			q.name,
+			sq.interval,
+			COUNT(s.host_id) AS host_count,
+			COALESCE(SUM(CASE WHEN s.denylisted THEN 1 ELSE 0 END), 0) AS denylisted_count,
+			COALESCE(SUM(s.executions), 0) AS executions,
+			COALESCE(SUM(s.wall_time), 0) AS wall_time,
+			COALESCE(SUM(s.user_time), 0) AS user_time,
+			COALESCE(SUM(s.system_time), 0) AS system_time,
+			COALESCE(SUM(s.output_size), 0) AS output_size,
+			COALESCE(CAST(ROUND(AVG(s.average_memory)) AS INTEGER), 0) AS average_memory,
+			COALESCE(MAX(s.average_memory), 0) AS max_memory
+		FROM scheduled_queries sq
+		JOIN queries q
+			ON sq.query_id = q.id
+		LEFT JOIN (
+			SELECT st.*
+			FROM scheduled_query_stats st
+			JOIN hosts h
+				ON st.host_id = h.id
+			WHERE NOT h.deleted
+		) s
+			ON s.scheduled_query_id = sq.id
+		WHERE sq.pack_id = ?
+		AND NOT sq.deleted
+		GROUP BY sq.id, sq.pack_id, sq.query_id, q.name, sq.interval
+		ORDER BY sq.id
+	`
+	results := []*kolide.ScheduledQueryStatsRollup{}
+	if err := d.db.Select(&results, d.db.Rebind(sqlStatement), packID); err != nil {
+		return nil, errors.Wrap(err, "selecting scheduled query stats in pack")
+	}
+
+	for _, r := range results {
+		r.ComputeAverages()
+	}
+
+	return results, nil
+}
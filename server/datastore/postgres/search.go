@@ -0,0 +1,33 @@
+package postgres
+
+import (
+	"strings"
+	"unicode"
+)
+
+// searchDocument returns the text search document of the columns, with
+// everything other than letters and digits replaced by spaces so that host
+// names, IP addresses and SQL are split into their words. The text search
+// indexes are built on the same expression, which must be kept in sync for
+// them to be used.
+func searchDocument(columns string) string {
+	return "to_tsvector('simple', regexp_replace(" + columns + ", '[^[:alnum:]]+', ' ', 'g'))"
+}
+
+// searchQuery returns the text search query, for use with
+// to_tsquery('simple', ?), that matches documents containing every word of
+// the search. The parts of a word, such as the octets of an IP address, must
+// appear in order, and the last part matches as a prefix.
+func searchQuery(search string) string {
+	terms := []string{}
+	for _, word := range strings.Fields(search) {
+		parts := strings.FieldsFunc(word, func(r rune) bool {
+			return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+		})
+		if len(parts) == 0 {
+			continue
+		}
+		terms = append(terms, strings.Join(parts, " <-> ")+":*")
+	}
+	return strings.Join(terms, " & ")
+}
@@ -0,0 +1,209 @@
+package postgres
+
+import (
+	"database/sql"
+	"strings"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/kolide/kolide-ose/server/datastore/internal/sqlcommon"
+	"github.com/kolide/kolide-ose/server/kolide"
+	"github.com/pkg/errors"
+)
+
+// softwareBatchSize limits the number of rows written by each statement when
+// saving the software of a host.
+const softwareBatchSize = 500
+
+// softwareKey identifies software within a source.
+type softwareKey struct {
+	name, version string
+}
+
+func (d *Datastore) SaveHostSoftware(hostID uint, source string, software []kolide.Software, seen time.Time) (err error) {
+	// Timestamps are stored to the second, and software that was not seen
+	// at exactly this time is removed
+	seen = seen.Truncate(time.Second)
+
+	unique := []softwareKey{}
+	seenKeys := map[softwareKey]bool{}
+	for _, s := range software {
+		key := softwareKey{s.Name, s.Version}
+		if !seenKeys[key] {
+			seenKeys[key] = true
+			unique = append(unique, key)
+		}
+	}
+
+	txn, err := d.db.Beginx()
+	if err != nil {
+		return errors.Wrap(err, "save host software begin transaction")
+	}
+	var success bool
+	defer func() {
+		if success {
+			if err = txn.Commit(); err == nil {
+				return
+			}
+		}
+		txn.Rollback()
+	}()
+
+	ids := map[softwareKey]uint{}
+	for start := 0; start < len(unique); start += softwareBatchSize {
+		end := start + softwareBatchSize
+		if end > len(unique) {
+			end = len(unique)
+		}
+		batch := unique[start:end]
+
+		// Create the software that no host has reported before
+		args := []interface{}{}
+		names := []string{}
+		for _, key := range batch {
+			args = append(args, key.name, key.version, source)
+			names = append(names, key.name)
+		}
+		values := strings.TrimSuffix(strings.Repeat("(?, ?, ?),", len(batch)), ",")
+		_, err := txn.Exec(d.db.Rebind("INSERT INTO software (name, version, source) VALUES "+values+" ON CONFLICT DO NOTHING"), args...)
+		if err != nil {
+			return errors.Wrap(err, "inserting software")
+		}
+
+		query, args, err := sqlx.In(
+			"SELECT id, name, version FROM software WHERE source = ? AND name IN (?)",
+			source, names,
+		)
+		if err != nil {
+			return errors.Wrap(err, "building software lookup")
+		}
+		rows := []kolide.Software{}
+		if err := txn.Select(&rows, txn.Rebind(query), args...); err != nil {
+			return errors.Wrap(err, "selecting software")
+		}
+		for _, row := range rows {
+			ids[softwareKey{row.Name, row.Version}] = row.ID
+		}
+
+		args = []interface{}{}
+		for _, key := range batch {
+			args = append(args, hostID, ids[key], seen, seen)
+		}
+		values = strings.TrimSuffix(strings.Repeat("(?, ?, ?, ?),", len(batch)), ",")
+		_, err = txn.Exec(d.db.Rebind(`
+			INSERT INTO host_software (host_id, software_id, first_seen, last_seen)
+			VALUES `+values+`
+			ON CONFLICT (host_id, software_id) DO UPDATE SET last_seen = excluded.last_seen
+			`),
+			args...,
+		)
+		if err != nil {
+			return errors.Wrap(err, "inserting host software")
+		}
+	}
+
+	for i := range software {
+		software[i].ID = ids[softwareKey{software[i].Name, software[i].Version}]
+	}
+
+	// Remove the software from the source that the host no longer has
+	_, err = txn.Exec(d.db.Rebind(`
+		DELETE FROM host_software
+		WHERE host_id = ?
+		AND software_id IN (SELECT id FROM software WHERE source = ?)
+		AND last_seen < ?
+		`),
+		hostID, source, seen,
+	)
+	if err != nil {
+		return errors.Wrap(err, "removing host software")
+	}
+
+	success = true
+	return err
+}
+
+func (d *Datastore) ListHostSoftware(hostID uint, opt kolide.ListOptions) ([]*kolide.HostSoftware, error) {
+	sqlStatement := `
+		SELECT s.*, hs.first_seen, hs.last_seen
+		FROM host_software hs
+		JOIN software s
+			ON hs.software_id = s.id
+		WHERE hs.host_id = ?
+	`
+	if opt.OrderKey == "" {
+		sqlStatement += " ORDER BY s.name, s.version"
+	}
+	sqlStatement = appendListOptionsToSQL(sqlStatement, opt)
+
+	software := []*kolide.HostSoftware{}
+	if err := d.db.Select(&software, d.db.Rebind(sqlStatement), hostID); err != nil {
+		return nil, errors.Wrap(err, "listing host software")
+	}
+	return software, nil
+}
+
+func (d *Datastore) SearchSoftware(filter kolide.SoftwareFilter, opt kolide.ListOptions) ([]*kolide.SoftwareCount, error) {
+	sqlStatement := `
+		SELECT s.*, COUNT(*) AS host_count
+		FROM software s
+		JOIN host_software hs
+			ON hs.software_id = s.id
+		JOIN hosts h
+			ON hs.host_id = h.id
+		WHERE NOT h.deleted
+		AND (? = '' OR s.name ILIKE ? ESCAPE '\')
+		AND (? = '' OR s.version = ?)
+		AND (? = '' OR s.source = ?)
+		GROUP BY s.id
+	`
+	if opt.OrderKey == "" {
+		sqlStatement += " ORDER BY s.name, s.version"
+	}
+	sqlStatement = appendListOptionsToSQL(sqlStatement, opt)
+
+	// ILIKE compares names ignoring case
+	name := "%" + sqlcommon.EscapeLike(filter.Name) + "%"
+	software := []*kolide.SoftwareCount{}
+	err := d.db.Select(&software, d.db.Rebind(sqlStatement),
+		filter.Name, name,
+		filter.Version, filter.Version,
+		filter.Source, filter.Source,
+	)
+	if err != nil {
+		return nil, errors.Wrap(err, "searching software")
+	}
+	return software, nil
+}
+
+func (d *Datastore) Software(id uint) (*kolide.Software, error) {
+	software := &kolide.Software{}
+	err := d.db.Get(software, d.db.Rebind("SELECT * FROM software WHERE id = ?"), id)
+	if err == sql.ErrNoRows {
+		return nil, notFound("Software").WithID(id)
+	} else if err != nil {
+		return nil, errors.Wrap(err, "selecting software")
+	}
+	return software, nil
+}
+
+func (d *Datastore) ListSoftwareInstalls(softwareID uint, opt kolide.ListOptions) ([]*kolide.SoftwareInstall, error) {
+	sqlStatement := `
+		SELECT hs.host_id, h.host_name, hs.first_seen, hs.last_seen
+		FROM host_software hs
+		JOIN hosts h
+			ON hs.host_id = h.id
+		WHERE hs.software_id = ?
+		AND NOT h.deleted
+	`
+	if opt.OrderKey == "" {
+		sqlStatement += " ORDER BY h.host_name, hs.host_id"
+	}
+	sqlStatement = appendListOptionsToSQL(sqlStatement, opt)
+
+	installs := []*kolide.SoftwareInstall{}
+	if err := d.db.Select(&installs, d.db.Rebind(sqlStatement), softwareID); err != nil {
+		return nil, errors.Wrap(err, "listing software installs")
+	}
+	return installs, nil
+}
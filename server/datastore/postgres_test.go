@@ -0,0 +1,56 @@
+package datastore
+
+import (
+	"os"
+	"testing"
+
+	"github.com/WatchBeam/clock"
+	"github.com/go-kit/kit/log"
+	"github.com/kolide/kolide-ose/server/config"
+	"github.com/kolide/kolide-ose/server/datastore/postgres"
+	"github.com/stretchr/testify/require"
+)
+
+func setupPostgres(t *testing.T) (ds *postgres.Datastore, teardown func()) {
+	config := config.PostgresConfig{
+		Username: "kolide",
+		Password: "kolide",
+		Database: "kolide",
+		Address:  "127.0.0.1:5432",
+		SSLMode:  "disable",
+	}
+
+	if h, ok := os.LookupEnv("POSTGRES_PORT_5432_TCP_ADDR"); ok {
+		config.Address = h + ":5432"
+	}
+
+	ds, err := postgres.New(config, clock.NewMockClock(), postgres.Logger(log.NewNopLogger()), postgres.LimitAttempts(1))
+	require.Nil(t, err)
+	teardown = func() {
+		ds.Close()
+	}
+
+	return ds, teardown
+}
+
+func TestPostgres(t *testing.T) {
+	if _, ok := os.LookupEnv("POSTGRES_TEST"); !ok {
+		t.SkipNow()
+	}
+
+	ds, teardown := setupPostgres(t)
+	defer teardown()
+	// get rid of database if it is hanging around
+	err := ds.Drop()
+	require.Nil(t, err)
+
+	for _, f := range testFunctions {
+
+		t.Run(functionName(f), func(t *testing.T) {
+			defer func() { require.Nil(t, ds.Drop()) }()
+			require.Nil(t, ds.MigrateTables())
+			f(t, ds)
+		})
+	}
+
+}
@@ -0,0 +1,18 @@
+package sqlite
+
+import "github.com/go-kit/kit/log"
+
+// DBOption is used to pass optional arguments to a database connection
+type DBOption func(o *dbOptions) error
+
+type dbOptions struct {
+	logger log.Logger
+}
+
+// Logger adds a logger to the datastore
+func Logger(l log.Logger) DBOption {
+	return func(o *dbOptions) error {
+		o.logger = l
+		return nil
+	}
+}
@@ -0,0 +1,163 @@
+package sqlite
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/WatchBeam/clock"
+	"github.com/kolide/kolide-ose/server/config"
+	"github.com/kolide/kolide-ose/server/kolide"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAppendListOptionsToSQL(t *testing.T) {
+	sql := "SELECT * FROM app_configs"
+	opts := kolide.ListOptions{
+		OrderKey: "name",
+	}
+
+	actual := appendListOptionsToSQL(sql, opts)
+	expected := "SELECT * FROM app_configs ORDER BY name ASC LIMIT 1000"
+	if actual != expected {
+		t.Error("Expected", expected, "Actual", actual)
+	}
+
+	sql = "SELECT * FROM app_configs"
+	opts.OrderDirection = kolide.OrderDescending
+	actual = appendListOptionsToSQL(sql, opts)
+	expected = "SELECT * FROM app_configs ORDER BY name DESC LIMIT 1000"
+	if actual != expected {
+		t.Error("Expected", expected, "Actual", actual)
+	}
+
+	opts = kolide.ListOptions{
+		PerPage: 10,
+	}
+
+	sql = "SELECT * FROM app_configs"
+	actual = appendListOptionsToSQL(sql, opts)
+	expected = "SELECT * FROM app_configs LIMIT 10"
+	if actual != expected {
+		t.Error("Expected", expected, "Actual", actual)
+	}
+
+	sql = "SELECT * FROM app_configs"
+	opts.Page = 2
+	actual = appendListOptionsToSQL(sql, opts)
+	expected = "SELECT * FROM app_configs LIMIT 10 OFFSET 20"
+	if actual != expected {
+		t.Error("Expected", expected, "Actual", actual)
+	}
+
+	opts = kolide.ListOptions{}
+	sql = "SELECT * FROM app_configs"
+	actual = appendListOptionsToSQL(sql, opts)
+	expected = "SELECT * FROM app_configs LIMIT 1000"
+
+	if actual != expected {
+		t.Error("Expected", expected, "Actual", actual)
+	}
+
+}
+
+func TestTimesStoredInUTC(t *testing.T) {
+	dir, err := ioutil.TempDir("", "kolide-sqlite")
+	require.Nil(t, err)
+	defer os.RemoveAll(dir)
+
+	ds, err := New(config.SqliteConfig{Path: filepath.Join(dir, "kolide.db")}, clock.NewMockClock())
+	require.Nil(t, err)
+	defer ds.Close()
+
+	_, err = ds.db.Exec("CREATE TABLE times (t TEXT)")
+	require.Nil(t, err)
+
+	zoned := time.Date(2017, time.February, 14, 9, 0, 0, 0, time.FixedZone("PST", -8*60*60))
+	_, err = ds.db.Exec("INSERT INTO times (t) VALUES (?)", zoned)
+	require.Nil(t, err)
+	stmt, err := ds.db.Prepare("INSERT INTO times (t) VALUES (?)")
+	require.Nil(t, err)
+	_, err = stmt.Exec(zoned)
+	require.Nil(t, err)
+	require.Nil(t, stmt.Close())
+
+	stored := []string{}
+	require.Nil(t, ds.db.Select(&stored, "SELECT CAST(t AS TEXT) FROM times"))
+	require.Len(t, stored, 2)
+	for _, s := range stored {
+		assert.True(t, strings.HasPrefix(s, "2017-02-14 17:00:00"), s)
+	}
+}
+
+func TestMigrations(t *testing.T) {
+	dir, err := ioutil.TempDir("", "kolide-sqlite")
+	require.Nil(t, err)
+	defer os.RemoveAll(dir)
+
+	ds, err := New(config.SqliteConfig{Path: filepath.Join(dir, "kolide.db")}, clock.NewMockClock())
+	require.Nil(t, err)
+	defer ds.Close()
+
+	status, err := ds.MigrationStatus()
+	require.Nil(t, err)
+	pending := status.Pending()
+	require.Len(t, pending, len(status.Tables)+len(status.Data))
+	assert.Equal(t, kolide.MigrationKindTables, pending[0].Kind)
+	assert.Equal(t, "CreateTables", pending[0].Name)
+
+	steps, err := ds.PlanMigrations()
+	require.Nil(t, err)
+	require.Len(t, steps, len(pending))
+	assert.True(t, steps[0].Up)
+	assert.NotEmpty(t, steps[0].Statements)
+
+	// Planning runs nothing against the database, not even creating the
+	// migration version tables
+	status, err = ds.MigrationStatus()
+	require.Nil(t, err)
+	assert.Len(t, status.Pending(), len(steps))
+	var tables int
+	require.Nil(t, ds.db.Get(&tables, "SELECT COUNT(*) FROM sqlite_master WHERE type = 'table'"))
+	assert.Equal(t, 0, tables)
+
+	require.Nil(t, ds.MigrateTables())
+	require.Nil(t, ds.MigrateData())
+	status, err = ds.MigrationStatus()
+	require.Nil(t, err)
+	assert.Empty(t, status.Pending())
+
+	// Rolling back to a table migration rolls back the data migrations,
+	// which are applied after every table migration, first
+	last := status.Tables[len(status.Tables)-1]
+	previous := status.Tables[len(status.Tables)-2]
+	steps, err = ds.PlanRollback(previous.Version)
+	require.Nil(t, err)
+	require.Len(t, steps, len(status.Data)+1)
+	for i, data := range status.Data {
+		assert.Equal(t, status.Data[len(status.Data)-1-i].Version, steps[i].Version)
+		assert.Equal(t, kolide.MigrationKindData, data.Kind)
+	}
+	assert.Equal(t, last.Version, steps[len(steps)-1].Version)
+	assert.False(t, steps[0].Up)
+
+	require.Nil(t, ds.RollbackMigrations(previous.Version))
+	status, err = ds.MigrationStatus()
+	require.Nil(t, err)
+	pending = status.Pending()
+	require.Len(t, pending, len(status.Data)+1)
+	assert.Equal(t, last.Version, pending[0].Version)
+
+	require.Nil(t, ds.MigrateTables())
+	require.Nil(t, ds.MigrateData())
+	status, err = ds.MigrationStatus()
+	require.Nil(t, err)
+	assert.Empty(t, status.Pending())
+
+	_, err = ds.PlanRollback(1)
+	assert.NotNil(t, err)
+}
@@ -0,0 +1,251 @@
+package sqlite
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/kolide/kolide-ose/server/kolide"
+	"github.com/pkg/errors"
+)
+
+func (d *Datastore) NewDetailQuery(query *kolide.DetailQuery) (*kolide.DetailQuery, error) {
+	sqlStatement := `
+		INSERT INTO detail_queries (
+			name,
+			query,
+			platform,
+			` + "`interval`" + `,
+			columns
+		) VALUES (?, ?, ?, ?, ?)
+	`
+	result, err := d.db.Exec(sqlStatement, query.Name, query.Query, query.Platform, query.Interval, query.Columns)
+	if err != nil && isDuplicate(err) {
+		return nil, alreadyExists("DetailQuery")
+	} else if err != nil {
+		return nil, errors.Wrap(err, "inserting detail query")
+	}
+
+	id, _ := result.LastInsertId()
+	query.ID = uint(id)
+	return query, nil
+}
+
+func (d *Datastore) SaveDetailQuery(query *kolide.DetailQuery) error {
+	sqlStatement := `
+		UPDATE detail_queries
+			SET name = ?, query = ?, platform = ?, ` + "`interval`" + ` = ?, columns = ?
+			WHERE id = ?
+	`
+	result, err := d.db.Exec(sqlStatement, query.Name, query.Query, query.Platform, query.Interval, query.Columns, query.ID)
+	if err != nil && isDuplicate(err) {
+		return alreadyExists("DetailQuery").WithID(query.ID)
+	} else if err != nil {
+		return errors.Wrap(err, "updating detail query")
+	}
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return errors.Wrap(err, "rows affected updating detail query")
+	}
+	if rows == 0 {
+		return notFound("DetailQuery").WithID(query.ID)
+	}
+	return nil
+}
+
+// DeleteDetailQuery removes a detail query along with the host attributes it
+// produced and the record of where it ran.
+func (d *Datastore) DeleteDetailQuery(id uint) (err error) {
+	txn, err := d.db.Begin()
+	if err != nil {
+		return errors.Wrap(err, "delete detail query begin transaction")
+	}
+	var success bool
+	defer func() {
+		if success {
+			if err = txn.Commit(); err == nil {
+				return
+			}
+		}
+		txn.Rollback()
+	}()
+
+	result, err := txn.Exec("DELETE FROM detail_queries WHERE id = ?", id)
+	if err != nil {
+		return errors.Wrap(err, "deleting detail query")
+	}
+	if rows, _ := result.RowsAffected(); rows != 1 {
+		return notFound("DetailQuery").WithID(id)
+	}
+	if _, err := txn.Exec("DELETE FROM host_attributes WHERE detail_query_id = ?", id); err != nil {
+		return errors.Wrap(err, "deleting host attributes of detail query")
+	}
+	if _, err := txn.Exec("DELETE FROM detail_query_executions WHERE detail_query_id = ?", id); err != nil {
+		return errors.Wrap(err, "deleting detail query executions")
+	}
+
+	success = true
+	return err
+}
+
+func (d *Datastore) DetailQuery(id uint) (*kolide.DetailQuery, error) {
+	query := &kolide.DetailQuery{}
+	err := d.db.Get(query, "SELECT * FROM detail_queries WHERE id = ?", id)
+	if err == sql.ErrNoRows {
+		return nil, notFound("DetailQuery").WithID(id)
+	} else if err != nil {
+		return nil, errors.Wrap(err, "selecting detail query")
+	}
+	return query, nil
+}
+
+func (d *Datastore) ListDetailQueries(opt kolide.ListOptions) ([]*kolide.DetailQuery, error) {
+	sqlStatement := `
+		SELECT * FROM detail_queries
+	`
+	sqlStatement = appendListOptionsToSQL(sqlStatement, opt)
+	queries := []*kolide.DetailQuery{}
+	if err := d.db.Select(&queries, sqlStatement); err != nil {
+		return nil, errors.Wrap(err, "listing detail queries")
+	}
+	return queries, nil
+}
+
+func (d *Datastore) DetailQueriesForHost(host *kolide.Host, now time.Time) (map[uint]string, error) {
+	sqlStatement := `
+		SELECT dq.id, dq.query
+		FROM detail_queries dq
+		LEFT JOIN detail_query_executions dqe
+			ON dqe.detail_query_id = dq.id AND dqe.host_id = ?
+		WHERE (dq.platform = '' OR instr(',' || dq.platform || ',', ',' || ? || ',') > 0)
+		AND (
+			dqe.updated_at IS NULL
+			OR julianday(dqe.updated_at) <= julianday(?) - dq.` + "`interval`" + ` / 86400.0
+		)
+	`
+	rows := []struct {
+		ID    uint
+		Query string
+	}{}
+	if err := d.db.Select(&rows, sqlStatement, host.ID, host.Platform, now); err != nil {
+		return nil, errors.Wrap(err, "selecting detail queries for host")
+	}
+
+	results := map[uint]string{}
+	for _, row := range rows {
+		results[row.ID] = row.Query
+	}
+	return results, nil
+}
+
+func (d *Datastore) RecordHostAttributes(hostID, detailQueryID uint, attributes []kolide.HostAttribute, updated time.Time) (err error) {
+	txn, err := d.db.Begin()
+	if err != nil {
+		return errors.Wrap(err, "record host attributes begin transaction")
+	}
+	var success bool
+	defer func() {
+		if success {
+			if err = txn.Commit(); err == nil {
+				return
+			}
+		}
+		txn.Rollback()
+	}()
+
+	_, err = txn.Exec(
+		"DELETE FROM host_attributes WHERE host_id = ? AND detail_query_id = ?",
+		hostID, detailQueryID,
+	)
+	if err != nil {
+		return errors.Wrap(err, "deleting host attributes")
+	}
+	insertStatement := `
+		INSERT INTO host_attributes (
+			host_id,
+			detail_query_id,
+			` + "`key`" + `,
+			type,
+			value,
+			updated_at
+		) VALUES (?, ?, ?, ?, ?, ?)
+		ON CONFLICT (host_id, ` + "`key`" + `) DO UPDATE SET
+			detail_query_id = excluded.detail_query_id,
+			type = excluded.type,
+			value = excluded.value,
+			updated_at = excluded.updated_at
+	`
+	for _, a := range attributes {
+		_, err := txn.Exec(insertStatement, hostID, detailQueryID, a.Key, a.Type, a.Value, updated)
+		if err != nil {
+			return errors.Wrap(err, "inserting host attribute")
+		}
+	}
+
+	_, err = txn.Exec(`
+		INSERT INTO detail_query_executions (host_id, detail_query_id, updated_at)
+		VALUES (?, ?, ?)
+		ON CONFLICT (host_id, detail_query_id) DO UPDATE SET updated_at = excluded.updated_at
+		`,
+		hostID, detailQueryID, updated,
+	)
+	if err != nil {
+		return errors.Wrap(err, "recording detail query execution")
+	}
+
+	success = true
+	return err
+}
+
+func (d *Datastore) HostAttributes(hostID uint) ([]kolide.HostAttribute, error) {
+	sqlStatement := `
+		SELECT * FROM host_attributes
+		WHERE host_id = ?
+		ORDER BY ` + "`key`" + `
+	`
+	attributes := []kolide.HostAttribute{}
+	if err := d.db.Select(&attributes, sqlStatement, hostID); err != nil {
+		return nil, errors.Wrap(err, "selecting host attributes")
+	}
+	return attributes, nil
+}
+
+// hostAttributeOperators maps the operators of host attribute filters to
+// the SQL that compares with them. Only the operators in this map are ever
+// written into a query.
+var hostAttributeOperators = map[string]string{
+	"=":  "=",
+	"!=": "!=",
+	"<":  "<",
+	"<=": "<=",
+	">":  ">",
+	">=": ">=",
+}
+
+// hostAttributeFilterSQL returns a condition on the hosts table, aliased h,
+// that selects hosts with an attribute matching the filter, along with its
+// arguments.
+func hostAttributeFilterSQL(filter kolide.HostAttributeFilter) (string, []interface{}, error) {
+	operator, ok := hostAttributeOperators[filter.Operator]
+	if !ok {
+		return "", nil, errors.Errorf("unknown host attribute operator %q", filter.Operator)
+	}
+
+	// A NULL comparison never matches, so numeric attributes do not match
+	// a value that is not a number
+	var numeric interface{}
+	if n, ok := filter.NumericValue(); ok {
+		numeric = n
+	}
+	condition := `
+		EXISTS (
+			SELECT 1 FROM host_attributes ha
+			WHERE ha.host_id = h.id
+			AND ha.` + "`key`" + ` = ?
+			AND CASE WHEN ha.type IN ('integer', 'float', 'boolean')
+				THEN CAST(ha.value AS REAL) ` + operator + ` ?
+				ELSE ha.value ` + operator + ` ?
+			END
+		)
+	`
+	return condition, []interface{}{filter.Key, numeric, filter.Value}, nil
+}
@@ -0,0 +1,24 @@
+package sqlite
+
+import (
+	"github.com/kolide/kolide-ose/server/datastore/internal/sqlcommon"
+	"github.com/mattn/go-sqlite3"
+)
+
+func notFound(kind string) *sqlcommon.NotFoundError {
+	return sqlcommon.NotFound(kind)
+}
+
+func alreadyExists(kind string) *sqlcommon.ExistsError {
+	return sqlcommon.AlreadyExists(kind)
+}
+
+func isDuplicate(err error) bool {
+	if driverErr, ok := err.(sqlite3.Error); ok {
+		if driverErr.ExtendedCode == sqlite3.ErrConstraintUnique ||
+			driverErr.ExtendedCode == sqlite3.ErrConstraintPrimaryKey {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,10 @@
+package sqlite
+
+import (
+	"github.com/kolide/kolide-ose/server/datastore/internal/sqlcommon"
+	"github.com/kolide/kolide-ose/server/kolide"
+)
+
+func (d *Datastore) HostBreakdown(labelID uint) (*kolide.HostBreakdown, error) {
+	return sqlcommon.HostBreakdown(d.db, labelID)
+}
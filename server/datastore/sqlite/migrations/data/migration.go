@@ -0,0 +1,7 @@
+package data
+
+import "github.com/kolide/goose"
+
+var (
+	MigrationClient = goose.New("migration_status_data", goose.Sqlite3Dialect{})
+)
@@ -0,0 +1,496 @@
+package tables
+
+import (
+	"database/sql"
+	"fmt"
+
+	"github.com/kolide/kolide-ose/server/kolide"
+)
+
+func init() {
+	MigrationClient.AddMigration(Up_20170214120000, Down_20170214120000)
+}
+
+// The SQLite schema starts out as the MySQL schema as of migration
+// 20170213101545. Later changes to the MySQL schema need a migration here as
+// well.
+var createTables = []string{
+	"CREATE TABLE `app_configs` (" +
+		"`id` INTEGER NOT NULL DEFAULT 1 PRIMARY KEY," +
+		"`org_name` VARCHAR(255) NOT NULL DEFAULT ''," +
+		"`org_logo_url` VARCHAR(255) NOT NULL DEFAULT ''," +
+		"`kolide_server_url` VARCHAR(255) NOT NULL DEFAULT ''," +
+		"`smtp_configured` BOOLEAN NOT NULL DEFAULT FALSE," +
+		"`smtp_sender_address` VARCHAR(255) NOT NULL DEFAULT ''," +
+		"`smtp_server` VARCHAR(255) NOT NULL DEFAULT ''," +
+		"`smtp_port` INTEGER NOT NULL DEFAULT 587," +
+		"`smtp_authentication_type` INTEGER NOT NULL DEFAULT 0," +
+		"`smtp_enable_ssl_tls` BOOLEAN NOT NULL DEFAULT TRUE," +
+		"`smtp_authentication_method` INTEGER NOT NULL DEFAULT 0," +
+		"`smtp_domain` VARCHAR(255) NOT NULL DEFAULT ''," +
+		"`smtp_user_name` VARCHAR(255) NOT NULL DEFAULT ''," +
+		"`smtp_password` VARCHAR(255) NOT NULL DEFAULT ''," +
+		"`smtp_verify_ssl_certs` BOOLEAN NOT NULL DEFAULT TRUE," +
+		"`smtp_enable_start_tls` BOOLEAN NOT NULL DEFAULT TRUE" +
+		")",
+	// there is only one app config, and it always needs to exist
+	"INSERT INTO app_configs DEFAULT VALUES",
+
+	"CREATE TABLE `distributed_query_campaign_targets` (" +
+		"`id` INTEGER PRIMARY KEY AUTOINCREMENT," +
+		"`type` INTEGER DEFAULT NULL," +
+		"`distributed_query_campaign_id` INTEGER DEFAULT NULL," +
+		"`target_id` INTEGER DEFAULT NULL" +
+		")",
+
+	"CREATE TABLE `distributed_query_campaigns` (" +
+		"`id` INTEGER PRIMARY KEY AUTOINCREMENT," +
+		"`created_at` TIMESTAMP DEFAULT CURRENT_TIMESTAMP," +
+		"`updated_at` TIMESTAMP NULL DEFAULT CURRENT_TIMESTAMP," +
+		"`deleted_at` TIMESTAMP NULL DEFAULT NULL," +
+		"`deleted` BOOLEAN NOT NULL DEFAULT FALSE," +
+		"`query_id` INTEGER DEFAULT NULL," +
+		"`status` INTEGER DEFAULT NULL," +
+		"`user_id` INTEGER DEFAULT NULL" +
+		")",
+
+	"CREATE TABLE `distributed_query_executions` (" +
+		"`id` INTEGER PRIMARY KEY AUTOINCREMENT," +
+		"`host_id` INTEGER DEFAULT NULL," +
+		"`distributed_query_campaign_id` INTEGER DEFAULT NULL," +
+		"`status` INTEGER DEFAULT NULL," +
+		"`error` VARCHAR(1024) DEFAULT NULL," +
+		"`execution_duration` BIGINT DEFAULT NULL," +
+		"UNIQUE (`host_id`, `distributed_query_campaign_id`)" +
+		")",
+
+	"CREATE TABLE `distributed_query_campaign_hosts` (" +
+		"`distributed_query_campaign_id` INTEGER NOT NULL," +
+		"`host_id` INTEGER NOT NULL," +
+		"PRIMARY KEY (`distributed_query_campaign_id`, `host_id`)" +
+		")",
+
+	"CREATE TABLE `hosts` (" +
+		"`id` INTEGER PRIMARY KEY AUTOINCREMENT," +
+		"`osquery_host_id` VARCHAR(255) NOT NULL UNIQUE," +
+		"`created_at` TIMESTAMP DEFAULT CURRENT_TIMESTAMP," +
+		"`updated_at` TIMESTAMP NULL DEFAULT CURRENT_TIMESTAMP," +
+		"`deleted_at` TIMESTAMP NULL DEFAULT NULL," +
+		"`deleted` BOOLEAN NOT NULL DEFAULT FALSE," +
+		"`detail_update_time` TIMESTAMP NULL DEFAULT NULL," +
+		"`node_key` VARCHAR(255) DEFAULT NULL UNIQUE," +
+		"`host_name` VARCHAR(255) NOT NULL DEFAULT ''," +
+		"`uuid` VARCHAR(255) NOT NULL DEFAULT ''," +
+		"`platform` VARCHAR(255) NOT NULL DEFAULT ''," +
+		"`osquery_version` VARCHAR(255) NOT NULL DEFAULT ''," +
+		"`os_version` VARCHAR(255) NOT NULL DEFAULT ''," +
+		"`build` VARCHAR(255) NOT NULL DEFAULT ''," +
+		"`platform_like` VARCHAR(255) NOT NULL DEFAULT ''," +
+		"`code_name` VARCHAR(255) NOT NULL DEFAULT ''," +
+		"`uptime` BIGINT NOT NULL DEFAULT 0," +
+		"`physical_memory` BIGINT NOT NULL DEFAULT 0," +
+		"`cpu_type` VARCHAR(255) NOT NULL DEFAULT ''," +
+		"`cpu_subtype` VARCHAR(255) NOT NULL DEFAULT ''," +
+		"`cpu_brand` VARCHAR(255) NOT NULL DEFAULT ''," +
+		"`cpu_physical_cores` INTEGER NOT NULL DEFAULT 0," +
+		"`cpu_logical_cores` INTEGER NOT NULL DEFAULT 0," +
+		"`hardware_vendor` VARCHAR(255) NOT NULL DEFAULT ''," +
+		"`hardware_model` VARCHAR(255) NOT NULL DEFAULT ''," +
+		"`hardware_version` VARCHAR(255) NOT NULL DEFAULT ''," +
+		"`hardware_serial` VARCHAR(255) NOT NULL DEFAULT ''," +
+		"`computer_name` VARCHAR(255) NOT NULL DEFAULT ''," +
+		"`primary_ip_id` INTEGER DEFAULT NULL," +
+		"`seen_time` TIMESTAMP NULL DEFAULT NULL," +
+		"`distributed_interval` INTEGER NOT NULL DEFAULT 0," +
+		"`config_refresh` INTEGER NOT NULL DEFAULT 0," +
+		"`config_fetch_time` TIMESTAMP NOT NULL DEFAULT '1970-01-02 00:00:00'," +
+		"`config_hash` VARCHAR(64) NOT NULL DEFAULT ''" +
+		")",
+
+	"CREATE TABLE `invites` (" +
+		"`id` INTEGER PRIMARY KEY AUTOINCREMENT," +
+		"`created_at` TIMESTAMP DEFAULT CURRENT_TIMESTAMP," +
+		"`updated_at` TIMESTAMP NULL DEFAULT CURRENT_TIMESTAMP," +
+		"`deleted_at` TIMESTAMP NULL DEFAULT NULL," +
+		"`deleted` BOOLEAN NOT NULL DEFAULT FALSE," +
+		"`invited_by` INTEGER NOT NULL," +
+		"`email` VARCHAR(255) NOT NULL UNIQUE," +
+		"`admin` BOOLEAN DEFAULT NULL," +
+		"`name` VARCHAR(255) DEFAULT NULL," +
+		"`position` VARCHAR(255) DEFAULT NULL," +
+		"`token` VARCHAR(255) NOT NULL UNIQUE" +
+		")",
+
+	"CREATE TABLE `label_query_executions` (" +
+		"`id` INTEGER PRIMARY KEY AUTOINCREMENT," +
+		"`created_at` TIMESTAMP DEFAULT CURRENT_TIMESTAMP," +
+		"`updated_at` TIMESTAMP NULL DEFAULT CURRENT_TIMESTAMP," +
+		"`matches` BOOLEAN NOT NULL DEFAULT FALSE," +
+		"`label_id` INTEGER DEFAULT NULL," +
+		"`host_id` INTEGER DEFAULT NULL," +
+		"UNIQUE (`label_id`, `host_id`)" +
+		")",
+
+	"CREATE TABLE `labels` (" +
+		"`id` INTEGER PRIMARY KEY AUTOINCREMENT," +
+		"`created_at` TIMESTAMP DEFAULT CURRENT_TIMESTAMP," +
+		"`updated_at` TIMESTAMP NULL DEFAULT CURRENT_TIMESTAMP," +
+		"`deleted_at` TIMESTAMP NULL DEFAULT NULL," +
+		"`deleted` BOOLEAN NOT NULL DEFAULT FALSE," +
+		"`name` VARCHAR(255) NOT NULL UNIQUE," +
+		"`description` VARCHAR(255) DEFAULT NULL," +
+		"`query` TEXT NOT NULL," +
+		"`platform` VARCHAR(255) DEFAULT NULL," +
+		fmt.Sprintf("`label_type` INTEGER NOT NULL DEFAULT %d", kolide.LabelTypeBuiltIn) +
+		")",
+
+	"CREATE TABLE `options` (" +
+		"`id` INTEGER PRIMARY KEY AUTOINCREMENT," +
+		"`name` VARCHAR(255) NOT NULL UNIQUE," +
+		"`type` INTEGER NOT NULL," +
+		"`value` VARCHAR(255) NOT NULL," +
+		"`read_only` BOOLEAN NOT NULL DEFAULT FALSE" +
+		")",
+
+	"CREATE TABLE `scheduled_queries` (" +
+		"`id` INTEGER PRIMARY KEY AUTOINCREMENT," +
+		"`created_at` TIMESTAMP DEFAULT CURRENT_TIMESTAMP," +
+		"`updated_at` TIMESTAMP NULL DEFAULT CURRENT_TIMESTAMP," +
+		"`deleted_at` TIMESTAMP NULL DEFAULT NULL," +
+		"`deleted` BOOLEAN NOT NULL DEFAULT FALSE," +
+		"`pack_id` INTEGER DEFAULT NULL," +
+		"`query_id` INTEGER DEFAULT NULL," +
+		"`interval` INTEGER DEFAULT NULL," +
+		"`snapshot` BOOLEAN DEFAULT NULL," +
+		"`removed` BOOLEAN DEFAULT NULL," +
+		"`platform` VARCHAR(255) DEFAULT NULL," +
+		"`version` VARCHAR(255) DEFAULT NULL," +
+		"`shard` INTEGER DEFAULT NULL" +
+		")",
+
+	"CREATE TABLE `pack_targets` (" +
+		"`id` INTEGER PRIMARY KEY AUTOINCREMENT," +
+		"`pack_id` INTEGER DEFAULT NULL," +
+		"`type` INTEGER DEFAULT NULL," +
+		"`target_id` INTEGER DEFAULT NULL," +
+		"UNIQUE (`pack_id`, `target_id`, `type`)" +
+		")",
+
+	"CREATE TABLE `packs` (" +
+		"`id` INTEGER PRIMARY KEY AUTOINCREMENT," +
+		"`created_at` TIMESTAMP DEFAULT CURRENT_TIMESTAMP," +
+		"`updated_at` TIMESTAMP NULL DEFAULT CURRENT_TIMESTAMP," +
+		"`deleted_at` TIMESTAMP NULL DEFAULT NULL," +
+		"`deleted` BOOLEAN NOT NULL DEFAULT FALSE," +
+		"`disabled` BOOLEAN NOT NULL DEFAULT FALSE," +
+		"`name` VARCHAR(255) NOT NULL UNIQUE," +
+		"`description` VARCHAR(255) DEFAULT NULL," +
+		"`platform` VARCHAR(255) DEFAULT NULL," +
+		"`created_by` INTEGER DEFAULT NULL" +
+		")",
+
+	"CREATE TABLE `password_reset_requests` (" +
+		"`id` INTEGER PRIMARY KEY AUTOINCREMENT," +
+		"`created_at` TIMESTAMP DEFAULT CURRENT_TIMESTAMP," +
+		"`updated_at` TIMESTAMP NULL DEFAULT CURRENT_TIMESTAMP," +
+		"`expires_at` TIMESTAMP NOT NULL DEFAULT '1970-01-01 00:00:01'," +
+		"`user_id` INTEGER NOT NULL," +
+		"`token` VARCHAR(1024) NOT NULL" +
+		")",
+
+	"CREATE TABLE `users` (" +
+		"`id` INTEGER PRIMARY KEY AUTOINCREMENT," +
+		"`created_at` TIMESTAMP DEFAULT CURRENT_TIMESTAMP," +
+		"`updated_at` TIMESTAMP NULL DEFAULT CURRENT_TIMESTAMP," +
+		"`deleted_at` TIMESTAMP NULL DEFAULT NULL," +
+		"`deleted` BOOLEAN NOT NULL DEFAULT FALSE," +
+		"`username` VARCHAR(255) NOT NULL UNIQUE," +
+		"`password` BLOB," +
+		"`salt` VARCHAR(255) NOT NULL," +
+		"`name` VARCHAR(255) NOT NULL DEFAULT ''," +
+		"`email` VARCHAR(255) NOT NULL UNIQUE," +
+		"`admin` BOOLEAN NOT NULL DEFAULT FALSE," +
+		"`enabled` BOOLEAN NOT NULL DEFAULT FALSE," +
+		"`admin_forced_password_reset` BOOLEAN NOT NULL DEFAULT FALSE," +
+		"`gravatar_url` VARCHAR(255) NOT NULL DEFAULT ''," +
+		"`position` VARCHAR(255) NOT NULL DEFAULT ''" +
+		")",
+
+	"CREATE TABLE `sessions` (" +
+		"`id` INTEGER PRIMARY KEY AUTOINCREMENT," +
+		"`created_at` TIMESTAMP DEFAULT CURRENT_TIMESTAMP," +
+		"`accessed_at` TIMESTAMP NULL DEFAULT CURRENT_TIMESTAMP," +
+		"`user_id` INTEGER NOT NULL," +
+		"`key` VARCHAR(255) NOT NULL UNIQUE" +
+		")",
+
+	"CREATE TABLE `queries` (" +
+		"`id` INTEGER PRIMARY KEY AUTOINCREMENT," +
+		"`created_at` TIMESTAMP DEFAULT CURRENT_TIMESTAMP," +
+		"`updated_at` TIMESTAMP NULL DEFAULT CURRENT_TIMESTAMP," +
+		"`deleted_at` TIMESTAMP NULL DEFAULT NULL," +
+		"`deleted` BOOLEAN NOT NULL DEFAULT FALSE," +
+		"`saved` BOOLEAN NOT NULL DEFAULT FALSE," +
+		"`name` VARCHAR(255) NOT NULL UNIQUE," +
+		"`description` VARCHAR(255) DEFAULT NULL," +
+		"`query` TEXT NOT NULL," +
+		"`author_id` INTEGER DEFAULT NULL REFERENCES `users` (`id`) ON DELETE SET NULL," +
+		"`category` VARCHAR(255) NOT NULL DEFAULT ''" +
+		")",
+	"CREATE INDEX `idx_queries_category` ON `queries` (`category`)",
+	"CREATE INDEX `idx_queries_saved_author` ON `queries` (`saved`, `author_id`)",
+
+	"CREATE TABLE `query_tags` (" +
+		"`query_id` INTEGER NOT NULL," +
+		"`tag` VARCHAR(64) NOT NULL," +
+		"PRIMARY KEY (`query_id`, `tag`)" +
+		")",
+	"CREATE INDEX `idx_query_tags_tag` ON `query_tags` (`tag`, `query_id`)",
+
+	"CREATE TABLE `network_interfaces` (" +
+		"`id` INTEGER PRIMARY KEY AUTOINCREMENT," +
+		"`host_id` INTEGER NOT NULL REFERENCES `hosts` (`id`) ON DELETE CASCADE," +
+		"`mac` VARCHAR(255) NOT NULL DEFAULT ''," +
+		"`ip_address` VARCHAR(255) NOT NULL DEFAULT ''," +
+		"`broadcast` VARCHAR(255) NOT NULL DEFAULT ''," +
+		"`ibytes` BIGINT NOT NULL DEFAULT 0," +
+		"`interface` VARCHAR(255) NOT NULL DEFAULT ''," +
+		"`ipackets` BIGINT NOT NULL DEFAULT 0," +
+		"`last_change` BIGINT NOT NULL DEFAULT 0," +
+		"`mask` VARCHAR(255) NOT NULL DEFAULT ''," +
+		"`metric` INTEGER NOT NULL DEFAULT 0," +
+		"`mtu` INTEGER NOT NULL DEFAULT 0," +
+		"`obytes` BIGINT NOT NULL DEFAULT 0," +
+		"`ierrors` BIGINT NOT NULL DEFAULT 0," +
+		"`oerrors` BIGINT NOT NULL DEFAULT 0," +
+		"`opackets` BIGINT NOT NULL DEFAULT 0," +
+		"`point_to_point` VARCHAR(255) NOT NULL DEFAULT ''," +
+		"`type` INTEGER NOT NULL DEFAULT 0," +
+		"UNIQUE (`ip_address`, `host_id`, `interface`)" +
+		")",
+	"CREATE INDEX `idx_network_interfaces_host` ON `network_interfaces` (`host_id`)",
+
+	"CREATE TABLE `decorators` (" +
+		"`id` INTEGER PRIMARY KEY AUTOINCREMENT," +
+		"`created_at` TIMESTAMP DEFAULT CURRENT_TIMESTAMP," +
+		"`updated_at` TIMESTAMP NULL DEFAULT CURRENT_TIMESTAMP," +
+		"`query` TEXT NOT NULL," +
+		"`type` INTEGER NOT NULL," +
+		"`interval` INTEGER NOT NULL" +
+		")",
+
+	"CREATE TABLE `file_integrity_monitorings` (" +
+		"`id` INTEGER PRIMARY KEY AUTOINCREMENT," +
+		"`section_name` VARCHAR(255) NOT NULL DEFAULT '' UNIQUE," +
+		"`description` VARCHAR(255) NOT NULL DEFAULT ''" +
+		")",
+
+	"CREATE TABLE `file_integrity_monitoring_files` (" +
+		"`id` INTEGER PRIMARY KEY AUTOINCREMENT," +
+		"`file` VARCHAR(255) NOT NULL DEFAULT '' UNIQUE," +
+		"`file_integrity_monitoring_id` INTEGER NOT NULL DEFAULT 0 " +
+		"REFERENCES `file_integrity_monitorings` (`id`) ON DELETE CASCADE" +
+		")",
+	"CREATE INDEX `fk_file_integrity_monitoring` ON `file_integrity_monitoring_files` (`file_integrity_monitoring_id`)",
+
+	"CREATE TABLE `yara_signatures` (" +
+		"`id` INTEGER PRIMARY KEY AUTOINCREMENT," +
+		"`signature_name` VARCHAR(128) NOT NULL DEFAULT '' UNIQUE" +
+		")",
+
+	"CREATE TABLE `yara_file_paths` (" +
+		"`file_integrity_monitoring_id` INTEGER NOT NULL DEFAULT 0 REFERENCES `file_integrity_monitorings` (`id`)," +
+		"`yara_signature_id` INTEGER NOT NULL DEFAULT 0 REFERENCES `yara_signatures` (`id`)," +
+		"PRIMARY KEY (`file_integrity_monitoring_id`, `yara_signature_id`)" +
+		")",
+	"CREATE INDEX `fk_yara_signature_id` ON `yara_file_paths` (`yara_signature_id`)",
+
+	"CREATE TABLE `yara_signature_paths` (" +
+		"`id` INTEGER PRIMARY KEY AUTOINCREMENT," +
+		"`file_path` VARCHAR(255) NOT NULL DEFAULT ''," +
+		"`yara_signature_id` INTEGER NOT NULL DEFAULT 0 REFERENCES `yara_signatures` (`id`) ON DELETE CASCADE" +
+		")",
+	"CREATE INDEX `fk_yara_signature` ON `yara_signature_paths` (`yara_signature_id`)",
+
+	"CREATE TABLE `revisions` (" +
+		"`id` INTEGER PRIMARY KEY AUTOINCREMENT," +
+		"`created_at` TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP," +
+		"`object_type` VARCHAR(32) NOT NULL," +
+		"`object_id` INTEGER NOT NULL," +
+		"`action` VARCHAR(32) NOT NULL," +
+		"`author_id` INTEGER DEFAULT NULL," +
+		"`restored_from` INTEGER DEFAULT NULL," +
+		"`snapshot` TEXT NOT NULL," +
+		"`changes` TEXT NOT NULL" +
+		")",
+	"CREATE INDEX `idx_revisions_object` ON `revisions` (`object_type`, `object_id`)",
+
+	"CREATE TABLE `scheduled_query_stats` (" +
+		"`host_id` INTEGER NOT NULL," +
+		"`scheduled_query_id` INTEGER NOT NULL," +
+		"`executions` BIGINT NOT NULL DEFAULT 0," +
+		"`last_executed` TIMESTAMP NULL DEFAULT NULL," +
+		"`wall_time` BIGINT NOT NULL DEFAULT 0," +
+		"`user_time` BIGINT NOT NULL DEFAULT 0," +
+		"`system_time` BIGINT NOT NULL DEFAULT 0," +
+		"`average_memory` BIGINT NOT NULL DEFAULT 0," +
+		"`output_size` BIGINT NOT NULL DEFAULT 0," +
+		"`denylisted` BOOLEAN NOT NULL DEFAULT FALSE," +
+		"PRIMARY KEY (`host_id`, `scheduled_query_id`)" +
+		")",
+	"CREATE INDEX `idx_scheduled_query_stats_scheduled_query` ON `scheduled_query_stats` (`scheduled_query_id`)",
+
+	"CREATE TABLE `agent_logs` (" +
+		"`id` INTEGER PRIMARY KEY AUTOINCREMENT," +
+		"`host_id` INTEGER NOT NULL," +
+		"`created_at` TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP," +
+		"`severity` VARCHAR(16) NOT NULL," +
+		"`filename` VARCHAR(255) NOT NULL DEFAULT ''," +
+		"`line` INTEGER NOT NULL DEFAULT 0," +
+		"`message` TEXT NOT NULL," +
+		"`version` VARCHAR(32) NOT NULL DEFAULT ''" +
+		")",
+	"CREATE INDEX `idx_agent_logs_host` ON `agent_logs` (`host_id`, `id`)",
+	"CREATE INDEX `idx_agent_logs_location` ON `agent_logs` (`severity`, `filename`, `line`)",
+
+	"CREATE TABLE `agent_log_counts` (" +
+		"`host_id` INTEGER NOT NULL," +
+		"`severity` VARCHAR(16) NOT NULL," +
+		"`count` INTEGER NOT NULL DEFAULT 0," +
+		"PRIMARY KEY (`host_id`, `severity`)" +
+		")",
+
+	"CREATE TABLE `detail_queries` (" +
+		"`id` INTEGER PRIMARY KEY AUTOINCREMENT," +
+		"`created_at` TIMESTAMP DEFAULT CURRENT_TIMESTAMP," +
+		"`updated_at` TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP," +
+		"`name` VARCHAR(64) NOT NULL UNIQUE," +
+		"`query` TEXT NOT NULL," +
+		"`platform` VARCHAR(255) NOT NULL DEFAULT ''," +
+		"`interval` INTEGER NOT NULL," +
+		"`columns` TEXT NOT NULL" +
+		")",
+
+	"CREATE TABLE `detail_query_executions` (" +
+		"`host_id` INTEGER NOT NULL," +
+		"`detail_query_id` INTEGER NOT NULL," +
+		"`updated_at` TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP," +
+		"PRIMARY KEY (`host_id`, `detail_query_id`)" +
+		")",
+	"CREATE INDEX `idx_detail_query_executions_query` ON `detail_query_executions` (`detail_query_id`)",
+
+	"CREATE TABLE `host_attributes` (" +
+		"`host_id` INTEGER NOT NULL," +
+		"`detail_query_id` INTEGER NOT NULL," +
+		"`key` VARCHAR(255) NOT NULL," +
+		"`type` VARCHAR(16) NOT NULL," +
+		"`value` TEXT NOT NULL," +
+		"`updated_at` TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP," +
+		"PRIMARY KEY (`host_id`, `key`)" +
+		")",
+	"CREATE INDEX `idx_host_attributes_key` ON `host_attributes` (`key`)",
+	"CREATE INDEX `idx_host_attributes_query` ON `host_attributes` (`detail_query_id`)",
+
+	"CREATE TABLE `software` (" +
+		"`id` INTEGER PRIMARY KEY AUTOINCREMENT," +
+		"`name` VARCHAR(255) NOT NULL," +
+		"`version` VARCHAR(255) NOT NULL DEFAULT ''," +
+		"`source` VARCHAR(64) NOT NULL," +
+		"UNIQUE (`name`, `version`, `source`)" +
+		")",
+	"CREATE INDEX `idx_software_source` ON `software` (`source`)",
+
+	"CREATE TABLE `host_software` (" +
+		"`host_id` INTEGER NOT NULL," +
+		"`software_id` INTEGER NOT NULL," +
+		"`first_seen` TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP," +
+		"`last_seen` TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP," +
+		"PRIMARY KEY (`host_id`, `software_id`)" +
+		")",
+	"CREATE INDEX `idx_host_software_software` ON `host_software` (`software_id`)",
+
+	"CREATE TABLE `vulnerabilities` (" +
+		"`cve` VARCHAR(32) NOT NULL PRIMARY KEY," +
+		"`summary` TEXT NOT NULL," +
+		"`cvss_score` DOUBLE NOT NULL DEFAULT 0" +
+		")",
+
+	"CREATE TABLE `vulnerable_software` (" +
+		"`id` INTEGER PRIMARY KEY AUTOINCREMENT," +
+		"`cve` VARCHAR(32) NOT NULL," +
+		"`vendor` VARCHAR(255) NOT NULL DEFAULT ''," +
+		"`product` VARCHAR(255) NOT NULL," +
+		"`version` VARCHAR(255) NOT NULL DEFAULT ''," +
+		"`version_start_including` VARCHAR(255) NOT NULL DEFAULT ''," +
+		"`version_start_excluding` VARCHAR(255) NOT NULL DEFAULT ''," +
+		"`version_end_including` VARCHAR(255) NOT NULL DEFAULT ''," +
+		"`version_end_excluding` VARCHAR(255) NOT NULL DEFAULT ''" +
+		")",
+	"CREATE INDEX `idx_vulnerable_software_cve` ON `vulnerable_software` (`cve`)",
+	"CREATE INDEX `idx_vulnerable_software_product` ON `vulnerable_software` (`product`)",
+
+	"CREATE TABLE `software_vulnerabilities` (" +
+		"`software_id` INTEGER NOT NULL," +
+		"`cve` VARCHAR(32) NOT NULL," +
+		"PRIMARY KEY (`software_id`, `cve`)" +
+		")",
+	"CREATE INDEX `idx_software_vulnerabilities_cve` ON `software_vulnerabilities` (`cve`)",
+}
+
+var dropTables = []string{
+	"software_vulnerabilities",
+	"vulnerable_software",
+	"vulnerabilities",
+	"host_software",
+	"software",
+	"host_attributes",
+	"detail_query_executions",
+	"detail_queries",
+	"agent_log_counts",
+	"agent_logs",
+	"scheduled_query_stats",
+	"revisions",
+	"yara_signature_paths",
+	"yara_file_paths",
+	"yara_signatures",
+	"file_integrity_monitoring_files",
+	"file_integrity_monitorings",
+	"decorators",
+	"network_interfaces",
+	"query_tags",
+	"queries",
+	"sessions",
+	"users",
+	"password_reset_requests",
+	"packs",
+	"pack_targets",
+	"scheduled_queries",
+	"options",
+	"labels",
+	"label_query_executions",
+	"invites",
+	"hosts",
+	"distributed_query_campaign_hosts",
+	"distributed_query_executions",
+	"distributed_query_campaigns",
+	"distributed_query_campaign_targets",
+	"app_configs",
+}
+
+func Up_20170214120000(tx *sql.Tx) error {
+	for _, statement := range createTables {
+		if _, err := tx.Exec(statement); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func Down_20170214120000(tx *sql.Tx) error {
+	for _, table := range dropTables {
+		if _, err := tx.Exec("DROP TABLE IF EXISTS `" + table + "`"); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,7 @@
+package tables
+
+import "github.com/kolide/goose"
+
+var (
+	MigrationClient = goose.New("migration_status_tables", goose.Sqlite3Dialect{})
+)
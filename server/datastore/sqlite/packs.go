@@ -0,0 +1,121 @@
+package sqlite
+
+import (
+	"database/sql"
+
+	"github.com/kolide/kolide-ose/server/datastore/internal/sqlcommon"
+	"github.com/kolide/kolide-ose/server/kolide"
+	"github.com/pkg/errors"
+)
+
+func (d *Datastore) PackByName(name string) (*kolide.Pack, bool, error) {
+	return sqlcommon.PackByName(d.db, name)
+}
+
+// NewPack creates a new Pack
+func (d *Datastore) NewPack(pack *kolide.Pack) (*kolide.Pack, error) {
+	var (
+		deletedPack kolide.Pack
+		query       string
+	)
+	err := d.db.Get(&deletedPack,
+		"SELECT * FROM packs WHERE name = ? AND deleted", pack.Name)
+	switch err {
+	case nil:
+		query = `
+		REPLACE INTO packs 
+			( name, description, platform, created_by, disabled, deleted)
+			VALUES ( ?, ?, ?, ?, ?, ?)
+		`
+	case sql.ErrNoRows:
+		query = `
+		INSERT INTO packs 
+			( name, description, platform, created_by, disabled, deleted)
+			VALUES ( ?, ?, ?, ?, ?, ?)
+		`
+	default:
+		return nil, errors.Wrap(err, "check for existing pack")
+	}
+
+	deleted := false
+	result, err := d.db.Exec(query, pack.Name, pack.Description, pack.Platform, pack.CreatedBy, pack.Disabled, deleted)
+	if err != nil && isDuplicate(err) {
+		return nil, alreadyExists("Pack").WithID(deletedPack.ID)
+	} else if err != nil {
+		return nil, errors.Wrap(err, "creating new pack")
+	}
+
+	id, _ := result.LastInsertId()
+	pack.ID = uint(id)
+	return pack, nil
+}
+
+func (d *Datastore) SavePack(pack *kolide.Pack) error {
+	return sqlcommon.SavePack(d.db, pack)
+}
+
+func (d *Datastore) DeletePack(pid uint) error {
+	return sqlcommon.DeletePack(d.db, pid, d.clock.Now())
+}
+
+func (d *Datastore) Pack(pid uint) (*kolide.Pack, error) {
+	return sqlcommon.Pack(d.db, pid)
+}
+
+func (d *Datastore) ListPacks(opt kolide.ListOptions) ([]*kolide.Pack, error) {
+	return sqlcommon.ListPacks(d.db, opt)
+}
+
+// AddLabelToPack associates a kolide.Label with a kolide.Pack
+func (d *Datastore) AddLabelToPack(lid uint, pid uint) error {
+	query := `
+		INSERT INTO pack_targets ( pack_id,	type, target_id )
+			VALUES ( ?, ?, ? )
+			ON CONFLICT DO NOTHING
+	`
+	_, err := d.db.Exec(query, pid, kolide.TargetLabel, lid)
+	if err != nil {
+		return errors.Wrap(err, "adding label to pack")
+	}
+
+	return nil
+}
+
+// AddHostToPack associates a kolide.Host with a kolide.Pack
+func (d *Datastore) AddHostToPack(hid, pid uint) error {
+	query := `
+		INSERT INTO pack_targets ( pack_id, type, target_id )
+			VALUES ( ?, ?, ? )
+			ON CONFLICT DO NOTHING
+	`
+	_, err := d.db.Exec(query, pid, kolide.TargetHost, hid)
+	if err != nil {
+		return errors.Wrap(err, "adding host to pack")
+	}
+
+	return nil
+}
+
+func (d *Datastore) ListLabelsForPack(pid uint) ([]*kolide.Label, error) {
+	return sqlcommon.ListLabelsForPack(d.db, pid)
+}
+
+func (d *Datastore) RemoveLabelFromPack(lid, pid uint) error {
+	return sqlcommon.RemoveLabelFromPack(d.db, lid, pid)
+}
+
+func (d *Datastore) RemoveHostFromPack(hid, pid uint) error {
+	return sqlcommon.RemoveHostFromPack(d.db, hid, pid)
+}
+
+func (d *Datastore) ListHostsInPack(pid uint, opt kolide.ListOptions) ([]*kolide.Host, error) {
+	return sqlcommon.ListHostsInPack(d.db, pid, opt)
+}
+
+func (d *Datastore) ListExplicitHostsInPack(pid uint, opt kolide.ListOptions) ([]*kolide.Host, error) {
+	return sqlcommon.ListExplicitHostsInPack(d.db, pid, opt)
+}
+
+func (d *Datastore) ListPackTargetsForHost(hid uint) ([]*kolide.HostPackTarget, error) {
+	return sqlcommon.ListPackTargetsForHost(d.db, hid)
+}
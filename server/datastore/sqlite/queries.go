@@ -0,0 +1,64 @@
+package sqlite
+
+import (
+	"strings"
+	"unicode"
+
+	"github.com/kolide/kolide-ose/server/datastore/internal/sqlcommon"
+	"github.com/kolide/kolide-ose/server/kolide"
+)
+
+func (d *Datastore) QueryByName(name string) (*kolide.Query, bool, error) {
+	return sqlcommon.QueryByName(d.db, name)
+}
+
+func (d *Datastore) NewQuery(query *kolide.Query) (*kolide.Query, error) {
+	return sqlcommon.NewQuery(d.db, dialect, query)
+}
+
+func (d *Datastore) SaveQuery(q *kolide.Query) error {
+	return sqlcommon.SaveQuery(d.db, dialect, q)
+}
+
+// DeleteQuery soft deletes Query identified by Query.ID
+func (d *Datastore) DeleteQuery(qid uint) error {
+	return d.deleteEntity("queries", qid)
+}
+
+func (d *Datastore) DeleteQueries(ids []uint) (uint, error) {
+	return sqlcommon.DeleteQueries(d.db, ids, d.clock.Now())
+}
+
+func (d *Datastore) Query(id uint) (*kolide.Query, error) {
+	return sqlcommon.Query(d.db, id)
+}
+
+func (d *Datastore) ListQueries(opt kolide.ListOptions, filter kolide.QueryFilter) ([]*kolide.Query, error) {
+	return sqlcommon.ListQueries(d.db, dialect, opt, filter)
+}
+
+// querySearchSQL returns the condition on the queries table, aliased q, that
+// requires the name, description or SQL of a query to contain each word of
+// the search.
+func querySearchSQL(search string) (string, []interface{}) {
+	conditions := []string{}
+	args := []interface{}{}
+	for _, word := range querySearchWords(search) {
+		conditions = append(conditions, `(
+			q.name LIKE ? ESCAPE '\'
+			OR q.description LIKE ? ESCAPE '\'
+			OR q.query LIKE ? ESCAPE '\'
+		)`)
+		pattern := "%" + sqlcommon.EscapeLike(word) + "%"
+		args = append(args, pattern, pattern, pattern)
+	}
+	return strings.Join(conditions, " AND "), args
+}
+
+// querySearchWords splits a search into the words that a query must contain
+// every one of.
+func querySearchWords(search string) []string {
+	return strings.FieldsFunc(search, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '_'
+	})
+}
@@ -0,0 +1,226 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"fmt"
+	"time"
+
+	"github.com/WatchBeam/clock"
+	"github.com/go-kit/kit/log"
+	"github.com/jmoiron/sqlx"
+	"github.com/kolide/kolide-ose/server/config"
+	"github.com/kolide/kolide-ose/server/datastore/internal/sqlcommon"
+	"github.com/kolide/kolide-ose/server/datastore/sqlite/migrations/data"
+	"github.com/kolide/kolide-ose/server/datastore/sqlite/migrations/tables"
+	"github.com/kolide/kolide-ose/server/kolide"
+	"github.com/mattn/go-sqlite3"
+)
+
+const (
+	defaultSelectLimit = 1000
+	driverName         = "sqlite3_kolide"
+)
+
+// dialect is what the shared queries need from SQLite. LIKE compares
+// ignoring case.
+var dialect = sqlcommon.Dialect{
+	Like:                   `LIKE ? ESCAPE '\'`,
+	InsertIgnore:           "INSERT OR IGNORE INTO %s",
+	IsDuplicate:            isDuplicate,
+	HostOfflineSQL:         hostOfflineSQL,
+	HostAttributeFilterSQL: hostAttributeFilterSQL,
+	QuerySearchSQL:         querySearchSQL,
+	UpsertNetworkInterface: upsertNetworkInterface,
+}
+
+func init() {
+	sql.Register(driverName, &utcDriver{})
+}
+
+// utcDriver is the SQLite driver with times stored in UTC. SQLite stores
+// times as text, which only compares correctly when every time is in the
+// same zone.
+type utcDriver struct {
+	sqlite3.SQLiteDriver
+}
+
+func (d *utcDriver) Open(dsn string) (driver.Conn, error) {
+	conn, err := d.SQLiteDriver.Open(dsn)
+	if err != nil {
+		return nil, err
+	}
+	return &utcConn{conn.(*sqlite3.SQLiteConn)}, nil
+}
+
+// utcConn converts the time arguments of statements to UTC before they are
+// bound. It overrides the context methods, which database/sql uses when the
+// driver has them.
+type utcConn struct {
+	*sqlite3.SQLiteConn
+}
+
+func (c *utcConn) ExecContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	return c.SQLiteConn.ExecContext(ctx, query, utcArgs(args))
+}
+
+func (c *utcConn) QueryContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	return c.SQLiteConn.QueryContext(ctx, query, utcArgs(args))
+}
+
+func (c *utcConn) Prepare(query string) (driver.Stmt, error) {
+	return c.PrepareContext(context.Background(), query)
+}
+
+func (c *utcConn) PrepareContext(ctx context.Context, query string) (driver.Stmt, error) {
+	stmt, err := c.SQLiteConn.PrepareContext(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	return &utcStmt{stmt.(*sqlite3.SQLiteStmt)}, nil
+}
+
+type utcStmt struct {
+	*sqlite3.SQLiteStmt
+}
+
+func (s *utcStmt) ExecContext(ctx context.Context, args []driver.NamedValue) (driver.Result, error) {
+	return s.SQLiteStmt.ExecContext(ctx, utcArgs(args))
+}
+
+func (s *utcStmt) QueryContext(ctx context.Context, args []driver.NamedValue) (driver.Rows, error) {
+	return s.SQLiteStmt.QueryContext(ctx, utcArgs(args))
+}
+
+// utcArgs returns the arguments with times converted to UTC.
+func utcArgs(args []driver.NamedValue) []driver.NamedValue {
+	converted := make([]driver.NamedValue, len(args))
+	for i, arg := range args {
+		if t, ok := arg.Value.(time.Time); ok {
+			arg.Value = t.UTC()
+		}
+		converted[i] = arg
+	}
+	return converted
+}
+
+// Datastore is an implementation of kolide.Datastore interface backed by
+// SQLite
+type Datastore struct {
+	db     *sqlx.DB
+	logger log.Logger
+	clock  clock.Clock
+	config config.SqliteConfig
+}
+
+// New creates a SQLite datastore, creating the database file if it does not
+// exist.
+func New(config config.SqliteConfig, c clock.Clock, opts ...DBOption) (*Datastore, error) {
+	options := &dbOptions{
+		logger: log.NewNopLogger(),
+	}
+
+	for _, setOpt := range opts {
+		setOpt(options)
+	}
+
+	db, err := sqlx.Open(driverName, generateSqliteConnectionString(config))
+	if err != nil {
+		return nil, err
+	}
+	// SQLite allows a single writer at a time, and a connection to an in
+	// memory database can't be shared, so all queries go through one
+	// connection
+	db.SetMaxOpenConns(1)
+	if err := db.Ping(); err != nil {
+		db.Close()
+		return nil, err
+	}
+
+	ds := &Datastore{
+		db:     db,
+		logger: options.logger,
+		clock:  c,
+		config: config,
+	}
+
+	return ds, nil
+}
+
+func (d *Datastore) Name() string {
+	return "sqlite"
+}
+
+func (d *Datastore) MigrateTables() error {
+	if err := tables.MigrationClient.Up(d.db.DB, ""); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (d *Datastore) MigrateData() error {
+	if err := data.MigrationClient.Up(d.db.DB, ""); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// Drop removes all tables
+func (d *Datastore) Drop() error {
+	tables := []string{}
+	sql := `
+		SELECT name FROM sqlite_master
+		WHERE type = 'table' AND name NOT LIKE 'sqlite_%'
+	`
+	if err := d.db.Select(&tables, sql); err != nil {
+		return err
+	}
+
+	// Foreign keys can't be turned off within a transaction
+	if _, err := d.db.Exec("PRAGMA foreign_keys = OFF"); err != nil {
+		return err
+	}
+	defer d.db.Exec("PRAGMA foreign_keys = ON")
+
+	tx, err := d.db.Begin()
+	if err != nil {
+		return err
+	}
+	for _, table := range tables {
+		_, err = tx.Exec(fmt.Sprintf("DROP TABLE `%s`", table))
+		if err != nil {
+			tx.Rollback()
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+// HealthCheck returns an error if the SQLite database is not healthy.
+func (d *Datastore) HealthCheck() error {
+	_, err := d.db.Exec("select 1")
+	return err
+}
+
+// Close frees resources associated with the underlying database
+func (d *Datastore) Close() error {
+	return d.db.Close()
+}
+
+func (d *Datastore) log(msg string) {
+	d.logger.Log("comp", d.Name(), "msg", msg)
+}
+
+func appendListOptionsToSQL(sql string, opts kolide.ListOptions) string {
+	return sqlcommon.AppendListOptionsToSQL(sql, opts)
+}
+
+// generateSqliteConnectionString returns a SQLite connection string using
+// the provided configuration. Times are read back in UTC, as they are
+// stored, and foreign keys are enforced as they are by MySQL.
+func generateSqliteConnectionString(conf config.SqliteConfig) string {
+	return fmt.Sprintf("file:%s?_loc=UTC&_foreign_keys=1&_busy_timeout=5000", conf.Path)
+}
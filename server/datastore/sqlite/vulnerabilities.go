@@ -0,0 +1,307 @@
+package sqlite
+
+import (
+	"database/sql"
+	"strings"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/kolide/kolide-ose/server/kolide"
+	"github.com/pkg/errors"
+)
+
+// vulnerabilityBatchSize limits the number of rows written or looked up by
+// each statement when importing and matching vulnerabilities.
+const vulnerabilityBatchSize = 500
+
+func (d *Datastore) ImportVulnerabilities(vulns []*kolide.Vulnerability) (err error) {
+	txn, err := d.db.Beginx()
+	if err != nil {
+		return errors.Wrap(err, "import vulnerabilities begin transaction")
+	}
+	var success bool
+	defer func() {
+		if success {
+			if err = txn.Commit(); err == nil {
+				return
+			}
+		}
+		txn.Rollback()
+	}()
+
+	insertVulnerability, err := txn.Prepare(`
+		INSERT INTO vulnerabilities (cve, summary, cvss_score)
+		VALUES (?, ?, ?)
+		ON CONFLICT (cve) DO UPDATE SET
+			summary = excluded.summary,
+			cvss_score = excluded.cvss_score
+	`)
+	if err != nil {
+		return errors.Wrap(err, "preparing vulnerability insert")
+	}
+	defer insertVulnerability.Close()
+
+	deleteCriteria, err := txn.Prepare("DELETE FROM vulnerable_software WHERE cve = ?")
+	if err != nil {
+		return errors.Wrap(err, "preparing vulnerable software delete")
+	}
+	defer deleteCriteria.Close()
+
+	insertCriterion, err := txn.Prepare(`
+		INSERT INTO vulnerable_software (
+			cve,
+			vendor,
+			product,
+			version,
+			version_start_including,
+			version_start_excluding,
+			version_end_including,
+			version_end_excluding
+		) VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+	`)
+	if err != nil {
+		return errors.Wrap(err, "preparing vulnerable software insert")
+	}
+	defer insertCriterion.Close()
+
+	for _, vuln := range vulns {
+		if _, err := insertVulnerability.Exec(vuln.CVE, vuln.Summary, vuln.CVSSScore); err != nil {
+			return errors.Wrapf(err, "inserting vulnerability %s", vuln.CVE)
+		}
+		if _, err := deleteCriteria.Exec(vuln.CVE); err != nil {
+			return errors.Wrapf(err, "deleting vulnerable software of %s", vuln.CVE)
+		}
+		for _, c := range vuln.Criteria {
+			_, err := insertCriterion.Exec(
+				vuln.CVE,
+				c.Vendor,
+				c.Product,
+				c.Version,
+				c.VersionStartIncluding,
+				c.VersionStartExcluding,
+				c.VersionEndIncluding,
+				c.VersionEndExcluding,
+			)
+			if err != nil {
+				return errors.Wrapf(err, "inserting vulnerable software of %s", vuln.CVE)
+			}
+		}
+	}
+
+	success = true
+	return err
+}
+
+func (d *Datastore) Vulnerability(cve string) (*kolide.Vulnerability, error) {
+	vuln := &kolide.Vulnerability{}
+	err := d.db.Get(vuln, "SELECT * FROM vulnerabilities WHERE cve = ?", cve)
+	if err == sql.ErrNoRows {
+		return nil, notFound("Vulnerability").WithMessage(cve)
+	} else if err != nil {
+		return nil, errors.Wrap(err, "selecting vulnerability")
+	}
+	return vuln, nil
+}
+
+func (d *Datastore) ListVulnerableSoftware(products []string) ([]*kolide.VulnerableSoftware, error) {
+	selectStatement := `
+		SELECT
+			cve,
+			vendor,
+			product,
+			version,
+			version_start_including,
+			version_start_excluding,
+			version_end_including,
+			version_end_excluding
+		FROM vulnerable_software
+	`
+	criteria := []*kolide.VulnerableSoftware{}
+	if products == nil {
+		if err := d.db.Select(&criteria, selectStatement); err != nil {
+			return nil, errors.Wrap(err, "listing vulnerable software")
+		}
+		return criteria, nil
+	}
+
+	for start := 0; start < len(products); start += vulnerabilityBatchSize {
+		end := start + vulnerabilityBatchSize
+		if end > len(products) {
+			end = len(products)
+		}
+		query, args, err := sqlx.In(selectStatement+" WHERE product IN (?)", products[start:end])
+		if err != nil {
+			return nil, errors.Wrap(err, "building vulnerable software lookup")
+		}
+		batch := []*kolide.VulnerableSoftware{}
+		if err := d.db.Select(&batch, d.db.Rebind(query), args...); err != nil {
+			return nil, errors.Wrap(err, "listing vulnerable software")
+		}
+		criteria = append(criteria, batch...)
+	}
+	return criteria, nil
+}
+
+func (d *Datastore) ListAllSoftware() ([]*kolide.Software, error) {
+	software := []*kolide.Software{}
+	if err := d.db.Select(&software, "SELECT * FROM software"); err != nil {
+		return nil, errors.Wrap(err, "listing all software")
+	}
+	return software, nil
+}
+
+func (d *Datastore) ReplaceSoftwareVulnerabilities(matches []kolide.SoftwareVulnerability) (err error) {
+	txn, err := d.db.Beginx()
+	if err != nil {
+		return errors.Wrap(err, "replace software vulnerabilities begin transaction")
+	}
+	var success bool
+	defer func() {
+		if success {
+			if err = txn.Commit(); err == nil {
+				return
+			}
+		}
+		txn.Rollback()
+	}()
+
+	if _, err := txn.Exec("DELETE FROM software_vulnerabilities"); err != nil {
+		return errors.Wrap(err, "deleting software vulnerabilities")
+	}
+	if err := insertSoftwareVulnerabilities(txn, matches); err != nil {
+		return err
+	}
+
+	success = true
+	return err
+}
+
+func (d *Datastore) AddSoftwareVulnerabilities(matches []kolide.SoftwareVulnerability) (err error) {
+	txn, err := d.db.Beginx()
+	if err != nil {
+		return errors.Wrap(err, "add software vulnerabilities begin transaction")
+	}
+	var success bool
+	defer func() {
+		if success {
+			if err = txn.Commit(); err == nil {
+				return
+			}
+		}
+		txn.Rollback()
+	}()
+
+	if err := insertSoftwareVulnerabilities(txn, matches); err != nil {
+		return err
+	}
+
+	success = true
+	return err
+}
+
+// insertSoftwareVulnerabilities stores the matches in batches, ignoring those
+// that are already stored.
+func insertSoftwareVulnerabilities(txn *sqlx.Tx, matches []kolide.SoftwareVulnerability) error {
+	for start := 0; start < len(matches); start += vulnerabilityBatchSize {
+		end := start + vulnerabilityBatchSize
+		if end > len(matches) {
+			end = len(matches)
+		}
+		batch := matches[start:end]
+
+		args := []interface{}{}
+		for _, m := range batch {
+			args = append(args, m.SoftwareID, m.CVE)
+		}
+		values := strings.TrimSuffix(strings.Repeat("(?, ?),", len(batch)), ",")
+		_, err := txn.Exec("INSERT OR IGNORE INTO software_vulnerabilities (software_id, cve) VALUES "+values, args...)
+		if err != nil {
+			return errors.Wrap(err, "inserting software vulnerabilities")
+		}
+	}
+	return nil
+}
+
+func (d *Datastore) ListVulnerabilities(opt kolide.ListOptions) ([]*kolide.VulnerabilityCount, error) {
+	sqlStatement := `
+		SELECT v.*, COUNT(DISTINCT hs.host_id) AS host_count
+		FROM vulnerabilities v
+		JOIN software_vulnerabilities sv
+			ON sv.cve = v.cve
+		JOIN host_software hs
+			ON hs.software_id = sv.software_id
+		JOIN hosts h
+			ON hs.host_id = h.id
+		WHERE NOT h.deleted
+		GROUP BY v.cve
+	`
+	if opt.OrderKey == "" {
+		sqlStatement += " ORDER BY v.cvss_score DESC, v.cve"
+	}
+	sqlStatement = appendListOptionsToSQL(sqlStatement, opt)
+
+	vulns := []*kolide.VulnerabilityCount{}
+	if err := d.db.Select(&vulns, sqlStatement); err != nil {
+		return nil, errors.Wrap(err, "listing vulnerabilities")
+	}
+	return vulns, nil
+}
+
+func (d *Datastore) ListVulnerableHosts(cve string, opt kolide.ListOptions) ([]*kolide.VulnerableHost, error) {
+	sqlStatement := `
+		SELECT
+			h.id AS host_id,
+			h.host_name,
+			s.id AS "software.id",
+			s.name AS "software.name",
+			s.version AS "software.version",
+			s.source AS "software.source"
+		FROM software_vulnerabilities sv
+		JOIN software s
+			ON sv.software_id = s.id
+		JOIN host_software hs
+			ON hs.software_id = s.id
+		JOIN hosts h
+			ON hs.host_id = h.id
+		WHERE sv.cve = ?
+		AND NOT h.deleted
+	`
+	if opt.OrderKey == "" {
+		sqlStatement += " ORDER BY h.host_name, h.id, s.name, s.version"
+	}
+	sqlStatement = appendListOptionsToSQL(sqlStatement, opt)
+
+	hosts := []*kolide.VulnerableHost{}
+	if err := d.db.Select(&hosts, sqlStatement, cve); err != nil {
+		return nil, errors.Wrap(err, "listing vulnerable hosts")
+	}
+	return hosts, nil
+}
+
+func (d *Datastore) ListHostVulnerabilities(hostID uint, opt kolide.ListOptions) ([]*kolide.HostVulnerability, error) {
+	sqlStatement := `
+		SELECT
+			v.*,
+			s.id AS "software.id",
+			s.name AS "software.name",
+			s.version AS "software.version",
+			s.source AS "software.source"
+		FROM host_software hs
+		JOIN software s
+			ON hs.software_id = s.id
+		JOIN software_vulnerabilities sv
+			ON sv.software_id = s.id
+		JOIN vulnerabilities v
+			ON sv.cve = v.cve
+		WHERE hs.host_id = ?
+	`
+	if opt.OrderKey == "" {
+		sqlStatement += " ORDER BY v.cvss_score DESC, v.cve, s.name, s.version"
+	}
+	sqlStatement = appendListOptionsToSQL(sqlStatement, opt)
+
+	vulns := []*kolide.HostVulnerability{}
+	if err := d.db.Select(&vulns, sqlStatement, hostID); err != nil {
+		return nil, errors.Wrap(err, "listing host vulnerabilities")
+	}
+	return vulns, nil
+}
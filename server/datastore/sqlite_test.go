@@ -0,0 +1,35 @@
+package datastore
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/WatchBeam/clock"
+	"github.com/go-kit/kit/log"
+	"github.com/kolide/kolide-ose/server/config"
+	"github.com/kolide/kolide-ose/server/datastore/sqlite"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSQLite(t *testing.T) {
+	dir, err := ioutil.TempDir("", "kolide-sqlite")
+	require.Nil(t, err)
+	defer os.RemoveAll(dir)
+
+	config := config.SqliteConfig{Path: filepath.Join(dir, "kolide.db")}
+	ds, err := sqlite.New(config, clock.NewMockClock(), sqlite.Logger(log.NewNopLogger()))
+	require.Nil(t, err)
+	defer ds.Close()
+
+	for _, f := range testFunctions {
+
+		t.Run(functionName(f), func(t *testing.T) {
+			defer func() { require.Nil(t, ds.Drop()) }()
+			require.Nil(t, ds.MigrateTables())
+			f(t, ds)
+		})
+	}
+
+}
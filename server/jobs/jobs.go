@@ -0,0 +1,142 @@
+// Package jobs runs the background jobs of the Kolide server. Several
+// servers may share a datastore, and each run of a job happens on only one
+// of them, whichever takes the lock of the job in the datastore first.
+package jobs
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/WatchBeam/clock"
+	kitlog "github.com/go-kit/kit/log"
+	"github.com/kolide/kolide-ose/server/kolide"
+	"golang.org/x/net/context"
+)
+
+// maxPollInterval is the longest that a server waits between attempts to
+// take the lock of a job. A job runs within this long of its interval
+// passing, whichever server runs it.
+const maxPollInterval = time.Minute
+
+// Func is the work of a job.
+type Func func(ctx context.Context) error
+
+type job struct {
+	name     string
+	interval time.Duration
+	run      Func
+}
+
+// Scheduler runs the jobs registered with it every interval, on one of the
+// servers running a scheduler for the jobs.
+type Scheduler struct {
+	ds       kolide.JobStore
+	instance string
+	logger   kitlog.Logger
+	clock    clock.Clock
+	jobs     []job
+}
+
+// NewScheduler creates a scheduler for the server named instance, which
+// must be unique among the servers sharing the datastore.
+func NewScheduler(ds kolide.JobStore, instance string, logger kitlog.Logger, c clock.Clock) *Scheduler {
+	return &Scheduler{
+		ds:       ds,
+		instance: instance,
+		logger:   logger,
+		clock:    c,
+	}
+}
+
+// Register adds a job, which runs once every interval across all of the
+// servers. A run that takes longer than the interval may overlap with the
+// next run on another server. The job is stored with its interval right
+// away, so that it is listed before it first runs.
+func (s *Scheduler) Register(name string, interval time.Duration, run Func) error {
+	if err := s.ds.RegisterJob(name, interval); err != nil {
+		return err
+	}
+	s.jobs = append(s.jobs, job{name: name, interval: interval, run: run})
+	return nil
+}
+
+// Run runs the registered jobs until ctx is done, and then waits for the
+// running jobs to return.
+func (s *Scheduler) Run(ctx context.Context) {
+	var wg sync.WaitGroup
+	for _, j := range s.jobs {
+		wg.Add(1)
+		go func(j job) {
+			defer wg.Done()
+			poll := j.interval
+			if poll > maxPollInterval {
+				poll = maxPollInterval
+			}
+			ticker := time.NewTicker(poll)
+			defer ticker.Stop()
+			for {
+				s.runJob(ctx, j)
+				select {
+				case <-ctx.Done():
+					return
+				case <-ticker.C:
+				}
+			}
+		}(j)
+	}
+	wg.Wait()
+}
+
+// runJob runs the job if this server takes its lock, and records the result
+// of the run.
+func (s *Scheduler) runJob(ctx context.Context, j job) {
+	locked, err := s.ds.LockJob(j.name, s.instance, j.interval, s.clock.Now())
+	if err != nil {
+		s.logger.Log("job", j.name, "err", err, "msg", "locking job")
+		return
+	}
+	if !locked {
+		return
+	}
+
+	start := s.clock.Now()
+	err = runRecovered(ctx, j.run)
+	runErr := ""
+	if err != nil {
+		runErr = err.Error()
+	}
+	s.logger.Log("job", j.name, "err", err, "took", s.clock.Now().Sub(start))
+
+	if err := s.ds.FinishJob(j.name, s.instance, s.clock.Now(), runErr); err != nil {
+		s.logger.Log("job", j.name, "err", err, "msg", "recording job run")
+	}
+}
+
+// runRecovered runs the job, returning a panic of the job as an error so
+// that the other jobs keep running.
+func runRecovered(ctx context.Context, run Func) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("panic: %v", r)
+		}
+	}()
+	return run(ctx)
+}
+
+// InstanceName returns a name for this server that is unique among the
+// servers sharing a datastore, made of the hostname and a random suffix.
+func InstanceName() (string, error) {
+	hostname, err := os.Hostname()
+	if err != nil {
+		return "", err
+	}
+	suffix := make([]byte, 4)
+	if _, err := rand.Read(suffix); err != nil {
+		return "", err
+	}
+	return hostname + "-" + hex.EncodeToString(suffix), nil
+}
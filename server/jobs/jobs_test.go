@@ -0,0 +1,87 @@
+package jobs
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/WatchBeam/clock"
+	kitlog "github.com/go-kit/kit/log"
+	"github.com/kolide/kolide-ose/server/config"
+	"github.com/kolide/kolide-ose/server/datastore/inmem"
+	"github.com/kolide/kolide-ose/server/kolide"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/net/context"
+)
+
+func TestRunJobOnOneInstance(t *testing.T) {
+	ds, err := inmem.New(config.TestConfig())
+	require.Nil(t, err)
+	mockClock := clock.NewMockClock()
+	ctx := context.Background()
+
+	runs := map[string]int{}
+	newScheduler := func(instance string) *Scheduler {
+		s := NewScheduler(ds, instance, kitlog.NewNopLogger(), mockClock)
+		require.Nil(t, s.Register("cleanup", time.Hour, func(ctx context.Context) error {
+			runs[instance]++
+			return nil
+		}))
+		return s
+	}
+	a, b := newScheduler("a"), newScheduler("b")
+
+	// Registered jobs are listed before they first run
+	jobs, err := ds.ListJobs()
+	require.Nil(t, err)
+	require.Len(t, jobs, 1)
+	assert.Equal(t, time.Hour, jobs[0].Interval)
+	assert.Equal(t, kolide.JobStatusNeverRun, jobs[0].Status())
+
+	a.runJob(ctx, a.jobs[0])
+	b.runJob(ctx, b.jobs[0])
+	assert.Equal(t, map[string]int{"a": 1}, runs)
+
+	mockClock.AddTime(30 * time.Minute)
+	a.runJob(ctx, a.jobs[0])
+	b.runJob(ctx, b.jobs[0])
+	assert.Equal(t, map[string]int{"a": 1}, runs)
+
+	mockClock.AddTime(30 * time.Minute)
+	b.runJob(ctx, b.jobs[0])
+	a.runJob(ctx, a.jobs[0])
+	assert.Equal(t, map[string]int{"a": 1, "b": 1}, runs)
+
+	jobs, err = ds.ListJobs()
+	require.Nil(t, err)
+	require.Len(t, jobs, 1)
+	assert.Equal(t, "b", jobs[0].LastInstance)
+	assert.Equal(t, kolide.JobStatusSucceeded, jobs[0].Status())
+}
+
+func TestRunJobErrors(t *testing.T) {
+	ds, err := inmem.New(config.TestConfig())
+	require.Nil(t, err)
+	mockClock := clock.NewMockClock()
+	ctx := context.Background()
+
+	s := NewScheduler(ds, "a", kitlog.NewNopLogger(), mockClock)
+	require.Nil(t, s.Register("failing", time.Hour, func(ctx context.Context) error {
+		return errors.New("no luck")
+	}))
+	require.Nil(t, s.Register("panicking", time.Hour, func(ctx context.Context) error {
+		panic("oops")
+	}))
+	for _, j := range s.jobs {
+		s.runJob(ctx, j)
+	}
+
+	jobs, err := ds.ListJobs()
+	require.Nil(t, err)
+	require.Len(t, jobs, 2)
+	assert.Equal(t, kolide.JobStatusFailed, jobs[0].Status())
+	assert.Equal(t, "no luck", jobs[0].LastError)
+	assert.Equal(t, kolide.JobStatusFailed, jobs[1].Status())
+	assert.Equal(t, "panic: oops", jobs[1].LastError)
+}
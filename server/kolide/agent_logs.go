@@ -0,0 +1,116 @@
+package kolide
+
+import (
+	"strconv"
+	"time"
+
+	"golang.org/x/net/context"
+)
+
+// AgentLogStore stores the warnings and errors that osqueryd reports in its
+// status logs.
+type AgentLogStore interface {
+	// NewAgentLogs counts the logs of a host by severity and stores those
+	// of warning severity and above. Only the most recent retain logs of
+	// each host are kept.
+	NewAgentLogs(hostID uint, logs []*AgentLog, retain int) error
+	// ListAgentLogs returns the stored logs of a host, newest first.
+	ListAgentLogs(hostID uint, opt ListOptions) ([]*AgentLog, error)
+	// AgentLogCounts returns the number of logs a host has reported for
+	// each severity, including logs that are no longer stored.
+	AgentLogCounts(hostID uint) (AgentLogCounts, error)
+	// ListAgentLogSummaries groups the stored logs of all hosts by source
+	// location and message, most frequent first. An empty severity
+	// includes every stored severity.
+	ListAgentLogSummaries(severity string, limit uint) ([]*AgentLogSummary, error)
+}
+
+// AgentLogService exposes the health of the osquery agents as reported in
+// their status logs.
+type AgentLogService interface {
+	// ListHostAgentLogs returns the stored logs of a host, newest first,
+	// along with its log counts.
+	ListHostAgentLogs(ctx context.Context, hostID uint, opt ListOptions) ([]*AgentLog, AgentLogCounts, error)
+	// ListAgentLogSummaries returns the most frequent logs across all hosts.
+	ListAgentLogSummaries(ctx context.Context, severity string, limit uint) ([]*AgentLogSummary, error)
+}
+
+// Severities of osquery status logs
+const (
+	AgentLogSeverityInfo    = "info"
+	AgentLogSeverityWarning = "warning"
+	AgentLogSeverityError   = "error"
+	AgentLogSeverityFatal   = "fatal"
+)
+
+// agentLogSeverities maps the numeric severities of glog, which osqueryd
+// uses for its status logs, to names.
+var agentLogSeverities = map[string]string{
+	"0": AgentLogSeverityInfo,
+	"1": AgentLogSeverityWarning,
+	"2": AgentLogSeverityError,
+	"3": AgentLogSeverityFatal,
+}
+
+// AgentLogSeverity returns the name of an osquery status log severity.
+// Unknown severities are treated as errors.
+func AgentLogSeverity(severity string) string {
+	if name, ok := agentLogSeverities[severity]; ok {
+		return name
+	}
+	for _, name := range agentLogSeverities {
+		if severity == name {
+			return name
+		}
+	}
+	return AgentLogSeverityError
+}
+
+// AgentLogSeverityStored returns whether logs of the severity are stored,
+// rather than only counted.
+func AgentLogSeverityStored(severity string) bool {
+	return severity != AgentLogSeverityInfo
+}
+
+// AgentLog is a warning or error reported in the status logs of a host.
+type AgentLog struct {
+	ID        uint      `json:"id"`
+	HostID    uint      `json:"host_id" db:"host_id"`
+	CreatedAt time.Time `json:"created_at" db:"created_at"`
+	Severity  string    `json:"severity"`
+	Filename  string    `json:"filename"`
+	Line      uint      `json:"line"`
+	Message   string    `json:"message"`
+	// Version is the version of osqueryd that reported the log.
+	Version string `json:"version"`
+}
+
+// NewAgentLog converts a status log received from osqueryd.
+func NewAgentLog(hostID uint, log OsqueryStatusLog, now time.Time) *AgentLog {
+	line, _ := strconv.ParseUint(log.Line, 10, 32)
+	return &AgentLog{
+		HostID:    hostID,
+		CreatedAt: now,
+		Severity:  AgentLogSeverity(log.Severity),
+		Filename:  log.Filename,
+		Line:      uint(line),
+		Message:   log.Message,
+		Version:   log.Version,
+	}
+}
+
+// AgentLogCounts is the number of logs reported for each severity.
+type AgentLogCounts map[string]uint
+
+// AgentLogSummary counts the stored logs with the same severity, source
+// location and message.
+type AgentLogSummary struct {
+	Severity  string `json:"severity"`
+	Filename  string `json:"filename"`
+	Line      uint   `json:"line"`
+	Message   string `json:"message"`
+	Count     uint   `json:"count"`
+	HostCount uint   `json:"host_count" db:"host_count"`
+	// LastSeen is when the log was last reported by any host.
+	LastSeen time.Time `json:"last_seen" db:"last_seen"`
+}
@@ -0,0 +1,48 @@
+package kolide
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAgentLogSeverity(t *testing.T) {
+	var severities = []struct {
+		in, out string
+	}{
+		{"0", AgentLogSeverityInfo},
+		{"1", AgentLogSeverityWarning},
+		{"2", AgentLogSeverityError},
+		{"3", AgentLogSeverityFatal},
+		{"warning", AgentLogSeverityWarning},
+		{"", AgentLogSeverityError},
+		{"7", AgentLogSeverityError},
+	}
+	for _, tt := range severities {
+		assert.Equal(t, tt.out, AgentLogSeverity(tt.in), "severity %q", tt.in)
+	}
+}
+
+func TestNewAgentLog(t *testing.T) {
+	now := time.Now()
+	log := NewAgentLog(3, OsqueryStatusLog{
+		Severity: "2",
+		Filename: "scheduler.cpp",
+		Line:     "81",
+		Message:  "Error executing scheduled query",
+		Version:  "2.2.1",
+	}, now)
+	assert.Equal(t, &AgentLog{
+		HostID:    3,
+		CreatedAt: now,
+		Severity:  AgentLogSeverityError,
+		Filename:  "scheduler.cpp",
+		Line:      81,
+		Message:   "Error executing scheduled query",
+		Version:   "2.2.1",
+	}, log)
+
+	log = NewAgentLog(3, OsqueryStatusLog{Severity: "1", Line: "not a number"}, now)
+	assert.Equal(t, uint(0), log.Line)
+}
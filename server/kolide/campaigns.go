@@ -25,6 +25,13 @@ type CampaignStore interface {
 	// distributed query campaign
 	NewDistributedQueryCampaignTarget(target *DistributedQueryCampaignTarget) (*DistributedQueryCampaignTarget, error)
 
+	// NewDistributedQueryCampaignHosts records the IDs of the hosts that
+	// the targets of the campaign resolved to when it was created
+	NewDistributedQueryCampaignHosts(campaignID uint, hostIDs []uint) error
+	// DistributedQueryCampaignHostIDs gets the IDs of the hosts recorded
+	// for the query campaign of the provided ID
+	DistributedQueryCampaignHostIDs(id uint) ([]uint, error)
+
 	// NewDistributedQueryCampaignExecution records a new execution for a
 	// distributed query campaign
 	NewDistributedQueryExecution(exec *DistributedQueryExecution) (*DistributedQueryExecution, error)
@@ -49,10 +56,12 @@ type CampaignService interface {
 	NewDistributedQueryCampaign(ctx context.Context, queryString string, hosts []uint, labels []uint) (*DistributedQueryCampaign, error)
 
 	// StreamCampaignResults streams updates with query results and
-	// expected host totals over the provided websocket. Note that the type
+	// expected host totals over the provided websocket. When aggregation
+	// is requested in opts, periodic aggregate updates are streamed as
+	// well, optionally in place of the raw results. Note that the type
 	// signature is somewhat inconsistent due to this being a streaming API
 	// and not the typical go-kit RPC style.
-	StreamCampaignResults(ctx context.Context, conn *websocket.Conn, campaignID uint)
+	StreamCampaignResults(ctx context.Context, conn *websocket.Conn, campaignID uint, opts CampaignAggregateOptions)
 }
 
 // DistributedQueryStatus is the lifecycle status of a distributed query
@@ -120,3 +129,53 @@ type DistributedQueryExecution struct {
 	Error                      string
 	ExecutionDuration          time.Duration `db:"execution_duration"`
 }
+
+// CampaignAggregateOptions configures the server-side aggregation of results
+// streamed for a distributed query campaign.
+type CampaignAggregateOptions struct {
+	// GroupBy is the list of result columns that rows are grouped by.
+	// Aggregation is disabled when GroupBy is empty.
+	GroupBy []string
+	// TopN is the number of most frequent values included in each
+	// aggregate update.
+	TopN int
+	// OmitRows disables streaming of the raw result rows, so that only
+	// aggregate updates are sent.
+	OmitRows bool
+}
+
+// Enabled returns true if aggregation was requested.
+func (o CampaignAggregateOptions) Enabled() bool {
+	return len(o.GroupBy) > 0
+}
+
+// CampaignAggregate is a snapshot of the aggregated results received so far
+// for a distributed query campaign.
+type CampaignAggregate struct {
+	GroupBy []string `json:"group_by"`
+	// HostsResponded is the number of distinct hosts that returned
+	// results, including hosts that reported an error.
+	HostsResponded uint `json:"hosts_responded"`
+	// HostsFailed is the number of distinct hosts that reported an error.
+	HostsFailed uint `json:"hosts_failed"`
+	// TotalRows is the number of rows received across all hosts.
+	TotalRows uint `json:"total_rows"`
+	// DistinctValues is the number of distinct value combinations seen for
+	// the GroupBy columns.
+	DistinctValues uint `json:"distinct_values"`
+	// Truncated is set when more distinct value combinations were seen
+	// than are tracked, in which case DistinctValues and Top only cover the
+	// combinations seen first.
+	Truncated bool `json:"truncated"`
+	// Top contains the most frequent value combinations, ordered by the
+	// number of hosts that reported them.
+	Top []CampaignAggregateValue `json:"top"`
+}
+
+// CampaignAggregateValue is the count of hosts and rows for a single
+// combination of values of the GroupBy columns.
+type CampaignAggregateValue struct {
+	Values map[string]string `json:"values"`
+	Hosts  uint              `json:"hosts"`
+	Rows   uint              `json:"rows"`
+}
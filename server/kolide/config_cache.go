@@ -0,0 +1,18 @@
+package kolide
+
+// OsqueryConfigCache caches the osquery configs generated for each set of
+// packs, so that the hosts that resolve to the same packs share a config
+// instead of each having it generated from the datastore.
+type OsqueryConfigCache interface {
+	// Get returns the cached config of the packs, or nil when none is
+	// cached, along with the generation of the cache. Configs are shared,
+	// so the caller must not modify the returned config.
+	Get(packIDs []uint) (config *OsqueryConfig, generation int64, err error)
+	// Set caches the config of the packs. The generation is the one
+	// returned by Get before the config was generated, and the config is
+	// not used if the cache was invalidated since.
+	Set(packIDs []uint, generation int64, config *OsqueryConfig) error
+	// Invalidate discards every cached config. It is called whenever packs,
+	// scheduled queries, options, labels or pack targets change.
+	Invalidate() error
+}
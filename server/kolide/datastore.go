@@ -8,6 +8,7 @@ type Datastore interface {
 	PackStore
 	LabelStore
 	HostStore
+	TargetStore
 	PasswordResetStore
 	SessionStore
 	AppConfigStore
@@ -17,6 +18,15 @@ type Datastore interface {
 	DecoratorStore
 	FileIntegrityMonitoringStore
 	YARAStore
+	RevisionStore
+	AgentLogStore
+	DetailQueryStore
+	SoftwareStore
+	VulnerabilityStore
+	HostPurgeStore
+	HostBreakdownStore
+	MigrationStore
+	JobStore
 	Name() string
 	Drop() error
 	// MigrateTables creates and migrates the table schemas
@@ -0,0 +1,349 @@
+package kolide
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"golang.org/x/net/context"
+)
+
+// DetailQueryStore stores the detail queries defined by admins, and the host
+// attributes that their results are kept as.
+type DetailQueryStore interface {
+	NewDetailQuery(query *DetailQuery) (*DetailQuery, error)
+	SaveDetailQuery(query *DetailQuery) error
+	// DeleteDetailQuery removes a detail query along with the host
+	// attributes it produced.
+	DeleteDetailQuery(id uint) error
+	DetailQuery(id uint) (*DetailQuery, error)
+	ListDetailQueries(opt ListOptions) ([]*DetailQuery, error)
+
+	// DetailQueriesForHost returns the detail queries that apply to the
+	// platform of the host and have not run on it within their interval.
+	// The result map is a mapping from detail query ID to query text.
+	DetailQueriesForHost(host *Host, now time.Time) (map[uint]string, error)
+	// RecordHostAttributes replaces the attributes of a host produced by a
+	// detail query, and records that the query ran at the provided time.
+	RecordHostAttributes(hostID, detailQueryID uint, attributes []HostAttribute, updated time.Time) error
+	// HostAttributes returns the attributes of a host, ordered by key.
+	HostAttributes(hostID uint) ([]HostAttribute, error)
+}
+
+// DetailQueryService manages the detail queries that admins define in
+// addition to the built in ones.
+type DetailQueryService interface {
+	ListDetailQueries(ctx context.Context, opt ListOptions) ([]*DetailQuery, error)
+	GetDetailQuery(ctx context.Context, id uint) (*DetailQuery, error)
+	NewDetailQuery(ctx context.Context, p DetailQueryPayload) (*DetailQuery, error)
+	ModifyDetailQuery(ctx context.Context, id uint, p DetailQueryPayload) (*DetailQuery, error)
+	DeleteDetailQuery(ctx context.Context, id uint) error
+}
+
+// DetailQuery is a query run periodically on hosts, the first row of which
+// is stored as attributes of the host.
+type DetailQuery struct {
+	UpdateCreateTimestamps
+	ID uint `json:"id"`
+	// Name prefixes the keys of the attributes produced by the query.
+	Name  string `json:"name"`
+	Query string `json:"query"`
+	// Platform is a comma separated list of the host platforms that the
+	// query runs on. An empty platform runs the query on every host.
+	Platform string `json:"platform"`
+	// Interval is the number of seconds between runs of the query.
+	Interval uint `json:"interval"`
+	// Columns are the columns of the results that are kept.
+	Columns DetailQueryColumns `json:"columns"`
+}
+
+// DetailQueryPayload is used to create and modify detail queries.
+type DetailQueryPayload struct {
+	Name     *string             `json:"name"`
+	Query    *string             `json:"query"`
+	Platform *string             `json:"platform"`
+	Interval *uint               `json:"interval"`
+	Columns  *DetailQueryColumns `json:"columns"`
+}
+
+// Types of the values of host attributes
+const (
+	HostAttributeString  = "string"
+	HostAttributeInteger = "integer"
+	HostAttributeFloat   = "float"
+	HostAttributeBoolean = "boolean"
+)
+
+// ValidHostAttributeType returns whether the type is one of the above.
+func ValidHostAttributeType(t string) bool {
+	switch t {
+	case HostAttributeString, HostAttributeInteger, HostAttributeFloat, HostAttributeBoolean:
+		return true
+	}
+	return false
+}
+
+// DetailQueryColumn is a column of the results of a detail query that is kept
+// as a host attribute of the given type.
+type DetailQueryColumn struct {
+	Name string `json:"name"`
+	Type string `json:"type"`
+}
+
+// DetailQueryColumns supports the Valuer and Scanner interfaces so that the
+// columns of a detail query can be stored as JSON.
+type DetailQueryColumns []DetailQueryColumn
+
+// Value is called by the DB driver. Columns are stored as JSON.
+func (c DetailQueryColumns) Value() (driver.Value, error) {
+	if c == nil {
+		c = DetailQueryColumns{}
+	}
+	return json.Marshal(c)
+}
+
+// Scan reads columns stored as JSON.
+func (c *DetailQueryColumns) Scan(src interface{}) error {
+	return json.Unmarshal(src.([]byte), c)
+}
+
+// RunsOnPlatform returns whether the query should run on hosts of the
+// platform.
+func (q *DetailQuery) RunsOnPlatform(platform string) bool {
+	if q.Platform == "" {
+		return true
+	}
+	for _, p := range strings.Split(q.Platform, ",") {
+		if p == platform {
+			return true
+		}
+	}
+	return false
+}
+
+// HostAttributes converts the first row of the results of the query to host
+// attributes. Columns that are missing from the results, or whose values do
+// not convert to the type of the column, are left out.
+func (q *DetailQuery) HostAttributes(hostID uint, rows []map[string]string, now time.Time) []HostAttribute {
+	attributes := []HostAttribute{}
+	if len(rows) == 0 {
+		return attributes
+	}
+	for _, column := range q.Columns {
+		raw, ok := rows[0][column.Name]
+		if !ok {
+			continue
+		}
+		value, ok := normalizeHostAttributeValue(column.Type, raw)
+		if !ok {
+			continue
+		}
+		attributes = append(attributes, HostAttribute{
+			HostID:        hostID,
+			DetailQueryID: q.ID,
+			Key:           q.Name + "." + column.Name,
+			Type:          column.Type,
+			Value:         value,
+			UpdatedAt:     now,
+		})
+	}
+	return attributes
+}
+
+// normalizeHostAttributeValue converts a value reported by osquery to the
+// form stored for the type. Booleans are stored as 1 or 0 so that they
+// compare like integers.
+func normalizeHostAttributeValue(t, value string) (string, bool) {
+	switch t {
+	case HostAttributeInteger:
+		i, err := strconv.ParseInt(strings.TrimSpace(value), 10, 64)
+		if err != nil {
+			return "", false
+		}
+		return strconv.FormatInt(i, 10), true
+	case HostAttributeFloat:
+		f, err := strconv.ParseFloat(strings.TrimSpace(value), 64)
+		if err != nil {
+			return "", false
+		}
+		return strconv.FormatFloat(f, 'f', -1, 64), true
+	case HostAttributeBoolean:
+		b, err := strconv.ParseBool(strings.TrimSpace(value))
+		if err != nil {
+			return "", false
+		}
+		if b {
+			return "1", true
+		}
+		return "0", true
+	}
+	return value, true
+}
+
+// HostAttribute is a value reported by a detail query for a host.
+type HostAttribute struct {
+	HostID        uint `json:"-" db:"host_id"`
+	DetailQueryID uint `json:"detail_query_id" db:"detail_query_id"`
+	// Key is the name of the detail query and the column, separated by a
+	// dot.
+	Key  string `json:"key"`
+	Type string `json:"type"`
+	// Value is the value in its stored form. It is marshaled to JSON with
+	// the type of the attribute.
+	Value     string    `json:"value"`
+	UpdatedAt time.Time `json:"updated_at" db:"updated_at"`
+}
+
+// TypedValue returns the value of the attribute as a Go value of its type.
+func (a HostAttribute) TypedValue() interface{} {
+	switch a.Type {
+	case HostAttributeInteger:
+		if i, err := strconv.ParseInt(a.Value, 10, 64); err == nil {
+			return i
+		}
+	case HostAttributeFloat:
+		if f, err := strconv.ParseFloat(a.Value, 64); err == nil {
+			return f
+		}
+	case HostAttributeBoolean:
+		return a.Value == "1"
+	}
+	return a.Value
+}
+
+// MarshalJSON marshals the value of the attribute with its type.
+func (a HostAttribute) MarshalJSON() ([]byte, error) {
+	type attribute HostAttribute
+	return json.Marshal(struct {
+		attribute
+		Value interface{} `json:"value"`
+	}{
+		attribute: attribute(a),
+		Value:     a.TypedValue(),
+	})
+}
+
+// numeric returns whether values of the attribute compare as numbers.
+func (a HostAttribute) numeric() bool {
+	return a.Type == HostAttributeInteger || a.Type == HostAttributeFloat || a.Type == HostAttributeBoolean
+}
+
+// Operators that host attributes can be compared with
+var HostAttributeOperators = []string{"<=", ">=", "!=", "=", "<", ">"}
+
+// HostAttributeFilter selects hosts with an attribute that compares to the
+// value with the operator. Integer, float and boolean attributes compare
+// numerically, and never match a value that is not a number. String
+// attributes compare lexically.
+type HostAttributeFilter struct {
+	Key      string `json:"key"`
+	Operator string `json:"operator"`
+	// Value is the value to compare to. The values true and false are
+	// accepted for boolean attributes.
+	Value string `json:"value"`
+}
+
+// ParseHostAttributeFilter parses a filter written as the key, operator and
+// value, such as "disk_encryption.encrypted=true".
+func ParseHostAttributeFilter(s string) (HostAttributeFilter, error) {
+	i := strings.IndexAny(s, "<>!=")
+	if i <= 0 {
+		return HostAttributeFilter{}, fmt.Errorf("attribute filter %q must be of the form key<operator>value", s)
+	}
+	filter := HostAttributeFilter{Key: strings.TrimSpace(s[:i])}
+	for _, op := range HostAttributeOperators {
+		if strings.HasPrefix(s[i:], op) {
+			filter.Operator = op
+			filter.Value = strings.TrimSpace(s[i+len(op):])
+			break
+		}
+	}
+	if filter.Operator == "" {
+		return HostAttributeFilter{}, fmt.Errorf("attribute filter %q has an unknown operator", s)
+	}
+	return filter, nil
+}
+
+// String formats the filter as accepted by ParseHostAttributeFilter.
+func (f HostAttributeFilter) String() string {
+	return f.Key + f.Operator + f.Value
+}
+
+// ValidOperator returns whether the operator of the filter is supported.
+func (f HostAttributeFilter) ValidOperator() bool {
+	for _, op := range HostAttributeOperators {
+		if f.Operator == op {
+			return true
+		}
+	}
+	return false
+}
+
+// NumericValue returns the value of the filter as a number, for comparison
+// with numeric attributes.
+func (f HostAttributeFilter) NumericValue() (float64, bool) {
+	switch strings.ToLower(f.Value) {
+	case "true":
+		return 1, true
+	case "false":
+		return 0, true
+	}
+	n, err := strconv.ParseFloat(f.Value, 64)
+	return n, err == nil
+}
+
+// Matches returns whether the attribute satisfies the filter.
+func (f HostAttributeFilter) Matches(a HostAttribute) bool {
+	if a.Key != f.Key {
+		return false
+	}
+
+	var cmp int
+	if a.numeric() {
+		want, ok := f.NumericValue()
+		if !ok {
+			return false
+		}
+		have, err := strconv.ParseFloat(a.Value, 64)
+		if err != nil {
+			return false
+		}
+		switch {
+		case have < want:
+			cmp = -1
+		case have > want:
+			cmp = 1
+		}
+	} else {
+		cmp = strings.Compare(a.Value, f.Value)
+	}
+
+	switch f.Operator {
+	case "=":
+		return cmp == 0
+	case "!=":
+		return cmp != 0
+	case "<":
+		return cmp < 0
+	case "<=":
+		return cmp <= 0
+	case ">":
+		return cmp > 0
+	case ">=":
+		return cmp >= 0
+	}
+	return false
+}
+
+// MatchesAny returns whether any of the attributes satisfies the filter.
+func (f HostAttributeFilter) MatchesAny(attributes []HostAttribute) bool {
+	for _, a := range attributes {
+		if f.Matches(a) {
+			return true
+		}
+	}
+	return false
+}
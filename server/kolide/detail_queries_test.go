@@ -0,0 +1,115 @@
+package kolide
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseHostAttributeFilter(t *testing.T) {
+	var tests = []struct {
+		in  string
+		out HostAttributeFilter
+		err bool
+	}{
+		{"disk.encrypted=true", HostAttributeFilter{"disk.encrypted", "=", "true"}, false},
+		{"disk.free_gb >= 10", HostAttributeFilter{"disk.free_gb", ">=", "10"}, false},
+		{"a.b!=c=d", HostAttributeFilter{"a.b", "!=", "c=d"}, false},
+		{"a.b<", HostAttributeFilter{"a.b", "<", ""}, false},
+		{"=1", HostAttributeFilter{}, true},
+		{"a.b!1", HostAttributeFilter{}, true},
+		{"a.b", HostAttributeFilter{}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			filter, err := ParseHostAttributeFilter(tt.in)
+			if tt.err {
+				assert.NotNil(t, err)
+				return
+			}
+			require.Nil(t, err)
+			assert.Equal(t, tt.out, filter)
+			assert.True(t, filter.ValidOperator())
+		})
+	}
+}
+
+func TestHostAttributeFilterMatches(t *testing.T) {
+	integer := HostAttribute{Key: "disk.free_gb", Type: HostAttributeInteger, Value: "9"}
+	boolean := HostAttribute{Key: "disk.encrypted", Type: HostAttributeBoolean, Value: "1"}
+	str := HostAttribute{Key: "kernel.version", Type: HostAttributeString, Value: "4.4.0"}
+
+	var tests = []struct {
+		filter    string
+		attribute HostAttribute
+		matches   bool
+	}{
+		{"disk.free_gb<10", integer, true},
+		{"disk.free_gb>10", integer, false},
+		{"disk.free_gb=9.0", integer, true},
+		{"disk.free_gb!=abc", integer, false},
+		{"disk.encrypted=true", boolean, true},
+		{"disk.encrypted=false", boolean, false},
+		{"disk.encrypted!=0", boolean, true},
+		{"kernel.version=4.4.0", str, true},
+		{"kernel.version<4.10", str, false},
+		{"kernel.version>=4", str, true},
+		{"other.version>=4", str, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.filter, func(t *testing.T) {
+			filter, err := ParseHostAttributeFilter(tt.filter)
+			require.Nil(t, err)
+			assert.Equal(t, tt.matches, filter.Matches(tt.attribute))
+		})
+	}
+}
+
+func TestDetailQueryHostAttributes(t *testing.T) {
+	now := time.Now()
+	query := &DetailQuery{
+		ID:   3,
+		Name: "disk",
+		Columns: DetailQueryColumns{
+			{Name: "encrypted", Type: HostAttributeBoolean},
+			{Name: "free_gb", Type: HostAttributeInteger},
+			{Name: "ratio", Type: HostAttributeFloat},
+			{Name: "name", Type: HostAttributeString},
+			{Name: "missing", Type: HostAttributeString},
+		},
+	}
+
+	assert.Empty(t, query.HostAttributes(1, nil, now))
+
+	attributes := query.HostAttributes(1, []map[string]string{
+		{"encrypted": "1", "free_gb": "", "ratio": "0.50", "name": "disk0", "extra": "x"},
+		{"encrypted": "0", "free_gb": "3", "ratio": "1", "name": "disk1"},
+	}, now)
+	assert.Equal(t, []HostAttribute{
+		{HostID: 1, DetailQueryID: 3, Key: "disk.encrypted", Type: HostAttributeBoolean, Value: "1", UpdatedAt: now},
+		{HostID: 1, DetailQueryID: 3, Key: "disk.ratio", Type: HostAttributeFloat, Value: "0.5", UpdatedAt: now},
+		{HostID: 1, DetailQueryID: 3, Key: "disk.name", Type: HostAttributeString, Value: "disk0", UpdatedAt: now},
+	}, attributes)
+
+	data, err := json.Marshal(attributes[:2])
+	require.Nil(t, err)
+	var decoded []map[string]interface{}
+	require.Nil(t, json.Unmarshal(data, &decoded))
+	assert.Equal(t, true, decoded[0]["value"])
+	assert.Equal(t, 0.5, decoded[1]["value"])
+	assert.Equal(t, "disk.ratio", decoded[1]["key"])
+	assert.NotContains(t, decoded[0], "HostID")
+}
+
+func TestDetailQueryRunsOnPlatform(t *testing.T) {
+	query := &DetailQuery{}
+	assert.True(t, query.RunsOnPlatform("darwin"))
+	query.Platform = "ubuntu,centos"
+	assert.True(t, query.RunsOnPlatform("centos"))
+	assert.False(t, query.RunsOnPlatform("darwin"))
+}
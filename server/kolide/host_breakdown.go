@@ -0,0 +1,97 @@
+package kolide
+
+import (
+	"fmt"
+	"sort"
+
+	"golang.org/x/net/context"
+)
+
+// HostBreakdownStore counts hosts grouped by their inventory.
+type HostBreakdownStore interface {
+	// HostBreakdown returns the number of hosts grouped by each of the
+	// inventory fields. Only members of the label are counted, unless
+	// labelID is zero.
+	HostBreakdown(labelID uint) (*HostBreakdown, error)
+}
+
+// HostBreakdownService counts hosts grouped by their inventory.
+type HostBreakdownService interface {
+	// GetHostBreakdown returns the number of hosts grouped by each of the
+	// inventory fields. Only members of the label are counted, unless
+	// labelID is zero.
+	GetHostBreakdown(ctx context.Context, labelID uint) (breakdown *HostBreakdown, err error)
+}
+
+// HostBreakdown is the number of hosts with each value of the inventory
+// fields. The counts of each field are ordered by descending count and then
+// by value.
+type HostBreakdown struct {
+	TotalCount      uint          `json:"total_count"`
+	Platforms       []*HostCount  `json:"platforms"`
+	OSVersions      []*HostCount  `json:"os_versions"`
+	OsqueryVersions []*HostCount  `json:"osquery_versions"`
+	HardwareVendors []*HostCount  `json:"hardware_vendors"`
+	HardwareModels  []*ModelCount `json:"hardware_models"`
+	CPUBrands       []*HostCount  `json:"cpu_brands"`
+	// Memory counts hosts by the MemoryBucket of their physical memory.
+	Memory []*HostCount `json:"memory"`
+}
+
+// HostCount is the number of hosts with a value of an inventory field.
+type HostCount struct {
+	Value string `json:"value" db:"value"`
+	Count uint   `json:"count" db:"count"`
+}
+
+// ModelCount is the number of hosts of a hardware model. Models are counted
+// per vendor, as models of different vendors may share a name.
+type ModelCount struct {
+	Vendor string `json:"vendor" db:"vendor"`
+	Model  string `json:"model" db:"model"`
+	Count  uint   `json:"count" db:"count"`
+}
+
+// memoryBuckets are the upper bounds, in GB, of the buckets that hosts are
+// counted in by physical memory.
+var memoryBuckets = []int{1, 2, 4, 8, 16, 32, 64, 128, 256}
+
+// MemoryBucket returns the name of the bucket that hosts with the physical
+// memory, in bytes, are counted in. Buckets are named by the largest amount
+// of memory in them, so a host with 6GB of memory is in the "8GB" bucket.
+func MemoryBucket(bytes int) string {
+	if bytes <= 0 {
+		return "unknown"
+	}
+	const gb = 1 << 30
+	for _, bucket := range memoryBuckets {
+		if bytes <= bucket*gb {
+			return fmt.Sprintf("%dGB", bucket)
+		}
+	}
+	return fmt.Sprintf(">%dGB", memoryBuckets[len(memoryBuckets)-1])
+}
+
+// SortHostCounts orders the counts by descending count and then by value.
+func SortHostCounts(counts []*HostCount) {
+	sort.Slice(counts, func(i, j int) bool {
+		if counts[i].Count != counts[j].Count {
+			return counts[i].Count > counts[j].Count
+		}
+		return counts[i].Value < counts[j].Value
+	})
+}
+
+// SortModelCounts orders the counts by descending count and then by vendor
+// and model.
+func SortModelCounts(counts []*ModelCount) {
+	sort.Slice(counts, func(i, j int) bool {
+		if counts[i].Count != counts[j].Count {
+			return counts[i].Count > counts[j].Count
+		}
+		if counts[i].Vendor != counts[j].Vendor {
+			return counts[i].Vendor < counts[j].Vendor
+		}
+		return counts[i].Model < counts[j].Model
+	})
+}
@@ -0,0 +1,50 @@
+package kolide
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"time"
+)
+
+// HostConfig is the osquery configuration that a host is served, annotated
+// with why each of its packs applies.
+type HostConfig struct {
+	Config *OsqueryConfig `json:"config"`
+	// Hash is the hash of Config, as returned by OsqueryConfig.Hash.
+	Hash  string            `json:"hash"`
+	Packs []*HostConfigPack `json:"packs"`
+	// LastFetchTime and LastFetchHash are when the host last fetched its
+	// config and the hash of the config it got. LastFetchHash differs from
+	// Hash when the config changed since.
+	LastFetchTime time.Time `json:"last_fetched_at"`
+	LastFetchHash string    `json:"last_fetched_hash"`
+}
+
+// HostConfigPack is a pack in the config of a host along with why it applies
+// to the host.
+type HostConfigPack struct {
+	ID   uint   `json:"id"`
+	Name string `json:"name"`
+	// HostTarget is true when the host is an explicit target of the pack.
+	HostTarget bool `json:"host_target"`
+	// Labels are the targets of the pack that the host is a member of.
+	Labels []HostConfigPackLabel `json:"labels"`
+}
+
+// HostConfigPackLabel is a label that makes a pack apply to a host.
+type HostConfigPackLabel struct {
+	ID   uint   `json:"id"`
+	Name string `json:"name"`
+}
+
+// Hash returns the hex encoded SHA-256 hash of the JSON encoding of the
+// config, which changes whenever the config served to a host does.
+func (c *OsqueryConfig) Hash() (string, error) {
+	encoded, err := json.Marshal(c)
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(encoded)
+	return hex.EncodeToString(sum[:]), nil
+}
@@ -0,0 +1,45 @@
+package kolide
+
+import (
+	"time"
+
+	"golang.org/x/net/context"
+)
+
+// HostPurgeStore permanently deletes hosts that have not been seen for
+// longer than the retention policy allows.
+type HostPurgeStore interface {
+	// ListPurgeableHosts returns the hosts last seen before seenBefore
+	// that are not members of any of the exempt labels, ordered by ID.
+	ListPurgeableHosts(seenBefore time.Time, exemptLabelIDs []uint) ([]*Host, error)
+	// PurgeHosts permanently deletes the hosts that are still last seen
+	// before seenBefore, along with their label query executions, pack
+	// and campaign targets, network interfaces and everything else stored
+	// about them. It returns the number of hosts deleted.
+	PurgeHosts(hostIDs []uint, seenBefore time.Time) (uint, error)
+}
+
+// HostPurgeService applies the host retention policy.
+type HostPurgeService interface {
+	// GetHostPurgeReport returns the hosts that the retention policy would
+	// purge now, without deleting them.
+	GetHostPurgeReport(ctx context.Context) (report *HostPurgeReport, err error)
+	// PurgeHosts deletes the hosts that the retention policy purges now
+	// and returns the report of the deleted hosts.
+	PurgeHosts(ctx context.Context) (report *HostPurgeReport, err error)
+}
+
+// HostPurgeReport lists the hosts that the retention policy purges.
+type HostPurgeReport struct {
+	// Enabled is false when no retention is configured, in which case
+	// hosts are never purged.
+	Enabled bool `json:"enabled"`
+	// Retention is how long hosts are kept after they were last seen.
+	Retention time.Duration `json:"retention"`
+	// SeenBefore is the time that purged hosts were last seen before.
+	SeenBefore time.Time `json:"seen_before"`
+	// ExemptLabels are the names of the labels whose members are never
+	// purged.
+	ExemptLabels []string `json:"exempt_labels"`
+	Hosts        []*Host  `json:"hosts"`
+}
@@ -11,28 +11,68 @@ import (
 const (
 	// StatusOnline host is active
 	StatusOnline string = "online"
-	// StatusOffline no communication with host for OfflineDuration
+	// StatusOffline no communication with host for the offline threshold
 	StatusOffline string = "offline"
-	// StatusMIA no communition with host for MIADuration
+	// StatusMIA no communition with host for the MIA threshold
 	StatusMIA string = "mia"
-	// OfflineDuration if a host hasn't been in communition for this
-	// period it is considered offline
+	// OfflineDuration is the default period that a host can go without
+	// communication before it is considered offline
 	OfflineDuration time.Duration = 30 * time.Minute
-	// OfflineDuration if a host hasn't been in communition for this
-	// period it is considered MIA
+	// MIADuration is the default period that a host can go without
+	// communication before it is considered MIA
 	MIADuration time.Duration = 30 * 24 * time.Hour
 )
 
+// HostStatusThresholds are the periods that hosts can go without
+// communication before they are considered offline or MIA.
+type HostStatusThresholds struct {
+	Offline time.Duration
+	MIA     time.Duration
+	// IntervalGrace, when positive, replaces Offline for hosts that
+	// reported their check in intervals. Those hosts are offline after
+	// their shortest interval multiplied by IntervalGrace.
+	IntervalGrace float64
+}
+
+// DefaultHostStatusThresholds are the thresholds used when none are
+// configured.
+var DefaultHostStatusThresholds = HostStatusThresholds{
+	Offline: OfflineDuration,
+	MIA:     MIADuration,
+}
+
+// OfflineDuration returns the period that the host can go without
+// communication before it is considered offline.
+func (t HostStatusThresholds) OfflineDuration(h *Host) time.Duration {
+	interval := h.CheckInInterval()
+	if t.IntervalGrace <= 0 || interval == 0 {
+		return t.Offline
+	}
+	return time.Duration(float64(interval) * t.IntervalGrace)
+}
+
 type HostStore interface {
 	NewHost(host *Host) (*Host, error)
+	// SaveHost saves the host, except for its seen time, which is only
+	// written by MarkHostSeen and MarkHostsSeen.
 	SaveHost(host *Host) error
 	DeleteHost(hid uint) error
 	Host(id uint) (*Host, error)
-	ListHosts(opt ListOptions) ([]*Host, error)
+	// ListHosts returns the hosts matching the filter.
+	ListHosts(opt ListOptions, filter HostFilter) ([]*Host, error)
+	// CountHosts returns the number of hosts matching the filter.
+	CountHosts(filter HostFilter) (uint, error)
 	EnrollHost(osqueryHostId string, nodeKeySize int) (*Host, error)
 	AuthenticateHost(nodeKey string) (*Host, error)
 	MarkHostSeen(host *Host, t time.Time) error
-	GenerateHostStatusStatistics(now time.Time) (online, offline, mia uint, err error)
+	// MarkHostsSeen sets the seen time of each of the hosts to t, with as
+	// few statements as the datastore allows.
+	MarkHostsSeen(hostIDs []uint, t time.Time) error
+	// MarkHostsConfigFetched records that each of the hosts fetched the
+	// config with the hash at t, with as few statements as the datastore
+	// allows.
+	MarkHostsConfigFetched(hostIDs []uint, hash string, t time.Time) error
+	GenerateHostStatusStatistics(now time.Time, thresholds HostStatusThresholds) (online, offline, mia uint, err error)
 	SearchHosts(query string, omit ...uint) ([]*Host, error)
 	// DistributedQueriesForHost retrieves the distributed queries that the
 	// given host should run. The result map is a mapping from campaign ID
@@ -41,10 +81,20 @@ type HostStore interface {
 }
 
 type HostService interface {
-	ListHosts(ctx context.Context, opt ListOptions) (hosts []*Host, err error)
+	ListHosts(ctx context.Context, opt ListOptions, filter HostFilter) (hosts []*Host, err error)
+	CountHosts(ctx context.Context, filter HostFilter) (count uint, err error)
 	GetHost(ctx context.Context, id uint) (host *Host, err error)
 	GetHostSummary(ctx context.Context) (summary *HostSummary, err error)
+	// HostStatus returns the status of the host using the configured
+	// thresholds.
+	HostStatus(ctx context.Context, host *Host) string
 	DeleteHost(ctx context.Context, id uint) (err error)
+	// GetHostConfig returns the osquery config that the host is served.
+	GetHostConfig(ctx context.Context, id uint) (config *HostConfig, err error)
+	// FlushSeenHosts writes the seen times and config fetches of the hosts
+	// that checked in since the last flush to the datastore, in batches.
+	// Until they are flushed, they are only known to this server.
+	FlushSeenHosts(ctx context.Context) error
 }
 
 type Host struct {
@@ -79,10 +129,48 @@ type Host struct {
 	HardwareVersion  string `json:"hardware_version" db:"hardware_version"`
 	HardwareSerial   string `json:"hardware_serial" db:"hardware_serial"`
 	ComputerName     string `json:"computer_name" db:"computer_name"`
+	// DistributedInterval and ConfigRefresh are the intervals, in seconds,
+	// that osquery reported it is running with. Zero means the host has
+	// not reported the interval or does not check in on it.
+	DistributedInterval uint `json:"distributed_interval" db:"distributed_interval"`
+	ConfigRefresh       uint `json:"config_refresh" db:"config_refresh"`
+	// ConfigFetchTime and ConfigHash are when the host last fetched its
+	// config and the hash of the config it got.
+	ConfigFetchTime time.Time `json:"config_fetched_at" db:"config_fetch_time"`
+	ConfigHash      string    `json:"config_hash" db:"config_hash"`
 	// PrimaryNetworkInterfaceID if present indicates to primary network for the host, the details of which
 	// can be found in the NetworkInterfaces element with the same ip_address.
 	PrimaryNetworkInterfaceID *uint               `json:"primary_ip_id,omitempty" db:"primary_ip_id"`
 	NetworkInterfaces         []*NetworkInterface `json:"network_interfaces" db:"-"`
+	// Attributes are the results of the detail queries defined by admins.
+	// They are only loaded for a single host.
+	Attributes []HostAttribute `json:"attributes,omitempty" db:"-"`
+}
+
+// HostFilter narrows the hosts returned by ListHosts. Empty fields match any
+// host.
+type HostFilter struct {
+	// Status matches hosts with the status, one of the Status constants,
+	// at StatusTime using StatusThresholds. The service sets both from the
+	// clock and configuration.
+	Status           string
+	StatusTime       time.Time
+	StatusThresholds HostStatusThresholds
+	Platform         string
+	OSVersion        string
+	OsqueryVersion   string
+	// LabelID matches hosts that are members of the label.
+	LabelID uint
+	// SeenAfter and SeenBefore match hosts last seen within the range,
+	// inclusive.
+	SeenAfter  time.Time
+	SeenBefore time.Time
+	// Query matches hosts with a hostname, UUID, hardware serial, IP
+	// address or MAC address containing it, ignoring case.
+	Query string
+	// Attributes are filters that the attributes of the hosts must all
+	// match.
+	Attributes []HostAttributeFilter
 }
 
 // HostSummary is a structure which represents a data summary about the total
@@ -138,11 +226,22 @@ func RandomText(keySize int) (string, error) {
 	return base64.StdEncoding.EncodeToString(key), nil
 }
 
-func (h *Host) Status(now time.Time) string {
+// CheckInInterval returns the shortest interval that the host reported it
+// checks in on, or zero if it did not report any.
+func (h *Host) CheckInInterval() time.Duration {
+	interval := h.DistributedInterval
+	if interval == 0 || (h.ConfigRefresh != 0 && h.ConfigRefresh < interval) {
+		interval = h.ConfigRefresh
+	}
+	return time.Duration(interval) * time.Second
+}
+
+// Status returns the status of the host at now, using the thresholds.
+func (h *Host) Status(now time.Time, thresholds HostStatusThresholds) string {
 	switch {
-	case h.SeenTime.Add(MIADuration).Before(now):
+	case h.SeenTime.Add(thresholds.MIA).Before(now):
 		return StatusMIA
-	case h.SeenTime.Add(OfflineDuration).Before(now):
+	case h.SeenTime.Add(thresholds.OfflineDuration(h)).Before(now):
 		return StatusOffline
 	default:
 		return StatusOnline
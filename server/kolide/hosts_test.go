@@ -50,14 +50,68 @@ func TestHostStatus(t *testing.T) {
 	host := Host{}
 
 	host.SeenTime = mockClock.Now()
-	assert.Equal(t, StatusOnline, host.Status(mockClock.Now()))
+	assert.Equal(t, StatusOnline, host.Status(mockClock.Now(), DefaultHostStatusThresholds))
 
 	host.SeenTime = mockClock.Now().Add(-1 * time.Minute)
-	assert.Equal(t, StatusOnline, host.Status(mockClock.Now()))
+	assert.Equal(t, StatusOnline, host.Status(mockClock.Now(), DefaultHostStatusThresholds))
 
 	host.SeenTime = mockClock.Now().Add(-1 * time.Hour)
-	assert.Equal(t, StatusOffline, host.Status(mockClock.Now()))
+	assert.Equal(t, StatusOffline, host.Status(mockClock.Now(), DefaultHostStatusThresholds))
 
 	host.SeenTime = mockClock.Now().Add(-35 * (24 * time.Hour)) // 35 days
-	assert.Equal(t, StatusMIA, host.Status(mockClock.Now()))
+	assert.Equal(t, StatusMIA, host.Status(mockClock.Now(), DefaultHostStatusThresholds))
+}
+
+func TestHostStatusIntervalGrace(t *testing.T) {
+	mockClock := clock.NewMockClock()
+	thresholds := HostStatusThresholds{
+		Offline:       30 * time.Minute,
+		MIA:           30 * 24 * time.Hour,
+		IntervalGrace: 3,
+	}
+
+	// Hosts that did not report intervals use the offline duration
+	host := Host{SeenTime: mockClock.Now().Add(-1 * time.Minute)}
+	assert.Equal(t, StatusOnline, host.Status(mockClock.Now(), thresholds))
+
+	// The shortest interval is used
+	host.DistributedInterval = 10
+	host.ConfigRefresh = 3600
+	assert.Equal(t, 10*time.Second, host.CheckInInterval())
+	assert.Equal(t, StatusOffline, host.Status(mockClock.Now(), thresholds))
+
+	host.SeenTime = mockClock.Now().Add(-20 * time.Second)
+	assert.Equal(t, StatusOnline, host.Status(mockClock.Now(), thresholds))
+
+	// Hosts on long intervals stay online beyond the offline duration
+	host.DistributedInterval = 0
+	host.SeenTime = mockClock.Now().Add(-2 * time.Hour)
+	assert.Equal(t, time.Hour, host.CheckInInterval())
+	assert.Equal(t, StatusOnline, host.Status(mockClock.Now(), thresholds))
+
+	host.SeenTime = mockClock.Now().Add(-4 * time.Hour)
+	assert.Equal(t, StatusOffline, host.Status(mockClock.Now(), thresholds))
+
+	// MIA is not derived from the intervals
+	host.SeenTime = mockClock.Now().Add(-35 * (24 * time.Hour))
+	assert.Equal(t, StatusMIA, host.Status(mockClock.Now(), thresholds))
+}
+
+func TestMemoryBucket(t *testing.T) {
+	const gb = 1 << 30
+	var bucketTests = []struct {
+		bytes    int
+		expected string
+	}{
+		{0, "unknown"},
+		{512 << 20, "1GB"},
+		{2 * gb, "2GB"},
+		{2*gb + 1, "4GB"},
+		{6 * gb, "8GB"},
+		{256 * gb, "256GB"},
+		{512 * gb, ">256GB"},
+	}
+	for _, tt := range bucketTests {
+		assert.Equal(t, tt.expected, MemoryBucket(tt.bytes))
+	}
 }
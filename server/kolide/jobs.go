@@ -0,0 +1,84 @@
+package kolide
+
+import (
+	"time"
+
+	"golang.org/x/net/context"
+)
+
+// JobStore holds the locks and the last run of the background jobs that the
+// Kolide servers run, so that each run of a job happens on a single server.
+type JobStore interface {
+	// RegisterJob creates the named job, or updates its interval when it
+	// already exists, so that it is listed before it first runs.
+	RegisterJob(name string, interval time.Duration) error
+	// LockJob takes the lock of the named job for owner until now plus
+	// interval, unless another owner holds it at now, and records that the
+	// run of the job started at now. Only registered jobs are locked. It
+	// returns whether the lock was taken.
+	LockJob(name, owner string, interval time.Duration, now time.Time) (bool, error)
+	// FinishJob records that the run of the named job started by owner
+	// finished at finished, with the error of the run, which is empty when
+	// it succeeded. The lock is kept until it expires, so that the job
+	// runs once per interval.
+	FinishJob(name, owner string, finished time.Time, runErr string) error
+	// TriggerJob releases the lock of the named job at now, so that it runs
+	// again the next time a server polls it rather than waiting for its
+	// interval to pass. Jobs that have never been locked are left alone, as
+	// they run as soon as a server starts them.
+	TriggerJob(name string, now time.Time) error
+	// ListJobs returns the registered jobs, ordered by name.
+	ListJobs() ([]*Job, error)
+}
+
+// JobService reports on the background jobs.
+type JobService interface {
+	// ListJobs returns the background jobs with the status of their last
+	// run.
+	ListJobs(ctx context.Context) (jobs []*Job, err error)
+}
+
+// The statuses of the last run of a job.
+const (
+	JobStatusNeverRun  = "never_run"
+	JobStatusRunning   = "running"
+	JobStatusSucceeded = "succeeded"
+	JobStatusFailed    = "failed"
+)
+
+// Job is a background job, run by one of the Kolide servers every interval.
+type Job struct {
+	Name string `json:"name"`
+	// Interval is serialized as seconds by the API, see IntervalSeconds.
+	Interval time.Duration `json:"-" db:"run_interval"`
+	// LockOwner is the server holding the lock of the job, which it holds
+	// until LockedUntil.
+	LockOwner   string    `json:"lock_owner" db:"lock_owner"`
+	LockedUntil time.Time `json:"locked_until" db:"locked_until"`
+	// LastInstance is the server that ran the job last, from
+	// LastStartedAt to LastFinishedAt, and LastError is the error of that
+	// run.
+	LastInstance   string    `json:"last_instance" db:"last_instance"`
+	LastStartedAt  time.Time `json:"last_started_at" db:"last_started_at"`
+	LastFinishedAt time.Time `json:"last_finished_at" db:"last_finished_at"`
+	LastError      string    `json:"last_error" db:"last_error"`
+}
+
+// IntervalSeconds returns the interval of the job in whole seconds.
+func (j *Job) IntervalSeconds() uint {
+	return uint(j.Interval / time.Second)
+}
+
+// Status returns the status of the last run of the job.
+func (j *Job) Status() string {
+	switch {
+	case j.LastInstance == "":
+		return JobStatusNeverRun
+	case j.LastFinishedAt.Before(j.LastStartedAt):
+		return JobStatusRunning
+	case j.LastError != "":
+		return JobStatusFailed
+	default:
+		return JobStatusSucceeded
+	}
+}
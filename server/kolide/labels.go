@@ -50,6 +50,10 @@ type LabelPayload struct {
 	Query       *string `json:"query"`
 	Platform    *string `json:"platform"`
 	Description *string `json:"description"`
+	// Attribute is a host attribute filter, such as
+	// "disk_encryption.encrypted=true", that determines the hosts in the
+	// label instead of a query.
+	Attribute *string `json:"attribute"`
 }
 
 // LabelType is used to catagorize the kind of label
@@ -61,6 +65,10 @@ const (
 	// LabelTypeBuiltIn is for labels built into Kolide that cannot be
 	// modified by users.
 	LabelTypeBuiltIn
+	// LabelTypeAttribute is for user created labels whose hosts are those
+	// with a matching host attribute. The label has an attribute filter
+	// instead of a query.
+	LabelTypeAttribute
 )
 
 type Label struct {
@@ -72,6 +80,9 @@ type Label struct {
 	Query       string    `json:"query"`
 	Platform    string    `json:"platform"`
 	LabelType   LabelType `json:"label_type" db:"label_type"`
+	// Attribute is the host attribute filter of an attribute label, in the
+	// form accepted by ParseHostAttributeFilter.
+	Attribute string `json:"attribute"`
 }
 
 type LabelQueryExecution struct {
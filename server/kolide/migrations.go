@@ -0,0 +1,70 @@
+package kolide
+
+// MigrationStore inspects and rolls back the table and data migrations of
+// the datastore.
+type MigrationStore interface {
+	// MigrationStatus returns the table and data migrations of the
+	// datastore, along with whether each has been applied.
+	MigrationStatus() (*MigrationStatus, error)
+	// RollbackMigrations rolls back the applied migrations that come after
+	// version in the order migrations are applied, table migrations before
+	// data migrations, newest first. Rolling back to 0 rolls back every
+	// migration.
+	RollbackMigrations(version int64) error
+	// PlanMigrations returns the migrations that MigrateTables and
+	// MigrateData would apply, along with the SQL of each, without changing
+	// the database.
+	PlanMigrations() ([]*MigrationStep, error)
+	// PlanRollback returns the migrations that RollbackMigrations would roll
+	// back, along with the SQL of each, without changing the database.
+	PlanRollback(version int64) ([]*MigrationStep, error)
+}
+
+// The kinds of migration. Table migrations change the schema, and data
+// migrations populate the built in data once the tables exist.
+const (
+	MigrationKindTables = "tables"
+	MigrationKindData   = "data"
+)
+
+// Migration is a single table or data migration of the datastore.
+type Migration struct {
+	Kind    string
+	Version int64
+	Name    string
+	Applied bool
+}
+
+// MigrationStatus is the state of the table and data migrations of the
+// datastore, each ordered by version.
+type MigrationStatus struct {
+	Tables []*Migration
+	Data   []*Migration
+}
+
+// Migrations returns the table migrations followed by the data migrations.
+func (s *MigrationStatus) Migrations() []*Migration {
+	migrations := []*Migration{}
+	migrations = append(migrations, s.Tables...)
+	return append(migrations, s.Data...)
+}
+
+// Pending returns the migrations that have not been applied, table
+// migrations first, in the order they are applied.
+func (s *MigrationStatus) Pending() []*Migration {
+	pending := []*Migration{}
+	for _, m := range s.Migrations() {
+		if !m.Applied {
+			pending = append(pending, m)
+		}
+	}
+	return pending
+}
+
+// MigrationStep is a migration applied or rolled back by a plan, with the
+// SQL statements it runs.
+type MigrationStep struct {
+	Migration
+	Up         bool
+	Statements []string
+}
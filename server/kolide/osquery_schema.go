@@ -0,0 +1,157 @@
+package kolide
+
+import (
+	"encoding/json"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+	"golang.org/x/net/context"
+)
+
+// OsquerySchemaService exposes the osquery table schema that queries are
+// validated against.
+type OsquerySchemaService interface {
+	// GetOsquerySchema returns the tables and columns known to osquery.
+	GetOsquerySchema(ctx context.Context) (*OsquerySchema, error)
+}
+
+// Platforms that osquery tables may be available on
+const (
+	PlatformDarwin  = "darwin"
+	PlatformLinux   = "linux"
+	PlatformWindows = "windows"
+)
+
+// AllPlatforms lists every platform, in the order platforms are reported.
+var AllPlatforms = []string{PlatformDarwin, PlatformLinux, PlatformWindows}
+
+// osquerySchemaAsset is the path of the bundled osquery table schema.
+const osquerySchemaAsset = "frontend/osquery_tables.json"
+
+// schemaGroupPlatforms maps the table groups of the bundled schema to the
+// platforms that the tables in the group are available on.
+var schemaGroupPlatforms = map[string][]string{
+	"darwin":  {PlatformDarwin},
+	"linux":   {PlatformLinux},
+	"windows": {PlatformWindows},
+	"posix":   {PlatformDarwin, PlatformLinux},
+	"specs":   AllPlatforms,
+	"utility": AllPlatforms,
+}
+
+// OsquerySchema is the set of tables that queries may reference.
+type OsquerySchema struct {
+	Tables []OsqueryTable `json:"tables"`
+
+	byName map[string]*OsqueryTable
+}
+
+// OsqueryTable describes a single osquery table.
+type OsqueryTable struct {
+	Name        string          `json:"name"`
+	Description string          `json:"description"`
+	Platforms   []string        `json:"platforms"`
+	Columns     []OsqueryColumn `json:"columns"`
+}
+
+// OsqueryColumn describes a column of an osquery table.
+type OsqueryColumn struct {
+	Name        string `json:"name"`
+	Type        string `json:"type"`
+	Description string `json:"description"`
+}
+
+// LoadOsquerySchema loads the osquery table schema bundled with the binary.
+func LoadOsquerySchema() (*OsquerySchema, error) {
+	data, err := Asset(osquerySchemaAsset)
+	if err != nil {
+		return nil, errors.Wrap(err, "loading osquery schema asset")
+	}
+	return ParseOsquerySchema(data)
+}
+
+// ParseOsquerySchema parses an osquery table schema in the format of the
+// osquery_tables.json file used by the frontend.
+func ParseOsquerySchema(data []byte) (*OsquerySchema, error) {
+	var raw struct {
+		Tables []struct {
+			Key    string         `json:"key"`
+			Tables []OsqueryTable `json:"tables"`
+		} `json:"tables"`
+	}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return nil, errors.Wrap(err, "parsing osquery schema")
+	}
+
+	schema := &OsquerySchema{byName: map[string]*OsqueryTable{}}
+	for _, group := range raw.Tables {
+		platforms, ok := schemaGroupPlatforms[group.Key]
+		if !ok {
+			return nil, errors.Errorf("unknown osquery schema table group %q", group.Key)
+		}
+		for _, table := range group.Tables {
+			table.Platforms = platforms
+			schema.Tables = append(schema.Tables, table)
+		}
+	}
+	sort.Slice(schema.Tables, func(i, j int) bool {
+		return schema.Tables[i].Name < schema.Tables[j].Name
+	})
+	for i := range schema.Tables {
+		schema.byName[strings.ToLower(schema.Tables[i].Name)] = &schema.Tables[i]
+	}
+	return schema, nil
+}
+
+// Table returns the table with the provided name, ignoring case.
+func (s *OsquerySchema) Table(name string) (*OsqueryTable, bool) {
+	table, ok := s.byName[strings.ToLower(name)]
+	return table, ok
+}
+
+// TableColumns returns the column names of the named table, and false if
+// the table is not in the schema.
+func (s *OsquerySchema) TableColumns(name string) ([]string, bool) {
+	table, ok := s.Table(name)
+	if !ok {
+		return nil, false
+	}
+	columns := make([]string, len(table.Columns))
+	for i, col := range table.Columns {
+		columns[i] = col.Name
+	}
+	return columns, true
+}
+
+// Platforms returns the platforms on which every one of the provided tables
+// is available. A query referencing no tables can run on any platform.
+func (s *OsquerySchema) Platforms(tables []string) []string {
+	available := map[string]bool{}
+	for _, platform := range AllPlatforms {
+		available[platform] = true
+	}
+	for _, name := range tables {
+		table, ok := s.Table(name)
+		if !ok {
+			continue
+		}
+		supported := map[string]bool{}
+		for _, platform := range table.Platforms {
+			supported[platform] = true
+		}
+		for platform := range available {
+			if !supported[platform] {
+				delete(available, platform)
+			}
+		}
+	}
+
+	platforms := []string{}
+	for _, platform := range AllPlatforms {
+		if available[platform] {
+			platforms = append(platforms, platform)
+		}
+	}
+	return platforms
+}
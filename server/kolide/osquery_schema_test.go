@@ -0,0 +1,59 @@
+package kolide
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testOsquerySchema = `{
+  "tables": [
+    {"key": "windows", "tables": [{"name": "programs", "columns": [{"name": "name", "type": "TEXT_TYPE"}]}]},
+    {"key": "posix", "tables": [{"name": "processes", "columns": [{"name": "pid", "type": "BIGINT_TYPE"}, {"name": "name", "type": "TEXT_TYPE"}]}]},
+    {"key": "darwin", "tables": [{"name": "apps", "columns": [{"name": "name", "type": "TEXT_TYPE"}]}]},
+    {"key": "utility", "tables": [{"name": "time", "columns": [{"name": "unix_time", "type": "INTEGER_TYPE"}]}]}
+  ],
+  "events": []
+}`
+
+func TestParseOsquerySchema(t *testing.T) {
+	schema, err := ParseOsquerySchema([]byte(testOsquerySchema))
+	require.Nil(t, err)
+	require.Len(t, schema.Tables, 4)
+	assert.Equal(t, "apps", schema.Tables[0].Name)
+
+	table, ok := schema.Table("Processes")
+	require.True(t, ok)
+	assert.Equal(t, []string{PlatformDarwin, PlatformLinux}, table.Platforms)
+
+	columns, ok := schema.TableColumns("processes")
+	require.True(t, ok)
+	assert.Equal(t, []string{"pid", "name"}, columns)
+
+	_, ok = schema.TableColumns("nope")
+	assert.False(t, ok)
+
+	_, err = ParseOsquerySchema([]byte(`{"tables": [{"key": "plan9", "tables": []}]}`))
+	assert.NotNil(t, err)
+}
+
+func TestOsquerySchemaPlatforms(t *testing.T) {
+	schema, err := ParseOsquerySchema([]byte(testOsquerySchema))
+	require.Nil(t, err)
+
+	var platformTests = []struct {
+		tables    []string
+		platforms []string
+	}{
+		{nil, AllPlatforms},
+		{[]string{"time"}, AllPlatforms},
+		{[]string{"processes"}, []string{PlatformDarwin, PlatformLinux}},
+		{[]string{"processes", "apps"}, []string{PlatformDarwin}},
+		{[]string{"time", "programs"}, []string{PlatformWindows}},
+		{[]string{"apps", "programs"}, []string{}},
+	}
+	for _, tt := range platformTests {
+		assert.Equal(t, tt.platforms, schema.Platforms(tt.tables), "%v", tt.tables)
+	}
+}
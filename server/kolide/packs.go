@@ -48,6 +48,12 @@ type PackStore interface {
 	// ListExplicitHostsInPack lists hosts that have been manually associated
 	// with a query pack.
 	ListExplicitHostsInPack(pid uint, opt ListOptions) ([]*Host, error)
+
+	// ListPackTargetsForHost lists the targets that make enabled packs apply
+	// to a host, the host itself or labels the host is a member of, ordered
+	// by pack ID. A pack applying through several targets is listed once for
+	// each of them.
+	ListPackTargetsForHost(hid uint) ([]*HostPackTarget, error)
 }
 
 // PackService is the service interface for managing query packs.
@@ -108,6 +114,16 @@ type Pack struct {
 	Disabled    bool   `json:"disabled"`
 }
 
+// HostPackTarget is a pack that applies to a host along with the target that
+// makes it apply.
+type HostPackTarget struct {
+	Pack
+	TargetType TargetType `db:"target_type"`
+	TargetID   uint       `db:"target_id"`
+	// LabelName is the name of the label when the target is a label.
+	LabelName string `db:"label_name"`
+}
+
 // PackPayload is the struct which is used to create/update packs.
 type PackPayload struct {
 	Name        *string `json:"name"`
@@ -17,19 +17,21 @@ type QueryStore interface {
 	// Query returns the query associated with the provided ID. Associated
 	// packs should also be loaded.
 	Query(id uint) (*Query, error)
-	// ListQueries returns a list of queries with the provided sorting and
-	// paging options. Associated packs should also be loaded.
-	ListQueries(opt ListOptions) ([]*Query, error)
+	// ListQueries returns a list of the queries matching the filter, with
+	// the provided sorting and paging options. Associated packs and tags
+	// should also be loaded.
+	ListQueries(opt ListOptions, filter QueryFilter) ([]*Query, error)
 	// QueryByName looks up a query by name, the second bool is true if a query
 	// by the name exists.
 	QueryByName(name string) (*Query, bool, error)
 }
 
 type QueryService interface {
-	// ListQueries returns a list of saved queries. Note only saved queries
-	// should be returned (those that are created for distributed queries
-	// but not saved should not be returned).
-	ListQueries(ctx context.Context, opt ListOptions) ([]*Query, error)
+	// ListQueries returns a list of the queries matching the filter. Unless
+	// the filter asks otherwise only saved queries should be returned
+	// (those that are created for distributed queries but not saved should
+	// not be returned).
+	ListQueries(ctx context.Context, opt ListOptions, filter QueryFilter) ([]*Query, error)
 	GetQuery(ctx context.Context, id uint) (*Query, error)
 	NewQuery(ctx context.Context, p QueryPayload) (*Query, error)
 	ModifyQuery(ctx context.Context, id uint, p QueryPayload) (*Query, error)
@@ -44,6 +46,25 @@ type QueryPayload struct {
 	Name        *string
 	Description *string
 	Query       *string
+	Tags        *[]string
+	Category    *string
+}
+
+// QueryFilter restricts the queries returned by ListQueries. The zero value
+// matches every saved query.
+type QueryFilter struct {
+	// Saved selects saved queries when nil or true, and unsaved queries
+	// (those created for distributed query campaigns) when false.
+	Saved *bool
+	// AuthorID, when set, matches queries created by the user.
+	AuthorID *uint
+	// Tags matches queries that have every one of the tags.
+	Tags []string
+	// Category, when not empty, matches queries in the category.
+	Category string
+	// Search matches queries containing every word of the search in their
+	// name, description or SQL.
+	Search string
 }
 
 type Query struct {
@@ -61,4 +82,12 @@ type Query struct {
 	// Packs is loaded when retrieving queries, but is stored in a join
 	// table in the MySQL backend.
 	Packs []Pack `json:"packs" db:"-"`
+	// Platforms lists the platforms the query can run on, based on the
+	// osquery tables it references. It is computed by the service rather
+	// than stored, and is nil if the query cannot be parsed.
+	Platforms []string `json:"platforms" db:"-"`
+	// Tags is loaded when retrieving queries, but is stored in a join table
+	// in the MySQL backend.
+	Tags     []string `json:"tags" db:"-"`
+	Category string   `json:"category"`
 }
@@ -0,0 +1,155 @@
+package kolide
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"time"
+
+	"golang.org/x/net/context"
+)
+
+// Object types that revisions are recorded for
+const (
+	RevisionObjectQuery          = "query"
+	RevisionObjectPack           = "pack"
+	RevisionObjectScheduledQuery = "scheduled_query"
+)
+
+// Revision actions
+const (
+	RevisionActionCreated  = "created"
+	RevisionActionModified = "modified"
+	RevisionActionDeleted  = "deleted"
+	RevisionActionRestored = "restored"
+)
+
+// RevisionStore is the datastore interface for the revision history of
+// queries, packs and scheduled queries.
+type RevisionStore interface {
+	// NewRevision records a new revision. The ID of the returned revision
+	// is set.
+	NewRevision(rev *Revision) (*Revision, error)
+	// Revision returns the revision with the provided ID.
+	Revision(id uint) (*Revision, error)
+	// ListRevisions returns the revisions of an object, newest first.
+	ListRevisions(objectType string, objectID uint, opt ListOptions) ([]*Revision, error)
+}
+
+// RevisionService is the service interface for browsing and restoring the
+// revision history of queries, packs and scheduled queries.
+type RevisionService interface {
+	// ListRevisions returns the revisions of an object, newest first.
+	ListRevisions(ctx context.Context, objectType string, objectID uint, opt ListOptions) ([]*Revision, error)
+	// DiffRevisions returns the changes between two revisions of the same
+	// object.
+	DiffRevisions(ctx context.Context, fromID, toID uint) ([]RevisionChange, error)
+	// RestoreRevision returns the object to the state recorded in a
+	// revision. The revision recording the restore is returned.
+	RestoreRevision(ctx context.Context, id uint) (*Revision, error)
+}
+
+// Revision records the state of an object after a change, along with who
+// made the change and which fields it touched.
+type Revision struct {
+	ID         uint      `json:"id"`
+	CreatedAt  time.Time `json:"created_at" db:"created_at"`
+	ObjectType string    `json:"object_type" db:"object_type"`
+	ObjectID   uint      `json:"object_id" db:"object_id"`
+	Action     string    `json:"action"`
+	AuthorID   *uint     `json:"author_id" db:"author_id"`
+	// AuthorName is retrieved with a join to the users table in the MySQL
+	// backend (using AuthorID)
+	AuthorName string `json:"author_name" db:"author_name"`
+	// RestoredFrom is the ID of the revision that a restore returned the
+	// object to.
+	RestoredFrom *uint `json:"restored_from" db:"restored_from"`
+	// Snapshot is the state of the object after the change. For a deleted
+	// object it is the state at the time of deletion.
+	Snapshot RevisionSnapshot `json:"snapshot"`
+	// Changes lists the fields modified by the change.
+	Changes RevisionChanges `json:"changes"`
+}
+
+// RevisionSnapshot holds the fields of an object, keyed by their JSON names.
+type RevisionSnapshot map[string]interface{}
+
+// Value is called by the DB driver. Snapshots are stored as JSON.
+func (s RevisionSnapshot) Value() (driver.Value, error) {
+	return json.Marshal(s)
+}
+
+// Scan reads a snapshot stored as JSON.
+func (s *RevisionSnapshot) Scan(src interface{}) error {
+	return scanJSON(src, s)
+}
+
+// RevisionChange is a single modified field.
+type RevisionChange struct {
+	Field string      `json:"field"`
+	Old   interface{} `json:"old"`
+	New   interface{} `json:"new"`
+}
+
+// RevisionChanges supports the Valuer and Scanner interfaces so that the
+// changes of a revision can be stored as JSON.
+type RevisionChanges []RevisionChange
+
+// Value is called by the DB driver. Changes are stored as JSON.
+func (c RevisionChanges) Value() (driver.Value, error) {
+	if c == nil {
+		c = RevisionChanges{}
+	}
+	return json.Marshal(c)
+}
+
+// Scan reads changes stored as JSON.
+func (c *RevisionChanges) Scan(src interface{}) error {
+	return scanJSON(src, c)
+}
+
+// scanJSON decodes a JSON column read by the DB driver into v. A NULL
+// column leaves v unchanged.
+func scanJSON(src interface{}, v interface{}) error {
+	switch src := src.(type) {
+	case []byte:
+		return json.Unmarshal(src, v)
+	case string:
+		return json.Unmarshal([]byte(src), v)
+	case nil:
+		return nil
+	default:
+		return fmt.Errorf("cannot scan %T as JSON", src)
+	}
+}
+
+// DiffRevisionSnapshots returns the fields that differ between two
+// snapshots, ordered by field name. Either snapshot may be nil, such as
+// when an object is created.
+func DiffRevisionSnapshots(old, new RevisionSnapshot) []RevisionChange {
+	fields := map[string]bool{}
+	for field := range old {
+		fields[field] = true
+	}
+	for field := range new {
+		fields[field] = true
+	}
+
+	changes := []RevisionChange{}
+	for field := range fields {
+		if reflect.DeepEqual(old[field], new[field]) {
+			continue
+		}
+		changes = append(changes, RevisionChange{
+			Field: field,
+			Old:   old[field],
+			New:   new[field],
+		})
+	}
+	sort.Slice(changes, func(i, j int) bool {
+		return changes[i].Field < changes[j].Field
+	})
+	return changes
+}
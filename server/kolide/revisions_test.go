@@ -0,0 +1,55 @@
+package kolide
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDiffRevisionSnapshots(t *testing.T) {
+	old := RevisionSnapshot{
+		"name":      "foo",
+		"query":     "select 1",
+		"label_ids": []interface{}{float64(1), float64(2)},
+	}
+	new := RevisionSnapshot{
+		"name":      "foo",
+		"query":     "select 2",
+		"label_ids": []interface{}{float64(1), float64(2)},
+		"platform":  "darwin",
+	}
+
+	assert.Equal(t, []RevisionChange{
+		{Field: "platform", Old: nil, New: "darwin"},
+		{Field: "query", Old: "select 1", New: "select 2"},
+	}, DiffRevisionSnapshots(old, new))
+
+	assert.Equal(t, []RevisionChange{}, DiffRevisionSnapshots(old, old))
+
+	assert.Equal(t, []RevisionChange{
+		{Field: "name", New: "foo"},
+		{Field: "query", New: "select 1"},
+	}, DiffRevisionSnapshots(nil, RevisionSnapshot{"name": "foo", "query": "select 1"}))
+}
+
+func TestRevisionSnapshotScan(t *testing.T) {
+	var snapshot RevisionSnapshot
+	assert.Nil(t, snapshot.Scan([]byte(`{"name":"foo"}`)))
+	assert.Equal(t, RevisionSnapshot{"name": "foo"}, snapshot)
+
+	snapshot = nil
+	assert.Nil(t, snapshot.Scan(`{"name":"bar"}`))
+	assert.Equal(t, RevisionSnapshot{"name": "bar"}, snapshot)
+
+	snapshot = nil
+	assert.Nil(t, snapshot.Scan(nil))
+	assert.Nil(t, snapshot)
+
+	assert.NotNil(t, snapshot.Scan(42))
+
+	var changes RevisionChanges
+	assert.Nil(t, changes.Scan(`[{"field":"name","old":"foo","new":"bar"}]`))
+	assert.Equal(t, RevisionChanges{{Field: "name", Old: "foo", New: "bar"}}, changes)
+	assert.Nil(t, changes.Scan(nil))
+	assert.NotNil(t, changes.Scan(true))
+}
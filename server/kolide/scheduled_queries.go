@@ -1,6 +1,8 @@
 package kolide
 
 import (
+	"time"
+
 	"golang.org/x/net/context"
 )
 
@@ -10,6 +12,17 @@ type ScheduledQueryStore interface {
 	DeleteScheduledQuery(id uint) error
 	ScheduledQuery(id uint) (*ScheduledQuery, error)
 	ListScheduledQueriesInPack(id uint, opts ListOptions) ([]*ScheduledQuery, error)
+	// ListScheduledQueryNames returns the pack and query names of every
+	// scheduled query, which osquery uses to name the queries in its
+	// schedule.
+	ListScheduledQueryNames() ([]*ScheduledQueryName, error)
+	// SaveScheduledQueryStats replaces the performance stats stored for a
+	// host with the provided stats.
+	SaveScheduledQueryStats(hostID uint, stats []ScheduledQueryStats) error
+	// ScheduledQueryStatsInPack returns the performance stats of each
+	// scheduled query in a pack, summed over the hosts that reported them.
+	// Scheduled queries without stats are included with zero values.
+	ScheduledQueryStatsInPack(packID uint) ([]*ScheduledQueryStatsRollup, error)
 }
 
 type ScheduledQueryService interface {
@@ -18,6 +31,9 @@ type ScheduledQueryService interface {
 	ScheduleQuery(ctx context.Context, sq *ScheduledQuery) (query *ScheduledQuery, err error)
 	DeleteScheduledQuery(ctx context.Context, id uint) (err error)
 	ModifyScheduledQuery(ctx context.Context, sq *ScheduledQuery) (query *ScheduledQuery, err error)
+	// GetScheduledQueryStatsInPack returns the fleet-wide performance stats
+	// of each scheduled query in a pack.
+	GetScheduledQueryStatsInPack(ctx context.Context, packID uint) (stats []*ScheduledQueryStatsRollup, err error)
 }
 
 type ScheduledQuery struct {
@@ -35,3 +51,75 @@ type ScheduledQuery struct {
 	Version  *string `json:"version"`
 	Shard    *uint   `json:"shard"`
 }
+
+// ScheduledQueryName identifies a scheduled query by the names of its pack
+// and query.
+type ScheduledQueryName struct {
+	ID        uint   `db:"id"`
+	PackName  string `db:"pack_name"`
+	QueryName string `db:"query_name"`
+}
+
+// ScheduledQueryStats are the performance stats that osquery reports for a
+// scheduled query in the osquery_schedule table of a host. Counters are
+// cumulative since osqueryd started on the host.
+type ScheduledQueryStats struct {
+	HostID           uint      `json:"host_id" db:"host_id"`
+	ScheduledQueryID uint      `json:"scheduled_query_id" db:"scheduled_query_id"`
+	Executions       uint64    `json:"executions"`
+	LastExecuted     time.Time `json:"last_executed" db:"last_executed"`
+	// WallTime is in seconds, UserTime and SystemTime are in milliseconds.
+	WallTime   uint64 `json:"wall_time" db:"wall_time"`
+	UserTime   uint64 `json:"user_time" db:"user_time"`
+	SystemTime uint64 `json:"system_time" db:"system_time"`
+	// AverageMemory is in bytes.
+	AverageMemory uint64 `json:"average_memory" db:"average_memory"`
+	OutputSize    uint64 `json:"output_size" db:"output_size"`
+	// Denylisted is true when the osquery watchdog stopped scheduling the
+	// query for exceeding its resource limits.
+	Denylisted bool `json:"denylisted"`
+}
+
+// ScheduledQueryStatsRollup sums the performance stats of a scheduled query
+// over the hosts that reported them.
+type ScheduledQueryStatsRollup struct {
+	ScheduledQueryID uint   `json:"scheduled_query_id" db:"scheduled_query_id"`
+	PackID           uint   `json:"pack_id" db:"pack_id"`
+	QueryID          uint   `json:"query_id" db:"query_id"`
+	Name             string `json:"name"`
+	Interval         uint   `json:"interval"`
+	// HostCount is the number of hosts that reported stats for the query,
+	// of which DenylistedCount have had the query denylisted.
+	HostCount       uint `json:"host_count" db:"host_count"`
+	DenylistedCount uint `json:"denylisted_count" db:"denylisted_count"`
+	// Executions, WallTime, UserTime, SystemTime and OutputSize are totals
+	// over all hosts, in the units of ScheduledQueryStats.
+	Executions uint64 `json:"executions"`
+	WallTime   uint64 `json:"wall_time" db:"wall_time"`
+	UserTime   uint64 `json:"user_time" db:"user_time"`
+	SystemTime uint64 `json:"system_time" db:"system_time"`
+	OutputSize uint64 `json:"output_size" db:"output_size"`
+	// AverageMemory is the mean and MaxMemory the largest of the average
+	// memory reported by each host, in bytes.
+	AverageMemory uint64 `json:"average_memory" db:"average_memory"`
+	MaxMemory     uint64 `json:"max_memory" db:"max_memory"`
+	// The per execution averages are computed from the totals, in
+	// milliseconds.
+	WallTimePerExecution   float64 `json:"wall_time_per_execution" db:"-"`
+	UserTimePerExecution   float64 `json:"user_time_per_execution" db:"-"`
+	SystemTimePerExecution float64 `json:"system_time_per_execution" db:"-"`
+}
+
+// ComputeAverages fills in the per execution averages from the totals.
+func (r *ScheduledQueryStatsRollup) ComputeAverages() {
+	if r.Executions == 0 {
+		r.WallTimePerExecution = 0
+		r.UserTimePerExecution = 0
+		r.SystemTimePerExecution = 0
+		return
+	}
+	executions := float64(r.Executions)
+	r.WallTimePerExecution = float64(r.WallTime) * 1000 / executions
+	r.UserTimePerExecution = float64(r.UserTime) / executions
+	r.SystemTimePerExecution = float64(r.SystemTime) / executions
+}
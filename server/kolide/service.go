@@ -1,5 +1,7 @@
 package kolide
 
+import "golang.org/x/net/context"
+
 // service a interface stub
 type Service interface {
 	UserService
@@ -16,4 +18,19 @@ type Service interface {
 	ScheduledQueryService
 	OptionService
 	ImportConfigService
+	OsquerySchemaService
+	RevisionService
+	AgentLogService
+	DetailQueryService
+	SoftwareService
+	VulnerabilityService
+	HostPurgeService
+	HostBreakdownService
+	JobService
+
+	// Shutdown closes the running campaign result streams, marking their
+	// campaigns complete, flushes the seen times of hosts and closes the
+	// osquery log files. It gives up waiting for the streams to close
+	// when ctx is done.
+	Shutdown(ctx context.Context) error
 }
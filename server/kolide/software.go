@@ -0,0 +1,79 @@
+package kolide
+
+import (
+	"time"
+
+	"golang.org/x/net/context"
+)
+
+// SoftwareStore stores the software inventory of hosts.
+type SoftwareStore interface {
+	// SaveHostSoftware replaces the software that a host reported from
+	// the source. Software the host already had keeps its first seen
+	// time, and all of it is marked as seen at the provided time. The ID
+	// of each piece of software is set.
+	SaveHostSoftware(hostID uint, source string, software []Software, seen time.Time) error
+	// ListHostSoftware returns the software installed on a host, ordered
+	// by name and version unless the options specify an order.
+	ListHostSoftware(hostID uint, opt ListOptions) ([]*HostSoftware, error)
+	// SearchSoftware returns the software installed on at least one host
+	// that matches the filter, along with the number of hosts it is
+	// installed on.
+	SearchSoftware(filter SoftwareFilter, opt ListOptions) ([]*SoftwareCount, error)
+	Software(id uint) (*Software, error)
+	// ListSoftwareInstalls returns the hosts that a piece of software is
+	// installed on.
+	ListSoftwareInstalls(softwareID uint, opt ListOptions) ([]*SoftwareInstall, error)
+}
+
+// SoftwareService exposes the software inventory of the fleet.
+type SoftwareService interface {
+	ListHostSoftware(ctx context.Context, hostID uint, opt ListOptions) ([]*HostSoftware, error)
+	SearchSoftware(ctx context.Context, filter SoftwareFilter, opt ListOptions) ([]*SoftwareCount, error)
+	ListSoftwareInstalls(ctx context.Context, softwareID uint, opt ListOptions) ([]*SoftwareInstall, error)
+}
+
+// Software is a package or application at a version.
+type Software struct {
+	ID      uint   `json:"id"`
+	Name    string `json:"name"`
+	Version string `json:"version"`
+	// Source is the osquery table that the software was reported in,
+	// such as deb_packages or apps.
+	Source string `json:"source"`
+}
+
+// HostSoftware is software installed on a host.
+type HostSoftware struct {
+	Software
+	// FirstSeen is when the host first reported the software.
+	FirstSeen time.Time `json:"first_seen" db:"first_seen"`
+	// LastSeen is when the host last reported the software.
+	LastSeen time.Time `json:"last_seen" db:"last_seen"`
+}
+
+// SoftwareCount is software along with the number of hosts that it is
+// installed on.
+type SoftwareCount struct {
+	Software
+	HostCount uint `json:"host_count" db:"host_count"`
+}
+
+// SoftwareInstall is a host that software is installed on.
+type SoftwareInstall struct {
+	HostID    uint      `json:"host_id" db:"host_id"`
+	HostName  string    `json:"hostname" db:"host_name"`
+	FirstSeen time.Time `json:"first_seen" db:"first_seen"`
+	LastSeen  time.Time `json:"last_seen" db:"last_seen"`
+}
+
+// SoftwareFilter narrows a software search. Empty fields match any
+// software.
+type SoftwareFilter struct {
+	// Name matches software whose name contains it, ignoring case.
+	Name string
+	// Version matches software at exactly the version.
+	Version string
+	// Source matches software reported in the source.
+	Source string
+}
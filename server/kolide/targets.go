@@ -22,6 +22,20 @@ type TargetMetrics struct {
 	MissingInActionHosts uint
 }
 
+// TargetStore resolves target selections to hosts. Hosts are selected either
+// directly by ID or through the labels that they are members of.
+type TargetStore interface {
+	// ListTargetHosts returns the hosts in the selected targets, ordered by
+	// hostname and then ID and paginated according to opt.
+	ListTargetHosts(hostIDs []uint, labelIDs []uint, opt ListOptions) ([]*Host, error)
+	// CountTargetHosts returns the number of hosts in the selected targets.
+	CountTargetHosts(hostIDs []uint, labelIDs []uint) (uint, error)
+	// TargetLabelIDsForHosts returns the IDs of the selected labels that
+	// each of the hosts is a member of, keyed by host ID and ordered by
+	// label ID.
+	TargetLabelIDsForHosts(hostIDs []uint, labelIDs []uint) (map[uint][]uint, error)
+}
+
 type TargetService interface {
 	// SearchTargets will accept a search query, a slice of IDs of hosts to omit,
 	// and a slice of IDs of labels to omit, and it will return a set of targets
@@ -34,6 +48,32 @@ type TargetService interface {
 	// returned uint is the total number of hosts that have been offline for more
 	// than 30 days. (Missing in action)
 	CountHostsInTargets(ctx context.Context, hostIDs []uint, labelIDs []uint) (*TargetMetrics, error)
+
+	// ResolveTargets returns the hosts that the selected targets resolve
+	// to, ordered by hostname and paginated according to opt. Each host
+	// is annotated with whether it was selected directly and which of the
+	// selected labels it matched.
+	ResolveTargets(ctx context.Context, hostIDs []uint, labelIDs []uint, opt ListOptions) (*ResolvedTargets, error)
+}
+
+// ResolvedHost is a host that a target selection resolved to.
+type ResolvedHost struct {
+	ID       uint   `json:"id"`
+	HostName string `json:"hostname"`
+	Platform string `json:"platform"`
+	Status   string `json:"status"`
+	// Selected is true if the host was selected directly rather than
+	// through a label
+	Selected bool `json:"selected"`
+	// LabelIDs are the IDs of the selected labels that the host matched
+	LabelIDs []uint `json:"label_ids"`
+}
+
+// ResolvedTargets is a page of the hosts that a target selection resolved
+// to, along with the total number of hosts across all pages.
+type ResolvedTargets struct {
+	Hosts      []ResolvedHost `json:"hosts"`
+	TotalHosts uint           `json:"total_hosts"`
 }
 
 type TargetType int
@@ -0,0 +1,266 @@
+package kolide
+
+import (
+	"io"
+	"strings"
+	"unicode"
+
+	"golang.org/x/net/context"
+)
+
+// VulnerabilityStore stores an imported vulnerability feed and the software
+// in the inventory that it affects.
+type VulnerabilityStore interface {
+	// ImportVulnerabilities stores the vulnerabilities. The affected
+	// software of vulnerabilities that were imported before is replaced.
+	ImportVulnerabilities(vulns []*Vulnerability) error
+	Vulnerability(cve string) (*Vulnerability, error)
+	// ListVulnerableSoftware returns the affected software of every
+	// vulnerability for the products, or for all products when products
+	// is nil.
+	ListVulnerableSoftware(products []string) ([]*VulnerableSoftware, error)
+	// ListAllSoftware returns all of the software in the inventory.
+	ListAllSoftware() ([]*Software, error)
+	// ReplaceSoftwareVulnerabilities replaces every match between software
+	// and vulnerabilities.
+	ReplaceSoftwareVulnerabilities(matches []SoftwareVulnerability) error
+	// AddSoftwareVulnerabilities stores matches between software and
+	// vulnerabilities, ignoring matches that are already stored.
+	AddSoftwareVulnerabilities(matches []SoftwareVulnerability) error
+	// ListVulnerabilities returns the vulnerabilities affecting at least
+	// one host, along with the number of hosts affected. They are ordered
+	// by descending CVSS score unless the options specify an order.
+	ListVulnerabilities(opt ListOptions) ([]*VulnerabilityCount, error)
+	// ListVulnerableHosts returns the hosts affected by a vulnerability,
+	// once for each piece of vulnerable software they have installed.
+	ListVulnerableHosts(cve string, opt ListOptions) ([]*VulnerableHost, error)
+	// ListHostVulnerabilities returns the vulnerabilities affecting a
+	// host, once for each piece of vulnerable software it has installed.
+	ListHostVulnerabilities(hostID uint, opt ListOptions) ([]*HostVulnerability, error)
+}
+
+// VulnerabilityService imports vulnerability feeds and reports on the hosts
+// they affect.
+type VulnerabilityService interface {
+	// ImportVulnerabilities loads a feed in the format, one of the
+	// VulnerabilityFeed constants, and triggers the job that matches it
+	// against the software inventory. It returns the number of
+	// vulnerabilities imported.
+	ImportVulnerabilities(ctx context.Context, format string, feed io.Reader) (int, error)
+	// MatchVulnerabilities replaces the vulnerabilities of all software
+	// in the inventory. It is the work of the MatchVulnerabilitiesJob.
+	MatchVulnerabilities(ctx context.Context) error
+	ListVulnerabilities(ctx context.Context, opt ListOptions) ([]*VulnerabilityCount, error)
+	ListVulnerableHosts(ctx context.Context, cve string, opt ListOptions) ([]*VulnerableHost, error)
+	ListHostVulnerabilities(ctx context.Context, hostID uint, opt ListOptions) ([]*HostVulnerability, error)
+}
+
+// MatchVulnerabilitiesJob is the name of the background job that matches the
+// imported feed against the whole software inventory. Importing a feed
+// triggers the job, which otherwise runs daily.
+const MatchVulnerabilitiesJob = "match_vulnerabilities"
+
+// Vulnerability is an entry of a vulnerability feed.
+type Vulnerability struct {
+	CVE       string  `json:"cve" db:"cve"`
+	Summary   string  `json:"summary"`
+	CVSSScore float64 `json:"cvss_score" db:"cvss_score"`
+	// Criteria is the software affected by the vulnerability.
+	Criteria []VulnerableSoftware `json:"-" db:"-"`
+}
+
+// VulnerableSoftware describes the versions of a product affected by a
+// vulnerability. A criterion with no version and no bounds affects every
+// version of the product.
+type VulnerableSoftware struct {
+	CVE    string `json:"cve" db:"cve"`
+	Vendor string `json:"vendor"`
+	// Product is the normalized name of the affected software, see
+	// NormalizeSoftwareName.
+	Product string `json:"product"`
+	// Version is the only affected version when it is set.
+	Version               string `json:"version"`
+	VersionStartIncluding string `json:"version_start_including" db:"version_start_including"`
+	VersionStartExcluding string `json:"version_start_excluding" db:"version_start_excluding"`
+	VersionEndIncluding   string `json:"version_end_including" db:"version_end_including"`
+	VersionEndExcluding   string `json:"version_end_excluding" db:"version_end_excluding"`
+}
+
+// SoftwareVulnerability records that software is affected by a
+// vulnerability.
+type SoftwareVulnerability struct {
+	SoftwareID uint   `db:"software_id"`
+	CVE        string `db:"cve"`
+}
+
+// VulnerabilityCount is a vulnerability along with the number of hosts it
+// affects.
+type VulnerabilityCount struct {
+	Vulnerability
+	HostCount uint `json:"host_count" db:"host_count"`
+}
+
+// VulnerableHost is a host affected by a vulnerability through the software
+// installed on it.
+type VulnerableHost struct {
+	HostID   uint     `json:"host_id" db:"host_id"`
+	HostName string   `json:"hostname" db:"host_name"`
+	Software Software `json:"software" db:"software"`
+}
+
+// HostVulnerability is a vulnerability affecting a host through the software
+// installed on it.
+type HostVulnerability struct {
+	Vulnerability
+	Software Software `json:"software" db:"software"`
+}
+
+// NormalizeSoftwareName returns the name that software is matched against
+// the products of vulnerability feeds with. CPE names are lowercase and use
+// underscores in place of spaces.
+func NormalizeSoftwareName(name string) string {
+	return strings.Replace(strings.ToLower(strings.TrimSpace(name)), " ", "_", -1)
+}
+
+// Matches returns whether the software is affected.
+func (v VulnerableSoftware) Matches(s Software) bool {
+	if v.Product != NormalizeSoftwareName(s.Name) {
+		return false
+	}
+	bounds := []string{
+		v.Version,
+		v.VersionStartIncluding,
+		v.VersionStartExcluding,
+		v.VersionEndIncluding,
+		v.VersionEndExcluding,
+	}
+	if strings.Join(bounds, "") == "" {
+		return true
+	}
+
+	version := upstreamVersion(s.Version)
+	if version == "" {
+		// Affected versions cannot be compared to an unknown version
+		return false
+	}
+	if v.Version != "" {
+		return CompareVersions(version, v.Version) == 0
+	}
+	if v.VersionStartIncluding != "" && CompareVersions(version, v.VersionStartIncluding) < 0 {
+		return false
+	}
+	if v.VersionStartExcluding != "" && CompareVersions(version, v.VersionStartExcluding) <= 0 {
+		return false
+	}
+	if v.VersionEndIncluding != "" && CompareVersions(version, v.VersionEndIncluding) > 0 {
+		return false
+	}
+	if v.VersionEndExcluding != "" && CompareVersions(version, v.VersionEndExcluding) >= 0 {
+		return false
+	}
+	return true
+}
+
+// MatchVulnerabilities returns the software affected by each criterion.
+func MatchVulnerabilities(software []Software, criteria []*VulnerableSoftware) []SoftwareVulnerability {
+	byProduct := map[string][]*VulnerableSoftware{}
+	for _, c := range criteria {
+		byProduct[c.Product] = append(byProduct[c.Product], c)
+	}
+
+	matches := []SoftwareVulnerability{}
+	for _, s := range software {
+		matched := map[string]bool{}
+		for _, c := range byProduct[NormalizeSoftwareName(s.Name)] {
+			if !matched[c.CVE] && c.Matches(s) {
+				matched[c.CVE] = true
+				matches = append(matches, SoftwareVulnerability{SoftwareID: s.ID, CVE: c.CVE})
+			}
+		}
+	}
+	return matches
+}
+
+// upstreamVersion strips the epoch and the packaging revision from the
+// version of a package, such as 1:1.0.2g-1ubuntu4.6, leaving the version
+// released by the vendor.
+func upstreamVersion(version string) string {
+	if i := strings.Index(version, ":"); i > 0 && strings.Trim(version[:i], "0123456789") == "" {
+		version = version[i+1:]
+	}
+	if i := strings.IndexAny(version, "-~+"); i >= 0 {
+		version = version[:i]
+	}
+	return version
+}
+
+// CompareVersions compares two versions, returning -1, 0 or 1 when a is
+// older than, the same as or newer than b. Versions are split into runs of
+// digits, which are compared numerically, and runs of letters, which are
+// compared lexically, with any other characters separating runs.
+func CompareVersions(a, b string) int {
+	for {
+		a = strings.TrimLeftFunc(a, isVersionSeparator)
+		b = strings.TrimLeftFunc(b, isVersionSeparator)
+		if a == "" || b == "" {
+			break
+		}
+
+		aNumeric, bNumeric := unicode.IsDigit(rune(a[0])), unicode.IsDigit(rune(b[0]))
+		if aNumeric != bNumeric {
+			// A number is newer than a letter, so 1.0.1 is newer
+			// than 1.0a
+			if aNumeric {
+				return 1
+			}
+			return -1
+		}
+
+		var aRun, bRun string
+		aRun, a = splitVersionRun(a, aNumeric)
+		bRun, b = splitVersionRun(b, bNumeric)
+		if aNumeric {
+			aRun = strings.TrimLeft(aRun, "0")
+			bRun = strings.TrimLeft(bRun, "0")
+			if len(aRun) != len(bRun) {
+				if len(aRun) < len(bRun) {
+					return -1
+				}
+				return 1
+			}
+		}
+		if c := strings.Compare(aRun, bRun); c != 0 {
+			return c
+		}
+	}
+
+	// The version with runs remaining is newer, so 1.0.2g is newer than
+	// 1.0.2
+	switch {
+	case a == "" && b == "":
+		return 0
+	case a == "":
+		return -1
+	default:
+		return 1
+	}
+}
+
+func isVersionSeparator(r rune) bool {
+	return !unicode.IsDigit(r) && !unicode.IsLetter(r)
+}
+
+// splitVersionRun splits the leading run of digits or letters from the
+// version.
+func splitVersionRun(version string, numeric bool) (string, string) {
+	end := strings.IndexFunc(version, func(r rune) bool {
+		if numeric {
+			return !unicode.IsDigit(r)
+		}
+		return !unicode.IsLetter(r)
+	})
+	if end < 0 {
+		return version, ""
+	}
+	return version[:end], version[end:]
+}
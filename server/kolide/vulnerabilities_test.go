@@ -0,0 +1,177 @@
+package kolide
+
+import (
+	"bytes"
+	"compress/gzip"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCompareVersions(t *testing.T) {
+	var versionTests = []struct {
+		a, b     string
+		expected int
+	}{
+		{"1.0.2", "1.0.2", 0},
+		{"1.0.2g", "1.0.2k", -1},
+		{"1.0.2g", "1.0.2", 1},
+		{"1.10", "1.9", 1},
+		{"1.01", "1.1", 0},
+		{"2.0", "10.0", -1},
+		{"7.47.0", "7.47", 1},
+		{"1.0.1", "1.0a", 1},
+		{"", "1.0", -1},
+	}
+	for _, tt := range versionTests {
+		t.Run(tt.a+" "+tt.b, func(t *testing.T) {
+			assert.Equal(t, tt.expected, CompareVersions(tt.a, tt.b))
+			assert.Equal(t, -tt.expected, CompareVersions(tt.b, tt.a))
+		})
+	}
+}
+
+func TestVulnerableSoftwareMatches(t *testing.T) {
+	openssl := VulnerableSoftware{
+		Product:               "openssl",
+		VersionStartIncluding: "1.0.2",
+		VersionEndExcluding:   "1.0.2k",
+	}
+	assert.True(t, openssl.Matches(Software{Name: "openssl", Version: "1.0.2g"}))
+	assert.True(t, openssl.Matches(Software{Name: "OpenSSL", Version: "1.0.2"}))
+	// Epochs and packaging revisions are ignored
+	assert.True(t, openssl.Matches(Software{Name: "openssl", Version: "1:1.0.2g-1ubuntu4.6"}))
+	assert.False(t, openssl.Matches(Software{Name: "openssl", Version: "1.0.2k"}))
+	assert.False(t, openssl.Matches(Software{Name: "openssl", Version: "1.0.1u"}))
+	assert.False(t, openssl.Matches(Software{Name: "openssl", Version: ""}))
+	assert.False(t, openssl.Matches(Software{Name: "libssl", Version: "1.0.2g"}))
+
+	exact := VulnerableSoftware{Product: "google_chrome", Version: "56.0.2924.87"}
+	assert.True(t, exact.Matches(Software{Name: "Google Chrome", Version: "56.0.2924.87"}))
+	assert.False(t, exact.Matches(Software{Name: "Google Chrome", Version: "56.0.2924.88"}))
+
+	allVersions := VulnerableSoftware{Product: "bash"}
+	assert.True(t, allVersions.Matches(Software{Name: "bash", Version: ""}))
+}
+
+func TestMatchVulnerabilities(t *testing.T) {
+	software := []Software{
+		{ID: 1, Name: "openssl", Version: "1.0.2g"},
+		{ID: 2, Name: "openssl", Version: "1.1.0e"},
+		{ID: 3, Name: "curl", Version: "7.47.0"},
+	}
+	criteria := []*VulnerableSoftware{
+		{CVE: "CVE-2016-2107", Product: "openssl", VersionEndIncluding: "1.0.2g"},
+		{CVE: "CVE-2016-2107", Product: "openssl", Version: "1.0.2g"},
+		{CVE: "CVE-2017-3731", Product: "openssl", VersionStartIncluding: "1.1.0", VersionEndExcluding: "1.1.0d"},
+	}
+	assert.Equal(t, []SoftwareVulnerability{
+		{SoftwareID: 1, CVE: "CVE-2016-2107"},
+	}, MatchVulnerabilities(software, criteria))
+}
+
+const testNVDFeed = `{
+	"CVE_data_type": "CVE",
+	"CVE_Items": [
+		{
+			"cve": {
+				"CVE_data_meta": {"ID": "CVE-2016-2107"},
+				"description": {"description_data": [{"lang": "en", "value": "The AES-NI implementation leaks"}]}
+			},
+			"configurations": {
+				"nodes": [
+					{
+						"operator": "OR",
+						"cpe": [
+							{"vulnerable": true, "cpe23Uri": "cpe:2.3:a:openssl:openssl:1.0.2g:*:*:*:*:*:*:*"},
+							{"vulnerable": true, "cpe23Uri": "cpe:2.3:a:openssl:openssl:*:*:*:*:*:*:*:*", "versionEndIncluding": "1.0.1s"}
+						]
+					}
+				]
+			},
+			"impact": {
+				"baseMetricV3": {"cvssV3": {"baseScore": 5.9}},
+				"baseMetricV2": {"cvssV2": {"baseScore": 2.6}}
+			}
+		},
+		{
+			"cve": {
+				"CVE_data_meta": {"ID": "CVE-2017-5030"},
+				"description": {"description_data": [{"lang": "en", "value": "Incorrect handling of complex species"}]}
+			},
+			"configurations": {
+				"nodes": [
+					{
+						"operator": "AND",
+						"children": [
+							{"operator": "OR", "cpe_match": [{"vulnerable": true, "cpe23Uri": "cpe:2.3:a:google:chrome:56.0.2924.87:*:*:*:*:*:*:*"}]},
+							{"operator": "OR", "cpe_match": [{"vulnerable": false, "cpe23Uri": "cpe:2.3:o:apple:mac_os_x:-:*:*:*:*:*:*:*"}]}
+						]
+					}
+				]
+			},
+			"impact": {"baseMetricV2": {"cvssV2": {"baseScore": 6.8}}}
+		}
+	]
+}`
+
+func TestParseNVDFeed(t *testing.T) {
+	var compressed bytes.Buffer
+	gz := gzip.NewWriter(&compressed)
+	_, err := gz.Write([]byte(testNVDFeed))
+	require.Nil(t, err)
+	require.Nil(t, gz.Close())
+
+	for _, feed := range []*bytes.Buffer{bytes.NewBufferString(testNVDFeed), &compressed} {
+		vulns, err := ParseVulnerabilityFeed(VulnerabilityFeedNVD, feed)
+		require.Nil(t, err)
+		require.Len(t, vulns, 2)
+
+		assert.Equal(t, "CVE-2016-2107", vulns[0].CVE)
+		assert.Equal(t, "The AES-NI implementation leaks", vulns[0].Summary)
+		assert.Equal(t, 5.9, vulns[0].CVSSScore)
+		assert.Equal(t, []VulnerableSoftware{
+			{CVE: "CVE-2016-2107", Vendor: "openssl", Product: "openssl", Version: "1.0.2g"},
+			{CVE: "CVE-2016-2107", Vendor: "openssl", Product: "openssl", VersionEndIncluding: "1.0.1s"},
+		}, vulns[0].Criteria)
+
+		// Software that is not vulnerable itself is left out
+		assert.Equal(t, 6.8, vulns[1].CVSSScore)
+		assert.Equal(t, []VulnerableSoftware{
+			{CVE: "CVE-2017-5030", Vendor: "google", Product: "chrome", Version: "56.0.2924.87"},
+		}, vulns[1].Criteria)
+	}
+
+	_, err = ParseVulnerabilityFeed(VulnerabilityFeedNVD, strings.NewReader(`{"CVE_Items": [{}]}`))
+	assert.NotNil(t, err)
+}
+
+func TestParseCPECSV(t *testing.T) {
+	feed := strings.NewReader(`cpe,cve
+cpe:2.3:a:openssl:openssl:1.0.2g:*:*:*:*:*:*:*,CVE-2016-2107
+cpe:/a:openssl:openssl:1.0.2f,CVE-2016-2107
+cpe:2.3:a:haxx:curl:7.47.0:*:*:*:*:*:*:*, CVE-2016-8615
+cpe:2.3:a:vendor:name\:with\:colons:-:*:*:*:*:*:*:*,CVE-2017-0001
+`)
+	vulns, err := ParseVulnerabilityFeed(VulnerabilityFeedCSV, feed)
+	require.Nil(t, err)
+	require.Len(t, vulns, 3)
+
+	assert.Equal(t, "CVE-2016-2107", vulns[0].CVE)
+	assert.Equal(t, []VulnerableSoftware{
+		{CVE: "CVE-2016-2107", Vendor: "openssl", Product: "openssl", Version: "1.0.2g"},
+		{CVE: "CVE-2016-2107", Vendor: "openssl", Product: "openssl", Version: "1.0.2f"},
+	}, vulns[0].Criteria)
+	assert.Equal(t, "CVE-2016-8615", vulns[1].CVE)
+	assert.Equal(t, "curl", vulns[1].Criteria[0].Product)
+	assert.Equal(t, VulnerableSoftware{CVE: "CVE-2017-0001", Vendor: "vendor", Product: "name:with:colons"}, vulns[2].Criteria[0])
+
+	_, err = ParseVulnerabilityFeed(VulnerabilityFeedCSV, strings.NewReader("cpe:2.3:a:openssl:openssl:1.0.2g\n"))
+	assert.NotNil(t, err)
+	_, err = ParseVulnerabilityFeed(VulnerabilityFeedCSV, strings.NewReader("not-a-cpe,CVE-2016-2107\ncpe:2.3:a:x,CVE-1\n"))
+	assert.NotNil(t, err)
+	_, err = ParseVulnerabilityFeed("xml", strings.NewReader(""))
+	assert.NotNil(t, err)
+}
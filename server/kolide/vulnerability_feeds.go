@@ -0,0 +1,274 @@
+package kolide
+
+import (
+	"bufio"
+	"compress/gzip"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/url"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	// VulnerabilityFeedNVD is a JSON feed published by the National
+	// Vulnerability Database.
+	VulnerabilityFeedNVD = "nvd"
+	// VulnerabilityFeedCSV is a CSV file of CPE names and the CVE that
+	// affects each of them.
+	VulnerabilityFeedCSV = "csv"
+)
+
+// ParseVulnerabilityFeed reads a feed in the format, one of the
+// VulnerabilityFeed constants. Feeds compressed with gzip, as the NVD
+// publishes them, are decompressed.
+func ParseVulnerabilityFeed(format string, r io.Reader) ([]*Vulnerability, error) {
+	br := bufio.NewReader(r)
+	if magic, _ := br.Peek(2); len(magic) == 2 && magic[0] == 0x1f && magic[1] == 0x8b {
+		gz, err := gzip.NewReader(br)
+		if err != nil {
+			return nil, errors.Wrap(err, "decompressing feed")
+		}
+		defer gz.Close()
+		r = gz
+	} else {
+		r = br
+	}
+
+	switch format {
+	case VulnerabilityFeedNVD:
+		return parseNVDFeed(r)
+	case VulnerabilityFeedCSV:
+		return parseCPECSV(r)
+	default:
+		return nil, fmt.Errorf("unknown vulnerability feed format %q", format)
+	}
+}
+
+type nvdFeed struct {
+	Items []struct {
+		CVE struct {
+			Meta struct {
+				ID string `json:"ID"`
+			} `json:"CVE_data_meta"`
+			Description struct {
+				Data []struct {
+					Lang  string `json:"lang"`
+					Value string `json:"value"`
+				} `json:"description_data"`
+			} `json:"description"`
+		} `json:"cve"`
+		Configurations struct {
+			Nodes []nvdNode `json:"nodes"`
+		} `json:"configurations"`
+		Impact struct {
+			V3 struct {
+				CVSS struct {
+					BaseScore float64 `json:"baseScore"`
+				} `json:"cvssV3"`
+			} `json:"baseMetricV3"`
+			V2 struct {
+				CVSS struct {
+					BaseScore float64 `json:"baseScore"`
+				} `json:"cvssV2"`
+			} `json:"baseMetricV2"`
+		} `json:"impact"`
+	} `json:"CVE_Items"`
+}
+
+type nvdNode struct {
+	Children []nvdNode `json:"children"`
+	// Version 1.0 of the feed lists matches in cpe, and version 1.1 in
+	// cpe_match
+	CPE      []nvdCPEMatch `json:"cpe"`
+	CPEMatch []nvdCPEMatch `json:"cpe_match"`
+}
+
+type nvdCPEMatch struct {
+	Vulnerable            bool   `json:"vulnerable"`
+	CPE23URI              string `json:"cpe23Uri"`
+	CPE22URI              string `json:"cpe22Uri"`
+	VersionStartIncluding string `json:"versionStartIncluding"`
+	VersionStartExcluding string `json:"versionStartExcluding"`
+	VersionEndIncluding   string `json:"versionEndIncluding"`
+	VersionEndExcluding   string `json:"versionEndExcluding"`
+}
+
+func parseNVDFeed(r io.Reader) ([]*Vulnerability, error) {
+	var feed nvdFeed
+	if err := json.NewDecoder(r).Decode(&feed); err != nil {
+		return nil, errors.Wrap(err, "decoding NVD feed")
+	}
+
+	vulns := []*Vulnerability{}
+	for _, item := range feed.Items {
+		vuln := &Vulnerability{
+			CVE:       item.CVE.Meta.ID,
+			CVSSScore: item.Impact.V3.CVSS.BaseScore,
+		}
+		if vuln.CVE == "" {
+			return nil, errors.New("NVD feed item has no CVE ID")
+		}
+		if vuln.CVSSScore == 0 {
+			vuln.CVSSScore = item.Impact.V2.CVSS.BaseScore
+		}
+		for _, d := range item.CVE.Description.Data {
+			if d.Lang == "en" || vuln.Summary == "" {
+				vuln.Summary = d.Value
+			}
+		}
+
+		criteria, err := nvdCriteria(vuln.CVE, item.Configurations.Nodes)
+		if err != nil {
+			return nil, errors.Wrapf(err, "parsing configurations of %s", vuln.CVE)
+		}
+		vuln.Criteria = criteria
+		vulns = append(vulns, vuln)
+	}
+	return vulns, nil
+}
+
+// nvdCriteria returns the vulnerable software of the configuration nodes.
+// Nodes that combine software, such as an application running on an
+// operating system, are flattened to the vulnerable software alone.
+func nvdCriteria(cve string, nodes []nvdNode) ([]VulnerableSoftware, error) {
+	criteria := []VulnerableSoftware{}
+	for _, node := range nodes {
+		children, err := nvdCriteria(cve, node.Children)
+		if err != nil {
+			return nil, err
+		}
+		criteria = append(criteria, children...)
+
+		for _, match := range append(node.CPE, node.CPEMatch...) {
+			if !match.Vulnerable {
+				continue
+			}
+			name := match.CPE23URI
+			if name == "" {
+				name = match.CPE22URI
+			}
+			criterion, err := parseCPE(name)
+			if err != nil {
+				return nil, err
+			}
+			criterion.CVE = cve
+			criterion.VersionStartIncluding = match.VersionStartIncluding
+			criterion.VersionStartExcluding = match.VersionStartExcluding
+			criterion.VersionEndIncluding = match.VersionEndIncluding
+			criterion.VersionEndExcluding = match.VersionEndExcluding
+			criteria = append(criteria, criterion)
+		}
+	}
+	return criteria, nil
+}
+
+// parseCPECSV reads rows of a CPE name and a CVE ID, optionally preceded by
+// a header row.
+func parseCPECSV(r io.Reader) ([]*Vulnerability, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	vulns := []*Vulnerability{}
+	byCVE := map[string]*Vulnerability{}
+	for line := 1; ; line++ {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, errors.Wrap(err, "reading CSV feed")
+		}
+		if len(record) < 2 {
+			return nil, fmt.Errorf("line %d: expected a CPE name and a CVE ID", line)
+		}
+		if line == 1 && !strings.HasPrefix(strings.ToLower(record[0]), "cpe:") {
+			// Header row
+			continue
+		}
+
+		criterion, err := parseCPE(record[0])
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %s", line, err)
+		}
+		cve := strings.TrimSpace(record[1])
+		if cve == "" {
+			return nil, fmt.Errorf("line %d: missing CVE ID", line)
+		}
+		criterion.CVE = cve
+
+		vuln, ok := byCVE[cve]
+		if !ok {
+			vuln = &Vulnerability{CVE: cve}
+			byCVE[cve] = vuln
+			vulns = append(vulns, vuln)
+		}
+		vuln.Criteria = append(vuln.Criteria, criterion)
+	}
+	return vulns, nil
+}
+
+// parseCPE returns the software named by a CPE, either a 2.3 formatted string
+// such as cpe:2.3:a:openssl:openssl:1.0.2g:*:*:*:*:*:*:* or a 2.2 URI such as
+// cpe:/a:openssl:openssl:1.0.2g.
+func parseCPE(name string) (VulnerableSoftware, error) {
+	var fields []string
+	switch {
+	case strings.HasPrefix(name, "cpe:2.3:"):
+		fields = splitCPE23(strings.TrimPrefix(name, "cpe:2.3:"))
+	case strings.HasPrefix(name, "cpe:/"):
+		for _, field := range strings.Split(strings.TrimPrefix(name, "cpe:/"), ":") {
+			unescaped, err := url.QueryUnescape(field)
+			if err != nil {
+				return VulnerableSoftware{}, fmt.Errorf("invalid CPE name %q", name)
+			}
+			fields = append(fields, unescaped)
+		}
+	default:
+		return VulnerableSoftware{}, fmt.Errorf("invalid CPE name %q", name)
+	}
+	// Fields are part, vendor, product and version, followed by others
+	// that are not matched
+	for len(fields) < 4 {
+		fields = append(fields, "")
+	}
+	if fields[2] == "" || fields[2] == "*" {
+		return VulnerableSoftware{}, fmt.Errorf("CPE name %q has no product", name)
+	}
+
+	version := fields[3]
+	if version == "*" || version == "-" {
+		version = ""
+	}
+	return VulnerableSoftware{
+		Vendor:  fields[1],
+		Product: NormalizeSoftwareName(fields[2]),
+		Version: version,
+	}, nil
+}
+
+// splitCPE23 splits the fields of a CPE 2.3 formatted string, removing the
+// backslashes that escape characters within them.
+func splitCPE23(name string) []string {
+	fields := []string{}
+	field := []byte{}
+	for i := 0; i < len(name); i++ {
+		switch name[i] {
+		case '\\':
+			if i+1 < len(name) {
+				i++
+				field = append(field, name[i])
+			}
+		case ':':
+			fields = append(fields, string(field))
+			field = []byte{}
+		default:
+			field = append(field, name[i])
+		}
+	}
+	return append(fields, string(field))
+}
@@ -10,6 +10,7 @@ var _ kolide.Datastore = (*Store)(nil)
 
 type Store struct {
 	kolide.HostStore
+	kolide.TargetStore
 	kolide.LabelStore
 	kolide.PackStore
 	kolide.CampaignStore
@@ -21,6 +22,15 @@ type Store struct {
 	kolide.DecoratorStore
 	kolide.FileIntegrityMonitoringStore
 	kolide.YARAStore
+	kolide.RevisionStore
+	kolide.AgentLogStore
+	kolide.DetailQueryStore
+	kolide.SoftwareStore
+	kolide.VulnerabilityStore
+	kolide.HostPurgeStore
+	kolide.HostBreakdownStore
+	kolide.MigrationStore
+	kolide.JobStore
 
 	InviteStore
 	UserStore
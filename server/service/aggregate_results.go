@@ -0,0 +1,171 @@
+package service
+
+import (
+	"container/heap"
+	"strings"
+
+	"github.com/kolide/kolide-ose/server/kolide"
+)
+
+// defaultAggregateTopN is the number of values reported in an aggregate
+// update when the client does not specify one.
+const defaultAggregateTopN = 10
+
+// maxAggregateValues limits the number of distinct value combinations tracked
+// for a campaign, so that grouping by a high cardinality column does not grow
+// the aggregate without bound. Rows with further combinations are still
+// counted in the totals.
+const maxAggregateValues = 10000
+
+// resultAggregator incrementally groups distributed query results by a set of
+// columns, counting the distinct hosts and the rows for each combination of
+// values. It is not safe for concurrent use.
+type resultAggregator struct {
+	groupBy []string
+	topN    int
+
+	hosts     map[uint]bool
+	failed    map[uint]bool
+	rows      uint
+	buckets   map[string]*aggregateBucket
+	truncated bool
+
+	// changed is set when results were added since the last snapshot
+	changed bool
+}
+
+type aggregateBucket struct {
+	key    string
+	values map[string]string
+	hosts  uint
+	rows   uint
+
+	// lastHost is the host whose result was last counted in the bucket.
+	// Each host's result is added at once, so the hosts of a bucket are
+	// counted without keeping a set of them.
+	lastHost uint
+}
+
+func newResultAggregator(opts kolide.CampaignAggregateOptions) *resultAggregator {
+	topN := opts.TopN
+	if topN <= 0 {
+		topN = defaultAggregateTopN
+	}
+	return &resultAggregator{
+		groupBy: opts.GroupBy,
+		topN:    topN,
+		hosts:   map[uint]bool{},
+		failed:  map[uint]bool{},
+		buckets: map[string]*aggregateBucket{},
+	}
+}
+
+// add folds a single host's result into the aggregate. Only the first result
+// received from a host is counted.
+func (a *resultAggregator) add(res kolide.DistributedQueryResult) {
+	if a.hosts[res.Host.ID] {
+		return
+	}
+	a.changed = true
+	a.hosts[res.Host.ID] = true
+	if res.Error != nil {
+		a.failed[res.Host.ID] = true
+		return
+	}
+
+	for _, row := range res.Rows {
+		a.rows++
+
+		// Columns missing from a row are grouped as empty values
+		values := make([]string, len(a.groupBy))
+		for i, col := range a.groupBy {
+			values[i] = row[col]
+		}
+		key := strings.Join(values, "\x00")
+
+		bucket, ok := a.buckets[key]
+		if !ok {
+			if len(a.buckets) >= maxAggregateValues {
+				a.truncated = true
+				continue
+			}
+			bucket = &aggregateBucket{
+				key:    key,
+				values: make(map[string]string, len(a.groupBy)),
+			}
+			for i, col := range a.groupBy {
+				bucket.values[col] = values[i]
+			}
+			a.buckets[key] = bucket
+		}
+		if bucket.hosts == 0 || bucket.lastHost != res.Host.ID {
+			bucket.hosts++
+			bucket.lastHost = res.Host.ID
+		}
+		bucket.rows++
+	}
+}
+
+// snapshot returns the current state of the aggregate, with the top values
+// ordered by host count, then row count.
+func (a *resultAggregator) snapshot() kolide.CampaignAggregate {
+	a.changed = false
+
+	// The heap holds the best topN buckets seen, with the lowest ranked on
+	// top so that it can be replaced when a better bucket is found
+	h := make(aggregateHeap, 0, a.topN+1)
+	for _, bucket := range a.buckets {
+		heap.Push(&h, bucket)
+		if h.Len() > a.topN {
+			heap.Pop(&h)
+		}
+	}
+	top := make([]kolide.CampaignAggregateValue, h.Len())
+	for i := len(top) - 1; i >= 0; i-- {
+		bucket := heap.Pop(&h).(*aggregateBucket)
+		top[i] = kolide.CampaignAggregateValue{
+			Values: bucket.values,
+			Hosts:  bucket.hosts,
+			Rows:   bucket.rows,
+		}
+	}
+
+	return kolide.CampaignAggregate{
+		GroupBy:        a.groupBy,
+		HostsResponded: uint(len(a.hosts)),
+		HostsFailed:    uint(len(a.failed)),
+		TotalRows:      a.rows,
+		DistinctValues: uint(len(a.buckets)),
+		Truncated:      a.truncated,
+		Top:            top,
+	}
+}
+
+// ranksBefore returns true if bucket x is reported before bucket y.
+func (x *aggregateBucket) ranksBefore(y *aggregateBucket) bool {
+	if x.hosts != y.hosts {
+		return x.hosts > y.hosts
+	}
+	if x.rows != y.rows {
+		return x.rows > y.rows
+	}
+	return x.key < y.key
+}
+
+// aggregateHeap is a heap of buckets with the lowest ranked bucket first.
+type aggregateHeap []*aggregateBucket
+
+func (h aggregateHeap) Len() int           { return len(h) }
+func (h aggregateHeap) Less(i, j int) bool { return h[j].ranksBefore(h[i]) }
+func (h aggregateHeap) Swap(i, j int)      { h[i], h[j] = h[j], h[i] }
+
+func (h *aggregateHeap) Push(x interface{}) {
+	*h = append(*h, x.(*aggregateBucket))
+}
+
+func (h *aggregateHeap) Pop() interface{} {
+	old := *h
+	bucket := old[len(old)-1]
+	*h = old[:len(old)-1]
+	return bucket
+}
@@ -0,0 +1,129 @@
+package service
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/kolide/kolide-ose/server/kolide"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestResultAggregator(t *testing.T) {
+	agg := newResultAggregator(kolide.CampaignAggregateOptions{
+		GroupBy: []string{"name", "version"},
+		TopN:    2,
+	})
+	assert.False(t, agg.changed)
+
+	errString := "failed"
+	results := []kolide.DistributedQueryResult{
+		{
+			Host: kolide.Host{ID: 1},
+			Rows: []map[string]string{
+				{"name": "openssl", "version": "1.0.1", "arch": "x86_64"},
+				{"name": "bash", "version": "4.3"},
+			},
+		},
+		{
+			Host: kolide.Host{ID: 2},
+			Rows: []map[string]string{
+				{"name": "openssl", "version": "1.0.1"},
+				{"name": "openssl", "version": "1.0.1"},
+			},
+		},
+		{
+			Host: kolide.Host{ID: 3},
+			Rows: []map[string]string{
+				{"name": "openssl", "version": "1.0.2"},
+				{"name": "bash", "version": "4.3"},
+			},
+		},
+		{
+			Host:  kolide.Host{ID: 4},
+			Error: &errString,
+		},
+	}
+	for _, res := range results {
+		agg.add(res)
+	}
+	assert.True(t, agg.changed)
+
+	snap := agg.snapshot()
+	assert.False(t, agg.changed)
+
+	assert.Equal(t, []string{"name", "version"}, snap.GroupBy)
+	assert.Equal(t, uint(4), snap.HostsResponded)
+	assert.Equal(t, uint(1), snap.HostsFailed)
+	assert.Equal(t, uint(6), snap.TotalRows)
+	assert.Equal(t, uint(3), snap.DistinctValues)
+
+	require.Len(t, snap.Top, 2)
+	assert.Equal(t, map[string]string{"name": "openssl", "version": "1.0.1"}, snap.Top[0].Values)
+	assert.Equal(t, uint(2), snap.Top[0].Hosts)
+	assert.Equal(t, uint(3), snap.Top[0].Rows)
+	assert.Equal(t, map[string]string{"name": "bash", "version": "4.3"}, snap.Top[1].Values)
+	assert.Equal(t, uint(2), snap.Top[1].Hosts)
+	assert.Equal(t, uint(2), snap.Top[1].Rows)
+}
+
+func TestResultAggregatorDefaultTopN(t *testing.T) {
+	agg := newResultAggregator(kolide.CampaignAggregateOptions{
+		GroupBy: []string{"pid"},
+	})
+	assert.Equal(t, defaultAggregateTopN, agg.topN)
+
+	var rows []map[string]string
+	for i := 0; i < 2*defaultAggregateTopN; i++ {
+		rows = append(rows, map[string]string{"pid": string('a' + rune(i))})
+	}
+	agg.add(kolide.DistributedQueryResult{Host: kolide.Host{ID: 1}, Rows: rows})
+
+	snap := agg.snapshot()
+	assert.Equal(t, uint(2*defaultAggregateTopN), snap.DistinctValues)
+	assert.Len(t, snap.Top, defaultAggregateTopN)
+}
+
+func TestResultAggregatorDuplicateHost(t *testing.T) {
+	agg := newResultAggregator(kolide.CampaignAggregateOptions{
+		GroupBy: []string{"name"},
+	})
+
+	res := kolide.DistributedQueryResult{
+		Host: kolide.Host{ID: 1},
+		Rows: []map[string]string{{"name": "bash"}, {"name": "bash"}},
+	}
+	agg.add(res)
+	agg.snapshot()
+	agg.add(res)
+	assert.False(t, agg.changed)
+
+	snap := agg.snapshot()
+	assert.Equal(t, uint(1), snap.HostsResponded)
+	assert.Equal(t, uint(2), snap.TotalRows)
+	require.Len(t, snap.Top, 1)
+	assert.Equal(t, uint(1), snap.Top[0].Hosts)
+	assert.Equal(t, uint(2), snap.Top[0].Rows)
+}
+
+func TestResultAggregatorTruncated(t *testing.T) {
+	agg := newResultAggregator(kolide.CampaignAggregateOptions{
+		GroupBy: []string{"pid"},
+		TopN:    1,
+	})
+
+	var rows []map[string]string
+	for i := 0; i < maxAggregateValues+5; i++ {
+		rows = append(rows, map[string]string{"pid": fmt.Sprint(i)})
+	}
+	rows = append(rows, map[string]string{"pid": "7"})
+	agg.add(kolide.DistributedQueryResult{Host: kolide.Host{ID: 1}, Rows: rows})
+
+	snap := agg.snapshot()
+	assert.True(t, snap.Truncated)
+	assert.Equal(t, uint(maxAggregateValues), snap.DistinctValues)
+	assert.Equal(t, uint(maxAggregateValues+6), snap.TotalRows)
+	require.Len(t, snap.Top, 1)
+	assert.Equal(t, map[string]string{"pid": "7"}, snap.Top[0].Values)
+	assert.Equal(t, uint(2), snap.Top[0].Rows)
+}
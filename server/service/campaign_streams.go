@@ -0,0 +1,71 @@
+package service
+
+import (
+	"sync"
+
+	"golang.org/x/net/context"
+)
+
+// campaignStreams tracks the running campaign result streams, so that they
+// can be closed when the server shuts down.
+type campaignStreams struct {
+	mtx     sync.Mutex
+	wg      sync.WaitGroup
+	closing bool
+	nextID  int
+	cancels map[int]context.CancelFunc
+}
+
+func newCampaignStreams() *campaignStreams {
+	return &campaignStreams{cancels: map[int]context.CancelFunc{}}
+}
+
+// start registers a stream, returning the context of the stream, which is
+// done when the streams are closed, and the function to call when the
+// stream ends. ok is false when the streams are already closing, in which
+// case the stream must not start.
+func (s *campaignStreams) start(ctx context.Context) (streamCtx context.Context, done func(), ok bool) {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	if s.closing {
+		return nil, nil, false
+	}
+
+	id := s.nextID
+	s.nextID++
+	streamCtx, cancel := context.WithCancel(ctx)
+	s.cancels[id] = cancel
+	s.wg.Add(1)
+
+	done = func() {
+		s.mtx.Lock()
+		delete(s.cancels, id)
+		s.mtx.Unlock()
+		cancel()
+		s.wg.Done()
+	}
+	return streamCtx, done, true
+}
+
+// close ends the running streams and prevents new ones from starting. It
+// waits for the streams to end, or until ctx is done.
+func (s *campaignStreams) close(ctx context.Context) error {
+	s.mtx.Lock()
+	s.closing = true
+	for _, cancel := range s.cancels {
+		cancel()
+	}
+	s.mtx.Unlock()
+
+	ended := make(chan struct{})
+	go func() {
+		s.wg.Wait()
+		close(ended)
+	}()
+	select {
+	case <-ended:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
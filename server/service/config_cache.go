@@ -0,0 +1,137 @@
+package service
+
+import (
+	kitlog "github.com/go-kit/kit/log"
+	"github.com/kolide/kolide-ose/server/kolide"
+	"golang.org/x/net/context"
+)
+
+// configCacheMiddleware invalidates the osquery config cache whenever the
+// packs, scheduled queries, options, labels or pack targets that configs are
+// generated from are changed.
+type configCacheMiddleware struct {
+	kolide.Service
+	cache  kolide.OsqueryConfigCache
+	logger kitlog.Logger
+}
+
+// invalidate invalidates the cache when the change succeeded. Failing to
+// invalidate does not fail the change, and the stale configs expire with the
+// cache TTL.
+func (mw configCacheMiddleware) invalidate(method string, err error) {
+	if err != nil {
+		return
+	}
+	if err := mw.cache.Invalidate(); err != nil {
+		mw.logger.Log("method", method, "err", err, "msg", "invalidating osquery config cache")
+	}
+}
+
+func (mw configCacheMiddleware) NewPack(ctx context.Context, p kolide.PackPayload) (*kolide.Pack, error) {
+	pack, err := mw.Service.NewPack(ctx, p)
+	mw.invalidate("NewPack", err)
+	return pack, err
+}
+
+func (mw configCacheMiddleware) ModifyPack(ctx context.Context, id uint, p kolide.PackPayload) (*kolide.Pack, error) {
+	pack, err := mw.Service.ModifyPack(ctx, id, p)
+	mw.invalidate("ModifyPack", err)
+	return pack, err
+}
+
+func (mw configCacheMiddleware) DeletePack(ctx context.Context, id uint) error {
+	err := mw.Service.DeletePack(ctx, id)
+	mw.invalidate("DeletePack", err)
+	return err
+}
+
+func (mw configCacheMiddleware) AddLabelToPack(ctx context.Context, lid, pid uint) error {
+	err := mw.Service.AddLabelToPack(ctx, lid, pid)
+	mw.invalidate("AddLabelToPack", err)
+	return err
+}
+
+func (mw configCacheMiddleware) RemoveLabelFromPack(ctx context.Context, lid, pid uint) error {
+	err := mw.Service.RemoveLabelFromPack(ctx, lid, pid)
+	mw.invalidate("RemoveLabelFromPack", err)
+	return err
+}
+
+func (mw configCacheMiddleware) AddHostToPack(ctx context.Context, hid, pid uint) error {
+	err := mw.Service.AddHostToPack(ctx, hid, pid)
+	mw.invalidate("AddHostToPack", err)
+	return err
+}
+
+func (mw configCacheMiddleware) RemoveHostFromPack(ctx context.Context, hid, pid uint) error {
+	err := mw.Service.RemoveHostFromPack(ctx, hid, pid)
+	mw.invalidate("RemoveHostFromPack", err)
+	return err
+}
+
+func (mw configCacheMiddleware) ScheduleQuery(ctx context.Context, sq *kolide.ScheduledQuery) (*kolide.ScheduledQuery, error) {
+	query, err := mw.Service.ScheduleQuery(ctx, sq)
+	mw.invalidate("ScheduleQuery", err)
+	return query, err
+}
+
+func (mw configCacheMiddleware) ModifyScheduledQuery(ctx context.Context, sq *kolide.ScheduledQuery) (*kolide.ScheduledQuery, error) {
+	query, err := mw.Service.ModifyScheduledQuery(ctx, sq)
+	mw.invalidate("ModifyScheduledQuery", err)
+	return query, err
+}
+
+func (mw configCacheMiddleware) DeleteScheduledQuery(ctx context.Context, id uint) error {
+	err := mw.Service.DeleteScheduledQuery(ctx, id)
+	mw.invalidate("DeleteScheduledQuery", err)
+	return err
+}
+
+func (mw configCacheMiddleware) ModifyQuery(ctx context.Context, id uint, p kolide.QueryPayload) (*kolide.Query, error) {
+	query, err := mw.Service.ModifyQuery(ctx, id, p)
+	mw.invalidate("ModifyQuery", err)
+	return query, err
+}
+
+func (mw configCacheMiddleware) DeleteQuery(ctx context.Context, id uint) error {
+	err := mw.Service.DeleteQuery(ctx, id)
+	mw.invalidate("DeleteQuery", err)
+	return err
+}
+
+func (mw configCacheMiddleware) DeleteQueries(ctx context.Context, ids []uint) (uint, error) {
+	n, err := mw.Service.DeleteQueries(ctx, ids)
+	mw.invalidate("DeleteQueries", err)
+	return n, err
+}
+
+func (mw configCacheMiddleware) ModifyOptions(ctx context.Context, req kolide.OptionRequest) ([]kolide.Option, error) {
+	options, err := mw.Service.ModifyOptions(ctx, req)
+	mw.invalidate("ModifyOptions", err)
+	return options, err
+}
+
+func (mw configCacheMiddleware) NewLabel(ctx context.Context, p kolide.LabelPayload) (*kolide.Label, error) {
+	label, err := mw.Service.NewLabel(ctx, p)
+	mw.invalidate("NewLabel", err)
+	return label, err
+}
+
+func (mw configCacheMiddleware) DeleteLabel(ctx context.Context, id uint) error {
+	err := mw.Service.DeleteLabel(ctx, id)
+	mw.invalidate("DeleteLabel", err)
+	return err
+}
+
+func (mw configCacheMiddleware) ImportConfig(ctx context.Context, cfg *kolide.ImportConfig) (*kolide.ImportConfigResponse, error) {
+	resp, err := mw.Service.ImportConfig(ctx, cfg)
+	// An import that fails part way may still have changed packs and options
+	mw.invalidate("ImportConfig", nil)
+	return resp, err
+}
+
+func (mw configCacheMiddleware) RestoreRevision(ctx context.Context, id uint) (*kolide.Revision, error) {
+	revision, err := mw.Service.RestoreRevision(ctx, id)
+	mw.invalidate("RestoreRevision", err)
+	return revision, err
+}
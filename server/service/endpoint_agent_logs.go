@@ -0,0 +1,75 @@
+package service
+
+import (
+	"github.com/go-kit/kit/endpoint"
+	"github.com/kolide/kolide-ose/server/kolide"
+	"golang.org/x/net/context"
+)
+
+////////////////////////////////////////////////////////////////////////////////
+// List Host Agent Logs
+////////////////////////////////////////////////////////////////////////////////
+
+type listHostAgentLogsRequest struct {
+	ID          uint
+	ListOptions kolide.ListOptions
+}
+
+type listHostAgentLogsResponse struct {
+	AgentLogs []kolide.AgentLog     `json:"agent_logs"`
+	Counts    kolide.AgentLogCounts `json:"counts"`
+	Err       error                 `json:"error,omitempty"`
+}
+
+func (r listHostAgentLogsResponse) error() error { return r.Err }
+
+func makeListHostAgentLogsEndpoint(svc kolide.Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(listHostAgentLogsRequest)
+		logs, counts, err := svc.ListHostAgentLogs(ctx, req.ID, req.ListOptions)
+		if err != nil {
+			return listHostAgentLogsResponse{Err: err}, nil
+		}
+
+		resp := listHostAgentLogsResponse{
+			AgentLogs: []kolide.AgentLog{},
+			Counts:    counts,
+		}
+		for _, log := range logs {
+			resp.AgentLogs = append(resp.AgentLogs, *log)
+		}
+		return resp, nil
+	}
+}
+
+////////////////////////////////////////////////////////////////////////////////
+// List Agent Log Summaries
+////////////////////////////////////////////////////////////////////////////////
+
+type listAgentLogSummariesRequest struct {
+	Severity string
+	Limit    uint
+}
+
+type listAgentLogSummariesResponse struct {
+	Summaries []kolide.AgentLogSummary `json:"summaries"`
+	Err       error                    `json:"error,omitempty"`
+}
+
+func (r listAgentLogSummariesResponse) error() error { return r.Err }
+
+func makeListAgentLogSummariesEndpoint(svc kolide.Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(listAgentLogSummariesRequest)
+		summaries, err := svc.ListAgentLogSummaries(ctx, req.Severity, req.Limit)
+		if err != nil {
+			return listAgentLogSummariesResponse{Err: err}, nil
+		}
+
+		resp := listAgentLogSummariesResponse{Summaries: []kolide.AgentLogSummary{}}
+		for _, summary := range summaries {
+			resp.Summaries = append(resp.Summaries, *summary)
+		}
+		return resp, nil
+	}
+}
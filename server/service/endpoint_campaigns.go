@@ -79,7 +79,14 @@ func makeStreamDistributedQueryCampaignResultsHandler(svc kolide.Service, jwtKey
 			return
 		}
 
-		svc.StreamCampaignResults(ctx, conn, campaignID)
+		opts, err := decodeCampaignAggregateOptions(r)
+		if err != nil {
+			logger.Log("err", err, "msg", "invalid aggregate options")
+			conn.WriteJSONError(err.Error())
+			return
+		}
+
+		svc.StreamCampaignResults(ctx, conn, campaignID, opts)
 
 	}
 }
@@ -0,0 +1,142 @@
+package service
+
+import (
+	"github.com/go-kit/kit/endpoint"
+	"github.com/kolide/kolide-ose/server/kolide"
+	"golang.org/x/net/context"
+)
+
+////////////////////////////////////////////////////////////////////////////////
+// Get Detail Query
+////////////////////////////////////////////////////////////////////////////////
+
+type getDetailQueryRequest struct {
+	ID uint
+}
+
+type getDetailQueryResponse struct {
+	DetailQuery *kolide.DetailQuery `json:"detail_query,omitempty"`
+	Err         error               `json:"error,omitempty"`
+}
+
+func (r getDetailQueryResponse) error() error { return r.Err }
+
+func makeGetDetailQueryEndpoint(svc kolide.Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(getDetailQueryRequest)
+		query, err := svc.GetDetailQuery(ctx, req.ID)
+		if err != nil {
+			return getDetailQueryResponse{Err: err}, nil
+		}
+		return getDetailQueryResponse{DetailQuery: query}, nil
+	}
+}
+
+////////////////////////////////////////////////////////////////////////////////
+// List Detail Queries
+////////////////////////////////////////////////////////////////////////////////
+
+type listDetailQueriesRequest struct {
+	ListOptions kolide.ListOptions
+}
+
+type listDetailQueriesResponse struct {
+	DetailQueries []kolide.DetailQuery `json:"detail_queries"`
+	Err           error                `json:"error,omitempty"`
+}
+
+func (r listDetailQueriesResponse) error() error { return r.Err }
+
+func makeListDetailQueriesEndpoint(svc kolide.Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(listDetailQueriesRequest)
+		queries, err := svc.ListDetailQueries(ctx, req.ListOptions)
+		if err != nil {
+			return listDetailQueriesResponse{Err: err}, nil
+		}
+
+		resp := listDetailQueriesResponse{DetailQueries: []kolide.DetailQuery{}}
+		for _, query := range queries {
+			resp.DetailQueries = append(resp.DetailQueries, *query)
+		}
+		return resp, nil
+	}
+}
+
+////////////////////////////////////////////////////////////////////////////////
+// Create Detail Query
+////////////////////////////////////////////////////////////////////////////////
+
+type createDetailQueryRequest struct {
+	payload kolide.DetailQueryPayload
+}
+
+type createDetailQueryResponse struct {
+	DetailQuery *kolide.DetailQuery `json:"detail_query,omitempty"`
+	Err         error               `json:"error,omitempty"`
+}
+
+func (r createDetailQueryResponse) error() error { return r.Err }
+
+func makeCreateDetailQueryEndpoint(svc kolide.Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(createDetailQueryRequest)
+		query, err := svc.NewDetailQuery(ctx, req.payload)
+		if err != nil {
+			return createDetailQueryResponse{Err: err}, nil
+		}
+		return createDetailQueryResponse{DetailQuery: query}, nil
+	}
+}
+
+////////////////////////////////////////////////////////////////////////////////
+// Modify Detail Query
+////////////////////////////////////////////////////////////////////////////////
+
+type modifyDetailQueryRequest struct {
+	ID      uint
+	payload kolide.DetailQueryPayload
+}
+
+type modifyDetailQueryResponse struct {
+	DetailQuery *kolide.DetailQuery `json:"detail_query,omitempty"`
+	Err         error               `json:"error,omitempty"`
+}
+
+func (r modifyDetailQueryResponse) error() error { return r.Err }
+
+func makeModifyDetailQueryEndpoint(svc kolide.Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(modifyDetailQueryRequest)
+		query, err := svc.ModifyDetailQuery(ctx, req.ID, req.payload)
+		if err != nil {
+			return modifyDetailQueryResponse{Err: err}, nil
+		}
+		return modifyDetailQueryResponse{DetailQuery: query}, nil
+	}
+}
+
+////////////////////////////////////////////////////////////////////////////////
+// Delete Detail Query
+////////////////////////////////////////////////////////////////////////////////
+
+type deleteDetailQueryRequest struct {
+	ID uint
+}
+
+type deleteDetailQueryResponse struct {
+	Err error `json:"error,omitempty"`
+}
+
+func (r deleteDetailQueryResponse) error() error { return r.Err }
+
+func makeDeleteDetailQueryEndpoint(svc kolide.Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(deleteDetailQueryRequest)
+		err := svc.DeleteDetailQuery(ctx, req.ID)
+		if err != nil {
+			return deleteDetailQueryResponse{Err: err}, nil
+		}
+		return deleteDetailQueryResponse{}, nil
+	}
+}
@@ -0,0 +1,28 @@
+package service
+
+import (
+	"github.com/go-kit/kit/endpoint"
+	"github.com/kolide/kolide-ose/server/kolide"
+	"golang.org/x/net/context"
+)
+
+////////////////////////////////////////////////////////////////////////////////
+// Get Host Purge Report
+////////////////////////////////////////////////////////////////////////////////
+
+type getHostPurgeReportResponse struct {
+	kolide.HostPurgeReport
+	Err error `json:"error,omitempty"`
+}
+
+func (r getHostPurgeReportResponse) error() error { return r.Err }
+
+func makeGetHostPurgeReportEndpoint(svc kolide.Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		report, err := svc.GetHostPurgeReport(ctx)
+		if err != nil {
+			return getHostPurgeReportResponse{Err: err}, nil
+		}
+		return getHostPurgeReportResponse{HostPurgeReport: *report}, nil
+	}
+}
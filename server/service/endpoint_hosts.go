@@ -1,8 +1,6 @@
 package service
 
 import (
-	"time"
-
 	"github.com/go-kit/kit/endpoint"
 	"github.com/kolide/kolide-ose/server/kolide"
 	"golang.org/x/net/context"
@@ -17,7 +15,7 @@ type hostResponse struct {
 func hostResponseForHost(ctx context.Context, svc kolide.Service, host *kolide.Host) (*hostResponse, error) {
 	return &hostResponse{
 		Host:        *host,
-		Status:      host.Status(time.Now()),
+		Status:      svc.HostStatus(ctx, host),
 		DisplayText: host.HostName,
 	}, nil
 }
@@ -62,11 +60,15 @@ func makeGetHostEndpoint(svc kolide.Service) endpoint.Endpoint {
 
 type listHostsRequest struct {
 	ListOptions kolide.ListOptions
+	Filter      kolide.HostFilter
 }
 
 type listHostsResponse struct {
 	Hosts []hostResponse `json:"hosts"`
-	Err   error          `json:"error,omitempty"`
+	// TotalCount is the number of hosts matching the filter on all
+	// pages.
+	TotalCount uint  `json:"total_count"`
+	Err        error `json:"error,omitempty"`
 }
 
 func (r listHostsResponse) error() error { return r.Err }
@@ -74,7 +76,7 @@ func (r listHostsResponse) error() error { return r.Err }
 func makeListHostsEndpoint(svc kolide.Service) endpoint.Endpoint {
 	return func(ctx context.Context, request interface{}) (interface{}, error) {
 		req := request.(listHostsRequest)
-		hosts, err := svc.ListHosts(ctx, req.ListOptions)
+		hosts, err := svc.ListHosts(ctx, req.ListOptions, req.Filter)
 		if err != nil {
 			return listHostsResponse{Err: err}, nil
 		}
@@ -88,7 +90,12 @@ func makeListHostsEndpoint(svc kolide.Service) endpoint.Endpoint {
 
 			hostResponses[i] = *h
 		}
-		return listHostsResponse{Hosts: hostResponses}, nil
+
+		count, err := svc.CountHosts(ctx, req.Filter)
+		if err != nil {
+			return listHostsResponse{Err: err}, nil
+		}
+		return listHostsResponse{Hosts: hostResponses, TotalCount: count}, nil
 	}
 }
 
@@ -117,6 +124,32 @@ func makeGetHostSummaryEndpoint(svc kolide.Service) endpoint.Endpoint {
 	}
 }
 
+////////////////////////////////////////////////////////////////////////////////
+// Get Host Breakdown
+////////////////////////////////////////////////////////////////////////////////
+
+type getHostBreakdownRequest struct {
+	LabelID uint
+}
+
+type getHostBreakdownResponse struct {
+	kolide.HostBreakdown
+	Err error `json:"error,omitempty"`
+}
+
+func (r getHostBreakdownResponse) error() error { return r.Err }
+
+func makeGetHostBreakdownEndpoint(svc kolide.Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(getHostBreakdownRequest)
+		breakdown, err := svc.GetHostBreakdown(ctx, req.LabelID)
+		if err != nil {
+			return getHostBreakdownResponse{Err: err}, nil
+		}
+		return getHostBreakdownResponse{HostBreakdown: *breakdown}, nil
+	}
+}
+
 ////////////////////////////////////////////////////////////////////////////////
 // Delete Host
 ////////////////////////////////////////////////////////////////////////////////
@@ -141,3 +174,29 @@ func makeDeleteHostEndpoint(svc kolide.Service) endpoint.Endpoint {
 		return deleteHostResponse{}, nil
 	}
 }
+
+////////////////////////////////////////////////////////////////////////////////
+// Get Host Config
+////////////////////////////////////////////////////////////////////////////////
+
+type getHostConfigRequest struct {
+	ID uint `json:"id"`
+}
+
+type getHostConfigResponse struct {
+	kolide.HostConfig
+	Err error `json:"error,omitempty"`
+}
+
+func (r getHostConfigResponse) error() error { return r.Err }
+
+func makeGetHostConfigEndpoint(svc kolide.Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(getHostConfigRequest)
+		config, err := svc.GetHostConfig(ctx, req.ID)
+		if err != nil {
+			return getHostConfigResponse{Err: err}, nil
+		}
+		return getHostConfigResponse{HostConfig: *config}, nil
+	}
+}
@@ -0,0 +1,43 @@
+package service
+
+import (
+	"github.com/go-kit/kit/endpoint"
+	"github.com/kolide/kolide-ose/server/kolide"
+	"golang.org/x/net/context"
+)
+
+////////////////////////////////////////////////////////////////////////////////
+// List Jobs
+////////////////////////////////////////////////////////////////////////////////
+
+type jobResponse struct {
+	kolide.Job
+	Interval uint   `json:"interval"`
+	Status   string `json:"status"`
+}
+
+type listJobsResponse struct {
+	Jobs []jobResponse `json:"jobs"`
+	Err  error         `json:"error,omitempty"`
+}
+
+func (r listJobsResponse) error() error { return r.Err }
+
+func makeListJobsEndpoint(svc kolide.Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		jobs, err := svc.ListJobs(ctx)
+		if err != nil {
+			return listJobsResponse{Err: err}, nil
+		}
+
+		resp := listJobsResponse{Jobs: []jobResponse{}}
+		for _, job := range jobs {
+			resp.Jobs = append(resp.Jobs, jobResponse{
+				Job:      *job,
+				Interval: job.IntervalSeconds(),
+				Status:   job.Status(),
+			})
+		}
+		return resp, nil
+	}
+}
@@ -0,0 +1,24 @@
+package service
+
+import (
+	"github.com/go-kit/kit/endpoint"
+	"github.com/kolide/kolide-ose/server/kolide"
+	"golang.org/x/net/context"
+)
+
+type getOsquerySchemaResponse struct {
+	Tables []kolide.OsqueryTable `json:"tables,omitempty"`
+	Err    error                 `json:"error,omitempty"`
+}
+
+func (r getOsquerySchemaResponse) error() error { return r.Err }
+
+func makeGetOsquerySchemaEndpoint(svc kolide.Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		schema, err := svc.GetOsquerySchema(ctx)
+		if err != nil {
+			return getOsquerySchemaResponse{Err: err}, nil
+		}
+		return getOsquerySchemaResponse{Tables: schema.Tables}, nil
+	}
+}
@@ -37,6 +37,7 @@ func makeGetQueryEndpoint(svc kolide.Service) endpoint.Endpoint {
 ////////////////////////////////////////////////////////////////////////////////
 type listQueriesRequest struct {
 	ListOptions kolide.ListOptions
+	Filter      kolide.QueryFilter
 }
 
 type listQueriesResponse struct {
@@ -49,7 +50,7 @@ func (r listQueriesResponse) error() error { return r.Err }
 func makeListQueriesEndpoint(svc kolide.Service) endpoint.Endpoint {
 	return func(ctx context.Context, request interface{}) (interface{}, error) {
 		req := request.(listQueriesRequest)
-		queries, err := svc.ListQueries(ctx, req.ListOptions)
+		queries, err := svc.ListQueries(ctx, req.ListOptions, req.Filter)
 		if err != nil {
 			return listQueriesResponse{Err: err}, nil
 		}
@@ -0,0 +1,88 @@
+package service
+
+import (
+	"github.com/go-kit/kit/endpoint"
+	"github.com/kolide/kolide-ose/server/kolide"
+	"golang.org/x/net/context"
+)
+
+////////////////////////////////////////////////////////////////////////////////
+// List Revisions
+////////////////////////////////////////////////////////////////////////////////
+
+type listRevisionsRequest struct {
+	ObjectType  string
+	ObjectID    uint
+	ListOptions kolide.ListOptions
+}
+
+type listRevisionsResponse struct {
+	Revisions []*kolide.Revision `json:"revisions"`
+	Err       error              `json:"error,omitempty"`
+}
+
+func (r listRevisionsResponse) error() error { return r.Err }
+
+func makeListRevisionsEndpoint(svc kolide.Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(listRevisionsRequest)
+		revisions, err := svc.ListRevisions(ctx, req.ObjectType, req.ObjectID, req.ListOptions)
+		if err != nil {
+			return listRevisionsResponse{Err: err}, nil
+		}
+		return listRevisionsResponse{Revisions: revisions}, nil
+	}
+}
+
+////////////////////////////////////////////////////////////////////////////////
+// Diff Revisions
+////////////////////////////////////////////////////////////////////////////////
+
+type diffRevisionsRequest struct {
+	FromID uint
+	ToID   uint
+}
+
+type diffRevisionsResponse struct {
+	Changes []kolide.RevisionChange `json:"changes"`
+	Err     error                   `json:"error,omitempty"`
+}
+
+func (r diffRevisionsResponse) error() error { return r.Err }
+
+func makeDiffRevisionsEndpoint(svc kolide.Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(diffRevisionsRequest)
+		changes, err := svc.DiffRevisions(ctx, req.FromID, req.ToID)
+		if err != nil {
+			return diffRevisionsResponse{Err: err}, nil
+		}
+		return diffRevisionsResponse{Changes: changes}, nil
+	}
+}
+
+////////////////////////////////////////////////////////////////////////////////
+// Restore Revision
+////////////////////////////////////////////////////////////////////////////////
+
+type restoreRevisionRequest struct {
+	ID uint
+}
+
+type restoreRevisionResponse struct {
+	Revision *kolide.Revision `json:"revision,omitempty"`
+	Err      error            `json:"error,omitempty"`
+}
+
+func (r restoreRevisionResponse) error() error { return r.Err }
+
+func makeRestoreRevisionEndpoint(svc kolide.Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(restoreRevisionRequest)
+		revision, err := svc.RestoreRevision(ctx, req.ID)
+		if err != nil {
+			return restoreRevisionResponse{Err: err}, nil
+		}
+		return restoreRevisionResponse{Revision: revision}, nil
+	}
+}
@@ -78,6 +78,39 @@ func makeGetScheduledQueriesInPackEndpoint(svc kolide.Service) endpoint.Endpoint
 	}
 }
 
+////////////////////////////////////////////////////////////////////////////////
+// Get Scheduled Query Stats In Pack
+////////////////////////////////////////////////////////////////////////////////
+
+type getScheduledQueryStatsInPackRequest struct {
+	ID uint
+}
+
+type getScheduledQueryStatsInPackResponse struct {
+	Stats []kolide.ScheduledQueryStatsRollup `json:"stats"`
+	Err   error                              `json:"error,omitempty"`
+}
+
+func (r getScheduledQueryStatsInPackResponse) error() error { return r.Err }
+
+func makeGetScheduledQueryStatsInPackEndpoint(svc kolide.Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(getScheduledQueryStatsInPackRequest)
+		resp := getScheduledQueryStatsInPackResponse{Stats: []kolide.ScheduledQueryStatsRollup{}}
+
+		stats, err := svc.GetScheduledQueryStatsInPack(ctx, req.ID)
+		if err != nil {
+			return getScheduledQueryStatsInPackResponse{Err: err}, nil
+		}
+
+		for _, s := range stats {
+			resp.Stats = append(resp.Stats, *s)
+		}
+
+		return resp, nil
+	}
+}
+
 ////////////////////////////////////////////////////////////////////////////////
 // Schedule Query
 ////////////////////////////////////////////////////////////////////////////////
@@ -0,0 +1,103 @@
+package service
+
+import (
+	"github.com/go-kit/kit/endpoint"
+	"github.com/kolide/kolide-ose/server/kolide"
+	"golang.org/x/net/context"
+)
+
+////////////////////////////////////////////////////////////////////////////////
+// List Host Software
+////////////////////////////////////////////////////////////////////////////////
+
+type listHostSoftwareRequest struct {
+	HostID      uint
+	ListOptions kolide.ListOptions
+}
+
+type listHostSoftwareResponse struct {
+	Software []kolide.HostSoftware `json:"software"`
+	Err      error                 `json:"error,omitempty"`
+}
+
+func (r listHostSoftwareResponse) error() error { return r.Err }
+
+func makeListHostSoftwareEndpoint(svc kolide.Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(listHostSoftwareRequest)
+		software, err := svc.ListHostSoftware(ctx, req.HostID, req.ListOptions)
+		if err != nil {
+			return listHostSoftwareResponse{Err: err}, nil
+		}
+
+		resp := listHostSoftwareResponse{Software: []kolide.HostSoftware{}}
+		for _, s := range software {
+			resp.Software = append(resp.Software, *s)
+		}
+		return resp, nil
+	}
+}
+
+////////////////////////////////////////////////////////////////////////////////
+// Search Software
+////////////////////////////////////////////////////////////////////////////////
+
+type searchSoftwareRequest struct {
+	Filter      kolide.SoftwareFilter
+	ListOptions kolide.ListOptions
+}
+
+type searchSoftwareResponse struct {
+	Software []kolide.SoftwareCount `json:"software"`
+	Err      error                  `json:"error,omitempty"`
+}
+
+func (r searchSoftwareResponse) error() error { return r.Err }
+
+func makeSearchSoftwareEndpoint(svc kolide.Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(searchSoftwareRequest)
+		software, err := svc.SearchSoftware(ctx, req.Filter, req.ListOptions)
+		if err != nil {
+			return searchSoftwareResponse{Err: err}, nil
+		}
+
+		resp := searchSoftwareResponse{Software: []kolide.SoftwareCount{}}
+		for _, s := range software {
+			resp.Software = append(resp.Software, *s)
+		}
+		return resp, nil
+	}
+}
+
+////////////////////////////////////////////////////////////////////////////////
+// List Software Installs
+////////////////////////////////////////////////////////////////////////////////
+
+type listSoftwareInstallsRequest struct {
+	SoftwareID  uint
+	ListOptions kolide.ListOptions
+}
+
+type listSoftwareInstallsResponse struct {
+	Hosts []kolide.SoftwareInstall `json:"hosts"`
+	Err   error                    `json:"error,omitempty"`
+}
+
+func (r listSoftwareInstallsResponse) error() error { return r.Err }
+
+func makeListSoftwareInstallsEndpoint(svc kolide.Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(listSoftwareInstallsRequest)
+		installs, err := svc.ListSoftwareInstalls(ctx, req.SoftwareID, req.ListOptions)
+		if err != nil {
+			return listSoftwareInstallsResponse{Err: err}, nil
+		}
+
+		resp := listSoftwareInstallsResponse{Hosts: []kolide.SoftwareInstall{}}
+		for _, install := range installs {
+			resp.Hosts = append(resp.Hosts, *install)
+		}
+		return resp, nil
+	}
+}
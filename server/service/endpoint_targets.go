@@ -1,8 +1,6 @@
 package service
 
 import (
-	"time"
-
 	"github.com/go-kit/kit/endpoint"
 	"github.com/kolide/kolide-ose/server/kolide"
 	"golang.org/x/net/context"
@@ -69,7 +67,7 @@ func makeSearchTargetsEndpoint(svc kolide.Service) endpoint.Endpoint {
 				hostSearchResult{
 					hostResponse{
 						Host:   host,
-						Status: host.Status(time.Now()),
+						Status: svc.HostStatus(ctx, &host),
 					},
 					host.HostName,
 				},
@@ -107,3 +105,37 @@ func makeSearchTargetsEndpoint(svc kolide.Service) endpoint.Endpoint {
 		}, nil
 	}
 }
+
+////////////////////////////////////////////////////////////////////////////////
+// Resolve Targets
+////////////////////////////////////////////////////////////////////////////////
+
+type resolveTargetsRequest struct {
+	ListOptions kolide.ListOptions `json:"-"`
+	Selected    struct {
+		Labels []uint `json:"labels"`
+		Hosts  []uint `json:"hosts"`
+	} `json:"selected"`
+}
+
+type resolveTargetsResponse struct {
+	Hosts      []kolide.ResolvedHost `json:"hosts"`
+	TotalHosts uint                  `json:"total_hosts"`
+	Err        error                 `json:"error,omitempty"`
+}
+
+func (r resolveTargetsResponse) error() error { return r.Err }
+
+func makeResolveTargetsEndpoint(svc kolide.Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(resolveTargetsRequest)
+		resolved, err := svc.ResolveTargets(ctx, req.Selected.Hosts, req.Selected.Labels, req.ListOptions)
+		if err != nil {
+			return resolveTargetsResponse{Err: err}, nil
+		}
+		return resolveTargetsResponse{
+			Hosts:      resolved.Hosts,
+			TotalHosts: resolved.TotalHosts,
+		}, nil
+	}
+}
@@ -0,0 +1,131 @@
+package service
+
+import (
+	"io"
+
+	"github.com/go-kit/kit/endpoint"
+	"github.com/kolide/kolide-ose/server/kolide"
+	"golang.org/x/net/context"
+)
+
+////////////////////////////////////////////////////////////////////////////////
+// Import Vulnerabilities
+////////////////////////////////////////////////////////////////////////////////
+
+type importVulnerabilitiesRequest struct {
+	Format string
+	Feed   io.Reader
+}
+
+type importVulnerabilitiesResponse struct {
+	Imported int   `json:"imported"`
+	Err      error `json:"error,omitempty"`
+}
+
+func (r importVulnerabilitiesResponse) error() error { return r.Err }
+
+func makeImportVulnerabilitiesEndpoint(svc kolide.Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(importVulnerabilitiesRequest)
+		imported, err := svc.ImportVulnerabilities(ctx, req.Format, req.Feed)
+		if err != nil {
+			return importVulnerabilitiesResponse{Err: err}, nil
+		}
+		return importVulnerabilitiesResponse{Imported: imported}, nil
+	}
+}
+
+////////////////////////////////////////////////////////////////////////////////
+// List Vulnerabilities
+////////////////////////////////////////////////////////////////////////////////
+
+type listVulnerabilitiesRequest struct {
+	ListOptions kolide.ListOptions
+}
+
+type listVulnerabilitiesResponse struct {
+	Vulnerabilities []kolide.VulnerabilityCount `json:"vulnerabilities"`
+	Err             error                       `json:"error,omitempty"`
+}
+
+func (r listVulnerabilitiesResponse) error() error { return r.Err }
+
+func makeListVulnerabilitiesEndpoint(svc kolide.Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(listVulnerabilitiesRequest)
+		vulns, err := svc.ListVulnerabilities(ctx, req.ListOptions)
+		if err != nil {
+			return listVulnerabilitiesResponse{Err: err}, nil
+		}
+
+		resp := listVulnerabilitiesResponse{Vulnerabilities: []kolide.VulnerabilityCount{}}
+		for _, vuln := range vulns {
+			resp.Vulnerabilities = append(resp.Vulnerabilities, *vuln)
+		}
+		return resp, nil
+	}
+}
+
+////////////////////////////////////////////////////////////////////////////////
+// List Vulnerable Hosts
+////////////////////////////////////////////////////////////////////////////////
+
+type listVulnerableHostsRequest struct {
+	CVE         string
+	ListOptions kolide.ListOptions
+}
+
+type listVulnerableHostsResponse struct {
+	Hosts []kolide.VulnerableHost `json:"hosts"`
+	Err   error                   `json:"error,omitempty"`
+}
+
+func (r listVulnerableHostsResponse) error() error { return r.Err }
+
+func makeListVulnerableHostsEndpoint(svc kolide.Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(listVulnerableHostsRequest)
+		hosts, err := svc.ListVulnerableHosts(ctx, req.CVE, req.ListOptions)
+		if err != nil {
+			return listVulnerableHostsResponse{Err: err}, nil
+		}
+
+		resp := listVulnerableHostsResponse{Hosts: []kolide.VulnerableHost{}}
+		for _, host := range hosts {
+			resp.Hosts = append(resp.Hosts, *host)
+		}
+		return resp, nil
+	}
+}
+
+////////////////////////////////////////////////////////////////////////////////
+// List Host Vulnerabilities
+////////////////////////////////////////////////////////////////////////////////
+
+type listHostVulnerabilitiesRequest struct {
+	HostID      uint
+	ListOptions kolide.ListOptions
+}
+
+type listHostVulnerabilitiesResponse struct {
+	Vulnerabilities []kolide.HostVulnerability `json:"vulnerabilities"`
+	Err             error                      `json:"error,omitempty"`
+}
+
+func (r listHostVulnerabilitiesResponse) error() error { return r.Err }
+
+func makeListHostVulnerabilitiesEndpoint(svc kolide.Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(listHostVulnerabilitiesRequest)
+		vulns, err := svc.ListHostVulnerabilities(ctx, req.HostID, req.ListOptions)
+		if err != nil {
+			return listHostVulnerabilitiesResponse{Err: err}, nil
+		}
+
+		resp := listHostVulnerabilitiesResponse{Vulnerabilities: []kolide.HostVulnerability{}}
+		for _, vuln := range vulns {
+			resp.Vulnerabilities = append(resp.Vulnerabilities, *vuln)
+		}
+		return resp, nil
+	}
+}
@@ -53,6 +53,21 @@ type KolideEndpoints struct {
 	DeletePack                     endpoint.Endpoint
 	ScheduleQuery                  endpoint.Endpoint
 	GetScheduledQueriesInPack      endpoint.Endpoint
+	GetScheduledQueryStatsInPack   endpoint.Endpoint
+	ListHostAgentLogs              endpoint.Endpoint
+	ListAgentLogSummaries          endpoint.Endpoint
+	ListDetailQueries              endpoint.Endpoint
+	GetDetailQuery                 endpoint.Endpoint
+	CreateDetailQuery              endpoint.Endpoint
+	ModifyDetailQuery              endpoint.Endpoint
+	DeleteDetailQuery              endpoint.Endpoint
+	ListHostSoftware               endpoint.Endpoint
+	SearchSoftware                 endpoint.Endpoint
+	ListSoftwareInstalls           endpoint.Endpoint
+	ImportVulnerabilities          endpoint.Endpoint
+	ListVulnerabilities            endpoint.Endpoint
+	ListVulnerableHosts            endpoint.Endpoint
+	ListHostVulnerabilities        endpoint.Endpoint
 	GetScheduledQuery              endpoint.Endpoint
 	ModifyScheduledQuery           endpoint.Endpoint
 	DeleteScheduledQuery           endpoint.Endpoint
@@ -69,10 +84,19 @@ type KolideEndpoints struct {
 	DeleteHost                     endpoint.Endpoint
 	ListHosts                      endpoint.Endpoint
 	GetHostSummary                 endpoint.Endpoint
+	GetHostPurgeReport             endpoint.Endpoint
+	ListJobs                       endpoint.Endpoint
+	GetHostBreakdown               endpoint.Endpoint
+	GetHostConfig                  endpoint.Endpoint
 	SearchTargets                  endpoint.Endpoint
+	ResolveTargets                 endpoint.Endpoint
 	GetOptions                     endpoint.Endpoint
 	ModifyOptions                  endpoint.Endpoint
 	ImportConfig                   endpoint.Endpoint
+	GetOsquerySchema               endpoint.Endpoint
+	ListRevisions                  endpoint.Endpoint
+	DiffRevisions                  endpoint.Endpoint
+	RestoreRevision                endpoint.Endpoint
 }
 
 // MakeKolideServerEndpoints creates the Kolide API endpoints.
@@ -126,21 +150,45 @@ func MakeKolideServerEndpoints(svc kolide.Service, jwtKey string) KolideEndpoint
 		DeletePack:                authenticatedUser(jwtKey, svc, makeDeletePackEndpoint(svc)),
 		ScheduleQuery:             authenticatedUser(jwtKey, svc, makeScheduleQueryEndpoint(svc)),
 		GetScheduledQueriesInPack: authenticatedUser(jwtKey, svc, makeGetScheduledQueriesInPackEndpoint(svc)),
+		GetScheduledQueryStatsInPack: authenticatedUser(jwtKey, svc, makeGetScheduledQueryStatsInPackEndpoint(svc)),
+		ListHostAgentLogs:            authenticatedUser(jwtKey, svc, makeListHostAgentLogsEndpoint(svc)),
+		ListAgentLogSummaries:        authenticatedUser(jwtKey, svc, makeListAgentLogSummariesEndpoint(svc)),
+		ListDetailQueries:            authenticatedUser(jwtKey, svc, makeListDetailQueriesEndpoint(svc)),
+		GetDetailQuery:               authenticatedUser(jwtKey, svc, makeGetDetailQueryEndpoint(svc)),
+		CreateDetailQuery:            authenticatedUser(jwtKey, svc, mustBeAdmin(makeCreateDetailQueryEndpoint(svc))),
+		ModifyDetailQuery:            authenticatedUser(jwtKey, svc, mustBeAdmin(makeModifyDetailQueryEndpoint(svc))),
+		DeleteDetailQuery:            authenticatedUser(jwtKey, svc, mustBeAdmin(makeDeleteDetailQueryEndpoint(svc))),
+		ListHostSoftware:             authenticatedUser(jwtKey, svc, makeListHostSoftwareEndpoint(svc)),
+		SearchSoftware:               authenticatedUser(jwtKey, svc, makeSearchSoftwareEndpoint(svc)),
+		ListSoftwareInstalls:         authenticatedUser(jwtKey, svc, makeListSoftwareInstallsEndpoint(svc)),
+		ImportVulnerabilities:        authenticatedUser(jwtKey, svc, mustBeAdmin(makeImportVulnerabilitiesEndpoint(svc))),
+		ListVulnerabilities:          authenticatedUser(jwtKey, svc, makeListVulnerabilitiesEndpoint(svc)),
+		ListVulnerableHosts:          authenticatedUser(jwtKey, svc, makeListVulnerableHostsEndpoint(svc)),
+		ListHostVulnerabilities:      authenticatedUser(jwtKey, svc, makeListHostVulnerabilitiesEndpoint(svc)),
 		GetScheduledQuery:         authenticatedUser(jwtKey, svc, makeGetScheduledQueryEndpoint(svc)),
 		ModifyScheduledQuery:      authenticatedUser(jwtKey, svc, makeModifyScheduledQueryEndpoint(svc)),
 		DeleteScheduledQuery:      authenticatedUser(jwtKey, svc, makeDeleteScheduledQueryEndpoint(svc)),
 		GetHost:                   authenticatedUser(jwtKey, svc, makeGetHostEndpoint(svc)),
 		ListHosts:                 authenticatedUser(jwtKey, svc, makeListHostsEndpoint(svc)),
 		GetHostSummary:            authenticatedUser(jwtKey, svc, makeGetHostSummaryEndpoint(svc)),
+		GetHostPurgeReport:        authenticatedUser(jwtKey, svc, mustBeAdmin(makeGetHostPurgeReportEndpoint(svc))),
+		ListJobs:                  authenticatedUser(jwtKey, svc, mustBeAdmin(makeListJobsEndpoint(svc))),
+		GetHostBreakdown:          authenticatedUser(jwtKey, svc, makeGetHostBreakdownEndpoint(svc)),
+		GetHostConfig:             authenticatedUser(jwtKey, svc, mustBeAdmin(makeGetHostConfigEndpoint(svc))),
 		DeleteHost:                authenticatedUser(jwtKey, svc, makeDeleteHostEndpoint(svc)),
 		GetLabel:                  authenticatedUser(jwtKey, svc, makeGetLabelEndpoint(svc)),
 		ListLabels:                authenticatedUser(jwtKey, svc, makeListLabelsEndpoint(svc)),
 		CreateLabel:               authenticatedUser(jwtKey, svc, makeCreateLabelEndpoint(svc)),
 		DeleteLabel:               authenticatedUser(jwtKey, svc, makeDeleteLabelEndpoint(svc)),
 		SearchTargets:             authenticatedUser(jwtKey, svc, makeSearchTargetsEndpoint(svc)),
+		ResolveTargets:            authenticatedUser(jwtKey, svc, makeResolveTargetsEndpoint(svc)),
 		GetOptions:                authenticatedUser(jwtKey, svc, mustBeAdmin(makeGetOptionsEndpoint(svc))),
 		ModifyOptions:             authenticatedUser(jwtKey, svc, mustBeAdmin(makeModifyOptionsEndpoint(svc))),
 		ImportConfig:              authenticatedUser(jwtKey, svc, makeImportConfigEndpoint(svc)),
+		GetOsquerySchema:          authenticatedUser(jwtKey, svc, makeGetOsquerySchemaEndpoint(svc)),
+		ListRevisions:             authenticatedUser(jwtKey, svc, makeListRevisionsEndpoint(svc)),
+		DiffRevisions:             authenticatedUser(jwtKey, svc, makeDiffRevisionsEndpoint(svc)),
+		RestoreRevision:           authenticatedUser(jwtKey, svc, makeRestoreRevisionEndpoint(svc)),
 
 		// Osquery endpoints
 		EnrollAgent:                   makeEnrollAgentEndpoint(svc),
@@ -190,6 +238,21 @@ type kolideHandlers struct {
 	DeletePack                     http.Handler
 	ScheduleQuery                  http.Handler
 	GetScheduledQueriesInPack      http.Handler
+	GetScheduledQueryStatsInPack   http.Handler
+	ListHostAgentLogs              http.Handler
+	ListAgentLogSummaries          http.Handler
+	ListDetailQueries              http.Handler
+	GetDetailQuery                 http.Handler
+	CreateDetailQuery              http.Handler
+	ModifyDetailQuery              http.Handler
+	DeleteDetailQuery              http.Handler
+	ListHostSoftware               http.Handler
+	SearchSoftware                 http.Handler
+	ListSoftwareInstalls           http.Handler
+	ImportVulnerabilities          http.Handler
+	ListVulnerabilities            http.Handler
+	ListVulnerableHosts            http.Handler
+	ListHostVulnerabilities        http.Handler
 	GetScheduledQuery              http.Handler
 	ModifyScheduledQuery           http.Handler
 	DeleteScheduledQuery           http.Handler
@@ -206,10 +269,21 @@ type kolideHandlers struct {
 	DeleteHost                     http.Handler
 	ListHosts                      http.Handler
 	GetHostSummary                 http.Handler
+	GetHostPurgeReport             http.Handler
+	ListJobs                       http.Handler
+	GetHostBreakdown               http.Handler
+	GetHostConfig                  http.Handler
 	SearchTargets                  http.Handler
+	ResolveTargets                 http.Handler
 	GetOptions                     http.Handler
 	ModifyOptions                  http.Handler
 	ImportConfig                   http.Handler
+	GetOsquerySchema               http.Handler
+	ListQueryRevisions             http.Handler
+	ListPackRevisions              http.Handler
+	ListScheduledQueryRevisions    http.Handler
+	DiffRevisions                  http.Handler
+	RestoreRevision                http.Handler
 }
 
 func makeKolideKitHandlers(ctx context.Context, e KolideEndpoints, opts []kithttp.ServerOption) *kolideHandlers {
@@ -255,6 +329,21 @@ func makeKolideKitHandlers(ctx context.Context, e KolideEndpoints, opts []kithtt
 		DeletePack:                    newServer(e.DeletePack, decodeDeletePackRequest),
 		ScheduleQuery:                 newServer(e.ScheduleQuery, decodeScheduleQueryRequest),
 		GetScheduledQueriesInPack:     newServer(e.GetScheduledQueriesInPack, decodeGetScheduledQueriesInPackRequest),
+		GetScheduledQueryStatsInPack:  newServer(e.GetScheduledQueryStatsInPack, decodeGetScheduledQueryStatsInPackRequest),
+		ListHostAgentLogs:             newServer(e.ListHostAgentLogs, decodeListHostAgentLogsRequest),
+		ListAgentLogSummaries:         newServer(e.ListAgentLogSummaries, decodeListAgentLogSummariesRequest),
+		ListDetailQueries:             newServer(e.ListDetailQueries, decodeListDetailQueriesRequest),
+		GetDetailQuery:                newServer(e.GetDetailQuery, decodeGetDetailQueryRequest),
+		CreateDetailQuery:             newServer(e.CreateDetailQuery, decodeCreateDetailQueryRequest),
+		ModifyDetailQuery:             newServer(e.ModifyDetailQuery, decodeModifyDetailQueryRequest),
+		DeleteDetailQuery:             newServer(e.DeleteDetailQuery, decodeDeleteDetailQueryRequest),
+		ListHostSoftware:              newServer(e.ListHostSoftware, decodeListHostSoftwareRequest),
+		SearchSoftware:                newServer(e.SearchSoftware, decodeSearchSoftwareRequest),
+		ListSoftwareInstalls:          newServer(e.ListSoftwareInstalls, decodeListSoftwareInstallsRequest),
+		ImportVulnerabilities:         newServer(e.ImportVulnerabilities, decodeImportVulnerabilitiesRequest),
+		ListVulnerabilities:           newServer(e.ListVulnerabilities, decodeListVulnerabilitiesRequest),
+		ListVulnerableHosts:           newServer(e.ListVulnerableHosts, decodeListVulnerableHostsRequest),
+		ListHostVulnerabilities:       newServer(e.ListHostVulnerabilities, decodeListHostVulnerabilitiesRequest),
 		GetScheduledQuery:             newServer(e.GetScheduledQuery, decodeGetScheduledQueryRequest),
 		ModifyScheduledQuery:          newServer(e.ModifyScheduledQuery, decodeModifyScheduledQueryRequest),
 		DeleteScheduledQuery:          newServer(e.DeleteScheduledQuery, decodeDeleteScheduledQueryRequest),
@@ -271,15 +360,42 @@ func makeKolideKitHandlers(ctx context.Context, e KolideEndpoints, opts []kithtt
 		DeleteHost:                    newServer(e.DeleteHost, decodeDeleteHostRequest),
 		ListHosts:                     newServer(e.ListHosts, decodeListHostsRequest),
 		GetHostSummary:                newServer(e.GetHostSummary, decodeNoParamsRequest),
+		GetHostPurgeReport:            newServer(e.GetHostPurgeReport, decodeNoParamsRequest),
+		ListJobs:                      newServer(e.ListJobs, decodeNoParamsRequest),
+		GetHostBreakdown:              newServer(e.GetHostBreakdown, decodeGetHostBreakdownRequest),
+		GetHostConfig:                 newServer(e.GetHostConfig, decodeGetHostConfigRequest),
 		SearchTargets:                 newServer(e.SearchTargets, decodeSearchTargetsRequest),
+		ResolveTargets:                newServer(e.ResolveTargets, decodeResolveTargetsRequest),
 		GetOptions:                    newServer(e.GetOptions, decodeNoParamsRequest),
 		ModifyOptions:                 newServer(e.ModifyOptions, decodeModifyOptionsRequest),
 		ImportConfig:                  newServer(e.ImportConfig, decodeImportConfigRequest),
+		GetOsquerySchema:              newServer(e.GetOsquerySchema, decodeNoParamsRequest),
+		ListQueryRevisions:            newServer(e.ListRevisions, makeDecodeListRevisionsRequest(kolide.RevisionObjectQuery)),
+		ListPackRevisions:             newServer(e.ListRevisions, makeDecodeListRevisionsRequest(kolide.RevisionObjectPack)),
+		ListScheduledQueryRevisions:   newServer(e.ListRevisions, makeDecodeListRevisionsRequest(kolide.RevisionObjectScheduledQuery)),
+		DiffRevisions:                 newServer(e.DiffRevisions, decodeDiffRevisionsRequest),
+		RestoreRevision:               newServer(e.RestoreRevision, decodeRestoreRevisionRequest),
 	}
 }
 
 // MakeHandler creates an HTTP handler for the Kolide server endpoints.
 func MakeHandler(ctx context.Context, svc kolide.Service, jwtKey string, logger kitlog.Logger) http.Handler {
+	return makeHandler(ctx, svc, jwtKey, logger, attachKolideAPIRoutes, true)
+}
+
+// MakeAdminHandler creates an HTTP handler for the Kolide server endpoints
+// other than those of the osquery agent API.
+func MakeAdminHandler(ctx context.Context, svc kolide.Service, jwtKey string, logger kitlog.Logger) http.Handler {
+	return makeHandler(ctx, svc, jwtKey, logger, attachAdminAPIRoutes, true)
+}
+
+// MakeOsqueryHandler creates an HTTP handler for only the endpoints of the
+// osquery agent API.
+func MakeOsqueryHandler(ctx context.Context, svc kolide.Service, jwtKey string, logger kitlog.Logger) http.Handler {
+	return makeHandler(ctx, svc, jwtKey, logger, attachOsqueryAPIRoutes, false)
+}
+
+func makeHandler(ctx context.Context, svc kolide.Service, jwtKey string, logger kitlog.Logger, attach func(*mux.Router, *kolideHandlers), campaignResults bool) http.Handler {
 	kolideAPIOptions := []kithttp.ServerOption{
 		kithttp.ServerBefore(
 			setRequestsContexts(svc, jwtKey),
@@ -295,10 +411,12 @@ func MakeHandler(ctx context.Context, svc kolide.Service, jwtKey string, logger
 	kolideHandlers := makeKolideKitHandlers(ctx, kolideEndpoints, kolideAPIOptions)
 
 	r := mux.NewRouter()
-	attachKolideAPIRoutes(r, kolideHandlers)
-	r.HandleFunc("/api/v1/kolide/results/{id}",
-		makeStreamDistributedQueryCampaignResultsHandler(svc, jwtKey, logger)).
-		Methods("GET").Name("distributed_query_results")
+	attach(r, kolideHandlers)
+	if campaignResults {
+		r.HandleFunc("/api/v1/kolide/results/{id}",
+			makeStreamDistributedQueryCampaignResultsHandler(svc, jwtKey, logger)).
+			Methods("GET").Name("distributed_query_results")
+	}
 
 	addMetrics(r)
 
@@ -316,6 +434,11 @@ func addMetrics(r *mux.Router) {
 }
 
 func attachKolideAPIRoutes(r *mux.Router, h *kolideHandlers) {
+	attachAdminAPIRoutes(r, h)
+	attachOsqueryAPIRoutes(r, h)
+}
+
+func attachAdminAPIRoutes(r *mux.Router, h *kolideHandlers) {
 	r.Handle("/api/v1/kolide/login", h.Login).Methods("POST").Name("login")
 	r.Handle("/api/v1/kolide/logout", h.Logout).Methods("POST").Name("logout")
 	r.Handle("/api/v1/kolide/forgot_password", h.ForgotPassword).Methods("POST").Name("forgot_password")
@@ -345,6 +468,7 @@ func attachKolideAPIRoutes(r *mux.Router, h *kolideHandlers) {
 	r.Handle("/api/v1/kolide/invites/{token}", h.VerifyInvite).Methods("GET").Name("verify_invite")
 
 	r.Handle("/api/v1/kolide/queries/{id}", h.GetQuery).Methods("GET").Name("get_query")
+	r.Handle("/api/v1/kolide/queries/{id}/revisions", h.ListQueryRevisions).Methods("GET").Name("list_query_revisions")
 	r.Handle("/api/v1/kolide/queries", h.ListQueries).Methods("GET").Name("list_queries")
 	r.Handle("/api/v1/kolide/queries", h.CreateQuery).Methods("POST").Name("create_query")
 	r.Handle("/api/v1/kolide/queries/{id}", h.ModifyQuery).Methods("PATCH").Name("modify_query")
@@ -353,13 +477,16 @@ func attachKolideAPIRoutes(r *mux.Router, h *kolideHandlers) {
 	r.Handle("/api/v1/kolide/queries/run", h.CreateDistributedQueryCampaign).Methods("POST").Name("create_distributed_query_campaign")
 
 	r.Handle("/api/v1/kolide/packs/{id}", h.GetPack).Methods("GET").Name("get_pack")
+	r.Handle("/api/v1/kolide/packs/{id}/revisions", h.ListPackRevisions).Methods("GET").Name("list_pack_revisions")
 	r.Handle("/api/v1/kolide/packs", h.ListPacks).Methods("GET").Name("list_packs")
 	r.Handle("/api/v1/kolide/packs", h.CreatePack).Methods("POST").Name("create_pack")
 	r.Handle("/api/v1/kolide/packs/{id}", h.ModifyPack).Methods("PATCH").Name("modify_pack")
 	r.Handle("/api/v1/kolide/packs/{id}", h.DeletePack).Methods("DELETE").Name("delete_pack")
 	r.Handle("/api/v1/kolide/packs/{id}/scheduled", h.GetScheduledQueriesInPack).Methods("GET").Name("get_scheduled_queries_in_pack")
+	r.Handle("/api/v1/kolide/packs/{id}/stats", h.GetScheduledQueryStatsInPack).Methods("GET").Name("get_scheduled_query_stats_in_pack")
 	r.Handle("/api/v1/kolide/schedule", h.ScheduleQuery).Methods("POST").Name("schedule_query")
 	r.Handle("/api/v1/kolide/schedule/{id}", h.GetScheduledQuery).Methods("GET").Name("get_scheduled_query")
+	r.Handle("/api/v1/kolide/schedule/{id}/revisions", h.ListScheduledQueryRevisions).Methods("GET").Name("list_scheduled_query_revisions")
 	r.Handle("/api/v1/kolide/schedule/{id}", h.ModifyScheduledQuery).Methods("PATCH").Name("modify_scheduled_query")
 	r.Handle("/api/v1/kolide/schedule/{id}", h.DeleteScheduledQuery).Methods("DELETE").Name("delete_scheduled_query")
 	r.Handle("/api/v1/kolide/labels/{id}", h.GetLabel).Methods("GET").Name("get_label")
@@ -369,16 +496,47 @@ func attachKolideAPIRoutes(r *mux.Router, h *kolideHandlers) {
 
 	r.Handle("/api/v1/kolide/hosts", h.ListHosts).Methods("GET").Name("list_hosts")
 	r.Handle("/api/v1/kolide/host_summary", h.GetHostSummary).Methods("GET").Name("get_host_summary")
+	r.Handle("/api/v1/kolide/host_purge_report", h.GetHostPurgeReport).Methods("GET").Name("get_host_purge_report")
+	r.Handle("/api/v1/kolide/host_breakdown", h.GetHostBreakdown).Methods("GET").Name("get_host_breakdown")
 	r.Handle("/api/v1/kolide/hosts/{id}", h.GetHost).Methods("GET").Name("get_host")
+	r.Handle("/api/v1/kolide/hosts/{id}/config", h.GetHostConfig).Methods("GET").Name("get_host_config")
 	r.Handle("/api/v1/kolide/hosts/{id}", h.DeleteHost).Methods("DELETE").Name("delete_host")
+	r.Handle("/api/v1/kolide/hosts/{id}/agent_logs", h.ListHostAgentLogs).Methods("GET").Name("list_host_agent_logs")
+	r.Handle("/api/v1/kolide/agent_logs/summaries", h.ListAgentLogSummaries).Methods("GET").Name("list_agent_log_summaries")
+
+	r.Handle("/api/v1/kolide/jobs", h.ListJobs).Methods("GET").Name("list_jobs")
+
+	r.Handle("/api/v1/kolide/detail_queries", h.ListDetailQueries).Methods("GET").Name("list_detail_queries")
+	r.Handle("/api/v1/kolide/detail_queries", h.CreateDetailQuery).Methods("POST").Name("create_detail_query")
+	r.Handle("/api/v1/kolide/detail_queries/{id}", h.GetDetailQuery).Methods("GET").Name("get_detail_query")
+	r.Handle("/api/v1/kolide/detail_queries/{id}", h.ModifyDetailQuery).Methods("PATCH").Name("modify_detail_query")
+	r.Handle("/api/v1/kolide/detail_queries/{id}", h.DeleteDetailQuery).Methods("DELETE").Name("delete_detail_query")
+
+	r.Handle("/api/v1/kolide/hosts/{id}/software", h.ListHostSoftware).Methods("GET").Name("list_host_software")
+	r.Handle("/api/v1/kolide/software", h.SearchSoftware).Methods("GET").Name("search_software")
+	r.Handle("/api/v1/kolide/software/{id}/hosts", h.ListSoftwareInstalls).Methods("GET").Name("list_software_installs")
+
+	r.Handle("/api/v1/kolide/vulnerabilities", h.ListVulnerabilities).Methods("GET").Name("list_vulnerabilities")
+	r.Handle("/api/v1/kolide/vulnerabilities/import", h.ImportVulnerabilities).Methods("POST").Name("import_vulnerabilities")
+	r.Handle("/api/v1/kolide/vulnerabilities/{cve}/hosts", h.ListVulnerableHosts).Methods("GET").Name("list_vulnerable_hosts")
+	r.Handle("/api/v1/kolide/hosts/{id}/vulnerabilities", h.ListHostVulnerabilities).Methods("GET").Name("list_host_vulnerabilities")
 
 	r.Handle("/api/v1/kolide/options", h.GetOptions).Methods("GET").Name("get_options")
 	r.Handle("/api/v1/kolide/options", h.ModifyOptions).Methods("PATCH").Name("modify_options")
 
 	r.Handle("/api/v1/kolide/targets", h.SearchTargets).Methods("POST").Name("search_targets")
+	r.Handle("/api/v1/kolide/targets/resolve", h.ResolveTargets).Methods("POST").Name("resolve_targets")
 
 	r.Handle("/api/v1/kolide/osquery/config/import", h.ImportConfig).Methods("POST").Name("import_config")
+	r.Handle("/api/v1/kolide/revisions/diff", h.DiffRevisions).Methods("GET").Name("diff_revisions")
+	r.Handle("/api/v1/kolide/revisions/{id}/restore", h.RestoreRevision).Methods("POST").Name("restore_revision")
+
+	r.Handle("/api/v1/kolide/osquery/schema", h.GetOsquerySchema).Methods("GET").Name("get_osquery_schema")
+}
 
+// attachOsqueryAPIRoutes attaches the endpoints called by osqueryd, which
+// authenticate with a node key rather than a user session.
+func attachOsqueryAPIRoutes(r *mux.Router, h *kolideHandlers) {
 	r.Handle("/api/v1/osquery/enroll", h.EnrollAgent).Methods("POST").Name("enroll_agent")
 	r.Handle("/api/v1/osquery/config", h.GetClientConfig).Methods("POST").Name("get_client_config")
 	r.Handle("/api/v1/osquery/distributed/read", h.GetDistributedQueries).Methods("POST").Name("get_distributed_queries")
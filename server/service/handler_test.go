@@ -5,6 +5,7 @@ import (
 	"net/http/httptest"
 	"testing"
 
+	kitlog "github.com/go-kit/kit/log"
 	"github.com/gorilla/mux"
 	"github.com/kolide/kolide-ose/server/config"
 	"github.com/kolide/kolide-ose/server/datastore/inmem"
@@ -213,3 +214,48 @@ func TestAPIRoutes(t *testing.T) {
 		})
 	}
 }
+
+func TestSplitAPIRoutes(t *testing.T) {
+	ds, err := inmem.New(config.TestConfig())
+	assert.Nil(t, err)
+
+	svc, err := newTestService(ds, nil)
+	assert.Nil(t, err)
+
+	ctx := context.Background()
+	logger := kitlog.NewNopLogger()
+	adminHandler := MakeAdminHandler(ctx, svc, "CHANGEME", logger)
+	osqueryHandler := MakeOsqueryHandler(ctx, svc, "CHANGEME", logger)
+
+	var routes = []struct {
+		verb    string
+		uri     string
+		osquery bool
+	}{
+		{verb: "POST", uri: "/api/v1/kolide/login"},
+		{verb: "GET", uri: "/api/v1/kolide/hosts"},
+		{verb: "GET", uri: "/api/v1/kolide/results/1"},
+		{verb: "POST", uri: "/api/v1/osquery/enroll", osquery: true},
+		{verb: "POST", uri: "/api/v1/osquery/config", osquery: true},
+		{verb: "POST", uri: "/api/v1/osquery/distributed/read", osquery: true},
+		{verb: "POST", uri: "/api/v1/osquery/distributed/write", osquery: true},
+		{verb: "POST", uri: "/api/v1/osquery/log", osquery: true},
+	}
+
+	for _, route := range routes {
+		t.Run(fmt.Sprintf(": %v", route.uri), func(st *testing.T) {
+			adminRecorder := httptest.NewRecorder()
+			adminHandler.ServeHTTP(adminRecorder, httptest.NewRequest(route.verb, route.uri, nil))
+			osqueryRecorder := httptest.NewRecorder()
+			osqueryHandler.ServeHTTP(osqueryRecorder, httptest.NewRequest(route.verb, route.uri, nil))
+
+			if route.osquery {
+				assert.Equal(st, 404, adminRecorder.Code)
+				assert.NotEqual(st, 404, osqueryRecorder.Code)
+			} else {
+				assert.NotEqual(st, 404, adminRecorder.Code)
+				assert.Equal(st, 404, osqueryRecorder.Code)
+			}
+		})
+	}
+}
@@ -0,0 +1,80 @@
+package service
+
+import (
+	"github.com/kolide/kolide-ose/server/kolide"
+	"github.com/prometheus/client_golang/prometheus"
+	"golang.org/x/net/context"
+)
+
+// hostBreakdownCollector exposes the host breakdown of the whole fleet as
+// Prometheus gauges. The breakdown is read each time metrics are collected.
+type hostBreakdownCollector struct {
+	svc             kolide.Service
+	total           *prometheus.Desc
+	platforms       *prometheus.Desc
+	osVersions      *prometheus.Desc
+	osqueryVersions *prometheus.Desc
+	hardwareVendors *prometheus.Desc
+	hardwareModels  *prometheus.Desc
+	cpuBrands       *prometheus.Desc
+	memory          *prometheus.Desc
+}
+
+// NewHostBreakdownCollector returns a Prometheus collector of the number of
+// hosts grouped by their inventory.
+func NewHostBreakdownCollector(svc kolide.Service) prometheus.Collector {
+	desc := func(name, help string, labels ...string) *prometheus.Desc {
+		return prometheus.NewDesc(prometheus.BuildFQName("kolide", "", name), help, labels, nil)
+	}
+	return &hostBreakdownCollector{
+		svc:             svc,
+		total:           desc("hosts", "Number of enrolled hosts."),
+		platforms:       desc("hosts_by_platform", "Number of hosts by platform.", "platform"),
+		osVersions:      desc("hosts_by_os_version", "Number of hosts by OS version.", "os_version"),
+		osqueryVersions: desc("hosts_by_osquery_version", "Number of hosts by osquery version.", "osquery_version"),
+		hardwareVendors: desc("hosts_by_hardware_vendor", "Number of hosts by hardware vendor.", "vendor"),
+		hardwareModels:  desc("hosts_by_hardware_model", "Number of hosts by hardware model.", "vendor", "model"),
+		cpuBrands:       desc("hosts_by_cpu_brand", "Number of hosts by CPU brand.", "cpu_brand"),
+		memory:          desc("hosts_by_memory", "Number of hosts by physical memory bucket.", "memory"),
+	}
+}
+
+func (c *hostBreakdownCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.total
+	ch <- c.platforms
+	ch <- c.osVersions
+	ch <- c.osqueryVersions
+	ch <- c.hardwareVendors
+	ch <- c.hardwareModels
+	ch <- c.cpuBrands
+	ch <- c.memory
+}
+
+func (c *hostBreakdownCollector) Collect(ch chan<- prometheus.Metric) {
+	breakdown, err := c.svc.GetHostBreakdown(context.Background(), 0)
+	if err != nil {
+		ch <- prometheus.NewInvalidMetric(c.total, err)
+		return
+	}
+
+	ch <- prometheus.MustNewConstMetric(c.total, prometheus.GaugeValue, float64(breakdown.TotalCount))
+	counts := []struct {
+		desc   *prometheus.Desc
+		counts []*kolide.HostCount
+	}{
+		{c.platforms, breakdown.Platforms},
+		{c.osVersions, breakdown.OSVersions},
+		{c.osqueryVersions, breakdown.OsqueryVersions},
+		{c.hardwareVendors, breakdown.HardwareVendors},
+		{c.cpuBrands, breakdown.CPUBrands},
+		{c.memory, breakdown.Memory},
+	}
+	for _, field := range counts {
+		for _, count := range field.counts {
+			ch <- prometheus.MustNewConstMetric(field.desc, prometheus.GaugeValue, float64(count.Count), count.Value)
+		}
+	}
+	for _, count := range breakdown.HardwareModels {
+		ch <- prometheus.MustNewConstMetric(c.hardwareModels, prometheus.GaugeValue, float64(count.Count), count.Vendor, count.Model)
+	}
+}
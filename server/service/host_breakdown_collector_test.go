@@ -0,0 +1,44 @@
+package service
+
+import (
+	"testing"
+
+	"github.com/WatchBeam/clock"
+	"github.com/kolide/kolide-ose/server/config"
+	"github.com/kolide/kolide-ose/server/datastore/inmem"
+	"github.com/kolide/kolide-ose/server/test"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHostBreakdownCollector(t *testing.T) {
+	ds, err := inmem.New(config.TestConfig())
+	require.Nil(t, err)
+	svc, err := newTestService(ds, nil)
+	require.Nil(t, err)
+
+	host := test.NewHost(t, ds, "foo", "", "1", "1", clock.C.Now())
+	host.Platform = "ubuntu"
+	require.Nil(t, ds.SaveHost(host))
+
+	collector := NewHostBreakdownCollector(svc)
+	registry := prometheus.NewRegistry()
+	require.Nil(t, registry.Register(collector))
+
+	families, err := registry.Gather()
+	require.Nil(t, err)
+	gauges := map[string]float64{}
+	for _, family := range families {
+		for _, metric := range family.GetMetric() {
+			name := family.GetName()
+			for _, label := range metric.GetLabel() {
+				name += " " + label.GetValue()
+			}
+			gauges[name] = metric.GetGauge().GetValue()
+		}
+	}
+	assert.Equal(t, float64(1), gauges["kolide_hosts"])
+	assert.Equal(t, float64(1), gauges["kolide_hosts_by_platform ubuntu"])
+	assert.Equal(t, float64(1), gauges["kolide_hosts_by_memory unknown"])
+}
@@ -7,7 +7,7 @@ import (
 	"golang.org/x/net/context"
 )
 
-func (mw loggingMiddleware) ListHosts(ctx context.Context, opt kolide.ListOptions) ([]*kolide.Host, error) {
+func (mw loggingMiddleware) ListHosts(ctx context.Context, opt kolide.ListOptions, filter kolide.HostFilter) ([]*kolide.Host, error) {
 	var (
 		hosts []*kolide.Host
 		err   error
@@ -21,10 +21,28 @@ func (mw loggingMiddleware) ListHosts(ctx context.Context, opt kolide.ListOption
 		)
 	}(time.Now())
 
-	hosts, err = mw.Service.ListHosts(ctx, opt)
+	hosts, err = mw.Service.ListHosts(ctx, opt, filter)
 	return hosts, err
 }
 
+func (mw loggingMiddleware) CountHosts(ctx context.Context, filter kolide.HostFilter) (uint, error) {
+	var (
+		count uint
+		err   error
+	)
+
+	defer func(begin time.Time) {
+		_ = mw.logger.Log(
+			"method", "CountHosts",
+			"err", err,
+			"took", time.Since(begin),
+		)
+	}(time.Now())
+
+	count, err = mw.Service.CountHosts(ctx, filter)
+	return count, err
+}
+
 func (mw loggingMiddleware) GetHost(ctx context.Context, id uint) (*kolide.Host, error) {
 	var (
 		host *kolide.Host
@@ -43,6 +61,24 @@ func (mw loggingMiddleware) GetHost(ctx context.Context, id uint) (*kolide.Host,
 	return host, err
 }
 
+func (mw loggingMiddleware) GetHostConfig(ctx context.Context, id uint) (*kolide.HostConfig, error) {
+	var (
+		config *kolide.HostConfig
+		err    error
+	)
+
+	defer func(begin time.Time) {
+		_ = mw.logger.Log(
+			"method", "GetHostConfig",
+			"err", err,
+			"took", time.Since(begin),
+		)
+	}(time.Now())
+
+	config, err = mw.Service.GetHostConfig(ctx, id)
+	return config, err
+}
+
 func (mw loggingMiddleware) GetHostSummary(ctx context.Context) (*kolide.HostSummary, error) {
 	var (
 		summary *kolide.HostSummary
@@ -77,3 +113,26 @@ func (mw loggingMiddleware) DeleteHost(ctx context.Context, id uint) error {
 	err = mw.Service.DeleteHost(ctx, id)
 	return err
 }
+
+func (mw loggingMiddleware) PurgeHosts(ctx context.Context) (*kolide.HostPurgeReport, error) {
+	var (
+		report *kolide.HostPurgeReport
+		err    error
+		purged int
+	)
+
+	defer func(begin time.Time) {
+		_ = mw.logger.Log(
+			"method", "PurgeHosts",
+			"purged", purged,
+			"err", err,
+			"took", time.Since(begin),
+		)
+	}(time.Now())
+
+	report, err = mw.Service.PurgeHosts(ctx)
+	if report != nil {
+		purged = len(report.Hosts)
+	}
+	return report, err
+}
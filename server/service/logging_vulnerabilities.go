@@ -0,0 +1,24 @@
+package service
+
+import (
+	"time"
+
+	"golang.org/x/net/context"
+)
+
+func (mw loggingMiddleware) MatchVulnerabilities(ctx context.Context) error {
+	var (
+		err error
+	)
+
+	defer func(begin time.Time) {
+		_ = mw.logger.Log(
+			"method", "MatchVulnerabilities",
+			"err", err,
+			"took", time.Since(begin),
+		)
+	}(time.Now())
+
+	err = mw.Service.MatchVulnerabilities(ctx)
+	return err
+}
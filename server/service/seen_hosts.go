@@ -0,0 +1,115 @@
+package service
+
+import (
+	"sync"
+	"time"
+
+	"github.com/kolide/kolide-ose/server/kolide"
+)
+
+// seenHosts buffers the times that hosts were last seen and the configs they
+// last fetched, so that the many requests of each host are written to the
+// datastore together, in batches, by FlushSeenHosts.
+type seenHosts struct {
+	mtx     sync.Mutex
+	times   map[uint]time.Time
+	fetches map[uint]configFetch
+}
+
+// configFetch is a config fetched by a host.
+type configFetch struct {
+	hash string
+	time time.Time
+}
+
+func newSeenHosts() *seenHosts {
+	return &seenHosts{times: map[uint]time.Time{}, fetches: map[uint]configFetch{}}
+}
+
+// mark records that the host was seen at t.
+func (s *seenHosts) mark(hostID uint, t time.Time) {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	if t.After(s.times[hostID]) {
+		s.times[hostID] = t
+	}
+}
+
+// apply sets the seen time of each host to its buffered seen time, when it
+// was seen since the time read from the datastore.
+func (s *seenHosts) apply(hosts ...*kolide.Host) {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	for _, host := range hosts {
+		if t, ok := s.times[host.ID]; ok && t.After(host.SeenTime) {
+			host.SeenTime = t
+		}
+	}
+}
+
+// pending returns the buffered seen times.
+func (s *seenHosts) pending() map[uint]time.Time {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	pending := make(map[uint]time.Time, len(s.times))
+	for id, t := range s.times {
+		pending[id] = t
+	}
+	return pending
+}
+
+// flushed removes the seen times that were written, unless the host was seen
+// again while they were written.
+func (s *seenHosts) flushed(written map[uint]time.Time) {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	for id, t := range written {
+		if s.times[id].Equal(t) {
+			delete(s.times, id)
+		}
+	}
+}
+
+// markConfigFetched records that the host fetched the config with the hash
+// at t.
+func (s *seenHosts) markConfigFetched(hostID uint, hash string, t time.Time) {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	if t.After(s.fetches[hostID].time) {
+		s.fetches[hostID] = configFetch{hash: hash, time: t}
+	}
+}
+
+// applyConfigFetch sets the config fetch of the host to its buffered config
+// fetch, when it fetched its config since the fetch read from the datastore.
+func (s *seenHosts) applyConfigFetch(host *kolide.Host) {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	if fetch, ok := s.fetches[host.ID]; ok && fetch.time.After(host.ConfigFetchTime) {
+		host.ConfigFetchTime = fetch.time
+		host.ConfigHash = fetch.hash
+	}
+}
+
+// pendingConfigFetches returns the buffered config fetches.
+func (s *seenHosts) pendingConfigFetches() map[uint]configFetch {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	pending := make(map[uint]configFetch, len(s.fetches))
+	for id, fetch := range s.fetches {
+		pending[id] = fetch
+	}
+	return pending
+}
+
+// configFetchesFlushed removes the config fetches that were written, unless
+// the host fetched its config again while they were written.
+func (s *seenHosts) configFetchesFlushed(written map[uint]configFetch) {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	for id, fetch := range written {
+		if s.fetches[id] == fetch {
+			delete(s.fetches, id)
+		}
+	}
+}
@@ -12,10 +12,16 @@ import (
 	lumberjack "gopkg.in/natefinch/lumberjack.v2"
 )
 
-// NewService creates a new service from the config struct
-func NewService(ds kolide.Datastore, resultStore kolide.QueryResultStore, logger kitlog.Logger, kolideConfig config.KolideConfig, mailService kolide.MailService, c clock.Clock) (kolide.Service, error) {
+// NewService creates a new service from the config struct. The generated
+// osquery configs are cached in configCache, unless it is nil.
+func NewService(ds kolide.Datastore, resultStore kolide.QueryResultStore, logger kitlog.Logger, kolideConfig config.KolideConfig, mailService kolide.MailService, c clock.Clock, configCache kolide.OsqueryConfigCache) (kolide.Service, error) {
 	var svc kolide.Service
 
+	osquerySchema, err := kolide.LoadOsquerySchema()
+	if err != nil {
+		return nil, err
+	}
+
 	logFile := func(path string) io.Writer {
 		return &lumberjack.Logger{
 			Filename:   path,
@@ -32,11 +38,27 @@ func NewService(ds kolide.Datastore, resultStore kolide.QueryResultStore, logger
 		config:      kolideConfig,
 		clock:       c,
 
+		osquerySchema: osquerySchema,
+
 		osqueryStatusLogWriter: logFile(kolideConfig.Osquery.StatusLogFile),
 		osqueryResultLogWriter: logFile(kolideConfig.Osquery.ResultLogFile),
 		mailService:            mailService,
+		configCache:            configCache,
+		seenHosts:              newSeenHosts(),
+		campaignStreams:        newCampaignStreams(),
+	}
+	if configCache != nil {
+		svc = configCacheMiddleware{
+			Service: svc,
+			cache:   configCache,
+			logger:  logger,
+		}
+	}
+	svc = validationMiddleware{
+		Service:       svc,
+		ds:            ds,
+		osquerySchema: osquerySchema,
 	}
-	svc = validationMiddleware{svc, ds}
 	return svc, nil
 }
 
@@ -47,10 +69,26 @@ type service struct {
 	config      config.KolideConfig
 	clock       clock.Clock
 
+	// osquerySchema holds the tables and columns that queries are
+	// validated against
+	osquerySchema *kolide.OsquerySchema
+
 	osqueryStatusLogWriter io.Writer
 	osqueryResultLogWriter io.Writer
 
 	mailService kolide.MailService
+
+	// configCache caches the generated osquery configs, and is nil when
+	// they are not cached
+	configCache kolide.OsqueryConfigCache
+
+	// seenHosts buffers the seen times of hosts until FlushSeenHosts
+	// writes them to the datastore
+	seenHosts *seenHosts
+
+	// campaignStreams tracks the running campaign result streams, which
+	// Shutdown closes
+	campaignStreams *campaignStreams
 }
 
 func (s service) SendEmail(mail kolide.Email) error {
@@ -0,0 +1,31 @@
+package service
+
+import (
+	"github.com/kolide/kolide-ose/server/kolide"
+	"golang.org/x/net/context"
+)
+
+func (svc service) ListHostAgentLogs(ctx context.Context, hostID uint, opt kolide.ListOptions) ([]*kolide.AgentLog, kolide.AgentLogCounts, error) {
+	if _, err := svc.ds.Host(hostID); err != nil {
+		return nil, nil, err
+	}
+
+	logs, err := svc.ds.ListAgentLogs(hostID, opt)
+	if err != nil {
+		return nil, nil, err
+	}
+	counts, err := svc.ds.AgentLogCounts(hostID)
+	if err != nil {
+		return nil, nil, err
+	}
+	return logs, counts, nil
+}
+
+func (svc service) ListAgentLogSummaries(ctx context.Context, severity string, limit uint) ([]*kolide.AgentLogSummary, error) {
+	switch severity {
+	case "", kolide.AgentLogSeverityWarning, kolide.AgentLogSeverityError, kolide.AgentLogSeverityFatal:
+	default:
+		return nil, newInvalidArgumentError("severity", "must be one of warning, error or fatal")
+	}
+	return svc.ds.ListAgentLogSummaries(severity, limit)
+}
@@ -60,17 +60,37 @@ func (svc service) NewDistributedQueryCampaign(ctx context.Context, queryString
 		}
 	}
 
+	// Snapshot the hosts that the targets resolve to at creation time
+	hostIDs, err := svc.targetHostIDs(hosts, labels)
+	if err != nil {
+		return nil, errors.Wrap(err, "resolving targets")
+	}
+	if err := svc.ds.NewDistributedQueryCampaignHosts(campaign.ID, hostIDs); err != nil {
+		return nil, errors.Wrap(err, "adding campaign hosts")
+	}
+
 	return campaign, nil
 }
 
 type targetTotals struct {
+	// Targeted is the number of hosts the targets resolved to when the
+	// campaign was created
+	Targeted        uint `json:"targeted"`
 	Total           uint `json:"count"`
 	Online          uint `json:"online"`
 	Offline         uint `json:"offline"`
 	MissingInAction uint `json:"missing_in_action"`
 }
 
-func (svc service) StreamCampaignResults(ctx context.Context, conn *websocket.Conn, campaignID uint) {
+func (svc service) StreamCampaignResults(ctx context.Context, conn *websocket.Conn, campaignID uint, opts kolide.CampaignAggregateOptions) {
+	// The stream ends when the server shuts down
+	ctx, done, ok := svc.campaignStreams.start(ctx)
+	if !ok {
+		conn.WriteJSONError("server is shutting down")
+		return
+	}
+	defer done()
+
 	// Find the campaign and ensure it is active
 	campaign, err := svc.ds.DistributedQueryCampaign(campaignID)
 	if err != nil {
@@ -107,20 +127,53 @@ func (svc service) StreamCampaignResults(ctx context.Context, conn *websocket.Co
 		return
 	}
 
+	snapshot, err := svc.ds.DistributedQueryCampaignHostIDs(campaign.ID)
+	if err != nil {
+		conn.WriteJSONError("error retrieving campaign hosts")
+		return
+	}
+
+	var aggregator *resultAggregator
+	if opts.Enabled() {
+		aggregator = newResultAggregator(opts)
+	}
+
+	// Use a ticker rather than a timeout so that periodic updates are
+	// still sent while results are arriving continuously
+	ticker := time.NewTicker(1 * time.Second)
+	defer ticker.Stop()
+
 	// Loop, pushing updates to results and expected totals
 	for {
 		select {
+		case <-ctx.Done():
+			conn.CloseGoingAway()
+			return
+
 		case res := <-readChan:
 			// Receive a result and push it over the websocket
 			switch res := res.(type) {
 			case kolide.DistributedQueryResult:
+				if aggregator != nil {
+					aggregator.add(res)
+					if opts.OmitRows {
+						continue
+					}
+				}
 				err = conn.WriteJSONMessage("result", res)
 				if err != nil {
 					fmt.Println("error writing to channel")
 				}
 			}
 
-		case <-time.After(1 * time.Second):
+		case <-ticker.C:
+			// Push the aggregate if it changed since the last update
+			if aggregator != nil && aggregator.changed {
+				if err = conn.WriteJSONMessage("aggregate", aggregator.snapshot()); err != nil {
+					return
+				}
+			}
+
 			// Update the expected hosts total
 			hostIDs, labelIDs, err := svc.ds.DistributedQueryCampaignTargetIDs(campaign.ID)
 			if err != nil {
@@ -137,6 +190,7 @@ func (svc service) StreamCampaignResults(ctx context.Context, conn *websocket.Co
 			}
 
 			totals := targetTotals{
+				Targeted:        uint(len(snapshot)),
 				Total:           metrics.TotalHosts,
 				Online:          metrics.OnlineHosts,
 				Offline:         metrics.OfflineHosts,
@@ -0,0 +1,75 @@
+package service
+
+import (
+	"strings"
+
+	"github.com/kolide/kolide-ose/server/kolide"
+	"golang.org/x/net/context"
+)
+
+// defaultDetailQueryInterval is the number of seconds between runs of a
+// detail query created without an interval.
+const defaultDetailQueryInterval = 3600
+
+func (svc service) ListDetailQueries(ctx context.Context, opt kolide.ListOptions) ([]*kolide.DetailQuery, error) {
+	return svc.ds.ListDetailQueries(opt)
+}
+
+func (svc service) GetDetailQuery(ctx context.Context, id uint) (*kolide.DetailQuery, error) {
+	return svc.ds.DetailQuery(id)
+}
+
+func (svc service) NewDetailQuery(ctx context.Context, p kolide.DetailQueryPayload) (*kolide.DetailQuery, error) {
+	query := &kolide.DetailQuery{
+		Interval: defaultDetailQueryInterval,
+		Columns:  kolide.DetailQueryColumns{},
+	}
+	applyDetailQueryPayload(query, p)
+	return svc.ds.NewDetailQuery(query)
+}
+
+func (svc service) ModifyDetailQuery(ctx context.Context, id uint, p kolide.DetailQueryPayload) (*kolide.DetailQuery, error) {
+	query, err := svc.ds.DetailQuery(id)
+	if err != nil {
+		return nil, err
+	}
+	applyDetailQueryPayload(query, p)
+	if err := svc.ds.SaveDetailQuery(query); err != nil {
+		return nil, err
+	}
+	return query, nil
+}
+
+func (svc service) DeleteDetailQuery(ctx context.Context, id uint) error {
+	return svc.ds.DeleteDetailQuery(id)
+}
+
+func applyDetailQueryPayload(query *kolide.DetailQuery, p kolide.DetailQueryPayload) {
+	if p.Name != nil {
+		query.Name = *p.Name
+	}
+	if p.Query != nil {
+		query.Query = *p.Query
+	}
+	if p.Platform != nil {
+		query.Platform = normalizeDetailQueryPlatform(*p.Platform)
+	}
+	if p.Interval != nil {
+		query.Interval = *p.Interval
+	}
+	if p.Columns != nil {
+		query.Columns = *p.Columns
+	}
+}
+
+// normalizeDetailQueryPlatform removes whitespace and empty entries from a
+// comma separated list of platforms.
+func normalizeDetailQueryPlatform(platform string) string {
+	platforms := []string{}
+	for _, p := range strings.Split(platform, ",") {
+		if p = strings.TrimSpace(p); p != "" {
+			platforms = append(platforms, p)
+		}
+	}
+	return strings.Join(platforms, ",")
+}
@@ -0,0 +1,170 @@
+package service
+
+import (
+	"strconv"
+	"testing"
+
+	"github.com/kolide/kolide-ose/server/config"
+	hostctx "github.com/kolide/kolide-ose/server/contexts/host"
+	"github.com/kolide/kolide-ose/server/datastore/inmem"
+	"github.com/kolide/kolide-ose/server/kolide"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/net/context"
+)
+
+func TestDetailQueryValidation(t *testing.T) {
+	ds, err := inmem.New(config.TestConfig())
+	require.Nil(t, err)
+	svc, err := newTestService(ds, nil)
+	require.Nil(t, err)
+	ctx := context.Background()
+
+	name := "disk_encryption"
+	sql := "select encrypted from disk_encryption"
+	columns := kolide.DetailQueryColumns{{Name: "encrypted", Type: kolide.HostAttributeBoolean}}
+
+	query, err := svc.NewDetailQuery(ctx, kolide.DetailQueryPayload{
+		Name:    &name,
+		Query:   &sql,
+		Columns: &columns,
+	})
+	require.Nil(t, err)
+	assert.Equal(t, uint(defaultDetailQueryInterval), query.Interval)
+
+	badName := "Disk.Encryption"
+	badSQL := "select nope from disk_encryption"
+	zero := uint(0)
+	badColumns := kolide.DetailQueryColumns{
+		{Name: "encrypted", Type: "bool"},
+		{Name: "encrypted", Type: kolide.HostAttributeString},
+	}
+	_, err = svc.NewDetailQuery(ctx, kolide.DetailQueryPayload{
+		Name:     &badName,
+		Query:    &badSQL,
+		Interval: &zero,
+		Columns:  &badColumns,
+	})
+	require.NotNil(t, err)
+	invalid, ok := err.(*invalidArgumentError)
+	require.True(t, ok)
+	fields := map[string]bool{}
+	for _, e := range *invalid {
+		fields[e.name] = true
+	}
+	assert.Equal(t, map[string]bool{"name": true, "query": true, "interval": true, "columns": true}, fields)
+
+	_, err = svc.NewDetailQuery(ctx, kolide.DetailQueryPayload{Name: &name})
+	assert.NotNil(t, err)
+
+	platform := " ubuntu, centos ,"
+	query, err = svc.ModifyDetailQuery(ctx, query.ID, kolide.DetailQueryPayload{Platform: &platform})
+	require.Nil(t, err)
+	assert.Equal(t, "ubuntu,centos", query.Platform)
+	assert.Equal(t, sql, query.Query)
+}
+
+func TestDetailQueryHostAttributes(t *testing.T) {
+	ds, err := inmem.New(config.TestConfig())
+	require.Nil(t, err)
+	svc, err := newTestService(ds, nil)
+	require.Nil(t, err)
+	ctx := context.Background()
+
+	name := "disk_encryption"
+	sql := "select encrypted, type from disk_encryption"
+	columns := kolide.DetailQueryColumns{
+		{Name: "encrypted", Type: kolide.HostAttributeBoolean},
+		{Name: "type", Type: kolide.HostAttributeString},
+	}
+	query, err := svc.NewDetailQuery(ctx, kolide.DetailQueryPayload{
+		Name:    &name,
+		Query:   &sql,
+		Columns: &columns,
+	})
+	require.Nil(t, err)
+
+	// An attribute label created before any host reported the attribute
+	encrypted := "disk_encryption.encrypted=true"
+	labelName := "Encrypted"
+	label, err := svc.NewLabel(ctx, kolide.LabelPayload{Name: &labelName, Attribute: &encrypted})
+	require.Nil(t, err)
+	assert.Equal(t, kolide.LabelTypeAttribute, label.LabelType)
+	assert.Equal(t, encrypted, label.Attribute)
+	assert.Empty(t, label.Query)
+
+	nodeKey, err := svc.EnrollAgent(ctx, "", "host123")
+	require.Nil(t, err)
+	host, err := ds.AuthenticateHost(nodeKey)
+	require.Nil(t, err)
+	hostCtx := hostctx.NewContext(ctx, *host)
+
+	queries, err := svc.GetDistributedQueries(hostCtx)
+	require.Nil(t, err)
+	queryName := hostAttributeQueryPrefix + strconv.Itoa(int(query.ID))
+	assert.Equal(t, sql, queries[queryName])
+	// Attribute labels are not sent to hosts
+	for name := range queries {
+		assert.NotEqual(t, hostLabelQueryPrefix+strconv.Itoa(int(label.ID)), name)
+	}
+
+	results := kolide.OsqueryDistributedQueryResults{
+		queryName: {
+			{"encrypted": "1", "type": "AES-XTS"},
+		},
+	}
+	require.Nil(t, svc.SubmitDistributedQueryResults(hostCtx, results, map[string]string{}))
+
+	// The query is not sent again until its interval has passed
+	queries, err = svc.GetDistributedQueries(hostCtx)
+	require.Nil(t, err)
+	assert.NotContains(t, queries, queryName)
+
+	got, err := svc.GetHost(ctx, host.ID)
+	require.Nil(t, err)
+	require.Len(t, got.Attributes, 2)
+	assert.Equal(t, "disk_encryption.encrypted", got.Attributes[0].Key)
+	assert.Equal(t, true, got.Attributes[0].TypedValue())
+	assert.Equal(t, query.ID, got.Attributes[0].DetailQueryID)
+	assert.Equal(t, "AES-XTS", got.Attributes[1].TypedValue())
+
+	filter := kolide.HostFilter{Attributes: []kolide.HostAttributeFilter{
+		{Key: "disk_encryption.type", Operator: "=", Value: "AES-XTS"},
+	}}
+	hosts, err := svc.ListHosts(ctx, kolide.ListOptions{}, filter)
+	require.Nil(t, err)
+	require.Len(t, hosts, 1)
+	assert.Equal(t, host.ID, hosts[0].ID)
+
+	filter.Attributes[0].Operator = "~"
+	_, err = svc.ListHosts(ctx, kolide.ListOptions{}, filter)
+	assert.NotNil(t, err)
+
+	// The host joined the label when it reported the attribute
+	hostIDs, err := svc.HostIDsForLabel(label.ID)
+	require.Nil(t, err)
+	assert.Equal(t, []uint{host.ID}, hostIDs)
+
+	// A label created after the attribute was reported includes the host
+	// straight away
+	notEncrypted := "disk_encryption.encrypted=false"
+	labelName = "Not Encrypted"
+	other, err := svc.NewLabel(ctx, kolide.LabelPayload{Name: &labelName, Attribute: &notEncrypted})
+	require.Nil(t, err)
+	hostIDs, err = svc.HostIDsForLabel(other.ID)
+	require.Nil(t, err)
+	assert.Empty(t, hostIDs)
+
+	labelName = "Encrypted Again"
+	again, err := svc.NewLabel(ctx, kolide.LabelPayload{Name: &labelName, Attribute: &encrypted})
+	require.Nil(t, err)
+	hostIDs, err = svc.HostIDsForLabel(again.ID)
+	require.Nil(t, err)
+	assert.Equal(t, []uint{host.ID}, hostIDs)
+
+	// Deleting the query removes the attributes
+	require.Nil(t, svc.DeleteDetailQuery(ctx, query.ID))
+	got, err = svc.GetHost(ctx, host.ID)
+	require.Nil(t, err)
+	assert.Empty(t, got.Attributes)
+}
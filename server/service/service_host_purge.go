@@ -0,0 +1,94 @@
+package service
+
+import (
+	"strings"
+
+	"github.com/kolide/kolide-ose/server/kolide"
+	"github.com/pkg/errors"
+	"golang.org/x/net/context"
+)
+
+func (svc service) GetHostPurgeReport(ctx context.Context) (*kolide.HostPurgeReport, error) {
+	return svc.hostPurgeReport(ctx)
+}
+
+func (svc service) PurgeHosts(ctx context.Context) (*kolide.HostPurgeReport, error) {
+	report, err := svc.hostPurgeReport(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if len(report.Hosts) == 0 {
+		return report, nil
+	}
+
+	hostIDs := []uint{}
+	for _, host := range report.Hosts {
+		hostIDs = append(hostIDs, host.ID)
+	}
+	if _, err := svc.ds.PurgeHosts(hostIDs, report.SeenBefore); err != nil {
+		return nil, errors.Wrap(err, "purging hosts")
+	}
+	return report, nil
+}
+
+// hostPurgeReport returns the hosts that the configured retention policy
+// purges now.
+func (svc service) hostPurgeReport(ctx context.Context) (*kolide.HostPurgeReport, error) {
+	report := &kolide.HostPurgeReport{
+		Retention:    svc.config.Osquery.HostRetention,
+		ExemptLabels: []string{},
+		Hosts:        []*kolide.Host{},
+	}
+	for _, name := range strings.Split(svc.config.Osquery.HostRetentionExemptLabels, ",") {
+		if name = strings.TrimSpace(name); name != "" {
+			report.ExemptLabels = append(report.ExemptLabels, name)
+		}
+	}
+	if report.Retention <= 0 {
+		return report, nil
+	}
+	report.Enabled = true
+
+	// Hosts are listed by the seen times in the datastore, so the hosts
+	// seen since the last flush are flushed first to not be purged
+	if err := svc.FlushSeenHosts(ctx); err != nil {
+		return nil, err
+	}
+	report.SeenBefore = svc.clock.Now().Add(-report.Retention)
+
+	exemptLabelIDs, err := svc.labelIDsByName(report.ExemptLabels)
+	if err != nil {
+		return nil, err
+	}
+	report.Hosts, err = svc.ds.ListPurgeableHosts(report.SeenBefore, exemptLabelIDs)
+	if err != nil {
+		return nil, errors.Wrap(err, "listing purgeable hosts")
+	}
+	return report, nil
+}
+
+// labelIDsByName returns the IDs of the labels with the names. A name that
+// matches no label is an error, so that a misspelled exemption does not
+// purge the hosts it was meant to keep.
+func (svc service) labelIDsByName(names []string) ([]uint, error) {
+	if len(names) == 0 {
+		return nil, nil
+	}
+	labels, err := svc.ds.ListLabels(kolide.ListOptions{})
+	if err != nil {
+		return nil, errors.Wrap(err, "listing labels")
+	}
+	byName := map[string]uint{}
+	for _, label := range labels {
+		byName[label.Name] = label.ID
+	}
+	ids := []uint{}
+	for _, name := range names {
+		id, ok := byName[name]
+		if !ok {
+			return nil, errors.Errorf("host retention exempt label %q does not exist", name)
+		}
+		ids = append(ids, id)
+	}
+	return ids, nil
+}
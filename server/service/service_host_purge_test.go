@@ -0,0 +1,67 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"github.com/WatchBeam/clock"
+	"github.com/kolide/kolide-ose/server/config"
+	"github.com/kolide/kolide-ose/server/datastore/inmem"
+	"github.com/kolide/kolide-ose/server/kolide"
+	"github.com/kolide/kolide-ose/server/test"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/net/context"
+)
+
+func TestHostPurge(t *testing.T) {
+	ds, err := inmem.New(config.TestConfig())
+	require.Nil(t, err)
+	mockClock := clock.NewMockClock()
+	conf := config.TestConfig()
+	svc := service{ds: ds, config: conf, clock: mockClock, seenHosts: newSeenHosts()}
+	ctx := context.Background()
+
+	now := mockClock.Now()
+	test.NewHost(t, ds, "active", "", "1", "1", now)
+	gone := test.NewHost(t, ds, "gone", "", "2", "2", now.Add(-100*24*time.Hour))
+	exempt := test.NewHost(t, ds, "exempt", "", "3", "3", now.Add(-100*24*time.Hour))
+	servers := test.NewLabel(t, ds, "servers", "select 1")
+	require.Nil(t, ds.RecordLabelQueryExecutions(exempt, map[uint]bool{servers.ID: true}, now))
+
+	// Hosts are kept when no retention is configured
+	report, err := svc.PurgeHosts(ctx)
+	require.Nil(t, err)
+	assert.False(t, report.Enabled)
+	assert.Empty(t, report.Hosts)
+
+	svc.config.Osquery.HostRetention = 90 * 24 * time.Hour
+	svc.config.Osquery.HostRetentionExemptLabels = "servers, desktops"
+
+	// A missing exempt label must not purge the hosts meant to be kept
+	_, err = svc.GetHostPurgeReport(ctx)
+	assert.NotNil(t, err)
+
+	svc.config.Osquery.HostRetentionExemptLabels = "servers"
+	report, err = svc.GetHostPurgeReport(ctx)
+	require.Nil(t, err)
+	assert.True(t, report.Enabled)
+	assert.Equal(t, now.Add(-90*24*time.Hour), report.SeenBefore)
+	assert.Equal(t, []string{"servers"}, report.ExemptLabels)
+	require.Len(t, report.Hosts, 1)
+	assert.Equal(t, gone.ID, report.Hosts[0].ID)
+
+	// The report does not delete anything
+	_, err = ds.Host(gone.ID)
+	require.Nil(t, err)
+
+	report, err = svc.PurgeHosts(ctx)
+	require.Nil(t, err)
+	require.Len(t, report.Hosts, 1)
+
+	_, err = ds.Host(gone.ID)
+	assert.NotNil(t, err)
+	hosts, err := ds.ListHosts(kolide.ListOptions{}, kolide.HostFilter{})
+	require.Nil(t, err)
+	assert.Len(t, hosts, 2)
+}
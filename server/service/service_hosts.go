@@ -1,20 +1,94 @@
 package service
 
 import (
+	"sort"
+	"time"
+
 	"github.com/kolide/kolide-ose/server/kolide"
+	"github.com/pkg/errors"
 	"golang.org/x/net/context"
 )
 
-func (svc service) ListHosts(ctx context.Context, opt kolide.ListOptions) ([]*kolide.Host, error) {
-	return svc.ds.ListHosts(opt)
+func (svc service) ListHosts(ctx context.Context, opt kolide.ListOptions, filter kolide.HostFilter) ([]*kolide.Host, error) {
+	filter, err := svc.prepareHostFilter(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+	hosts, err := svc.ds.ListHosts(opt, filter)
+	if err != nil {
+		return nil, err
+	}
+	svc.seenHosts.apply(hosts...)
+	return hosts, nil
+}
+
+func (svc service) CountHosts(ctx context.Context, filter kolide.HostFilter) (uint, error) {
+	filter, err := svc.prepareHostFilter(ctx, filter)
+	if err != nil {
+		return 0, err
+	}
+	return svc.ds.CountHosts(filter)
+}
+
+// prepareHostFilter validates the filter and sets the time and thresholds
+// that its status is matched with. The datastore matches the status and seen
+// times against the seen times it holds, so buffered seen times are flushed
+// first when the filter uses them.
+func (svc service) prepareHostFilter(ctx context.Context, filter kolide.HostFilter) (kolide.HostFilter, error) {
+	for _, f := range filter.Attributes {
+		if !f.ValidOperator() {
+			return filter, newInvalidArgumentError("attribute", "unknown operator "+f.Operator)
+		}
+	}
+
+	switch filter.Status {
+	case "", kolide.StatusOnline, kolide.StatusOffline, kolide.StatusMIA:
+	default:
+		return filter, newInvalidArgumentError("status", "must be one of online, offline or mia")
+	}
+	if filter.Status != "" || !filter.SeenAfter.IsZero() || !filter.SeenBefore.IsZero() {
+		if err := svc.FlushSeenHosts(ctx); err != nil {
+			return filter, err
+		}
+	}
+	filter.StatusTime = svc.clock.Now()
+	filter.StatusThresholds = svc.hostStatusThresholds()
+	return filter, nil
+}
+
+// hostStatusThresholds returns the configured thresholds of host statuses.
+func (svc service) hostStatusThresholds() kolide.HostStatusThresholds {
+	return kolide.HostStatusThresholds{
+		Offline:       svc.config.Osquery.OfflineDuration,
+		MIA:           svc.config.Osquery.MIADuration,
+		IntervalGrace: svc.config.Osquery.OfflineIntervalGrace,
+	}
+}
+
+func (svc service) HostStatus(ctx context.Context, host *kolide.Host) string {
+	svc.seenHosts.apply(host)
+	return host.Status(svc.clock.Now(), svc.hostStatusThresholds())
 }
 
 func (svc service) GetHost(ctx context.Context, id uint) (*kolide.Host, error) {
-	return svc.ds.Host(id)
+	host, err := svc.ds.Host(id)
+	if err != nil {
+		return nil, err
+	}
+	host.Attributes, err = svc.ds.HostAttributes(id)
+	if err != nil {
+		return nil, err
+	}
+	svc.seenHosts.apply(host)
+	return host, nil
 }
 
 func (svc service) GetHostSummary(ctx context.Context) (*kolide.HostSummary, error) {
-	online, offline, mia, err := svc.ds.GenerateHostStatusStatistics(svc.clock.Now())
+	// The statistics are counted from the seen times in the datastore
+	if err := svc.FlushSeenHosts(ctx); err != nil {
+		return nil, err
+	}
+	online, offline, mia, err := svc.ds.GenerateHostStatusStatistics(svc.clock.Now(), svc.hostStatusThresholds())
 	if err != nil {
 		return nil, err
 	}
@@ -25,6 +99,113 @@ func (svc service) GetHostSummary(ctx context.Context) (*kolide.HostSummary, err
 	}, nil
 }
 
+func (svc service) GetHostBreakdown(ctx context.Context, labelID uint) (*kolide.HostBreakdown, error) {
+	if labelID != 0 {
+		if _, err := svc.ds.Label(labelID); err != nil {
+			return nil, err
+		}
+	}
+	return svc.ds.HostBreakdown(labelID)
+}
+
+func (svc service) GetHostConfig(ctx context.Context, id uint) (*kolide.HostConfig, error) {
+	host, err := svc.ds.Host(id)
+	if err != nil {
+		return nil, err
+	}
+	svc.seenHosts.applyConfigFetch(host)
+	config, packs, err := svc.clientConfig(host.ID)
+	if err != nil {
+		return nil, err
+	}
+	hash, err := config.Hash()
+	if err != nil {
+		return nil, errors.Wrap(err, "hashing config")
+	}
+	return &kolide.HostConfig{
+		Config:        config,
+		Hash:          hash,
+		Packs:         packs,
+		LastFetchTime: host.ConfigFetchTime,
+		LastFetchHash: host.ConfigHash,
+	}, nil
+}
+
 func (svc service) DeleteHost(ctx context.Context, id uint) error {
 	return svc.ds.DeleteHost(id)
 }
+
+func (svc service) FlushSeenHosts(ctx context.Context) error {
+	if err := svc.flushSeenTimes(); err != nil {
+		return err
+	}
+	return svc.flushConfigFetches()
+}
+
+// flushSeenTimes writes the buffered seen times.
+func (svc service) flushSeenTimes() error {
+	pending := svc.seenHosts.pending()
+	if len(pending) == 0 {
+		return nil
+	}
+
+	// Hosts are written with the time each was seen, which may be long
+	// before the flush when an earlier flush failed. The times are grouped
+	// by the second, the precision that the datastores keep, so that hosts
+	// seen in the same second are written together.
+	bySecond := map[int64][]uint{}
+	for id, t := range pending {
+		second := t.Unix()
+		bySecond[second] = append(bySecond[second], id)
+	}
+	seconds := make([]int64, 0, len(bySecond))
+	for second := range bySecond {
+		seconds = append(seconds, second)
+	}
+	sort.Slice(seconds, func(i, j int) bool { return seconds[i] < seconds[j] })
+
+	written := map[uint]time.Time{}
+	defer func() { svc.seenHosts.flushed(written) }()
+	for _, second := range seconds {
+		hostIDs := bySecond[second]
+		if err := svc.ds.MarkHostsSeen(hostIDs, time.Unix(second, 0).UTC()); err != nil {
+			return errors.Wrap(err, "marking hosts seen")
+		}
+		for _, id := range hostIDs {
+			written[id] = pending[id]
+		}
+	}
+	return nil
+}
+
+// flushConfigFetches writes the buffered config fetches. Hosts with the same
+// packs fetch the same config, so fetches are grouped by the hash and second
+// of the fetch.
+func (svc service) flushConfigFetches() error {
+	pending := svc.seenHosts.pendingConfigFetches()
+	if len(pending) == 0 {
+		return nil
+	}
+
+	type group struct {
+		hash   string
+		second int64
+	}
+	groups := map[group][]uint{}
+	for id, fetch := range pending {
+		g := group{hash: fetch.hash, second: fetch.time.Unix()}
+		groups[g] = append(groups[g], id)
+	}
+
+	written := map[uint]configFetch{}
+	defer func() { svc.seenHosts.configFetchesFlushed(written) }()
+	for g, hostIDs := range groups {
+		if err := svc.ds.MarkHostsConfigFetched(hostIDs, g.hash, time.Unix(g.second, 0).UTC()); err != nil {
+			return errors.Wrap(err, "marking hosts config fetched")
+		}
+		for _, id := range hostIDs {
+			written[id] = pending[id]
+		}
+	}
+	return nil
+}
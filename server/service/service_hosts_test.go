@@ -2,11 +2,16 @@ package service
 
 import (
 	"testing"
+	"time"
 
+	"github.com/WatchBeam/clock"
 	"github.com/kolide/kolide-ose/server/config"
+	hostctx "github.com/kolide/kolide-ose/server/contexts/host"
 	"github.com/kolide/kolide-ose/server/datastore/inmem"
 	"github.com/kolide/kolide-ose/server/kolide"
+	"github.com/kolide/kolide-ose/server/test"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 	"golang.org/x/net/context"
 )
 
@@ -19,7 +24,7 @@ func TestListHosts(t *testing.T) {
 
 	ctx := context.Background()
 
-	hosts, err := svc.ListHosts(ctx, kolide.ListOptions{})
+	hosts, err := svc.ListHosts(ctx, kolide.ListOptions{}, kolide.HostFilter{})
 	assert.Nil(t, err)
 	assert.Len(t, hosts, 0)
 
@@ -28,11 +33,155 @@ func TestListHosts(t *testing.T) {
 	})
 	assert.Nil(t, err)
 
-	hosts, err = svc.ListHosts(ctx, kolide.ListOptions{})
+	hosts, err = svc.ListHosts(ctx, kolide.ListOptions{}, kolide.HostFilter{})
 	assert.Nil(t, err)
 	assert.Len(t, hosts, 1)
 }
 
+func TestListHostsStatus(t *testing.T) {
+	ds, err := inmem.New(config.TestConfig())
+	require.Nil(t, err)
+	mockClock := clock.NewMockClock()
+	svc, err := newTestServiceWithClock(ds, nil, mockClock)
+	require.Nil(t, err)
+	ctx := context.Background()
+
+	now := mockClock.Now()
+	online := test.NewHost(t, ds, "online", "", "1", "1", now)
+	offline := test.NewHost(t, ds, "offline", "", "2", "2", now.Add(-time.Hour))
+	mia := test.NewHost(t, ds, "mia", "", "3", "3", now.Add(-60*24*time.Hour))
+
+	var statusTests = []struct {
+		status   string
+		expected uint
+	}{
+		{kolide.StatusOnline, online.ID},
+		{kolide.StatusOffline, offline.ID},
+		{kolide.StatusMIA, mia.ID},
+	}
+	for _, tt := range statusTests {
+		hosts, err := svc.ListHosts(ctx, kolide.ListOptions{}, kolide.HostFilter{Status: tt.status})
+		require.Nil(t, err)
+		require.Len(t, hosts, 1, tt.status)
+		assert.Equal(t, tt.expected, hosts[0].ID)
+
+		count, err := svc.CountHosts(ctx, kolide.HostFilter{Status: tt.status})
+		require.Nil(t, err)
+		assert.Equal(t, uint(1), count)
+	}
+
+	// The status combines with a requested range of seen times
+	hosts, err := svc.ListHosts(ctx, kolide.ListOptions{}, kolide.HostFilter{
+		Status:     kolide.StatusOffline,
+		SeenBefore: now.Add(-2 * time.Hour),
+	})
+	require.Nil(t, err)
+	assert.Len(t, hosts, 0)
+
+	_, err = svc.ListHosts(ctx, kolide.ListOptions{}, kolide.HostFilter{Status: "gone"})
+	assert.IsType(t, &invalidArgumentError{}, err)
+}
+
+func TestHostStatusThresholds(t *testing.T) {
+	ds, err := inmem.New(config.TestConfig())
+	require.Nil(t, err)
+	mockClock := clock.NewMockClock()
+	conf := config.TestConfig()
+	conf.Osquery.OfflineDuration = 2 * time.Hour
+	conf.Osquery.OfflineIntervalGrace = 3
+	svc := service{ds: ds, config: conf, clock: mockClock, seenHosts: newSeenHosts()}
+	ctx := context.Background()
+
+	now := mockClock.Now()
+	// Online within the configured offline duration
+	unreported := test.NewHost(t, ds, "unreported", "", "1", "1", now.Add(-time.Hour))
+	// Offline after three distributed intervals
+	fast := test.NewHost(t, ds, "fast", "", "2", "2", now.Add(-time.Minute))
+	fast.DistributedInterval = 10
+	require.Nil(t, ds.SaveHost(fast))
+
+	assert.Equal(t, kolide.StatusOnline, svc.HostStatus(ctx, unreported))
+	assert.Equal(t, kolide.StatusOffline, svc.HostStatus(ctx, fast))
+
+	hosts, err := svc.ListHosts(ctx, kolide.ListOptions{}, kolide.HostFilter{Status: kolide.StatusOffline})
+	require.Nil(t, err)
+	require.Len(t, hosts, 1)
+	assert.Equal(t, fast.ID, hosts[0].ID)
+
+	summary, err := svc.GetHostSummary(ctx)
+	require.Nil(t, err)
+	assert.Equal(t, uint(1), summary.OnlineCount)
+	assert.Equal(t, uint(1), summary.OfflineCount)
+	assert.Equal(t, uint(0), summary.MIACount)
+}
+
+func TestFlushSeenHosts(t *testing.T) {
+	ds, err := inmem.New(config.TestConfig())
+	require.Nil(t, err)
+	mockClock := clock.NewMockClock()
+	svc := service{ds: ds, config: config.TestConfig(), clock: mockClock, seenHosts: newSeenHosts()}
+	ctx := context.Background()
+
+	aDayAgo := mockClock.Now().Add(-24 * time.Hour)
+	host := test.NewHost(t, ds, "foo", "", "key", "uuid", aDayAgo)
+
+	_, err = svc.AuthenticateHost(ctx, "key")
+	require.Nil(t, err)
+
+	// The seen time is buffered, but reads through the service use it
+	stored, err := ds.Host(host.ID)
+	require.Nil(t, err)
+	assert.Equal(t, aDayAgo, stored.SeenTime)
+	read, err := svc.GetHost(ctx, host.ID)
+	require.Nil(t, err)
+	assert.Equal(t, mockClock.Now(), read.SeenTime)
+	assert.Equal(t, kolide.StatusOnline, svc.HostStatus(ctx, stored))
+
+	// The host is written with the time it was seen, rather than the time
+	// of the flush, which may be much later after a failed flush
+	seen := mockClock.Now()
+	mockClock.AddTime(time.Hour)
+	require.Nil(t, svc.FlushSeenHosts(ctx))
+	stored, err = ds.Host(host.ID)
+	require.Nil(t, err)
+	assert.WithinDuration(t, seen, stored.SeenTime, time.Second)
+	assert.True(t, !stored.SeenTime.After(seen))
+	assert.Empty(t, svc.seenHosts.pending())
+
+	// Saving the host loaded before the flush does not move its seen time
+	// backwards
+	stale := *host
+	stale.SeenTime = aDayAgo
+	require.Nil(t, ds.SaveHost(&stale))
+	stored, err = ds.Host(host.ID)
+	require.Nil(t, err)
+	assert.WithinDuration(t, seen, stored.SeenTime, time.Second)
+}
+
+func TestHostSummaryUsesSeenHosts(t *testing.T) {
+	ds, err := inmem.New(config.TestConfig())
+	require.Nil(t, err)
+	mockClock := clock.NewMockClock()
+	svc := service{ds: ds, config: config.TestConfig(), clock: mockClock, seenHosts: newSeenHosts()}
+	ctx := context.Background()
+
+	test.NewHost(t, ds, "foo", "", "key", "uuid", mockClock.Now().Add(-24*time.Hour))
+	summary, err := svc.GetHostSummary(ctx)
+	require.Nil(t, err)
+	assert.Equal(t, uint(0), summary.OnlineCount)
+
+	_, err = svc.AuthenticateHost(ctx, "key")
+	require.Nil(t, err)
+
+	summary, err = svc.GetHostSummary(ctx)
+	require.Nil(t, err)
+	assert.Equal(t, uint(1), summary.OnlineCount)
+
+	online, err := svc.ListHosts(ctx, kolide.ListOptions{}, kolide.HostFilter{Status: kolide.StatusOnline})
+	require.Nil(t, err)
+	assert.Len(t, online, 1)
+}
+
 func TestGetHost(t *testing.T) {
 	ds, err := inmem.New(config.TestConfig())
 	assert.Nil(t, err)
@@ -72,8 +221,77 @@ func TestDeleteHost(t *testing.T) {
 	err = svc.DeleteHost(ctx, host.ID)
 	assert.Nil(t, err)
 
-	hosts, err := ds.ListHosts(kolide.ListOptions{})
+	hosts, err := ds.ListHosts(kolide.ListOptions{}, kolide.HostFilter{})
 	assert.Nil(t, err)
 	assert.Len(t, hosts, 0)
 
 }
+
+func TestGetHostConfig(t *testing.T) {
+	ds, err := inmem.New(config.TestConfig())
+	require.Nil(t, err)
+	require.Nil(t, ds.MigrateData())
+
+	mockClock := clock.NewMockClock()
+	svc, err := newTestServiceWithClock(ds, nil, mockClock)
+	require.Nil(t, err)
+
+	ctx := context.Background()
+
+	host := test.NewHost(t, ds, "foo", "192.168.1.10", "1", "1", mockClock.Now())
+	label := test.NewLabel(t, ds, "label", "select 1")
+	require.Nil(t, ds.RecordLabelQueryExecutions(host, map[uint]bool{label.ID: true}, mockClock.Now()))
+
+	query, err := ds.NewQuery(&kolide.Query{Name: "time", Query: "select * from time"})
+	require.Nil(t, err)
+
+	labelPack := test.NewPack(t, ds, "label_pack")
+	require.Nil(t, ds.AddLabelToPack(label.ID, labelPack.ID))
+	test.NewScheduledQuery(t, ds, labelPack.ID, query.ID, 60, false, false)
+
+	hostPack := test.NewPack(t, ds, "host_pack")
+	require.Nil(t, ds.AddHostToPack(host.ID, hostPack.ID))
+
+	disabledPack := test.NewPack(t, ds, "disabled_pack")
+	require.Nil(t, ds.AddHostToPack(host.ID, disabledPack.ID))
+	disabledPack.Disabled = true
+	require.Nil(t, ds.SavePack(disabledPack))
+
+	_, err = svc.GetHostConfig(ctx, host.ID+100)
+	assert.NotNil(t, err)
+
+	config, err := svc.GetHostConfig(ctx, host.ID)
+	require.Nil(t, err)
+	require.Len(t, config.Packs, 2)
+	assert.Equal(t, labelPack.ID, config.Packs[0].ID)
+	assert.False(t, config.Packs[0].HostTarget)
+	assert.Equal(t, []kolide.HostConfigPackLabel{{ID: label.ID, Name: label.Name}}, config.Packs[0].Labels)
+	assert.Equal(t, hostPack.ID, config.Packs[1].ID)
+	assert.True(t, config.Packs[1].HostTarget)
+	assert.Empty(t, config.Packs[1].Labels)
+
+	require.Len(t, config.Config.Packs, 2)
+	assert.Len(t, config.Config.Packs["label_pack"].Queries, 1)
+	assert.NotEmpty(t, config.Hash)
+	assert.Empty(t, config.LastFetchHash)
+	assert.True(t, config.LastFetchTime.IsZero())
+
+	// The config the host fetches is the one previewed
+	mockClock.AddTime(time.Minute)
+	clientConfig, err := svc.GetClientConfig(hostctx.NewContext(ctx, *host))
+	require.Nil(t, err)
+	assert.Equal(t, config.Config, clientConfig)
+
+	fetched, err := svc.GetHostConfig(ctx, host.ID)
+	require.Nil(t, err)
+	assert.Equal(t, config.Hash, fetched.LastFetchHash)
+	assert.Equal(t, mockClock.Now(), fetched.LastFetchTime)
+
+	// Changing a pack changes the hash, leaving the last fetched hash stale
+	require.Nil(t, ds.AddLabelToPack(label.ID, hostPack.ID))
+	test.NewScheduledQuery(t, ds, hostPack.ID, query.ID, 120, false, false)
+	changed, err := svc.GetHostConfig(ctx, host.ID)
+	require.Nil(t, err)
+	assert.NotEqual(t, config.Hash, changed.Hash)
+	assert.Equal(t, config.Hash, changed.LastFetchHash)
+}
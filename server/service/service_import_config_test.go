@@ -322,7 +322,7 @@ func TestPacksImportConfig(t *testing.T) {
 	assert.Len(t, packs, 4)
 	err = svc.importPacks(user.ID, &importConfig, resp)
 	require.Nil(t, err)
-	queries, err := svc.ds.ListQueries(kolide.ListOptions{})
+	queries, err := svc.ds.ListQueries(kolide.ListOptions{}, kolide.QueryFilter{})
 	require.Nil(t, err)
 	assert.Len(t, queries, 3)
 	pack, ok, err := svc.ds.PackByName("pack1")
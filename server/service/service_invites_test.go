@@ -89,12 +89,15 @@ func setupInviteTest(t *testing.T) (kolide.Service, *mock.Store, *mockMailServic
 		KolideServerURL: "https://acme.co",
 	})
 	mailer := &mockMailService{SendEmailFn: func(e kolide.Email) error { return nil }}
-	svc := validationMiddleware{service{
-		ds:          ms,
-		config:      config.TestConfig(),
-		mailService: mailer,
-		clock:       clock.NewMockClock(),
-	}, ms}
+	svc := validationMiddleware{
+		Service: service{
+			ds:          ms,
+			config:      config.TestConfig(),
+			mailService: mailer,
+			clock:       clock.NewMockClock(),
+		},
+		ds: ms,
+	}
 	return svc, ms, mailer
 }
 
@@ -0,0 +1,10 @@
+package service
+
+import (
+	"github.com/kolide/kolide-ose/server/kolide"
+	"golang.org/x/net/context"
+)
+
+func (svc service) ListJobs(ctx context.Context) ([]*kolide.Job, error) {
+	return svc.ds.ListJobs()
+}
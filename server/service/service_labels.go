@@ -2,6 +2,7 @@ package service
 
 import (
 	"github.com/kolide/kolide-ose/server/kolide"
+	"github.com/pkg/errors"
 	"golang.org/x/net/context"
 )
 
@@ -21,10 +22,21 @@ func (svc service) NewLabel(ctx context.Context, p kolide.LabelPayload) (*kolide
 	}
 	label.Name = *p.Name
 
-	if p.Query == nil {
+	switch {
+	case p.Attribute != nil && p.Query != nil:
+		return nil, newInvalidArgumentError("attribute", "a label cannot have both a query and an attribute")
+	case p.Attribute != nil:
+		filter, err := kolide.ParseHostAttributeFilter(*p.Attribute)
+		if err != nil {
+			return nil, newInvalidArgumentError("attribute", err.Error())
+		}
+		label.LabelType = kolide.LabelTypeAttribute
+		label.Attribute = filter.String()
+	case p.Query != nil:
+		label.Query = *p.Query
+	default:
 		return nil, newInvalidArgumentError("query", "missing required argument")
 	}
-	label.Query = *p.Query
 
 	if p.Platform != nil {
 		label.Platform = *p.Platform
@@ -38,9 +50,41 @@ func (svc service) NewLabel(ctx context.Context, p kolide.LabelPayload) (*kolide
 	if err != nil {
 		return nil, err
 	}
+
+	if label.LabelType == kolide.LabelTypeAttribute {
+		if err := svc.recordAttributeLabelHosts(label); err != nil {
+			return nil, err
+		}
+	}
 	return label, nil
 }
 
+// recordAttributeLabelHosts records the hosts that a new attribute label
+// matches, based on the attributes that they have already reported.
+func (svc service) recordAttributeLabelHosts(label *kolide.Label) error {
+	filter, err := kolide.ParseHostAttributeFilter(label.Attribute)
+	if err != nil {
+		return errors.Wrap(err, "parsing attribute label")
+	}
+	matching, err := svc.ds.ListHosts(kolide.ListOptions{}, kolide.HostFilter{
+		Attributes: []kolide.HostAttributeFilter{filter},
+	})
+	if err != nil {
+		return errors.Wrap(err, "listing hosts matching attribute label")
+	}
+	now := svc.clock.Now()
+	for _, host := range matching {
+		if label.Platform != "" && label.Platform != host.Platform {
+			continue
+		}
+		err := svc.ds.RecordLabelQueryExecutions(host, map[uint]bool{label.ID: true}, now)
+		if err != nil {
+			return errors.Wrap(err, "recording attribute label hosts")
+		}
+	}
+	return nil
+}
+
 func (svc service) DeleteLabel(ctx context.Context, id uint) error {
 	return svc.ds.DeleteLabel(id)
 }
@@ -56,3 +100,32 @@ func (svc service) HostIDsForLabel(lid uint) ([]uint, error) {
 	}
 	return ids, nil
 }
+
+// evaluateAttributeLabels adds whether the host matches each attribute label
+// for its platform to labelResults.
+func (svc service) evaluateAttributeLabels(host kolide.Host, labelResults map[uint]bool) error {
+	labels, err := svc.ds.ListLabels(kolide.ListOptions{})
+	if err != nil {
+		return errors.Wrap(err, "listing labels")
+	}
+	var attributes []kolide.HostAttribute
+	for _, label := range labels {
+		if label.LabelType != kolide.LabelTypeAttribute {
+			continue
+		}
+		if label.Platform != "" && label.Platform != host.Platform {
+			continue
+		}
+		if attributes == nil {
+			if attributes, err = svc.ds.HostAttributes(host.ID); err != nil {
+				return errors.Wrap(err, "loading host attributes")
+			}
+		}
+		filter, err := kolide.ParseHostAttributeFilter(label.Attribute)
+		if err != nil {
+			return errors.Wrapf(err, "parsing attribute label %d", label.ID)
+		}
+		labelResults[label.ID] = filter.MatchesAny(attributes)
+	}
+	return nil
+}
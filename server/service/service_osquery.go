@@ -41,10 +41,7 @@ func (svc service) AuthenticateHost(ctx context.Context, nodeKey string) (*kolid
 			nodeInvalid: true,
 		}
 	}
-	err = svc.ds.MarkHostSeen(host, svc.clock.Now())
-	if err != nil {
-		return nil, osqueryError{message: "failed to make host seen: " + err.Error()}
-	}
+	svc.markHostSeen(host)
 	return host, nil
 }
 
@@ -61,12 +58,85 @@ func (svc service) EnrollAgent(ctx context.Context, enrollSecret, hostIdentifier
 	return host.NodeKey, nil
 }
 
+// markHostSeen records that the host was seen now. The seen time is
+// buffered and written to the datastore by FlushSeenHosts.
+func (svc service) markHostSeen(host *kolide.Host) {
+	svc.seenHosts.mark(host.ID, svc.clock.Now())
+}
+
 func (svc service) GetClientConfig(ctx context.Context) (*kolide.OsqueryConfig, error) {
 	host, ok := hostctx.FromContext(ctx)
 	if !ok {
 		return nil, osqueryError{message: "internal error: missing host from request context"}
 	}
 
+	config, _, err := svc.clientConfig(host.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	hash, err := config.Hash()
+	if err != nil {
+		return nil, osqueryError{message: "internal error: hashing config: " + err.Error()}
+	}
+	// Like the seen time, the fetch is buffered and written to the
+	// datastore by FlushSeenHosts
+	svc.seenHosts.markConfigFetched(host.ID, hash, svc.clock.Now())
+
+	return config, nil
+}
+
+// clientConfig returns the osquery config of the host, along with why each
+// of its packs applies.
+func (svc service) clientConfig(hostID uint) (*kolide.OsqueryConfig, []*kolide.HostConfigPack, error) {
+	packs, reasons, err := svc.packsForHost(hostID)
+	if err != nil {
+		return nil, nil, osqueryError{message: "database error: " + err.Error()}
+	}
+
+	config, err := svc.packsConfig(packs)
+	if err != nil {
+		return nil, nil, err
+	}
+	return config, reasons, nil
+}
+
+// packsConfig returns the osquery config holding the options and the given
+// packs. Hosts that resolve to the same packs share a config, so it is
+// cached by the set of pack IDs when a config cache is configured. A cache
+// that cannot be read or written is logged and the config is generated.
+func (svc service) packsConfig(packs []*kolide.Pack) (*kolide.OsqueryConfig, error) {
+	if svc.configCache == nil {
+		return svc.generatePacksConfig(packs)
+	}
+
+	packIDs := make([]uint, 0, len(packs))
+	for _, pack := range packs {
+		packIDs = append(packIDs, pack.ID)
+	}
+
+	cached, generation, cacheErr := svc.configCache.Get(packIDs)
+	if cacheErr != nil {
+		svc.logger.Log("method", "packsConfig", "err", cacheErr, "msg", "reading osquery config cache")
+	} else if cached != nil {
+		return cached, nil
+	}
+
+	config, err := svc.generatePacksConfig(packs)
+	if err != nil {
+		return nil, err
+	}
+	// Setting the generation read before the config was generated ensures
+	// that a config generated while the cache was invalidated is not cached
+	if cacheErr == nil {
+		if err := svc.configCache.Set(packIDs, generation, config); err != nil {
+			svc.logger.Log("method", "packsConfig", "err", err, "msg", "writing osquery config cache")
+		}
+	}
+	return config, nil
+}
+
+func (svc service) generatePacksConfig(packs []*kolide.Pack) (*kolide.OsqueryConfig, error) {
 	options, err := svc.ds.GetOsqueryConfigOptions()
 	if err != nil {
 		return nil, osqueryError{message: "internal error: unable to fetch configuration options"}
@@ -77,11 +147,6 @@ func (svc service) GetClientConfig(ctx context.Context) (*kolide.OsqueryConfig,
 		Packs:   kolide.Packs{},
 	}
 
-	packs, err := svc.ListPacksForHost(ctx, host.ID)
-	if err != nil {
-		return nil, osqueryError{message: "database error: " + err.Error()}
-	}
-
 	for _, pack := range packs {
 		// first, we must figure out what queries are in this pack
 		queries, err := svc.ds.ListScheduledQueriesInPack(pack.ID, kolide.ListOptions{})
@@ -124,18 +189,21 @@ func (svc service) SubmitStatusLogs(ctx context.Context, logs []kolide.OsquerySt
 		return osqueryError{message: "internal error: missing host from request context"}
 	}
 
+	agentLogs := make([]*kolide.AgentLog, 0, len(logs))
 	for _, log := range logs {
 		err := json.NewEncoder(svc.osqueryStatusLogWriter).Encode(log)
 		if err != nil {
 			return osqueryError{message: "error writing status log: " + err.Error()}
 		}
+		agentLogs = append(agentLogs, kolide.NewAgentLog(host.ID, log, svc.clock.Now()))
 	}
 
-	err := svc.ds.MarkHostSeen(&host, svc.clock.Now())
+	err := svc.ds.NewAgentLogs(host.ID, agentLogs, svc.config.Osquery.AgentLogRetention)
 	if err != nil {
-		return osqueryError{message: "failed to update host seen: " + err.Error()}
+		return osqueryError{message: "failed to store status logs: " + err.Error()}
 	}
 
+	svc.markHostSeen(&host)
 	return nil
 }
 
@@ -152,11 +220,7 @@ func (svc service) SubmitResultLogs(ctx context.Context, logs []kolide.OsqueryRe
 		}
 	}
 
-	err := svc.ds.MarkHostSeen(&host, svc.clock.Now())
-	if err != nil {
-		return osqueryError{message: "failed to update host seen: " + err.Error()}
-	}
-
+	svc.markHostSeen(&host)
 	return nil
 }
 
@@ -169,16 +233,23 @@ const hostLabelQueryPrefix = "kolide_label_query_"
 // provided as a detail query.
 const hostDetailQueryPrefix = "kolide_detail_query_"
 
+// hostAttributeQueryPrefix is appended before the ID of a detail query defined
+// by an admin, the results of which are stored as host attributes.
+const hostAttributeQueryPrefix = "kolide_attribute_query_"
+
 // hostDistributedQueryPrefix is appended before the query name when a query is
 // run from a distributed query campaign
 const hostDistributedQueryPrefix = "kolide_distributed_query_"
 
 // detailQueries defines the detail queries that should be run on the host, as
 // well as how the results of those queries should be ingested into the
-// kolide.Host data model. This map should not be modified at runtime.
+// kolide.Host data model. Results that are not part of the host are stored
+// by DirectIngestFunc instead of IngestFunc. This map should not be modified
+// at runtime.
 var detailQueries = map[string]struct {
-	Query      string
-	IngestFunc func(host *kolide.Host, rows []map[string]string) error
+	Query            string
+	IngestFunc       func(host *kolide.Host, rows []map[string]string) error
+	DirectIngestFunc func(svc service, host *kolide.Host, rows []map[string]string) error
 }{
 	"osquery_info": {
 		Query: "select * from osquery_info limit 1",
@@ -332,6 +403,119 @@ var detailQueries = map[string]struct {
 			return nil
 		},
 	},
+	"osquery_flags": {
+		// config_refresh was named config_tls_refresh before osquery 2.0
+		Query: `select name, value from osquery_flags where name in
+                        ("distributed_interval", "config_tls_refresh", "config_refresh")`,
+		IngestFunc: func(host *kolide.Host, rows []map[string]string) error {
+			var configRefresh, configTLSRefresh uint
+			host.DistributedInterval = 0
+			for _, row := range rows {
+				value, err := strconv.ParseUint(row["value"], 10, 32)
+				if err != nil {
+					return osqueryError{
+						message: fmt.Sprintf("parsing %s: %s", row["name"], err),
+					}
+				}
+				switch row["name"] {
+				case "distributed_interval":
+					host.DistributedInterval = uint(value)
+				case "config_refresh":
+					configRefresh = uint(value)
+				case "config_tls_refresh":
+					configTLSRefresh = uint(value)
+				}
+			}
+			host.ConfigRefresh = configRefresh
+			if host.ConfigRefresh == 0 {
+				host.ConfigRefresh = configTLSRefresh
+			}
+			return nil
+		},
+	},
+	"scheduled_query_stats": {
+		Query:            "select * from osquery_schedule",
+		DirectIngestFunc: ingestScheduledQueryStats,
+	},
+}
+
+// ingestScheduledQueryStats stores the performance stats from the
+// osquery_schedule table of a host for the scheduled queries in packs.
+// Queries that osquery reports but that are not (or no longer) in a pack are
+// ignored.
+func ingestScheduledQueryStats(svc service, host *kolide.Host, rows []map[string]string) error {
+	scheduledQueryIDs, err := svc.scheduledQueryIDsByScheduleName()
+	if err != nil {
+		return err
+	}
+
+	stats := []kolide.ScheduledQueryStats{}
+	for _, row := range rows {
+		id, ok := scheduledQueryIDs[row["name"]]
+		if !ok {
+			continue
+		}
+
+		s := kolide.ScheduledQueryStats{
+			HostID:           host.ID,
+			ScheduledQueryID: id,
+		}
+		var lastExecuted uint64
+		for column, value := range map[string]*uint64{
+			"executions":     &s.Executions,
+			"last_executed":  &lastExecuted,
+			"wall_time":      &s.WallTime,
+			"user_time":      &s.UserTime,
+			"system_time":    &s.SystemTime,
+			"average_memory": &s.AverageMemory,
+			"output_size":    &s.OutputSize,
+		} {
+			if row[column] == "" {
+				continue
+			}
+			if *value, err = strconv.ParseUint(row[column], 10, 64); err != nil {
+				return errors.Wrapf(err, "parsing %s of %s", column, row["name"])
+			}
+		}
+		if lastExecuted > 0 {
+			s.LastExecuted = time.Unix(int64(lastExecuted), 0).UTC()
+		}
+		// Versions of osquery before 4.4 call the column blacklisted
+		s.Denylisted = row["denylisted"] == "1" || row["blacklisted"] == "1"
+
+		stats = append(stats, s)
+	}
+
+	return svc.ds.SaveScheduledQueryStats(host.ID, stats)
+}
+
+// defaultPackDelimiter is the delimiter osquery uses in the names of pack
+// queries when the pack_delimiter option is not set.
+const defaultPackDelimiter = "_"
+
+// scheduledQueryIDsByScheduleName maps the names that osquery gives the
+// queries of packs in its schedule to the IDs of the scheduled queries.
+func (svc service) scheduledQueryIDsByScheduleName() (map[string]uint, error) {
+	options, err := svc.ds.GetOsqueryConfigOptions()
+	if err != nil {
+		return nil, errors.Wrap(err, "retrieving pack delimiter")
+	}
+	delimiter, ok := options["pack_delimiter"].(string)
+	if !ok || delimiter == "" {
+		delimiter = defaultPackDelimiter
+	}
+
+	names, err := svc.ds.ListScheduledQueryNames()
+	if err != nil {
+		return nil, errors.Wrap(err, "listing scheduled query names")
+	}
+
+	// Names match those of the packs and queries in GetClientConfig
+	ids := map[string]uint{}
+	for _, name := range names {
+		ids["pack"+delimiter+name.PackName+delimiter+name.QueryName] = name.ID
+	}
+	return ids, nil
 }
 
 // detailUpdateInterval determines how often the detail queries should be
@@ -350,6 +534,10 @@ func (svc service) hostDetailQueries(host kolide.Host) map[string]string {
 	for name, query := range detailQueries {
 		queries[hostDetailQueryPrefix+name] = query.Query
 	}
+	// The software inventory is refreshed along with the details
+	for name, query := range hostSoftwareQueries(host) {
+		queries[name] = query
+	}
 	return queries
 }
 
@@ -372,6 +560,15 @@ func (svc service) GetDistributedQueries(ctx context.Context) (map[string]string
 		queries[hostLabelQueryPrefix+name] = query
 	}
 
+	attributeQueries, err := svc.ds.DetailQueriesForHost(&host, svc.clock.Now())
+	if err != nil {
+		return nil, osqueryError{message: "retrieving detail queries: " + err.Error()}
+	}
+
+	for id, query := range attributeQueries {
+		queries[hostAttributeQueryPrefix+strconv.Itoa(int(id))] = query
+	}
+
 	distributedQueries, err := svc.ds.DistributedQueriesForHost(&host)
 	if err != nil {
 		return nil, osqueryError{message: "retrieving query campaigns: " + err.Error()}
@@ -393,7 +590,12 @@ func (svc service) ingestDetailQuery(host *kolide.Host, name string, rows []map[
 		return osqueryError{message: "unknown detail query " + trimmedQuery}
 	}
 
-	err := query.IngestFunc(host, rows)
+	var err error
+	if query.DirectIngestFunc != nil {
+		err = query.DirectIngestFunc(svc, host, rows)
+	} else {
+		err = query.IngestFunc(host, rows)
+	}
 	if err != nil {
 		return osqueryError{
 			message: fmt.Sprintf("ingesting query %s: %s", name, err.Error()),
@@ -416,6 +618,33 @@ func (svc service) ingestLabelQuery(host kolide.Host, query string, rows []map[s
 	return nil
 }
 
+// ingestHostAttributes stores the first row of the results of a detail query
+// defined by an admin as attributes of the host. Whether the host matches
+// each attribute label is added to labelResults, to be recorded along with
+// the results of label queries.
+func (svc service) ingestHostAttributes(host kolide.Host, name string, rows []map[string]string, labelResults map[uint]bool) error {
+	id, err := strconv.Atoi(strings.TrimPrefix(name, hostAttributeQueryPrefix))
+	if err != nil {
+		return osqueryError{message: "unable to parse detail query ID: " + name}
+	}
+	query, err := svc.ds.DetailQuery(uint(id))
+	if err != nil {
+		if e, ok := err.(kolide.NotFoundError); ok && e.IsNotFound() {
+			// The query was deleted after the host retrieved it
+			return nil
+		}
+		return osqueryError{message: "loading detail query: " + err.Error()}
+	}
+
+	now := svc.clock.Now()
+	attributes := query.HostAttributes(host.ID, rows, now)
+	if err := svc.ds.RecordHostAttributes(host.ID, query.ID, attributes, now); err != nil {
+		return osqueryError{message: "recording host attributes: " + err.Error()}
+	}
+
+	return svc.evaluateAttributeLabels(host, labelResults)
+}
+
 // ingestDistributedQuery takes the results of a distributed query and modifies the
 // provided kolide.Host appropriately.
 func (svc service) ingestDistributedQuery(host kolide.Host, name string, rows []map[string]string, failed bool) error {
@@ -486,11 +715,9 @@ func (svc service) SubmitDistributedQueryResults(ctx context.Context, results ko
 		return osqueryError{message: "internal error: missing host from request context"}
 	}
 
-	err := svc.ds.MarkHostSeen(&host, svc.clock.Now())
-	if err != nil {
-		return osqueryError{message: "failed to update host seen: " + err.Error()}
-	}
+	svc.markHostSeen(&host)
 
+	var err error
 	detailUpdated := false
 	labelResults := map[uint]bool{}
 	for query, rows := range results {
@@ -500,6 +727,12 @@ func (svc service) SubmitDistributedQueryResults(ctx context.Context, results ko
 			detailUpdated = true
 		case strings.HasPrefix(query, hostLabelQueryPrefix):
 			err = svc.ingestLabelQuery(host, query, rows, labelResults)
+		case strings.HasPrefix(query, hostAttributeQueryPrefix):
+			err = svc.ingestHostAttributes(host, query, rows, labelResults)
+		case strings.HasPrefix(query, hostSoftwareQueryPrefix):
+			status, ok := statuses[query]
+			failed := ok && status != "0"
+			err = svc.ingestSoftware(host, query, rows, failed)
 		case strings.HasPrefix(query, hostDistributedQueryPrefix):
 			// osquery docs say any nonzero (string) value for
 			// status indicates a query error
@@ -0,0 +1,14 @@
+package service
+
+import (
+	"github.com/kolide/kolide-ose/server/kolide"
+	"github.com/pkg/errors"
+	"golang.org/x/net/context"
+)
+
+func (svc service) GetOsquerySchema(ctx context.Context) (*kolide.OsquerySchema, error) {
+	if svc.osquerySchema == nil {
+		return nil, errors.New("osquery schema not loaded")
+	}
+	return svc.osquerySchema, nil
+}
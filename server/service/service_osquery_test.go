@@ -12,7 +12,9 @@ import (
 	"golang.org/x/net/context"
 
 	"github.com/WatchBeam/clock"
+	kitlog "github.com/go-kit/kit/log"
 	"github.com/kolide/kolide-ose/server/config"
+	"github.com/kolide/kolide-ose/server/configcache"
 	hostctx "github.com/kolide/kolide-ose/server/contexts/host"
 	"github.com/kolide/kolide-ose/server/contexts/viewer"
 	"github.com/kolide/kolide-ose/server/datastore/inmem"
@@ -32,7 +34,7 @@ func TestEnrollAgent(t *testing.T) {
 
 	ctx := context.Background()
 
-	hosts, err := ds.ListHosts(kolide.ListOptions{})
+	hosts, err := ds.ListHosts(kolide.ListOptions{}, kolide.HostFilter{})
 	assert.Nil(t, err)
 	assert.Len(t, hosts, 0)
 
@@ -40,7 +42,7 @@ func TestEnrollAgent(t *testing.T) {
 	assert.Nil(t, err)
 	assert.NotEmpty(t, nodeKey)
 
-	hosts, err = ds.ListHosts(kolide.ListOptions{})
+	hosts, err = ds.ListHosts(kolide.ListOptions{}, kolide.HostFilter{})
 	assert.Nil(t, err)
 	assert.Len(t, hosts, 1)
 }
@@ -54,7 +56,7 @@ func TestEnrollAgentIncorrectEnrollSecret(t *testing.T) {
 
 	ctx := context.Background()
 
-	hosts, err := ds.ListHosts(kolide.ListOptions{})
+	hosts, err := ds.ListHosts(kolide.ListOptions{}, kolide.HostFilter{})
 	assert.Nil(t, err)
 	assert.Len(t, hosts, 0)
 
@@ -62,7 +64,7 @@ func TestEnrollAgentIncorrectEnrollSecret(t *testing.T) {
 	assert.NotNil(t, err)
 	assert.Empty(t, nodeKey)
 
-	hosts, err = ds.ListHosts(kolide.ListOptions{})
+	hosts, err = ds.ListHosts(kolide.ListOptions{}, kolide.HostFilter{})
 	assert.Nil(t, err)
 	assert.Len(t, hosts, 0)
 }
@@ -81,7 +83,7 @@ func TestSubmitStatusLogs(t *testing.T) {
 	_, err = svc.EnrollAgent(ctx, "", "host123")
 	assert.Nil(t, err)
 
-	hosts, err := ds.ListHosts(kolide.ListOptions{})
+	hosts, err := ds.ListHosts(kolide.ListOptions{}, kolide.HostFilter{})
 	require.Nil(t, err)
 	require.Len(t, hosts, 1)
 	host := hosts[0]
@@ -123,10 +125,35 @@ func TestSubmitStatusLogs(t *testing.T) {
 		}
 	}
 
-	// Verify that the update time is set appropriately
+	// Warnings and errors are kept as agent logs, and all logs are counted
+	agentLogs, counts, err := svc.ListHostAgentLogs(ctx, host.ID, kolide.ListOptions{})
+	require.Nil(t, err)
+	require.Len(t, agentLogs, 1)
+	assert.Equal(t, kolide.AgentLogSeverityWarning, agentLogs[0].Severity)
+	assert.Equal(t, "buffered.cpp", agentLogs[0].Filename)
+	assert.Equal(t, uint(122), agentLogs[0].Line)
+	assert.Equal(t, "warning!", agentLogs[0].Message)
+	assert.Equal(t, "1.8.2", agentLogs[0].Version)
+	assert.Equal(t, mockClock.Now(), agentLogs[0].CreatedAt)
+	assert.Equal(t, kolide.AgentLogCounts{
+		kolide.AgentLogSeverityInfo:    1,
+		kolide.AgentLogSeverityWarning: 1,
+	}, counts)
+
+	summaries, err := svc.ListAgentLogSummaries(ctx, "", 10)
+	require.Nil(t, err)
+	require.Len(t, summaries, 1)
+	assert.Equal(t, uint(1), summaries[0].HostCount)
+
+	_, err = svc.ListAgentLogSummaries(ctx, "bogus", 10)
+	assert.IsType(t, &invalidArgumentError{}, err)
+
+	// Verify that the update time is set appropriately once the seen
+	// hosts are flushed
+	require.Nil(t, serv.FlushSeenHosts(ctx))
 	checkHost, err := ds.Host(host.ID)
 	assert.Nil(t, err)
-	assert.Equal(t, mockClock.Now(), checkHost.UpdatedAt)
+	assert.WithinDuration(t, mockClock.Now(), checkHost.UpdatedAt, time.Second)
 
 	// Advance clock time and check that time is updated on new logs
 	mockClock.AddTime(1 * time.Minute)
@@ -134,9 +161,10 @@ func TestSubmitStatusLogs(t *testing.T) {
 	err = serv.SubmitStatusLogs(ctx, []kolide.OsqueryStatusLog{})
 	assert.Nil(t, err)
 
+	require.Nil(t, serv.FlushSeenHosts(ctx))
 	checkHost, err = ds.Host(host.ID)
 	assert.Nil(t, err)
-	assert.Equal(t, mockClock.Now(), checkHost.UpdatedAt)
+	assert.WithinDuration(t, mockClock.Now(), checkHost.UpdatedAt, time.Second)
 }
 
 func TestSubmitResultLogs(t *testing.T) {
@@ -153,7 +181,7 @@ func TestSubmitResultLogs(t *testing.T) {
 	_, err = svc.EnrollAgent(ctx, "", "host123")
 	assert.Nil(t, err)
 
-	hosts, err := ds.ListHosts(kolide.ListOptions{})
+	hosts, err := ds.ListHosts(kolide.ListOptions{}, kolide.HostFilter{})
 	require.Nil(t, err)
 	require.Len(t, hosts, 1)
 	host := hosts[0]
@@ -195,10 +223,12 @@ func TestSubmitResultLogs(t *testing.T) {
 		}
 	}
 
-	// Verify that the update time is set appropriately
+	// Verify that the update time is set appropriately once the seen
+	// hosts are flushed
+	require.Nil(t, serv.FlushSeenHosts(ctx))
 	checkHost, err := ds.Host(host.ID)
 	assert.Nil(t, err)
-	assert.Equal(t, mockClock.Now(), checkHost.UpdatedAt)
+	assert.WithinDuration(t, mockClock.Now(), checkHost.UpdatedAt, time.Second)
 
 	// Advance clock time and check that time is updated on new logs
 	mockClock.AddTime(1 * time.Minute)
@@ -206,9 +236,10 @@ func TestSubmitResultLogs(t *testing.T) {
 	err = serv.SubmitResultLogs(ctx, []kolide.OsqueryResultLog{})
 	assert.Nil(t, err)
 
+	require.Nil(t, serv.FlushSeenHosts(ctx))
 	checkHost, err = ds.Host(host.ID)
 	assert.Nil(t, err)
-	assert.Equal(t, mockClock.Now(), checkHost.UpdatedAt)
+	assert.WithinDuration(t, mockClock.Now(), checkHost.UpdatedAt, time.Second)
 }
 
 func TestHostDetailQueries(t *testing.T) {
@@ -262,7 +293,7 @@ func TestLabelQueries(t *testing.T) {
 	_, err = svc.EnrollAgent(ctx, "", "host123")
 	assert.Nil(t, err)
 
-	hosts, err := ds.ListHosts(kolide.ListOptions{})
+	hosts, err := ds.ListHosts(kolide.ListOptions{}, kolide.HostFilter{})
 	require.Nil(t, err)
 	require.Len(t, hosts, 1)
 	host := hosts[0]
@@ -389,14 +420,14 @@ func TestGetClientConfig(t *testing.T) {
 
 	ctx := context.Background()
 
-	hosts, err := ds.ListHosts(kolide.ListOptions{})
+	hosts, err := ds.ListHosts(kolide.ListOptions{}, kolide.HostFilter{})
 	require.Nil(t, err)
 	require.Len(t, hosts, 0)
 
 	_, err = svc.EnrollAgent(ctx, "", "user.local")
 	assert.Nil(t, err)
 
-	hosts, err = ds.ListHosts(kolide.ListOptions{})
+	hosts, err = ds.ListHosts(kolide.ListOptions{}, kolide.HostFilter{})
 	require.Nil(t, err)
 	require.Len(t, hosts, 1)
 	host := hosts[0]
@@ -464,6 +495,70 @@ func TestGetClientConfig(t *testing.T) {
 	require.Nil(t, err)
 	assert.Len(t, config.Packs, 1)
 	assert.Len(t, config.Packs["monitoring"].Queries, 1)
+
+	// failing to record the fetch does not fail the request, and the fetch
+	// stays buffered until it is written
+	svc, err = newTestServiceWithClock(failingConfigFetchStore{ds}, nil, mockClock)
+	require.Nil(t, err)
+	config, err = svc.GetClientConfig(ctx)
+	require.Nil(t, err)
+	assert.Len(t, config.Packs, 1)
+	assert.NotNil(t, svc.FlushSeenHosts(ctx))
+	hostConfig, err := svc.GetHostConfig(ctx, host.ID)
+	require.Nil(t, err)
+	assert.Equal(t, hostConfig.Hash, hostConfig.LastFetchHash)
+}
+
+// failingConfigFetchStore is a datastore that cannot record config fetches.
+type failingConfigFetchStore struct {
+	kolide.Datastore
+}
+
+func (failingConfigFetchStore) MarkHostsConfigFetched(hostIDs []uint, hash string, now time.Time) error {
+	return fmt.Errorf("config fetch not recorded")
+}
+
+func TestGetClientConfigCached(t *testing.T) {
+	ds, err := inmem.New(config.TestConfig())
+	require.Nil(t, err)
+	require.Nil(t, ds.MigrateData())
+
+	mailer := &mockMailService{SendEmailFn: func(e kolide.Email) error { return nil }}
+	cache := configcache.NewInmemConfigCache(time.Hour, clock.C)
+	svc, err := NewService(ds, nil, kitlog.NewNopLogger(), config.TestConfig(), mailer, clock.C, cache)
+	require.Nil(t, err)
+
+	ctx := context.Background()
+	_, err = svc.EnrollAgent(ctx, "", "user.local")
+	require.Nil(t, err)
+	hosts, err := ds.ListHosts(kolide.ListOptions{}, kolide.HostFilter{})
+	require.Nil(t, err)
+	require.Len(t, hosts, 1)
+	host := hosts[0]
+
+	pack := test.NewPack(t, ds, "monitoring")
+	require.Nil(t, ds.AddHostToPack(host.ID, pack.ID))
+	query := test.NewQuery(t, ds, "time", "select * from time", 0, true)
+	uptime := test.NewQuery(t, ds, "uptime", "select * from uptime", 0, true)
+
+	config, err := svc.GetClientConfig(hostctx.NewContext(ctx, *host))
+	require.Nil(t, err)
+	require.Contains(t, config.Packs, "monitoring")
+	assert.Len(t, config.Packs["monitoring"].Queries, 0)
+
+	// Changes made directly in the datastore are not seen until the cache
+	// is invalidated
+	test.NewScheduledQuery(t, ds, pack.ID, query.ID, 60, false, false)
+	config, err = svc.GetClientConfig(hostctx.NewContext(ctx, *host))
+	require.Nil(t, err)
+	assert.Len(t, config.Packs["monitoring"].Queries, 0)
+
+	// Changes made through the service invalidate the cache
+	_, err = svc.ScheduleQuery(ctx, &kolide.ScheduledQuery{PackID: pack.ID, QueryID: uptime.ID, Interval: 120})
+	require.Nil(t, err)
+	config, err = svc.GetClientConfig(hostctx.NewContext(ctx, *host))
+	require.Nil(t, err)
+	assert.Len(t, config.Packs["monitoring"].Queries, 2)
 }
 
 func TestDetailQueries(t *testing.T) {
@@ -559,6 +654,16 @@ func TestDetailQueries(t *testing.T) {
         "seconds": "13",
         "total_seconds": "1730893"
     }
+],
+"kolide_detail_query_osquery_flags": [
+    {
+        "name": "config_tls_refresh",
+        "value": "3600"
+    },
+    {
+        "name": "distributed_interval",
+        "value": "10"
+    }
 ]
 }
 `
@@ -589,6 +694,10 @@ func TestDetailQueries(t *testing.T) {
 	// uptime
 	assert.Equal(t, 1730893*time.Second, host.Uptime)
 
+	// osquery_flags
+	assert.Equal(t, uint(10), host.DistributedInterval)
+	assert.Equal(t, uint(3600), host.ConfigRefresh)
+
 	mockClock.AddTime(1 * time.Minute)
 
 	// Now no detail queries should be required
@@ -610,7 +719,72 @@ func TestDetailQueries(t *testing.T) {
 	ctx = hostctx.NewContext(ctx, *host)
 	queries, err = svc.GetDistributedQueries(ctx)
 	assert.Nil(t, err)
-	assert.Len(t, queries, len(detailQueries))
+	// The darwin host also collects its apps and homebrew packages
+	assert.Len(t, queries, len(detailQueries)+2)
+}
+
+func TestScheduledQueryStatsDetailQuery(t *testing.T) {
+	ds, err := inmem.New(config.TestConfig())
+	require.Nil(t, err)
+	// Sets the pack_delimiter option
+	require.Nil(t, ds.MigrateData())
+
+	svc, err := newTestService(ds, nil)
+	require.Nil(t, err)
+
+	ctx := context.Background()
+
+	user := test.NewUser(t, ds, "Admin", "admin", "admin@kolide.co", true)
+	query := test.NewQuery(t, ds, "time", "select * from time", user.ID, true)
+	pack := test.NewPack(t, ds, "monitoring")
+	sq := test.NewScheduledQuery(t, ds, pack.ID, query.ID, 60, false, false)
+
+	nodeKey, err := svc.EnrollAgent(ctx, "", "host123")
+	require.Nil(t, err)
+	host, err := ds.AuthenticateHost(nodeKey)
+	require.Nil(t, err)
+	ctx = hostctx.NewContext(ctx, *host)
+
+	queries, err := svc.GetDistributedQueries(ctx)
+	require.Nil(t, err)
+	assert.Equal(t, "select * from osquery_schedule", queries[hostDetailQueryPrefix+"scheduled_query_stats"])
+
+	results := kolide.OsqueryDistributedQueryResults{
+		hostDetailQueryPrefix + "scheduled_query_stats": {
+			{
+				"name":           "pack/monitoring/time",
+				"executions":     "12",
+				"last_executed":  "1485000000",
+				"wall_time":      "3",
+				"user_time":      "240",
+				"system_time":    "60",
+				"average_memory": "4096",
+				"output_size":    "100",
+				"blacklisted":    "1",
+			},
+			// Queries not in a pack are ignored
+			{
+				"name":       "pack/other/query",
+				"executions": "1",
+			},
+		},
+	}
+	err = svc.SubmitDistributedQueryResults(ctx, results, map[string]string{})
+	require.Nil(t, err)
+
+	stats, err := svc.GetScheduledQueryStatsInPack(ctx, pack.ID)
+	require.Nil(t, err)
+	require.Len(t, stats, 1)
+	assert.Equal(t, sq.ID, stats[0].ScheduledQueryID)
+	assert.Equal(t, uint(1), stats[0].HostCount)
+	assert.Equal(t, uint(1), stats[0].DenylistedCount)
+	assert.Equal(t, uint64(12), stats[0].Executions)
+	assert.Equal(t, uint64(240), stats[0].UserTime)
+	assert.Equal(t, uint64(4096), stats[0].AverageMemory)
+	assert.Equal(t, float64(20), stats[0].UserTimePerExecution)
+
+	_, err = svc.GetScheduledQueryStatsInPack(ctx, pack.ID+100)
+	assert.NotNil(t, err)
 }
 
 func TestDistributedQueries(t *testing.T) {
@@ -63,6 +63,15 @@ func (svc service) NewPack(ctx context.Context, p kolide.PackPayload) (*kolide.P
 		}
 	}
 
+	after, err := svc.packSnapshot(&pack)
+	if err != nil {
+		return nil, err
+	}
+	err = svc.recordRevision(ctx, kolide.RevisionObjectPack, pack.ID, kolide.RevisionActionCreated, nil, after, nil)
+	if err != nil {
+		return nil, err
+	}
+
 	return &pack, nil
 }
 
@@ -72,6 +81,11 @@ func (svc service) ModifyPack(ctx context.Context, id uint, p kolide.PackPayload
 		return nil, err
 	}
 
+	before, err := svc.packSnapshot(pack)
+	if err != nil {
+		return nil, err
+	}
+
 	if p.Name != nil {
 		pack.Name = *p.Name
 	}
@@ -189,11 +203,34 @@ func (svc service) ModifyPack(ctx context.Context, id uint, p kolide.PackPayload
 		}
 	}
 
-	return pack, err
+	after, err := svc.packSnapshot(pack)
+	if err != nil {
+		return nil, err
+	}
+	action, restoredFrom := revisionAction(ctx)
+	err = svc.recordRevision(ctx, kolide.RevisionObjectPack, pack.ID, action, before, after, restoredFrom)
+	if err != nil {
+		return nil, err
+	}
+
+	return pack, nil
 }
 
 func (svc service) DeletePack(ctx context.Context, id uint) error {
-	return svc.ds.DeletePack(id)
+	pack, err := svc.ds.Pack(id)
+	if err != nil {
+		return err
+	}
+	before, err := svc.packSnapshot(pack)
+	if err != nil {
+		return err
+	}
+
+	if err := svc.ds.DeletePack(id); err != nil {
+		return err
+	}
+
+	return svc.recordRevision(ctx, kolide.RevisionObjectPack, id, kolide.RevisionActionDeleted, before, nil, nil)
 }
 
 func (svc service) AddLabelToPack(ctx context.Context, lid, pid uint) error {
@@ -225,65 +262,46 @@ func (svc service) ListExplicitHostsInPack(ctx context.Context, pid uint, opt ko
 }
 
 func (svc service) ListPacksForHost(ctx context.Context, hid uint) ([]*kolide.Pack, error) {
-	packs := []*kolide.Pack{}
+	packs, _, err := svc.packsForHost(hid)
+	return packs, err
+}
 
-	// we will need to give some subset of packs to this host based on the
-	// labels which this host is known to belong to
-	allPacks, err := svc.ds.ListPacks(kolide.ListOptions{})
-	if err != nil {
-		return nil, err
-	}
+// packsForHost returns the enabled packs that target the host, either
+// explicitly or through a label that the host is a member of, along with why
+// each of them applies.
+func (svc service) packsForHost(hid uint) ([]*kolide.Pack, []*kolide.HostConfigPack, error) {
+	packs := []*kolide.Pack{}
+	reasons := []*kolide.HostConfigPack{}
 
-	// pull the labels that this host belongs to
-	labels, err := svc.ds.ListLabelsForHost(hid)
+	// targets are ordered by pack, so the targets of each pack are adjacent
+	targets, err := svc.ds.ListPackTargetsForHost(hid)
 	if err != nil {
-		return nil, err
-	}
-
-	// in order to use o(1) array indexing in an o(n) loop vs a o(n^2) double
-	// for loop iteration, we must create the array which may be indexed below
-	labelIDs := map[uint]bool{}
-	for _, label := range labels {
-		labelIDs[label.ID] = true
+		return nil, nil, err
 	}
 
-	for _, pack := range allPacks {
-		// don't include packs which have been disabled
-		if pack.Disabled {
-			continue
-		}
-
-		// for each pack, we must know what labels have been assigned to that
-		// pack
-		labelsForPack, err := svc.ds.ListLabelsForPack(pack.ID)
-		if err != nil {
-			return nil, err
-		}
-
-		// o(n) iteration to determine whether or not a pack is enabled
-		// in this case, n is len(labelsForPack)
-		for _, label := range labelsForPack {
-			if labelIDs[label.ID] {
-				packs = append(packs, pack)
-				break
+	var reason *kolide.HostConfigPack
+	for _, target := range targets {
+		if reason == nil || reason.ID != target.ID {
+			pack := target.Pack
+			reason = &kolide.HostConfigPack{
+				ID:     pack.ID,
+				Name:   pack.Name,
+				Labels: []kolide.HostConfigPackLabel{},
 			}
+			packs = append(packs, &pack)
+			reasons = append(reasons, reason)
 		}
 
-		// for each pack, we must know what host have been assigned to that pack
-		hostsForPack, err := svc.ds.ListExplicitHostsInPack(pack.ID, kolide.ListOptions{})
-		if err != nil {
-			return nil, err
-		}
-
-		// o(n) iteration to determine whether or not a pack is enabled
-		// in this case, n is len(hostsForPack)
-		for _, host := range hostsForPack {
-			if host.ID == hid {
-				packs = append(packs, pack)
-				break
-			}
+		switch target.TargetType {
+		case kolide.TargetHost:
+			reason.HostTarget = true
+		case kolide.TargetLabel:
+			reason.Labels = append(reason.Labels, kolide.HostConfigPackLabel{
+				ID:   target.TargetID,
+				Name: target.LabelName,
+			})
 		}
 	}
 
-	return packs, nil
+	return packs, reasons, nil
 }
@@ -1,21 +1,38 @@
 package service
 
 import (
+	"sort"
+	"strings"
+
 	"github.com/kolide/kolide-ose/server/contexts/viewer"
 	"github.com/kolide/kolide-ose/server/kolide"
+	"github.com/kolide/kolide-ose/server/sqlparser"
 	"golang.org/x/net/context"
 )
 
-func (svc service) ListQueries(ctx context.Context, opt kolide.ListOptions) ([]*kolide.Query, error) {
-	return svc.ds.ListQueries(opt)
+func (svc service) ListQueries(ctx context.Context, opt kolide.ListOptions, filter kolide.QueryFilter) ([]*kolide.Query, error) {
+	filter.Tags = normalizeQueryTags(filter.Tags)
+	queries, err := svc.ds.ListQueries(opt, filter)
+	if err != nil {
+		return nil, err
+	}
+	for _, query := range queries {
+		svc.setQueryPlatforms(query)
+	}
+	return queries, nil
 }
 
 func (svc service) GetQuery(ctx context.Context, id uint) (*kolide.Query, error) {
-	return svc.ds.Query(id)
+	query, err := svc.ds.Query(id)
+	if err != nil {
+		return nil, err
+	}
+	svc.setQueryPlatforms(query)
+	return query, nil
 }
 
 func (svc service) NewQuery(ctx context.Context, p kolide.QueryPayload) (*kolide.Query, error) {
-	query := &kolide.Query{Saved: true}
+	query := &kolide.Query{Saved: true, Tags: []string{}}
 
 	if p.Name != nil {
 		query.Name = *p.Name
@@ -29,6 +46,14 @@ func (svc service) NewQuery(ctx context.Context, p kolide.QueryPayload) (*kolide
 		query.Query = *p.Query
 	}
 
+	if p.Tags != nil {
+		query.Tags = normalizeQueryTags(*p.Tags)
+	}
+
+	if p.Category != nil {
+		query.Category = strings.TrimSpace(*p.Category)
+	}
+
 	vc, ok := viewer.FromContext(ctx)
 	if ok {
 		query.AuthorID = vc.UserID()
@@ -39,6 +64,16 @@ func (svc service) NewQuery(ctx context.Context, p kolide.QueryPayload) (*kolide
 	if err != nil {
 		return nil, err
 	}
+	svc.setQueryPlatforms(query)
+
+	after, err := querySnapshot(query)
+	if err != nil {
+		return nil, err
+	}
+	err = svc.recordRevision(ctx, kolide.RevisionObjectQuery, query.ID, kolide.RevisionActionCreated, nil, after, nil)
+	if err != nil {
+		return nil, err
+	}
 
 	return query, nil
 }
@@ -49,6 +84,11 @@ func (svc service) ModifyQuery(ctx context.Context, id uint, p kolide.QueryPaylo
 		return nil, err
 	}
 
+	before, err := querySnapshot(query)
+	if err != nil {
+		return nil, err
+	}
+
 	if p.Name != nil {
 		query.Name = *p.Name
 	}
@@ -61,18 +101,109 @@ func (svc service) ModifyQuery(ctx context.Context, id uint, p kolide.QueryPaylo
 		query.Query = *p.Query
 	}
 
+	if p.Tags != nil {
+		query.Tags = normalizeQueryTags(*p.Tags)
+	}
+
+	if p.Category != nil {
+		query.Category = strings.TrimSpace(*p.Category)
+	}
+
 	err = svc.ds.SaveQuery(query)
 	if err != nil {
 		return nil, err
 	}
+	svc.setQueryPlatforms(query)
+
+	after, err := querySnapshot(query)
+	if err != nil {
+		return nil, err
+	}
+	action, restoredFrom := revisionAction(ctx)
+	err = svc.recordRevision(ctx, kolide.RevisionObjectQuery, query.ID, action, before, after, restoredFrom)
+	if err != nil {
+		return nil, err
+	}
 
 	return query, nil
 }
 
 func (svc service) DeleteQuery(ctx context.Context, id uint) error {
-	return svc.ds.DeleteQuery(id)
+	query, err := svc.ds.Query(id)
+	if err != nil {
+		return err
+	}
+	before, err := querySnapshot(query)
+	if err != nil {
+		return err
+	}
+
+	if err := svc.ds.DeleteQuery(id); err != nil {
+		return err
+	}
+
+	return svc.recordRevision(ctx, kolide.RevisionObjectQuery, id, kolide.RevisionActionDeleted, before, nil, nil)
 }
 
 func (svc service) DeleteQueries(ctx context.Context, ids []uint) (uint, error) {
-	return svc.ds.DeleteQueries(ids)
+	// Snapshot the queries that exist so that their deletion can be
+	// recorded
+	snapshots := map[uint]kolide.RevisionSnapshot{}
+	for _, id := range ids {
+		query, err := svc.ds.Query(id)
+		if err != nil {
+			if e, ok := err.(kolide.NotFoundError); ok && e.IsNotFound() {
+				continue
+			}
+			return 0, err
+		}
+		if snapshots[id], err = querySnapshot(query); err != nil {
+			return 0, err
+		}
+	}
+
+	deleted, err := svc.ds.DeleteQueries(ids)
+	if err != nil {
+		return deleted, err
+	}
+
+	for id, before := range snapshots {
+		err := svc.recordRevision(ctx, kolide.RevisionObjectQuery, id, kolide.RevisionActionDeleted, before, nil, nil)
+		if err != nil {
+			return deleted, err
+		}
+	}
+	return deleted, nil
+}
+
+// normalizeQueryTags trims and lowercases tags, and returns them sorted
+// with duplicates removed.
+func normalizeQueryTags(tags []string) []string {
+	seen := map[string]bool{}
+	normalized := []string{}
+	for _, tag := range tags {
+		tag = strings.ToLower(strings.TrimSpace(tag))
+		if tag == "" || seen[tag] {
+			continue
+		}
+		seen[tag] = true
+		normalized = append(normalized, tag)
+	}
+	sort.Strings(normalized)
+	return normalized
+}
+
+// setQueryPlatforms fills in the platforms that the query can run on. Queries
+// saved before schema validation may not parse, in which case the platforms
+// are left unset.
+func (svc service) setQueryPlatforms(query *kolide.Query) {
+	if svc.osquerySchema == nil {
+		return
+	}
+	analysis, err := sqlparser.Analyze(query.Query, svc.osquerySchema)
+	if err != nil {
+		query.Platforms = nil
+		return
+	}
+	query.Platforms = svc.osquerySchema.Platforms(analysis.Tables)
 }
@@ -1,6 +1,7 @@
 package service
 
 import (
+	"strings"
 	"testing"
 
 	"github.com/kolide/kolide-ose/server/config"
@@ -21,7 +22,7 @@ func TestListQueries(t *testing.T) {
 
 	ctx := context.Background()
 
-	queries, err := svc.ListQueries(ctx, kolide.ListOptions{})
+	queries, err := svc.ListQueries(ctx, kolide.ListOptions{}, kolide.QueryFilter{})
 	assert.Nil(t, err)
 	assert.Len(t, queries, 0)
 
@@ -33,7 +34,7 @@ func TestListQueries(t *testing.T) {
 	})
 	assert.Nil(t, err)
 
-	queries, err = svc.ListQueries(ctx, kolide.ListOptions{})
+	queries, err = svc.ListQueries(ctx, kolide.ListOptions{}, kolide.QueryFilter{})
 	assert.Nil(t, err)
 	assert.Len(t, queries, 1)
 }
@@ -84,7 +85,7 @@ func TestNewQuery(t *testing.T) {
 	assert.Equal(t, "Test Name admin1", q.AuthorName)
 	assert.Equal(t, []kolide.Pack{}, q.Packs)
 
-	queries, err := ds.ListQueries(kolide.ListOptions{})
+	queries, err := ds.ListQueries(kolide.ListOptions{}, kolide.QueryFilter{})
 	assert.Nil(t, err)
 	if assert.Len(t, queries, 1) {
 		assert.Equal(t, "Test Name admin1", queries[0].AuthorName)
@@ -138,7 +139,102 @@ func TestDeleteQuery(t *testing.T) {
 	err = svc.DeleteQuery(ctx, query.ID)
 	assert.Nil(t, err)
 
-	queries, err := ds.ListQueries(kolide.ListOptions{})
+	queries, err := ds.ListQueries(kolide.ListOptions{}, kolide.QueryFilter{})
 	assert.Nil(t, err)
 	assert.Len(t, queries, 0)
 }
+
+func TestQueryValidationAgainstSchema(t *testing.T) {
+	ds, err := inmem.New(config.TestConfig())
+	require.Nil(t, err)
+
+	svc, err := newTestService(ds, nil)
+	require.Nil(t, err)
+
+	ctx := context.Background()
+
+	var invalidQueries = []string{
+		"select * from proceses",
+		"select nme from processes",
+		"select * from processes where",
+		"delete from processes",
+	}
+	for _, sql := range invalidQueries {
+		name := sql
+		_, err := svc.NewQuery(ctx, kolide.QueryPayload{Name: &name, Query: &sql})
+		require.NotNil(t, err, sql)
+		assert.IsType(t, &invalidArgumentError{}, err)
+	}
+
+	name := "processes"
+	sql := "select u.username, k.key from users u join authorized_keys k using (uid)"
+	query, err := svc.NewQuery(ctx, kolide.QueryPayload{Name: &name, Query: &sql})
+	require.Nil(t, err)
+	assert.Equal(t, []string{kolide.PlatformDarwin, kolide.PlatformLinux}, query.Platforms)
+
+	sql = "select * from no_such_table"
+	_, err = svc.ModifyQuery(ctx, query.ID, kolide.QueryPayload{Query: &sql})
+	require.NotNil(t, err)
+	assert.IsType(t, &invalidArgumentError{}, err)
+
+	sql = "select version from osquery_info"
+	query, err = svc.ModifyQuery(ctx, query.ID, kolide.QueryPayload{Query: &sql})
+	require.Nil(t, err)
+	assert.Equal(t, kolide.AllPlatforms, query.Platforms)
+
+	query, err = svc.GetQuery(ctx, query.ID)
+	require.Nil(t, err)
+	assert.Equal(t, kolide.AllPlatforms, query.Platforms)
+
+	_, err = svc.NewDistributedQueryCampaign(ctx, "select * from nope", nil, nil)
+	require.NotNil(t, err)
+	assert.IsType(t, &invalidArgumentError{}, err)
+}
+
+func TestQueryTagsAndCategory(t *testing.T) {
+	ds, err := inmem.New(config.TestConfig())
+	require.Nil(t, err)
+
+	svc, err := newTestService(ds, nil)
+	require.Nil(t, err)
+
+	ctx := context.Background()
+
+	name := "time"
+	sql := "select * from time"
+	tags := []string{" Linux", "network", "linux ", ""}
+	category := " inventory "
+	query, err := svc.NewQuery(ctx, kolide.QueryPayload{
+		Name:     &name,
+		Query:    &sql,
+		Tags:     &tags,
+		Category: &category,
+	})
+	require.Nil(t, err)
+	assert.Equal(t, []string{"linux", "network"}, query.Tags)
+	assert.Equal(t, "inventory", query.Category)
+
+	queries, err := svc.ListQueries(ctx, kolide.ListOptions{}, kolide.QueryFilter{Tags: []string{"LINUX"}})
+	require.Nil(t, err)
+	assert.Len(t, queries, 1)
+
+	queries, err = svc.ListQueries(ctx, kolide.ListOptions{}, kolide.QueryFilter{Category: "security"})
+	require.Nil(t, err)
+	assert.Len(t, queries, 0)
+
+	tags = []string{"bad,tag"}
+	_, err = svc.ModifyQuery(ctx, query.ID, kolide.QueryPayload{Tags: &tags})
+	require.NotNil(t, err)
+	assert.IsType(t, &invalidArgumentError{}, err)
+
+	tags = []string{strings.Repeat("x", maxQueryTagLength+1)}
+	_, err = svc.ModifyQuery(ctx, query.ID, kolide.QueryPayload{Tags: &tags})
+	require.NotNil(t, err)
+	assert.IsType(t, &invalidArgumentError{}, err)
+
+	tags = []string{}
+	query, err = svc.ModifyQuery(ctx, query.ID, kolide.QueryPayload{Tags: &tags})
+	require.Nil(t, err)
+	assert.Equal(t, []string{}, query.Tags)
+	assert.Equal(t, "inventory", query.Category)
+}
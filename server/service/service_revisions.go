@@ -0,0 +1,348 @@
+package service
+
+import (
+	"encoding/json"
+	"strings"
+
+	"github.com/kolide/kolide-ose/server/contexts/viewer"
+	"github.com/kolide/kolide-ose/server/kolide"
+	"github.com/pkg/errors"
+	"golang.org/x/net/context"
+)
+
+// queryRevision, packRevision and scheduledQueryRevision are the fields of
+// each object type that are recorded in a revision snapshot, and restored
+// from it.
+type queryRevision struct {
+	Name        string   `json:"name"`
+	Description string   `json:"description"`
+	Query       string   `json:"query"`
+	Tags        []string `json:"tags"`
+	Category    string   `json:"category"`
+}
+
+type packRevision struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	Platform    string `json:"platform"`
+	Disabled    bool   `json:"disabled"`
+	LabelIDs    []uint `json:"label_ids"`
+	HostIDs     []uint `json:"host_ids"`
+}
+
+type scheduledQueryRevision struct {
+	PackID   uint    `json:"pack_id"`
+	QueryID  uint    `json:"query_id"`
+	Interval uint    `json:"interval"`
+	Snapshot *bool   `json:"snapshot"`
+	Removed  *bool   `json:"removed"`
+	Platform *string `json:"platform"`
+	Version  *string `json:"version"`
+	Shard    *uint   `json:"shard"`
+}
+
+// newRevisionSnapshot converts one of the revision structs above to a
+// snapshot. Going through JSON gives snapshots the same value types whether
+// they were just created or read back from the datastore, so that they can
+// be compared.
+func newRevisionSnapshot(v interface{}) (kolide.RevisionSnapshot, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, errors.Wrap(err, "marshaling revision snapshot")
+	}
+	var snapshot kolide.RevisionSnapshot
+	if err := json.Unmarshal(data, &snapshot); err != nil {
+		return nil, errors.Wrap(err, "unmarshaling revision snapshot")
+	}
+	return snapshot, nil
+}
+
+// decodeRevisionSnapshot is the inverse of newRevisionSnapshot.
+func decodeRevisionSnapshot(snapshot kolide.RevisionSnapshot, v interface{}) error {
+	data, err := json.Marshal(snapshot)
+	if err != nil {
+		return errors.Wrap(err, "marshaling revision snapshot")
+	}
+	return errors.Wrap(json.Unmarshal(data, v), "decoding revision snapshot")
+}
+
+func querySnapshot(query *kolide.Query) (kolide.RevisionSnapshot, error) {
+	return newRevisionSnapshot(queryRevision{
+		Name:        query.Name,
+		Description: query.Description,
+		Query:       query.Query,
+		Tags:        normalizeQueryTags(query.Tags),
+		Category:    query.Category,
+	})
+}
+
+func (svc service) packSnapshot(pack *kolide.Pack) (kolide.RevisionSnapshot, error) {
+	labels, err := svc.ds.ListLabelsForPack(pack.ID)
+	if err != nil {
+		return nil, errors.Wrap(err, "listing labels for pack revision")
+	}
+	hosts, err := svc.ds.ListExplicitHostsInPack(pack.ID, kolide.ListOptions{})
+	if err != nil {
+		return nil, errors.Wrap(err, "listing hosts for pack revision")
+	}
+
+	rev := packRevision{
+		Name:        pack.Name,
+		Description: pack.Description,
+		Platform:    pack.Platform,
+		Disabled:    pack.Disabled,
+		LabelIDs:    []uint{},
+		HostIDs:     []uint{},
+	}
+	for _, label := range labels {
+		rev.LabelIDs = append(rev.LabelIDs, label.ID)
+	}
+	for _, host := range hosts {
+		rev.HostIDs = append(rev.HostIDs, host.ID)
+	}
+	return newRevisionSnapshot(rev)
+}
+
+func scheduledQuerySnapshot(sq *kolide.ScheduledQuery) (kolide.RevisionSnapshot, error) {
+	return newRevisionSnapshot(scheduledQueryRevision{
+		PackID:   sq.PackID,
+		QueryID:  sq.QueryID,
+		Interval: sq.Interval,
+		Snapshot: sq.Snapshot,
+		Removed:  sq.Removed,
+		Platform: sq.Platform,
+		Version:  sq.Version,
+		Shard:    sq.Shard,
+	})
+}
+
+// recordRevision stores a revision of an object. before is nil for a newly
+// created object, and after is nil for a deleted one.
+func (svc service) recordRevision(ctx context.Context, objectType string, objectID uint, action string, before, after kolide.RevisionSnapshot, restoredFrom *uint) error {
+	rev := &kolide.Revision{
+		CreatedAt:    svc.clock.Now(),
+		ObjectType:   objectType,
+		ObjectID:     objectID,
+		Action:       action,
+		RestoredFrom: restoredFrom,
+		Snapshot:     after,
+		Changes:      kolide.DiffRevisionSnapshots(before, after),
+	}
+	if after == nil {
+		// Keep the final state of deleted objects
+		rev.Snapshot = before
+		rev.Changes = kolide.RevisionChanges{}
+	}
+
+	if vc, ok := viewer.FromContext(ctx); ok {
+		if userID := vc.UserID(); userID != 0 {
+			rev.AuthorID = &userID
+		}
+	}
+
+	rev, err := svc.ds.NewRevision(rev)
+	if err != nil {
+		return errors.Wrap(err, "recording revision")
+	}
+	if restore, ok := ctx.Value(restoreContextKey{}).(*revisionRestore); ok {
+		restore.recorded = rev
+	}
+	return nil
+}
+
+func (svc service) ListRevisions(ctx context.Context, objectType string, objectID uint, opt kolide.ListOptions) ([]*kolide.Revision, error) {
+	switch objectType {
+	case kolide.RevisionObjectQuery, kolide.RevisionObjectPack, kolide.RevisionObjectScheduledQuery:
+	default:
+		return nil, newInvalidArgumentError("object_type", "unknown object type: "+objectType)
+	}
+	return svc.ds.ListRevisions(objectType, objectID, opt)
+}
+
+func (svc service) DiffRevisions(ctx context.Context, fromID, toID uint) ([]kolide.RevisionChange, error) {
+	from, err := svc.ds.Revision(fromID)
+	if err != nil {
+		return nil, err
+	}
+	to, err := svc.ds.Revision(toID)
+	if err != nil {
+		return nil, err
+	}
+	if from.ObjectType != to.ObjectType || from.ObjectID != to.ObjectID {
+		return nil, newInvalidArgumentError("to", "revisions must belong to the same object")
+	}
+	return kolide.DiffRevisionSnapshots(from.Snapshot, to.Snapshot), nil
+}
+
+// restoreContextKey marks a context as restoring a revision, so that the
+// modification made with it is recorded as a restore.
+type restoreContextKey struct{}
+
+// revisionRestore is the value stored under restoreContextKey. The revision
+// recorded for the restore is handed back through it, so that the restore
+// does not have to look the revision up again.
+type revisionRestore struct {
+	revisionID uint
+	recorded   *kolide.Revision
+}
+
+// withRestoredRevision returns a context for restoring the revision.
+func withRestoredRevision(ctx context.Context, restore *revisionRestore) context.Context {
+	return context.WithValue(ctx, restoreContextKey{}, restore)
+}
+
+// revisionAction returns the action that a modification made with the
+// context is recorded as, along with the ID of the restored revision when
+// the modification is a restore.
+func revisionAction(ctx context.Context) (string, *uint) {
+	if restore, ok := ctx.Value(restoreContextKey{}).(*revisionRestore); ok {
+		id := restore.revisionID
+		return kolide.RevisionActionRestored, &id
+	}
+	return kolide.RevisionActionModified, nil
+}
+
+// queryRevisionPayload returns the payload that restores a query to the
+// snapshot.
+func queryRevisionPayload(snapshot kolide.RevisionSnapshot) (kolide.QueryPayload, error) {
+	var state queryRevision
+	if err := decodeRevisionSnapshot(snapshot, &state); err != nil {
+		return kolide.QueryPayload{}, err
+	}
+	return kolide.QueryPayload{
+		Name:        &state.Name,
+		Description: &state.Description,
+		Query:       &state.Query,
+		Tags:        &state.Tags,
+		Category:    &state.Category,
+	}, nil
+}
+
+// packRevisionPayload returns the payload that restores a pack to the
+// snapshot. Labels and hosts that no longer exist are dropped from the
+// targets.
+func (svc service) packRevisionPayload(snapshot kolide.RevisionSnapshot) (kolide.PackPayload, error) {
+	var state packRevision
+	if err := decodeRevisionSnapshot(snapshot, &state); err != nil {
+		return kolide.PackPayload{}, err
+	}
+
+	labelIDs := []uint{}
+	for _, id := range state.LabelIDs {
+		if _, err := svc.ds.Label(id); err != nil {
+			if e, ok := err.(kolide.NotFoundError); ok && e.IsNotFound() {
+				continue
+			}
+			return kolide.PackPayload{}, err
+		}
+		labelIDs = append(labelIDs, id)
+	}
+	hostIDs := []uint{}
+	for _, id := range state.HostIDs {
+		if _, err := svc.ds.Host(id); err != nil {
+			if e, ok := err.(kolide.NotFoundError); ok && e.IsNotFound() {
+				continue
+			}
+			return kolide.PackPayload{}, err
+		}
+		hostIDs = append(hostIDs, id)
+	}
+
+	return kolide.PackPayload{
+		Name:        &state.Name,
+		Description: &state.Description,
+		Platform:    &state.Platform,
+		Disabled:    &state.Disabled,
+		LabelIDs:    &labelIDs,
+		HostIDs:     &hostIDs,
+	}, nil
+}
+
+// revisionObjectExists returns false if the object a revision belongs to was
+// deleted.
+func (svc service) revisionObjectExists(rev *kolide.Revision) (bool, error) {
+	var err error
+	switch rev.ObjectType {
+	case kolide.RevisionObjectQuery:
+		_, err = svc.ds.Query(rev.ObjectID)
+	case kolide.RevisionObjectPack:
+		_, err = svc.ds.Pack(rev.ObjectID)
+	case kolide.RevisionObjectScheduledQuery:
+		_, err = svc.ds.ScheduledQuery(rev.ObjectID)
+	}
+	if err != nil {
+		if e, ok := err.(kolide.NotFoundError); ok && e.IsNotFound() {
+			return false, nil
+		}
+		return false, err
+	}
+	return true, nil
+}
+
+// RestoreRevision returns an object to the state of a revision. The restore
+// is made through the same methods as any other modification, and is
+// recorded as a new revision. Deleted objects cannot be restored.
+func (svc service) RestoreRevision(ctx context.Context, id uint) (*kolide.Revision, error) {
+	rev, err := svc.ds.Revision(id)
+	if err != nil {
+		return nil, err
+	}
+
+	exists, err := svc.revisionObjectExists(rev)
+	if err != nil {
+		return nil, err
+	}
+	if !exists {
+		return nil, newInvalidArgumentError("id", "revision belongs to a deleted "+strings.Replace(rev.ObjectType, "_", " ", -1)+" and cannot be restored")
+	}
+
+	restore := &revisionRestore{revisionID: rev.ID}
+	ctx = withRestoredRevision(ctx, restore)
+	switch rev.ObjectType {
+	case kolide.RevisionObjectQuery:
+		payload, err := queryRevisionPayload(rev.Snapshot)
+		if err != nil {
+			return nil, err
+		}
+		if _, err := svc.ModifyQuery(ctx, rev.ObjectID, payload); err != nil {
+			return nil, err
+		}
+
+	case kolide.RevisionObjectPack:
+		payload, err := svc.packRevisionPayload(rev.Snapshot)
+		if err != nil {
+			return nil, err
+		}
+		if _, err := svc.ModifyPack(ctx, rev.ObjectID, payload); err != nil {
+			return nil, err
+		}
+
+	case kolide.RevisionObjectScheduledQuery:
+		var state scheduledQueryRevision
+		if err := decodeRevisionSnapshot(rev.Snapshot, &state); err != nil {
+			return nil, err
+		}
+		sq := &kolide.ScheduledQuery{
+			ID:       rev.ObjectID,
+			PackID:   state.PackID,
+			QueryID:  state.QueryID,
+			Interval: state.Interval,
+			Snapshot: state.Snapshot,
+			Removed:  state.Removed,
+			Platform: state.Platform,
+			Version:  state.Version,
+			Shard:    state.Shard,
+		}
+		if _, err := svc.ModifyScheduledQuery(ctx, sq); err != nil {
+			return nil, err
+		}
+
+	default:
+		return nil, errors.Errorf("cannot restore revision of unknown object type %q", rev.ObjectType)
+	}
+
+	if restore.recorded == nil {
+		return nil, errors.New("restore did not record a revision")
+	}
+	return restore.recorded, nil
+}
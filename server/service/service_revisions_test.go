@@ -0,0 +1,245 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"github.com/kolide/kolide-ose/server/config"
+	"github.com/kolide/kolide-ose/server/contexts/viewer"
+	"github.com/kolide/kolide-ose/server/datastore/inmem"
+	"github.com/kolide/kolide-ose/server/kolide"
+	"github.com/kolide/kolide-ose/server/test"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/net/context"
+)
+
+func TestQueryRevisions(t *testing.T) {
+	ds, err := inmem.New(config.TestConfig())
+	require.Nil(t, err)
+	svc, err := newTestService(ds, nil)
+	require.Nil(t, err)
+
+	user := test.NewUser(t, ds, "Admin", "admin", "admin@kolide.co", true)
+	ctx := viewer.NewContext(context.Background(), viewer.Viewer{User: user})
+
+	name, sql := "foo", "select * from time"
+	query, err := svc.NewQuery(ctx, kolide.QueryPayload{Name: &name, Query: &sql})
+	require.Nil(t, err)
+
+	sql = "select * from osquery_info"
+	_, err = svc.ModifyQuery(ctx, query.ID, kolide.QueryPayload{Query: &sql})
+	require.Nil(t, err)
+
+	revisions, err := svc.ListRevisions(ctx, kolide.RevisionObjectQuery, query.ID, kolide.ListOptions{})
+	require.Nil(t, err)
+	require.Len(t, revisions, 2)
+
+	modified, created := revisions[0], revisions[1]
+	assert.Equal(t, kolide.RevisionActionCreated, created.Action)
+	assert.Equal(t, kolide.RevisionActionModified, modified.Action)
+	require.NotNil(t, modified.AuthorID)
+	assert.Equal(t, user.ID, *modified.AuthorID)
+	assert.Equal(t, kolide.RevisionChanges{
+		{Field: "query", Old: "select * from time", New: "select * from osquery_info"},
+	}, modified.Changes)
+	assert.Equal(t, "select * from osquery_info", modified.Snapshot["query"])
+
+	changes, err := svc.DiffRevisions(ctx, created.ID, modified.ID)
+	require.Nil(t, err)
+	assert.Equal(t, []kolide.RevisionChange{
+		{Field: "query", Old: "select * from time", New: "select * from osquery_info"},
+	}, changes)
+
+	restored, err := svc.RestoreRevision(ctx, created.ID)
+	require.Nil(t, err)
+	assert.NotZero(t, restored.ID)
+	assert.Equal(t, query.ID, restored.ObjectID)
+	assert.Equal(t, kolide.RevisionActionRestored, restored.Action)
+	require.NotNil(t, restored.RestoredFrom)
+	assert.Equal(t, created.ID, *restored.RestoredFrom)
+
+	query, err = svc.GetQuery(ctx, query.ID)
+	require.Nil(t, err)
+	assert.Equal(t, "select * from time", query.Query)
+
+	require.Nil(t, svc.DeleteQuery(ctx, query.ID))
+	revisions, err = svc.ListRevisions(ctx, kolide.RevisionObjectQuery, query.ID, kolide.ListOptions{})
+	require.Nil(t, err)
+	require.Len(t, revisions, 4)
+	assert.Equal(t, kolide.RevisionActionDeleted, revisions[0].Action)
+	assert.Equal(t, "foo", revisions[0].Snapshot["name"])
+
+	// A deleted query cannot be restored
+	_, err = svc.RestoreRevision(ctx, created.ID)
+	assert.IsType(t, &invalidArgumentError{}, err)
+
+	_, err = svc.ListRevisions(ctx, "host", 1, kolide.ListOptions{})
+	assert.IsType(t, &invalidArgumentError{}, err)
+}
+
+func TestRestoreQueryRevisionValidated(t *testing.T) {
+	ds, err := inmem.New(config.TestConfig())
+	require.Nil(t, err)
+	svc, err := newTestService(ds, nil)
+	require.Nil(t, err)
+
+	user := test.NewUser(t, ds, "Admin", "admin", "admin@kolide.co", true)
+	ctx := viewer.NewContext(context.Background(), viewer.Viewer{User: user})
+
+	name, sql := "foo", "select * from time"
+	query, err := svc.NewQuery(ctx, kolide.QueryPayload{Name: &name, Query: &sql})
+	require.Nil(t, err)
+
+	// A revision recorded before a table was removed from the schema
+	rev, err := ds.NewRevision(&kolide.Revision{
+		ObjectType: kolide.RevisionObjectQuery,
+		ObjectID:   query.ID,
+		Action:     kolide.RevisionActionModified,
+		Snapshot: kolide.RevisionSnapshot{
+			"name":  "foo",
+			"query": "select * from no_such_table",
+			"tags":  []interface{}{},
+		},
+	})
+	require.Nil(t, err)
+
+	_, err = svc.RestoreRevision(ctx, rev.ID)
+	assert.IsType(t, &invalidArgumentError{}, err)
+
+	query, err = svc.GetQuery(ctx, query.ID)
+	require.Nil(t, err)
+	assert.Equal(t, "select * from time", query.Query)
+}
+
+func TestPackRevisions(t *testing.T) {
+	ds, err := inmem.New(config.TestConfig())
+	require.Nil(t, err)
+	svc, err := newTestService(ds, nil)
+	require.Nil(t, err)
+	ctx := context.Background()
+
+	label, err := ds.NewLabel(&kolide.Label{Name: "label", Query: "select 1"})
+	require.Nil(t, err)
+
+	name := "pack"
+	pack, err := svc.NewPack(ctx, kolide.PackPayload{Name: &name})
+	require.Nil(t, err)
+
+	disabled := true
+	labelIDs := []uint{label.ID}
+	_, err = svc.ModifyPack(ctx, pack.ID, kolide.PackPayload{Disabled: &disabled, LabelIDs: &labelIDs})
+	require.Nil(t, err)
+
+	revisions, err := svc.ListRevisions(ctx, kolide.RevisionObjectPack, pack.ID, kolide.ListOptions{})
+	require.Nil(t, err)
+	require.Len(t, revisions, 2)
+	require.Len(t, revisions[0].Changes, 2)
+	assert.Equal(t, "disabled", revisions[0].Changes[0].Field)
+	assert.Equal(t, "label_ids", revisions[0].Changes[1].Field)
+
+	_, err = svc.RestoreRevision(ctx, revisions[1].ID)
+	require.Nil(t, err)
+
+	pack, err = svc.GetPack(ctx, pack.ID)
+	require.Nil(t, err)
+	assert.False(t, pack.Disabled)
+	labels, err := svc.ListLabelsForPack(ctx, pack.ID)
+	require.Nil(t, err)
+	assert.Len(t, labels, 0)
+
+	// A deleted pack cannot be restored
+	require.Nil(t, svc.DeletePack(ctx, pack.ID))
+	_, err = svc.RestoreRevision(ctx, revisions[1].ID)
+	assert.IsType(t, &invalidArgumentError{}, err)
+}
+
+func TestRestorePackRevisionDropsDeletedTargets(t *testing.T) {
+	ds, err := inmem.New(config.TestConfig())
+	require.Nil(t, err)
+	svc, err := newTestService(ds, nil)
+	require.Nil(t, err)
+	ctx := context.Background()
+
+	l1, err := ds.NewLabel(&kolide.Label{Name: "label foo", Query: "select 1"})
+	require.Nil(t, err)
+	l2, err := ds.NewLabel(&kolide.Label{Name: "label bar", Query: "select 1"})
+	require.Nil(t, err)
+	h1 := test.NewHost(t, ds, "foo.local", "192.168.1.10", "1", "1", time.Now())
+	h2 := test.NewHost(t, ds, "bar.local", "192.168.1.11", "2", "2", time.Now())
+
+	name := "pack"
+	labelIDs := []uint{l1.ID, l2.ID}
+	hostIDs := []uint{h1.ID, h2.ID}
+	pack, err := svc.NewPack(ctx, kolide.PackPayload{Name: &name})
+	require.Nil(t, err)
+	_, err = svc.ModifyPack(ctx, pack.ID, kolide.PackPayload{LabelIDs: &labelIDs, HostIDs: &hostIDs})
+	require.Nil(t, err)
+
+	revisions, err := svc.ListRevisions(ctx, kolide.RevisionObjectPack, pack.ID, kolide.ListOptions{})
+	require.Nil(t, err)
+	require.Len(t, revisions, 2)
+	targeted := revisions[0]
+
+	labelIDs, hostIDs = []uint{}, []uint{}
+	_, err = svc.ModifyPack(ctx, pack.ID, kolide.PackPayload{LabelIDs: &labelIDs, HostIDs: &hostIDs})
+	require.Nil(t, err)
+	require.Nil(t, ds.DeleteLabel(l2.ID))
+	require.Nil(t, ds.DeleteHost(h2.ID))
+
+	_, err = svc.RestoreRevision(ctx, targeted.ID)
+	require.Nil(t, err)
+
+	labels, err := svc.ListLabelsForPack(ctx, pack.ID)
+	require.Nil(t, err)
+	require.Len(t, labels, 1)
+	assert.Equal(t, l1.ID, labels[0].ID)
+	hosts, err := ds.ListExplicitHostsInPack(pack.ID, kolide.ListOptions{})
+	require.Nil(t, err)
+	require.Len(t, hosts, 1)
+	assert.Equal(t, h1.ID, hosts[0].ID)
+}
+
+func TestScheduledQueryRevisions(t *testing.T) {
+	ds, err := inmem.New(config.TestConfig())
+	require.Nil(t, err)
+	svc, err := newTestService(ds, nil)
+	require.Nil(t, err)
+	ctx := context.Background()
+
+	u1 := test.NewUser(t, ds, "Admin", "admin", "admin@kolide.co", true)
+	q1 := test.NewQuery(t, ds, "foo", "select * from time;", u1.ID, true)
+	p1 := test.NewPack(t, ds, "baz")
+
+	sq, err := svc.ScheduleQuery(ctx, &kolide.ScheduledQuery{PackID: p1.ID, QueryID: q1.ID, Interval: 60})
+	require.Nil(t, err)
+
+	_, err = svc.ModifyScheduledQuery(ctx, &kolide.ScheduledQuery{ID: sq.ID, PackID: p1.ID, QueryID: q1.ID, Interval: 120})
+	require.Nil(t, err)
+
+	revisions, err := svc.ListRevisions(ctx, kolide.RevisionObjectScheduledQuery, sq.ID, kolide.ListOptions{})
+	require.Nil(t, err)
+	require.Len(t, revisions, 2)
+	assert.Equal(t, kolide.RevisionChanges{
+		{Field: "interval", Old: float64(60), New: float64(120)},
+	}, revisions[0].Changes)
+
+	_, err = svc.RestoreRevision(ctx, revisions[1].ID)
+	require.Nil(t, err)
+
+	sq, err = svc.GetScheduledQuery(ctx, sq.ID)
+	require.Nil(t, err)
+	assert.Equal(t, uint(60), sq.Interval)
+
+	// A deleted scheduled query cannot be restored
+	require.Nil(t, svc.DeleteScheduledQuery(ctx, sq.ID))
+	_, err = svc.RestoreRevision(ctx, revisions[1].ID)
+	assert.IsType(t, &invalidArgumentError{}, err)
+
+	// Revisions of different objects cannot be compared
+	queryRevisions, err := svc.ListRevisions(ctx, kolide.RevisionObjectQuery, q1.ID, kolide.ListOptions{})
+	require.Nil(t, err)
+	assert.Len(t, queryRevisions, 0)
+	_, err = svc.DiffRevisions(ctx, revisions[0].ID, 12345)
+	assert.NotNil(t, err)
+}
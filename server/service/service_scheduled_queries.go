@@ -13,14 +13,72 @@ func (svc service) GetScheduledQueriesInPack(ctx context.Context, id uint, opts
 	return svc.ds.ListScheduledQueriesInPack(id, opts)
 }
 
+func (svc service) GetScheduledQueryStatsInPack(ctx context.Context, packID uint) ([]*kolide.ScheduledQueryStatsRollup, error) {
+	if _, err := svc.ds.Pack(packID); err != nil {
+		return nil, err
+	}
+	return svc.ds.ScheduledQueryStatsInPack(packID)
+}
+
 func (svc service) ScheduleQuery(ctx context.Context, sq *kolide.ScheduledQuery) (*kolide.ScheduledQuery, error) {
-	return svc.ds.NewScheduledQuery(sq)
+	sq, err := svc.ds.NewScheduledQuery(sq)
+	if err != nil {
+		return nil, err
+	}
+
+	after, err := scheduledQuerySnapshot(sq)
+	if err != nil {
+		return nil, err
+	}
+	err = svc.recordRevision(ctx, kolide.RevisionObjectScheduledQuery, sq.ID, kolide.RevisionActionCreated, nil, after, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return sq, nil
 }
 
 func (svc service) ModifyScheduledQuery(ctx context.Context, sq *kolide.ScheduledQuery) (*kolide.ScheduledQuery, error) {
-	return svc.ds.SaveScheduledQuery(sq)
+	existing, err := svc.ds.ScheduledQuery(sq.ID)
+	if err != nil {
+		return nil, err
+	}
+	before, err := scheduledQuerySnapshot(existing)
+	if err != nil {
+		return nil, err
+	}
+
+	sq, err = svc.ds.SaveScheduledQuery(sq)
+	if err != nil {
+		return nil, err
+	}
+
+	after, err := scheduledQuerySnapshot(sq)
+	if err != nil {
+		return nil, err
+	}
+	action, restoredFrom := revisionAction(ctx)
+	err = svc.recordRevision(ctx, kolide.RevisionObjectScheduledQuery, sq.ID, action, before, after, restoredFrom)
+	if err != nil {
+		return nil, err
+	}
+
+	return sq, nil
 }
 
 func (svc service) DeleteScheduledQuery(ctx context.Context, id uint) error {
-	return svc.ds.DeleteScheduledQuery(id)
+	sq, err := svc.ds.ScheduledQuery(id)
+	if err != nil {
+		return err
+	}
+	before, err := scheduledQuerySnapshot(sq)
+	if err != nil {
+		return err
+	}
+
+	if err := svc.ds.DeleteScheduledQuery(id); err != nil {
+		return err
+	}
+
+	return svc.recordRevision(ctx, kolide.RevisionObjectScheduledQuery, id, kolide.RevisionActionDeleted, before, nil, nil)
 }
@@ -0,0 +1,37 @@
+package service
+
+import (
+	"io"
+
+	"github.com/pkg/errors"
+	"golang.org/x/net/context"
+)
+
+func (svc service) Shutdown(ctx context.Context) error {
+	// Each step runs even when an earlier one fails, so that a campaign
+	// stream that is slow to close does not keep the logs from being
+	// flushed. The first error is returned and the others are logged.
+	var firstErr error
+	fail := func(err error) {
+		if firstErr == nil {
+			firstErr = err
+			return
+		}
+		svc.logger.Log("method", "Shutdown", "err", err)
+	}
+
+	if err := svc.campaignStreams.close(ctx); err != nil {
+		fail(errors.Wrap(err, "closing campaign streams"))
+	}
+	if err := svc.FlushSeenHosts(ctx); err != nil {
+		fail(err)
+	}
+	for _, w := range []io.Writer{svc.osqueryStatusLogWriter, svc.osqueryResultLogWriter} {
+		if closer, ok := w.(io.Closer); ok {
+			if err := closer.Close(); err != nil {
+				fail(errors.Wrap(err, "closing osquery log"))
+			}
+		}
+	}
+	return firstErr
+}
@@ -0,0 +1,83 @@
+package service
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/WatchBeam/clock"
+	"github.com/kolide/kolide-ose/server/config"
+	"github.com/kolide/kolide-ose/server/datastore/inmem"
+	"github.com/kolide/kolide-ose/server/test"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/net/context"
+)
+
+type closeBuffer struct {
+	bytes.Buffer
+	closed bool
+}
+
+func (b *closeBuffer) Close() error {
+	b.closed = true
+	return nil
+}
+
+func TestShutdown(t *testing.T) {
+	ds, err := inmem.New(config.TestConfig())
+	require.Nil(t, err)
+	mockClock := clock.NewMockClock()
+	statusLog, resultLog := &closeBuffer{}, &closeBuffer{}
+	svc := service{
+		ds:                     ds,
+		config:                 config.TestConfig(),
+		clock:                  mockClock,
+		seenHosts:              newSeenHosts(),
+		campaignStreams:        newCampaignStreams(),
+		osqueryStatusLogWriter: statusLog,
+		osqueryResultLogWriter: resultLog,
+	}
+	ctx := context.Background()
+
+	host := test.NewHost(t, ds, "foo", "", "key", "uuid", mockClock.Now().Add(-time.Hour))
+	_, err = svc.AuthenticateHost(ctx, "key")
+	require.Nil(t, err)
+
+	streamCtx, done, ok := svc.campaignStreams.start(ctx)
+	require.True(t, ok)
+	go func() {
+		<-streamCtx.Done()
+		done()
+	}()
+
+	require.Nil(t, svc.Shutdown(ctx))
+
+	// The stream was closed, and no more may start
+	assert.NotNil(t, streamCtx.Err())
+	_, _, ok = svc.campaignStreams.start(ctx)
+	assert.False(t, ok)
+
+	stored, err := ds.Host(host.ID)
+	require.Nil(t, err)
+	assert.WithinDuration(t, mockClock.Now(), stored.SeenTime, time.Second)
+
+	assert.True(t, statusLog.closed)
+	assert.True(t, resultLog.closed)
+}
+
+func TestShutdownTimeout(t *testing.T) {
+	svc := service{
+		seenHosts:       newSeenHosts(),
+		campaignStreams: newCampaignStreams(),
+	}
+
+	// A stream that never ends holds up the shutdown until its deadline
+	_, _, ok := svc.campaignStreams.start(context.Background())
+	require.True(t, ok)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	assert.Equal(t, context.DeadlineExceeded, errors.Cause(svc.Shutdown(ctx)))
+}
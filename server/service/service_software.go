@@ -0,0 +1,112 @@
+package service
+
+import (
+	"strings"
+
+	"github.com/kolide/kolide-ose/server/kolide"
+	"golang.org/x/net/context"
+)
+
+// hostSoftwareQueryPrefix is appended before the source when a query is
+// provided to collect the software installed on a host.
+const hostSoftwareQueryPrefix = "kolide_software_query_"
+
+// softwareQueries are the queries that collect the software installed on
+// hosts, keyed by the source that the software is stored with. Each query
+// returns a name and version column, and is only sent to hosts on one of its
+// platforms.
+var softwareQueries = map[string]struct {
+	Query     string
+	Platforms []string
+}{
+	"deb_packages": {
+		Query:     "select name, version from deb_packages",
+		Platforms: []string{"ubuntu", "debian"},
+	},
+	"rpm_packages": {
+		Query:     "select name, version from rpm_packages",
+		Platforms: []string{"centos", "rhel", "fedora", "amzn"},
+	},
+	"apps": {
+		Query:     "select bundle_name as name, bundle_short_version as version from apps",
+		Platforms: []string{"darwin"},
+	},
+	"homebrew_packages": {
+		Query:     "select name, version from homebrew_packages",
+		Platforms: []string{"darwin"},
+	},
+	"programs": {
+		Query:     "select name, version from programs",
+		Platforms: []string{"windows"},
+	},
+	"chocolatey_packages": {
+		Query:     "select name, version from chocolatey_packages",
+		Platforms: []string{"windows"},
+	},
+}
+
+// hostSoftwareQueries returns the software queries for the platform of the
+// host.
+func hostSoftwareQueries(host kolide.Host) map[string]string {
+	queries := map[string]string{}
+	for source, query := range softwareQueries {
+		for _, platform := range query.Platforms {
+			if platform == host.Platform {
+				queries[hostSoftwareQueryPrefix+source] = query.Query
+				break
+			}
+		}
+	}
+	return queries
+}
+
+// ingestSoftware replaces the software of the host from the source of the
+// query with the reported rows. Results of a failed query are ignored so
+// that the inventory of the host is not lost.
+func (svc service) ingestSoftware(host kolide.Host, name string, rows []map[string]string, failed bool) error {
+	source := strings.TrimPrefix(name, hostSoftwareQueryPrefix)
+	if _, ok := softwareQueries[source]; !ok {
+		return osqueryError{message: "unknown software query " + source}
+	}
+	if failed {
+		return nil
+	}
+
+	software := []kolide.Software{}
+	for _, row := range rows {
+		if row["name"] == "" {
+			continue
+		}
+		software = append(software, kolide.Software{
+			Name:    row["name"],
+			Version: row["version"],
+			Source:  source,
+		})
+	}
+
+	if err := svc.ds.SaveHostSoftware(host.ID, source, software, svc.clock.Now()); err != nil {
+		return osqueryError{message: "saving host software: " + err.Error()}
+	}
+	if err := svc.matchSoftwareVulnerabilities(software); err != nil {
+		return osqueryError{message: "matching software vulnerabilities: " + err.Error()}
+	}
+	return nil
+}
+
+func (svc service) ListHostSoftware(ctx context.Context, hostID uint, opt kolide.ListOptions) ([]*kolide.HostSoftware, error) {
+	if _, err := svc.ds.Host(hostID); err != nil {
+		return nil, err
+	}
+	return svc.ds.ListHostSoftware(hostID, opt)
+}
+
+func (svc service) SearchSoftware(ctx context.Context, filter kolide.SoftwareFilter, opt kolide.ListOptions) ([]*kolide.SoftwareCount, error) {
+	return svc.ds.SearchSoftware(filter, opt)
+}
+
+func (svc service) ListSoftwareInstalls(ctx context.Context, softwareID uint, opt kolide.ListOptions) ([]*kolide.SoftwareInstall, error) {
+	if _, err := svc.ds.Software(softwareID); err != nil {
+		return nil, err
+	}
+	return svc.ds.ListSoftwareInstalls(softwareID, opt)
+}
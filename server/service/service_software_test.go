@@ -0,0 +1,75 @@
+package service
+
+import (
+	"testing"
+
+	"github.com/kolide/kolide-ose/server/config"
+	hostctx "github.com/kolide/kolide-ose/server/contexts/host"
+	"github.com/kolide/kolide-ose/server/datastore/inmem"
+	"github.com/kolide/kolide-ose/server/kolide"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/net/context"
+)
+
+func TestIngestSoftware(t *testing.T) {
+	ds, err := inmem.New(config.TestConfig())
+	require.Nil(t, err)
+	svc, err := newTestService(ds, nil)
+	require.Nil(t, err)
+	ctx := context.Background()
+
+	nodeKey, err := svc.EnrollAgent(ctx, "", "host123")
+	require.Nil(t, err)
+	host, err := ds.AuthenticateHost(nodeKey)
+	require.Nil(t, err)
+	host.Platform = "ubuntu"
+	require.Nil(t, ds.SaveHost(host))
+	hostCtx := hostctx.NewContext(ctx, *host)
+
+	queries, err := svc.GetDistributedQueries(hostCtx)
+	require.Nil(t, err)
+	debName := hostSoftwareQueryPrefix + "deb_packages"
+	assert.Equal(t, softwareQueries["deb_packages"].Query, queries[debName])
+	// Only the queries for the platform of the host are sent
+	assert.NotContains(t, queries, hostSoftwareQueryPrefix+"apps")
+	assert.NotContains(t, queries, hostSoftwareQueryPrefix+"rpm_packages")
+
+	results := kolide.OsqueryDistributedQueryResults{
+		debName: {
+			{"name": "openssl", "version": "1.0.2g"},
+			{"name": "", "version": "1.0"},
+			{"name": "bash", "version": "4.3"},
+		},
+	}
+	require.Nil(t, svc.SubmitDistributedQueryResults(hostCtx, results, map[string]string{}))
+
+	software, err := svc.ListHostSoftware(ctx, host.ID, kolide.ListOptions{})
+	require.Nil(t, err)
+	require.Len(t, software, 2)
+	assert.Equal(t, "bash", software[0].Name)
+	assert.Equal(t, "deb_packages", software[0].Source)
+	assert.Equal(t, "openssl", software[1].Name)
+
+	// A failed query does not clear the inventory
+	results = kolide.OsqueryDistributedQueryResults{debName: {}}
+	require.Nil(t, svc.SubmitDistributedQueryResults(hostCtx, results, map[string]string{debName: "1"}))
+	software, err = svc.ListHostSoftware(ctx, host.ID, kolide.ListOptions{})
+	require.Nil(t, err)
+	assert.Len(t, software, 2)
+
+	counts, err := svc.SearchSoftware(ctx, kolide.SoftwareFilter{Name: "ssl"}, kolide.ListOptions{})
+	require.Nil(t, err)
+	require.Len(t, counts, 1)
+	assert.Equal(t, uint(1), counts[0].HostCount)
+
+	installs, err := svc.ListSoftwareInstalls(ctx, counts[0].ID, kolide.ListOptions{})
+	require.Nil(t, err)
+	require.Len(t, installs, 1)
+	assert.Equal(t, host.ID, installs[0].HostID)
+
+	_, err = svc.ListHostSoftware(ctx, host.ID+1, kolide.ListOptions{})
+	assert.NotNil(t, err)
+	_, err = svc.ListSoftwareInstalls(ctx, counts[0].ID+1000, kolide.ListOptions{})
+	assert.NotNil(t, err)
+}
@@ -47,7 +47,8 @@ func (svc service) CountHostsInTargets(ctx context.Context, hostIDs []uint, labe
 	for _, host := range hosts {
 		if !hostLookup[host.ID] {
 			hostLookup[host.ID] = true
-			switch host.Status(svc.clock.Now().UTC()) {
+			svc.seenHosts.apply(&host)
+			switch host.Status(svc.clock.Now().UTC(), svc.hostStatusThresholds()) {
 			case kolide.StatusOnline:
 				result.OnlineHosts++
 			case kolide.StatusOffline:
@@ -62,3 +63,98 @@ func (svc service) CountHostsInTargets(ctx context.Context, hostIDs []uint, labe
 
 	return result, nil
 }
+
+func (svc service) ResolveTargets(ctx context.Context, hostIDs []uint, labelIDs []uint, opt kolide.ListOptions) (*kolide.ResolvedTargets, error) {
+	hostIDs, labelIDs = uniqueIDs(hostIDs), uniqueIDs(labelIDs)
+
+	selected, err := svc.ds.CountTargetHosts(hostIDs, nil)
+	if err != nil {
+		return nil, err
+	}
+	if selected != uint(len(hostIDs)) {
+		return nil, newInvalidArgumentError("host_ids", "unknown host")
+	}
+
+	total, err := svc.ds.CountTargetHosts(hostIDs, labelIDs)
+	if err != nil {
+		return nil, err
+	}
+	hosts, err := svc.ds.ListTargetHosts(hostIDs, labelIDs, opt)
+	if err != nil {
+		return nil, err
+	}
+
+	pageIDs := make([]uint, len(hosts))
+	for i, host := range hosts {
+		pageIDs[i] = host.ID
+	}
+	hostLabels, err := svc.ds.TargetLabelIDsForHosts(pageIDs, labelIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	isSelected := map[uint]bool{}
+	for _, id := range hostIDs {
+		isSelected[id] = true
+	}
+	now := svc.clock.Now().UTC()
+	thresholds := svc.hostStatusThresholds()
+	result := &kolide.ResolvedTargets{
+		Hosts:      make([]kolide.ResolvedHost, len(hosts)),
+		TotalHosts: total,
+	}
+	svc.seenHosts.apply(hosts...)
+	for i, host := range hosts {
+		result.Hosts[i] = kolide.ResolvedHost{
+			ID:       host.ID,
+			HostName: host.HostName,
+			Platform: host.Platform,
+			Status:   host.Status(now, thresholds),
+			Selected: isSelected[host.ID],
+			LabelIDs: []uint{},
+		}
+		if ids, ok := hostLabels[host.ID]; ok {
+			result.Hosts[i].LabelIDs = ids
+		}
+	}
+
+	return result, nil
+}
+
+// targetHostsPageSize is the number of hosts read per page when resolving
+// every host in the selected targets.
+const targetHostsPageSize = 1000
+
+// targetHostIDs returns the IDs of every host in the selected targets.
+func (svc service) targetHostIDs(hostIDs []uint, labelIDs []uint) ([]uint, error) {
+	ids := []uint{}
+	for page := uint(0); ; page++ {
+		hosts, err := svc.ds.ListTargetHosts(hostIDs, labelIDs, kolide.ListOptions{
+			Page:    page,
+			PerPage: targetHostsPageSize,
+		})
+		if err != nil {
+			return nil, err
+		}
+		for _, host := range hosts {
+			ids = append(ids, host.ID)
+		}
+		if len(hosts) < targetHostsPageSize {
+			return ids, nil
+		}
+	}
+}
+
+// uniqueIDs returns the IDs without duplicates, in the order they were first
+// given.
+func uniqueIDs(ids []uint) []uint {
+	seen := map[uint]bool{}
+	unique := []uint{}
+	for _, id := range ids {
+		if !seen[id] {
+			seen[id] = true
+			unique = append(unique, id)
+		}
+	}
+	return unique
+}
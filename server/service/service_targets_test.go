@@ -301,3 +301,96 @@ func TestSearchResultsLimit(t *testing.T) {
 	require.Nil(t, err)
 	assert.Len(t, targets.Hosts, 10)
 }
+
+func TestResolveTargets(t *testing.T) {
+	ds, err := inmem.New(config.TestConfig())
+	require.Nil(t, err)
+
+	mockClock := clock.NewMockClock()
+
+	svc, err := newTestServiceWithClock(ds, nil, mockClock)
+	require.Nil(t, err)
+
+	ctx := context.Background()
+
+	var hosts []*kolide.Host
+	for i, name := range []string{"bar.local", "foo.local", "baz.local"} {
+		h, err := ds.NewHost(&kolide.Host{
+			HostName: name,
+			NodeKey:  fmt.Sprint(i),
+			UUID:     fmt.Sprint(i),
+			Platform: "darwin",
+		})
+		require.Nil(t, err)
+		hosts = append(hosts, h)
+	}
+	h1, h2, h3 := hosts[0], hosts[1], hosts[2]
+	require.Nil(t, ds.MarkHostSeen(h1, mockClock.Now()))
+	require.Nil(t, ds.MarkHostSeen(h2, mockClock.Now().Add(-1*time.Hour)))
+	require.Nil(t, ds.MarkHostSeen(h3, mockClock.Now()))
+
+	l1, err := ds.NewLabel(&kolide.Label{
+		Name:  "label foo",
+		Query: "query foo",
+	})
+	require.Nil(t, err)
+
+	l2, err := ds.NewLabel(&kolide.Label{
+		Name:  "label bar",
+		Query: "query bar",
+	})
+	require.Nil(t, err)
+
+	require.Nil(t, ds.RecordLabelQueryExecutions(h2, map[uint]bool{l1.ID: true, l2.ID: true}, mockClock.Now()))
+	require.Nil(t, ds.RecordLabelQueryExecutions(h3, map[uint]bool{l1.ID: true}, mockClock.Now()))
+
+	resolved, err := svc.ResolveTargets(ctx, []uint{h1.ID, h2.ID}, []uint{l1.ID, l2.ID}, kolide.ListOptions{})
+	require.Nil(t, err)
+	assert.Equal(t, uint(3), resolved.TotalHosts)
+	require.Len(t, resolved.Hosts, 3)
+
+	assert.Equal(t, kolide.ResolvedHost{
+		ID:       h1.ID,
+		HostName: "bar.local",
+		Platform: "darwin",
+		Status:   kolide.StatusOnline,
+		Selected: true,
+		LabelIDs: []uint{},
+	}, resolved.Hosts[0])
+	assert.Equal(t, kolide.ResolvedHost{
+		ID:       h3.ID,
+		HostName: "baz.local",
+		Platform: "darwin",
+		Status:   kolide.StatusOnline,
+		LabelIDs: []uint{l1.ID},
+	}, resolved.Hosts[1])
+	assert.Equal(t, kolide.ResolvedHost{
+		ID:       h2.ID,
+		HostName: "foo.local",
+		Platform: "darwin",
+		Status:   kolide.StatusOffline,
+		Selected: true,
+		LabelIDs: []uint{l1.ID, l2.ID},
+	}, resolved.Hosts[2])
+
+	resolved, err = svc.ResolveTargets(ctx, []uint{h1.ID, h2.ID}, []uint{l1.ID, l2.ID}, kolide.ListOptions{Page: 1, PerPage: 2})
+	require.Nil(t, err)
+	assert.Equal(t, uint(3), resolved.TotalHosts)
+	require.Len(t, resolved.Hosts, 1)
+	assert.Equal(t, h2.ID, resolved.Hosts[0].ID)
+
+	resolved, err = svc.ResolveTargets(ctx, []uint{h1.ID}, nil, kolide.ListOptions{Page: 3, PerPage: 2})
+	require.Nil(t, err)
+	assert.Equal(t, uint(1), resolved.TotalHosts)
+	assert.Len(t, resolved.Hosts, 0)
+
+	// Selecting a label twice does not duplicate it
+	resolved, err = svc.ResolveTargets(ctx, nil, []uint{l1.ID, l1.ID}, kolide.ListOptions{})
+	require.Nil(t, err)
+	assert.Equal(t, uint(2), resolved.TotalHosts)
+	require.Len(t, resolved.Hosts, 2)
+	assert.Equal(t, []uint{l1.ID}, resolved.Hosts[1].LabelIDs)
+
+	_, err = svc.ResolveTargets(ctx, []uint{99}, nil, kolide.ListOptions{})
+	assert.NotNil(t, err)
+}
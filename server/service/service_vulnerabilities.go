@@ -0,0 +1,89 @@
+package service
+
+import (
+	"io"
+
+	"github.com/kolide/kolide-ose/server/kolide"
+	"github.com/pkg/errors"
+	"golang.org/x/net/context"
+)
+
+func (svc service) ImportVulnerabilities(ctx context.Context, format string, feed io.Reader) (int, error) {
+	if format != kolide.VulnerabilityFeedNVD && format != kolide.VulnerabilityFeedCSV {
+		return 0, newInvalidArgumentError("format", "must be one of nvd or csv")
+	}
+	vulns, err := kolide.ParseVulnerabilityFeed(format, feed)
+	if err != nil {
+		return 0, newInvalidArgumentError("feed", err.Error())
+	}
+	if err := svc.ds.ImportVulnerabilities(vulns); err != nil {
+		return 0, err
+	}
+	// The feed changed, so all of the inventory is matched again. Matching
+	// takes too long for the request, and is left to a background job
+	if err := svc.ds.TriggerJob(kolide.MatchVulnerabilitiesJob, svc.clock.Now()); err != nil {
+		return 0, err
+	}
+	return len(vulns), nil
+}
+
+func (svc service) MatchVulnerabilities(ctx context.Context) error {
+	criteria, err := svc.ds.ListVulnerableSoftware(nil)
+	if err != nil {
+		return errors.Wrap(err, "listing vulnerable software")
+	}
+	all, err := svc.ds.ListAllSoftware()
+	if err != nil {
+		return errors.Wrap(err, "listing software")
+	}
+	software := []kolide.Software{}
+	for _, s := range all {
+		software = append(software, *s)
+	}
+	matches := kolide.MatchVulnerabilities(software, criteria)
+	return svc.ds.ReplaceSoftwareVulnerabilities(matches)
+}
+
+// matchSoftwareVulnerabilities stores the vulnerabilities of software that a
+// host reported. Matches for software that is already in the inventory do
+// not change until the feed does, so they are only ever added.
+func (svc service) matchSoftwareVulnerabilities(software []kolide.Software) error {
+	if len(software) == 0 {
+		return nil
+	}
+	products := []string{}
+	seen := map[string]bool{}
+	for _, s := range software {
+		product := kolide.NormalizeSoftwareName(s.Name)
+		if !seen[product] {
+			seen[product] = true
+			products = append(products, product)
+		}
+	}
+	criteria, err := svc.ds.ListVulnerableSoftware(products)
+	if err != nil {
+		return errors.Wrap(err, "listing vulnerable software")
+	}
+	if len(criteria) == 0 {
+		return nil
+	}
+	return svc.ds.AddSoftwareVulnerabilities(kolide.MatchVulnerabilities(software, criteria))
+}
+
+func (svc service) ListVulnerabilities(ctx context.Context, opt kolide.ListOptions) ([]*kolide.VulnerabilityCount, error) {
+	return svc.ds.ListVulnerabilities(opt)
+}
+
+func (svc service) ListVulnerableHosts(ctx context.Context, cve string, opt kolide.ListOptions) ([]*kolide.VulnerableHost, error) {
+	if _, err := svc.ds.Vulnerability(cve); err != nil {
+		return nil, err
+	}
+	return svc.ds.ListVulnerableHosts(cve, opt)
+}
+
+func (svc service) ListHostVulnerabilities(ctx context.Context, hostID uint, opt kolide.ListOptions) ([]*kolide.HostVulnerability, error) {
+	if _, err := svc.ds.Host(hostID); err != nil {
+		return nil, err
+	}
+	return svc.ds.ListHostVulnerabilities(hostID, opt)
+}
@@ -0,0 +1,102 @@
+package service
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/kolide/kolide-ose/server/config"
+	hostctx "github.com/kolide/kolide-ose/server/contexts/host"
+	"github.com/kolide/kolide-ose/server/datastore/inmem"
+	"github.com/kolide/kolide-ose/server/kolide"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/net/context"
+)
+
+func TestVulnerabilityMatching(t *testing.T) {
+	ds, err := inmem.New(config.TestConfig())
+	require.Nil(t, err)
+	svc, err := newTestService(ds, nil)
+	require.Nil(t, err)
+	ctx := context.Background()
+
+	nodeKey, err := svc.EnrollAgent(ctx, "", "host123")
+	require.Nil(t, err)
+	host, err := ds.AuthenticateHost(nodeKey)
+	require.Nil(t, err)
+	host.Platform = "ubuntu"
+	require.Nil(t, ds.SaveHost(host))
+	hostCtx := hostctx.NewContext(ctx, *host)
+
+	debName := hostSoftwareQueryPrefix + "deb_packages"
+	results := kolide.OsqueryDistributedQueryResults{
+		debName: {
+			{"name": "openssl", "version": "1.0.2g-1ubuntu4.6"},
+			{"name": "curl", "version": "7.47.0-1ubuntu2.2"},
+		},
+	}
+	require.Nil(t, svc.SubmitDistributedQueryResults(hostCtx, results, map[string]string{}))
+
+	// Importing the feed triggers the job that matches the inventory
+	now := time.Now()
+	require.Nil(t, ds.RegisterJob(kolide.MatchVulnerabilitiesJob, time.Hour))
+	locked, err := ds.LockJob(kolide.MatchVulnerabilitiesJob, "server", time.Hour, now)
+	require.Nil(t, err)
+	require.True(t, locked)
+	feed := strings.NewReader(`cpe,cve
+cpe:2.3:a:openssl:openssl:1.0.2g:*:*:*:*:*:*:*,CVE-2016-2107
+cpe:2.3:a:haxx:curl:7.50.0:*:*:*:*:*:*:*,CVE-2016-8615
+`)
+	imported, err := svc.ImportVulnerabilities(ctx, kolide.VulnerabilityFeedCSV, feed)
+	require.Nil(t, err)
+	assert.Equal(t, 2, imported)
+	locked, err = ds.LockJob(kolide.MatchVulnerabilitiesJob, "server", time.Hour, time.Now())
+	require.Nil(t, err)
+	assert.True(t, locked)
+
+	vulns, err := svc.ListHostVulnerabilities(ctx, host.ID, kolide.ListOptions{})
+	require.Nil(t, err)
+	assert.Len(t, vulns, 0)
+	require.Nil(t, svc.MatchVulnerabilities(ctx))
+
+	vulns, err = svc.ListHostVulnerabilities(ctx, host.ID, kolide.ListOptions{})
+	require.Nil(t, err)
+	require.Len(t, vulns, 1)
+	assert.Equal(t, "CVE-2016-2107", vulns[0].CVE)
+	assert.Equal(t, "openssl", vulns[0].Software.Name)
+
+	// A change of the inventory is matched when it is reported
+	results = kolide.OsqueryDistributedQueryResults{
+		debName: {
+			{"name": "openssl", "version": "1.0.2g-1ubuntu4.6"},
+			{"name": "curl", "version": "7.50.0-1"},
+		},
+	}
+	require.Nil(t, svc.SubmitDistributedQueryResults(hostCtx, results, map[string]string{}))
+	hosts, err := svc.ListVulnerableHosts(ctx, "CVE-2016-8615", kolide.ListOptions{})
+	require.Nil(t, err)
+	require.Len(t, hosts, 1)
+	assert.Equal(t, host.ID, hosts[0].HostID)
+	assert.Equal(t, "7.50.0-1", hosts[0].Software.Version)
+
+	counts, err := svc.ListVulnerabilities(ctx, kolide.ListOptions{})
+	require.Nil(t, err)
+	assert.Len(t, counts, 2)
+
+	// A new feed replaces the matches
+	feed = strings.NewReader(`cpe:2.3:a:haxx:curl:7.49.0:*:*:*:*:*:*:*,CVE-2016-8615`)
+	_, err = svc.ImportVulnerabilities(ctx, kolide.VulnerabilityFeedCSV, feed)
+	require.Nil(t, err)
+	require.Nil(t, svc.MatchVulnerabilities(ctx))
+	hosts, err = svc.ListVulnerableHosts(ctx, "CVE-2016-8615", kolide.ListOptions{})
+	require.Nil(t, err)
+	assert.Len(t, hosts, 0)
+
+	_, err = svc.ListVulnerableHosts(ctx, "CVE-1999-0001", kolide.ListOptions{})
+	assert.NotNil(t, err)
+	_, err = svc.ImportVulnerabilities(ctx, "xml", strings.NewReader(""))
+	assert.IsType(t, &invalidArgumentError{}, err)
+	_, err = svc.ImportVulnerabilities(ctx, kolide.VulnerabilityFeedNVD, strings.NewReader("not json"))
+	assert.IsType(t, &invalidArgumentError{}, err)
+}
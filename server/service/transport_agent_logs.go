@@ -0,0 +1,40 @@
+package service
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"golang.org/x/net/context"
+)
+
+func decodeListHostAgentLogsRequest(ctx context.Context, r *http.Request) (interface{}, error) {
+	id, err := idFromRequest(r, "id")
+	if err != nil {
+		return nil, err
+	}
+	opt, err := listOptionsFromRequest(r)
+	if err != nil {
+		return nil, err
+	}
+	return listHostAgentLogsRequest{ID: id, ListOptions: opt}, nil
+}
+
+// defaultAgentLogSummaries is the number of agent log summaries returned
+// when no limit is provided
+const defaultAgentLogSummaries = 20
+
+func decodeListAgentLogSummariesRequest(ctx context.Context, r *http.Request) (interface{}, error) {
+	req := listAgentLogSummariesRequest{
+		Severity: r.URL.Query().Get("severity"),
+		Limit:    defaultAgentLogSummaries,
+	}
+	if limit := r.URL.Query().Get("limit"); limit != "" {
+		l, err := strconv.ParseUint(limit, 10, 32)
+		if err != nil || l == 0 {
+			return nil, errors.New("limit must be a positive integer")
+		}
+		req.Limit = uint(l)
+	}
+	return req, nil
+}
@@ -0,0 +1,43 @@
+package service
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gorilla/mux"
+	"github.com/kolide/kolide-ose/server/kolide"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/net/context"
+)
+
+func TestDecodeListHostAgentLogsRequest(t *testing.T) {
+	router := mux.NewRouter()
+	router.HandleFunc("/api/v1/kolide/hosts/{id}/agent_logs", func(writer http.ResponseWriter, request *http.Request) {
+		r, err := decodeListHostAgentLogsRequest(context.Background(), request)
+		require.Nil(t, err)
+
+		params := r.(listHostAgentLogsRequest)
+		assert.Equal(t, uint(1), params.ID)
+		assert.Equal(t, kolide.ListOptions{Page: 2, PerPage: 10}, params.ListOptions)
+	}).Methods("GET")
+
+	router.ServeHTTP(
+		httptest.NewRecorder(),
+		httptest.NewRequest("GET", "/api/v1/kolide/hosts/1/agent_logs?page=2&per_page=10", nil),
+	)
+}
+
+func TestDecodeListAgentLogSummariesRequest(t *testing.T) {
+	r, err := decodeListAgentLogSummariesRequest(context.Background(), httptest.NewRequest("GET", "/api/v1/kolide/agent_logs/summaries", nil))
+	require.Nil(t, err)
+	assert.Equal(t, listAgentLogSummariesRequest{Limit: defaultAgentLogSummaries}, r)
+
+	r, err = decodeListAgentLogSummariesRequest(context.Background(), httptest.NewRequest("GET", "/api/v1/kolide/agent_logs/summaries?severity=error&limit=5", nil))
+	require.Nil(t, err)
+	assert.Equal(t, listAgentLogSummariesRequest{Severity: "error", Limit: 5}, r)
+
+	_, err = decodeListAgentLogSummariesRequest(context.Background(), httptest.NewRequest("GET", "/api/v1/kolide/agent_logs/summaries?limit=0", nil))
+	assert.NotNil(t, err)
+}
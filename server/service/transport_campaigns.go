@@ -3,7 +3,11 @@ package service
 import (
 	"encoding/json"
 	"net/http"
+	"strconv"
+	"strings"
 
+	"github.com/kolide/kolide-ose/server/kolide"
+	"github.com/pkg/errors"
 	"golang.org/x/net/context"
 )
 
@@ -14,3 +18,45 @@ func decodeCreateDistributedQueryCampaignRequest(ctx context.Context, r *http.Re
 	}
 	return req, nil
 }
+
+// decodeCampaignAggregateOptions reads the aggregation options for a campaign
+// results stream from the query string of the websocket request. The
+// supported parameters are group_by (a comma separated list of columns), top
+// (the number of values to report) and rows (set to false to stream only the
+// aggregates).
+func decodeCampaignAggregateOptions(r *http.Request) (kolide.CampaignAggregateOptions, error) {
+	var opts kolide.CampaignAggregateOptions
+	query := r.URL.Query()
+
+	if groupBy := query.Get("group_by"); groupBy != "" {
+		for _, col := range strings.Split(groupBy, ",") {
+			col = strings.TrimSpace(col)
+			if col == "" {
+				return opts, errors.New("group_by contains an empty column name")
+			}
+			opts.GroupBy = append(opts.GroupBy, col)
+		}
+	}
+
+	if top := query.Get("top"); top != "" {
+		topN, err := strconv.Atoi(top)
+		if err != nil || topN < 1 {
+			return opts, errors.Errorf("invalid top value %q", top)
+		}
+		opts.TopN = topN
+	}
+
+	if rows := query.Get("rows"); rows != "" {
+		includeRows, err := strconv.ParseBool(rows)
+		if err != nil {
+			return opts, errors.Errorf("invalid rows value %q", rows)
+		}
+		opts.OmitRows = !includeRows
+	}
+
+	if opts.OmitRows && !opts.Enabled() {
+		return opts, errors.New("rows may only be omitted when group_by is set")
+	}
+
+	return opts, nil
+}
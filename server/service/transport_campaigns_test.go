@@ -0,0 +1,43 @@
+package service
+
+import (
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDecodeCampaignAggregateOptions(t *testing.T) {
+	var testCases = []struct {
+		url      string
+		groupBy  []string
+		topN     int
+		omitRows bool
+		wantErr  bool
+	}{
+		{url: "/api/v1/kolide/results/1"},
+		{url: "/api/v1/kolide/results/1?group_by=name", groupBy: []string{"name"}},
+		{url: "/api/v1/kolide/results/1?group_by=name,%20version&top=5&rows=false", groupBy: []string{"name", "version"}, topN: 5, omitRows: true},
+		{url: "/api/v1/kolide/results/1?group_by=name&rows=true", groupBy: []string{"name"}},
+		{url: "/api/v1/kolide/results/1?group_by=name,,version", wantErr: true},
+		{url: "/api/v1/kolide/results/1?group_by=name&top=0", wantErr: true},
+		{url: "/api/v1/kolide/results/1?group_by=name&top=foo", wantErr: true},
+		{url: "/api/v1/kolide/results/1?group_by=name&rows=maybe", wantErr: true},
+		{url: "/api/v1/kolide/results/1?rows=false", wantErr: true},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.url, func(t *testing.T) {
+			opts, err := decodeCampaignAggregateOptions(httptest.NewRequest("GET", tt.url, nil))
+			if tt.wantErr {
+				assert.NotNil(t, err)
+				return
+			}
+			require.Nil(t, err)
+			assert.Equal(t, tt.groupBy, opts.GroupBy)
+			assert.Equal(t, tt.topN, opts.TopN)
+			assert.Equal(t, tt.omitRows, opts.OmitRows)
+		})
+	}
+}
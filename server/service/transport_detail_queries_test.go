@@ -0,0 +1,41 @@
+package service
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gorilla/mux"
+	"github.com/kolide/kolide-ose/server/kolide"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/net/context"
+)
+
+func TestDecodeModifyDetailQueryRequest(t *testing.T) {
+	router := mux.NewRouter()
+	router.HandleFunc("/api/v1/kolide/detail_queries/{id}", func(writer http.ResponseWriter, request *http.Request) {
+		r, err := decodeModifyDetailQueryRequest(context.Background(), request)
+		require.Nil(t, err)
+
+		params := r.(modifyDetailQueryRequest)
+		assert.Equal(t, uint(1), params.ID)
+		assert.Equal(t, "ubuntu", *params.payload.Platform)
+		assert.Equal(t, uint(600), *params.payload.Interval)
+		assert.Equal(t, kolide.DetailQueryColumns{
+			{Name: "version", Type: kolide.HostAttributeString},
+		}, *params.payload.Columns)
+		assert.Nil(t, params.payload.Name)
+	}).Methods("PATCH")
+
+	body := bytes.NewBufferString(`{
+		"platform": "ubuntu",
+		"interval": 600,
+		"columns": [{"name": "version", "type": "string"}]
+	}`)
+	router.ServeHTTP(
+		httptest.NewRecorder(),
+		httptest.NewRequest("PATCH", "/api/v1/kolide/detail_queries/1", body),
+	)
+}
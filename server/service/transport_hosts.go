@@ -1,8 +1,12 @@
 package service
 
 import (
+	"errors"
 	"net/http"
+	"strconv"
+	"time"
 
+	"github.com/kolide/kolide-ose/server/kolide"
 	"golang.org/x/net/context"
 )
 
@@ -14,6 +18,14 @@ func decodeGetHostRequest(ctx context.Context, r *http.Request) (interface{}, er
 	return getHostRequest{ID: id}, nil
 }
 
+func decodeGetHostConfigRequest(ctx context.Context, r *http.Request) (interface{}, error) {
+	id, err := idFromRequest(r, "id")
+	if err != nil {
+		return nil, err
+	}
+	return getHostConfigRequest{ID: id}, nil
+}
+
 func decodeDeleteHostRequest(ctx context.Context, r *http.Request) (interface{}, error) {
 	id, err := idFromRequest(r, "id")
 	if err != nil {
@@ -27,5 +39,66 @@ func decodeListHostsRequest(ctx context.Context, r *http.Request) (interface{},
 	if err != nil {
 		return nil, err
 	}
-	return listHostsRequest{ListOptions: opt}, nil
+	filter, err := hostFilterFromRequest(r)
+	if err != nil {
+		return nil, err
+	}
+	return listHostsRequest{ListOptions: opt, Filter: filter}, nil
+}
+
+// hostFilterFromRequest reads the host filter from the query parameters of a
+// request. Seen times are in RFC 3339 format, and each attribute parameter is
+// an attribute filter such as disk_encryption.encrypted=true.
+func hostFilterFromRequest(r *http.Request) (kolide.HostFilter, error) {
+	query := r.URL.Query()
+	filter := kolide.HostFilter{
+		Status:         query.Get("status"),
+		Platform:       query.Get("platform"),
+		OSVersion:      query.Get("os_version"),
+		OsqueryVersion: query.Get("osquery_version"),
+		Query:          query.Get("query"),
+	}
+
+	if labelID := query.Get("label_id"); labelID != "" {
+		id, err := strconv.ParseUint(labelID, 10, 32)
+		if err != nil {
+			return kolide.HostFilter{}, errors.New("non-int label_id value")
+		}
+		filter.LabelID = uint(id)
+	}
+
+	var err error
+	if seenAfter := query.Get("seen_after"); seenAfter != "" {
+		filter.SeenAfter, err = time.Parse(time.RFC3339, seenAfter)
+		if err != nil {
+			return kolide.HostFilter{}, errors.New("invalid seen_after value")
+		}
+	}
+	if seenBefore := query.Get("seen_before"); seenBefore != "" {
+		filter.SeenBefore, err = time.Parse(time.RFC3339, seenBefore)
+		if err != nil {
+			return kolide.HostFilter{}, errors.New("invalid seen_before value")
+		}
+	}
+
+	for _, attribute := range query["attribute"] {
+		f, err := kolide.ParseHostAttributeFilter(attribute)
+		if err != nil {
+			return kolide.HostFilter{}, err
+		}
+		filter.Attributes = append(filter.Attributes, f)
+	}
+	return filter, nil
+}
+
+func decodeGetHostBreakdownRequest(ctx context.Context, r *http.Request) (interface{}, error) {
+	var req getHostBreakdownRequest
+	if labelID := r.URL.Query().Get("label_id"); labelID != "" {
+		id, err := strconv.ParseUint(labelID, 10, 32)
+		if err != nil {
+			return nil, errors.New("non-int label_id value")
+		}
+		req.LabelID = uint(id)
+	}
+	return req, nil
 }
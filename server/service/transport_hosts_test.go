@@ -0,0 +1,75 @@
+package service
+
+import (
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/kolide/kolide-ose/server/kolide"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/net/context"
+)
+
+func TestDecodeListHostsRequest(t *testing.T) {
+	params := url.Values{}
+	params.Add("page", "1")
+	params.Add("attribute", "disk_encryption.encrypted=true")
+	params.Add("attribute", "disk.free_gb>=10")
+	r, err := decodeListHostsRequest(context.Background(), httptest.NewRequest("GET", "/api/v1/kolide/hosts?"+params.Encode(), nil))
+	require.Nil(t, err)
+
+	req := r.(listHostsRequest)
+	assert.Equal(t, uint(1), req.ListOptions.Page)
+	assert.Equal(t, []kolide.HostAttributeFilter{
+		{Key: "disk_encryption.encrypted", Operator: "=", Value: "true"},
+		{Key: "disk.free_gb", Operator: ">=", Value: "10"},
+	}, req.Filter.Attributes)
+
+	_, err = decodeListHostsRequest(context.Background(), httptest.NewRequest("GET", "/api/v1/kolide/hosts?attribute=nope", nil))
+	assert.NotNil(t, err)
+}
+
+func TestDecodeListHostsRequestFilter(t *testing.T) {
+	params := url.Values{}
+	params.Add("status", "offline")
+	params.Add("platform", "ubuntu")
+	params.Add("os_version", "Ubuntu 16.04")
+	params.Add("osquery_version", "2.2.1")
+	params.Add("label_id", "4")
+	params.Add("seen_after", "2017-02-01T00:00:00Z")
+	params.Add("seen_before", "2017-02-08T12:00:00Z")
+	params.Add("query", "web")
+	r, err := decodeListHostsRequest(context.Background(), httptest.NewRequest("GET", "/api/v1/kolide/hosts?"+params.Encode(), nil))
+	require.Nil(t, err)
+
+	assert.Equal(t, kolide.HostFilter{
+		Status:         "offline",
+		Platform:       "ubuntu",
+		OSVersion:      "Ubuntu 16.04",
+		OsqueryVersion: "2.2.1",
+		LabelID:        4,
+		SeenAfter:      time.Date(2017, 2, 1, 0, 0, 0, 0, time.UTC),
+		SeenBefore:     time.Date(2017, 2, 8, 12, 0, 0, 0, time.UTC),
+		Query:          "web",
+	}, r.(listHostsRequest).Filter)
+
+	_, err = decodeListHostsRequest(context.Background(), httptest.NewRequest("GET", "/api/v1/kolide/hosts?label_id=web", nil))
+	assert.NotNil(t, err)
+	_, err = decodeListHostsRequest(context.Background(), httptest.NewRequest("GET", "/api/v1/kolide/hosts?seen_after=yesterday", nil))
+	assert.NotNil(t, err)
+}
+
+func TestDecodeGetHostBreakdownRequest(t *testing.T) {
+	r, err := decodeGetHostBreakdownRequest(context.Background(), httptest.NewRequest("GET", "/api/v1/kolide/host_breakdown", nil))
+	require.Nil(t, err)
+	assert.Equal(t, uint(0), r.(getHostBreakdownRequest).LabelID)
+
+	r, err = decodeGetHostBreakdownRequest(context.Background(), httptest.NewRequest("GET", "/api/v1/kolide/host_breakdown?label_id=3", nil))
+	require.Nil(t, err)
+	assert.Equal(t, uint(3), r.(getHostBreakdownRequest).LabelID)
+
+	_, err = decodeGetHostBreakdownRequest(context.Background(), httptest.NewRequest("GET", "/api/v1/kolide/host_breakdown?label_id=all", nil))
+	assert.NotNil(t, err)
+}
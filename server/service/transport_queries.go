@@ -2,7 +2,12 @@ package service
 
 import (
 	"encoding/json"
+	"errors"
 	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/kolide/kolide-ose/server/kolide"
 
 	"golang.org/x/net/context"
 )
@@ -61,5 +66,42 @@ func decodeListQueriesRequest(ctx context.Context, r *http.Request) (interface{}
 	if err != nil {
 		return nil, err
 	}
-	return listQueriesRequest{ListOptions: opt}, nil
+	filter, err := queryFilterFromRequest(r)
+	if err != nil {
+		return nil, err
+	}
+	return listQueriesRequest{ListOptions: opt, Filter: filter}, nil
+}
+
+// queryFilterFromRequest parses the query filter from the request
+// parameters. Tags are given as a comma separated list.
+func queryFilterFromRequest(r *http.Request) (kolide.QueryFilter, error) {
+	params := r.URL.Query()
+	filter := kolide.QueryFilter{
+		Category: params.Get("category"),
+		Search:   params.Get("search"),
+	}
+
+	if saved := params.Get("saved"); saved != "" {
+		b, err := strconv.ParseBool(saved)
+		if err != nil {
+			return kolide.QueryFilter{}, errors.New("non-bool saved value")
+		}
+		filter.Saved = &b
+	}
+
+	if authorID := params.Get("author_id"); authorID != "" {
+		id, err := strconv.ParseUint(authorID, 10, 32)
+		if err != nil {
+			return kolide.QueryFilter{}, errors.New("non-int author_id value")
+		}
+		uid := uint(id)
+		filter.AuthorID = &uid
+	}
+
+	if tags := params.Get("tags"); tags != "" {
+		filter.Tags = strings.Split(tags, ",")
+	}
+
+	return filter, nil
 }
@@ -7,7 +7,9 @@ import (
 	"testing"
 
 	"github.com/gorilla/mux"
+	"github.com/kolide/kolide-ose/server/kolide"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 
 	"golang.org/x/net/context"
 )
@@ -88,3 +90,50 @@ func TestDecodeGetQueryRequest(t *testing.T) {
 		httptest.NewRequest("GET", "/api/v1/kolide/queries/1", nil),
 	)
 }
+
+func TestDecodeListQueriesRequest(t *testing.T) {
+	saved := false
+	author := uint(3)
+
+	var filterTests = []struct {
+		url       string
+		filter    kolide.QueryFilter
+		shouldErr bool
+	}{
+		{
+			url:    "/api/v1/kolide/queries",
+			filter: kolide.QueryFilter{},
+		},
+		{
+			url: "/api/v1/kolide/queries?saved=false&author_id=3&tags=linux,network&category=security&search=open+ports",
+			filter: kolide.QueryFilter{
+				Saved:    &saved,
+				AuthorID: &author,
+				Tags:     []string{"linux", "network"},
+				Category: "security",
+				Search:   "open ports",
+			},
+		},
+		{
+			url:       "/api/v1/kolide/queries?saved=maybe",
+			shouldErr: true,
+		},
+		{
+			url:       "/api/v1/kolide/queries?author_id=-1",
+			shouldErr: true,
+		},
+	}
+
+	for _, tt := range filterTests {
+		t.Run(tt.url, func(t *testing.T) {
+			r, err := decodeListQueriesRequest(context.Background(), httptest.NewRequest("GET", tt.url, nil))
+			if tt.shouldErr {
+				assert.NotNil(t, err)
+				return
+			}
+
+			require.Nil(t, err)
+			assert.Equal(t, tt.filter, r.(listQueriesRequest).Filter)
+		})
+	}
+}
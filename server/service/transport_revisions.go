@@ -0,0 +1,63 @@
+package service
+
+import (
+	"net/http"
+	"strconv"
+
+	kithttp "github.com/go-kit/kit/transport/http"
+	"github.com/pkg/errors"
+	"golang.org/x/net/context"
+)
+
+// makeDecodeListRevisionsRequest returns a decoder for the revisions of the
+// object type served by a route, with the object ID taken from the path.
+func makeDecodeListRevisionsRequest(objectType string) kithttp.DecodeRequestFunc {
+	return func(ctx context.Context, r *http.Request) (interface{}, error) {
+		id, err := idFromRequest(r, "id")
+		if err != nil {
+			return nil, err
+		}
+		opt, err := listOptionsFromRequest(r)
+		if err != nil {
+			return nil, err
+		}
+		return listRevisionsRequest{
+			ObjectType:  objectType,
+			ObjectID:    id,
+			ListOptions: opt,
+		}, nil
+	}
+}
+
+func decodeDiffRevisionsRequest(ctx context.Context, r *http.Request) (interface{}, error) {
+	from, err := revisionIDFromQuery(r, "from")
+	if err != nil {
+		return nil, err
+	}
+	to, err := revisionIDFromQuery(r, "to")
+	if err != nil {
+		return nil, err
+	}
+	return diffRevisionsRequest{FromID: from, ToID: to}, nil
+}
+
+// revisionIDFromQuery parses a required revision ID from the query string.
+func revisionIDFromQuery(r *http.Request, name string) (uint, error) {
+	value := r.URL.Query().Get(name)
+	if value == "" {
+		return 0, errors.Errorf("missing %s revision", name)
+	}
+	id, err := strconv.ParseUint(value, 10, 0)
+	if err != nil {
+		return 0, errors.Errorf("invalid %s value %q", name, value)
+	}
+	return uint(id), nil
+}
+
+func decodeRestoreRevisionRequest(ctx context.Context, r *http.Request) (interface{}, error) {
+	id, err := idFromRequest(r, "id")
+	if err != nil {
+		return nil, err
+	}
+	return restoreRevisionRequest{ID: id}, nil
+}
@@ -0,0 +1,60 @@
+package service
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gorilla/mux"
+	"github.com/kolide/kolide-ose/server/kolide"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/net/context"
+)
+
+func TestDecodeListRevisionsRequest(t *testing.T) {
+	router := mux.NewRouter()
+	router.HandleFunc("/api/v1/kolide/packs/{id}/revisions", func(writer http.ResponseWriter, request *http.Request) {
+		r, err := makeDecodeListRevisionsRequest(kolide.RevisionObjectPack)(context.Background(), request)
+		require.Nil(t, err)
+
+		params := r.(listRevisionsRequest)
+		assert.Equal(t, kolide.RevisionObjectPack, params.ObjectType)
+		assert.Equal(t, uint(3), params.ObjectID)
+		assert.Equal(t, uint(2), params.ListOptions.Page)
+		assert.Equal(t, uint(5), params.ListOptions.PerPage)
+	}).Methods("GET")
+
+	router.ServeHTTP(
+		httptest.NewRecorder(),
+		httptest.NewRequest("GET", "/api/v1/kolide/packs/3/revisions?page=2&per_page=5", nil),
+	)
+}
+
+func TestDecodeDiffRevisionsRequest(t *testing.T) {
+	var decodeTests = []struct {
+		query string
+		from  uint
+		to    uint
+		valid bool
+	}{
+		{"from=1&to=2", 1, 2, true},
+		{"to=2", 0, 0, false},
+		{"from=1", 0, 0, false},
+		{"from=a&to=2", 0, 0, false},
+		{"from=1&to=-2", 0, 0, false},
+	}
+
+	for _, tt := range decodeTests {
+		req := httptest.NewRequest("GET", "/api/v1/kolide/revisions/diff?"+tt.query, nil)
+		r, err := decodeDiffRevisionsRequest(context.Background(), req)
+		if !tt.valid {
+			assert.NotNil(t, err, tt.query)
+			continue
+		}
+		require.Nil(t, err, tt.query)
+		params := r.(diffRevisionsRequest)
+		assert.Equal(t, tt.from, params.FromID)
+		assert.Equal(t, tt.to, params.ToID)
+	}
+}
@@ -61,3 +61,13 @@ func decodeGetScheduledQueriesInPackRequest(ctx context.Context, r *http.Request
 	req.ID = id
 	return req, nil
 }
+
+func decodeGetScheduledQueryStatsInPackRequest(ctx context.Context, r *http.Request) (interface{}, error) {
+	id, err := idFromRequest(r, "id")
+	if err != nil {
+		return nil, err
+	}
+	var req getScheduledQueryStatsInPackRequest
+	req.ID = id
+	return req, nil
+}
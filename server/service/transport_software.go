@@ -0,0 +1,46 @@
+package service
+
+import (
+	"net/http"
+
+	"github.com/kolide/kolide-ose/server/kolide"
+	"golang.org/x/net/context"
+)
+
+func decodeListHostSoftwareRequest(ctx context.Context, r *http.Request) (interface{}, error) {
+	id, err := idFromRequest(r, "id")
+	if err != nil {
+		return nil, err
+	}
+	opt, err := listOptionsFromRequest(r)
+	if err != nil {
+		return nil, err
+	}
+	return listHostSoftwareRequest{HostID: id, ListOptions: opt}, nil
+}
+
+func decodeSearchSoftwareRequest(ctx context.Context, r *http.Request) (interface{}, error) {
+	opt, err := listOptionsFromRequest(r)
+	if err != nil {
+		return nil, err
+	}
+	query := r.URL.Query()
+	filter := kolide.SoftwareFilter{
+		Name:    query.Get("name"),
+		Version: query.Get("version"),
+		Source:  query.Get("source"),
+	}
+	return searchSoftwareRequest{Filter: filter, ListOptions: opt}, nil
+}
+
+func decodeListSoftwareInstallsRequest(ctx context.Context, r *http.Request) (interface{}, error) {
+	id, err := idFromRequest(r, "id")
+	if err != nil {
+		return nil, err
+	}
+	opt, err := listOptionsFromRequest(r)
+	if err != nil {
+		return nil, err
+	}
+	return listSoftwareInstallsRequest{SoftwareID: id, ListOptions: opt}, nil
+}
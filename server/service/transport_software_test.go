@@ -0,0 +1,50 @@
+package service
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gorilla/mux"
+	"github.com/kolide/kolide-ose/server/kolide"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/net/context"
+)
+
+func TestDecodeListHostSoftwareRequest(t *testing.T) {
+	router := mux.NewRouter()
+	router.HandleFunc("/api/v1/kolide/hosts/{id}/software", func(writer http.ResponseWriter, request *http.Request) {
+		r, err := decodeListHostSoftwareRequest(context.Background(), request)
+		require.Nil(t, err)
+
+		params := r.(listHostSoftwareRequest)
+		assert.Equal(t, uint(1), params.HostID)
+		assert.Equal(t, uint(2), params.ListOptions.Page)
+	}).Methods("GET")
+
+	router.ServeHTTP(
+		httptest.NewRecorder(),
+		httptest.NewRequest("GET", "/api/v1/kolide/hosts/1/software?page=2", nil),
+	)
+}
+
+func TestDecodeSearchSoftwareRequest(t *testing.T) {
+	router := mux.NewRouter()
+	router.HandleFunc("/api/v1/kolide/software", func(writer http.ResponseWriter, request *http.Request) {
+		r, err := decodeSearchSoftwareRequest(context.Background(), request)
+		require.Nil(t, err)
+
+		params := r.(searchSoftwareRequest)
+		assert.Equal(t, kolide.SoftwareFilter{
+			Name:    "openssl",
+			Version: "1.0.2g",
+			Source:  "deb_packages",
+		}, params.Filter)
+	}).Methods("GET")
+
+	router.ServeHTTP(
+		httptest.NewRecorder(),
+		httptest.NewRequest("GET", "/api/v1/kolide/software?name=openssl&version=1.0.2g&source=deb_packages", nil),
+	)
+}
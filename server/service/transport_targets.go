@@ -15,3 +15,18 @@ func decodeSearchTargetsRequest(ctx context.Context, r *http.Request) (interface
 
 	return req, nil
 }
+
+func decodeResolveTargetsRequest(ctx context.Context, r *http.Request) (interface{}, error) {
+	opt, err := listOptionsFromRequest(r)
+	if err != nil {
+		return nil, err
+	}
+
+	var req resolveTargetsRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		return nil, err
+	}
+	req.ListOptions = opt
+
+	return req, nil
+}
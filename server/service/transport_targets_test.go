@@ -44,3 +44,30 @@ func TestDecodeSearchTargetsRequest(t *testing.T) {
 		httptest.NewRequest("POST", "/api/v1/kolide/targets", &body),
 	)
 }
+
+func TestDecodeResolveTargetsRequest(t *testing.T) {
+	router := mux.NewRouter()
+	router.HandleFunc("/api/v1/kolide/targets/resolve", func(writer http.ResponseWriter, request *http.Request) {
+		r, err := decodeResolveTargetsRequest(context.Background(), request)
+		assert.Nil(t, err)
+
+		params := r.(resolveTargetsRequest)
+		assert.Equal(t, uint(2), params.ListOptions.Page)
+		assert.Equal(t, uint(50), params.ListOptions.PerPage)
+		assert.Equal(t, []uint{1, 2, 3}, params.Selected.Hosts)
+		assert.Equal(t, []uint{4}, params.Selected.Labels)
+	}).Methods("POST")
+	var body bytes.Buffer
+
+	body.Write([]byte(`{
+		"selected": {
+			"hosts": [1, 2, 3],
+			"labels": [4]
+		}
+	}`))
+
+	router.ServeHTTP(
+		httptest.NewRecorder(),
+		httptest.NewRequest("POST", "/api/v1/kolide/targets/resolve?page=2&per_page=50", &body),
+	)
+}
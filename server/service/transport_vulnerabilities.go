@@ -0,0 +1,52 @@
+package service
+
+import (
+	"net/http"
+
+	"github.com/gorilla/mux"
+	"github.com/kolide/kolide-ose/server/kolide"
+	"golang.org/x/net/context"
+)
+
+// decodeImportVulnerabilitiesRequest reads the feed from the body of the
+// request, in the format given by the format parameter, which defaults to an
+// NVD feed.
+func decodeImportVulnerabilitiesRequest(ctx context.Context, r *http.Request) (interface{}, error) {
+	format := r.URL.Query().Get("format")
+	if format == "" {
+		format = kolide.VulnerabilityFeedNVD
+	}
+	return importVulnerabilitiesRequest{Format: format, Feed: r.Body}, nil
+}
+
+func decodeListVulnerabilitiesRequest(ctx context.Context, r *http.Request) (interface{}, error) {
+	opt, err := listOptionsFromRequest(r)
+	if err != nil {
+		return nil, err
+	}
+	return listVulnerabilitiesRequest{ListOptions: opt}, nil
+}
+
+func decodeListVulnerableHostsRequest(ctx context.Context, r *http.Request) (interface{}, error) {
+	cve, ok := mux.Vars(r)["cve"]
+	if !ok {
+		return nil, errBadRoute
+	}
+	opt, err := listOptionsFromRequest(r)
+	if err != nil {
+		return nil, err
+	}
+	return listVulnerableHostsRequest{CVE: cve, ListOptions: opt}, nil
+}
+
+func decodeListHostVulnerabilitiesRequest(ctx context.Context, r *http.Request) (interface{}, error) {
+	id, err := idFromRequest(r, "id")
+	if err != nil {
+		return nil, err
+	}
+	opt, err := listOptionsFromRequest(r)
+	if err != nil {
+		return nil, err
+	}
+	return listHostVulnerabilitiesRequest{HostID: id, ListOptions: opt}, nil
+}
@@ -0,0 +1,39 @@
+package service
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gorilla/mux"
+	"github.com/kolide/kolide-ose/server/kolide"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/net/context"
+)
+
+func TestDecodeImportVulnerabilitiesRequest(t *testing.T) {
+	r, err := decodeImportVulnerabilitiesRequest(context.Background(), httptest.NewRequest("POST", "/api/v1/kolide/vulnerabilities/import", nil))
+	require.Nil(t, err)
+	assert.Equal(t, kolide.VulnerabilityFeedNVD, r.(importVulnerabilitiesRequest).Format)
+
+	r, err = decodeImportVulnerabilitiesRequest(context.Background(), httptest.NewRequest("POST", "/api/v1/kolide/vulnerabilities/import?format=csv", nil))
+	require.Nil(t, err)
+	assert.Equal(t, kolide.VulnerabilityFeedCSV, r.(importVulnerabilitiesRequest).Format)
+}
+
+func TestDecodeListVulnerableHostsRequest(t *testing.T) {
+	router := mux.NewRouter()
+	router.HandleFunc("/api/v1/kolide/vulnerabilities/{cve}/hosts", func(writer http.ResponseWriter, request *http.Request) {
+		r, err := decodeListVulnerableHostsRequest(context.Background(), request)
+		require.Nil(t, err)
+
+		params := r.(listVulnerableHostsRequest)
+		assert.Equal(t, "CVE-2016-2107", params.CVE)
+	}).Methods("GET")
+
+	router.ServeHTTP(
+		httptest.NewRecorder(),
+		httptest.NewRequest("GET", "/api/v1/kolide/vulnerabilities/CVE-2016-2107/hosts", nil),
+	)
+}
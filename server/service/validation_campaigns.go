@@ -0,0 +1,17 @@
+package service
+
+import (
+	"github.com/kolide/kolide-ose/server/kolide"
+	"golang.org/x/net/context"
+)
+
+func (mw validationMiddleware) NewDistributedQueryCampaign(ctx context.Context, queryString string, hosts []uint, labels []uint) (*kolide.DistributedQueryCampaign, error) {
+	invalid := &invalidArgumentError{}
+	if err := mw.validateQuerySQL(queryString); err != nil {
+		invalid.Append("query", err.Error())
+	}
+	if invalid.HasErrors() {
+		return nil, invalid
+	}
+	return mw.Service.NewDistributedQueryCampaign(ctx, queryString, hosts, labels)
+}
@@ -0,0 +1,81 @@
+package service
+
+import (
+	"regexp"
+
+	"github.com/kolide/kolide-ose/server/kolide"
+	"golang.org/x/net/context"
+)
+
+func (mw validationMiddleware) NewDetailQuery(ctx context.Context, p kolide.DetailQueryPayload) (*kolide.DetailQuery, error) {
+	invalid := &invalidArgumentError{}
+	if p.Name == nil {
+		invalid.Append("name", "missing required argument")
+	}
+	if p.Query == nil {
+		invalid.Append("query", "missing required argument")
+	}
+	if p.Columns == nil {
+		invalid.Append("columns", "missing required argument")
+	}
+	mw.validateDetailQueryPayload(invalid, p)
+	if invalid.HasErrors() {
+		return nil, invalid
+	}
+	return mw.Service.NewDetailQuery(ctx, p)
+}
+
+func (mw validationMiddleware) ModifyDetailQuery(ctx context.Context, id uint, p kolide.DetailQueryPayload) (*kolide.DetailQuery, error) {
+	invalid := &invalidArgumentError{}
+	mw.validateDetailQueryPayload(invalid, p)
+	if invalid.HasErrors() {
+		return nil, invalid
+	}
+	return mw.Service.ModifyDetailQuery(ctx, id, p)
+}
+
+// detailQueryNameRegexp restricts the names of detail queries and their
+// columns so that attribute keys, which join the two with a dot, are
+// unambiguous and can be written in attribute filters.
+var detailQueryNameRegexp = regexp.MustCompile(`^[a-z][a-z0-9_]*$`)
+
+// maxDetailQueryNameLength matches the column that stores the name in the
+// MySQL backend.
+const maxDetailQueryNameLength = 64
+
+func (mw validationMiddleware) validateDetailQueryPayload(invalid *invalidArgumentError, p kolide.DetailQueryPayload) {
+	if p.Name != nil {
+		if !detailQueryNameRegexp.MatchString(*p.Name) {
+			invalid.Append("name", "must start with a letter and contain only lowercase letters, digits and underscores")
+		}
+		if len(*p.Name) > maxDetailQueryNameLength {
+			invalid.Appendf("name", "must not be longer than %d characters", maxDetailQueryNameLength)
+		}
+	}
+	if p.Query != nil {
+		if err := mw.validateQuerySQL(*p.Query); err != nil {
+			invalid.Append("query", err.Error())
+		}
+	}
+	if p.Interval != nil && *p.Interval == 0 {
+		invalid.Append("interval", "must be greater than zero")
+	}
+	if p.Columns != nil {
+		if len(*p.Columns) == 0 {
+			invalid.Append("columns", "at least one column must be kept")
+		}
+		seen := map[string]bool{}
+		for _, column := range *p.Columns {
+			if !detailQueryNameRegexp.MatchString(column.Name) {
+				invalid.Appendf("columns", "column name %q must start with a letter and contain only lowercase letters, digits and underscores", column.Name)
+			}
+			if seen[column.Name] {
+				invalid.Appendf("columns", "column %q is listed more than once", column.Name)
+			}
+			seen[column.Name] = true
+			if !kolide.ValidHostAttributeType(column.Type) {
+				invalid.Appendf("columns", "column %q has unknown type %q", column.Name, column.Type)
+			}
+		}
+	}
+}
@@ -0,0 +1,69 @@
+package service
+
+import (
+	"strings"
+
+	"github.com/kolide/kolide-ose/server/kolide"
+	"github.com/kolide/kolide-ose/server/sqlparser"
+	"golang.org/x/net/context"
+)
+
+func (mw validationMiddleware) NewQuery(ctx context.Context, p kolide.QueryPayload) (*kolide.Query, error) {
+	if invalid := mw.validateQueryPayload(p); invalid.HasErrors() {
+		return nil, invalid
+	}
+	return mw.Service.NewQuery(ctx, p)
+}
+
+func (mw validationMiddleware) ModifyQuery(ctx context.Context, id uint, p kolide.QueryPayload) (*kolide.Query, error) {
+	if invalid := mw.validateQueryPayload(p); invalid.HasErrors() {
+		return nil, invalid
+	}
+	return mw.Service.ModifyQuery(ctx, id, p)
+}
+
+// validateQueryPayload checks the SQL, tags and category of a query payload.
+func (mw validationMiddleware) validateQueryPayload(p kolide.QueryPayload) *invalidArgumentError {
+	invalid := &invalidArgumentError{}
+	if p.Query != nil {
+		if err := mw.validateQuerySQL(*p.Query); err != nil {
+			invalid.Append("query", err.Error())
+		}
+	}
+	validateQueryTagsAndCategory(invalid, p)
+	return invalid
+}
+
+// validateQuerySQL checks that the query parses and only references tables
+// and columns in the osquery schema.
+func (mw validationMiddleware) validateQuerySQL(sql string) error {
+	if mw.osquerySchema == nil {
+		return nil
+	}
+	_, err := sqlparser.Analyze(sql, mw.osquerySchema)
+	return err
+}
+
+// Limits on the length of query tags and categories, matching the columns
+// that store them in the MySQL backend.
+const (
+	maxQueryTagLength      = 64
+	maxQueryCategoryLength = 255
+)
+
+// validateQueryTagsAndCategory checks the tags and category of a query payload.
+func validateQueryTagsAndCategory(invalid *invalidArgumentError, p kolide.QueryPayload) {
+	if p.Tags != nil {
+		for _, tag := range *p.Tags {
+			if len(strings.TrimSpace(tag)) > maxQueryTagLength {
+				invalid.Appendf("tags", "tag %q is longer than %d characters", tag, maxQueryTagLength)
+			}
+			if strings.Contains(tag, ",") {
+				invalid.Appendf("tags", "tag %q must not contain a comma", tag)
+			}
+		}
+	}
+	if p.Category != nil && len(strings.TrimSpace(*p.Category)) > maxQueryCategoryLength {
+		invalid.Appendf("category", "category is longer than %d characters", maxQueryCategoryLength)
+	}
+}
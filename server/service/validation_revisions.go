@@ -0,0 +1,25 @@
+package service
+
+import (
+	"github.com/kolide/kolide-ose/server/kolide"
+	"golang.org/x/net/context"
+)
+
+func (mw validationMiddleware) RestoreRevision(ctx context.Context, id uint) (*kolide.Revision, error) {
+	rev, err := mw.ds.Revision(id)
+	if err != nil {
+		return nil, err
+	}
+	// A restored query is checked like any other query modification, as
+	// the schema may have changed since the revision was recorded
+	if rev.ObjectType == kolide.RevisionObjectQuery {
+		p, err := queryRevisionPayload(rev.Snapshot)
+		if err != nil {
+			return nil, err
+		}
+		if invalid := mw.validateQueryPayload(p); invalid.HasErrors() {
+			return nil, invalid
+		}
+	}
+	return mw.Service.RestoreRevision(ctx, id)
+}
@@ -11,7 +11,8 @@ import (
 
 type validationMiddleware struct {
 	kolide.Service
-	ds kolide.Datastore
+	ds            kolide.Datastore
+	osquerySchema *kolide.OsquerySchema
 }
 
 func (mw validationMiddleware) NewUser(ctx context.Context, p kolide.UserPayload) (*kolide.User, error) {
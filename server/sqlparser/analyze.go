@@ -0,0 +1,403 @@
+package sqlparser
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Catalog provides the tables that a query may reference.
+type Catalog interface {
+	// TableColumns returns the column names of the named table, or false
+	// if the table does not exist. Names are given in lower case.
+	TableColumns(name string) ([]string, bool)
+}
+
+// Analysis is the result of analyzing a query against a catalog.
+type Analysis struct {
+	Statement *SelectStmt
+	// Tables contains the catalog tables referenced by the query, in lower
+	// case and sorted by name.
+	Tables []string
+}
+
+// Analyze parses the query and checks that every table and column it
+// references exists, either in the catalog or as a common table expression
+// or subquery defined within the query. Name resolution follows SQLite and
+// is case insensitive.
+func Analyze(sql string, catalog Catalog) (*Analysis, error) {
+	stmt, err := Parse(sql)
+	if err != nil {
+		return nil, err
+	}
+
+	a := &analyzer{catalog: catalog, tables: map[string]bool{}}
+	if _, err := a.resolveSelect(stmt, nil); err != nil {
+		return nil, err
+	}
+
+	analysis := &Analysis{Statement: stmt, Tables: []string{}}
+	for name := range a.tables {
+		analysis.Tables = append(analysis.Tables, name)
+	}
+	sort.Strings(analysis.Tables)
+	return analysis, nil
+}
+
+// relation is a named set of columns visible in a scope.
+type relation struct {
+	name    string
+	columns map[string]bool
+	// open is set when the columns of the relation are not fully known,
+	// such as a subquery selecting unnamed expressions. Any column is
+	// accepted for an open relation.
+	open bool
+}
+
+func newRelation(name string, columns []string) *relation {
+	r := &relation{name: strings.ToLower(name), columns: map[string]bool{}}
+	for _, col := range columns {
+		r.columns[strings.ToLower(col)] = true
+	}
+	return r
+}
+
+func (r *relation) hasColumn(name string) bool {
+	return r.open || r.columns[name] || isRowID(name)
+}
+
+func isRowID(name string) bool {
+	return name == "rowid" || name == "oid" || name == "_rowid_"
+}
+
+// scope holds the names visible at one level of a query. Lookups fall back
+// to the parent scope, which is how correlated subqueries resolve columns
+// of the enclosing query.
+type scope struct {
+	parent    *scope
+	relations []*relation
+	ctes      map[string]*relation
+	// aliases holds the result column aliases, which SQLite allows in the
+	// WHERE, GROUP BY, HAVING and ORDER BY clauses.
+	aliases map[string]bool
+}
+
+func (s *scope) lookupCTE(name string) (*relation, bool) {
+	for ; s != nil; s = s.parent {
+		if r, ok := s.ctes[name]; ok {
+			return r, true
+		}
+	}
+	return nil, false
+}
+
+type analyzer struct {
+	catalog Catalog
+	tables  map[string]bool
+}
+
+// lookupTable finds a table by name, looking first at common table
+// expressions in scope and then at the catalog.
+func (a *analyzer) lookupTable(table *TableSource, s *scope) (*relation, error) {
+	name := strings.ToLower(table.Name)
+	if table.Schema == "" {
+		if cte, ok := s.lookupCTE(name); ok {
+			return cte, nil
+		}
+	}
+	columns, ok := a.catalog.TableColumns(name)
+	if !ok {
+		return nil, &UnknownTableError{Name: table.Name}
+	}
+	a.tables[name] = true
+	return newRelation(name, columns), nil
+}
+
+// resolveSelect checks a SELECT statement and returns the relation
+// describing its result columns.
+func (a *analyzer) resolveSelect(stmt *SelectStmt, parent *scope) (*relation, error) {
+	outer := &scope{parent: parent}
+	if stmt.With != nil {
+		outer.ctes = map[string]*relation{}
+		for _, cte := range stmt.With.Tables {
+			name := strings.ToLower(cte.Name)
+			if stmt.With.Recursive {
+				// A recursive table refers to itself before its columns
+				// are known
+				outer.ctes[name] = &relation{name: name, open: true}
+			}
+			result, err := a.resolveSelect(cte.Select, outer)
+			if err != nil {
+				return nil, err
+			}
+			if len(cte.Columns) > 0 {
+				result = newRelation(name, cte.Columns)
+			}
+			result.name = name
+			outer.ctes[name] = result
+		}
+	}
+
+	var result *relation
+	var first *scope
+	for i, core := range stmt.Cores {
+		coreScope, rel, err := a.resolveCore(core, outer)
+		if err != nil {
+			return nil, err
+		}
+		if i == 0 {
+			result = rel
+			first = coreScope
+		}
+	}
+
+	// ORDER BY terms of a compound select may only refer to the result
+	// columns
+	orderScope := first
+	if len(stmt.Cores) > 1 {
+		orderScope = &scope{parent: outer, relations: []*relation{result}}
+	}
+	for _, term := range stmt.OrderBy {
+		if err := a.resolveExpr(term.Expr, orderScope); err != nil {
+			return nil, err
+		}
+	}
+	if err := a.resolveExpr(stmt.Limit, outer); err != nil {
+		return nil, err
+	}
+	if err := a.resolveExpr(stmt.Offset, outer); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+func (a *analyzer) resolveCore(core *SelectCore, parent *scope) (*scope, *relation, error) {
+	s := &scope{parent: parent}
+
+	if core.Values != nil {
+		result := &relation{columns: map[string]bool{}}
+		for _, row := range core.Values {
+			for i := range row {
+				result.columns[fmt.Sprintf("column%d", i+1)] = true
+			}
+			if err := a.resolveExprs(row, s); err != nil {
+				return nil, nil, err
+			}
+		}
+		return s, result, nil
+	}
+
+	if core.From != nil {
+		if err := a.resolveSource(core.From, s); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	result := &relation{columns: map[string]bool{}}
+	aliases := map[string]bool{}
+	for _, col := range core.Columns {
+		if col.Star {
+			if err := a.expandStar(col, s, result); err != nil {
+				return nil, nil, err
+			}
+			continue
+		}
+		if err := a.resolveExpr(col.Expr, s); err != nil {
+			return nil, nil, err
+		}
+		switch {
+		case col.Alias != "":
+			alias := strings.ToLower(col.Alias)
+			result.columns[alias] = true
+			aliases[alias] = true
+		default:
+			if ref, ok := col.Expr.(*ColumnRef); ok {
+				result.columns[strings.ToLower(ref.Column)] = true
+			} else {
+				// SQLite names unaliased expressions after their text,
+				// which we don't attempt to reproduce
+				result.open = true
+			}
+		}
+	}
+
+	s.aliases = aliases
+	if err := a.resolveExpr(core.Where, s); err != nil {
+		return nil, nil, err
+	}
+	if err := a.resolveExprs(core.GroupBy, s); err != nil {
+		return nil, nil, err
+	}
+	if err := a.resolveExpr(core.Having, s); err != nil {
+		return nil, nil, err
+	}
+	return s, result, nil
+}
+
+// expandStar adds the columns selected by * or table.* to the result.
+func (a *analyzer) expandStar(col *ResultColumn, s *scope, result *relation) error {
+	table := strings.ToLower(col.Table)
+	found := false
+	for _, r := range s.relations {
+		if table != "" && r.name != table {
+			continue
+		}
+		found = true
+		result.open = result.open || r.open
+		for name := range r.columns {
+			result.columns[name] = true
+		}
+	}
+	if table != "" && !found {
+		return &UnknownTableError{Name: col.Table}
+	}
+	return nil
+}
+
+// resolveSource adds the relations of a FROM clause to the scope.
+func (a *analyzer) resolveSource(src Source, s *scope) error {
+	switch src := src.(type) {
+	case *TableSource:
+		r, err := a.lookupTable(src, s)
+		if err != nil {
+			return err
+		}
+		// Copy so that an alias doesn't rename a shared CTE relation
+		alias := &relation{name: r.name, columns: r.columns, open: r.open}
+		if src.Alias != "" {
+			alias.name = strings.ToLower(src.Alias)
+		}
+		s.relations = append(s.relations, alias)
+		// Arguments to table-valued functions may refer to tables to the
+		// left in the join
+		return a.resolveExprs(src.Args, s)
+
+	case *SubquerySource:
+		r, err := a.resolveSelect(src.Select, s.parent)
+		if err != nil {
+			return err
+		}
+		r.name = strings.ToLower(src.Alias)
+		s.relations = append(s.relations, r)
+		return nil
+
+	case *JoinSource:
+		if err := a.resolveSource(src.Left, s); err != nil {
+			return err
+		}
+		if err := a.resolveSource(src.Right, s); err != nil {
+			return err
+		}
+		for _, col := range src.Using {
+			ref := &ColumnRef{Column: col}
+			if err := a.resolveColumn(ref, s); err != nil {
+				return err
+			}
+		}
+		return a.resolveExpr(src.On, s)
+	}
+	return nil
+}
+
+func (a *analyzer) resolveExprs(exprs []Expr, s *scope) error {
+	for _, expr := range exprs {
+		if err := a.resolveExpr(expr, s); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (a *analyzer) resolveExpr(expr Expr, s *scope) error {
+	switch e := expr.(type) {
+	case nil, *Literal:
+		return nil
+	case *ColumnRef:
+		return a.resolveColumn(e, s)
+	case *UnaryExpr:
+		return a.resolveExpr(e.X, s)
+	case *BinaryExpr:
+		return a.resolveExprs([]Expr{e.X, e.Y}, s)
+	case *LikeExpr:
+		return a.resolveExprs([]Expr{e.X, e.Pattern, e.Escape}, s)
+	case *BetweenExpr:
+		return a.resolveExprs([]Expr{e.X, e.Low, e.High}, s)
+	case *IsNullExpr:
+		return a.resolveExpr(e.X, s)
+	case *InExpr:
+		if err := a.resolveExpr(e.X, s); err != nil {
+			return err
+		}
+		if e.Table != nil {
+			if _, err := a.lookupTable(e.Table, s); err != nil {
+				return err
+			}
+			return a.resolveExprs(e.Table.Args, s)
+		}
+		if e.Select != nil {
+			_, err := a.resolveSelect(e.Select, s)
+			return err
+		}
+		return a.resolveExprs(e.List, s)
+	case *FuncCall:
+		return a.resolveExprs(e.Args, s)
+	case *CastExpr:
+		return a.resolveExpr(e.X, s)
+	case *CollateExpr:
+		return a.resolveExpr(e.X, s)
+	case *CaseExpr:
+		if err := a.resolveExprs([]Expr{e.Operand, e.Else}, s); err != nil {
+			return err
+		}
+		for _, when := range e.Whens {
+			if err := a.resolveExprs([]Expr{when.Cond, when.Result}, s); err != nil {
+				return err
+			}
+		}
+		return nil
+	case *SubqueryExpr:
+		_, err := a.resolveSelect(e.Select, s)
+		return err
+	case *ParenExpr:
+		return a.resolveExprs(e.List, s)
+	}
+	return fmt.Errorf("unhandled expression %T", expr)
+}
+
+func (a *analyzer) resolveColumn(ref *ColumnRef, s *scope) error {
+	column := strings.ToLower(ref.Column)
+
+	if ref.Table != "" {
+		table := strings.ToLower(ref.Table)
+		for cur := s; cur != nil; cur = cur.parent {
+			for _, r := range cur.relations {
+				if r.name != table {
+					continue
+				}
+				if r.hasColumn(column) {
+					return nil
+				}
+				return &UnknownColumnError{Table: ref.Table, Column: ref.Column}
+			}
+		}
+		return &UnknownColumnError{Table: ref.Table, Column: ref.Column}
+	}
+
+	for cur := s; cur != nil; cur = cur.parent {
+		for _, r := range cur.relations {
+			if r.hasColumn(column) {
+				return nil
+			}
+		}
+		if cur.aliases[column] {
+			return nil
+		}
+	}
+
+	// SQLite treats a double quoted identifier that doesn't match any
+	// column as a string literal
+	if ref.DoubleQuoted {
+		return nil
+	}
+	return &UnknownColumnError{Column: ref.Column}
+}
@@ -0,0 +1,172 @@
+package sqlparser
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type testCatalog map[string][]string
+
+func (c testCatalog) TableColumns(name string) ([]string, bool) {
+	cols, ok := c[name]
+	return cols, ok
+}
+
+var catalog = testCatalog{
+	"processes":       {"pid", "name", "path", "uid", "parent"},
+	"users":           {"uid", "username", "directory", "shell"},
+	"listening_ports": {"pid", "port", "protocol", "address"},
+	"osquery_info":    {"version", "pid"},
+	"file":            {"path", "directory", "filename", "size", "mtime", "type"},
+	"time":            {"unix_time", "local_time"},
+}
+
+func TestAnalyzeValid(t *testing.T) {
+	var queries = []struct {
+		sql    string
+		tables []string
+	}{
+		{"select * from processes", []string{"processes"}},
+		{"SELECT pid, name FROM Processes;", []string{"processes"}},
+		{"select 1", []string{}},
+		{"select * from osquery_info;;", []string{"osquery_info"}},
+		{"select p.name, u.username from processes p join users u on p.uid = u.uid", []string{"processes", "users"}},
+		{"select * from processes as p, users as u where p.uid = u.uid", []string{"processes", "users"}},
+		{"select name from processes natural join users", []string{"processes", "users"}},
+		{"select * from processes left outer join users using (uid)", []string{"processes", "users"}},
+		{"select distinct p.name, lp.port from listening_ports lp inner join processes p on lp.pid = p.pid where lp.port != 0 and lp.address <> '127.0.0.1'", []string{"listening_ports", "processes"}},
+		{"select count(*) as total, uid from processes group by uid having total > 1 order by total desc limit 5", []string{"processes"}},
+		{"select name as n from processes where n like '%osquery%' escape '\\'", []string{"processes"}},
+		{"select * from processes where pid in (select pid from listening_ports)", []string{"listening_ports", "processes"}},
+		{"select * from processes where pid not in (1, 2, 3) and name not like 'a%'", []string{"processes"}},
+		{"select * from processes where exists (select 1 from users where users.uid = processes.uid)", []string{"processes", "users"}},
+		{"select * from (select pid, name as n from processes) sub where sub.n = 'x' and sub.pid > 0", []string{"processes"}},
+		{"select * from (select pid, upper(name) from processes) where anything = 1", []string{"processes"}},
+		{"with recursive cnt(x) as (select 1 union all select x + 1 from cnt where x < 10) select x from cnt", []string{}},
+		{"with procs as (select pid, name from processes) select p.name from procs p join users u on p.pid = u.uid", []string{"processes", "users"}},
+		{"select pid from processes union select pid from listening_ports order by pid", []string{"listening_ports", "processes"}},
+		{"select pid from processes intersect select pid from osquery_info except select 1", []string{"osquery_info", "processes"}},
+		{"values (1, 'a'), (2, 'b')", []string{}},
+		{"select column1 from (values (1), (2))", []string{}},
+		{"select cast(size as integer) / 1024 as kb, path || '/' || filename from file where path = '/etc/hosts' and type = 'regular'", []string{"file"}},
+		{"select case when size > 100 then 'big' else 'small' end, case type when 'a' then 1 end from file where directory = '/tmp'", []string{"file"}},
+		{"select * from file where size between 1 and 10 and mtime is not null and path isnull and path notnull and path not null", []string{"file"}},
+		{"select * from time where unix_time - 60 * 60 > 0x10 and local_time glob '*' and -unix_time < ~1", []string{"time"}},
+		{"select \"name\", [pid], `uid` from processes where name = \"not a column\"", []string{"processes"}},
+		{"select rowid, oid, _rowid_ from processes", []string{"processes"}},
+		{"select * from main.processes where processes.pid = ?", []string{"processes"}},
+		{"select * from processes where name = :name or name = @name or name = $name or pid = ?1", []string{"processes"}},
+		{"select x'0102', 1.5e3, .5, null, current_timestamp", []string{}},
+		{"-- comment\nselect /* inline */ pid from processes -- trailing", []string{"processes"}},
+		{"select name collate nocase from processes order by name collate nocase asc", []string{"processes"}},
+		{"select count(distinct uid) from processes limit 10 offset 5", []string{"processes"}},
+		{"select * from processes limit 5, 10", []string{"processes"}},
+		{"select (pid, uid) = (1, 2) from processes", []string{"processes"}},
+		{"select * from processes where pid in users", []string{"processes", "users"}},
+		{"select p.* from processes p cross join users", []string{"processes", "users"}},
+		{"select * from processes p where p.pid = (select max(pid) from processes where uid = p.uid)", []string{"processes"}},
+		{"select path, type from file", []string{"file"}},
+		{"select * from processes where pid is distinct from 1", []string{"processes"}},
+		{"select * from (processes join users using (uid))", []string{"processes", "users"}},
+	}
+
+	for _, tt := range queries {
+		t.Run(tt.sql, func(t *testing.T) {
+			analysis, err := Analyze(tt.sql, catalog)
+			require.Nil(t, err)
+			assert.Equal(t, tt.tables, analysis.Tables)
+		})
+	}
+}
+
+func TestAnalyzeSyntaxErrors(t *testing.T) {
+	var queries = []string{
+		"",
+		";",
+		"selec * from processes",
+		"select * from",
+		"select * from processes where",
+		"select * from processes; select * from users",
+		"delete from processes",
+		"insert into processes values (1)",
+		"pragma table_info(processes)",
+		"select 'unterminated from processes",
+		"select * from processes where pid = 0xZZ",
+		"select from processes",
+		"select * from processes limit",
+		"select case end from processes",
+		"select cast(pid as) from processes",
+		"select * from processes left users",
+		"select * from processes where pid in (1, 2",
+		"select #pid from processes",
+		"select * from processes order pid",
+		"select 12abc from processes",
+	}
+
+	for _, sql := range queries {
+		t.Run(sql, func(t *testing.T) {
+			_, err := Analyze(sql, catalog)
+			require.NotNil(t, err)
+			_, ok := err.(*SyntaxError)
+			assert.True(t, ok, "expected syntax error, got %v", err)
+		})
+	}
+}
+
+func TestAnalyzeUnknownTables(t *testing.T) {
+	var queries = []struct {
+		sql   string
+		table string
+	}{
+		{"select * from proceses", "proceses"},
+		{"select * from processes join usrs on processes.uid = usrs.uid", "usrs"},
+		{"select * from processes where pid in (select pid from nope)", "nope"},
+		{"with a as (select * from b) select * from a", "b"},
+		{"select * from processes where pid in missing", "missing"},
+		{"select missing.* from processes", "missing"},
+	}
+
+	for _, tt := range queries {
+		t.Run(tt.sql, func(t *testing.T) {
+			_, err := Analyze(tt.sql, catalog)
+			require.NotNil(t, err)
+			tableErr, ok := err.(*UnknownTableError)
+			require.True(t, ok, "expected unknown table error, got %v", err)
+			assert.Equal(t, tt.table, tableErr.Name)
+		})
+	}
+}
+
+func TestAnalyzeUnknownColumns(t *testing.T) {
+	var queries = []struct {
+		sql    string
+		table  string
+		column string
+	}{
+		{"select nam from processes", "", "nam"},
+		{"select processes.username from processes", "processes", "username"},
+		{"select * from processes where usrname = 'root'", "", "usrname"},
+		{"select p.pid from processes", "p", "pid"},
+		{"select pid from processes order by bogus", "", "bogus"},
+		{"select * from processes join users using (missing)", "", "missing"},
+		{"select * from (select pid from processes) sub where sub.name = 'x'", "sub", "name"},
+		{"with procs as (select pid from processes) select name from procs", "", "name"},
+		{"select pid from processes group by nope", "", "nope"},
+		{"select count(nope) from processes", "", "nope"},
+		{"select * from processes where exists (select 1 from users where users.pid = 1)", "users", "pid"},
+		{"select pid from processes union select pid from users", "", "pid"},
+	}
+
+	for _, tt := range queries {
+		t.Run(tt.sql, func(t *testing.T) {
+			_, err := Analyze(tt.sql, catalog)
+			require.NotNil(t, err)
+			colErr, ok := err.(*UnknownColumnError)
+			require.True(t, ok, "expected unknown column error, got %v", err)
+			assert.Equal(t, tt.table, colErr.Table)
+			assert.Equal(t, tt.column, colErr.Column)
+		})
+	}
+}
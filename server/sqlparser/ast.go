@@ -0,0 +1,229 @@
+package sqlparser
+
+// SelectStmt is a complete SELECT statement, which may be a compound of
+// several select cores.
+type SelectStmt struct {
+	With *With
+	// Cores holds each SELECT or VALUES clause of a compound statement.
+	Cores []*SelectCore
+	// CompoundOps holds the operator joining Cores[i] and Cores[i+1], one
+	// of UNION, UNION ALL, INTERSECT or EXCEPT.
+	CompoundOps []string
+	OrderBy     []*OrderingTerm
+	Limit       Expr
+	Offset      Expr
+}
+
+// With is a common table expression clause.
+type With struct {
+	Recursive bool
+	Tables    []*CommonTable
+}
+
+// CommonTable is a single named table of a WITH clause.
+type CommonTable struct {
+	Name    string
+	Columns []string
+	Select  *SelectStmt
+}
+
+// SelectCore is a single SELECT or VALUES clause.
+type SelectCore struct {
+	Distinct bool
+	Columns  []*ResultColumn
+	From     Source
+	Where    Expr
+	GroupBy  []Expr
+	Having   Expr
+	// Values is set instead of the fields above for a VALUES clause.
+	Values [][]Expr
+}
+
+// ResultColumn is a single column of a SELECT result. Either Star is set,
+// optionally with a Table qualifier, or Expr is set.
+type ResultColumn struct {
+	Star  bool
+	Table string
+	Expr  Expr
+	Alias string
+}
+
+// OrderingTerm is a single term of an ORDER BY clause.
+type OrderingTerm struct {
+	Expr Expr
+	Desc bool
+}
+
+// Source is a table, subquery or join in a FROM clause.
+type Source interface {
+	source()
+}
+
+// TableSource is a named table, or a table-valued function when Args is
+// not nil.
+type TableSource struct {
+	Schema string
+	Name   string
+	Args   []Expr
+	Alias  string
+}
+
+// SubquerySource is a parenthesized SELECT used as a table.
+type SubquerySource struct {
+	Select *SelectStmt
+	Alias  string
+}
+
+// JoinSource joins two sources. Op is "," for a comma join, otherwise the
+// join operator keywords, such as "LEFT JOIN".
+type JoinSource struct {
+	Left    Source
+	Right   Source
+	Op      string
+	Natural bool
+	On      Expr
+	Using   []string
+}
+
+func (*TableSource) source()    {}
+func (*SubquerySource) source() {}
+func (*JoinSource) source()     {}
+
+// Expr is an SQL expression.
+type Expr interface {
+	expr()
+}
+
+// Literal kinds
+const (
+	LiteralNumber = "number"
+	LiteralString = "string"
+	LiteralBlob   = "blob"
+	LiteralNull   = "null"
+	LiteralParam  = "param"
+	// LiteralKeyword covers CURRENT_TIME, CURRENT_DATE and
+	// CURRENT_TIMESTAMP.
+	LiteralKeyword = "keyword"
+)
+
+// Literal is a constant value or bind parameter.
+type Literal struct {
+	Kind  string
+	Value string
+}
+
+// ColumnRef is a reference to a column, optionally qualified by a table
+// name.
+type ColumnRef struct {
+	Table  string
+	Column string
+	// DoubleQuoted is set when the column name was quoted with "", in which
+	// case SQLite falls back to treating it as a string literal if no such
+	// column exists.
+	DoubleQuoted bool
+}
+
+// UnaryExpr is a prefix operator: -, +, ~ or NOT.
+type UnaryExpr struct {
+	Op string
+	X  Expr
+}
+
+// BinaryExpr is an infix operator, including AND, OR, IS and IS NOT.
+type BinaryExpr struct {
+	Op string
+	X  Expr
+	Y  Expr
+}
+
+// LikeExpr is a LIKE, GLOB, REGEXP or MATCH pattern match.
+type LikeExpr struct {
+	X       Expr
+	Not     bool
+	Op      string
+	Pattern Expr
+	Escape  Expr
+}
+
+// BetweenExpr is a BETWEEN range test.
+type BetweenExpr struct {
+	X    Expr
+	Not  bool
+	Low  Expr
+	High Expr
+}
+
+// InExpr is an IN membership test against a list of values, a subquery or
+// a table.
+type InExpr struct {
+	X      Expr
+	Not    bool
+	List   []Expr
+	Select *SelectStmt
+	Table  *TableSource
+}
+
+// IsNullExpr is an ISNULL, NOTNULL or NOT NULL test.
+type IsNullExpr struct {
+	X   Expr
+	Not bool
+}
+
+// FuncCall is a call to a scalar or aggregate function.
+type FuncCall struct {
+	Name     string
+	Distinct bool
+	Star     bool
+	Args     []Expr
+}
+
+// CastExpr is a CAST(x AS type) expression.
+type CastExpr struct {
+	X    Expr
+	Type string
+}
+
+// CollateExpr applies a collating sequence to an expression.
+type CollateExpr struct {
+	X         Expr
+	Collation string
+}
+
+// CaseExpr is a CASE expression, with an optional base operand.
+type CaseExpr struct {
+	Operand Expr
+	Whens   []*When
+	Else    Expr
+}
+
+// When is a single WHEN ... THEN ... branch of a CASE expression.
+type When struct {
+	Cond   Expr
+	Result Expr
+}
+
+// SubqueryExpr is a scalar subquery, or an EXISTS test when Exists is set.
+type SubqueryExpr struct {
+	Exists bool
+	Select *SelectStmt
+}
+
+// ParenExpr is a parenthesized expression or row value.
+type ParenExpr struct {
+	List []Expr
+}
+
+func (*Literal) expr()      {}
+func (*ColumnRef) expr()    {}
+func (*UnaryExpr) expr()    {}
+func (*BinaryExpr) expr()   {}
+func (*LikeExpr) expr()     {}
+func (*BetweenExpr) expr()  {}
+func (*InExpr) expr()       {}
+func (*IsNullExpr) expr()   {}
+func (*FuncCall) expr()     {}
+func (*CastExpr) expr()     {}
+func (*CollateExpr) expr()  {}
+func (*CaseExpr) expr()     {}
+func (*SubqueryExpr) expr() {}
+func (*ParenExpr) expr()    {}
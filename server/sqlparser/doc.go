@@ -0,0 +1,4 @@
+// Package sqlparser implements a parser for the SQLite dialect of SELECT
+// statements that osquery accepts, along with an analyzer that checks the
+// tables and columns referenced by a query against a catalog.
+package sqlparser
@@ -0,0 +1,38 @@
+package sqlparser
+
+import "fmt"
+
+// SyntaxError is returned when a query cannot be parsed.
+type SyntaxError struct {
+	// Offset is the byte offset in the query at which the error occurred
+	Offset int
+	Msg    string
+}
+
+func (e *SyntaxError) Error() string {
+	return fmt.Sprintf("syntax error at offset %d: %s", e.Offset, e.Msg)
+}
+
+// UnknownTableError is returned when a query references a table that is
+// not in the catalog.
+type UnknownTableError struct {
+	Name string
+}
+
+func (e *UnknownTableError) Error() string {
+	return "no such table: " + e.Name
+}
+
+// UnknownColumnError is returned when a query references a column that
+// does not exist in any table in scope.
+type UnknownColumnError struct {
+	Table  string
+	Column string
+}
+
+func (e *UnknownColumnError) Error() string {
+	if e.Table != "" {
+		return "no such column: " + e.Table + "." + e.Column
+	}
+	return "no such column: " + e.Column
+}
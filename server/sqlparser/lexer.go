@@ -0,0 +1,283 @@
+package sqlparser
+
+import (
+	"fmt"
+	"strings"
+)
+
+type tokenKind int
+
+const (
+	tokEOF tokenKind = iota
+	// tokIdent is an unquoted word, which may be a keyword
+	tokIdent
+	// tokQuotedIdent is an identifier quoted with "", [] or ``
+	tokQuotedIdent
+	tokString
+	tokBlob
+	tokNumber
+	tokParam
+	tokOp
+)
+
+type token struct {
+	kind tokenKind
+	// val is the unquoted value of the token. For operators it is the
+	// operator itself.
+	val string
+	// doubleQuoted is set for identifiers quoted with "". SQLite treats
+	// these as string literals if they do not resolve to a column.
+	doubleQuoted bool
+	// pos is the byte offset of the token in the input
+	pos int
+}
+
+func (t token) String() string {
+	switch t.kind {
+	case tokEOF:
+		return "end of input"
+	case tokString:
+		return "'" + t.val + "'"
+	default:
+		return t.val
+	}
+}
+
+// keyword returns the upper cased value of an unquoted word, or the empty
+// string for any other token.
+func (t token) keyword() string {
+	if t.kind != tokIdent {
+		return ""
+	}
+	return strings.ToUpper(t.val)
+}
+
+// reserved contains the SQLite keywords that may never be used as unquoted
+// identifiers. All other keywords fall back to identifiers where the grammar
+// allows it, as they do in SQLite.
+var reserved = map[string]bool{
+	"ADD": true, "ALL": true, "ALTER": true, "AND": true, "AS": true,
+	"AUTOINCREMENT": true, "BETWEEN": true, "CASE": true, "CHECK": true,
+	"COLLATE": true, "COMMIT": true, "CONSTRAINT": true, "CREATE": true,
+	"DEFAULT": true, "DEFERRABLE": true, "DELETE": true, "DISTINCT": true,
+	"DROP": true, "ELSE": true, "ESCAPE": true, "EXCEPT": true,
+	"EXISTS": true, "FOREIGN": true, "FROM": true, "GROUP": true,
+	"HAVING": true, "IN": true, "INDEX": true, "INSERT": true,
+	"INTERSECT": true, "INTO": true, "IS": true, "ISNULL": true,
+	"JOIN": true, "LIMIT": true, "NOT": true, "NOTNULL": true, "NULL": true,
+	"ON": true, "OR": true, "ORDER": true, "PRIMARY": true,
+	"REFERENCES": true, "SELECT": true, "SET": true, "TABLE": true,
+	"THEN": true, "TO": true, "TRANSACTION": true, "UNION": true,
+	"UNIQUE": true, "UPDATE": true, "USING": true, "VALUES": true,
+	"WHEN": true, "WHERE": true,
+}
+
+// joinKeywords may be used as column names, but not as implicit aliases.
+var joinKeywords = map[string]bool{
+	"CROSS": true, "FULL": true, "INNER": true, "LEFT": true,
+	"NATURAL": true, "OUTER": true, "RIGHT": true,
+}
+
+// operators lists the multi-character operators first so that the longest
+// match wins.
+var operators = []string{
+	"||", "<<", ">>", "<=", ">=", "==", "!=", "<>",
+	"*", "/", "%", "+", "-", "<", ">", "=", "&", "|", "~", "(", ")", ",", ".", ";",
+}
+
+func isIdentStart(c byte) bool {
+	return c == '_' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') || c >= 0x80
+}
+
+func isIdentChar(c byte) bool {
+	return isIdentStart(c) || isDigit(c) || c == '$'
+}
+
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
+
+func isHexDigit(c byte) bool {
+	return isDigit(c) || (c >= 'a' && c <= 'f') || (c >= 'A' && c <= 'F')
+}
+
+// tokenize splits the input into tokens, skipping whitespace and comments.
+func tokenize(input string) ([]token, error) {
+	var tokens []token
+	i := 0
+	for i < len(input) {
+		c := input[i]
+		switch {
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r' || c == '\f':
+			i++
+
+		case strings.HasPrefix(input[i:], "--"):
+			end := strings.IndexByte(input[i:], '\n')
+			if end < 0 {
+				i = len(input)
+			} else {
+				i += end + 1
+			}
+
+		case strings.HasPrefix(input[i:], "/*"):
+			end := strings.Index(input[i+2:], "*/")
+			if end < 0 {
+				// SQLite accepts unterminated block comments
+				i = len(input)
+			} else {
+				i += end + 4
+			}
+
+		case (c == 'x' || c == 'X') && i+1 < len(input) && input[i+1] == '\'':
+			val, n, err := scanQuoted(input, i+1, '\'')
+			if err != nil {
+				return nil, err
+			}
+			if len(val)%2 != 0 {
+				return nil, &SyntaxError{Offset: i, Msg: "malformed blob literal"}
+			}
+			for j := 0; j < len(val); j++ {
+				if !isHexDigit(val[j]) {
+					return nil, &SyntaxError{Offset: i, Msg: "malformed blob literal"}
+				}
+			}
+			tokens = append(tokens, token{kind: tokBlob, val: val, pos: i})
+			i += 1 + n
+
+		case isIdentStart(c):
+			start := i
+			for i < len(input) && isIdentChar(input[i]) {
+				i++
+			}
+			tokens = append(tokens, token{kind: tokIdent, val: input[start:i], pos: start})
+
+		case isDigit(c) || (c == '.' && i+1 < len(input) && isDigit(input[i+1])):
+			n, err := scanNumber(input, i)
+			if err != nil {
+				return nil, err
+			}
+			tokens = append(tokens, token{kind: tokNumber, val: input[i : i+n], pos: i})
+			i += n
+
+		case c == '\'':
+			val, n, err := scanQuoted(input, i, '\'')
+			if err != nil {
+				return nil, err
+			}
+			tokens = append(tokens, token{kind: tokString, val: val, pos: i})
+			i += n
+
+		case c == '"' || c == '`':
+			val, n, err := scanQuoted(input, i, c)
+			if err != nil {
+				return nil, err
+			}
+			tokens = append(tokens, token{kind: tokQuotedIdent, val: val, doubleQuoted: c == '"', pos: i})
+			i += n
+
+		case c == '[':
+			end := strings.IndexByte(input[i:], ']')
+			if end < 0 {
+				return nil, &SyntaxError{Offset: i, Msg: "unterminated identifier"}
+			}
+			tokens = append(tokens, token{kind: tokQuotedIdent, val: input[i+1 : i+end], pos: i})
+			i += end + 1
+
+		case c == '?':
+			start := i
+			i++
+			for i < len(input) && isDigit(input[i]) {
+				i++
+			}
+			tokens = append(tokens, token{kind: tokParam, val: input[start:i], pos: start})
+
+		case c == ':' || c == '@' || c == '$':
+			start := i
+			i++
+			for i < len(input) && isIdentChar(input[i]) {
+				i++
+			}
+			if i == start+1 {
+				return nil, &SyntaxError{Offset: start, Msg: fmt.Sprintf("unrecognized token: %q", string(c))}
+			}
+			tokens = append(tokens, token{kind: tokParam, val: input[start:i], pos: start})
+
+		default:
+			matched := false
+			for _, op := range operators {
+				if strings.HasPrefix(input[i:], op) {
+					tokens = append(tokens, token{kind: tokOp, val: op, pos: i})
+					i += len(op)
+					matched = true
+					break
+				}
+			}
+			if !matched {
+				return nil, &SyntaxError{Offset: i, Msg: fmt.Sprintf("unrecognized token: %q", string(c))}
+			}
+		}
+	}
+	tokens = append(tokens, token{kind: tokEOF, pos: len(input)})
+	return tokens, nil
+}
+
+// scanQuoted reads a quoted string or identifier starting at input[start],
+// where a doubled quote character is an escaped quote. It returns the
+// unquoted value and the number of bytes consumed.
+func scanQuoted(input string, start int, quote byte) (string, int, error) {
+	var val []byte
+	i := start + 1
+	for i < len(input) {
+		if input[i] == quote {
+			if i+1 < len(input) && input[i+1] == quote {
+				val = append(val, quote)
+				i += 2
+				continue
+			}
+			return string(val), i + 1 - start, nil
+		}
+		val = append(val, input[i])
+		i++
+	}
+	return "", 0, &SyntaxError{Offset: start, Msg: "unterminated quoted string"}
+}
+
+// scanNumber returns the length of the numeric literal at input[start].
+func scanNumber(input string, start int) (int, error) {
+	i := start
+	if strings.HasPrefix(input[i:], "0x") || strings.HasPrefix(input[i:], "0X") {
+		i += 2
+		for i < len(input) && isHexDigit(input[i]) {
+			i++
+		}
+		if i == start+2 {
+			return 0, &SyntaxError{Offset: start, Msg: "malformed hex literal"}
+		}
+	} else {
+		for i < len(input) && isDigit(input[i]) {
+			i++
+		}
+		if i < len(input) && input[i] == '.' {
+			i++
+			for i < len(input) && isDigit(input[i]) {
+				i++
+			}
+		}
+		if i < len(input) && (input[i] == 'e' || input[i] == 'E') {
+			j := i + 1
+			if j < len(input) && (input[j] == '+' || input[j] == '-') {
+				j++
+			}
+			if j < len(input) && isDigit(input[j]) {
+				i = j
+				for i < len(input) && isDigit(input[i]) {
+					i++
+				}
+			}
+		}
+	}
+	if i < len(input) && isIdentChar(input[i]) {
+		return 0, &SyntaxError{Offset: start, Msg: fmt.Sprintf("unrecognized token: %q", input[start:i+1])}
+	}
+	return i - start, nil
+}
@@ -0,0 +1,1044 @@
+package sqlparser
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Parse parses a single SELECT statement, optionally followed by a
+// semicolon.
+func Parse(sql string) (*SelectStmt, error) {
+	tokens, err := tokenize(sql)
+	if err != nil {
+		return nil, err
+	}
+	p := &parser{tokens: tokens}
+
+	switch p.peek().keyword() {
+	case "SELECT", "WITH", "VALUES":
+	default:
+		if p.peek().kind == tokEOF {
+			return nil, p.errorf("empty query")
+		}
+		return nil, p.errorf("only SELECT statements are allowed, found %s", p.peek())
+	}
+
+	stmt, err := p.parseSelect()
+	if err != nil {
+		return nil, err
+	}
+	for p.acceptOp(";") {
+	}
+	if p.peek().kind != tokEOF {
+		return nil, p.errorf("unexpected %s", p.peek())
+	}
+	return stmt, nil
+}
+
+type parser struct {
+	tokens []token
+	pos    int
+}
+
+func (p *parser) peek() token {
+	return p.tokens[p.pos]
+}
+
+func (p *parser) peekAt(n int) token {
+	if p.pos+n >= len(p.tokens) {
+		return p.tokens[len(p.tokens)-1]
+	}
+	return p.tokens[p.pos+n]
+}
+
+func (p *parser) next() token {
+	t := p.tokens[p.pos]
+	if t.kind != tokEOF {
+		p.pos++
+	}
+	return t
+}
+
+func (p *parser) errorf(format string, args ...interface{}) error {
+	return &SyntaxError{Offset: p.peek().pos, Msg: fmt.Sprintf(format, args...)}
+}
+
+func (p *parser) isOp(op string) bool {
+	t := p.peek()
+	return t.kind == tokOp && t.val == op
+}
+
+func (p *parser) acceptOp(op string) bool {
+	if p.isOp(op) {
+		p.next()
+		return true
+	}
+	return false
+}
+
+func (p *parser) expectOp(op string) error {
+	if !p.acceptOp(op) {
+		return p.errorf("expected %q, found %s", op, p.peek())
+	}
+	return nil
+}
+
+func (p *parser) isKeyword(kw string) bool {
+	return p.peek().keyword() == kw
+}
+
+func (p *parser) acceptKeyword(kw string) bool {
+	if p.isKeyword(kw) {
+		p.next()
+		return true
+	}
+	return false
+}
+
+func (p *parser) expectKeyword(kw string) error {
+	if !p.acceptKeyword(kw) {
+		return p.errorf("expected %s, found %s", kw, p.peek())
+	}
+	return nil
+}
+
+// isIdent reports whether t can be used as an identifier.
+func isIdent(t token) bool {
+	switch t.kind {
+	case tokQuotedIdent:
+		return true
+	case tokIdent:
+		return !reserved[strings.ToUpper(t.val)]
+	}
+	return false
+}
+
+func (p *parser) parseIdent() (token, error) {
+	t := p.peek()
+	if !isIdent(t) {
+		return t, p.errorf("expected identifier, found %s", t)
+	}
+	return p.next(), nil
+}
+
+// parseAlias parses an optional alias following a table or result column.
+func (p *parser) parseAlias() (string, error) {
+	if p.acceptKeyword("AS") {
+		t := p.peek()
+		if t.kind == tokString {
+			return p.next().val, nil
+		}
+		ident, err := p.parseIdent()
+		if err != nil {
+			return "", err
+		}
+		return ident.val, nil
+	}
+
+	t := p.peek()
+	switch {
+	case t.kind == tokString || t.kind == tokQuotedIdent:
+		return p.next().val, nil
+	case t.kind == tokIdent:
+		kw := t.keyword()
+		if reserved[kw] || joinKeywords[kw] {
+			return "", nil
+		}
+		// INDEXED BY follows the alias position of a table
+		if kw == "INDEXED" && p.peekAt(1).keyword() == "BY" {
+			return "", nil
+		}
+		return p.next().val, nil
+	}
+	return "", nil
+}
+
+func (p *parser) isSelectStart() bool {
+	switch p.peek().keyword() {
+	case "SELECT", "WITH", "VALUES":
+		return true
+	}
+	return false
+}
+
+func (p *parser) parseSelect() (*SelectStmt, error) {
+	stmt := &SelectStmt{}
+
+	if p.acceptKeyword("WITH") {
+		with, err := p.parseWith()
+		if err != nil {
+			return nil, err
+		}
+		stmt.With = with
+	}
+
+	core, err := p.parseSelectCore()
+	if err != nil {
+		return nil, err
+	}
+	stmt.Cores = append(stmt.Cores, core)
+
+	for {
+		var op string
+		switch {
+		case p.acceptKeyword("UNION"):
+			op = "UNION"
+			if p.acceptKeyword("ALL") {
+				op = "UNION ALL"
+			}
+		case p.acceptKeyword("INTERSECT"):
+			op = "INTERSECT"
+		case p.acceptKeyword("EXCEPT"):
+			op = "EXCEPT"
+		}
+		if op == "" {
+			break
+		}
+		core, err := p.parseSelectCore()
+		if err != nil {
+			return nil, err
+		}
+		stmt.CompoundOps = append(stmt.CompoundOps, op)
+		stmt.Cores = append(stmt.Cores, core)
+	}
+
+	if p.acceptKeyword("ORDER") {
+		if err := p.expectKeyword("BY"); err != nil {
+			return nil, err
+		}
+		for {
+			term, err := p.parseOrderingTerm()
+			if err != nil {
+				return nil, err
+			}
+			stmt.OrderBy = append(stmt.OrderBy, term)
+			if !p.acceptOp(",") {
+				break
+			}
+		}
+	}
+
+	if p.acceptKeyword("LIMIT") {
+		limit, err := p.parseExpr()
+		if err != nil {
+			return nil, err
+		}
+		stmt.Limit = limit
+		if p.acceptKeyword("OFFSET") {
+			if stmt.Offset, err = p.parseExpr(); err != nil {
+				return nil, err
+			}
+		} else if p.acceptOp(",") {
+			// LIMIT offset, count
+			count, err := p.parseExpr()
+			if err != nil {
+				return nil, err
+			}
+			stmt.Offset = limit
+			stmt.Limit = count
+		}
+	}
+
+	return stmt, nil
+}
+
+func (p *parser) parseWith() (*With, error) {
+	with := &With{Recursive: p.acceptKeyword("RECURSIVE")}
+	for {
+		name, err := p.parseIdent()
+		if err != nil {
+			return nil, err
+		}
+		table := &CommonTable{Name: name.val}
+		if p.acceptOp("(") {
+			if table.Columns, err = p.parseIdentList(); err != nil {
+				return nil, err
+			}
+		}
+		if err := p.expectKeyword("AS"); err != nil {
+			return nil, err
+		}
+		if err := p.expectOp("("); err != nil {
+			return nil, err
+		}
+		if table.Select, err = p.parseSelect(); err != nil {
+			return nil, err
+		}
+		if err := p.expectOp(")"); err != nil {
+			return nil, err
+		}
+		with.Tables = append(with.Tables, table)
+		if !p.acceptOp(",") {
+			return with, nil
+		}
+	}
+}
+
+// parseIdentList parses a comma separated list of identifiers and the
+// closing parenthesis.
+func (p *parser) parseIdentList() ([]string, error) {
+	var names []string
+	for {
+		ident, err := p.parseIdent()
+		if err != nil {
+			return nil, err
+		}
+		names = append(names, ident.val)
+		if !p.acceptOp(",") {
+			break
+		}
+	}
+	if err := p.expectOp(")"); err != nil {
+		return nil, err
+	}
+	return names, nil
+}
+
+func (p *parser) parseSelectCore() (*SelectCore, error) {
+	core := &SelectCore{}
+
+	if p.acceptKeyword("VALUES") {
+		for {
+			if err := p.expectOp("("); err != nil {
+				return nil, err
+			}
+			row, err := p.parseExprList()
+			if err != nil {
+				return nil, err
+			}
+			if err := p.expectOp(")"); err != nil {
+				return nil, err
+			}
+			core.Values = append(core.Values, row)
+			if !p.acceptOp(",") {
+				return core, nil
+			}
+		}
+	}
+
+	if err := p.expectKeyword("SELECT"); err != nil {
+		return nil, err
+	}
+	if p.acceptKeyword("DISTINCT") {
+		core.Distinct = true
+	} else {
+		p.acceptKeyword("ALL")
+	}
+
+	for {
+		col, err := p.parseResultColumn()
+		if err != nil {
+			return nil, err
+		}
+		core.Columns = append(core.Columns, col)
+		if !p.acceptOp(",") {
+			break
+		}
+	}
+
+	var err error
+	if p.acceptKeyword("FROM") {
+		if core.From, err = p.parseSource(); err != nil {
+			return nil, err
+		}
+	}
+	if p.acceptKeyword("WHERE") {
+		if core.Where, err = p.parseExpr(); err != nil {
+			return nil, err
+		}
+	}
+	if p.acceptKeyword("GROUP") {
+		if err := p.expectKeyword("BY"); err != nil {
+			return nil, err
+		}
+		if core.GroupBy, err = p.parseExprList(); err != nil {
+			return nil, err
+		}
+	}
+	if p.acceptKeyword("HAVING") {
+		if core.Having, err = p.parseExpr(); err != nil {
+			return nil, err
+		}
+	}
+	return core, nil
+}
+
+func (p *parser) parseResultColumn() (*ResultColumn, error) {
+	if p.acceptOp("*") {
+		return &ResultColumn{Star: true}, nil
+	}
+	if isIdent(p.peek()) && p.peekAt(1).kind == tokOp && p.peekAt(1).val == "." &&
+		p.peekAt(2).kind == tokOp && p.peekAt(2).val == "*" {
+		table := p.next()
+		p.next()
+		p.next()
+		return &ResultColumn{Star: true, Table: table.val}, nil
+	}
+
+	expr, err := p.parseExpr()
+	if err != nil {
+		return nil, err
+	}
+	alias, err := p.parseAlias()
+	if err != nil {
+		return nil, err
+	}
+	return &ResultColumn{Expr: expr, Alias: alias}, nil
+}
+
+func (p *parser) parseOrderingTerm() (*OrderingTerm, error) {
+	expr, err := p.parseExpr()
+	if err != nil {
+		return nil, err
+	}
+	term := &OrderingTerm{Expr: expr}
+	if p.acceptKeyword("DESC") {
+		term.Desc = true
+	} else {
+		p.acceptKeyword("ASC")
+	}
+	if p.acceptKeyword("NULLS") {
+		if !p.acceptKeyword("FIRST") && !p.acceptKeyword("LAST") {
+			return nil, p.errorf("expected FIRST or LAST, found %s", p.peek())
+		}
+	}
+	return term, nil
+}
+
+func (p *parser) parseSource() (Source, error) {
+	left, err := p.parseSingleSource()
+	if err != nil {
+		return nil, err
+	}
+
+	for {
+		join := &JoinSource{Left: left}
+		if p.acceptOp(",") {
+			join.Op = ","
+		} else {
+			op, ok, err := p.parseJoinOp()
+			if err != nil {
+				return nil, err
+			}
+			if !ok {
+				return left, nil
+			}
+			join.Natural = strings.HasPrefix(op, "NATURAL ")
+			join.Op = strings.TrimPrefix(op, "NATURAL ")
+		}
+
+		if join.Right, err = p.parseSingleSource(); err != nil {
+			return nil, err
+		}
+
+		if p.acceptKeyword("ON") {
+			if join.On, err = p.parseExpr(); err != nil {
+				return nil, err
+			}
+		} else if p.acceptKeyword("USING") {
+			if err := p.expectOp("("); err != nil {
+				return nil, err
+			}
+			if join.Using, err = p.parseIdentList(); err != nil {
+				return nil, err
+			}
+		}
+		left = join
+	}
+}
+
+// parseJoinOp parses the keywords of a join operator, returning false if
+// the next token does not start one.
+func (p *parser) parseJoinOp() (string, bool, error) {
+	var words []string
+	for {
+		kw := p.peek().keyword()
+		if kw == "JOIN" {
+			p.next()
+			words = append(words, kw)
+			return strings.Join(words, " "), true, nil
+		}
+		if !joinKeywords[kw] {
+			if len(words) > 0 {
+				return "", false, p.errorf("expected JOIN, found %s", p.peek())
+			}
+			return "", false, nil
+		}
+		p.next()
+		words = append(words, kw)
+	}
+}
+
+func (p *parser) parseSingleSource() (Source, error) {
+	if p.acceptOp("(") {
+		if p.isSelectStart() {
+			sel, err := p.parseSelect()
+			if err != nil {
+				return nil, err
+			}
+			if err := p.expectOp(")"); err != nil {
+				return nil, err
+			}
+			alias, err := p.parseAlias()
+			if err != nil {
+				return nil, err
+			}
+			return &SubquerySource{Select: sel, Alias: alias}, nil
+		}
+		src, err := p.parseSource()
+		if err != nil {
+			return nil, err
+		}
+		if err := p.expectOp(")"); err != nil {
+			return nil, err
+		}
+		return src, nil
+	}
+
+	table, err := p.parseTableName()
+	if err != nil {
+		return nil, err
+	}
+	if p.acceptOp("(") {
+		table.Args = []Expr{}
+		if !p.acceptOp(")") {
+			if table.Args, err = p.parseExprList(); err != nil {
+				return nil, err
+			}
+			if err := p.expectOp(")"); err != nil {
+				return nil, err
+			}
+		}
+	}
+	if table.Alias, err = p.parseAlias(); err != nil {
+		return nil, err
+	}
+
+	if p.isKeyword("INDEXED") && p.peekAt(1).keyword() == "BY" {
+		p.next()
+		p.next()
+		if _, err := p.parseIdent(); err != nil {
+			return nil, err
+		}
+	} else if p.isKeyword("NOT") && p.peekAt(1).keyword() == "INDEXED" {
+		p.next()
+		p.next()
+	}
+	return table, nil
+}
+
+// parseTableName parses a table name with an optional schema qualifier.
+func (p *parser) parseTableName() (*TableSource, error) {
+	name, err := p.parseIdent()
+	if err != nil {
+		return nil, err
+	}
+	table := &TableSource{Name: name.val}
+	if p.acceptOp(".") {
+		name, err := p.parseIdent()
+		if err != nil {
+			return nil, err
+		}
+		table.Schema = table.Name
+		table.Name = name.val
+	}
+	return table, nil
+}
+
+func (p *parser) parseExprList() ([]Expr, error) {
+	var list []Expr
+	for {
+		expr, err := p.parseExpr()
+		if err != nil {
+			return nil, err
+		}
+		list = append(list, expr)
+		if !p.acceptOp(",") {
+			return list, nil
+		}
+	}
+}
+
+// parseExpr parses an expression. The parse functions below are ordered
+// from the lowest to the highest operator precedence.
+func (p *parser) parseExpr() (Expr, error) {
+	return p.parseOr()
+}
+
+func (p *parser) parseOr() (Expr, error) {
+	x, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	for p.acceptKeyword("OR") {
+		y, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		x = &BinaryExpr{Op: "OR", X: x, Y: y}
+	}
+	return x, nil
+}
+
+func (p *parser) parseAnd() (Expr, error) {
+	x, err := p.parseNot()
+	if err != nil {
+		return nil, err
+	}
+	for p.acceptKeyword("AND") {
+		y, err := p.parseNot()
+		if err != nil {
+			return nil, err
+		}
+		x = &BinaryExpr{Op: "AND", X: x, Y: y}
+	}
+	return x, nil
+}
+
+func (p *parser) parseNot() (Expr, error) {
+	if p.acceptKeyword("NOT") {
+		x, err := p.parseNot()
+		if err != nil {
+			return nil, err
+		}
+		return &UnaryExpr{Op: "NOT", X: x}, nil
+	}
+	return p.parseEquality()
+}
+
+var likeOps = map[string]bool{"LIKE": true, "GLOB": true, "REGEXP": true, "MATCH": true}
+
+func (p *parser) parseEquality() (Expr, error) {
+	x, err := p.parseComparison()
+	if err != nil {
+		return nil, err
+	}
+
+	for {
+		t := p.peek()
+		if t.kind == tokOp {
+			switch t.val {
+			case "=", "==", "!=", "<>":
+				p.next()
+				y, err := p.parseComparison()
+				if err != nil {
+					return nil, err
+				}
+				x = &BinaryExpr{Op: t.val, X: x, Y: y}
+				continue
+			}
+			return x, nil
+		}
+
+		kw := t.keyword()
+		not := false
+		if kw == "NOT" {
+			switch next := p.peekAt(1).keyword(); {
+			case next == "IN" || next == "BETWEEN" || next == "NULL" || likeOps[next]:
+				p.next()
+				not = true
+				kw = next
+			default:
+				return x, nil
+			}
+		}
+
+		switch {
+		case kw == "IS":
+			p.next()
+			op := "IS"
+			if p.acceptKeyword("NOT") {
+				op = "IS NOT"
+			}
+			if p.acceptKeyword("DISTINCT") {
+				if err := p.expectKeyword("FROM"); err != nil {
+					return nil, err
+				}
+				if op == "IS" {
+					op = "IS NOT"
+				} else {
+					op = "IS"
+				}
+			}
+			y, err := p.parseComparison()
+			if err != nil {
+				return nil, err
+			}
+			x = &BinaryExpr{Op: op, X: x, Y: y}
+
+		case kw == "ISNULL" || kw == "NOTNULL" || (kw == "NULL" && not):
+			p.next()
+			x = &IsNullExpr{X: x, Not: kw != "ISNULL"}
+
+		case kw == "IN":
+			p.next()
+			if x, err = p.parseIn(x, not); err != nil {
+				return nil, err
+			}
+
+		case likeOps[kw]:
+			p.next()
+			like := &LikeExpr{X: x, Not: not, Op: kw}
+			if like.Pattern, err = p.parseComparison(); err != nil {
+				return nil, err
+			}
+			if p.acceptKeyword("ESCAPE") {
+				if like.Escape, err = p.parseComparison(); err != nil {
+					return nil, err
+				}
+			}
+			x = like
+
+		case kw == "BETWEEN":
+			p.next()
+			between := &BetweenExpr{X: x, Not: not}
+			if between.Low, err = p.parseComparison(); err != nil {
+				return nil, err
+			}
+			if err := p.expectKeyword("AND"); err != nil {
+				return nil, err
+			}
+			if between.High, err = p.parseComparison(); err != nil {
+				return nil, err
+			}
+			x = between
+
+		default:
+			return x, nil
+		}
+	}
+}
+
+func (p *parser) parseIn(x Expr, not bool) (Expr, error) {
+	in := &InExpr{X: x, Not: not}
+	if !p.acceptOp("(") {
+		table, err := p.parseTableName()
+		if err != nil {
+			return nil, err
+		}
+		if p.acceptOp("(") {
+			table.Args = []Expr{}
+			if !p.acceptOp(")") {
+				if table.Args, err = p.parseExprList(); err != nil {
+					return nil, err
+				}
+				if err := p.expectOp(")"); err != nil {
+					return nil, err
+				}
+			}
+		}
+		in.Table = table
+		return in, nil
+	}
+
+	var err error
+	switch {
+	case p.isOp(")"):
+		in.List = []Expr{}
+	case p.isSelectStart():
+		if in.Select, err = p.parseSelect(); err != nil {
+			return nil, err
+		}
+	default:
+		if in.List, err = p.parseExprList(); err != nil {
+			return nil, err
+		}
+	}
+	if err := p.expectOp(")"); err != nil {
+		return nil, err
+	}
+	return in, nil
+}
+
+// parseBinaryLevel parses a left associative level of binary operators.
+func (p *parser) parseBinaryLevel(ops map[string]bool, next func() (Expr, error)) (Expr, error) {
+	x, err := next()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		t := p.peek()
+		if t.kind != tokOp || !ops[t.val] {
+			return x, nil
+		}
+		p.next()
+		y, err := next()
+		if err != nil {
+			return nil, err
+		}
+		x = &BinaryExpr{Op: t.val, X: x, Y: y}
+	}
+}
+
+var (
+	comparisonOps     = map[string]bool{"<": true, "<=": true, ">": true, ">=": true}
+	bitwiseOps        = map[string]bool{"&": true, "|": true, "<<": true, ">>": true}
+	additiveOps       = map[string]bool{"+": true, "-": true}
+	multiplicativeOps = map[string]bool{"*": true, "/": true, "%": true}
+	concatOps         = map[string]bool{"||": true}
+)
+
+func (p *parser) parseComparison() (Expr, error) {
+	return p.parseBinaryLevel(comparisonOps, p.parseBitwise)
+}
+
+func (p *parser) parseBitwise() (Expr, error) {
+	return p.parseBinaryLevel(bitwiseOps, p.parseAdditive)
+}
+
+func (p *parser) parseAdditive() (Expr, error) {
+	return p.parseBinaryLevel(additiveOps, p.parseMultiplicative)
+}
+
+func (p *parser) parseMultiplicative() (Expr, error) {
+	return p.parseBinaryLevel(multiplicativeOps, p.parseConcat)
+}
+
+func (p *parser) parseConcat() (Expr, error) {
+	return p.parseBinaryLevel(concatOps, p.parseUnary)
+}
+
+func (p *parser) parseUnary() (Expr, error) {
+	t := p.peek()
+	if t.kind == tokOp && (t.val == "-" || t.val == "+" || t.val == "~") {
+		p.next()
+		x, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return &UnaryExpr{Op: t.val, X: x}, nil
+	}
+
+	x, err := p.parsePrimary()
+	if err != nil {
+		return nil, err
+	}
+	for p.acceptKeyword("COLLATE") {
+		name, err := p.parseIdent()
+		if err != nil {
+			return nil, err
+		}
+		x = &CollateExpr{X: x, Collation: name.val}
+	}
+	return x, nil
+}
+
+func (p *parser) parsePrimary() (Expr, error) {
+	t := p.peek()
+	switch t.kind {
+	case tokNumber:
+		p.next()
+		return &Literal{Kind: LiteralNumber, Value: t.val}, nil
+	case tokString:
+		p.next()
+		return &Literal{Kind: LiteralString, Value: t.val}, nil
+	case tokBlob:
+		p.next()
+		return &Literal{Kind: LiteralBlob, Value: t.val}, nil
+	case tokParam:
+		p.next()
+		return &Literal{Kind: LiteralParam, Value: t.val}, nil
+	case tokOp:
+		if t.val == "(" {
+			return p.parseParen()
+		}
+		return nil, p.errorf("unexpected %s", t)
+	case tokEOF:
+		return nil, p.errorf("unexpected end of input")
+	}
+
+	switch t.keyword() {
+	case "NULL":
+		p.next()
+		return &Literal{Kind: LiteralNull, Value: "NULL"}, nil
+	case "CURRENT_TIME", "CURRENT_DATE", "CURRENT_TIMESTAMP":
+		p.next()
+		return &Literal{Kind: LiteralKeyword, Value: t.keyword()}, nil
+	case "CAST":
+		if p.peekAt(1).kind == tokOp && p.peekAt(1).val == "(" {
+			return p.parseCast()
+		}
+	case "CASE":
+		return p.parseCase()
+	case "EXISTS":
+		p.next()
+		if err := p.expectOp("("); err != nil {
+			return nil, err
+		}
+		sel, err := p.parseSelect()
+		if err != nil {
+			return nil, err
+		}
+		if err := p.expectOp(")"); err != nil {
+			return nil, err
+		}
+		return &SubqueryExpr{Exists: true, Select: sel}, nil
+	}
+
+	if !isIdent(t) {
+		return nil, p.errorf("unexpected %s", t)
+	}
+	p.next()
+
+	if t.kind == tokIdent && p.acceptOp("(") {
+		return p.parseFuncCall(t.val)
+	}
+
+	if !p.isOp(".") {
+		return &ColumnRef{Column: t.val, DoubleQuoted: t.doubleQuoted}, nil
+	}
+	p.next()
+	second, err := p.parseIdent()
+	if err != nil {
+		return nil, err
+	}
+	if !p.isOp(".") {
+		return &ColumnRef{Table: t.val, Column: second.val}, nil
+	}
+	// schema.table.column
+	p.next()
+	third, err := p.parseIdent()
+	if err != nil {
+		return nil, err
+	}
+	return &ColumnRef{Table: second.val, Column: third.val}, nil
+}
+
+func (p *parser) parseParen() (Expr, error) {
+	p.next()
+	if p.isSelectStart() {
+		sel, err := p.parseSelect()
+		if err != nil {
+			return nil, err
+		}
+		if err := p.expectOp(")"); err != nil {
+			return nil, err
+		}
+		return &SubqueryExpr{Select: sel}, nil
+	}
+	list, err := p.parseExprList()
+	if err != nil {
+		return nil, err
+	}
+	if err := p.expectOp(")"); err != nil {
+		return nil, err
+	}
+	return &ParenExpr{List: list}, nil
+}
+
+func (p *parser) parseFuncCall(name string) (Expr, error) {
+	call := &FuncCall{Name: name}
+	switch {
+	case p.acceptOp(")"):
+		return call, nil
+	case p.acceptOp("*"):
+		call.Star = true
+	default:
+		if p.acceptKeyword("DISTINCT") {
+			call.Distinct = true
+		} else {
+			p.acceptKeyword("ALL")
+		}
+		args, err := p.parseExprList()
+		if err != nil {
+			return nil, err
+		}
+		call.Args = args
+	}
+	if err := p.expectOp(")"); err != nil {
+		return nil, err
+	}
+	return call, nil
+}
+
+func (p *parser) parseCast() (Expr, error) {
+	p.next()
+	p.next()
+	x, err := p.parseExpr()
+	if err != nil {
+		return nil, err
+	}
+	if err := p.expectKeyword("AS"); err != nil {
+		return nil, err
+	}
+	typ, err := p.parseTypeName()
+	if err != nil {
+		return nil, err
+	}
+	if err := p.expectOp(")"); err != nil {
+		return nil, err
+	}
+	return &CastExpr{X: x, Type: typ}, nil
+}
+
+// parseTypeName parses a type name such as INTEGER or VARCHAR(255).
+func (p *parser) parseTypeName() (string, error) {
+	var words []string
+	for isIdent(p.peek()) {
+		words = append(words, p.next().val)
+	}
+	if len(words) == 0 {
+		return "", p.errorf("expected type name, found %s", p.peek())
+	}
+	typ := strings.Join(words, " ")
+
+	if p.acceptOp("(") {
+		var args []string
+		for {
+			sign := ""
+			if p.isOp("-") || p.isOp("+") {
+				sign = p.next().val
+			}
+			t := p.peek()
+			if t.kind != tokNumber {
+				return "", p.errorf("expected number, found %s", t)
+			}
+			p.next()
+			args = append(args, sign+t.val)
+			if !p.acceptOp(",") {
+				break
+			}
+		}
+		if err := p.expectOp(")"); err != nil {
+			return "", err
+		}
+		typ += "(" + strings.Join(args, ",") + ")"
+	}
+	return typ, nil
+}
+
+func (p *parser) parseCase() (Expr, error) {
+	p.next()
+	expr := &CaseExpr{}
+	var err error
+	if !p.isKeyword("WHEN") {
+		if expr.Operand, err = p.parseExpr(); err != nil {
+			return nil, err
+		}
+	}
+	for p.acceptKeyword("WHEN") {
+		when := &When{}
+		if when.Cond, err = p.parseExpr(); err != nil {
+			return nil, err
+		}
+		if err := p.expectKeyword("THEN"); err != nil {
+			return nil, err
+		}
+		if when.Result, err = p.parseExpr(); err != nil {
+			return nil, err
+		}
+		expr.Whens = append(expr.Whens, when)
+	}
+	if len(expr.Whens) == 0 {
+		return nil, p.errorf("expected WHEN, found %s", p.peek())
+	}
+	if p.acceptKeyword("ELSE") {
+		if expr.Else, err = p.parseExpr(); err != nil {
+			return nil, err
+		}
+	}
+	if err := p.expectKeyword("END"); err != nil {
+		return nil, err
+	}
+	return expr, nil
+}
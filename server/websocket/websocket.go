@@ -135,3 +135,11 @@ func (c *Conn) ReadAuthToken() (token.Token, error) {
 
 	return auth.Token, nil
 }
+
+// CloseGoingAway sends a close message telling the client that the server is
+// going away, as it does when it shuts down. The connection must still be
+// closed after.
+func (c *Conn) CloseGoingAway() error {
+	msg := websocket.FormatCloseMessage(websocket.CloseGoingAway, "server shutting down")
+	return c.WriteControl(websocket.CloseMessage, msg, time.Now().Add(c.Timeout))
+}